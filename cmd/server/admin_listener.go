@@ -0,0 +1,217 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pbsconfig "github.com/thenexusengine/tne_springwire/internal/config"
+	"github.com/thenexusengine/tne_springwire/internal/endpoints"
+	"github.com/thenexusengine/tne_springwire/internal/filewatch"
+	"github.com/thenexusengine/tne_springwire/internal/metrics"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// initAdminServer creates the admin listener when ADMIN_PORT is set
+// The admin mux serves /admin/*, /metrics, pprof and health; buildHandler adds the /admin routes.
+func (s *Server) initAdminServer() error {
+	if s.config.AdminPort == "" {
+		if s.config.AdminTLS != nil {
+			logger.Log.Warn().Msg("ADMIN_TLS_* settings ignored because ADMIN_PORT is not set")
+		}
+		return nil
+	}
+	if s.config.AdminPort == s.config.Port {
+		return fmt.Errorf("ADMIN_PORT must differ from the public port %s", s.config.Port)
+	}
+
+	var tlsReloader *adminTLSReloader
+	if s.config.AdminTLS != nil {
+		var err error
+		tlsReloader, err = newAdminTLSReloader(s.config.AdminTLS)
+		if err != nil {
+			return fmt.Errorf("invalid admin TLS configuration: %w", err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/status", endpoints.NewStatusHandler())
+	mux.Handle("/health", healthHandler())
+	mux.Handle("/health/ready", readyHandler(s.redisClient, s.exchange))
+	mux.Handle("/metrics", metrics.Handler())
+
+	s.adminMux = mux
+	s.adminTLS = tlsReloader
+	s.adminServer = &http.Server{
+		Addr:         ":" + s.config.AdminPort,
+		ReadTimeout:  pbsconfig.ServerReadTimeout,
+		WriteTimeout: adminWriteTimeout,
+		IdleTimeout:  pbsconfig.ServerIdleTimeout,
+	}
+	if tlsReloader != nil {
+		s.adminServer.TLSConfig = tlsReloader.TLSConfig()
+	}
+	return nil
+}
+
+// adminWriteTimeout leaves room for pprof CPU profiles and traces (30s by default)
+const adminWriteTimeout = 60 * time.Second
+
+// registerProfilingRoutes serves pprof on the admin listener
+// Profiles reveal internals, so they need the profiling permission even for reads.
+func registerProfilingRoutes(mux *http.ServeMux, adminAuth *middleware.AdminAuth) {
+	route := middleware.AdminRoute{Read: middleware.PermProfiling}
+	mux.Handle("/debug/pprof/", adminAuth.Protect(route, http.HandlerFunc(pprof.Index)))
+	mux.Handle("/debug/pprof/cmdline", adminAuth.Protect(route, http.HandlerFunc(pprof.Cmdline)))
+	mux.Handle("/debug/pprof/profile", adminAuth.Protect(route, http.HandlerFunc(pprof.Profile)))
+	mux.Handle("/debug/pprof/symbol", adminAuth.Protect(route, http.HandlerFunc(pprof.Symbol)))
+	mux.Handle("/debug/pprof/trace", adminAuth.Protect(route, http.HandlerFunc(pprof.Trace)))
+}
+
+// buildAdminHandler builds the admin listener's middleware chain
+// There is no CORS, publisher auth, public rate limiting or gzip; each admin route
+// authenticates itself through AdminAuth.
+func buildAdminHandler(mux *http.ServeMux) http.Handler {
+	security := middleware.NewSecurity(nil)
+	sizeLimiter := middleware.NewSizeLimiter(middleware.DefaultSizeLimitConfig())
+
+	// Build chain: Security -> Logging -> Size Limit -> Handler
+	handler := http.Handler(mux)
+	handler = sizeLimiter.Middleware(handler)
+	handler = loggingMiddleware(handler)
+	handler = security.Middleware(handler)
+
+	return handler
+}
+
+// adminTLSReloader serves the admin listener's certificate and client CAs
+// Files are re-read when they change; a failed reload keeps the previous material.
+type adminTLSReloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	poller       *filewatch.Poller
+
+	material atomic.Pointer[adminTLSMaterial]
+	mu       sync.Mutex // Serializes reloads
+}
+
+// adminTLSMaterial is an immutable snapshot of the loaded files
+type adminTLSMaterial struct {
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool // nil = no client certificates required
+	versions  map[string]filewatch.Version
+}
+
+// newAdminTLSReloader validates the config and loads the certificate and client CAs
+func newAdminTLSReloader(cfg *AdminTLSConfig) (*adminTLSReloader, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("ADMIN_TLS_CERT_FILE and ADMIN_TLS_KEY_FILE must both be set")
+	}
+
+	r := &adminTLSReloader{
+		certFile:     cfg.CertFile,
+		keyFile:      cfg.KeyFile,
+		clientCAFile: cfg.ClientCAFile,
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	r.poller = filewatch.NewPoller(cfg.ReloadInterval, r.Reload, func(err error) {
+		logger.Log.Warn().Err(err).Msg("Failed to reload admin TLS material, keeping previous")
+	})
+	return r, nil
+}
+
+// Reload re-reads the certificate, key and client CAs if any of them changed
+func (r *adminTLSReloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	paths := []string{r.certFile, r.keyFile}
+	if r.clientCAFile != "" {
+		paths = append(paths, r.clientCAFile)
+	}
+	versions := make(map[string]filewatch.Version, len(paths))
+	for _, path := range paths {
+		version, err := filewatch.Stat(path)
+		if err != nil {
+			return err
+		}
+		versions[path] = version
+	}
+
+	current := r.material.Load()
+	if current != nil && maps.Equal(current.versions, versions) {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load admin certificate: %w", err)
+	}
+	next := &adminTLSMaterial{cert: &cert, versions: versions}
+
+	if r.clientCAFile != "" {
+		pem, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("read admin client CAs: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in %s", r.clientCAFile)
+		}
+		next.clientCAs = pool
+	}
+
+	r.material.Store(next)
+	logger.Log.Info().
+		Str("cert_file", r.certFile).
+		Bool("client_auth", next.clientCAs != nil).
+		Msg("Admin TLS material loaded")
+	return nil
+}
+
+// TLSConfig returns a config that picks up reloaded material on every handshake
+func (r *adminTLSReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			material := r.material.Load()
+			cfg := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*material.cert},
+			}
+			if material.clientCAs != nil {
+				cfg.ClientAuth = tls.RequireAndVerifyClientCert
+				cfg.ClientCAs = material.clientCAs
+			}
+			return cfg, nil
+		},
+	}
+}
+
+// Start launches periodic change checks; a zero interval disables them
+func (r *adminTLSReloader) Start() {
+	if r == nil {
+		return
+	}
+	r.poller.Start()
+}
+
+// Stop stops periodic reloads
+func (r *adminTLSReloader) Stop() {
+	if r == nil {
+		return
+	}
+	r.poller.Stop()
+}
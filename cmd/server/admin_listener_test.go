@@ -0,0 +1,226 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+)
+
+// testCert is a generated certificate with its key (test helper)
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCert creates a certificate signed by parent, or self-signed when parent is nil
+func newTestCert(t *testing.T, commonName string, isCA bool, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// writeFile writes data and bumps the mtime so reloads notice same-size rewrites
+func writeFile(t *testing.T, path string, data []byte, mtime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatalf("chtimes %s: %v", path, err)
+	}
+}
+
+func TestAdminTLSReloader_MTLSAndReload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "admin-ca", true, nil)
+	server := newTestCert(t, "server-1", false, ca)
+	client := newTestCert(t, "ops-client", false, ca)
+	stranger := newTestCert(t, "stranger", false, nil)
+
+	certFile := filepath.Join(dir, "admin.crt")
+	keyFile := filepath.Join(dir, "admin.key")
+	caFile := filepath.Join(dir, "clients.pem")
+	mtime := time.Now().Add(-time.Minute)
+	writeFile(t, certFile, server.certPEM, mtime)
+	writeFile(t, keyFile, server.keyPEM, mtime)
+	writeFile(t, caFile, ca.certPEM, mtime)
+
+	reloader, err := newAdminTLSReloader(&AdminTLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile})
+	if err != nil {
+		t.Fatalf("newAdminTLSReloader failed: %v", err)
+	}
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	ts.TLS = reloader.TLSConfig()
+	ts.StartTLS()
+	defer ts.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	get := func(clientCert *testCert) (*http.Response, error) {
+		tlsConfig := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+		if clientCert != nil {
+			pair, _ := tls.X509KeyPair(clientCert.certPEM, clientCert.keyPEM)
+			tlsConfig.Certificates = []tls.Certificate{pair}
+		}
+		httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+		return httpClient.Get(ts.URL)
+	}
+
+	resp, err := get(client)
+	if err != nil {
+		t.Fatalf("expected client with a CA-signed certificate to connect: %v", err)
+	}
+	resp.Body.Close()
+	if resp.TLS.PeerCertificates[0].Subject.CommonName != "server-1" {
+		t.Errorf("expected server-1 certificate, got %s", resp.TLS.PeerCertificates[0].Subject.CommonName)
+	}
+
+	if _, err := get(nil); err == nil {
+		t.Error("expected connection without a client certificate to fail")
+	}
+	if _, err := get(stranger); err == nil {
+		t.Error("expected connection with an unknown client certificate to fail")
+	}
+
+	// A broken file keeps the previous certificate
+	writeFile(t, certFile, []byte("garbage"), mtime.Add(time.Second))
+	if err := reloader.Reload(); err == nil {
+		t.Error("expected reload of an invalid certificate to fail")
+	}
+
+	// A rotated certificate is served without restarting the listener
+	rotated := newTestCert(t, "server-2", false, ca)
+	writeFile(t, certFile, rotated.certPEM, mtime.Add(2*time.Second))
+	writeFile(t, keyFile, rotated.keyPEM, mtime.Add(2*time.Second))
+	if err := reloader.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	resp, err = get(client)
+	if err != nil {
+		t.Fatalf("request after reload failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.TLS.PeerCertificates[0].Subject.CommonName != "server-2" {
+		t.Errorf("expected rotated server-2 certificate, got %s", resp.TLS.PeerCertificates[0].Subject.CommonName)
+	}
+}
+
+func TestNewAdminTLSReloader_InvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	emptyCA := filepath.Join(dir, "empty.pem")
+	writeFile(t, emptyCA, []byte("no certificates here"), time.Now())
+	server := newTestCert(t, "server", false, nil)
+	certFile := filepath.Join(dir, "admin.crt")
+	keyFile := filepath.Join(dir, "admin.key")
+	writeFile(t, certFile, server.certPEM, time.Now())
+	writeFile(t, keyFile, server.keyPEM, time.Now())
+
+	tests := []struct {
+		name string
+		cfg  AdminTLSConfig
+	}{
+		{"missing key", AdminTLSConfig{CertFile: certFile}},
+		{"client CA without certificate", AdminTLSConfig{ClientCAFile: emptyCA}},
+		{"missing file", AdminTLSConfig{CertFile: filepath.Join(dir, "nope.crt"), KeyFile: keyFile}},
+		{"client CA without certificates", AdminTLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: emptyCA}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newAdminTLSReloader(&tt.cfg); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestServer_InitAdminServer(t *testing.T) {
+	s := &Server{config: &ServerConfig{Port: "8000"}}
+	if err := s.initAdminServer(); err != nil || s.adminServer != nil {
+		t.Fatalf("expected no admin listener without ADMIN_PORT, got %v, %v", s.adminServer, err)
+	}
+
+	s = &Server{config: &ServerConfig{Port: "8000", AdminPort: "8000"}}
+	if err := s.initAdminServer(); err == nil {
+		t.Error("expected an error when ADMIN_PORT equals the public port")
+	}
+
+	s = &Server{config: &ServerConfig{Port: "8000", AdminPort: "9100"}}
+	if err := s.initAdminServer(); err != nil {
+		t.Fatalf("initAdminServer failed: %v", err)
+	}
+	if s.adminServer.Addr != ":9100" || s.adminServer.TLSConfig != nil {
+		t.Errorf("unexpected admin server %+v", s.adminServer)
+	}
+
+	adminAuth := middleware.NewAdminAuth(&middleware.AdminAuthConfig{Enabled: true}, middleware.NewAuth(&middleware.AuthConfig{}))
+	registerProfilingRoutes(s.adminMux, adminAuth)
+	handler := buildAdminHandler(s.adminMux)
+
+	routes := []struct {
+		path           string
+		expectedStatus int
+	}{
+		{"/health", http.StatusOK},
+		{"/status", http.StatusOK},
+		{"/metrics", http.StatusOK},
+		{"/debug/pprof/", http.StatusUnauthorized}, // Profiling needs an operator key
+		{"/openrtb2/auction", http.StatusNotFound},
+	}
+	for _, route := range routes {
+		t.Run(route.path, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, route.path, nil))
+			if rr.Code != route.expectedStatus {
+				t.Errorf("Expected status %d for %s, got %d", route.expectedStatus, route.path, rr.Code)
+			}
+		})
+	}
+}
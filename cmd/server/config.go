@@ -1,86 +1,517 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/thenexusengine/tne_springwire/internal/exchange"
+	"github.com/thenexusengine/tne_springwire/internal/fpd"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/usersync"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
 )
 
 // ServerConfig holds all server configuration
+// Values are layered defaults < config file < environment < command-line flags. Fields tagged
+// reload:"true" are applied on SIGHUP; the rest require a restart.
 type ServerConfig struct {
 	// Server
-	Port    string
-	Timeout time.Duration
+	Port    string        `yaml:"port" env:"PBS_PORT"`
+	Timeout time.Duration `yaml:"timeout" env:"PBS_TIMEOUT" reload:"true"`
+
+	// Admin listener (empty AdminPort = admin endpoints share the public port)
+	AdminPort string          `yaml:"admin_port" env:"ADMIN_PORT"`
+	AdminTLS  *AdminTLSConfig `yaml:"admin_tls"`
 
 	// Database
-	DatabaseConfig *DatabaseConfig
+	DatabaseConfig *DatabaseConfig `yaml:"database"`
 
 	// Redis
-	RedisURL string
+	RedisURL string `yaml:"redis_url" env:"REDIS_URL" secret:"true"`
 
 	// IDR
-	IDREnabled bool
-	IDRUrl     string
-	IDRAPIKey  string
+	IDREnabled bool   `yaml:"idr_enabled" env:"IDR_ENABLED"`
+	IDRUrl     string `yaml:"idr_url" env:"IDR_URL"`
+	IDRAPIKey  string `yaml:"idr_api_key" env:"IDR_API_KEY" secret:"true"`
 
 	// Currency
-	CurrencyConversionEnabled bool
-	DefaultCurrency           string
+	CurrencyConversionEnabled bool   `yaml:"currency_conversion_enabled" env:"CURRENCY_CONVERSION_ENABLED"`
+	DefaultCurrency           string `yaml:"default_currency"`
 
 	// Privacy
-	DisableGDPREnforcement bool
+	DisableGDPREnforcement bool `yaml:"disable_gdpr_enforcement" env:"PBS_DISABLE_GDPR_ENFORCEMENT"`
 
 	// Cookie Sync
-	HostURL string
+	HostURL string `yaml:"host_url" env:"PBS_HOST_URL"`
+
+	// Sections below are nil in hand-built configs; NewServer fills them from defaults and env
+	Log             *LogConfig             `yaml:"log"`
+	Privacy         *PrivacyConfig         `yaml:"privacy"`
+	IVT             *IVTConfig             `yaml:"ivt"`
+	RateLimit       *RateLimitConfig       `yaml:"rate_limit"`
+	CORS            *CORSConfig            `yaml:"cors"`
+	Auth            *AuthConfig            `yaml:"auth"`
+	PublisherAuth   *PublisherAuthConfig   `yaml:"publisher_auth"`
+	SecurityHeaders *SecurityHeadersConfig `yaml:"security_headers"`
+	SizeLimit       *SizeLimitConfig       `yaml:"size_limit"`
+	GeoEnrichment   *GeoEnrichmentConfig   `yaml:"geo_enrichment"`
+	FPD             *fpd.Config            `yaml:"fpd" reload:"true"`
+
+	// sources records where each value came from, by config path (for --check-config)
+	sources map[string]string
+	// loader loads the config again on SIGHUP (nil for hand-built configs)
+	loader *configLoader
 }
 
 // DatabaseConfig holds database connection configuration
 type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	Name     string
-	SSLMode  string
-}
-
-// ParseConfig parses configuration from flags and environment variables
-func ParseConfig() *ServerConfig {
-	// Parse flags with environment variable fallbacks
-	port := flag.String("port", getEnvOrDefault("PBS_PORT", "8000"), "Server port")
-	idrURL := flag.String("idr-url", getEnvOrDefault("IDR_URL", "http://localhost:5050"), "IDR service URL")
-	idrEnabled := flag.Bool("idr-enabled", getEnvBoolOrDefault("IDR_ENABLED", true), "Enable IDR integration")
-	timeout := flag.Duration("timeout", 1000*time.Millisecond, "Default auction timeout")
-	flag.Parse()
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Port     string `yaml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" env:"DB_USER"`
+	Password string `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name     string `yaml:"name" env:"DB_NAME"`
+	SSLMode  string `yaml:"ssl_mode" env:"DB_SSL_MODE"`
+}
+
+// AdminTLSConfig holds TLS settings for the admin listener
+type AdminTLSConfig struct {
+	CertFile       string        `yaml:"cert_file" env:"ADMIN_TLS_CERT_FILE"`
+	KeyFile        string        `yaml:"key_file" env:"ADMIN_TLS_KEY_FILE"`
+	ClientCAFile   string        `yaml:"client_ca_file" env:"ADMIN_TLS_CLIENT_CA_FILE"`   // Require client certificates signed by these CAs (empty = no mTLS)
+	ReloadInterval time.Duration `yaml:"reload_interval" env:"ADMIN_TLS_RELOAD_INTERVAL"` // How often changed files are reloaded (0 = never)
+}
+
+// LogConfig holds logging configuration
+type LogConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL"`   // debug, info, warn, error
+	Format string `yaml:"format" env:"LOG_FORMAT"` // json, console
+}
+
+// PrivacyConfig holds privacy enforcement configuration
+type PrivacyConfig struct {
+	EnforceGDPR    bool `yaml:"enforce_gdpr" env:"PBS_ENFORCE_GDPR"`
+	EnforceCOPPA   bool `yaml:"enforce_coppa" env:"PBS_ENFORCE_COPPA"`
+	EnforceCCPA    bool `yaml:"enforce_ccpa" env:"PBS_ENFORCE_CCPA"`
+	GeoEnforcement bool `yaml:"geo_enforcement" env:"PBS_GEO_ENFORCEMENT"`
+	StrictMode     bool `yaml:"strict_mode" env:"PBS_PRIVACY_STRICT_MODE"`
+	AnonymizeIP    bool `yaml:"anonymize_ip" env:"PBS_ANONYMIZE_IP"`
+}
+
+// IVTConfig holds invalid traffic detection configuration
+// GeoIP and IP list files are opened at startup; everything else reloads.
+type IVTConfig struct {
+	MonitoringEnabled    bool     `yaml:"monitoring_enabled" env:"IVT_MONITORING_ENABLED" reload:"true"`
+	BlockingEnabled      bool     `yaml:"blocking_enabled" env:"IVT_BLOCKING_ENABLED" reload:"true"`
+	BlockThreshold       int      `yaml:"block_threshold" env:"IVT_BLOCK_THRESHOLD" reload:"true"`
+	CheckUserAgent       bool     `yaml:"check_user_agent" env:"IVT_CHECK_UA" reload:"true"`
+	CheckReferer         bool     `yaml:"check_referer" env:"IVT_CHECK_REFERER" reload:"true"`
+	CheckGeo             bool     `yaml:"check_geo" env:"IVT_CHECK_GEO" reload:"true"`
+	CheckIPReputation    bool     `yaml:"check_ip_reputation" env:"IVT_CHECK_IP_REPUTATION" reload:"true"`
+	CheckRateLimit       bool     `yaml:"check_rate_limit" env:"IVT_CHECK_RATELIMIT" reload:"true"`
+	AllowedCountries     []string `yaml:"allowed_countries" env:"IVT_ALLOWED_COUNTRIES" reload:"true"`
+	BlockedCountries     []string `yaml:"blocked_countries" env:"IVT_BLOCKED_COUNTRIES" reload:"true"`
+	SuspiciousUAPatterns []string `yaml:"suspicious_ua_patterns" reload:"true"`
+	RequireReferer       bool     `yaml:"require_referer" env:"IVT_REQUIRE_REFERER" reload:"true"`
+
+	GeoIPDBPath          string        `yaml:"geoip_db_path" env:"GEOIP_DB_PATH"`
+	DatacenterListPath   string        `yaml:"datacenter_list" env:"IVT_DATACENTER_LIST"`
+	ProxyListPath        string        `yaml:"proxy_list" env:"IVT_PROXY_LIST"`
+	BotASNListPath       string        `yaml:"bot_asn_list" env:"IVT_BOT_ASN_LIST"`
+	IPListReloadInterval time.Duration `yaml:"ip_list_reload_interval" env:"IVT_IP_LIST_RELOAD_INTERVAL"`
+
+	CheckBehavior      bool          `yaml:"check_behavior" env:"IVT_CHECK_BEHAVIOR" reload:"true"`
+	BehaviorWindow     time.Duration `yaml:"behavior_window" env:"IVT_BEHAVIOR_WINDOW" reload:"true"`
+	BehaviorTimeout    time.Duration `yaml:"behavior_timeout" env:"IVT_BEHAVIOR_TIMEOUT" reload:"true"`
+	MaxRequestsPerIP   int64         `yaml:"max_requests_per_ip" env:"IVT_MAX_REQUESTS_PER_IP" reload:"true"`
+	MaxRequestsPerIFA  int64         `yaml:"max_requests_per_ifa" env:"IVT_MAX_REQUESTS_PER_IFA" reload:"true"`
+	MaxRequestsPerUser int64         `yaml:"max_requests_per_user" env:"IVT_MAX_REQUESTS_PER_USER" reload:"true"`
+	MaxDomainsPerIP    int64         `yaml:"max_domains_per_ip" env:"IVT_MAX_DOMAINS_PER_IP" reload:"true"`
+}
+
+// RateLimitConfig holds per-client rate limiting configuration
+type RateLimitConfig struct {
+	Enabled           bool          `yaml:"enabled" env:"RATE_LIMIT_ENABLED" reload:"true"`
+	RequestsPerSecond int           `yaml:"requests_per_second" env:"RATE_LIMIT_RPS" reload:"true"`
+	BurstSize         int           `yaml:"burst_size" env:"RATE_LIMIT_BURST" reload:"true"` // 0 = twice RequestsPerSecond
+	RedisTimeout      time.Duration `yaml:"redis_timeout" env:"RATE_LIMIT_REDIS_TIMEOUT"`
+	TrustedProxies    []string      `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"` // CIDRs or IPs allowed to set X-Forwarded-For
+}
+
+// CORSConfig holds CORS configuration
+type CORSConfig struct {
+	Enabled          bool     `yaml:"enabled" env:"CORS_ENABLED" reload:"true"`
+	AllowedOrigins   []string `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS" reload:"true"`  // Empty rejects cross-origin requests unless AllowAll
+	AllowAll         bool     `yaml:"allow_all" env:"CORS_ALLOW_ALL,PBS_DEV_MODE" reload:"true"` // Development only
+	AllowCredentials bool     `yaml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS"`
+}
+
+// AuthConfig holds API key authentication configuration
+type AuthConfig struct {
+	Enabled         bool              `yaml:"enabled" env:"AUTH_ENABLED"`
+	APIKeys         map[string]string `yaml:"api_keys" env:"API_KEYS" secret:"true"` // key -> publisher ID
+	UseRedis        bool              `yaml:"use_redis" env:"AUTH_USE_REDIS"`
+	LegacyKeyScopes []string          `yaml:"legacy_key_scopes" env:"API_KEY_LEGACY_SCOPES"` // nil = all scopes, ["none"] = legacy keys rejected
+	LegacyKeyRole   string            `yaml:"legacy_key_role" env:"API_KEY_LEGACY_ROLE"`     // "" = admin, "none" = no admin access
+	AdminEnabled    bool              `yaml:"admin_enabled" env:"ADMIN_AUTH_ENABLED"`
+}
+
+// PublisherAuthConfig holds publisher authentication configuration
+type PublisherAuthConfig struct {
+	Enabled              bool              `yaml:"enabled" env:"PUBLISHER_AUTH_ENABLED"`
+	AllowUnregistered    bool              `yaml:"allow_unregistered" env:"PUBLISHER_ALLOW_UNREGISTERED"`
+	RegisteredPublishers map[string]string `yaml:"registered_publishers" env:"REGISTERED_PUBLISHERS"` // publisher_id -> allowed domains (comma-separated, empty = any)
+	ValidateDomain       bool              `yaml:"validate_domain" env:"PUBLISHER_VALIDATE_DOMAIN"`
+	RateLimitRPS         int               `yaml:"rate_limit_rps" env:"PUBLISHER_RATE_LIMIT_RPS"` // 0 = unlimited
+	RateLimitTiers       RateLimitTiers    `yaml:"rate_limit_tiers" env:"PUBLISHER_RATE_LIMIT_TIERS"`
+	UseRedis             bool              `yaml:"use_redis" env:"PUBLISHER_AUTH_USE_REDIS"`
+}
+
+// RateLimitTiers are named per-publisher rate limits
+// In the environment they are written "basic:50,standard:100:200" (name:rps[:burst]).
+type RateLimitTiers map[string]RateLimitTier
+
+// RateLimitTier is one named per-publisher rate limit
+type RateLimitTier struct {
+	RequestsPerSecond int `yaml:"requests_per_second"` // 0 = unlimited
+	BurstSize         int `yaml:"burst_size"`          // 0 = RequestsPerSecond
+}
+
+// decodeEnv parses the environment form of rate limit tiers
+func (t *RateLimitTiers) decodeEnv(value string) error {
+	tiers := make(RateLimitTiers)
+	for _, entry := range splitList(value) {
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 || len(parts) > 3 || strings.TrimSpace(parts[0]) == "" {
+			return fmt.Errorf("invalid tier %q, want name:rps[:burst]", entry)
+		}
+		var tier RateLimitTier
+		var err error
+		if tier.RequestsPerSecond, err = strconv.Atoi(strings.TrimSpace(parts[1])); err != nil {
+			return fmt.Errorf("invalid tier %q: %w", entry, err)
+		}
+		if len(parts) == 3 {
+			if tier.BurstSize, err = strconv.Atoi(strings.TrimSpace(parts[2])); err != nil {
+				return fmt.Errorf("invalid tier %q: %w", entry, err)
+			}
+		}
+		tiers[strings.TrimSpace(parts[0])] = tier
+	}
+	*t = tiers
+	return nil
+}
+
+// SecurityHeadersConfig holds security response header configuration
+// Enabled, HSTS and CSP reload; an empty header value omits the header.
+type SecurityHeadersConfig struct {
+	Enabled               bool   `yaml:"enabled" env:"SECURITY_HEADERS_ENABLED" reload:"true"`
+	XFrameOptions         string `yaml:"x_frame_options" env:"SECURITY_X_FRAME_OPTIONS"`
+	ContentSecurityPolicy string `yaml:"content_security_policy" env:"SECURITY_CSP" reload:"true"`
+	ReferrerPolicy        string `yaml:"referrer_policy" env:"SECURITY_REFERRER_POLICY"`
+	HSTS                  string `yaml:"hsts" env:"SECURITY_HSTS" reload:"true"`
+	PermissionsPolicy     string `yaml:"permissions_policy" env:"SECURITY_PERMISSIONS_POLICY"`
+	CacheControl          string `yaml:"cache_control" env:"SECURITY_CACHE_CONTROL"`
+}
+
+// SizeLimitConfig holds request size limit configuration
+type SizeLimitConfig struct {
+	Enabled        bool  `yaml:"enabled" reload:"true"`
+	MaxRequestSize int64 `yaml:"max_request_size" env:"MAX_REQUEST_SIZE" reload:"true"` // Bytes
+	MaxURLLength   int   `yaml:"max_url_length" env:"MAX_URL_LENGTH" reload:"true"`
+}
+
+// GeoEnrichmentConfig holds device.geo enrichment configuration
+type GeoEnrichmentConfig struct {
+	Enabled bool `yaml:"enabled" env:"GEOIP_ENRICH_DEVICE_GEO" reload:"true"` // Needs GeoIP (ivt.geoip_db_path) for IP lookups
+}
 
-	cfg := &ServerConfig{
-		Port:                      *port,
-		Timeout:                   *timeout,
-		RedisURL:                  os.Getenv("REDIS_URL"),
-		IDREnabled:                *idrEnabled,
-		IDRUrl:                    *idrURL,
-		IDRAPIKey:                 os.Getenv("IDR_API_KEY"),
-		CurrencyConversionEnabled: os.Getenv("CURRENCY_CONVERSION_ENABLED") != "false",
+// DefaultServerConfig returns the built-in defaults, before the config file and environment
+func DefaultServerConfig() *ServerConfig {
+	return &ServerConfig{
+		Port:    "8000",
+		Timeout: 1000 * time.Millisecond,
+		AdminTLS: &AdminTLSConfig{
+			ReloadInterval: time.Minute,
+		},
+		DatabaseConfig: &DatabaseConfig{
+			Port:    "5432",
+			User:    "catalyst",
+			Name:    "catalyst",
+			SSLMode: "disable",
+		},
+		IDREnabled:                true,
+		IDRUrl:                    "http://localhost:5050",
+		CurrencyConversionEnabled: true,
 		DefaultCurrency:           "USD",
-		DisableGDPREnforcement:    os.Getenv("PBS_DISABLE_GDPR_ENFORCEMENT") == "true",
-		HostURL:                   getEnvOrDefault("PBS_HOST_URL", "https://catalyst.springwire.ai"),
+		HostURL:                   "https://catalyst.springwire.ai",
+		Log: &LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Privacy: &PrivacyConfig{
+			EnforceGDPR:    true,
+			EnforceCOPPA:   true,
+			EnforceCCPA:    true,
+			GeoEnforcement: true,
+			StrictMode:     true,
+			AnonymizeIP:    true,
+		},
+		IVT: &IVTConfig{
+			MonitoringEnabled:    true,
+			BlockThreshold:       middleware.DefaultIVTBlockThreshold,
+			CheckUserAgent:       true,
+			CheckReferer:         true,
+			CheckIPReputation:    true,
+			CheckRateLimit:       true,
+			IPListReloadInterval: time.Minute,
+			CheckBehavior:        true,
+			BehaviorWindow:       time.Minute,
+			BehaviorTimeout:      10 * time.Millisecond,
+			MaxRequestsPerIP:     600,
+			MaxRequestsPerIFA:    120,
+			MaxRequestsPerUser:   120,
+			MaxDomainsPerIP:      20,
+		},
+		RateLimit: &RateLimitConfig{
+			Enabled:           true,
+			RequestsPerSecond: 1000,
+			RedisTimeout:      5 * time.Millisecond,
+		},
+		CORS: &CORSConfig{
+			Enabled: true,
+		},
+		Auth: &AuthConfig{
+			UseRedis:     true,
+			AdminEnabled: true,
+		},
+		PublisherAuth: &PublisherAuthConfig{
+			Enabled:      true,
+			RateLimitRPS: 100,
+			UseRedis:     true,
+		},
+		SecurityHeaders: &SecurityHeadersConfig{
+			Enabled:               true,
+			XFrameOptions:         "DENY",
+			ContentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'",
+			ReferrerPolicy:        "strict-origin-when-cross-origin",
+			HSTS:                  "max-age=31536000; includeSubDomains",
+			PermissionsPolicy:     "geolocation=(), microphone=(), camera=()",
+			CacheControl:          "no-store, no-cache, must-revalidate, private",
+		},
+		SizeLimit: &SizeLimitConfig{
+			Enabled:        true,
+			MaxRequestSize: 1024 * 1024,
+			MaxURLLength:   8192,
+		},
+		GeoEnrichment: &GeoEnrichmentConfig{
+			Enabled: true,
+		},
+		FPD: defaultFPDConfig(),
+	}
+}
+
+// defaultFPDConfig returns the FPD defaults, allowing the pubcid.org EID source only when SharedID minting is enabled
+// Deployments without minting keep the upstream allow list; a config file can still list pubcid.org.
+func defaultFPDConfig() *fpd.Config {
+	cfg := fpd.DefaultConfig()
+	if usersync.DefaultSharedIDConfig().Enabled {
+		cfg.EIDSources = append(cfg.EIDSources, usersync.SharedIDSource)
+	}
+	return cfg
+}
+
+// ParseConfig parses configuration from the config file, environment variables and flags
+func ParseConfig() (*ServerConfig, error) {
+	// Flags override the config file and environment only when given
+	configFile := flag.String("config", getEnvOrDefault("PBS_CONFIG_FILE", ""), "Config file (YAML or JSON)")
+	flag.String("port", "8000", "Server port")
+	flag.String("idr-url", "http://localhost:5050", "IDR service URL")
+	flag.Bool("idr-enabled", true, "Enable IDR integration")
+	flag.String("admin-port", "", "Admin listener port (empty = serve admin endpoints on the public port)")
+	flag.Duration("timeout", 1000*time.Millisecond, "Default auction timeout")
+	flag.Parse()
+
+	loader := &configLoader{file: *configFile, flags: setFlags(flag.CommandLine)}
+	return loader.Load()
+}
+
+// Validate reports every invalid setting at once
+func (c *ServerConfig) Validate() error {
+	var errs []error
+	fail := func(path, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+	}
+
+	if !validPort(c.Port) {
+		fail("port", "invalid port %q", c.Port)
+	}
+	if c.Timeout <= 0 {
+		fail("timeout", "must be positive")
+	}
+	if c.AdminPort != "" {
+		if !validPort(c.AdminPort) {
+			fail("admin_port", "invalid port %q", c.AdminPort)
+		} else if c.AdminPort == c.Port {
+			fail("admin_port", "must differ from the public port %s", c.Port)
+		}
+	}
+	if tls := c.AdminTLS; tls != nil {
+		if c.AdminPort == "" {
+			fail("admin_tls", "requires admin_port")
+		}
+		if tls.CertFile == "" || tls.KeyFile == "" {
+			fail("admin_tls", "cert_file and key_file are both required")
+		}
+		if tls.ReloadInterval < 0 {
+			fail("admin_tls.reload_interval", "must not be negative")
+		}
+	}
+	if db := c.DatabaseConfig; db != nil {
+		if !validPort(db.Port) {
+			fail("database.port", "invalid port %q", db.Port)
+		}
+		switch db.SSLMode {
+		case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+		default:
+			fail("database.ssl_mode", "unknown mode %q", db.SSLMode)
+		}
+	}
+	if c.RedisURL != "" && !validURL(c.RedisURL, "redis", "rediss") {
+		fail("redis_url", "must be a redis:// or rediss:// URL")
+	}
+	if c.IDREnabled && !validURL(c.IDRUrl, "http", "https") {
+		fail("idr_url", "must be an http(s) URL")
+	}
+	if !validURL(c.HostURL, "http", "https") {
+		fail("host_url", "must be an http(s) URL")
+	}
+	if len(c.DefaultCurrency) != 3 {
+		fail("default_currency", "must be a 3-letter currency code")
 	}
 
-	// Parse database config if DB_HOST is set
-	if dbHost := os.Getenv("DB_HOST"); dbHost != "" {
-		cfg.DatabaseConfig = &DatabaseConfig{
-			Host:     dbHost,
-			Port:     getEnvOrDefault("DB_PORT", "5432"),
-			User:     getEnvOrDefault("DB_USER", "catalyst"),
-			Password: getEnvOrDefault("DB_PASSWORD", ""),
-			Name:     getEnvOrDefault("DB_NAME", "catalyst"),
-			SSLMode:  getEnvOrDefault("DB_SSL_MODE", "disable"),
+	if l := c.Log; l != nil {
+		switch l.Level {
+		case "trace", "debug", "info", "warn", "error", "fatal", "panic":
+		default:
+			fail("log.level", "unknown level %q", l.Level)
+		}
+		if l.Format != "json" && l.Format != "console" {
+			fail("log.format", "must be json or console")
 		}
 	}
 
-	return cfg
+	if ivt := c.IVT; ivt != nil {
+		if ivt.BlockThreshold < 0 || ivt.BlockThreshold > 100 {
+			fail("ivt.block_threshold", "must be between 0 and 100")
+		}
+		if ivt.IPListReloadInterval < 0 || ivt.BehaviorWindow < 0 || ivt.BehaviorTimeout < 0 {
+			fail("ivt", "durations must not be negative")
+		}
+		if ivt.MaxRequestsPerIP < 0 || ivt.MaxRequestsPerIFA < 0 || ivt.MaxRequestsPerUser < 0 || ivt.MaxDomainsPerIP < 0 {
+			fail("ivt", "max_* thresholds must not be negative")
+		}
+	}
+
+	if rl := c.RateLimit; rl != nil {
+		if rl.RequestsPerSecond <= 0 {
+			fail("rate_limit.requests_per_second", "must be positive")
+		}
+		if rl.BurstSize < 0 {
+			fail("rate_limit.burst_size", "must not be negative")
+		}
+		if rl.RedisTimeout <= 0 {
+			fail("rate_limit.redis_timeout", "must be positive")
+		}
+		if _, err := middleware.ParseTrustedProxies(rl.TrustedProxies); err != nil {
+			fail("rate_limit.trusted_proxies", "%v", err)
+		}
+	}
+
+	if cors := c.CORS; cors != nil {
+		for _, origin := range cors.AllowedOrigins {
+			if !validOrigin(origin) {
+				fail("cors.allowed_origins", "invalid origin %q", origin)
+			}
+		}
+	}
+
+	if auth := c.Auth; auth != nil {
+		if role := auth.LegacyKeyRole; role != "" && role != "none" && !middleware.ValidRole(role) {
+			fail("auth.legacy_key_role", "unknown role %q", role)
+		}
+		for _, scope := range auth.LegacyKeyScopes {
+			if !validScope(scope, auth.LegacyKeyScopes) {
+				fail("auth.legacy_key_scopes", "unknown scope %q", scope)
+			}
+		}
+	}
+
+	if pa := c.PublisherAuth; pa != nil {
+		if pa.RateLimitRPS < 0 {
+			fail("publisher_auth.rate_limit_rps", "must not be negative")
+		}
+		for name, tier := range pa.RateLimitTiers {
+			if tier.RequestsPerSecond < 0 || tier.BurstSize < 0 {
+				fail("publisher_auth.rate_limit_tiers."+name, "limits must not be negative")
+			}
+		}
+	}
+
+	if sl := c.SizeLimit; sl != nil && (sl.MaxRequestSize <= 0 || sl.MaxURLLength <= 0) {
+		fail("size_limit", "limits must be positive")
+	}
+
+	return errors.Join(errs...)
+}
+
+// validPort reports whether port is a TCP port number
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
+
+// validURL reports whether raw is an absolute URL with one of the given schemes
+func validURL(raw string, schemes ...string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			return true
+		}
+	}
+	return false
+}
+
+// validOrigin reports whether origin is "*", a "*.domain" wildcard or a scheme://host[:port] origin
+func validOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+	if strings.HasPrefix(origin, "*.") {
+		return len(origin) > 2 && !strings.ContainsAny(origin[2:], "/*")
+	}
+	u, err := url.Parse(origin)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && u.Path == "" && u.RawQuery == ""
+}
+
+// validScope reports whether scope is a known API key scope ("none" is only valid alone)
+func validScope(scope string, scopes []string) bool {
+	if scope == "none" {
+		return len(scopes) == 1
+	}
+	for _, s := range middleware.AllAPIKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
 }
 
 // ToExchangeConfig converts ServerConfig to exchange.Config
@@ -95,9 +526,203 @@ func (c *ServerConfig) ToExchangeConfig() *exchange.Config {
 		EventBufferSize:    100,
 		CurrencyConv:       c.CurrencyConversionEnabled,
 		DefaultCurrency:    c.DefaultCurrency,
+		FPD:                c.FPD,
+	}
+}
+
+// ToLoggerConfig converts the log section to logger.Config
+func (c *ServerConfig) ToLoggerConfig() logger.Config {
+	return logger.Config{
+		Level:      c.Log.Level,
+		Format:     c.Log.Format,
+		TimeFormat: time.RFC3339,
+	}
+}
+
+// ToPrivacyConfig converts the privacy section to middleware.PrivacyConfig
+// DisableGDPREnforcement is the older switch and still turns GDPR enforcement off.
+func (c *ServerConfig) ToPrivacyConfig() middleware.PrivacyConfig {
+	return middleware.PrivacyConfig{
+		EnforceGDPR:      c.Privacy.EnforceGDPR && !c.DisableGDPREnforcement,
+		EnforceCOPPA:     c.Privacy.EnforceCOPPA,
+		EnforceCCPA:      c.Privacy.EnforceCCPA,
+		GeoEnforcement:   c.Privacy.GeoEnforcement,
+		RequiredPurposes: middleware.RequiredPurposes,
+		StrictMode:       c.Privacy.StrictMode,
+		AnonymizeIP:      c.Privacy.AnonymizeIP,
+	}
+}
+
+// ToIVTConfig converts the IVT section to middleware.IVTConfig
+func (c *ServerConfig) ToIVTConfig() *middleware.IVTConfig {
+	ivt := c.IVT
+	patterns := ivt.SuspiciousUAPatterns
+	if patterns == nil {
+		patterns = middleware.DefaultSuspiciousUAPatterns
+	}
+	return &middleware.IVTConfig{
+		// Blocking relies on the scores monitoring computes
+		MonitoringEnabled:    ivt.MonitoringEnabled || ivt.BlockingEnabled,
+		BlockingEnabled:      ivt.BlockingEnabled,
+		BlockThreshold:       ivt.BlockThreshold,
+		CheckUserAgent:       ivt.CheckUserAgent,
+		CheckReferer:         ivt.CheckReferer,
+		CheckGeo:             ivt.CheckGeo,
+		CheckIPReputation:    ivt.CheckIPReputation,
+		CheckRateLimit:       ivt.CheckRateLimit,
+		AllowedCountries:     append([]string{}, ivt.AllowedCountries...),
+		BlockedCountries:     append([]string{}, ivt.BlockedCountries...),
+		SuspiciousUAPatterns: append([]string(nil), patterns...),
+		RequireReferer:       ivt.RequireReferer,
+		GeoIPDBPath:          ivt.GeoIPDBPath,
+		DatacenterListPath:   ivt.DatacenterListPath,
+		ProxyListPath:        ivt.ProxyListPath,
+		BotASNListPath:       ivt.BotASNListPath,
+		IPListReloadInterval: ivt.IPListReloadInterval,
+		CheckBehavior:        ivt.CheckBehavior,
+		BehaviorWindow:       ivt.BehaviorWindow,
+		BehaviorTimeout:      ivt.BehaviorTimeout,
+		MaxRequestsPerIP:     ivt.MaxRequestsPerIP,
+		MaxRequestsPerIFA:    ivt.MaxRequestsPerIFA,
+		MaxRequestsPerUser:   ivt.MaxRequestsPerUser,
+		MaxDomainsPerIP:      ivt.MaxDomainsPerIP,
+	}
+}
+
+// ToRateLimitConfig converts the rate limit section to middleware.RateLimitConfig
+func (c *ServerConfig) ToRateLimitConfig() *middleware.RateLimitConfig {
+	trustedProxies, _ := middleware.ParseTrustedProxies(c.RateLimit.TrustedProxies)
+	return &middleware.RateLimitConfig{
+		Enabled:           c.RateLimit.Enabled,
+		RequestsPerSecond: c.RateLimit.RequestsPerSecond,
+		BurstSize:         c.RateLimit.burstSize(),
+		CleanupInterval:   time.Minute,
+		WindowSize:        time.Second,
+		TrustedProxies:    trustedProxies,
+		TrustXFF:          len(trustedProxies) > 0,
+		RedisTimeout:      c.RateLimit.RedisTimeout,
 	}
 }
 
+// burstSize returns the configured burst, defaulting to twice the rate
+func (rl *RateLimitConfig) burstSize() int {
+	if rl.BurstSize == 0 {
+		return rl.RequestsPerSecond * 2
+	}
+	return rl.BurstSize
+}
+
+// ToCORSConfig converts the CORS section to middleware.CORSConfig
+func (c *ServerConfig) ToCORSConfig() *middleware.CORSConfig {
+	cors := middleware.DefaultCORSConfig()
+	cors.Enabled = c.CORS.Enabled
+	cors.AllowedOrigins = c.CORS.origins()
+	cors.AllowCredentials = c.CORS.AllowCredentials
+	return cors
+}
+
+// origins returns the allowed origins, with allow_all applying when none are listed
+func (cc *CORSConfig) origins() []string {
+	if len(cc.AllowedOrigins) == 0 && cc.AllowAll {
+		return []string{"*"}
+	}
+	return append([]string{}, cc.AllowedOrigins...)
+}
+
+// ToAuthConfig converts the auth section to middleware.AuthConfig
+func (c *ServerConfig) ToAuthConfig() *middleware.AuthConfig {
+	auth := middleware.DefaultAuthConfig()
+	auth.Enabled = c.Auth.Enabled
+	auth.APIKeys = make(map[string]string, len(c.Auth.APIKeys))
+	for key, publisherID := range c.Auth.APIKeys {
+		if publisherID == "" {
+			publisherID = "default"
+		}
+		auth.APIKeys[key] = publisherID
+	}
+	auth.RedisURL = c.RedisURL
+	auth.UseRedis = c.RedisURL != "" && c.Auth.UseRedis
+
+	switch {
+	case c.Auth.LegacyKeyScopes == nil:
+		auth.LegacyKeyScopes = nil
+	case len(c.Auth.LegacyKeyScopes) == 1 && c.Auth.LegacyKeyScopes[0] == "none":
+		auth.LegacyKeyScopes = []string{}
+	default:
+		auth.LegacyKeyScopes = append([]string{}, c.Auth.LegacyKeyScopes...)
+	}
+
+	switch c.Auth.LegacyKeyRole {
+	case "":
+		auth.LegacyKeyRole = middleware.RoleAdmin
+	case "none":
+		auth.LegacyKeyRole = ""
+	default:
+		auth.LegacyKeyRole = c.Auth.LegacyKeyRole
+	}
+	return auth
+}
+
+// ToAdminAuthConfig converts the auth section to middleware.AdminAuthConfig
+func (c *ServerConfig) ToAdminAuthConfig() *middleware.AdminAuthConfig {
+	admin := middleware.DefaultAdminAuthConfig()
+	admin.Enabled = c.Auth.AdminEnabled
+	return admin
+}
+
+// ToPublisherAuthConfig converts the publisher auth and IVT sections to middleware.PublisherAuthConfig
+func (c *ServerConfig) ToPublisherAuthConfig() *middleware.PublisherAuthConfig {
+	pa := c.PublisherAuth
+	registered := make(map[string]string, len(pa.RegisteredPublishers))
+	for publisherID, domains := range pa.RegisteredPublishers {
+		registered[publisherID] = domains
+	}
+	tiers := make(map[string]middleware.RateLimitTier, len(pa.RateLimitTiers))
+	for name, tier := range pa.RateLimitTiers {
+		tiers[name] = middleware.RateLimitTier{RequestsPerSecond: tier.RequestsPerSecond, BurstSize: tier.BurstSize}
+	}
+	return &middleware.PublisherAuthConfig{
+		Enabled:           pa.Enabled,
+		AllowUnregistered: pa.AllowUnregistered,
+		RegisteredPubs:    registered,
+		ValidateDomain:    pa.ValidateDomain,
+		RateLimitPerPub:   pa.RateLimitRPS,
+		RateLimitTiers:    tiers,
+		UseRedis:          pa.UseRedis,
+		IVT:               c.ToIVTConfig(),
+	}
+}
+
+// ToSecurityConfig converts the security headers section to middleware.SecurityConfig
+func (c *ServerConfig) ToSecurityConfig() *middleware.SecurityConfig {
+	sh := c.SecurityHeaders
+	return &middleware.SecurityConfig{
+		Enabled:                 sh.Enabled,
+		XFrameOptions:           sh.XFrameOptions,
+		XContentTypeOptions:     "nosniff",
+		XXSSProtection:          "1; mode=block",
+		ContentSecurityPolicy:   sh.ContentSecurityPolicy,
+		ReferrerPolicy:          sh.ReferrerPolicy,
+		StrictTransportSecurity: sh.HSTS,
+		PermissionsPolicy:       sh.PermissionsPolicy,
+		CacheControl:            sh.CacheControl,
+	}
+}
+
+// ToSizeLimitConfig converts the size limit section to middleware.SizeLimitConfig
+func (c *ServerConfig) ToSizeLimitConfig() *middleware.SizeLimitConfig {
+	return &middleware.SizeLimitConfig{
+		Enabled:      c.SizeLimit.Enabled,
+		MaxBodySize:  c.SizeLimit.MaxRequestSize,
+		MaxURLLength: c.SizeLimit.MaxURLLength,
+	}
+}
+
+// ToGeoEnrichmentConfig converts the geo enrichment section to middleware.GeoEnrichmentConfig
+func (c *ServerConfig) ToGeoEnrichmentConfig() *middleware.GeoEnrichmentConfig {
+	return &middleware.GeoEnrichmentConfig{Enabled: c.GeoEnrichment.Enabled}
+}
+
 // getEnvOrDefault returns the environment variable value or a default
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -114,3 +739,16 @@ func getEnvBoolOrDefault(key string, defaultValue bool) bool {
 	}
 	return value == "true" || value == "1" || value == "yes"
 }
+
+// getEnvDurationOrDefault returns the environment variable as a duration or a default
+func getEnvDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue
+	}
+	return d
+}
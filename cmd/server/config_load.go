@@ -0,0 +1,442 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Value sources reported by --check-config
+const (
+	sourceDefault = "default"
+	sourceFile    = "file"
+)
+
+// flagPaths maps command-line flags to the config paths they override
+var flagPaths = map[string]string{
+	"port":        "port",
+	"idr-url":     "idr_url",
+	"idr-enabled": "idr_enabled",
+	"admin-port":  "admin_port",
+	"timeout":     "timeout",
+}
+
+// configLoader builds the config tree; it is kept so SIGHUP can load again with the same inputs
+type configLoader struct {
+	file  string            // Config file path ("" = defaults and environment only)
+	flags map[string]string // Flags given on the command line, by name
+}
+
+// setFlags returns the config flags given on the command line
+func setFlags(fs *flag.FlagSet) map[string]string {
+	set := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		if _, ok := flagPaths[f.Name]; ok {
+			set[f.Name] = f.Value.String()
+		}
+	})
+	return set
+}
+
+// Load reads the config file, environment and flags, then validates the result
+func (l *configLoader) Load() (*ServerConfig, error) {
+	cfg, err := l.load()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return cfg, nil
+}
+
+// load builds the config tree without validating it
+func (l *configLoader) load() (*ServerConfig, error) {
+	cfg := DefaultServerConfig()
+	cfg.loader = l
+	cfg.sources = make(map[string]string)
+
+	fileKeys := map[string]bool{}
+	if l.file != "" {
+		keys, err := decodeConfigFile(l.file, cfg)
+		if err != nil {
+			return nil, err
+		}
+		fileKeys = keys
+
+		// A section set to null in the file keeps its defaults
+		defaults := DefaultServerConfig()
+		cfg.fillSections(defaults)
+		if cfg.DatabaseConfig == nil {
+			cfg.DatabaseConfig = defaults.DatabaseConfig
+		}
+		if cfg.AdminTLS == nil {
+			cfg.AdminTLS = defaults.AdminTLS
+		}
+	}
+
+	var errs []error
+	fields := configFields(cfg)
+	for _, f := range fields {
+		cfg.sources[f.path] = sourceDefault
+		if fileKeys[f.path] {
+			cfg.sources[f.path] = sourceFile
+		}
+		for _, name := range f.env {
+			value := os.Getenv(name)
+			if value == "" {
+				continue
+			}
+			if err := setConfigValue(f.value, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+			cfg.sources[f.path] = "env " + name
+			break
+		}
+	}
+
+	for name, value := range l.flags {
+		path := flagPaths[name]
+		for _, f := range fields {
+			if f.path != path {
+				continue
+			}
+			if err := setConfigValue(f.value, value); err != nil {
+				errs = append(errs, fmt.Errorf("-%s: %w", name, err))
+			}
+			cfg.sources[path] = "flag -" + name
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	cfg.finalize()
+	return cfg, nil
+}
+
+// decodeConfigFile decodes a YAML or JSON file over cfg, returning every key path it sets
+// Unknown keys are errors so typos don't silently fall back to defaults.
+func decodeConfigFile(path string, cfg *ServerConfig) (map[string]bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	// JSON is valid YAML, so one decoder handles both
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	keys := make(map[string]bool)
+	collectKeys(&root, "", keys)
+	return keys, nil
+}
+
+// collectKeys records the dotted path of every mapping key under node
+func collectKeys(node *yaml.Node, prefix string, keys map[string]bool) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			collectKeys(child, prefix, keys)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			path := joinPath(prefix, node.Content[i].Value)
+			keys[path] = true
+			collectKeys(node.Content[i+1], path, keys)
+		}
+	}
+}
+
+// fillSections sets nil sections from base, leaving the optional database and admin TLS alone
+func (c *ServerConfig) fillSections(base *ServerConfig) {
+	if c.Log == nil {
+		c.Log = base.Log
+	}
+	if c.Privacy == nil {
+		c.Privacy = base.Privacy
+	}
+	if c.IVT == nil {
+		c.IVT = base.IVT
+	}
+	if c.RateLimit == nil {
+		c.RateLimit = base.RateLimit
+	}
+	if c.CORS == nil {
+		c.CORS = base.CORS
+	}
+	if c.Auth == nil {
+		c.Auth = base.Auth
+	}
+	if c.PublisherAuth == nil {
+		c.PublisherAuth = base.PublisherAuth
+	}
+	if c.SecurityHeaders == nil {
+		c.SecurityHeaders = base.SecurityHeaders
+	}
+	if c.SizeLimit == nil {
+		c.SizeLimit = base.SizeLimit
+	}
+	if c.GeoEnrichment == nil {
+		c.GeoEnrichment = base.GeoEnrichment
+	}
+	if c.FPD == nil {
+		c.FPD = base.FPD
+	}
+}
+
+// finalize drops unconfigured optional sections and applies legacy switches
+func (c *ServerConfig) finalize() {
+	if c.DatabaseConfig.Host == "" {
+		c.DatabaseConfig = nil
+	}
+	// Incomplete TLS settings are reported by Validate
+	if tls := c.AdminTLS; tls.CertFile == "" && tls.KeyFile == "" && tls.ClientCAFile == "" {
+		c.AdminTLS = nil
+	}
+
+	// AUTH_ENABLED=false has always been the development switch for unregistered publishers too
+	if c.sources["auth.enabled"] == "env AUTH_ENABLED" && !c.Auth.Enabled {
+		c.PublisherAuth.AllowUnregistered = true
+		c.sources["publisher_auth.allow_unregistered"] = "env AUTH_ENABLED"
+	}
+}
+
+// withDefaultSections fills nil sections of a hand-built config from the defaults and environment
+func (c *ServerConfig) withDefaultSections() error {
+	if c.Log != nil && c.Privacy != nil && c.IVT != nil && c.RateLimit != nil && c.CORS != nil &&
+		c.Auth != nil && c.PublisherAuth != nil && c.SecurityHeaders != nil && c.SizeLimit != nil &&
+		c.GeoEnrichment != nil && c.FPD != nil {
+		return nil
+	}
+	base, err := (&configLoader{}).load()
+	if err != nil {
+		return err
+	}
+	c.fillSections(base)
+	return nil
+}
+
+// reparse loads the config again from the same file, environment and flags
+func (c *ServerConfig) reparse() (*ServerConfig, error) {
+	if c.loader == nil {
+		return nil, errors.New("config was not loaded by ParseConfig")
+	}
+	return c.loader.Load()
+}
+
+// configField is one setting in the config tree
+type configField struct {
+	path   string        // Dotted config path, e.g. "rate_limit.burst_size"
+	value  reflect.Value // Settable value in the tree
+	env    []string      // Environment variables, the first one set wins
+	secret bool          // Redacted in reports and logs
+	reload bool          // Applied on SIGHUP
+}
+
+// configFields flattens the config tree into its settings
+// Nil sections are skipped; reload tags on a section apply to all of its settings.
+func configFields(cfg *ServerConfig) []configField {
+	var fields []configField
+	walkConfig(reflect.ValueOf(cfg).Elem(), "", false, &fields)
+	return fields
+}
+
+// walkConfig appends the settings of the struct v to fields
+func walkConfig(v reflect.Value, prefix string, reload bool, fields *[]configField) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+		if !sf.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		path := joinPath(prefix, name)
+		fieldReload := reload || sf.Tag.Get("reload") == "true"
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Ptr && fv.Type().Elem().Kind() == reflect.Struct {
+			if !fv.IsNil() {
+				walkConfig(fv.Elem(), path, fieldReload, fields)
+			}
+			continue
+		}
+
+		var env []string
+		if tag := sf.Tag.Get("env"); tag != "" {
+			env = strings.Split(tag, ",")
+		}
+		*fields = append(*fields, configField{
+			path:   path,
+			value:  fv,
+			env:    env,
+			secret: sf.Tag.Get("secret") == "true",
+			reload: fieldReload,
+		})
+	}
+}
+
+// joinPath appends a key to a dotted config path
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// envDecoder is implemented by settings with their own environment syntax
+type envDecoder interface {
+	decodeEnv(value string) error
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setConfigValue parses an environment or flag value into a setting
+// Lists are comma-separated and maps are "key:value,key:value".
+func setConfigValue(v reflect.Value, value string) error {
+	if decoder, ok := v.Addr().Interface().(envDecoder); ok {
+		return decoder.decodeEnv(value)
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := parseConfigBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		v.SetInt(n)
+	case reflect.Slice:
+		v.Set(reflect.ValueOf(splitList(value)))
+	case reflect.Map:
+		pairs := make(map[string]string)
+		for _, entry := range splitList(value) {
+			key, val, _ := strings.Cut(entry, ":")
+			pairs[strings.TrimSpace(key)] = strings.TrimSpace(val)
+		}
+		v.Set(reflect.ValueOf(pairs))
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+// parseConfigBool parses true/false, 1/0 and yes/no, rejecting anything else
+func parseConfigBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "1", "yes":
+		return true, nil
+	case "false", "0", "no":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", value)
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(value string) []string {
+	list := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
+// String formats a tier as rps:burst
+func (t RateLimitTier) String() string {
+	return fmt.Sprintf("%d:%d", t.RequestsPerSecond, t.BurstSize)
+}
+
+// display formats a setting for reports and logs, redacting secrets
+func (f configField) display() string {
+	if f.secret && !f.value.IsZero() {
+		return "<redacted>"
+	}
+	return formatConfigValue(f.value)
+}
+
+// formatConfigValue formats a setting in its environment syntax
+func formatConfigValue(v reflect.Value) string {
+	switch {
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String()
+	case v.Kind() == reflect.String:
+		return strconv.Quote(v.String())
+	case v.Kind() == reflect.Slice:
+		if v.IsNil() {
+			return "<unset>"
+		}
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	case v.Kind() == reflect.Map:
+		items := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			items = append(items, fmt.Sprintf("%v:%v", iter.Key().Interface(), iter.Value().Interface()))
+		}
+		sort.Strings(items)
+		return "{" + strings.Join(items, ",") + "}"
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// WriteReport writes every effective setting with where it came from
+func (c *ServerConfig) WriteReport(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SETTING\tVALUE\tSOURCE\tRELOAD")
+	for _, f := range configFields(c) {
+		source := c.sources[f.path]
+		if source == "" {
+			source = sourceDefault
+		}
+		reload := "restart"
+		if f.reload {
+			reload = "sighup"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.path, f.display(), source, reload)
+	}
+	if c.DatabaseConfig == nil {
+		fmt.Fprintln(tw, "database\t<disabled>\tno host\trestart")
+	}
+	if c.AdminTLS == nil {
+		fmt.Fprintln(tw, "admin_tls\t<disabled>\tno certificate files\trestart")
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+)
+
+// writeConfigFile writes a config file into a temp dir and returns its path (test helper)
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+const testConfigYAML = `
+port: "9000"
+timeout: 750ms
+redis_url: redis://cache:6379/0
+database:
+  host: db.internal
+  password: hunter2
+ivt:
+  blocking_enabled: true
+  block_threshold: 80
+  allowed_countries: [US, GB]
+rate_limit:
+  requests_per_second: 200
+  trusted_proxies: [10.0.0.0/8, 127.0.0.1]
+cors:
+  allowed_origins: ["https://pub.example.com", "*.example.org"]
+publisher_auth:
+  registered_publishers:
+    pub1: example.com
+  rate_limit_tiers:
+    premium: {requests_per_second: 1000, burst_size: 2000}
+fpd:
+  eids_enabled: false
+`
+
+func TestParseConfig_File(t *testing.T) {
+	clearEnvVars(t)
+	t.Setenv("PBS_CONFIG_FILE", writeConfigFile(t, "pbs.yaml", testConfigYAML))
+	t.Setenv("RATE_LIMIT_RPS", "300")
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+
+	cfg := mustParseConfig(t)
+
+	if cfg.Port != "9000" || cfg.Timeout != 750*time.Millisecond {
+		t.Errorf("Expected port 9000 and timeout 750ms from file, got %s and %v", cfg.Port, cfg.Timeout)
+	}
+	if cfg.DatabaseConfig == nil || cfg.DatabaseConfig.Host != "db.internal" || cfg.DatabaseConfig.Port != "5432" {
+		t.Errorf("Expected file database host with default port, got %+v", cfg.DatabaseConfig)
+	}
+	if !cfg.IVT.BlockingEnabled || cfg.IVT.BlockThreshold != 80 || len(cfg.IVT.AllowedCountries) != 2 {
+		t.Errorf("Expected IVT settings from file, got %+v", cfg.IVT)
+	}
+	if !cfg.IVT.CheckUserAgent {
+		t.Error("Expected IVT settings missing from the file to keep their defaults")
+	}
+	if cfg.PublisherAuth.RateLimitTiers["premium"] != (RateLimitTier{RequestsPerSecond: 1000, BurstSize: 2000}) {
+		t.Errorf("Expected premium tier from file, got %+v", cfg.PublisherAuth.RateLimitTiers)
+	}
+	if cfg.FPD.EIDsEnabled || !cfg.FPD.Enabled {
+		t.Errorf("Expected FPD EIDs disabled and FPD still enabled, got %+v", cfg.FPD)
+	}
+
+	// The environment overrides the file
+	if cfg.RateLimit.RequestsPerSecond != 300 {
+		t.Errorf("Expected RATE_LIMIT_RPS to override the file, got %d", cfg.RateLimit.RequestsPerSecond)
+	}
+
+	wantSources := map[string]string{
+		"port":                            sourceFile,
+		"rate_limit.requests_per_second":  "env RATE_LIMIT_RPS",
+		"rate_limit.burst_size":           sourceDefault,
+		"publisher_auth.rate_limit_tiers": sourceFile,
+	}
+	for path, want := range wantSources {
+		if got := cfg.sources[path]; got != want {
+			t.Errorf("Expected %s from %q, got %q", path, want, got)
+		}
+	}
+}
+
+func TestParseConfig_JSONFile(t *testing.T) {
+	clearEnvVars(t)
+	path := writeConfigFile(t, "pbs.json", `{"port": "9001", "cors": {"allow_all": true}, "size_limit": {"max_request_size": 2048}}`)
+
+	cfg, err := (&configLoader{file: path}).Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Port != "9001" || cfg.SizeLimit.MaxRequestSize != 2048 {
+		t.Errorf("Expected JSON values, got port %s and max request size %d", cfg.Port, cfg.SizeLimit.MaxRequestSize)
+	}
+	if origins := cfg.ToCORSConfig().AllowedOrigins; len(origins) != 1 || origins[0] != "*" {
+		t.Errorf("Expected allow_all to allow every origin, got %v", origins)
+	}
+}
+
+func TestConfigLoader_FlagsOverrideEnv(t *testing.T) {
+	clearEnvVars(t)
+	t.Setenv("PBS_PORT", "7000")
+	t.Setenv("PBS_TIMEOUT", "2s")
+
+	cfg, err := (&configLoader{flags: map[string]string{"port": "7001", "idr-enabled": "false"}}).Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Port != "7001" || cfg.sources["port"] != "flag -port" {
+		t.Errorf("Expected port from flag, got %s from %s", cfg.Port, cfg.sources["port"])
+	}
+	if cfg.IDREnabled {
+		t.Error("Expected IDR disabled by flag")
+	}
+	if cfg.Timeout != 2*time.Second {
+		t.Errorf("Expected timeout from env without a flag, got %v", cfg.Timeout)
+	}
+}
+
+func TestConfigLoader_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		wantErr []string
+	}{
+		{
+			name:    "unknown key",
+			file:    "rate_limt:\n  enabled: false\n",
+			wantErr: []string{"field rate_limt not found"},
+		},
+		{
+			name:    "bad env values",
+			env:     map[string]string{"IDR_ENABLED": "maybe", "RATE_LIMIT_RPS": "fast", "PUBLISHER_RATE_LIMIT_TIERS": "gold"},
+			wantErr: []string{"IDR_ENABLED", "RATE_LIMIT_RPS", "PUBLISHER_RATE_LIMIT_TIERS"},
+		},
+		{
+			name: "invalid values",
+			file: `
+port: "8000"
+admin_port: "8000"
+ivt: {block_threshold: 150}
+rate_limit: {requests_per_second: 0, trusted_proxies: [not-an-ip]}
+cors: {allowed_origins: ["example.com/path"]}
+auth: {legacy_key_role: root, legacy_key_scopes: [auction, everything]}
+`,
+			wantErr: []string{
+				"admin_port: must differ",
+				"ivt.block_threshold",
+				"rate_limit.requests_per_second",
+				"rate_limit.trusted_proxies",
+				`invalid origin "example.com/path"`,
+				`unknown role "root"`,
+				`unknown scope "everything"`,
+			},
+		},
+		{
+			name:    "incomplete admin TLS",
+			env:     map[string]string{"ADMIN_PORT": "9100", "ADMIN_TLS_CERT_FILE": "/etc/tls/admin.crt"},
+			wantErr: []string{"cert_file and key_file are both required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnvVars(t)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			loader := &configLoader{}
+			if tt.file != "" {
+				loader.file = writeConfigFile(t, "pbs.yaml", tt.file)
+			}
+
+			_, err := loader.Load()
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected error to mention %q, got: %v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigLoader_LegacyEnv(t *testing.T) {
+	clearEnvVars(t)
+	t.Setenv("AUTH_ENABLED", "false")
+	t.Setenv("PBS_DEV_MODE", "true")
+	t.Setenv("API_KEYS", "key1:pub1,key2")
+	t.Setenv("API_KEY_LEGACY_ROLE", "none")
+	t.Setenv("API_KEY_LEGACY_SCOPES", "none")
+	t.Setenv("PBS_DISABLE_GDPR_ENFORCEMENT", "true")
+	t.Setenv("PUBLISHER_RATE_LIMIT_TIERS", "basic:50,standard:100:200")
+
+	cfg, err := (&configLoader{}).Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if !cfg.PublisherAuth.AllowUnregistered {
+		t.Error("Expected AUTH_ENABLED=false to allow unregistered publishers")
+	}
+	if origins := cfg.ToCORSConfig().AllowedOrigins; len(origins) != 1 || origins[0] != "*" {
+		t.Errorf("Expected PBS_DEV_MODE to allow every origin, got %v", origins)
+	}
+
+	auth := cfg.ToAuthConfig()
+	if auth.APIKeys["key1"] != "pub1" || auth.APIKeys["key2"] != "default" {
+		t.Errorf("Expected key publishers pub1 and default, got %v", auth.APIKeys)
+	}
+	if auth.LegacyKeyRole != "" || auth.LegacyKeyScopes == nil || len(auth.LegacyKeyScopes) != 0 {
+		t.Errorf("Expected legacy keys without role or scopes, got %q and %v", auth.LegacyKeyRole, auth.LegacyKeyScopes)
+	}
+
+	if cfg.ToPrivacyConfig().EnforceGDPR {
+		t.Error("Expected PBS_DISABLE_GDPR_ENFORCEMENT to turn GDPR enforcement off")
+	}
+
+	tiers := cfg.ToPublisherAuthConfig().RateLimitTiers
+	if tiers["basic"] != (middleware.RateLimitTier{RequestsPerSecond: 50}) || tiers["standard"] != (middleware.RateLimitTier{RequestsPerSecond: 100, BurstSize: 200}) {
+		t.Errorf("Expected tiers from env, got %+v", tiers)
+	}
+}
+
+func TestServerConfig_Conversions(t *testing.T) {
+	cfg := DefaultServerConfig()
+	cfg.RateLimit.TrustedProxies = []string{"10.0.0.0/8"}
+	cfg.IVT.MonitoringEnabled = false
+	cfg.IVT.BlockingEnabled = true
+
+	rl := cfg.ToRateLimitConfig()
+	if rl.BurstSize != 2000 {
+		t.Errorf("Expected burst to default to twice the rate, got %d", rl.BurstSize)
+	}
+	if !rl.TrustXFF || len(rl.TrustedProxies) != 1 {
+		t.Errorf("Expected X-Forwarded-For trusted from one proxy range, got %v", rl.TrustedProxies)
+	}
+
+	ivt := cfg.ToIVTConfig()
+	if !ivt.MonitoringEnabled {
+		t.Error("Expected blocking to enable monitoring")
+	}
+	if len(ivt.SuspiciousUAPatterns) != len(middleware.DefaultSuspiciousUAPatterns) {
+		t.Errorf("Expected built-in UA patterns, got %v", ivt.SuspiciousUAPatterns)
+	}
+
+	auth := cfg.ToAuthConfig()
+	if auth.LegacyKeyRole != middleware.RoleAdmin || auth.LegacyKeyScopes != nil {
+		t.Errorf("Expected legacy keys with admin role and all scopes, got %q and %v", auth.LegacyKeyRole, auth.LegacyKeyScopes)
+	}
+	if auth.UseRedis {
+		t.Error("Expected Redis key lookups off without a Redis URL")
+	}
+
+	if pa := cfg.ToPublisherAuthConfig(); pa.IVT == nil || pa.RateLimitPerPub != 100 {
+		t.Errorf("Expected publisher auth with IVT config and 100 RPS, got %+v", pa)
+	}
+}
+
+func TestServerConfig_WriteReport(t *testing.T) {
+	clearEnvVars(t)
+	t.Setenv("IDR_API_KEY", "super-secret-key")
+	t.Setenv("DB_HOST", "db.internal")
+	t.Setenv("DB_PASSWORD", "hunter2")
+
+	cfg, err := (&configLoader{}).Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := cfg.WriteReport(&buf); err != nil {
+		t.Fatalf("WriteReport failed: %v", err)
+	}
+	report := buf.String()
+
+	for _, secret := range []string{"super-secret-key", "hunter2"} {
+		if strings.Contains(report, secret) {
+			t.Errorf("Expected %q redacted from the report", secret)
+		}
+	}
+	for _, want := range []string{"env IDR_API_KEY", `"db.internal"`, "rate_limit.requests_per_second", "admin_tls"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected report to contain %q", want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"os"
+	"slices"
 	"testing"
 	"time"
 )
@@ -14,7 +15,7 @@ func TestParseConfig_Defaults(t *testing.T) {
 	// Reset flags before each test
 	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
 
-	cfg := ParseConfig()
+	cfg := mustParseConfig(t)
 
 	if cfg.Port != "8000" {
 		t.Errorf("Expected default port '8000', got '%s'", cfg.Port)
@@ -103,6 +104,25 @@ func TestParseConfig_EnvironmentOverrides(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "Admin listener with mTLS",
+			envVars: map[string]string{
+				"ADMIN_PORT":                "9100",
+				"ADMIN_TLS_CERT_FILE":       "/etc/tls/admin.crt",
+				"ADMIN_TLS_KEY_FILE":        "/etc/tls/admin.key",
+				"ADMIN_TLS_CLIENT_CA_FILE":  "/etc/tls/clients.pem",
+				"ADMIN_TLS_RELOAD_INTERVAL": "5m",
+			},
+			validate: func(t *testing.T, cfg *ServerConfig) {
+				if cfg.AdminPort != "9100" {
+					t.Errorf("Expected admin port '9100', got '%s'", cfg.AdminPort)
+				}
+				want := AdminTLSConfig{CertFile: "/etc/tls/admin.crt", KeyFile: "/etc/tls/admin.key", ClientCAFile: "/etc/tls/clients.pem", ReloadInterval: 5 * time.Minute}
+				if cfg.AdminTLS == nil || *cfg.AdminTLS != want {
+					t.Errorf("Expected admin TLS %+v, got %+v", want, cfg.AdminTLS)
+				}
+			},
+		},
 		{
 			name: "Redis URL",
 			envVars: map[string]string{
@@ -160,7 +180,7 @@ func TestParseConfig_EnvironmentOverrides(t *testing.T) {
 			// Reset flags
 			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
 
-			cfg := ParseConfig()
+			cfg := mustParseConfig(t)
 			tt.validate(t, cfg)
 		})
 	}
@@ -180,7 +200,7 @@ func TestParseConfig_DatabaseConfig(t *testing.T) {
 	// Reset flags
 	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
 
-	cfg := ParseConfig()
+	cfg := mustParseConfig(t)
 
 	if cfg.DatabaseConfig == nil {
 		t.Fatal("Expected database config to be set")
@@ -219,7 +239,7 @@ func TestParseConfig_DatabaseConfig_NotSet(t *testing.T) {
 	// Reset flags
 	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
 
-	cfg := ParseConfig()
+	cfg := mustParseConfig(t)
 
 	if cfg.DatabaseConfig != nil {
 		t.Error("Expected no database config when DB_HOST is not set")
@@ -235,7 +255,7 @@ func TestParseConfig_DatabaseConfig_Defaults(t *testing.T) {
 	// Reset flags
 	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
 
-	cfg := ParseConfig()
+	cfg := mustParseConfig(t)
 
 	if cfg.DatabaseConfig == nil {
 		t.Fatal("Expected database config to be set")
@@ -318,6 +338,18 @@ func TestToExchangeConfig(t *testing.T) {
 	}
 }
 
+func TestDefaultServerConfig_SharedIDEIDSource(t *testing.T) {
+	t.Setenv("SHAREDID_ENABLED", "")
+	if slices.Contains(DefaultServerConfig().FPD.EIDSources, "pubcid.org") {
+		t.Error("Expected pubcid.org not allowed by default without SharedID minting")
+	}
+
+	t.Setenv("SHAREDID_ENABLED", "true")
+	if !slices.Contains(DefaultServerConfig().FPD.EIDSources, "pubcid.org") {
+		t.Error("Expected pubcid.org allowed when SharedID minting is enabled")
+	}
+}
+
 func TestGetEnvOrDefault(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -453,6 +485,16 @@ func TestGetEnvBoolOrDefault(t *testing.T) {
 	}
 }
 
+// mustParseConfig parses the config, failing the test on error (test helper)
+func mustParseConfig(t *testing.T) *ServerConfig {
+	t.Helper()
+	cfg, err := ParseConfig()
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	return cfg
+}
+
 // Helper function to clear relevant environment variables
 func clearEnvVars(t *testing.T) {
 	t.Helper()
@@ -472,6 +514,13 @@ func clearEnvVars(t *testing.T) {
 		"CURRENCY_CONVERSION_ENABLED",
 		"PBS_DISABLE_GDPR_ENFORCEMENT",
 		"PBS_HOST_URL",
+		"ADMIN_PORT",
+		"ADMIN_TLS_CERT_FILE",
+		"ADMIN_TLS_KEY_FILE",
+		"ADMIN_TLS_CLIENT_CA_FILE",
+		"ADMIN_TLS_RELOAD_INTERVAL",
+		"PBS_CONFIG_FILE",
+		"PBS_TIMEOUT",
 	}
 
 	for _, key := range envVars {
@@ -500,7 +549,7 @@ func TestParseConfig_AllEnvironmentVariables(t *testing.T) {
 
 	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
 
-	cfg := ParseConfig()
+	cfg := mustParseConfig(t)
 
 	// Verify all values
 	if cfg.Port != "9090" {
@@ -574,7 +623,7 @@ func TestParseConfig_MixedFlagsAndEnv(t *testing.T) {
 
 	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
 
-	cfg := ParseConfig()
+	cfg := mustParseConfig(t)
 
 	// Port should come from env
 	if cfg.Port != "7777" {
@@ -3,6 +3,8 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
@@ -12,11 +14,20 @@ import (
 )
 
 func main() {
-	// Parse configuration from flags and environment
-	cfg := ParseConfig()
+	checkConfig := flag.Bool("check-config", false, "Validate the config, print the effective values and exit")
+
+	// Parse configuration from the config file, environment and flags
+	cfg, err := ParseConfig()
+	if *checkConfig {
+		os.Exit(runCheckConfig(cfg, err))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
 	// Initialize structured logger
-	logger.Init(logger.DefaultConfig())
+	logger.Init(cfg.ToLoggerConfig())
 	log := logger.Log
 
 	// Create server
@@ -32,10 +43,19 @@ func main() {
 		}
 	}()
 
-	// Wait for shutdown signal
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	sig := <-quit
+	// Reload on SIGHUP until a shutdown signal
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	sig := <-signals
+	for sig == syscall.SIGHUP {
+		log.Info().Msg("SIGHUP received, reloading config")
+		if next, err := cfg.reparse(); err != nil {
+			log.Error().Err(err).Msg("Config reload failed, keeping the running config")
+		} else {
+			server.Reload(next)
+		}
+		sig = <-signals
+	}
 
 	log.Info().Str("signal", sig.String()).Msg("Shutdown signal received")
 
@@ -47,3 +67,17 @@ func main() {
 		log.Fatal().Err(err).Msg("Server forced to shutdown")
 	}
 }
+
+// runCheckConfig prints the effective config, or why it is invalid, and returns the exit code
+func runCheckConfig(cfg *ServerConfig, err error) int {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config check failed:\n%v\n", err)
+		return 1
+	}
+	if err := cfg.WriteReport(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Println("config OK")
+	return 0
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// Reload applies the reloadable settings of cfg to the running server
+// Changed settings that need a restart are logged and keep their running values, so the
+// stored config always describes what the server is doing.
+func (s *Server) Reload(cfg *ServerConfig) {
+	log := logger.Log
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	current := s.config
+	if err := cfg.withDefaultSections(); err != nil {
+		log.Error().Err(err).Msg("Config reload failed, keeping the running config")
+		return
+	}
+
+	// Optional sections appearing or disappearing need a restart too
+	if (cfg.DatabaseConfig == nil) != (current.DatabaseConfig == nil) {
+		log.Warn().Str("setting", "database").Msg("Config change requires a restart, keeping the running value")
+	}
+	if (cfg.AdminTLS == nil) != (current.AdminTLS == nil) {
+		log.Warn().Str("setting", "admin_tls").Msg("Config change requires a restart, keeping the running value")
+	}
+	cfg.DatabaseConfig, cfg.AdminTLS = current.DatabaseConfig, current.AdminTLS
+
+	running := make(map[string]configField)
+	for _, f := range configFields(current) {
+		running[f.path] = f
+	}
+
+	changed := make(map[string]bool) // By top-level key
+	for _, f := range configFields(cfg) {
+		old, ok := running[f.path]
+		if !ok || reflect.DeepEqual(old.value.Interface(), f.value.Interface()) {
+			continue
+		}
+		if !f.reload {
+			log.Warn().
+				Str("setting", f.path).
+				Str("running", old.display()).
+				Str("configured", f.display()).
+				Msg("Config change requires a restart, keeping the running value")
+			f.value.Set(old.value)
+			continue
+		}
+		log.Info().
+			Str("setting", f.path).
+			Str("from", old.display()).
+			Str("to", f.display()).
+			Msg("Config setting reloaded")
+		changed[strings.SplitN(f.path, ".", 2)[0]] = true
+	}
+
+	s.applyReload(cfg, changed)
+	s.config = cfg
+	log.Info().Int("sections", len(changed)).Msg("Config reloaded")
+}
+
+// applyReload pushes the changed sections to the components that own them
+func (s *Server) applyReload(cfg *ServerConfig, changed map[string]bool) {
+	log := logger.Log
+
+	if changed["timeout"] && s.exchange != nil {
+		s.exchange.SetDefaultTimeout(cfg.Timeout)
+		if cfg.Timeout > s.bidderTimeout {
+			log.Warn().
+				Dur("timeout", cfg.Timeout).
+				Dur("bidder_timeout", s.bidderTimeout).
+				Msg("Bidder calls stay capped at the startup timeout until restart")
+		}
+	}
+	if changed["fpd"] && s.exchange != nil {
+		s.exchange.UpdateFPDConfig(cfg.FPD)
+	}
+	if changed["ivt"] && s.publisherAuth != nil {
+		s.publisherAuth.SetIVTConfig(cfg.ToIVTConfig())
+	}
+	if changed["rate_limit"] && s.rateLimiter != nil {
+		s.rateLimiter.SetEnabled(cfg.RateLimit.Enabled)
+		s.rateLimiter.SetRPS(cfg.RateLimit.RequestsPerSecond)
+		s.rateLimiter.SetBurstSize(cfg.RateLimit.burstSize())
+	}
+	if changed["cors"] && s.cors != nil {
+		s.cors.SetEnabled(cfg.CORS.Enabled)
+		s.cors.SetAllowedOrigins(cfg.CORS.origins())
+	}
+	if changed["security_headers"] && s.security != nil {
+		s.security.SetEnabled(cfg.SecurityHeaders.Enabled)
+		s.security.SetHSTS(cfg.SecurityHeaders.HSTS)
+		s.security.SetCSP(cfg.SecurityHeaders.ContentSecurityPolicy)
+	}
+	if changed["size_limit"] && s.sizeLimiter != nil {
+		s.sizeLimiter.SetEnabled(cfg.SizeLimit.Enabled)
+		s.sizeLimiter.SetMaxBodySize(cfg.SizeLimit.MaxRequestSize)
+		s.sizeLimiter.SetMaxURLLength(cfg.SizeLimit.MaxURLLength)
+	}
+	if changed["geo_enrichment"] && s.geoEnrichment != nil {
+		s.geoEnrichment.SetEnabled(cfg.GeoEnrichment.Enabled)
+	}
+}
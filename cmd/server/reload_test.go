@@ -0,0 +1,169 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/exchange"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+)
+
+// newReloadTestServer wires the reloadable components without NewServer's global metrics (test helper)
+func newReloadTestServer(t *testing.T, cfg *ServerConfig) *Server {
+	t.Helper()
+	s := &Server{
+		config:        cfg,
+		exchange:      exchange.New(adapters.NewRegistry(), cfg.ToExchangeConfig()),
+		rateLimiter:   middleware.NewRateLimiter(cfg.ToRateLimitConfig()),
+		cors:          middleware.NewCORS(cfg.ToCORSConfig()),
+		security:      middleware.NewSecurity(cfg.ToSecurityConfig()),
+		publisherAuth: middleware.NewPublisherAuth(cfg.ToPublisherAuthConfig()),
+		sizeLimiter:   middleware.NewSizeLimiter(cfg.ToSizeLimitConfig()),
+		geoEnrichment: middleware.NewGeoEnrichment(cfg.ToGeoEnrichmentConfig(), nil),
+	}
+	s.bidderTimeout = s.exchange.DefaultTimeout()
+	t.Cleanup(s.rateLimiter.Stop)
+	return s
+}
+
+// corsAllows reports whether the CORS middleware echoes origin back (test helper)
+func corsAllows(s *Server, origin string) bool {
+	handler := s.cors.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil)
+	req.Header.Set("Origin", origin)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Header().Get("Access-Control-Allow-Origin") == origin
+}
+
+func TestServer_Reload(t *testing.T) {
+	clearEnvVars(t)
+	cfg, err := (&configLoader{}).Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	s := newReloadTestServer(t, cfg)
+
+	if corsAllows(s, "https://pub.example.com") {
+		t.Fatal("Expected origin rejected before reload")
+	}
+
+	next, err := (&configLoader{}).Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	next.Timeout = 600 * time.Millisecond
+	next.Port = "9999"
+	next.FPD.EIDsEnabled = false
+	next.IVT.BlockThreshold = 90
+	next.IVT.GeoIPDBPath = "/var/lib/GeoIP/GeoLite2-City.mmdb"
+	next.RateLimit.RequestsPerSecond = 50
+	next.RateLimit.RedisTimeout = time.Second
+	next.CORS.AllowedOrigins = []string{"https://pub.example.com"}
+	next.SecurityHeaders.HSTS = ""
+	next.SizeLimit.MaxURLLength = 2048
+	next.DatabaseConfig = &DatabaseConfig{Host: "db.internal"}
+
+	s.Reload(next)
+
+	if got := s.exchange.DefaultTimeout(); got != 600*time.Millisecond {
+		t.Errorf("Expected exchange timeout 600ms, got %v", got)
+	}
+	if s.exchange.GetFPDConfig().EIDsEnabled {
+		t.Error("Expected FPD EIDs disabled")
+	}
+	if got := s.publisherAuth.GetIVTConfig().BlockThreshold; got != 90 {
+		t.Errorf("Expected IVT block threshold 90, got %d", got)
+	}
+	if !corsAllows(s, "https://pub.example.com") {
+		t.Error("Expected reloaded origin allowed")
+	}
+	if got := s.security.GetConfig().StrictTransportSecurity; got != "" {
+		t.Errorf("Expected HSTS removed, got %q", got)
+	}
+	if got := s.sizeLimiter.GetConfig().MaxURLLength; got != 2048 {
+		t.Errorf("Expected max URL length 2048, got %d", got)
+	}
+
+	// Restart-only settings keep their running values
+	if s.config.Port != "8000" {
+		t.Errorf("Expected running port kept, got %s", s.config.Port)
+	}
+	if s.config.IVT.GeoIPDBPath != "" {
+		t.Errorf("Expected running GeoIP path kept, got %q", s.config.IVT.GeoIPDBPath)
+	}
+	if s.config.RateLimit.RedisTimeout != 5*time.Millisecond {
+		t.Errorf("Expected running Redis timeout kept, got %v", s.config.RateLimit.RedisTimeout)
+	}
+	if s.config.DatabaseConfig != nil {
+		t.Error("Expected database to stay disabled until restart")
+	}
+
+	// Reloadable settings are stored
+	if s.config.RateLimit.RequestsPerSecond != 50 || s.config.Timeout != 600*time.Millisecond {
+		t.Errorf("Expected reloaded values stored, got %d RPS and %v", s.config.RateLimit.RequestsPerSecond, s.config.Timeout)
+	}
+}
+
+func TestServer_ReloadUnchanged(t *testing.T) {
+	clearEnvVars(t)
+	cfg, err := (&configLoader{}).Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	s := newReloadTestServer(t, cfg)
+	fpdBefore := s.exchange.GetFPDConfig()
+
+	next, err := (&configLoader{}).Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	s.Reload(next)
+
+	// Nothing changed, so nothing is rebuilt
+	if s.exchange.GetFPDConfig() != fpdBefore {
+		t.Error("Expected FPD config untouched when unchanged")
+	}
+	if s.config != next {
+		t.Error("Expected the new config stored")
+	}
+}
+
+func TestServerConfig_Reparse(t *testing.T) {
+	clearEnvVars(t)
+	path := writeConfigFile(t, "pbs.yaml", "rate_limit: {requests_per_second: 100}\n")
+	cfg, err := (&configLoader{file: path, flags: map[string]string{"port": "9000"}}).Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	writeFile := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to rewrite config file: %v", err)
+		}
+	}
+
+	writeFile("rate_limit: {requests_per_second: 200}\n")
+	next, err := cfg.reparse()
+	if err != nil {
+		t.Fatalf("reparse failed: %v", err)
+	}
+	if next.RateLimit.RequestsPerSecond != 200 || next.Port != "9000" {
+		t.Errorf("Expected new file value and original flag, got %d RPS on port %s", next.RateLimit.RequestsPerSecond, next.Port)
+	}
+
+	// A broken file is reported and the caller keeps its config
+	writeFile("rate_limit: {requests_per_second: -1}\n")
+	if _, err := cfg.reparse(); err == nil {
+		t.Error("Expected an invalid file to fail")
+	}
+
+	if _, err := (&ServerConfig{}).reparse(); err == nil {
+		t.Error("Expected a hand-built config to have nothing to reparse")
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/thenexusengine/tne_springwire/internal/adapters"
@@ -19,6 +22,7 @@ import (
 	"github.com/thenexusengine/tne_springwire/internal/metrics"
 	"github.com/thenexusengine/tne_springwire/internal/middleware"
 	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/internal/usersync"
 	"github.com/thenexusengine/tne_springwire/pkg/logger"
 	"github.com/thenexusengine/tne_springwire/pkg/redis"
 )
@@ -32,11 +36,37 @@ type Server struct {
 	rateLimiter *middleware.RateLimiter
 	db          *storage.BidderStore
 	publisher   *storage.PublisherStore
+	audit       *storage.AuditStore
 	redisClient *redis.Client
+
+	// Cache invalidations from other instances (nil without Redis)
+	invalidation *redis.InvalidationBus
+
+	syncerReloader *endpoints.SyncerReloader
+	bidderAdmin    *endpoints.BidderAdminHandler
+
+	// Admin listener (nil unless ADMIN_PORT is set)
+	adminServer *http.Server
+	adminMux    *http.ServeMux
+	adminTLS    *adminTLSReloader
+
+	// Middleware with settings applied by Reload
+	cors          *middleware.CORS
+	security      *middleware.Security
+	publisherAuth *middleware.PublisherAuth
+	sizeLimiter   *middleware.SizeLimiter
+	geoEnrichment *middleware.GeoEnrichment
+
+	// reloadMu serializes Reload; bidderTimeout caps bidder calls however far the timeout is raised
+	reloadMu      sync.Mutex
+	bidderTimeout time.Duration
 }
 
 // NewServer creates a new PBS server instance
 func NewServer(cfg *ServerConfig) (*Server, error) {
+	if err := cfg.withDefaultSections(); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
 	s := &Server{
 		config: cfg,
 	}
@@ -63,6 +93,11 @@ func (s *Server) initialize() error {
 	s.metrics = metrics.NewMetrics("pbs")
 	log.Info().Msg("Prometheus metrics enabled")
 
+	// Configure uids cookie signing before any handler reads cookies
+	if err := s.initCookieSecurity(); err != nil {
+		return err
+	}
+
 	// Initialize database if configured
 	if err := s.initDatabase(); err != nil {
 		// Database failures are non-fatal, log and continue
@@ -81,6 +116,11 @@ func (s *Server) initialize() error {
 		log.Warn().Err(err).Msg("Redis initialization failed, continuing with reduced functionality")
 	}
 
+	// Admin endpoints get their own listener when ADMIN_PORT is set
+	if err := s.initAdminServer(); err != nil {
+		return err
+	}
+
 	// List registered bidders
 	bidders := adapters.DefaultRegistry.ListBidders()
 	log.Info().
@@ -94,6 +134,32 @@ func (s *Server) initialize() error {
 	return nil
 }
 
+// initCookieSecurity configures signing and encryption of the uids cookie
+func (s *Server) initCookieSecurity() error {
+	log := logger.Log
+
+	cfg, err := usersync.DefaultSecurityConfig()
+	if err != nil {
+		return fmt.Errorf("invalid uids cookie configuration: %w", err)
+	}
+	if err := usersync.ConfigureSecurity(cfg, s.metrics); err != nil {
+		return fmt.Errorf("invalid uids cookie configuration: %w", err)
+	}
+
+	if len(cfg.SigningKeys) == 0 {
+		log.Warn().Msg("UIDS_COOKIE_SIGNING_KEYS not set, uids cookie is unsigned")
+		return nil
+	}
+
+	log.Info().
+		Str("signing_key", cfg.SigningKeys[0].ID).
+		Int("verification_keys", len(cfg.SigningKeys)).
+		Bool("encrypted", len(cfg.EncryptionKey) > 0).
+		Time("legacy_accept_until", cfg.LegacyAcceptUntil).
+		Msg("uids cookie signing enabled")
+	return nil
+}
+
 // initDatabase initializes database connections
 func (s *Server) initDatabase() error {
 	log := logger.Log
@@ -119,6 +185,7 @@ func (s *Server) initDatabase() error {
 
 	s.db = storage.NewBidderStore(dbConn)
 	s.publisher = storage.NewPublisherStore(dbConn)
+	s.audit = storage.NewAuditStore(dbConn)
 
 	// Test connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -151,20 +218,15 @@ func (s *Server) initDatabase() error {
 func (s *Server) initMiddleware() {
 	log := logger.Log
 
-	// Initialize PublisherAuth first to check if it's enabled
-	publisherAuth := middleware.NewPublisherAuth(middleware.DefaultPublisherAuthConfig())
-
-	// Build Auth config with conditional bypass for /openrtb2/auction
-	authConfig := middleware.DefaultAuthConfig()
-	if publisherAuth.IsEnabled() {
-		authConfig.BypassPaths = append(authConfig.BypassPaths, "/openrtb2/auction")
+	// With PublisherAuth enabled, /openrtb2/auction bypasses general Auth
+	if s.config.PublisherAuth.Enabled {
 		log.Info().Msg("PublisherAuth enabled - /openrtb2/auction bypasses general Auth")
 	} else {
 		log.Warn().Msg("PublisherAuth disabled - /openrtb2/auction requires API key auth")
 	}
 
 	// Store rate limiter for graceful shutdown
-	s.rateLimiter = middleware.NewRateLimiter(middleware.DefaultRateLimitConfig())
+	s.rateLimiter = middleware.NewRateLimiter(s.config.ToRateLimitConfig())
 
 	log.Info().Msg("Middleware initialized")
 }
@@ -175,6 +237,7 @@ func (s *Server) initExchange() {
 
 	// Create exchange with default registry
 	s.exchange = exchange.New(adapters.DefaultRegistry, s.config.ToExchangeConfig())
+	s.bidderTimeout = s.exchange.DefaultTimeout()
 
 	// Wire up metrics for margin tracking
 	s.exchange.SetMetrics(s.metrics)
@@ -197,6 +260,9 @@ func (s *Server) initRedis() error {
 		return err
 	}
 
+	// Admin writes on any instance evict the in-process caches on every other instance
+	s.invalidation = redis.NewInvalidationBus(s.redisClient, redis.InvalidationChannel)
+
 	log.Info().Msg("Redis client initialized")
 	return nil
 }
@@ -216,15 +282,110 @@ func (s *Server) initHandlers() {
 	setuidHandler := endpoints.NewSetUIDHandler(cookieSyncHandler.ListBidders())
 	optoutHandler := endpoints.NewOptOutHandler()
 
+	// TCF vendor consent checks for user syncs use the adapter GVL IDs
+	gvlVendorIDs := func(bidderCode string) int {
+		if awi, ok := adapters.DefaultRegistry.Get(bidderCode); ok {
+			return awi.Info.GVLVendorID
+		}
+		return 0
+	}
+	cookieSyncHandler.SetGVLVendorIDs(gvlVendorIDs)
+	setuidHandler.SetGVLVendorIDs(cookieSyncHandler.GVLVendorID)
+
+	// Syncer definitions from the bidders table override the built-in sync URLs
+	if s.db != nil {
+		s.syncerReloader = endpoints.NewSyncerReloader(s.db, cookieSyncHandler, setuidHandler, endpoints.DefaultSyncerReloadInterval())
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if loaded, err := s.syncerReloader.Reload(ctx); err != nil {
+			log.Warn().Err(err).Msg("Failed to load syncer definitions from database, using built-in syncers")
+		} else {
+			log.Info().Int("loaded", loaded).Msg("Syncer definitions loaded from PostgreSQL")
+		}
+		cancel()
+		s.syncerReloader.Start()
+
+		// Syncer definitions come from the bidders table too, so bidder writes reload them early
+		reloadSyncers := func(ctx context.Context) error {
+			_, err := s.syncerReloader.Reload(ctx)
+			return err
+		}
+		s.invalidation.Subscribe(redis.CacheBidders, redis.InvalidationSubscriber{
+			Evict:   func(string) { withInvalidationTimeout(reloadSyncers) },
+			Refresh: reloadSyncers,
+		})
+	}
+
+	// Rows in the bidders table override the compiled adapters' endpoints, headers and enabled state
+	var bidderStore endpoints.BidderAdminStore
+	if s.db != nil {
+		bidderStore = s.db
+	}
+	s.bidderAdmin = endpoints.NewBidderAdminHandler(bidderStore, adapters.DefaultRegistry)
+	if s.db != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if applied, err := s.bidderAdmin.Reload(ctx); err != nil {
+			log.Warn().Err(err).Msg("Failed to apply bidder configuration from database, using built-in adapters")
+		} else {
+			log.Info().Int("applied", applied).Strs("enabled", adapters.DefaultRegistry.ListEnabledBidders()).Msg("Bidder configuration applied from PostgreSQL")
+		}
+		cancel()
+	}
+	if s.invalidation != nil {
+		s.bidderAdmin.SetInvalidationPublisher(s.invalidation)
+		s.invalidation.Subscribe(redis.CacheBidders, redis.InvalidationSubscriber{
+			Evict: func(bidderCode string) {
+				withInvalidationTimeout(func(ctx context.Context) error { return s.bidderAdmin.ReloadBidder(ctx, bidderCode) })
+			},
+			Refresh: func(ctx context.Context) error {
+				_, err := s.bidderAdmin.Reload(ctx)
+				return err
+			},
+		})
+	}
+
+	// Rank cooperative syncs by each bidder's revenue contribution
+	cookieSyncHandler.SetRevenueSource(s.metrics)
+
 	log.Info().
 		Str("host_url", s.config.HostURL).
 		Int("syncers", len(cookieSyncHandler.ListBidders())).
 		Msg("Cookie sync initialized")
 
+	// Server-side UID store for app/CTV identities and bidders trimmed from the uids cookie
+	if s.redisClient != nil {
+		uidStoreConfig := usersync.DefaultStoreConfig()
+		uidStore := usersync.NewStore(s.redisClient, uidStoreConfig)
+		auctionHandler.SetUIDStore(uidStore)
+		setuidHandler.SetUIDStore(uidStore)
+		cookieSyncHandler.SetUIDStore(uidStore)
+		optoutHandler.SetUIDStore(uidStore)
+		log.Info().
+			Bool("enabled", uidStoreConfig.Enabled).
+			Dur("ttl", uidStoreConfig.TTL).
+			Dur("lookup_timeout", uidStoreConfig.LookupTimeout).
+			Msg("UID store initialized")
+	}
+
+	// Server-minted SharedID for publishers without an on-page ID module
+	sharedIDConfig := usersync.DefaultSharedIDConfig()
+	if sharedIDConfig.Enabled {
+		sharedID := usersync.NewSharedIDMinter(sharedIDConfig)
+		auctionHandler.SetSharedID(sharedID)
+		cookieSyncHandler.SetSharedID(sharedID)
+		optoutHandler.SetSharedID(sharedID)
+		log.Info().
+			Strs("cookie_domains", sharedIDConfig.CookieDomains).
+			Dur("ttl", sharedIDConfig.TTL).
+			Msg("SharedID minting enabled")
+		if len(sharedIDConfig.CookieDomains) == 0 {
+			// Without a publisher domain the cookie can't be first-party, so nothing is minted
+			log.Warn().Msg("SHAREDID_COOKIE_DOMAINS not set, SharedID only reuses existing _pubcid cookies and never mints")
+		}
+	}
+
 	// Initialize privacy middleware
-	privacyConfig := middleware.DefaultPrivacyConfig()
+	privacyConfig := s.config.ToPrivacyConfig()
 	if s.config.DisableGDPREnforcement {
-		privacyConfig.EnforceGDPR = false
 		log.Warn().Msg("GDPR enforcement disabled via PBS_DISABLE_GDPR_ENFORCEMENT")
 	}
 	privacyMiddleware := middleware.NewPrivacyMiddleware(privacyConfig)
@@ -251,21 +412,18 @@ func (s *Server) initHandlers() {
 	mux.Handle("/setuid", setuidHandler)
 	mux.Handle("/optout", optoutHandler)
 
-	// Prometheus metrics endpoint
-	mux.Handle("/metrics", metrics.Handler())
+	// Prometheus metrics endpoint (on the admin listener when there is one)
+	if s.adminMux == nil {
+		mux.Handle("/metrics", metrics.Handler())
+	}
 
-	// Admin endpoints
-	mux.HandleFunc("/admin/circuit-breaker", s.circuitBreakerHandler)
-	dashboardHandler := endpoints.NewDashboardHandler()
-	metricsAPIHandler := endpoints.NewMetricsAPIHandler()
-	publisherAdminHandler := endpoints.NewPublisherAdminHandler(s.redisClient)
-	mux.Handle("/admin/dashboard", dashboardHandler)
-	mux.Handle("/admin/metrics", metricsAPIHandler)
-	mux.Handle("/admin/publishers", publisherAdminHandler)
-	mux.Handle("/admin/publishers/", publisherAdminHandler)
+	// Admin endpoints are registered by buildHandler, which owns the admin auth layer
 
 	// Build middleware chain
 	handler := s.buildHandler(mux)
+	if s.adminServer != nil {
+		s.adminServer.Handler = buildAdminHandler(s.adminMux)
+	}
 
 	// Create HTTP server
 	s.httpServer = &http.Server{
@@ -282,17 +440,23 @@ func (s *Server) buildHandler(mux *http.ServeMux) http.Handler {
 	log := logger.Log
 
 	// Initialize middleware
-	cors := middleware.NewCORS(middleware.DefaultCORSConfig())
-	security := middleware.NewSecurity(nil)
-	publisherAuth := middleware.NewPublisherAuth(middleware.DefaultPublisherAuthConfig())
+	cors := middleware.NewCORS(s.config.ToCORSConfig())
+	security := middleware.NewSecurity(s.config.ToSecurityConfig())
+	publisherAuth := middleware.NewPublisherAuth(s.config.ToPublisherAuthConfig())
 
 	// Build Auth config with conditional bypass
-	authConfig := middleware.DefaultAuthConfig()
+	authConfig := s.config.ToAuthConfig()
 	if publisherAuth.IsEnabled() {
 		authConfig.BypassPaths = append(authConfig.BypassPaths, "/openrtb2/auction")
 	}
 	auth := middleware.NewAuth(authConfig)
-	sizeLimiter := middleware.NewSizeLimiter(middleware.DefaultSizeLimitConfig())
+	sizeLimiter := middleware.NewSizeLimiter(s.config.ToSizeLimitConfig())
+	// Share the IVT detector's GeoIP database so it is only loaded once
+	geoEnrichment := middleware.NewGeoEnrichment(s.config.ToGeoEnrichmentConfig(), publisherAuth.GeoIP())
+	s.cors, s.security, s.publisherAuth = cors, security, publisherAuth
+	s.sizeLimiter, s.geoEnrichment = sizeLimiter, geoEnrichment
+	// Header-derived device fields are filled before geo, privacy and IVT see the request
+	deviceHeaders := endpoints.NewDeviceHeaders(s.rateLimiter.ClientIP)
 	gzipMiddleware := middleware.NewGzip(middleware.DefaultGzipConfig())
 
 	// Wire up metrics
@@ -306,25 +470,72 @@ func (s *Server) buildHandler(mux *http.ServeMux) http.Handler {
 	}
 
 	// Wire up Redis
+	var apiKeyStore *middleware.APIKeyStore
 	if s.redisClient != nil {
 		auth.SetRedisClient(s.redisClient)
+
+		// Hashed, scoped API keys; minted and revoked through /admin/api-keys
+		apiKeyStore = middleware.NewAPIKeyStore(s.redisClient)
+		auth.SetKeyStore(apiKeyStore)
+
 		publisherAuth.SetRedisClient(s.redisClient)
+		publisherAuth.SetIVTBehaviorClient(s.redisClient)
+
+		// Share rate limits across instances; each falls back to its own buckets if Redis fails
+		distributedLimiter := middleware.NewDistributedRateLimiter(s.redisClient, s.rateLimiter.RedisTimeout())
+		s.rateLimiter.SetDistributed(distributedLimiter)
+		publisherAuth.SetDistributedRateLimiter(distributedLimiter)
 		log.Info().Msg("Redis client set for auth middlewares")
 	}
 
+	// Publisher and API key writes on other instances evict the local caches
+	s.invalidation.Subscribe(redis.CachePublishers, redis.InvalidationSubscriber{
+		Evict:   publisherAuth.InvalidatePublisher,
+		Refresh: func(context.Context) error { publisherAuth.ClearCache(); return nil },
+	})
+	s.invalidation.Subscribe(redis.CacheAPIKeys, redis.InvalidationSubscriber{
+		Evict:   auth.InvalidateAPIKey,
+		Refresh: func(context.Context) error { auth.ClearCache(); return nil },
+	})
+	if apiKeyStore != nil {
+		apiKeyStore.OnChange(func(keyID string) {
+			withInvalidationTimeout(func(ctx context.Context) error {
+				return s.invalidation.Publish(ctx, redis.CacheAPIKeys, keyID)
+			})
+		})
+	}
+
+	// Admin endpoints authenticate through the same key stores, with role permissions
+	adminAuth := middleware.NewAdminAuth(s.config.ToAdminAuthConfig(), auth)
+	if s.audit != nil {
+		adminAuth.SetAuditSink(s.audit)
+	}
+	adminMux := mux
+	if s.adminMux != nil {
+		adminMux = s.adminMux
+		registerProfilingRoutes(adminMux, adminAuth)
+	}
+	s.registerAdminRoutes(adminMux, adminAuth, apiKeyStore, publisherAuth)
+
 	log.Info().
 		Bool("cors_enabled", true).
 		Bool("security_headers_enabled", security.GetConfig().Enabled).
 		Bool("auth_enabled", auth.IsEnabled()).
+		Bool("admin_auth_enabled", adminAuth.IsEnabled()).
+		Bool("admin_audit_persisted", s.audit != nil).
+		Bool("admin_listener", s.adminServer != nil).
 		Bool("rate_limiting_enabled", s.rateLimiter != nil).
+		Bool("geoip_enabled", publisherAuth.GeoIP() != nil).
 		Msg("Middleware chain built")
 
-	// Build chain: CORS -> Security -> Logging -> Size Limit -> Auth -> PublisherAuth -> Rate Limit -> Metrics -> Gzip -> Handler
+	// Build chain: CORS -> Security -> Logging -> Size Limit -> Auth -> Device Headers -> Geo Enrichment -> PublisherAuth -> Rate Limit -> Metrics -> Gzip -> Handler
 	handler := http.Handler(mux)
 	handler = gzipMiddleware.Middleware(handler)
 	handler = s.metrics.Middleware(handler)
 	handler = s.rateLimiter.Middleware(handler)
 	handler = publisherAuth.Middleware(handler)
+	handler = geoEnrichment.Middleware(handler)
+	handler = deviceHeaders.Middleware(handler)
 	handler = auth.Middleware(handler)
 	handler = sizeLimiter.Middleware(handler)
 	handler = loggingMiddleware(handler)
@@ -334,6 +545,63 @@ func (s *Server) buildHandler(mux *http.ServeMux) http.Handler {
 	return handler
 }
 
+// registerAdminRoutes registers the /admin endpoints, each with the permissions it requires
+// Reads need PermAdminRead unless noted; state-changing calls are audited by adminAuth.
+func (s *Server) registerAdminRoutes(mux *http.ServeMux, adminAuth *middleware.AdminAuth, apiKeyStore *middleware.APIKeyStore, publisherAuth *middleware.PublisherAuth) {
+	read := middleware.AdminRoute{Read: middleware.PermAdminRead}
+	handle := func(route middleware.AdminRoute, handler http.Handler, paths ...string) {
+		protected := adminAuth.Protect(route, handler)
+		for _, path := range paths {
+			mux.Handle(path, protected)
+		}
+	}
+
+	// The dashboard page is static; the metrics it polls require a viewer key
+	handle(middleware.AdminRoute{}, endpoints.NewDashboardHandler(), "/admin/dashboard")
+	handle(read, endpoints.NewMetricsAPIHandler(), "/admin/metrics")
+	handle(read, http.HandlerFunc(s.circuitBreakerHandler), "/admin/circuit-breaker")
+	handle(read, endpoints.NewPrivacyDecodeHandler(adapters.DefaultRegistry), "/admin/privacy/decode")
+
+	// Publishers live in PostgreSQL; writes are mirrored to Redis and evict PublisherAuth's cache
+	var publisherStore endpoints.PublisherAdminStore
+	if s.publisher != nil {
+		publisherStore = s.publisher
+	}
+	publisherAdmin := endpoints.NewPublisherAdminHandler(publisherStore, s.redisClient)
+	publisherAdmin.SetCacheInvalidator(publisherAuth)
+	if s.invalidation != nil {
+		publisherAdmin.SetInvalidationPublisher(s.invalidation)
+	}
+	handle(middleware.AdminRoute{Read: middleware.PermAdminRead, Write: middleware.PermPublishersWrite},
+		publisherAdmin, "/admin/publishers", "/admin/publishers/")
+
+	// Bidder writes are applied to the running adapter registry
+	handle(middleware.AdminRoute{Read: middleware.PermAdminRead, Write: middleware.PermBiddersWrite},
+		s.bidderAdmin, "/admin/bidders", "/admin/bidders/")
+
+	if s.syncerReloader != nil {
+		handle(middleware.AdminRoute{Read: middleware.PermAdminRead, Write: middleware.PermOperationsWrite},
+			s.syncerReloader, "/admin/syncers/reload")
+	}
+
+	// Per-publisher IVT reports come from the detector owned by the publisher auth middleware
+	if ivtStats := publisherAuth.IVTStats(); ivtStats != nil {
+		handle(read, endpoints.NewIVTAdminHandler(ivtStats), "/admin/ivt", "/admin/ivt/")
+	}
+
+	if apiKeyStore != nil {
+		handle(middleware.AdminRoute{Read: middleware.PermAPIKeysRead, Write: middleware.PermAPIKeysWrite},
+			endpoints.NewAPIKeyAdminHandler(apiKeyStore), "/admin/api-keys", "/admin/api-keys/")
+	}
+
+	// A nil store must not become a non-nil AuditLog interface
+	var auditLog endpoints.AuditLog
+	if s.audit != nil {
+		auditLog = s.audit
+	}
+	handle(middleware.AdminRoute{Read: middleware.PermAuditRead}, endpoints.NewAuditAdminHandler(auditLog), "/admin/audit")
+}
+
 // circuitBreakerHandler returns circuit breaker stats
 func (s *Server) circuitBreakerHandler(w http.ResponseWriter, r *http.Request) {
 	log := logger.Log
@@ -356,17 +624,62 @@ func (s *Server) circuitBreakerHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// Start starts the HTTP server
+// Start starts the HTTP server, and the admin listener if configured
+// It returns when both have stopped, or with the first listener error.
 func (s *Server) Start() error {
 	log := logger.Log
-	log.Info().Str("addr", s.httpServer.Addr).Msg("Server listening")
 
-	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		return err
+	s.invalidation.Start()
+
+	errCh := make(chan error, 2)
+	listeners := 1
+	go func() {
+		log.Info().Str("addr", s.httpServer.Addr).Msg("Server listening")
+		errCh <- ignoreServerClosed(s.httpServer.ListenAndServe())
+	}()
+
+	if s.adminServer != nil {
+		listeners++
+		s.adminTLS.Start()
+		go func() {
+			log.Info().
+				Str("addr", s.adminServer.Addr).
+				Bool("tls", s.adminTLS != nil).
+				Msg("Admin server listening")
+			if s.adminTLS != nil {
+				// Certificates come from TLSConfig so reloads apply without a restart
+				errCh <- ignoreServerClosed(s.adminServer.ListenAndServeTLS("", ""))
+			} else {
+				errCh <- ignoreServerClosed(s.adminServer.ListenAndServe())
+			}
+		}()
+	}
+
+	for i := 0; i < listeners; i++ {
+		if err := <-errCh; err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// withInvalidationTimeout runs a cache update triggered by the invalidation bus, logging failures
+func withInvalidationTimeout(fn func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := fn(ctx); err != nil {
+		logger.Log.Warn().Err(err).Msg("Cache invalidation failed")
+	}
+}
+
+// ignoreServerClosed maps the error returned after Shutdown to nil
+func ignoreServerClosed(err error) error {
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
 // Shutdown performs graceful shutdown
 func (s *Server) Shutdown(ctx context.Context) error {
 	log := logger.Log
@@ -377,6 +690,14 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		s.rateLimiter.Stop()
 	}
 
+	// Stop syncer reloads
+	if s.syncerReloader != nil {
+		s.syncerReloader.Stop()
+	}
+
+	// Stop applying invalidations from other instances
+	s.invalidation.Stop()
+
 	// Flush pending events from exchange
 	if s.exchange != nil {
 		if err := s.exchange.Close(); err != nil {
@@ -386,9 +707,25 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		}
 	}
 
-	// Shutdown HTTP server
-	if err := s.httpServer.Shutdown(ctx); err != nil {
-		return err
+	// Shutdown HTTP servers together so both drain within the same deadline
+	var wg sync.WaitGroup
+	var adminErr error
+	if s.adminServer != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			adminErr = s.adminServer.Shutdown(ctx)
+		}()
+	}
+	publicErr := s.httpServer.Shutdown(ctx)
+	wg.Wait()
+	s.adminTLS.Stop()
+
+	if publicErr != nil {
+		return publicErr
+	}
+	if adminErr != nil {
+		return fmt.Errorf("admin server: %w", adminErr)
 	}
 
 	log.Info().Msg("Server stopped gracefully")
@@ -339,7 +339,7 @@ func TestServer_AllRoutes(t *testing.T) {
 		{"/info/bidders", http.StatusOK},
 		{"/metrics", http.StatusOK},
 		{"/admin/dashboard", http.StatusOK},
-		{"/admin/circuit-breaker", http.StatusOK},
+		{"/admin/circuit-breaker", http.StatusUnauthorized}, // Admin reads need a viewer key
 	}
 
 	for _, route := range routes {
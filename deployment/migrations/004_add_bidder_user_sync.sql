@@ -0,0 +1,65 @@
+-- =====================================================
+-- Add User Sync Definitions to Bidders
+-- =====================================================
+-- This migration moves cookie sync URL templates from
+-- code into the bidders table so sync URLs can be added
+-- or fixed without a release, and database-defined
+-- bidders can sync.
+--
+-- URL templates support {{gdpr}}, {{gdpr_consent}},
+-- {{us_privacy}} and {{redirect_url}} placeholders.
+-- The supported sync types are the ones with a URL set.
+-- =====================================================
+
+ALTER TABLE bidders
+ADD COLUMN usersync_iframe_url TEXT,                          -- iframe sync URL template
+ADD COLUMN usersync_redirect_url TEXT,                        -- redirect (image) sync URL template
+ADD COLUMN usersync_supports_cors BOOLEAN DEFAULT false,      -- Bidder sync endpoint supports CORS
+ADD COLUMN usersync_user_macro VARCHAR(50);                   -- Bidder's user ID macro (default: $UID)
+
+-- Seed sync URLs for the bidders created in 002
+UPDATE bidders SET
+    usersync_redirect_url = 'https://pixel.rubiconproject.com/exchange/sync.php?p=prebid&gdpr={{gdpr}}&gdpr_consent={{gdpr_consent}}&us_privacy={{us_privacy}}&redir={{redirect_url}}',
+    usersync_iframe_url = 'https://eus.rubiconproject.com/usync.html?p=prebid&gdpr={{gdpr}}&gdpr_consent={{gdpr_consent}}&us_privacy={{us_privacy}}',
+    usersync_supports_cors = true
+WHERE bidder_code = 'rubicon' AND usersync_redirect_url IS NULL;
+
+UPDATE bidders SET
+    usersync_redirect_url = 'https://ads.pubmatic.com/AdServer/js/user_sync.html?gdpr={{gdpr}}&gdpr_consent={{gdpr_consent}}&us_privacy={{us_privacy}}&predirect={{redirect_url}}',
+    usersync_iframe_url = 'https://ads.pubmatic.com/AdServer/js/user_sync.html?gdpr={{gdpr}}&gdpr_consent={{gdpr_consent}}&us_privacy={{us_privacy}}&predirect={{redirect_url}}',
+    usersync_supports_cors = true
+WHERE bidder_code = 'pubmatic' AND usersync_redirect_url IS NULL;
+
+UPDATE bidders SET
+    usersync_redirect_url = 'https://ib.adnxs.com/getuid?{{redirect_url}}',
+    usersync_supports_cors = true
+WHERE bidder_code = 'appnexus' AND usersync_redirect_url IS NULL;
+
+UPDATE bidders SET
+    usersync_redirect_url = 'https://ssum.casalemedia.com/usermatchredir?s=194962&gdpr={{gdpr}}&gdpr_consent={{gdpr_consent}}&us_privacy={{us_privacy}}&cb={{redirect_url}}',
+    usersync_supports_cors = true
+WHERE bidder_code = 'ix' AND usersync_redirect_url IS NULL;
+
+UPDATE bidders SET
+    usersync_redirect_url = 'https://rtb.openx.net/sync/prebid?gdpr={{gdpr}}&gdpr_consent={{gdpr_consent}}&r={{redirect_url}}',
+    usersync_supports_cors = true
+WHERE bidder_code = 'openx' AND usersync_redirect_url IS NULL;
+
+UPDATE bidders SET
+    usersync_redirect_url = 'https://gum.criteo.com/syncframe?origin=prebidserver&gdpr={{gdpr}}&gdpr_consent={{gdpr_consent}}&us_privacy={{us_privacy}}#{{redirect_url}}',
+    usersync_supports_cors = true
+WHERE bidder_code = 'criteo' AND usersync_redirect_url IS NULL;
+
+UPDATE bidders SET
+    usersync_redirect_url = 'https://eb2.3lift.com/sync?gdpr={{gdpr}}&cmp_cs={{gdpr_consent}}&us_privacy={{us_privacy}}&redir={{redirect_url}}',
+    usersync_supports_cors = true
+WHERE bidder_code = 'triplelift' AND usersync_redirect_url IS NULL;
+
+UPDATE bidders SET
+    usersync_redirect_url = 'https://ap.lijit.com/pixel?redir={{redirect_url}}',
+    usersync_supports_cors = true
+WHERE bidder_code = 'sovrn' AND usersync_redirect_url IS NULL;
+
+COMMENT ON COLUMN bidders.usersync_iframe_url IS 'iframe user sync URL template ({{gdpr}}, {{gdpr_consent}}, {{us_privacy}}, {{redirect_url}})';
+COMMENT ON COLUMN bidders.usersync_redirect_url IS 'Redirect (image) user sync URL template ({{gdpr}}, {{gdpr_consent}}, {{us_privacy}}, {{redirect_url}})';
+COMMENT ON COLUMN bidders.usersync_user_macro IS 'Macro the bidder replaces with its user ID in the /setuid redirect (default: $UID)';
@@ -0,0 +1,23 @@
+-- =====================================================
+-- Add EID Permissions to Publishers
+-- =====================================================
+-- This migration adds an eid_permissions column so a
+-- publisher can restrict Extended ID sources to named
+-- bidders, matching Prebid's eidpermissions:
+--
+--   [{"source": "liveramp.com", "bidders": ["appnexus", "rubicon"]}]
+--
+-- "*" in bidders allows every bidder. Sources not listed
+-- are sent to all bidders (subject to the global EID source
+-- filter). Request-level ext.prebid.data.eidpermissions
+-- apply as well; a bidder must be allowed by both.
+-- =====================================================
+
+ALTER TABLE publishers
+ADD COLUMN eid_permissions JSONB DEFAULT '[]'::jsonb;
+
+UPDATE publishers
+SET eid_permissions = '[]'::jsonb
+WHERE eid_permissions IS NULL;
+
+COMMENT ON COLUMN publishers.eid_permissions IS 'Per-bidder EID permissions: [{"source": "liveramp.com", "bidders": ["appnexus"]}]. "*" allows all bidders.';
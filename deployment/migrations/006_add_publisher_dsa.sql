@@ -0,0 +1,18 @@
+-- =====================================================
+-- Add DSA Defaults to Publishers
+-- =====================================================
+-- This migration adds a dsa column holding the EU Digital
+-- Services Act requirements applied to a publisher's
+-- requests when they don't carry regs.ext.dsa:
+--
+--   {"dsarequired": 2, "pubrender": 1, "datatopub": 2}
+--
+-- dsarequired 2 or 3 makes bid.ext.dsa (behalf, paid,
+-- transparency) mandatory; bids without it are rejected.
+-- NULL leaves requests untouched.
+-- =====================================================
+
+ALTER TABLE publishers
+ADD COLUMN dsa JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN publishers.dsa IS 'Default regs.ext.dsa: {"dsarequired": 2, "pubrender": 1, "datatopub": 2, "transparency": [...]}. NULL = none.';
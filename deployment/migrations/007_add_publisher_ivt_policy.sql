@@ -0,0 +1,20 @@
+-- =====================================================
+-- Add IVT Policy to Publishers
+-- =====================================================
+-- This migration adds an ivt_policy column holding
+-- per-publisher overrides of the global IVT (invalid
+-- traffic) configuration:
+--
+--   {"mode": "block", "block_threshold": 50,
+--    "allowed_countries": ["US", "CA"], "require_referer": true}
+--
+-- mode is "monitor" or "block"; thresholds include the
+-- behavioral limits max_requests_per_ip, max_requests_per_ifa,
+-- max_requests_per_user and max_domains_per_ip.
+-- NULL uses the global IVT_* configuration.
+-- =====================================================
+
+ALTER TABLE publishers
+ADD COLUMN ivt_policy JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN publishers.ivt_policy IS 'IVT overrides: {"mode": "monitor|block", "block_threshold": 70, "allowed_countries": [...], "require_referer": false}. NULL = global config.';
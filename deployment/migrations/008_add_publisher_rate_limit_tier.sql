@@ -0,0 +1,17 @@
+-- =====================================================
+-- Add Rate Limit Tier to Publishers
+-- =====================================================
+-- This migration adds a rate_limit_tier column naming
+-- the publisher's request rate limit. Tiers are defined
+-- per deployment in PUBLISHER_RATE_LIMIT_TIERS:
+--
+--   PUBLISHER_RATE_LIMIT_TIERS=basic:50,premium:1000:2000
+--
+-- (name:requests_per_second[:burst]). NULL, or a tier not
+-- defined in the environment, uses PUBLISHER_RATE_LIMIT_RPS.
+-- =====================================================
+
+ALTER TABLE publishers
+ADD COLUMN rate_limit_tier VARCHAR(32) DEFAULT NULL;
+
+COMMENT ON COLUMN publishers.rate_limit_tier IS 'Rate limit tier name from PUBLISHER_RATE_LIMIT_TIERS. NULL = PUBLISHER_RATE_LIMIT_RPS.';
@@ -0,0 +1,40 @@
+-- =====================================================
+-- Admin Audit Log
+-- =====================================================
+-- This migration creates the admin_audit_log table.
+-- Every state-changing call to an /admin/* endpoint is
+-- recorded with the acting API key and role, the state
+-- before and after the change (or the request body when
+-- the endpoint doesn't report one), and the time.
+--
+-- Rows are append-only; the application never updates
+-- or deletes them.
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+    id BIGSERIAL PRIMARY KEY,
+
+    -- Who
+    actor VARCHAR(255) NOT NULL,                  -- Key name, publisher ID, or 'anonymous'
+    actor_key_id VARCHAR(64),                     -- Hashed API key ID (NULL for legacy keys)
+    actor_role VARCHAR(32),                       -- 'viewer', 'operator', 'admin'
+    remote_ip VARCHAR(64),
+
+    -- What
+    method VARCHAR(16) NOT NULL,                  -- HTTP method
+    path TEXT NOT NULL,                           -- Admin endpoint path
+    status_code INTEGER NOT NULL,                 -- Response status
+    before_state JSONB,                           -- State before the change
+    after_state JSONB,                            -- State after the change
+
+    -- When
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_created_at ON admin_audit_log(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_actor ON admin_audit_log(actor, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_path ON admin_audit_log(path, created_at DESC);
+
+COMMENT ON TABLE admin_audit_log IS 'Append-only record of state-changing admin API calls';
+COMMENT ON COLUMN admin_audit_log.before_state IS 'State before the change (NULL for creates or when not reported)';
+COMMENT ON COLUMN admin_audit_log.after_state IS 'State after the change, or the request body when the endpoint does not report one';
@@ -0,0 +1,32 @@
+-- =====================================================
+-- Align Seeded Bidder Endpoints With Compiled Adapters
+-- =====================================================
+-- Rows in the bidders table are now applied to the
+-- running adapters at startup and on every
+-- /admin/bidders write: endpoint_url replaces the
+-- adapter's built-in endpoint, and enabled/status turn
+-- the bidder on or off.
+--
+-- Some endpoints seeded by 002 never matched the
+-- adapters' built-in endpoints. This migration moves
+-- them to the built-in values, but only where the row
+-- still holds the original seed, so endpoints changed
+-- by an operator are kept.
+-- =====================================================
+
+UPDATE bidders SET endpoint_url = 'https://hbopenbid.pubmatic.com/translator'
+WHERE bidder_code = 'pubmatic' AND endpoint_url = 'https://hbopenbid.pubmatic.com/translator?source=prebid-server';
+
+UPDATE bidders SET endpoint_url = 'https://ib.adnxs.com/openrtb2/prebid'
+WHERE bidder_code = 'appnexus' AND endpoint_url = 'https://ib.adnxs.com/openrtb2';
+
+UPDATE bidders SET endpoint_url = 'https://rtb.openx.net/openrtb/prebid'
+WHERE bidder_code = 'openx' AND endpoint_url = 'https://rtb.openx.net/prebid';
+
+UPDATE bidders SET endpoint_url = 'https://bidder.criteo.com/cdb'
+WHERE bidder_code = 'criteo' AND endpoint_url = 'https://bidder.criteo.com/openrtb/pbjs';
+
+UPDATE bidders SET endpoint_url = 'https://ap.lijit.com/rtb/bid'
+WHERE bidder_code = 'sovrn' AND endpoint_url = 'https://ap.lijit.com/rtb/bid?src=prebid_server';
+
+COMMENT ON COLUMN bidders.endpoint_url IS 'Replaces the compiled adapter endpoint at runtime. Ignored by mock adapters (demo).';
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"syscall"
 	"time"
 
 	"github.com/thenexusengine/tne_springwire/internal/openrtb"
@@ -63,6 +64,9 @@ type BidderResponse struct {
 	Bids       []*TypedBid
 	Currency   string
 	ResponseID string // P2-5: Original BidResponse.ID for validation against request
+
+	// FledgeAuctionConfigs are Protected Audience auction configs from ext.igi or ext.prebid.fledge
+	FledgeAuctionConfigs []*openrtb.FledgeAuctionConfig
 }
 
 // TypedBid is a bid with its type
@@ -115,6 +119,8 @@ type BidderInfo struct {
 	Endpoint                string
 	ExtraInfo               string
 	DemandType              DemandType // platform (obfuscated) or publisher (transparent)
+	FledgeSupported         bool       // Receives imp.ext.ae and may return Protected Audience auction configs
+	OpenRTBVersion          string     // openrtb.Version25 gets requests down-converted to 2.5 shapes; empty means 2.6
 }
 
 // MaintainerInfo contains maintainer info
@@ -179,6 +185,39 @@ type DefaultHTTPClient struct {
 // Connection pooling reduces latency by reusing TCP connections and TLS sessions
 // for repeated requests to the same bidder endpoints.
 func NewHTTPClient(timeout time.Duration) *DefaultHTTPClient {
+	return newHTTPClient(timeout, nil)
+}
+
+// ErrNonPublicAddress is returned when a public-only client is asked to connect to an internal address
+var ErrNonPublicAddress = errors.New("connection to non-public address refused")
+
+// NewPublicHTTPClient creates an HTTP client that only connects to public addresses
+// The check runs on the resolved address at connect time, so redirects and DNS rebinding can't reach internal hosts.
+func NewPublicHTTPClient(timeout time.Duration) *DefaultHTTPClient {
+	return newHTTPClient(timeout, func(network, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		if ip := net.ParseIP(host); ip == nil || !IsPublicIP(ip) {
+			return fmt.Errorf("%w: %s", ErrNonPublicAddress, host)
+		}
+		return nil
+	})
+}
+
+// cgnatNet is the shared address space for carrier-grade NAT (RFC 6598), often used by cloud internal networks
+var cgnatNet = &net.IPNet{IP: net.IPv4(100, 64, 0, 0).To4(), Mask: net.CIDRMask(10, 32)}
+
+// IsPublicIP reports whether ip is a globally routable unicast address
+// Loopback, private, CGNAT (100.64.0.0/10), link-local (including 169.254.169.254), unspecified
+// and multicast addresses are not.
+func IsPublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !cgnatNet.Contains(ip)
+}
+
+// newHTTPClient creates the pooled client; control, if set, vets each resolved address before connecting
+func newHTTPClient(timeout time.Duration, control func(network, address string, c syscall.RawConn) error) *DefaultHTTPClient {
 	transport := &http.Transport{
 		// Connection pooling settings
 		MaxIdleConns:        100,              // Total idle connections across all hosts
@@ -196,6 +235,7 @@ func NewHTTPClient(timeout time.Duration) *DefaultHTTPClient {
 		DialContext: (&net.Dialer{
 			Timeout:   5 * time.Second,  // Connection timeout
 			KeepAlive: 30 * time.Second, // TCP keepalive interval
+			Control:   control,
 		}).DialContext,
 		TLSHandshakeTimeout:   5 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
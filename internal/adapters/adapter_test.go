@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -213,6 +214,48 @@ func TestHTTPClientDo_ConnectionRefused(t *testing.T) {
 	}
 }
 
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1::1", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"100.127.255.254", false},
+		{"::ffff:100.100.100.200", false},
+		{"100.128.0.1", true},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := IsPublicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("IsPublicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestPublicHTTPClient_RefusesInternalAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("internal"))
+	}))
+	defer server.Close()
+
+	client := NewPublicHTTPClient(time.Second)
+	resp, err := client.Do(context.Background(), &RequestData{Method: "GET", URI: server.URL}, time.Second)
+	if !errors.Is(err, ErrNonPublicAddress) {
+		t.Fatalf("Expected ErrNonPublicAddress, got %v (%+v)", err, resp)
+	}
+}
+
 func TestBodyReader_Read(t *testing.T) {
 	data := []byte("hello world")
 	reader := &bodyReader{data: data}
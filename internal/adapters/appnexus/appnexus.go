@@ -120,9 +120,10 @@ func Info() adapters.BidderInfo {
 				},
 			},
 		},
-		GVLVendorID: 32,
-		Endpoint:    defaultEndpoint,
-		DemandType:  adapters.DemandTypePlatform, // Platform demand (obfuscated as "thenexusengine")
+		GVLVendorID:    32,
+		Endpoint:       defaultEndpoint,
+		DemandType:     adapters.DemandTypePlatform, // Platform demand (obfuscated as "thenexusengine")
+		OpenRTBVersion: openrtb.Version25,           // Xandr's Prebid Server endpoint expects OpenRTB 2.5 ext locations
 	}
 }
 
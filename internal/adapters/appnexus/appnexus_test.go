@@ -206,6 +206,10 @@ func TestInfo(t *testing.T) {
 		t.Errorf("Expected GVL vendor ID 32, got %d", info.GVLVendorID)
 	}
 
+	if info.OpenRTBVersion != openrtb.Version25 {
+		t.Errorf("Expected OpenRTB version 2.5, got %q", info.OpenRTBVersion)
+	}
+
 	if info.Capabilities == nil {
 		t.Fatal("Expected capabilities to be set")
 	}
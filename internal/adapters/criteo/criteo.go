@@ -56,6 +56,7 @@ func (a *Adapter) MakeBids(request *openrtb.BidRequest, responseData *adapters.R
 	}
 
 	response := &adapters.BidderResponse{Currency: bidResp.Cur, ResponseID: bidResp.ID, Bids: make([]*adapters.TypedBid, 0)}
+	response.FledgeAuctionConfigs = adapters.ParseFledgeAuctionConfigs(bidResp.Ext)
 	for _, seatBid := range bidResp.SeatBid {
 		for i := range seatBid.Bid {
 			response.Bids = append(response.Bids, &adapters.TypedBid{
@@ -70,10 +71,11 @@ func (a *Adapter) MakeBids(request *openrtb.BidRequest, responseData *adapters.R
 // Info returns bidder information
 func Info() adapters.BidderInfo {
 	return adapters.BidderInfo{
-		Enabled:     true,
-		GVLVendorID: 91,
-		Endpoint:    defaultEndpoint,
-		Maintainer:  &adapters.MaintainerInfo{Email: "prebid@criteo.com"},
+		Enabled:         true,
+		GVLVendorID:     91,
+		Endpoint:        defaultEndpoint,
+		FledgeSupported: true,
+		Maintainer:      &adapters.MaintainerInfo{Email: "prebid@criteo.com"},
 		Capabilities: &adapters.CapabilitiesInfo{
 			Site: &adapters.PlatformInfo{MediaTypes: []adapters.BidType{adapters.BidTypeBanner, adapters.BidTypeVideo, adapters.BidTypeNative}},
 			App:  &adapters.PlatformInfo{MediaTypes: []adapters.BidType{adapters.BidTypeBanner, adapters.BidTypeVideo, adapters.BidTypeNative}},
@@ -129,6 +129,60 @@ func GetBidType(bid *openrtb.Bid, request *openrtb.BidRequest) BidType {
 	return BidTypeBanner
 }
 
+// fledgeResponseExt holds the bid response extensions that carry Protected Audience auction configs
+type fledgeResponseExt struct {
+	// IGI is the OpenRTB Protected Audience extension: seller configs are in igs
+	IGI []struct {
+		ImpID string `json:"impid"`
+		IGS   []struct {
+			ImpID  string          `json:"impid"`
+			Config json.RawMessage `json:"config"`
+		} `json:"igs"`
+	} `json:"igi"`
+	Prebid *struct {
+		Fledge *openrtb.ExtBidResponseFledge `json:"fledge"`
+	} `json:"prebid"`
+}
+
+// ParseFledgeAuctionConfigs extracts Protected Audience auction configs from a bid response ext
+// Both ext.igi[].igs[] and ext.prebid.fledge.auctionconfigs[] are read. Entries without an
+// impid or config are dropped; a malformed ext yields no configs.
+func ParseFledgeAuctionConfigs(ext json.RawMessage) []*openrtb.FledgeAuctionConfig {
+	if len(ext) == 0 {
+		return nil
+	}
+	var parsed fledgeResponseExt
+	if err := json.Unmarshal(ext, &parsed); err != nil {
+		return nil
+	}
+
+	var configs []*openrtb.FledgeAuctionConfig
+	add := func(impID string, config json.RawMessage) {
+		if impID == "" || len(config) == 0 || string(config) == "null" {
+			return
+		}
+		configs = append(configs, &openrtb.FledgeAuctionConfig{ImpID: impID, Config: config})
+	}
+
+	for _, igi := range parsed.IGI {
+		for _, igs := range igi.IGS {
+			impID := igs.ImpID
+			if impID == "" {
+				impID = igi.ImpID
+			}
+			add(impID, igs.Config)
+		}
+	}
+	if parsed.Prebid != nil && parsed.Prebid.Fledge != nil {
+		for _, ac := range parsed.Prebid.Fledge.AuctionConfigs {
+			if ac != nil {
+				add(ac.ImpID, ac.Config)
+			}
+		}
+	}
+	return configs
+}
+
 // P2-5: SimpleAdapter provides common OpenRTB adapter functionality
 // Simple bidders can embed this to reduce boilerplate code.
 // This handles the common pattern of: POST JSON -> Parse JSON response -> Extract bids
@@ -184,9 +238,10 @@ func (a *SimpleAdapter) MakeBids(request *openrtb.BidRequest, responseData *Resp
 	impMap := BuildImpMap(request.Imp)
 
 	response := &BidderResponse{
-		Currency:   bidResp.Cur,
-		ResponseID: bidResp.ID,
-		Bids:       make([]*TypedBid, 0, len(bidResp.SeatBid)),
+		Currency:             bidResp.Cur,
+		ResponseID:           bidResp.ID,
+		Bids:                 make([]*TypedBid, 0, len(bidResp.SeatBid)),
+		FledgeAuctionConfigs: ParseFledgeAuctionConfigs(bidResp.Ext),
 	}
 
 	for _, seatBid := range bidResp.SeatBid {
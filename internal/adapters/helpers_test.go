@@ -446,3 +446,53 @@ func BenchmarkGetBidType(b *testing.B) {
 		GetBidType(bid, request)
 	}
 }
+
+func TestParseFledgeAuctionConfigs(t *testing.T) {
+	ext := []byte(`{
+		"igi": [
+			{"impid": "imp-1", "igs": [{"config": {"seller": "https://a.example"}}]},
+			{"igs": [{"impid": "imp-2", "config": {"seller": "https://b.example"}}, {"impid": "imp-3"}]}
+		],
+		"prebid": {"fledge": {"auctionconfigs": [
+			{"impid": "imp-4", "config": {"seller": "https://c.example"}},
+			{"config": {"seller": "https://missing-imp.example"}}
+		]}}
+	}`)
+
+	configs := ParseFledgeAuctionConfigs(ext)
+	if len(configs) != 3 {
+		t.Fatalf("expected 3 configs, got %d", len(configs))
+	}
+	for i, impID := range []string{"imp-1", "imp-2", "imp-4"} {
+		if configs[i].ImpID != impID {
+			t.Errorf("config %d: expected impid %s, got %s", i, impID, configs[i].ImpID)
+		}
+	}
+	if !strings.Contains(string(configs[0].Config), "https://a.example") {
+		t.Errorf("expected config to be passed through, got %s", configs[0].Config)
+	}
+
+	if ParseFledgeAuctionConfigs(nil) != nil {
+		t.Error("expected nil for empty ext")
+	}
+	if ParseFledgeAuctionConfigs([]byte(`{"igi":`)) != nil {
+		t.Error("expected nil for malformed ext")
+	}
+}
+
+func TestSimpleAdapter_MakeBids_FledgeAuctionConfigs(t *testing.T) {
+	adapter := NewSimpleAdapter("test", "https://example.com", BidTypeBanner)
+	request := &openrtb.BidRequest{ID: "req-1", Imp: []openrtb.Imp{{ID: "imp-1"}}}
+	response := &ResponseData{
+		StatusCode: 200,
+		Body:       []byte(`{"id":"req-1","seatbid":[],"ext":{"igi":[{"impid":"imp-1","igs":[{"config":{"seller":"https://a.example"}}]}]}}`),
+	}
+
+	result, errs := adapter.MakeBids(request, response)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(result.FledgeAuctionConfigs) != 1 || result.FledgeAuctionConfigs[0].ImpID != "imp-1" {
+		t.Errorf("expected 1 auction config for imp-1, got %+v", result.FledgeAuctionConfigs)
+	}
+}
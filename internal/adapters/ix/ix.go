@@ -56,6 +56,7 @@ func (a *Adapter) MakeBids(request *openrtb.BidRequest, responseData *adapters.R
 	}
 
 	response := &adapters.BidderResponse{Currency: bidResp.Cur, ResponseID: bidResp.ID, Bids: make([]*adapters.TypedBid, 0)}
+	response.FledgeAuctionConfigs = adapters.ParseFledgeAuctionConfigs(bidResp.Ext)
 
 	// P3-2: Use shared helper for O(1) bid type lookup
 	impMap := adapters.BuildImpMap(request.Imp)
@@ -74,10 +75,11 @@ func (a *Adapter) MakeBids(request *openrtb.BidRequest, responseData *adapters.R
 // Info returns bidder information
 func Info() adapters.BidderInfo {
 	return adapters.BidderInfo{
-		Enabled:     true,
-		GVLVendorID: 10,
-		Endpoint:    defaultEndpoint,
-		Maintainer:  &adapters.MaintainerInfo{Email: "prebid.support@indexexchange.com"},
+		Enabled:         true,
+		GVLVendorID:     10,
+		Endpoint:        defaultEndpoint,
+		FledgeSupported: true,
+		Maintainer:      &adapters.MaintainerInfo{Email: "prebid.support@indexexchange.com"},
 		Capabilities: &adapters.CapabilitiesInfo{
 			Site: &adapters.PlatformInfo{MediaTypes: []adapters.BidType{adapters.BidTypeBanner, adapters.BidTypeVideo, adapters.BidTypeNative}},
 			App:  &adapters.PlatformInfo{MediaTypes: []adapters.BidType{adapters.BidTypeBanner, adapters.BidTypeVideo, adapters.BidTypeNative}},
@@ -96,4 +96,27 @@ func TestInfo(t *testing.T) {
 	if info.GVLVendorID != 10 {
 		t.Errorf("Expected GVL vendor ID 10, got %d", info.GVLVendorID)
 	}
+	if !info.FledgeSupported {
+		t.Error("Expected Protected Audience support")
+	}
+}
+
+func TestMakeBids_FledgeAuctionConfigs(t *testing.T) {
+	adapter := New("")
+	request := &openrtb.BidRequest{
+		ID:  "test-request-1",
+		Imp: []openrtb.Imp{{ID: "imp-1", Banner: &openrtb.Banner{W: 300, H: 250}, Ext: json.RawMessage(`{"ae":1}`)}},
+	}
+	response := &adapters.ResponseData{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{"id":"test-request-1","ext":{"prebid":{"fledge":{"auctionconfigs":[{"impid":"imp-1","config":{"seller":"https://ix.example"}}]}}}}`),
+	}
+
+	bidderResponse, errs := adapter.MakeBids(request, response)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if len(bidderResponse.FledgeAuctionConfigs) != 1 || bidderResponse.FledgeAuctionConfigs[0].ImpID != "imp-1" {
+		t.Errorf("Expected 1 auction config for imp-1, got %+v", bidderResponse.FledgeAuctionConfigs)
+	}
 }
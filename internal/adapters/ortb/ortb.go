@@ -69,6 +69,7 @@ type CapabilitiesConfig struct {
 	SupportsFPD    bool     `json:"supports_first_party_data"`
 	SupportsCTV    bool     `json:"supports_ctv"`
 	SupportsAdPods bool     `json:"supports_ad_pods"`
+	SupportsFledge bool     `json:"supports_fledge"`
 }
 
 // RateLimitsConfig holds rate limiting configuration
@@ -225,6 +226,9 @@ func (a *GenericAdapter) MakeBids(request *openrtb.BidRequest, responseData *ada
 		ResponseID: bidResp.ID, // P2-5: Pass through for validation
 		Bids:       make([]*adapters.TypedBid, 0),
 	}
+	if config.Capabilities.SupportsFledge {
+		response.FledgeAuctionConfigs = adapters.ParseFledgeAuctionConfigs(bidResp.Ext)
+	}
 
 	// P2-NEW-1: Use BuildImpMap for O(1) bid type lookup instead of O(n) per bid
 	impMap := adapters.BuildImpMap(request.Imp)
@@ -284,19 +288,29 @@ func (a *GenericAdapter) transformRequest(request *openrtb.BidRequest, config *B
 
 	// Apply schain augmentation
 	if config.RequestTransform.SChainAugment.Enabled && len(config.RequestTransform.SChainAugment.Nodes) > 0 {
-		reqCopy.Source = a.augmentSChain(reqCopy.Source, &config.RequestTransform.SChainAugment)
+		inExt := config.Endpoint.ProtocolVersion == openrtb.Version25
+		reqCopy.Source = a.augmentSChain(reqCopy.Source, &config.RequestTransform.SChainAugment, inExt)
 	}
 
 	return &reqCopy
 }
 
 // augmentSChain adds supply chain nodes to the request's schain
-func (a *GenericAdapter) augmentSChain(source *openrtb.Source, augment *SChainAugmentConfig) *openrtb.Source {
+// inExt reads and writes the chain in source.ext.schain, where the exchange moved it for OpenRTB 2.5 bidders
+func (a *GenericAdapter) augmentSChain(source *openrtb.Source, augment *SChainAugmentConfig, inExt bool) *openrtb.Source {
 	// Create source if not present
 	var sourceCopy openrtb.Source
 	if source != nil {
 		sourceCopy = *source
 	}
+	if inExt && sourceCopy.SChain == nil && len(sourceCopy.Ext) > 0 {
+		var ext struct {
+			SChain *openrtb.SupplyChain `json:"schain"`
+		}
+		if err := json.Unmarshal(sourceCopy.Ext, &ext); err == nil {
+			sourceCopy.SChain = ext.SChain
+		}
+	}
 
 	// Initialize schain if not present
 	if sourceCopy.SChain == nil {
@@ -351,6 +365,10 @@ func (a *GenericAdapter) augmentSChain(source *openrtb.Source, augment *SChainAu
 		sourceCopy.SChain.Nodes = append(sourceCopy.SChain.Nodes, node)
 	}
 
+	if inExt {
+		sourceCopy.Ext = mergeJSONExt(sourceCopy.Ext, map[string]interface{}{"schain": sourceCopy.SChain})
+		sourceCopy.SChain = nil
+	}
 	return &sourceCopy
 }
 
@@ -433,7 +451,9 @@ func (a *GenericAdapter) Info() adapters.BidderInfo {
 		Maintainer: &adapters.MaintainerInfo{
 			Email: config.MaintainerEmail,
 		},
-		Endpoint: config.Endpoint.URL,
+		Endpoint:        config.Endpoint.URL,
+		FledgeSupported: config.Capabilities.SupportsFledge,
+		OpenRTBVersion:  config.Endpoint.ProtocolVersion,
 	}
 
 	// Set GVL Vendor ID if present
@@ -550,6 +550,9 @@ func TestGenericAdapter_Info(t *testing.T) {
 	if info.Endpoint != config.Endpoint.URL {
 		t.Error("expected endpoint URL")
 	}
+	if info.OpenRTBVersion != "2.5" {
+		t.Errorf("expected declared protocol version 2.5, got %q", info.OpenRTBVersion)
+	}
 }
 
 func TestGenericAdapter_Info_Capabilities(t *testing.T) {
@@ -807,7 +810,7 @@ func TestAugmentSChain_NilSource(t *testing.T) {
 		Version: "1.0",
 	}
 
-	result := adapter.augmentSChain(nil, augment)
+	result := adapter.augmentSChain(nil, augment, false)
 
 	if result == nil {
 		t.Fatal("expected non-nil result when source is nil")
@@ -840,7 +843,7 @@ func TestAugmentSChain_EmptySource(t *testing.T) {
 		Version: "1.0",
 	}
 
-	result := adapter.augmentSChain(source, augment)
+	result := adapter.augmentSChain(source, augment, false)
 
 	if result.SChain == nil {
 		t.Fatal("expected schain to be created")
@@ -871,7 +874,7 @@ func TestAugmentSChain_AppendToExisting(t *testing.T) {
 		},
 	}
 
-	result := adapter.augmentSChain(source, augment)
+	result := adapter.augmentSChain(source, augment, false)
 
 	if len(result.SChain.Nodes) != 2 {
 		t.Errorf("expected 2 nodes, got %d", len(result.SChain.Nodes))
@@ -886,6 +889,53 @@ func TestAugmentSChain_AppendToExisting(t *testing.T) {
 	}
 }
 
+func TestMakeRequests_SChainOpenRTB25(t *testing.T) {
+	config := basicConfig() // Declares OpenRTB 2.5
+	config.RequestTransform.SChainAugment = SChainAugmentConfig{
+		Enabled: true,
+		Nodes:   []SChainNodeConfig{{ASI: "nexusengine.com", SID: "nexus-001", HP: 1}},
+	}
+	adapter := New(config)
+
+	request := testBidRequest()
+	request.Source = &openrtb.Source{
+		TID: "tid-1",
+		SChain: &openrtb.SupplyChain{
+			Ver:      "1.0",
+			Complete: 1,
+			Nodes:    []openrtb.SupplyChainNode{{ASI: "upstream.com", SID: "up-001", HP: 1}},
+		},
+	}
+	// The exchange down-converts before the adapter runs
+	if err := openrtb.ConvertTo25(request); err != nil {
+		t.Fatalf("ConvertTo25 failed: %v", err)
+	}
+
+	requests, errs := adapter.MakeRequests(request, nil)
+	if len(errs) > 0 || len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d (errors %v)", len(requests), errs)
+	}
+
+	var sent struct {
+		Source struct {
+			SChain json.RawMessage `json:"schain"`
+			Ext    struct {
+				SChain openrtb.SupplyChain `json:"schain"`
+			} `json:"ext"`
+		} `json:"source"`
+	}
+	if err := json.Unmarshal(requests[0].Body, &sent); err != nil {
+		t.Fatalf("failed to decode request body: %v", err)
+	}
+	if sent.Source.SChain != nil {
+		t.Errorf("expected no 2.6 source.schain for a 2.5 bidder, got %s", sent.Source.SChain)
+	}
+	nodes := sent.Source.Ext.SChain.Nodes
+	if len(nodes) != 2 || nodes[0].ASI != "upstream.com" || nodes[1].ASI != "nexusengine.com" {
+		t.Errorf("expected upstream node followed by host node in source.ext.schain, got %+v", nodes)
+	}
+}
+
 func TestAugmentSChain_MultipleNodes(t *testing.T) {
 	adapter := &GenericAdapter{}
 	augment := &SChainAugmentConfig{
@@ -898,7 +948,7 @@ func TestAugmentSChain_MultipleNodes(t *testing.T) {
 		Version: "1.0",
 	}
 
-	result := adapter.augmentSChain(nil, augment)
+	result := adapter.augmentSChain(nil, augment, false)
 
 	if len(result.SChain.Nodes) != 3 {
 		t.Errorf("expected 3 nodes, got %d", len(result.SChain.Nodes))
@@ -928,7 +978,7 @@ func TestAugmentSChain_OverrideComplete(t *testing.T) {
 		Complete: &complete0,
 	}
 
-	result := adapter.augmentSChain(nil, augment)
+	result := adapter.augmentSChain(nil, augment, false)
 
 	if result.SChain.Complete != 0 {
 		t.Errorf("expected complete=0, got %d", result.SChain.Complete)
@@ -938,7 +988,7 @@ func TestAugmentSChain_OverrideComplete(t *testing.T) {
 	complete1 := 1
 	augment.Complete = &complete1
 
-	result = adapter.augmentSChain(nil, augment)
+	result = adapter.augmentSChain(nil, augment, false)
 
 	if result.SChain.Complete != 1 {
 		t.Errorf("expected complete=1, got %d", result.SChain.Complete)
@@ -964,7 +1014,7 @@ func TestAugmentSChain_PreserveOriginalComplete(t *testing.T) {
 		Complete: nil, // Don't override
 	}
 
-	result := adapter.augmentSChain(source, augment)
+	result := adapter.augmentSChain(source, augment, false)
 
 	if result.SChain.Complete != 0 {
 		t.Errorf("expected complete=0 (preserved), got %d", result.SChain.Complete)
@@ -987,7 +1037,7 @@ func TestAugmentSChain_VersionOverride(t *testing.T) {
 		Version: "2.0",
 	}
 
-	result := adapter.augmentSChain(source, augment)
+	result := adapter.augmentSChain(source, augment, false)
 
 	if result.SChain.Ver != "2.0" {
 		t.Errorf("expected version '2.0', got '%s'", result.SChain.Ver)
@@ -1004,7 +1054,7 @@ func TestAugmentSChain_DefaultVersion(t *testing.T) {
 		Version: "", // Empty version should default to "1.0"
 	}
 
-	result := adapter.augmentSChain(nil, augment)
+	result := adapter.augmentSChain(nil, augment, false)
 
 	if result.SChain.Ver != "1.0" {
 		t.Errorf("expected default version '1.0', got '%s'", result.SChain.Ver)
@@ -1027,7 +1077,7 @@ func TestAugmentSChain_NodeWithAllFields(t *testing.T) {
 		},
 	}
 
-	result := adapter.augmentSChain(nil, augment)
+	result := adapter.augmentSChain(nil, augment, false)
 
 	node := result.SChain.Nodes[0]
 	if node.ASI != "nexusengine.com" {
@@ -1067,7 +1117,7 @@ func TestAugmentSChain_NodeWithExt(t *testing.T) {
 		},
 	}
 
-	result := adapter.augmentSChain(nil, augment)
+	result := adapter.augmentSChain(nil, augment, false)
 
 	node := result.SChain.Nodes[0]
 	if node.Ext == nil {
@@ -1107,7 +1157,7 @@ func TestAugmentSChain_DoesNotModifyOriginal(t *testing.T) {
 		},
 	}
 
-	result := adapter.augmentSChain(source, augment)
+	result := adapter.augmentSChain(source, augment, false)
 
 	// Verify original is not modified
 	if len(source.SChain.Nodes) != 1 {
@@ -1134,7 +1184,7 @@ func TestAugmentSChain_HP_DirectAndIndirect(t *testing.T) {
 		},
 	}
 
-	result := adapter.augmentSChain(nil, augmentDirect)
+	result := adapter.augmentSChain(nil, augmentDirect, false)
 	if result.SChain.Nodes[0].HP != 1 {
 		t.Errorf("expected HP=1 for direct, got %d", result.SChain.Nodes[0].HP)
 	}
@@ -1147,7 +1197,7 @@ func TestAugmentSChain_HP_DirectAndIndirect(t *testing.T) {
 		},
 	}
 
-	result = adapter.augmentSChain(nil, augmentIndirect)
+	result = adapter.augmentSChain(nil, augmentIndirect, false)
 	if result.SChain.Nodes[0].HP != 0 {
 		t.Errorf("expected HP=0 for indirect, got %d", result.SChain.Nodes[0].HP)
 	}
@@ -1250,3 +1300,26 @@ func TestTransformRequest_SChainEmptyNodes(t *testing.T) {
 		t.Error("expected no SChain when nodes are empty")
 	}
 }
+
+func TestGenericAdapter_MakeBids_FledgeAuctionConfigs(t *testing.T) {
+	respBody := []byte(`{"id":"resp-1","cur":"USD","ext":{"igi":[{"impid":"imp-1","igs":[{"config":{"seller":"https://ssp.example"}}]}]}}`)
+	responseData := &adapters.ResponseData{StatusCode: http.StatusOK, Body: respBody}
+
+	// Configs are ignored unless the bidder declares support
+	adapter := New(basicConfig())
+	response, _ := adapter.MakeBids(testBidRequest(), responseData)
+	if len(response.FledgeAuctionConfigs) != 0 {
+		t.Errorf("expected no auction configs, got %d", len(response.FledgeAuctionConfigs))
+	}
+
+	config := basicConfig()
+	config.Capabilities.SupportsFledge = true
+	adapter = New(config)
+	response, _ = adapter.MakeBids(testBidRequest(), responseData)
+	if len(response.FledgeAuctionConfigs) != 1 {
+		t.Errorf("expected 1 auction config, got %d", len(response.FledgeAuctionConfigs))
+	}
+	if !adapter.Info().FledgeSupported {
+		t.Error("expected Info to report Protected Audience support")
+	}
+}
@@ -0,0 +1,76 @@
+package adapters
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// BidderOverride is runtime configuration layered over a registered adapter
+// (e.g. from the bidders table). Zero values keep the registered settings.
+type BidderOverride struct {
+	Enabled     bool
+	Endpoint    string      // Replaces the registered endpoint in request URIs ("" = keep)
+	Headers     http.Header // Set on every request
+	GVLVendorID int         // 0 = keep
+	MediaTypes  []BidType   // Site and app media types (nil = keep)
+}
+
+// apply builds the effective adapter and info for a registered entry
+func (o BidderOverride) apply(base AdapterWithInfo) AdapterWithInfo {
+	info := base.Info
+	info.Enabled = o.Enabled
+	if o.GVLVendorID > 0 {
+		info.GVLVendorID = o.GVLVendorID
+	}
+	if o.MediaTypes != nil {
+		info.Capabilities = &CapabilitiesInfo{
+			Site: &PlatformInfo{MediaTypes: o.MediaTypes},
+			App:  &PlatformInfo{MediaTypes: o.MediaTypes},
+		}
+	}
+
+	adapter := base.Adapter
+	rebase := o.Endpoint != "" && base.Info.Endpoint != "" && o.Endpoint != base.Info.Endpoint
+	if rebase || len(o.Headers) > 0 {
+		wrapped := &overrideAdapter{Adapter: base.Adapter, headers: o.Headers.Clone()}
+		if rebase {
+			wrapped.from, wrapped.to = base.Info.Endpoint, o.Endpoint
+			info.Endpoint = o.Endpoint
+		}
+		adapter = wrapped
+	}
+
+	return AdapterWithInfo{Adapter: adapter, Info: info}
+}
+
+// overrideAdapter rewrites the requests of a registered adapter
+// URIs built from the registered endpoint are rebased onto the override, keeping any
+// path or query the adapter appended. Mock requests are left alone.
+type overrideAdapter struct {
+	Adapter
+	from    string
+	to      string
+	headers http.Header
+}
+
+// MakeRequests builds the wrapped adapter's requests and applies the override
+func (a *overrideAdapter) MakeRequests(request *openrtb.BidRequest, extraInfo *ExtraRequestInfo) ([]*RequestData, []error) {
+	requests, errs := a.Adapter.MakeRequests(request, extraInfo)
+	for _, req := range requests {
+		if req == nil || req.Method == "MOCK" {
+			continue
+		}
+		if a.from != "" && strings.HasPrefix(req.URI, a.from) {
+			req.URI = a.to + strings.TrimPrefix(req.URI, a.from)
+		}
+		if len(a.headers) > 0 && req.Headers == nil {
+			req.Headers = http.Header{}
+		}
+		for name, values := range a.headers {
+			req.Headers[name] = append([]string(nil), values...)
+		}
+	}
+	return requests, errs
+}
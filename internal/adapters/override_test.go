@@ -0,0 +1,104 @@
+package adapters
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// requestingAdapter returns fixed requests for testing overrides
+type requestingAdapter struct {
+	uris []string
+}
+
+func (a *requestingAdapter) MakeRequests(request *openrtb.BidRequest, extraInfo *ExtraRequestInfo) ([]*RequestData, []error) {
+	requests := make([]*RequestData, 0, len(a.uris))
+	for _, uri := range a.uris {
+		method := "POST"
+		if uri == "" {
+			method = "MOCK"
+		}
+		requests = append(requests, &RequestData{Method: method, URI: uri})
+	}
+	return requests, nil
+}
+
+func (a *requestingAdapter) MakeBids(request *openrtb.BidRequest, responseData *ResponseData) (*BidderResponse, []error) {
+	return nil, nil
+}
+
+func TestRegistry_Configure(t *testing.T) {
+	r := NewRegistry()
+	adapter := &requestingAdapter{uris: []string{"https://bidder.example.com/auction?src=pbs", "https://other.example.com/x", ""}}
+	info := BidderInfo{Enabled: true, Endpoint: "https://bidder.example.com/auction", GVLVendorID: 10}
+	if err := r.Register("bidder", adapter, info); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	headers := http.Header{}
+	headers.Set("X-Seat", "abc")
+	err := r.Configure("bidder", BidderOverride{
+		Enabled:     false,
+		Endpoint:    "https://eu.bidder.example.com/auction",
+		Headers:     headers,
+		GVLVendorID: 20,
+		MediaTypes:  []BidType{BidTypeVideo},
+	})
+	if err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+
+	awi, _ := r.Get("bidder")
+	if awi.Info.Enabled || awi.Info.GVLVendorID != 20 || awi.Info.Endpoint != "https://eu.bidder.example.com/auction" {
+		t.Errorf("unexpected info %+v", awi.Info)
+	}
+	if awi.Info.Capabilities.Site.MediaTypes[0] != BidTypeVideo {
+		t.Errorf("expected video capability, got %+v", awi.Info.Capabilities.Site)
+	}
+	if len(r.ListEnabledBidders()) != 0 {
+		t.Error("expected disabled bidder to leave the enabled list")
+	}
+
+	requests, _ := awi.Adapter.MakeRequests(&openrtb.BidRequest{}, nil)
+	if requests[0].URI != "https://eu.bidder.example.com/auction?src=pbs" {
+		t.Errorf("expected rebased URI with query kept, got %s", requests[0].URI)
+	}
+	if requests[1].URI != "https://other.example.com/x" {
+		t.Errorf("expected unrelated URI untouched, got %s", requests[1].URI)
+	}
+	if requests[0].Headers.Get("X-Seat") != "abc" || requests[2].Headers != nil {
+		t.Errorf("expected headers on HTTP requests only, got %v / %v", requests[0].Headers, requests[2].Headers)
+	}
+
+	// Overrides replace each other rather than stacking
+	r.Configure("bidder", BidderOverride{Enabled: true})
+	awi, _ = r.Get("bidder")
+	if awi.Adapter != adapter || awi.Info.Endpoint != info.Endpoint || awi.Info.GVLVendorID != 10 || !awi.Info.Enabled {
+		t.Errorf("expected registered adapter with only enabled overridden, got %+v", awi.Info)
+	}
+
+	if err := r.Configure("unknown", BidderOverride{}); err == nil {
+		t.Error("expected error for unregistered bidder")
+	}
+}
+
+func TestRegistry_Reset(t *testing.T) {
+	r := NewRegistry()
+	adapter := &mockAdapter{name: "test"}
+	r.Register("bidder", adapter, BidderInfo{Enabled: true})
+	r.Configure("bidder", BidderOverride{Enabled: false})
+
+	if !r.Reset("bidder") {
+		t.Fatal("expected reset to find the bidder")
+	}
+	if awi, _ := r.Get("bidder"); !awi.Info.Enabled {
+		t.Error("expected registered info restored")
+	}
+	if def, ok := r.GetDefault("bidder"); !ok || def.Adapter != adapter {
+		t.Error("expected GetDefault to return the registered adapter")
+	}
+	if r.Reset("unknown") {
+		t.Error("expected reset of unknown bidder to report false")
+	}
+}
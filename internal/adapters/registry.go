@@ -9,12 +9,14 @@ import (
 type Registry struct {
 	mu       sync.RWMutex
 	adapters map[string]AdapterWithInfo
+	defaults map[string]AdapterWithInfo // Registered entries before any Configure override
 }
 
 // NewRegistry creates a new adapter registry
 func NewRegistry() *Registry {
 	return &Registry{
 		adapters: make(map[string]AdapterWithInfo),
+		defaults: make(map[string]AdapterWithInfo),
 	}
 }
 
@@ -31,9 +33,45 @@ func (r *Registry) Register(bidderCode string, adapter Adapter, info BidderInfo)
 		Adapter: adapter,
 		Info:    info,
 	}
+	r.defaults[bidderCode] = r.adapters[bidderCode]
 	return nil
 }
 
+// Configure applies a runtime override to a registered bidder, replacing any earlier override
+// The registered adapter and info are kept so the override can be changed or reset later.
+func (r *Registry) Configure(bidderCode string, override BidderOverride) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	base, ok := r.defaults[bidderCode]
+	if !ok {
+		return fmt.Errorf("adapter not registered: %s", bidderCode)
+	}
+	r.adapters[bidderCode] = override.apply(base)
+	return nil
+}
+
+// Reset restores a bidder's registered adapter and info, dropping any override
+func (r *Registry) Reset(bidderCode string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	base, ok := r.defaults[bidderCode]
+	if ok {
+		r.adapters[bidderCode] = base
+	}
+	return ok
+}
+
+// GetDefault retrieves a bidder's registered adapter and info, ignoring overrides
+func (r *Registry) GetDefault(bidderCode string) (AdapterWithInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	adapter, ok := r.defaults[bidderCode]
+	return adapter, ok
+}
+
 // Get retrieves an adapter by bidder code
 func (r *Registry) Get(bidderCode string) (AdapterWithInfo, bool) {
 	r.mu.RLock()
@@ -0,0 +1,327 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// defaultAPIKeyRotationOverlap is how long a rotated key keeps working when no overlap is given
+const defaultAPIKeyRotationOverlap = 24 * time.Hour
+
+// APIKeyManager mints, rotates and revokes hashed API keys
+type APIKeyManager interface {
+	Mint(ctx context.Context, spec middleware.APIKeySpec) (string, *middleware.APIKey, error)
+	Get(ctx context.Context, id string) (*middleware.APIKey, error)
+	List(ctx context.Context, publisherID string) ([]*middleware.APIKey, error)
+	Revoke(ctx context.Context, id string) (*middleware.APIKey, error)
+	Rotate(ctx context.Context, id string, overlap time.Duration) (string, *middleware.APIKey, error)
+}
+
+// APIKeyAdminHandler handles API key management via API
+type APIKeyAdminHandler struct {
+	keys APIKeyManager
+}
+
+// NewAPIKeyAdminHandler creates an API key admin handler
+func NewAPIKeyAdminHandler(keys APIKeyManager) *APIKeyAdminHandler {
+	return &APIKeyAdminHandler{keys: keys}
+}
+
+// APIKeyResponse is a key's metadata; salts and hashes are never returned
+type APIKeyResponse struct {
+	ID          string     `json:"id"`
+	PublisherID string     `json:"publisher_id"`
+	Name        string     `json:"name,omitempty"`
+	Scopes      []string   `json:"scopes"`
+	Role        string     `json:"role,omitempty"`
+	Active      bool       `json:"active"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RotatedFrom string     `json:"rotated_from,omitempty"`
+}
+
+// APIKeyListResponse is the response for listing keys
+type APIKeyListResponse struct {
+	Keys  []APIKeyResponse `json:"keys"`
+	Count int              `json:"count"`
+}
+
+// APIKeyMintResponse returns a new key; the raw key is only shown once
+type APIKeyMintResponse struct {
+	Key    string         `json:"key"`
+	APIKey APIKeyResponse `json:"api_key"`
+}
+
+// APIKeyRequest is the request body for minting keys
+type APIKeyRequest struct {
+	PublisherID string   `json:"publisher_id"`
+	Name        string   `json:"name"`
+	Scopes      []string `json:"scopes"` // Default: ["auction"], or the admin scopes of Role
+	Role        string   `json:"role"`   // Admin role: viewer, operator or admin (empty = no admin access)
+	TTL         string   `json:"ttl"`    // Go duration, e.g. "2160h" (empty = never expires)
+}
+
+// APIKeyRotateRequest is the optional request body for rotating keys
+type APIKeyRotateRequest struct {
+	Overlap string `json:"overlap"` // How long the old key keeps working (default: 24h)
+}
+
+// ServeHTTP handles API key requests
+// Routes:
+//
+//	GET    /admin/api-keys?publisher_id=x  - List keys (optionally for one publisher)
+//	GET    /admin/api-keys/:id             - Get key metadata
+//	POST   /admin/api-keys                 - Mint a key
+//	POST   /admin/api-keys/:id/rotate      - Mint a replacement; the old key expires after the overlap
+//	DELETE /admin/api-keys/:id             - Revoke a key immediately
+func (h *APIKeyAdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.keys == nil {
+		h.sendError(w, http.StatusServiceUnavailable, "Redis not available", "API key management requires Redis connection")
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/api-keys"), "/")
+	keyID, action, _ := strings.Cut(path, "/")
+
+	switch {
+	case r.Method == http.MethodGet && action == "":
+		if keyID != "" {
+			h.getKey(w, r, keyID)
+		} else {
+			h.listKeys(w, r)
+		}
+	case r.Method == http.MethodPost && keyID == "":
+		h.mintKey(w, r)
+	case r.Method == http.MethodPost && action == "rotate":
+		h.rotateKey(w, r, keyID)
+	case r.Method == http.MethodDelete && keyID != "" && action == "":
+		h.revokeKey(w, r, keyID)
+	case keyID == "" && (r.Method == http.MethodDelete || r.Method == http.MethodPut):
+		h.sendError(w, http.StatusBadRequest, "missing_key_id", "Key ID required in path")
+	default:
+		h.sendError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+	}
+}
+
+// listKeys returns all keys, optionally filtered by publisher
+func (h *APIKeyAdminHandler) listKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.keys.List(r.Context(), r.URL.Query().Get("publisher_id"))
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to list API keys")
+		h.sendError(w, http.StatusInternalServerError, "redis_error", "Failed to retrieve API keys")
+		return
+	}
+
+	now := time.Now()
+	response := APIKeyListResponse{Keys: make([]APIKeyResponse, 0, len(keys))}
+	for _, key := range keys {
+		response.Keys = append(response.Keys, newAPIKeyResponse(key, now))
+	}
+	response.Count = len(response.Keys)
+
+	h.sendJSON(w, http.StatusOK, response)
+}
+
+// getKey returns one key's metadata
+func (h *APIKeyAdminHandler) getKey(w http.ResponseWriter, r *http.Request, keyID string) {
+	key, err := h.keys.Get(r.Context(), keyID)
+	if err != nil {
+		h.sendStoreError(w, err, keyID, "Failed to retrieve API key")
+		return
+	}
+	h.sendJSON(w, http.StatusOK, newAPIKeyResponse(key, time.Now()))
+}
+
+// mintKey creates a key and returns it once
+func (h *APIKeyAdminHandler) mintKey(w http.ResponseWriter, r *http.Request) {
+	var req APIKeyRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 64*1024)).Decode(&req); err != nil {
+		h.sendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		return
+	}
+
+	spec := middleware.APIKeySpec{
+		PublisherID: strings.TrimSpace(req.PublisherID),
+		Name:        req.Name,
+		Scopes:      req.Scopes,
+		Role:        strings.TrimSpace(req.Role),
+	}
+	if spec.PublisherID == "" {
+		h.sendError(w, http.StatusBadRequest, "missing_publisher_id", "publisher_id is required")
+		return
+	}
+	if spec.Role != "" && !middleware.ValidRole(spec.Role) {
+		h.sendError(w, http.StatusBadRequest, "invalid_role", "role must be one of "+strings.Join(middleware.AdminRoles, ", "))
+		return
+	}
+	if len(spec.Scopes) == 0 {
+		spec.Scopes = defaultAPIKeyScopes(spec.Role)
+	}
+	if err := middleware.ValidateScopes(spec.Scopes); err != nil {
+		h.sendError(w, http.StatusBadRequest, "invalid_scopes", err.Error())
+		return
+	}
+	if req.TTL != "" {
+		ttl, err := time.ParseDuration(req.TTL)
+		if err != nil || ttl <= 0 {
+			h.sendError(w, http.StatusBadRequest, "invalid_ttl", "ttl must be a positive duration such as 720h")
+			return
+		}
+		spec.TTL = ttl
+	}
+
+	raw, key, err := h.keys.Mint(r.Context(), spec)
+	if err != nil {
+		logger.Log.Error().Err(err).Str("publisher_id", spec.PublisherID).Msg("Failed to mint API key")
+		h.sendError(w, http.StatusInternalServerError, "redis_error", "Failed to create API key")
+		return
+	}
+
+	logger.Log.Info().
+		Str("key_id", key.ID).
+		Str("publisher_id", key.PublisherID).
+		Strs("scopes", key.Scopes).
+		Str("role", key.Role).
+		Msg("API key minted")
+
+	// The raw key is never written to the audit log
+	response := newAPIKeyResponse(key, time.Now())
+	middleware.RecordAuditChange(r.Context(), nil, response)
+
+	h.sendJSON(w, http.StatusCreated, APIKeyMintResponse{Key: raw, APIKey: response})
+}
+
+// rotateKey mints a replacement key and schedules the old one to expire
+func (h *APIKeyAdminHandler) rotateKey(w http.ResponseWriter, r *http.Request, keyID string) {
+	var req APIKeyRotateRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 64*1024)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.sendError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body")
+		return
+	}
+
+	overlap := defaultAPIKeyRotationOverlap
+	if req.Overlap != "" {
+		parsed, err := time.ParseDuration(req.Overlap)
+		if err != nil || parsed < 0 {
+			h.sendError(w, http.StatusBadRequest, "invalid_overlap", "overlap must be a duration such as 24h (0 revokes the old key now)")
+			return
+		}
+		overlap = parsed
+	}
+
+	before, err := h.keys.Get(r.Context(), keyID)
+	if err != nil {
+		h.sendStoreError(w, err, keyID, "Failed to rotate API key")
+		return
+	}
+
+	raw, key, err := h.keys.Rotate(r.Context(), keyID, overlap)
+	if err != nil {
+		h.sendStoreError(w, err, keyID, "Failed to rotate API key")
+		return
+	}
+
+	logger.Log.Info().
+		Str("key_id", key.ID).
+		Str("rotated_from", keyID).
+		Dur("overlap", overlap).
+		Msg("API key rotated")
+
+	now := time.Now()
+	response := newAPIKeyResponse(key, now)
+	middleware.RecordAuditChange(r.Context(), newAPIKeyResponse(before, now), response)
+
+	h.sendJSON(w, http.StatusCreated, APIKeyMintResponse{Key: raw, APIKey: response})
+}
+
+// revokeKey disables a key immediately
+func (h *APIKeyAdminHandler) revokeKey(w http.ResponseWriter, r *http.Request, keyID string) {
+	before, err := h.keys.Get(r.Context(), keyID)
+	if err != nil {
+		h.sendStoreError(w, err, keyID, "Failed to revoke API key")
+		return
+	}
+
+	key, err := h.keys.Revoke(r.Context(), keyID)
+	if err != nil {
+		h.sendStoreError(w, err, keyID, "Failed to revoke API key")
+		return
+	}
+
+	logger.Log.Info().
+		Str("key_id", keyID).
+		Str("publisher_id", key.PublisherID).
+		Msg("API key revoked")
+
+	now := time.Now()
+	response := newAPIKeyResponse(key, now)
+	middleware.RecordAuditChange(r.Context(), newAPIKeyResponse(before, now), response)
+
+	h.sendJSON(w, http.StatusOK, response)
+}
+
+// newAPIKeyResponse converts stored key metadata to its API representation
+func newAPIKeyResponse(key *middleware.APIKey, now time.Time) APIKeyResponse {
+	return APIKeyResponse{
+		ID:          key.ID,
+		PublisherID: key.PublisherID,
+		Name:        key.Name,
+		Scopes:      key.Scopes,
+		Role:        key.Role,
+		Active:      key.Active(now),
+		CreatedAt:   key.CreatedAt,
+		ExpiresAt:   key.ExpiresAt,
+		RevokedAt:   key.RevokedAt,
+		LastUsedAt:  key.LastUsedAt,
+		RotatedFrom: key.RotatedFrom,
+	}
+}
+
+// defaultAPIKeyScopes returns the scopes a key gets when none are requested
+func defaultAPIKeyScopes(role string) []string {
+	switch role {
+	case "":
+		return []string{middleware.ScopeAuction}
+	case middleware.RoleViewer:
+		return []string{middleware.ScopeAdminRead}
+	default:
+		return []string{middleware.ScopeAdminRead, middleware.ScopeAdminWrite}
+	}
+}
+
+// sendStoreError maps key store errors to responses
+func (h *APIKeyAdminHandler) sendStoreError(w http.ResponseWriter, err error, keyID, message string) {
+	switch {
+	case errors.Is(err, middleware.ErrAPIKeyNotFound):
+		h.sendError(w, http.StatusNotFound, "not_found", "API key not found")
+	case errors.Is(err, middleware.ErrAPIKeyInactive):
+		h.sendError(w, http.StatusConflict, "inactive", "API key is expired or revoked")
+	default:
+		logger.Log.Error().Err(err).Str("key_id", keyID).Msg(message)
+		h.sendError(w, http.StatusInternalServerError, "redis_error", message)
+	}
+}
+
+// sendJSON sends a JSON response
+func (h *APIKeyAdminHandler) sendJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to encode JSON response")
+	}
+}
+
+// sendError sends a JSON error response
+func (h *APIKeyAdminHandler) sendError(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	h.sendJSON(w, statusCode, ErrorResponse{Error: errorCode, Message: message})
+}
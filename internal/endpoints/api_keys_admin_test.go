@@ -0,0 +1,179 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+)
+
+// newTestAPIKeyAdminHandler returns a handler over a miniredis-backed key store (test helper)
+func newTestAPIKeyAdminHandler(t *testing.T) (*APIKeyAdminHandler, *middleware.APIKeyStore) {
+	t.Helper()
+	client, _ := setupTestRedisForPublisher(t)
+	store := middleware.NewAPIKeyStore(client)
+	return NewAPIKeyAdminHandler(store), store
+}
+
+func serveAPIKeyAdmin(handler http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(method, target, bytes.NewBufferString(body)))
+	return rr
+}
+
+func TestAPIKeyAdminHandler_MintListRevoke(t *testing.T) {
+	handler, store := newTestAPIKeyAdminHandler(t)
+
+	rr := serveAPIKeyAdmin(handler, http.MethodPost, "/admin/api-keys", `{"publisher_id":"pub1","name":"prebid.js","scopes":["auction","debug"],"ttl":"720h"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if strings.Contains(rr.Body.String(), `"hash"`) || strings.Contains(rr.Body.String(), `"salt"`) {
+		t.Error("expected no hash or salt in the response")
+	}
+
+	var minted APIKeyMintResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &minted); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !strings.HasPrefix(minted.Key, middleware.APIKeyPrefix) || !minted.APIKey.Active || minted.APIKey.ExpiresAt == nil {
+		t.Fatalf("unexpected mint response %+v", minted)
+	}
+	if _, err := store.Authenticate(context.Background(), minted.Key); err != nil {
+		t.Errorf("expected the returned key to authenticate, got %v", err)
+	}
+
+	// Scopes default to auction
+	rr = serveAPIKeyAdmin(handler, http.MethodPost, "/admin/api-keys", `{"publisher_id":"pub2"}`)
+	var defaulted APIKeyMintResponse
+	json.Unmarshal(rr.Body.Bytes(), &defaulted)
+	if len(defaulted.APIKey.Scopes) != 1 || defaulted.APIKey.Scopes[0] != middleware.ScopeAuction {
+		t.Errorf("expected default auction scope, got %v", defaulted.APIKey.Scopes)
+	}
+
+	rr = serveAPIKeyAdmin(handler, http.MethodGet, "/admin/api-keys?publisher_id=pub1", "")
+	var list APIKeyListResponse
+	json.Unmarshal(rr.Body.Bytes(), &list)
+	if list.Count != 1 || list.Keys[0].ID != minted.APIKey.ID {
+		t.Errorf("expected pub1's key, got %+v", list)
+	}
+
+	rr = serveAPIKeyAdmin(handler, http.MethodDelete, "/admin/api-keys/"+minted.APIKey.ID, "")
+	var revoked APIKeyResponse
+	json.Unmarshal(rr.Body.Bytes(), &revoked)
+	if rr.Code != http.StatusOK || revoked.Active || revoked.RevokedAt == nil {
+		t.Errorf("expected revoked key, got %d %+v", rr.Code, revoked)
+	}
+
+	rr = serveAPIKeyAdmin(handler, http.MethodGet, "/admin/api-keys/"+minted.APIKey.ID, "")
+	var fetched APIKeyResponse
+	json.Unmarshal(rr.Body.Bytes(), &fetched)
+	if rr.Code != http.StatusOK || fetched.Active {
+		t.Errorf("expected inactive key on fetch, got %d %+v", rr.Code, fetched)
+	}
+}
+
+func TestAPIKeyAdminHandler_Rotate(t *testing.T) {
+	handler, store := newTestAPIKeyAdminHandler(t)
+
+	rr := serveAPIKeyAdmin(handler, http.MethodPost, "/admin/api-keys", `{"publisher_id":"pub1"}`)
+	var original APIKeyMintResponse
+	json.Unmarshal(rr.Body.Bytes(), &original)
+
+	rr = serveAPIKeyAdmin(handler, http.MethodPost, "/admin/api-keys/"+original.APIKey.ID+"/rotate", `{"overlap":"0s"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var rotated APIKeyMintResponse
+	json.Unmarshal(rr.Body.Bytes(), &rotated)
+	if rotated.APIKey.RotatedFrom != original.APIKey.ID {
+		t.Errorf("expected rotated_from %s, got %s", original.APIKey.ID, rotated.APIKey.RotatedFrom)
+	}
+
+	// With no overlap the old key stops working at once
+	if _, err := store.Authenticate(context.Background(), original.Key); err == nil {
+		t.Error("expected the old key to be expired")
+	}
+	if _, err := store.Authenticate(context.Background(), rotated.Key); err != nil {
+		t.Errorf("expected the new key to work, got %v", err)
+	}
+
+	// Without a body the default overlap applies
+	rr = serveAPIKeyAdmin(handler, http.MethodPost, "/admin/api-keys/"+rotated.APIKey.ID+"/rotate", "")
+	if rr.Code != http.StatusCreated {
+		t.Errorf("expected 201 with the default overlap, got %d", rr.Code)
+	}
+
+	rr = serveAPIKeyAdmin(handler, http.MethodPost, "/admin/api-keys/"+original.APIKey.ID+"/rotate", "")
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected 409 rotating an expired key, got %d", rr.Code)
+	}
+}
+
+func TestAPIKeyAdminHandler_Errors(t *testing.T) {
+	handler, _ := newTestAPIKeyAdminHandler(t)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		want   int
+	}{
+		{"invalid json", http.MethodPost, "/admin/api-keys", `{`, http.StatusBadRequest},
+		{"missing publisher", http.MethodPost, "/admin/api-keys", `{"scopes":["auction"]}`, http.StatusBadRequest},
+		{"unknown scope", http.MethodPost, "/admin/api-keys", `{"publisher_id":"pub1","scopes":["root"]}`, http.StatusBadRequest},
+		{"invalid ttl", http.MethodPost, "/admin/api-keys", `{"publisher_id":"pub1","ttl":"forever"}`, http.StatusBadRequest},
+		{"invalid overlap", http.MethodPost, "/admin/api-keys/abc/rotate", `{"overlap":"-1h"}`, http.StatusBadRequest},
+		{"unknown key", http.MethodGet, "/admin/api-keys/abc", "", http.StatusNotFound},
+		{"revoke unknown key", http.MethodDelete, "/admin/api-keys/abc", "", http.StatusNotFound},
+		{"revoke without id", http.MethodDelete, "/admin/api-keys", "", http.StatusBadRequest},
+		{"wrong method", http.MethodPatch, "/admin/api-keys/abc", "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rr := serveAPIKeyAdmin(handler, tt.method, tt.target, tt.body); rr.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	rr := serveAPIKeyAdmin(NewAPIKeyAdminHandler(nil), http.MethodGet, "/admin/api-keys", "")
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without a key store, got %d", rr.Code)
+	}
+}
+
+func TestAPIKeyAdminHandler_Roles(t *testing.T) {
+	handler, _ := newTestAPIKeyAdminHandler(t)
+
+	tests := []struct {
+		role   string
+		scopes []string
+	}{
+		{middleware.RoleViewer, []string{middleware.ScopeAdminRead}},
+		{middleware.RoleOperator, []string{middleware.ScopeAdminRead, middleware.ScopeAdminWrite}},
+		{middleware.RoleAdmin, []string{middleware.ScopeAdminRead, middleware.ScopeAdminWrite}},
+	}
+	for _, tt := range tests {
+		rr := serveAPIKeyAdmin(handler, http.MethodPost, "/admin/api-keys", `{"publisher_id":"ops","role":"`+tt.role+`"}`)
+		var minted APIKeyMintResponse
+		json.Unmarshal(rr.Body.Bytes(), &minted)
+		if rr.Code != http.StatusCreated || minted.APIKey.Role != tt.role {
+			t.Fatalf("expected %s key, got %d: %s", tt.role, rr.Code, rr.Body.String())
+		}
+		if len(minted.APIKey.Scopes) != len(tt.scopes) {
+			t.Errorf("expected %s scopes %v, got %v", tt.role, tt.scopes, minted.APIKey.Scopes)
+		}
+	}
+
+	rr := serveAPIKeyAdmin(handler, http.MethodPost, "/admin/api-keys", `{"publisher_id":"ops","role":"root"}`)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown role, got %d", rr.Code)
+	}
+}
@@ -14,7 +14,9 @@ import (
 	log "github.com/rs/zerolog/log"
 
 	"github.com/thenexusengine/tne_springwire/internal/exchange"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
 	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/usersync"
 	"github.com/thenexusengine/tne_springwire/pkg/logger"
 )
 
@@ -45,6 +47,8 @@ func GetPublisherID(ctx context.Context) (string, bool) {
 // AuctionHandler handles /openrtb2/auction requests
 type AuctionHandler struct {
 	exchange *exchange.Exchange
+	uidStore *usersync.Store
+	sharedID *usersync.SharedIDMinter
 }
 
 // NewAuctionHandler creates a new auction handler
@@ -52,6 +56,16 @@ func NewAuctionHandler(ex *exchange.Exchange) *AuctionHandler {
 	return &AuctionHandler{exchange: ex}
 }
 
+// SetUIDStore sets the server-side UID store used to populate buyeruid
+func (h *AuctionHandler) SetUIDStore(store *usersync.Store) {
+	h.uidStore = store
+}
+
+// SetSharedID sets the minter for the server-generated SharedID (pubcid) EID
+func (h *AuctionHandler) SetSharedID(minter *usersync.SharedIDMinter) {
+	h.sharedID = minter
+}
+
 // ServeHTTP handles the auction request
 func (h *AuctionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -76,6 +90,9 @@ func (h *AuctionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Read 2.5 ext locations (schain, gdpr, consent, eids, ...) as their 2.6 fields
+	openrtb.ConvertTo26(&bidRequest)
+
 	// Validate request
 	err = validateBidRequest(&bidRequest)
 	if err != nil {
@@ -83,6 +100,15 @@ func (h *AuctionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Fill device, page and Topics signals the body omits from the browser's headers
+	applyRequestHeaders(w, r, &bidRequest)
+
+	// Parse the uids cookie once; every parse is counted in the verification metrics
+	uids := usersync.ParseCookie(r)
+
+	// Add the first-party SharedID before UID resolution so it also keys the UID store
+	h.applySharedID(w, r, uids, &bidRequest)
+
 	// Build auction request
 	// P2-1: Debug mode requires authentication to prevent information disclosure
 	debugRequested := r.URL.Query().Get("debug") == "1"
@@ -103,6 +129,7 @@ func (h *AuctionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	auctionReq := &exchange.AuctionRequest{
 		BidRequest: &bidRequest,
 		Debug:      debugEnabled,
+		UserIDs:    h.resolveUserIDs(r, uids, &bidRequest),
 	}
 
 	// Run auction
@@ -164,9 +191,24 @@ func (h *AuctionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Build response with extensions
 	response := result.BidResponse
+	var ext *openrtb.BidResponseExt
 	if auctionReq.Debug && result.DebugInfo != nil {
 		// Add debug info to extension
-		ext := buildResponseExt(result)
+		ext = buildResponseExt(result)
+	}
+	var prebidExt openrtb.ExtBidResponsePrebid
+	if len(result.FledgeAuctionConfigs) > 0 {
+		// Protected Audience configs are returned on every response, not just debug ones
+		prebidExt.Fledge = &openrtb.ExtBidResponseFledge{AuctionConfigs: result.FledgeAuctionConfigs}
+	}
+	prebidExt.UIDStoreToken = h.uidStoreToken(r, uids, &bidRequest)
+	if prebidExt.Fledge != nil || prebidExt.UIDStoreToken != "" {
+		if ext == nil {
+			ext = &openrtb.BidResponseExt{}
+		}
+		ext.Prebid = &prebidExt
+	}
+	if ext != nil {
 		if extBytes, err := json.Marshal(ext); err == nil {
 			response.Ext = extBytes
 		}
@@ -180,6 +222,164 @@ func (h *AuctionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// applySharedID injects the server-generated SharedID into user.eids and refreshes the pubcid cookie
+// Only site traffic without a page-supplied pubcid.org EID is eligible. COPPA, opt-outs and
+// missing storage consent (TCF purpose 1 or a US opt-out) skip it. The EID still passes
+// through the exchange's EID filter.
+func (h *AuctionHandler) applySharedID(w http.ResponseWriter, r *http.Request, uids *usersync.Cookie, req *openrtb.BidRequest) {
+	if !h.sharedID.Enabled() || req.Site == nil || (req.Regs != nil && req.Regs.COPPA == 1) {
+		return
+	}
+	if usersync.HasSharedIDEID(req.User) {
+		return
+	}
+
+	consent := middleware.EvaluateSyncConsent(middleware.SyncPrivacyFromRequest(req))
+	if allowed, _ := consent.AllowsSync(0); !allowed {
+		return
+	}
+
+	id, _, err := h.sharedID.Resolve(r, uids)
+	if err != nil {
+		logger.Log.Warn().Err(err).Str("request_id", req.ID).Msg("Failed to mint SharedID")
+		return
+	}
+	if id == "" {
+		return
+	}
+
+	if c := h.sharedID.Cookie(id, r); c != nil {
+		http.SetCookie(w, c)
+	}
+	usersync.InjectSharedIDEID(req, id)
+}
+
+// resolveUserIDs returns bidder UIDs from the uids cookie and the server-side UID store
+// Cookie UIDs take precedence; the store fills bidders the cookie lacks (app, CTV, trimmed cookies)
+func (h *AuctionHandler) resolveUserIDs(r *http.Request, uids *usersync.Cookie, req *openrtb.BidRequest) map[string]string {
+	if req.Regs != nil && req.Regs.COPPA == 1 {
+		return nil
+	}
+
+	if uids.IsOptOut() {
+		return nil
+	}
+	userIDs := uids.GetAllUIDs()
+
+	if !h.uidStore.Enabled() {
+		return userIDs
+	}
+	keys := uidStoreKeysFromRequest(r, req)
+	if len(keys) == 0 {
+		return userIDs
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.uidStore.LookupTimeout())
+	defer cancel()
+
+	stored, err := h.uidStore.Lookup(ctx, keys)
+	if err != nil {
+		logger.Log.Debug().Err(err).Str("request_id", req.ID).Msg("UID store lookup failed")
+		return userIDs
+	}
+	if stored.OptOut {
+		return nil
+	}
+
+	for bidder, uid := range stored.UIDs {
+		if _, exists := userIDs[bidder]; !exists {
+			userIDs[bidder] = uid
+		}
+	}
+	return userIDs
+}
+
+// uidStoreToken signs the UID store keys the server can vouch for so the client's sync pixels can write them
+// No token is issued for COPPA or opted-out users, or when the store is disabled.
+func (h *AuctionHandler) uidStoreToken(r *http.Request, uids *usersync.Cookie, req *openrtb.BidRequest) string {
+	if !h.uidStore.Enabled() || (req.Regs != nil && req.Regs.COPPA == 1) || uids.IsOptOut() {
+		return ""
+	}
+	return usersync.IssueStoreToken(trustedStoreKeys(r, req))
+}
+
+// trustedStoreKeys returns the UID store keys that may be signed into a store token
+// The _pubcid cookie is always trusted. device.ifa and user.id are raw body fields anyone can
+// set, so they are only signed when the request carries an auction API key of its publisher.
+func trustedStoreKeys(r *http.Request, req *openrtb.BidRequest) []usersync.StoreKey {
+	var keys []usersync.StoreKey
+	if key, ok := pubCIDCookieKey(r); ok {
+		keys = append(keys, key)
+	}
+	if !publisherAuthenticated(r, req) {
+		return keys
+	}
+	for _, key := range uidStoreKeysFromRequest(r, req) {
+		if key.Type != usersync.IDTypePubCID {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// publisherAuthenticated reports whether the request carries an auction API key issued to its publisher
+func publisherAuthenticated(r *http.Request, req *openrtb.BidRequest) bool {
+	identity := middleware.APIKeyFromContext(r.Context())
+	if !identity.HasScope(middleware.ScopeAuction) || identity.PublisherID == "" {
+		return false
+	}
+	return identity.PublisherID == requestPublisherID(req)
+}
+
+// requestPublisherID returns the site or app publisher ID of a bid request
+func requestPublisherID(req *openrtb.BidRequest) string {
+	if req.Site != nil && req.Site.Publisher != nil {
+		return req.Site.Publisher.ID
+	}
+	if req.App != nil && req.App.Publisher != nil {
+		return req.App.Publisher.ID
+	}
+	return ""
+}
+
+// uidStoreKeysFromRequest returns UID store keys for a bid request in lookup priority order:
+// device IFA, pubcid (user.eids or first-party cookie), then the hashed publisher user ID
+func uidStoreKeysFromRequest(r *http.Request, req *openrtb.BidRequest) []usersync.StoreKey {
+	var keys []usersync.StoreKey
+
+	if req.Device != nil && (req.Device.Lmt == nil || *req.Device.Lmt != 1) {
+		if key, ok := usersync.IFAKey(req.Device.IFA); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	pubcid := ""
+	if req.User != nil {
+		for _, eid := range req.User.EIDs {
+			if eid.Source == "pubcid.org" && len(eid.UIDs) > 0 {
+				pubcid = eid.UIDs[0].ID
+				break
+			}
+		}
+	}
+	if pubcid == "" {
+		if c, err := r.Cookie(usersync.PubCIDCookieName); err == nil {
+			pubcid = c.Value
+		}
+	}
+	if key, ok := usersync.PubCIDKey(pubcid); ok {
+		keys = append(keys, key)
+	}
+
+	if req.User != nil {
+		if key, ok := usersync.PPUIDKey(requestPublisherID(req), req.User.ID); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
 // validateBidRequest validates the bid request
 func validateBidRequest(req *openrtb.BidRequest) error {
 	if req.ID == "" {
@@ -234,6 +434,17 @@ func buildResponseExt(result *exchange.AuctionResponse) *openrtb.BidResponseExt
 			ext.Errors[bidder] = messages
 		}
 
+		for bidder, fields := range result.DebugInfo.MinimizedFields {
+			if ext.DataMinimization == nil {
+				ext.DataMinimization = make(map[string][]openrtb.ExtMinimizedField)
+			}
+			minimized := make([]openrtb.ExtMinimizedField, len(fields))
+			for i, f := range fields {
+				minimized[i] = openrtb.ExtMinimizedField{Field: f.Field, Action: f.Action, Reason: f.Reason}
+			}
+			ext.DataMinimization[bidder] = minimized
+		}
+
 		ext.TMMaxRequest = int(result.DebugInfo.TotalLatency.Milliseconds())
 	}
 
@@ -249,31 +460,11 @@ func writeError(w http.ResponseWriter, message string, status int) {
 	}
 }
 
-// hasAPIKey checks if request has valid API key
-// P2-1: Used to gate debug mode access
+// hasAPIKey checks if request was authenticated with an API key granting the debug scope
+// P2-1: Used to gate debug mode access. The identity is set by the auth middleware from a
+// validated key, so headers such as X-Publisher-ID can't be injected to enable debug.
 func hasAPIKey(r *http.Request) bool {
-	// Check context first (secure - can't be spoofed by client)
-	if publisherID, ok := GetPublisherID(r.Context()); ok && publisherID != "" {
-		return true
-	}
-
-	// Fallback: check if auth middleware set X-Publisher-ID header
-	// SECURITY NOTE: Auth middleware should strip incoming X-Publisher-ID headers
-	// to prevent header injection attacks
-	publisherIDHeader := r.Header.Get("X-Publisher-ID")
-	if publisherIDHeader != "" && len(publisherIDHeader) > 0 {
-		// Additional validation: publisher ID should look like a valid ID
-		// (not just "1" or "test" which could be injected)
-		// Basic check: should be alphanumeric and reasonable length
-		if len(publisherIDHeader) >= 8 {
-			return true
-		}
-		logger.Log.Warn().
-			Str("publisher_id", publisherIDHeader).
-			Msg("Rejecting suspicious X-Publisher-ID header (too short, possible injection attempt)")
-	}
-
-	return false
+	return middleware.APIKeyFromContext(r.Context()).HasScope(middleware.ScopeDebug)
 }
 
 // StatusHandler handles /status requests
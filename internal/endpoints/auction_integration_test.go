@@ -809,3 +809,69 @@ func TestAuctionIntegration_DifferentMediaTypes(t *testing.T) {
 		t.Error("expected seatbids for different media types")
 	}
 }
+
+// mockFledgeAdapter returns a bid and a Protected Audience auction config for every impression
+type mockFledgeAdapter struct {
+	mockSuccessfulAdapter
+}
+
+func (m *mockFledgeAdapter) MakeBids(request *openrtb.BidRequest, response *adapters.ResponseData) (*adapters.BidderResponse, []error) {
+	resp, errs := m.mockSuccessfulAdapter.MakeBids(request, response)
+	for _, imp := range request.Imp {
+		resp.FledgeAuctionConfigs = append(resp.FledgeAuctionConfigs, &openrtb.FledgeAuctionConfig{
+			ImpID:  imp.ID,
+			Config: json.RawMessage(`{"seller":"https://ssp.example"}`),
+		})
+	}
+	return resp, errs
+}
+
+// TestAuctionIntegration_FledgeAuctionConfigs tests that auction configs reach ext.prebid.fledge without debug
+func TestAuctionIntegration_FledgeAuctionConfigs(t *testing.T) {
+	registry := adapters.NewRegistry()
+	registry.Register("pabidder", &mockFledgeAdapter{mockSuccessfulAdapter{bidPrice: 1.50}}, adapters.BidderInfo{
+		Enabled:         true,
+		FledgeSupported: true,
+		DemandType:      adapters.DemandTypePublisher,
+	})
+
+	ex := exchange.New(registry, &exchange.Config{
+		DefaultTimeout: 1000 * time.Millisecond,
+		IDREnabled:     false,
+	})
+	handler := NewAuctionHandler(ex)
+
+	bidReq := &openrtb.BidRequest{
+		ID: "test-auction-fledge",
+		Imp: []openrtb.Imp{
+			{ID: "imp-1", Banner: &openrtb.Banner{W: 300, H: 250}, Ext: []byte(`{"ae":1}`)},
+			{ID: "imp-2", Banner: &openrtb.Banner{W: 728, H: 90}},
+		},
+		Site: &openrtb.Site{ID: "site-1", Domain: "example.com"},
+	}
+	body, _ := json.Marshal(bidReq)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/openrtb2/auction", bytes.NewReader(body)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Ext openrtb.BidResponseExt `json:"ext"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Ext.Prebid == nil || resp.Ext.Prebid.Fledge == nil {
+		t.Fatalf("expected ext.prebid.fledge, got %s", w.Body.String())
+	}
+	configs := resp.Ext.Prebid.Fledge.AuctionConfigs
+	if len(configs) != 1 || configs[0].ImpID != "imp-1" || configs[0].Bidder != "pabidder" {
+		t.Errorf("expected one auction config for imp-1 from pabidder, got %+v", configs)
+	}
+	if len(resp.Ext.ResponseTimeMillis) != 0 {
+		t.Error("debug fields should not be set without debug")
+	}
+}
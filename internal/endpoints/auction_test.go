@@ -14,7 +14,9 @@ import (
 
 	"github.com/thenexusengine/tne_springwire/internal/adapters"
 	"github.com/thenexusengine/tne_springwire/internal/exchange"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
 	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/usersync"
 )
 
 // Mock adapter for testing
@@ -389,7 +391,8 @@ func TestHasAPIKey(t *testing.T) {
 	tests := []struct {
 		name        string
 		headers     map[string]string
-		publisherID string // Publisher ID to set in context (empty = not set)
+		publisherID string                     // Publisher ID to set in context (empty = not set)
+		identity    *middleware.APIKeyIdentity // API key identity set by the auth middleware
 		expected    bool
 	}{
 		{
@@ -398,31 +401,30 @@ func TestHasAPIKey(t *testing.T) {
 			expected: false,
 		},
 		{
-			name:        "publisher ID in context",
-			headers:     map[string]string{},
-			publisherID: "pub-12345678",
-			expected:    true,
-		},
-		{
-			name:     "X-Publisher-ID header valid (8+ chars)",
-			headers:  map[string]string{"X-Publisher-ID": "pub-12345"},
+			name:     "key with debug scope",
+			identity: &middleware.APIKeyIdentity{PublisherID: "pub-12345678", Scopes: []string{middleware.ScopeAuction, middleware.ScopeDebug}},
 			expected: true,
 		},
 		{
-			name:     "X-Publisher-ID header too short (<8 chars)",
-			headers:  map[string]string{"X-Publisher-ID": "pub-123"},
+			name:     "key without debug scope",
+			identity: &middleware.APIKeyIdentity{PublisherID: "pub-12345678", Scopes: []string{middleware.ScopeAuction}},
 			expected: false,
 		},
 		{
-			name:     "empty X-Publisher-ID",
-			headers:  map[string]string{"X-Publisher-ID": ""},
+			name:        "publisher ID in context without a key",
+			headers:     map[string]string{},
+			publisherID: "pub-12345678",
+			expected:    false,
+		},
+		{
+			name:     "X-Publisher-ID header alone",
+			headers:  map[string]string{"X-Publisher-ID": "pub-12345"},
 			expected: false,
 		},
 		{
-			name:        "context overrides short header",
-			headers:     map[string]string{"X-Publisher-ID": "short"},
-			publisherID: "pub-from-context",
-			expected:    true,
+			name:     "API key header not validated by auth middleware",
+			headers:  map[string]string{"X-API-Key": "test-key"},
+			expected: false,
 		},
 	}
 
@@ -438,6 +440,9 @@ func TestHasAPIKey(t *testing.T) {
 				ctx := SetPublisherID(req.Context(), tt.publisherID)
 				req = req.WithContext(ctx)
 			}
+			if tt.identity != nil {
+				req = req.WithContext(middleware.ContextWithAPIKey(req.Context(), tt.identity))
+			}
 
 			result := hasAPIKey(req)
 			if result != tt.expected {
@@ -694,6 +699,29 @@ func TestBuildResponseExt_WithErrors(t *testing.T) {
 	}
 }
 
+func TestBuildResponseExt_WithDataMinimization(t *testing.T) {
+	result := &exchange.AuctionResponse{
+		DebugInfo: &exchange.DebugInfo{
+			BidderLatencies: map[string]time.Duration{},
+			MinimizedFields: map[string][]middleware.MinimizedField{
+				"bidder1": {
+					{Field: "device.ip", Action: middleware.MinimizeActionTruncated, Reason: middleware.MinimizeReasonUSOptOut},
+					{Field: "user.eids", Action: middleware.MinimizeActionRemoved, Reason: middleware.MinimizeReasonUSOptOut},
+				},
+			},
+		},
+	}
+	ext := buildResponseExt(result)
+
+	fields := ext.DataMinimization["bidder1"]
+	if len(fields) != 2 {
+		t.Fatalf("expected 2 minimized fields for bidder1, got %+v", ext.DataMinimization)
+	}
+	if fields[0] != (openrtb.ExtMinimizedField{Field: "device.ip", Action: "truncated", Reason: "us_optout"}) {
+		t.Errorf("unexpected minimized field: %+v", fields[0])
+	}
+}
+
 // Test writeError
 func TestWriteError(t *testing.T) {
 	w := httptest.NewRecorder()
@@ -983,3 +1011,291 @@ func BenchmarkAuctionHandler_ValidRequest(b *testing.B) {
 		handler.ServeHTTP(w, req)
 	}
 }
+
+func TestAuctionHandler_ResolveUserIDs(t *testing.T) {
+	store := setupTestUIDStore(t)
+	ctx := context.Background()
+	ifaKey, _ := usersync.IFAKey("ifa-ctv")
+	_ = store.SetUID(ctx, ifaKey, "appnexus", "store-an") //nolint:errcheck // Test setup
+	_ = store.SetUID(ctx, ifaKey, "rubicon", "store-rp")  //nolint:errcheck // Test setup
+
+	handler := NewAuctionHandler(nil)
+	handler.SetUIDStore(store)
+
+	cookie := usersync.NewCookie()
+	cookie.SetUID("appnexus", "cookie-an")
+	httpCookie, _ := cookie.ToHTTPCookie("example.com") //nolint:errcheck // Test setup
+
+	bidReq := validBidRequest()
+	bidReq.Device = &openrtb.Device{IFA: "IFA-CTV"}
+
+	req := httptest.NewRequest("POST", "/openrtb2/auction", nil)
+	req.AddCookie(httpCookie)
+
+	uids := handler.resolveUserIDs(req, usersync.ParseCookie(req), bidReq)
+
+	if uids["appnexus"] != "cookie-an" {
+		t.Errorf("Cookie UID should take precedence, got %q", uids["appnexus"])
+	}
+	if uids["rubicon"] != "store-rp" {
+		t.Errorf("Store should fill bidders missing from the cookie, got %q", uids["rubicon"])
+	}
+
+	t.Run("coppa", func(t *testing.T) {
+		coppaReq := validBidRequest()
+		coppaReq.Device = &openrtb.Device{IFA: "ifa-ctv"}
+		coppaReq.Regs = &openrtb.Regs{COPPA: 1}
+		if uids := handler.resolveUserIDs(req, usersync.ParseCookie(req), coppaReq); len(uids) != 0 {
+			t.Errorf("COPPA requests should carry no UIDs, got %v", uids)
+		}
+	})
+
+	t.Run("store opt-out", func(t *testing.T) {
+		_ = store.OptOut(ctx, ifaKey) //nolint:errcheck // Test setup
+		if uids := handler.resolveUserIDs(req, usersync.ParseCookie(req), bidReq); len(uids) != 0 {
+			t.Errorf("Opted-out identity should carry no UIDs, got %v", uids)
+		}
+	})
+}
+
+func TestUIDStoreKeysFromRequest(t *testing.T) {
+	lmt := 1
+	bidReq := validBidRequest()
+	bidReq.Site.Publisher = &openrtb.Publisher{ID: "pub1"}
+	bidReq.Device = &openrtb.Device{IFA: "limited-ifa", Lmt: &lmt}
+	bidReq.User = &openrtb.User{
+		ID:   "pub-user",
+		EIDs: []openrtb.EID{{Source: "pubcid.org", UIDs: []openrtb.UID{{ID: "eid-pubcid"}}}},
+	}
+
+	req := httptest.NewRequest("POST", "/openrtb2/auction", nil)
+	keys := uidStoreKeysFromRequest(req, bidReq)
+
+	ppuidKey, _ := usersync.PPUIDKey("pub1", "pub-user")
+	expected := []usersync.StoreKey{{Type: usersync.IDTypePubCID, Value: "eid-pubcid"}, ppuidKey}
+	if len(keys) != len(expected) {
+		t.Fatalf("Expected %d keys (IFA skipped for lmt=1), got %+v", len(expected), keys)
+	}
+	for i := range expected {
+		if keys[i] != expected[i] {
+			t.Errorf("Key %d: expected %+v, got %+v", i, expected[i], keys[i])
+		}
+	}
+}
+
+func TestAuctionHandler_UIDStoreToken(t *testing.T) {
+	handler := NewAuctionHandler(nil)
+	handler.SetUIDStore(setupTestUIDStore(t))
+	issueTestStoreToken(t)
+
+	ifaKey, _ := usersync.IFAKey("ifa-app")
+	ppuidKey, _ := usersync.PPUIDKey("pub1", "pub-user")
+	pubcidKey, _ := usersync.PubCIDKey("cookie-pubcid")
+	appRequest := func() *openrtb.BidRequest {
+		bidReq := validBidRequest()
+		bidReq.Site = nil
+		bidReq.App = &openrtb.App{ID: "app-1", Publisher: &openrtb.Publisher{ID: "pub1"}}
+		bidReq.Device = &openrtb.Device{IFA: "ifa-app"}
+		bidReq.User = &openrtb.User{
+			ID:   "pub-user",
+			EIDs: []openrtb.EID{{Source: "pubcid.org", UIDs: []openrtb.UID{{ID: "body-pubcid"}}}},
+		}
+		return bidReq
+	}
+
+	tests := []struct {
+		name     string
+		identity *middleware.APIKeyIdentity
+		cookie   bool
+		want     []usersync.StoreKey
+	}{
+		{"unauthenticated body identities", nil, false, nil},
+		{"pubcid cookie", nil, true, []usersync.StoreKey{pubcidKey}},
+		{"publisher api key", &middleware.APIKeyIdentity{PublisherID: "pub1", Scopes: []string{middleware.ScopeAuction}}, false, []usersync.StoreKey{ifaKey, ppuidKey}},
+		{"publisher api key and cookie", &middleware.APIKeyIdentity{PublisherID: "pub1", Scopes: []string{middleware.ScopeAuction}}, true, []usersync.StoreKey{pubcidKey, ifaKey, ppuidKey}},
+		{"other publisher's api key", &middleware.APIKeyIdentity{PublisherID: "pub2", Scopes: []string{middleware.ScopeAuction}}, false, nil},
+		{"api key without auction scope", &middleware.APIKeyIdentity{PublisherID: "pub1", Scopes: []string{middleware.ScopeDebug}}, false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/openrtb2/auction", nil)
+			if tt.identity != nil {
+				req = req.WithContext(middleware.ContextWithAPIKey(req.Context(), tt.identity))
+			}
+			if tt.cookie {
+				req.AddCookie(&http.Cookie{Name: usersync.PubCIDCookieName, Value: "cookie-pubcid"})
+			}
+
+			token := handler.uidStoreToken(req, usersync.ParseCookie(req), appRequest())
+			keys := usersync.VerifyStoreToken(token)
+			if len(keys) != len(tt.want) {
+				t.Fatalf("Expected keys %+v, got %+v", tt.want, keys)
+			}
+			for i := range tt.want {
+				if keys[i] != tt.want[i] {
+					t.Errorf("Key %d: expected %+v, got %+v", i, tt.want[i], keys[i])
+				}
+			}
+		})
+	}
+
+	t.Run("coppa", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/openrtb2/auction", nil)
+		req.AddCookie(&http.Cookie{Name: usersync.PubCIDCookieName, Value: "cookie-pubcid"})
+		bidReq := appRequest()
+		bidReq.Regs = &openrtb.Regs{COPPA: 1}
+		if token := handler.uidStoreToken(req, usersync.ParseCookie(req), bidReq); token != "" {
+			t.Errorf("Expected no token for COPPA requests, got %q", token)
+		}
+	})
+}
+
+// firstPartyAuctionRequest returns an auction request from a page on the SharedID test domain
+func firstPartyAuctionRequest() *http.Request {
+	r := httptest.NewRequest("POST", "/openrtb2/auction", nil)
+	r.Header.Set("Origin", "https://www.example.com")
+	return r
+}
+
+// sharedIDTestMinter returns a minter whose cookie domain covers the httptest host
+func sharedIDTestMinter() *usersync.SharedIDMinter {
+	return usersync.NewSharedIDMinter(&usersync.SharedIDConfig{Enabled: true, CookieDomains: []string{"example.com"}})
+}
+
+func TestAuctionHandler_SharedID(t *testing.T) {
+	handler := NewAuctionHandler(nil)
+	handler.SetSharedID(sharedIDTestMinter())
+
+	gdpr := 1
+	tests := []struct {
+		name   string
+		modify func(*openrtb.BidRequest)
+		cookie *http.Cookie
+		want   bool
+	}{
+		{"site request", func(r *openrtb.BidRequest) {}, nil, true},
+		{"app request", func(r *openrtb.BidRequest) { r.Site = nil; r.App = &openrtb.App{ID: "app"} }, nil, false},
+		{"coppa", func(r *openrtb.BidRequest) { r.Regs = &openrtb.Regs{COPPA: 1} }, nil, false},
+		{"gdpr without consent", func(r *openrtb.BidRequest) { r.Regs = &openrtb.Regs{GDPR: &gdpr} }, nil, false},
+		{"gdpr with consent", func(r *openrtb.BidRequest) {
+			r.Regs = &openrtb.Regs{GDPR: &gdpr}
+			r.User = &openrtb.User{Consent: testTCFConsent}
+		}, nil, true},
+		{"us opt-out", func(r *openrtb.BidRequest) { r.Regs = &openrtb.Regs{USPrivacy: "1YYN"} }, nil, false},
+		{"opt-out cookie", func(r *openrtb.BidRequest) {}, &http.Cookie{Name: usersync.SharedIDOptOutCookieName, Value: "1"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidReq := validBidRequest()
+			tt.modify(bidReq)
+			r := firstPartyAuctionRequest()
+			if tt.cookie != nil {
+				r.AddCookie(tt.cookie)
+			}
+			w := httptest.NewRecorder()
+
+			handler.applySharedID(w, r, usersync.ParseCookie(r), bidReq)
+
+			got := usersync.HasSharedIDEID(bidReq.User)
+			if got != tt.want {
+				t.Errorf("Expected SharedID injected=%v, got %v", tt.want, got)
+			}
+			setCookie := strings.Contains(w.Header().Get("Set-Cookie"), usersync.PubCIDCookieName+"=")
+			if setCookie != tt.want {
+				t.Errorf("Expected pubcid cookie set=%v, got %v", tt.want, setCookie)
+			}
+		})
+	}
+}
+
+func TestAuctionHandler_SharedIDThirdPartyPage(t *testing.T) {
+	handler := NewAuctionHandler(nil)
+	handler.SetSharedID(sharedIDTestMinter())
+
+	// The cookie would not come back from another site, so no ID is minted or injected
+	bidReq := validBidRequest()
+	r := httptest.NewRequest("POST", "/openrtb2/auction", nil)
+	r.Header.Set("Origin", "https://other.com")
+	w := httptest.NewRecorder()
+	handler.applySharedID(w, r, usersync.ParseCookie(r), bidReq)
+	if usersync.HasSharedIDEID(bidReq.User) || w.Header().Get("Set-Cookie") != "" {
+		t.Errorf("Expected no SharedID for a third-party page, got %+v", bidReq.User)
+	}
+}
+
+func TestAuctionHandler_SharedIDReusesCookieAndEID(t *testing.T) {
+	handler := NewAuctionHandler(nil)
+	handler.SetSharedID(sharedIDTestMinter())
+
+	// The existing pubcid cookie is injected and refreshed
+	bidReq := validBidRequest()
+	r := firstPartyAuctionRequest()
+	r.AddCookie(&http.Cookie{Name: usersync.PubCIDCookieName, Value: "existing-pubcid"})
+	handler.applySharedID(httptest.NewRecorder(), r, usersync.ParseCookie(r), bidReq)
+	if len(bidReq.User.EIDs) != 1 || bidReq.User.EIDs[0].UIDs[0].ID != "existing-pubcid" {
+		t.Errorf("Expected existing pubcid EID, got %+v", bidReq.User.EIDs)
+	}
+
+	// A page-supplied pubcid EID is left alone and no cookie is written
+	bidReq = validBidRequest()
+	bidReq.User = &openrtb.User{EIDs: []openrtb.EID{{Source: "pubcid.org", UIDs: []openrtb.UID{{ID: "page-id"}}}}}
+	w := httptest.NewRecorder()
+	handler.applySharedID(w, httptest.NewRequest("POST", "/openrtb2/auction", nil), usersync.NewCookie(), bidReq)
+	if len(bidReq.User.EIDs) != 1 || bidReq.User.EIDs[0].UIDs[0].ID != "page-id" {
+		t.Errorf("Expected page EID to be kept, got %+v", bidReq.User.EIDs)
+	}
+	if w.Header().Get("Set-Cookie") != "" {
+		t.Error("Expected no cookie when the page supplies a pubcid EID")
+	}
+
+	// Disabled minter does nothing
+	bidReq = validBidRequest()
+	NewAuctionHandler(nil).applySharedID(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil), usersync.NewCookie(), bidReq)
+	if bidReq.User != nil {
+		t.Error("Expected no SharedID when minting is disabled")
+	}
+}
+
+// countingCookieMetrics counts uids cookie verifications
+type countingCookieMetrics struct {
+	count int
+}
+
+func (m *countingCookieMetrics) RecordUIDCookieVerification(result string) {
+	m.count++
+}
+
+func TestAuctionHandler_ParsesUIDsCookieOnce(t *testing.T) {
+	metrics := &countingCookieMetrics{}
+	cfg := &usersync.SecurityConfig{
+		SigningKeys: []usersync.SigningKey{{ID: "t1", Secret: []byte("0123456789abcdef0123456789abcdef")}},
+	}
+	if err := usersync.ConfigureSecurity(cfg, metrics); err != nil {
+		t.Fatalf("ConfigureSecurity failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = usersync.ConfigureSecurity(nil, nil) //nolint:errcheck // nil config cannot fail
+	})
+
+	cookie := usersync.NewCookie()
+	cookie.SetUID("appnexus", "an-uid")
+	httpCookie, err := cookie.ToHTTPCookie("example.com")
+	if err != nil {
+		t.Fatalf("Failed to build uids cookie: %v", err)
+	}
+
+	handler := NewAuctionHandler(exchange.New(adapters.NewRegistry(), &exchange.Config{DefaultTimeout: 100 * time.Millisecond}))
+	handler.SetSharedID(usersync.NewSharedIDMinter(&usersync.SharedIDConfig{Enabled: true}))
+	handler.SetUIDStore(setupTestUIDStore(t))
+
+	body, _ := json.Marshal(validBidRequest())
+	req := httptest.NewRequest("POST", "/openrtb2/auction", bytes.NewReader(body))
+	req.AddCookie(httpCookie)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if metrics.count != 1 {
+		t.Errorf("Expected the uids cookie to be verified once per auction, got %d", metrics.count)
+	}
+}
@@ -0,0 +1,94 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// AuditLog reads admin audit entries
+type AuditLog interface {
+	List(ctx context.Context, filter storage.AuditFilter) ([]*middleware.AuditEntry, error)
+}
+
+// AuditAdminHandler serves the admin audit log
+type AuditAdminHandler struct {
+	audit AuditLog
+}
+
+// NewAuditAdminHandler creates an audit log handler
+func NewAuditAdminHandler(audit AuditLog) *AuditAdminHandler {
+	return &AuditAdminHandler{audit: audit}
+}
+
+// AuditListResponse is the response for listing audit entries
+type AuditListResponse struct {
+	Entries []*middleware.AuditEntry `json:"entries"`
+	Count   int                      `json:"count"`
+}
+
+// ServeHTTP handles audit log requests
+// Routes:
+//
+//	GET /admin/audit?actor=x&path=/admin/publishers&since=RFC3339&limit=100 - Newest entries first
+func (h *AuditAdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.audit == nil {
+		h.sendError(w, http.StatusServiceUnavailable, "database_unavailable", "Audit log requires a database connection")
+		return
+	}
+	if r.Method != http.MethodGet {
+		h.sendError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := storage.AuditFilter{
+		Actor: query.Get("actor"),
+		Path:  query.Get("path"),
+	}
+	if since := query.Get("since"); since != "" {
+		parsed, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			h.sendError(w, http.StatusBadRequest, "invalid_since", "since must be an RFC 3339 timestamp")
+			return
+		}
+		filter.Since = parsed
+	}
+	if limit := query.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed <= 0 {
+			h.sendError(w, http.StatusBadRequest, "invalid_limit", "limit must be a positive integer")
+			return
+		}
+		filter.Limit = parsed
+	}
+
+	entries, err := h.audit.List(r.Context(), filter)
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to list admin audit entries")
+		h.sendError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve audit log")
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, AuditListResponse{Entries: entries, Count: len(entries)})
+}
+
+// sendJSON sends a JSON response
+func (h *AuditAdminHandler) sendJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to encode JSON response")
+	}
+}
+
+// sendError sends a JSON error response
+func (h *AuditAdminHandler) sendError(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	h.sendJSON(w, statusCode, ErrorResponse{Error: errorCode, Message: message})
+}
@@ -0,0 +1,146 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+// fakeAuditLog records filters and returns canned entries (test helper)
+type fakeAuditLog struct {
+	entries []*middleware.AuditEntry
+	err     error
+	filter  storage.AuditFilter
+}
+
+func (f *fakeAuditLog) List(ctx context.Context, filter storage.AuditFilter) ([]*middleware.AuditEntry, error) {
+	f.filter = filter
+	return f.entries, f.err
+}
+
+func (f *fakeAuditLog) Record(ctx context.Context, entry *middleware.AuditEntry) error {
+	f.entries = append(f.entries, entry)
+	return f.err
+}
+
+func TestAuditAdminHandler_List(t *testing.T) {
+	audit := &fakeAuditLog{entries: []*middleware.AuditEntry{
+		{ID: 1, Actor: "ops", Method: http.MethodDelete, Path: "/admin/publishers/pub1", StatusCode: 200, Before: json.RawMessage(`{"id":"pub1"}`)},
+	}}
+	handler := NewAuditAdminHandler(audit)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/audit?actor=ops&path=/admin/publishers&since=2026-01-01T00:00:00Z&limit=10", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var response AuditListResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if response.Count != 1 || string(response.Entries[0].Before) != `{"id":"pub1"}` {
+		t.Errorf("unexpected response %+v", response)
+	}
+
+	want := storage.AuditFilter{Actor: "ops", Path: "/admin/publishers", Since: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Limit: 10}
+	if !audit.filter.Since.Equal(want.Since) || audit.filter.Actor != want.Actor || audit.filter.Path != want.Path || audit.filter.Limit != want.Limit {
+		t.Errorf("expected filter %+v, got %+v", want, audit.filter)
+	}
+}
+
+func TestAuditAdminHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler *AuditAdminHandler
+		method  string
+		target  string
+		want    int
+	}{
+		{"no database", NewAuditAdminHandler(nil), http.MethodGet, "/admin/audit", http.StatusServiceUnavailable},
+		{"wrong method", NewAuditAdminHandler(&fakeAuditLog{}), http.MethodPost, "/admin/audit", http.StatusMethodNotAllowed},
+		{"invalid since", NewAuditAdminHandler(&fakeAuditLog{}), http.MethodGet, "/admin/audit?since=yesterday", http.StatusBadRequest},
+		{"invalid limit", NewAuditAdminHandler(&fakeAuditLog{}), http.MethodGet, "/admin/audit?limit=-1", http.StatusBadRequest},
+		{"query error", NewAuditAdminHandler(&fakeAuditLog{err: errors.New("db down")}), http.MethodGet, "/admin/audit", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			tt.handler.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.target, nil))
+			if rr.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestAdminHandlers_RecordAuditDiffs(t *testing.T) {
+	client, _ := setupTestRedisForPublisher(t)
+	keys := middleware.NewAPIKeyStore(client)
+	raw, _, err := keys.Mint(context.Background(), middleware.APIKeySpec{
+		PublisherID: "ops-team",
+		Name:        "ops",
+		Scopes:      []string{middleware.ScopeAdminRead, middleware.ScopeAdminWrite},
+		Role:        middleware.RoleAdmin,
+	})
+	if err != nil {
+		t.Fatalf("mint failed: %v", err)
+	}
+
+	auth := middleware.NewAuth(&middleware.AuthConfig{Enabled: true, HeaderName: "X-API-Key"})
+	auth.SetKeyStore(keys)
+	adminAuth := middleware.NewAdminAuth(&middleware.AdminAuthConfig{Enabled: true}, auth)
+	sink := &fakeAuditLog{}
+	adminAuth.SetAuditSink(sink)
+
+	publishers := adminAuth.Protect(middleware.AdminRoute{Read: middleware.PermAdminRead, Write: middleware.PermPublishersWrite}, NewPublisherAdminHandler(newFakePublisherStore(), client))
+	apiKeys := adminAuth.Protect(middleware.AdminRoute{Read: middleware.PermAPIKeysRead, Write: middleware.PermAPIKeysWrite}, NewAPIKeyAdminHandler(keys))
+
+	serve := func(handler http.Handler, method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("X-API-Key", raw)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve(publishers, http.MethodPost, "/admin/publishers", `{"publisher_id":"pub1","name":"Pub","allowed_domains":"a.com"}`)
+	created := decodePublisher(t, rr)
+	stamp, _ := json.Marshal(created.UpdatedAt)
+	serve(publishers, http.MethodPut, "/admin/publishers/pub1", `{"name":"Pub","allowed_domains":"b.com","updated_at":`+string(stamp)+`}`)
+	serve(publishers, http.MethodDelete, "/admin/publishers/pub1", "")
+	rr = serve(apiKeys, http.MethodPost, "/admin/api-keys", `{"publisher_id":"pub1"}`)
+	var minted APIKeyMintResponse
+	json.Unmarshal(rr.Body.Bytes(), &minted)
+
+	// Reads are not audited
+	serve(publishers, http.MethodGet, "/admin/publishers", "")
+
+	if len(sink.entries) != 4 {
+		t.Fatalf("expected 4 audit entries, got %d", len(sink.entries))
+	}
+	for _, entry := range sink.entries {
+		if entry.Actor != "ops" || entry.ActorRole != middleware.RoleAdmin {
+			t.Errorf("unexpected actor on %s %s: %+v", entry.Method, entry.Path, entry)
+		}
+	}
+
+	update := sink.entries[1]
+	if update.StatusCode != http.StatusOK || !strings.Contains(string(update.Before), "a.com") || !strings.Contains(string(update.After), "b.com") {
+		t.Errorf("expected update diff a.com -> b.com, got %s -> %s", update.Before, update.After)
+	}
+	if deleted := sink.entries[2]; !strings.Contains(string(deleted.Before), `"status":"active"`) || !strings.Contains(string(deleted.After), `"status":"archived"`) {
+		t.Errorf("expected delete to record the archived publisher, got %s -> %s", deleted.Before, deleted.After)
+	}
+	if mint := sink.entries[3]; minted.Key == "" || strings.Contains(string(mint.After), minted.Key) {
+		t.Error("expected the raw key to stay out of the audit log")
+	}
+}
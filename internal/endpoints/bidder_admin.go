@@ -0,0 +1,880 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+	"github.com/thenexusengine/tne_springwire/pkg/redis"
+)
+
+// BidderAdminStore is the bidder storage behind the admin API
+// Implemented by storage.BidderStore.
+type BidderAdminStore interface {
+	Get(ctx context.Context, bidderCode string) (*storage.Bidder, error)
+	List(ctx context.Context) ([]*storage.Bidder, error)
+	GetCapabilities(ctx context.Context, banner, video, native, audio bool) ([]*storage.Bidder, error)
+	Create(ctx context.Context, b *storage.Bidder) error
+	Update(ctx context.Context, b *storage.Bidder) error
+	Delete(ctx context.Context, bidderCode string) error
+	SetEnabled(ctx context.Context, bidderCode string, enabled bool) error
+}
+
+// Bidder timeout bounds from the bidders.timeout_ms check constraint
+const (
+	minBidderTimeoutMs     = 100
+	maxBidderTimeoutMs     = 10000
+	defaultBidderTimeoutMs = 1000
+)
+
+// BidderAdminHandler handles bidder configuration via API
+// Bidders are compiled in; a bidders row overrides the registered adapter's endpoint,
+// headers, GVL ID, media types and enabled state in the running registry.
+type BidderAdminHandler struct {
+	store    BidderAdminStore
+	registry *adapters.Registry
+	client   adapters.HTTPClient // Refuses internal addresses, so test-fire can't read them back
+	bus      InvalidationPublisher
+	lookupIP func(ctx context.Context, host string) ([]net.IP, error)
+}
+
+// NewBidderAdminHandler creates a new bidder admin handler
+func NewBidderAdminHandler(store BidderAdminStore, registry *adapters.Registry) *BidderAdminHandler {
+	return &BidderAdminHandler{
+		store:    store,
+		registry: registry,
+		client:   adapters.NewPublicHTTPClient(maxBidderTimeoutMs * time.Millisecond),
+		lookupIP: func(ctx context.Context, host string) ([]net.IP, error) {
+			return net.DefaultResolver.LookupIP(ctx, "ip", host)
+		},
+	}
+}
+
+// SetInvalidationPublisher sets the bus that tells other instances to reload a bidder after each write
+func (h *BidderAdminHandler) SetInvalidationPublisher(bus InvalidationPublisher) {
+	h.bus = bus
+}
+
+// BidderRuntime is a bidder's effective configuration in the running registry
+type BidderRuntime struct {
+	Enabled         bool               `json:"enabled"`
+	Endpoint        string             `json:"endpoint,omitempty"`
+	DefaultEndpoint string             `json:"default_endpoint,omitempty"`
+	GVLVendorID     int                `json:"gvl_vendor_id,omitempty"`
+	MediaTypes      []adapters.BidType `json:"media_types,omitempty"`
+}
+
+// BidderView is a stored bidder with its runtime configuration
+// Runtime is nil when no adapter is compiled in for the bidder code.
+type BidderView struct {
+	*storage.Bidder
+	Runtime *BidderRuntime `json:"runtime"`
+}
+
+// BidderListResponse is the response for listing bidders
+type BidderListResponse struct {
+	Bidders      []*BidderView `json:"bidders"`
+	Count        int           `json:"count"`
+	Unconfigured []string      `json:"unconfigured,omitempty"` // Compiled adapters without a bidders row
+}
+
+// BidderRequest is the request body for creating/updating bidders
+// PUT replaces the whole bidder.
+type BidderRequest struct {
+	BidderCode       string                 `json:"bidder_code"`
+	BidderName       string                 `json:"bidder_name"`
+	EndpointURL      string                 `json:"endpoint_url"` // "" = the adapter's built-in endpoint
+	TimeoutMs        int                    `json:"timeout_ms"`   // 0 = 1000
+	Enabled          *bool                  `json:"enabled"`      // nil = true
+	Status           string                 `json:"status"`       // "" = active
+	SupportsBanner   bool                   `json:"supports_banner"`
+	SupportsVideo    bool                   `json:"supports_video"`
+	SupportsNative   bool                   `json:"supports_native"`
+	SupportsAudio    bool                   `json:"supports_audio"`
+	GVLVendorID      *int                   `json:"gvl_vendor_id"`
+	HTTPHeaders      map[string]interface{} `json:"http_headers"`
+	Description      string                 `json:"description"`
+	DocumentationURL string                 `json:"documentation_url"`
+	ContactEmail     string                 `json:"contact_email"`
+}
+
+// BidderTestRequest is the request body for test-firing a bidder
+type BidderTestRequest struct {
+	Format    string              `json:"format"`     // banner (default), video, native or audio
+	Params    json.RawMessage     `json:"params"`     // Bidder params, sent in imp.ext.bidder
+	Request   *openrtb.BidRequest `json:"request"`    // Replaces the synthetic request
+	TimeoutMs int                 `json:"timeout_ms"` // 0 = the bidder's timeout_ms
+}
+
+// BidderHTTPExchange is one raw HTTP round trip made during a test-fire
+type BidderHTTPExchange struct {
+	Method          string      `json:"method"`
+	URI             string      `json:"uri"`
+	RequestHeaders  http.Header `json:"request_headers,omitempty"`
+	RequestBody     string      `json:"request_body,omitempty"`
+	StatusCode      int         `json:"status_code,omitempty"`
+	ResponseHeaders http.Header `json:"response_headers,omitempty"`
+	ResponseBody    string      `json:"response_body,omitempty"`
+	DurationMs      float64     `json:"duration_ms"`
+	Error           string      `json:"error,omitempty"`
+}
+
+// BidderTestBid is a bid parsed from a test-fire response
+type BidderTestBid struct {
+	Type adapters.BidType `json:"type"`
+	Bid  *openrtb.Bid     `json:"bid"`
+}
+
+// BidderTestResponse is the result of a test-fire
+type BidderTestResponse struct {
+	BidderCode string               `json:"bidder_code"`
+	Enabled    bool                 `json:"enabled"`
+	Request    *openrtb.BidRequest  `json:"request"`
+	Exchanges  []BidderHTTPExchange `json:"exchanges"`
+	Bids       []BidderTestBid      `json:"bids"`
+	Errors     []string             `json:"errors,omitempty"`
+}
+
+// ServeHTTP handles bidder API requests
+// Routes:
+//
+//	GET    /admin/bidders                 - List bidders (?supports=banner,video for active bidders with those formats)
+//	GET    /admin/bidders/:code           - Get specific bidder (any status) with its runtime configuration
+//	POST   /admin/bidders                 - Create bidder (the code must have a compiled adapter)
+//	PUT    /admin/bidders/:code           - Replace bidder
+//	DELETE /admin/bidders/:code           - Archive and disable bidder
+//	POST   /admin/bidders/:code/enable    - Enable bidder
+//	POST   /admin/bidders/:code/disable   - Disable bidder
+//	POST   /admin/bidders/:code/test      - Send a test bid request and return the raw HTTP exchange
+func (h *BidderAdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		h.sendError(w, http.StatusServiceUnavailable, "database_unavailable", "Bidder management requires a database connection")
+		return
+	}
+
+	// Parse path to extract bidder code and action if present
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/bidders"), "/")
+	bidderCode, action, _ := strings.Cut(path, "/")
+
+	if action != "" {
+		if r.Method != http.MethodPost {
+			h.sendError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+			return
+		}
+		switch action {
+		case "enable":
+			h.setEnabled(w, r, bidderCode, true)
+		case "disable":
+			h.setEnabled(w, r, bidderCode, false)
+		case "test":
+			h.testBidder(w, r, bidderCode)
+		default:
+			h.sendError(w, http.StatusNotFound, "not_found", "Unknown bidder action")
+		}
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if bidderCode != "" {
+			h.getBidder(w, r, bidderCode)
+		} else {
+			h.listBidders(w, r)
+		}
+	case http.MethodPost:
+		h.createBidder(w, r)
+	case http.MethodPut:
+		if bidderCode == "" {
+			h.sendError(w, http.StatusBadRequest, "missing_bidder_code", "Bidder code required in path")
+			return
+		}
+		h.updateBidder(w, r, bidderCode)
+	case http.MethodDelete:
+		if bidderCode == "" {
+			h.sendError(w, http.StatusBadRequest, "missing_bidder_code", "Bidder code required in path")
+			return
+		}
+		h.deleteBidder(w, r, bidderCode)
+	default:
+		h.sendError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+	}
+}
+
+// Reload applies every bidders row to the registry and resets compiled adapters without one
+// Rows for bidder codes without a compiled adapter are skipped. Returns the number of rows applied.
+func (h *BidderAdminHandler) Reload(ctx context.Context) (int, error) {
+	bidders, err := h.store.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	configured := make(map[string]bool, len(bidders))
+	for _, b := range bidders {
+		if h.apply(b) {
+			configured[b.BidderCode] = true
+		}
+	}
+	for _, code := range h.registry.ListBidders() {
+		if !configured[code] {
+			h.registry.Reset(code)
+		}
+	}
+	return len(configured), nil
+}
+
+// ReloadBidder re-reads one bidder and applies it, resetting the adapter if the row is gone
+// Called by the invalidation bus when another instance writes the bidder.
+func (h *BidderAdminHandler) ReloadBidder(ctx context.Context, bidderCode string) error {
+	b, err := h.store.Get(ctx, bidderCode)
+	if errors.Is(err, storage.ErrBidderNotFound) {
+		h.registry.Reset(bidderCode)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	h.apply(b)
+	return nil
+}
+
+// listBidders returns all bidders, or the active bidders supporting the requested formats
+func (h *BidderAdminHandler) listBidders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var (
+		bidders []*storage.Bidder
+		err     error
+	)
+	if supports := r.URL.Query().Get("supports"); supports != "" {
+		formats := map[string]bool{}
+		for _, format := range strings.Split(supports, ",") {
+			format = strings.TrimSpace(format)
+			if !validBidderFormat(format) {
+				h.sendError(w, http.StatusBadRequest, "invalid_format", fmt.Sprintf("unknown format %q; use banner, video, native or audio", format))
+				return
+			}
+			formats[format] = true
+		}
+		bidders, err = h.store.GetCapabilities(ctx, formats["banner"], formats["video"], formats["native"], formats["audio"])
+	} else {
+		bidders, err = h.store.List(ctx)
+	}
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to list bidders")
+		h.sendError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve bidders")
+		return
+	}
+
+	response := BidderListResponse{Bidders: make([]*BidderView, 0, len(bidders))}
+	stored := make(map[string]bool, len(bidders))
+	for _, b := range bidders {
+		response.Bidders = append(response.Bidders, h.view(redactHeaders(ctx, b)))
+		stored[b.BidderCode] = true
+	}
+	response.Count = len(response.Bidders)
+
+	// Only the full listing can tell which compiled adapters have no row
+	if r.URL.Query().Get("supports") == "" {
+		for _, code := range h.registry.ListBidders() {
+			if !stored[code] {
+				response.Unconfigured = append(response.Unconfigured, code)
+			}
+		}
+		sort.Strings(response.Unconfigured)
+	}
+
+	h.sendJSON(w, http.StatusOK, response)
+}
+
+// getBidder returns a specific bidder by code
+func (h *BidderAdminHandler) getBidder(w http.ResponseWriter, r *http.Request, bidderCode string) {
+	bidder, ok := h.loadBidder(w, r.Context(), bidderCode)
+	if !ok {
+		return
+	}
+	h.sendJSON(w, http.StatusOK, h.view(redactHeaders(r.Context(), bidder)))
+}
+
+// createBidder stores configuration for a compiled adapter and applies it
+func (h *BidderAdminHandler) createBidder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req BidderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, http.StatusBadRequest, "invalid_json", "Invalid request body")
+		return
+	}
+
+	bidder := h.toBidder(&req, strings.TrimSpace(req.BidderCode))
+	if _, ok := h.registry.GetDefault(bidder.BidderCode); !ok {
+		h.sendError(w, http.StatusBadRequest, "unknown_bidder", fmt.Sprintf("No adapter is compiled in for bidder %q", bidder.BidderCode))
+		return
+	}
+	if err := validateBidder(bidder); err != nil {
+		h.sendError(w, http.StatusBadRequest, "validation_failed", err.Error())
+		return
+	}
+	if err := h.checkEndpoint(ctx, bidder); err != nil {
+		h.sendError(w, http.StatusBadRequest, "validation_failed", err.Error())
+		return
+	}
+
+	if err := h.store.Create(ctx, bidder); err != nil {
+		if errors.Is(err, storage.ErrBidderExists) {
+			h.sendError(w, http.StatusConflict, "already_exists", "Bidder already exists. Use PUT to update.")
+			return
+		}
+		logger.Log.Error().Err(err).Str("bidder", bidder.BidderCode).Msg("Failed to create bidder")
+		h.sendError(w, http.StatusInternalServerError, "database_error", "Failed to create bidder")
+		return
+	}
+
+	h.apply(bidder)
+	h.publish(ctx, bidder.BidderCode)
+	logger.Log.Info().
+		Str("bidder", bidder.BidderCode).
+		Str("status", bidder.Status).
+		Bool("enabled", bidder.Enabled).
+		Str("endpoint", bidder.EndpointURL).
+		Msg("Bidder created")
+	middleware.RecordAuditChange(ctx, nil, bidder)
+
+	h.sendJSON(w, http.StatusCreated, h.view(bidder))
+}
+
+// updateBidder replaces an existing bidder and applies it
+func (h *BidderAdminHandler) updateBidder(w http.ResponseWriter, r *http.Request, bidderCode string) {
+	ctx := r.Context()
+
+	var req BidderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, http.StatusBadRequest, "invalid_json", "Invalid request body")
+		return
+	}
+	if req.BidderCode != "" && req.BidderCode != bidderCode {
+		h.sendError(w, http.StatusBadRequest, "bidder_code_mismatch", "bidder_code in body must match the path")
+		return
+	}
+
+	bidder := h.toBidder(&req, bidderCode)
+	if err := validateBidder(bidder); err != nil {
+		h.sendError(w, http.StatusBadRequest, "validation_failed", err.Error())
+		return
+	}
+	if err := h.checkEndpoint(ctx, bidder); err != nil {
+		h.sendError(w, http.StatusBadRequest, "validation_failed", err.Error())
+		return
+	}
+
+	existing, ok := h.loadBidder(w, ctx, bidderCode)
+	if !ok {
+		return
+	}
+
+	if err := h.store.Update(ctx, bidder); err != nil {
+		if errors.Is(err, storage.ErrBidderNotFound) {
+			h.sendError(w, http.StatusNotFound, "not_found", "Bidder not found. Use POST to create.")
+			return
+		}
+		logger.Log.Error().Err(err).Str("bidder", bidderCode).Msg("Failed to update bidder")
+		h.sendError(w, http.StatusInternalServerError, "database_error", "Failed to update bidder")
+		return
+	}
+
+	bidder.ID, bidder.CreatedAt = existing.ID, existing.CreatedAt
+	bidder.UpdatedAt = time.Now().UTC()
+	h.apply(bidder)
+	h.publish(ctx, bidderCode)
+	logger.Log.Info().
+		Str("bidder", bidderCode).
+		Str("old_status", existing.Status).
+		Str("new_status", bidder.Status).
+		Str("old_endpoint", existing.EndpointURL).
+		Str("new_endpoint", bidder.EndpointURL).
+		Msg("Bidder updated")
+	middleware.RecordAuditChange(ctx, existing, bidder)
+
+	h.sendJSON(w, http.StatusOK, h.view(bidder))
+}
+
+// deleteBidder archives and disables a bidder; the row is kept for reporting
+func (h *BidderAdminHandler) deleteBidder(w http.ResponseWriter, r *http.Request, bidderCode string) {
+	ctx := r.Context()
+
+	existing, ok := h.loadBidder(w, ctx, bidderCode)
+	if !ok {
+		return
+	}
+
+	if err := h.store.Delete(ctx, bidderCode); err != nil {
+		logger.Log.Error().Err(err).Str("bidder", bidderCode).Msg("Failed to archive bidder")
+		h.sendError(w, http.StatusInternalServerError, "database_error", "Failed to archive bidder")
+		return
+	}
+
+	archived := *existing
+	archived.Status = storage.BidderStatusArchived
+	archived.Enabled = false
+	h.apply(&archived)
+	h.publish(ctx, bidderCode)
+
+	logger.Log.Info().Str("bidder", bidderCode).Msg("Bidder archived")
+	middleware.RecordAuditChange(ctx, existing, &archived)
+
+	h.sendJSON(w, http.StatusOK, map[string]interface{}{
+		"success":     true,
+		"bidder_code": bidderCode,
+		"status":      storage.BidderStatusArchived,
+	})
+}
+
+// setEnabled turns a bidder on or off without changing the rest of its configuration
+func (h *BidderAdminHandler) setEnabled(w http.ResponseWriter, r *http.Request, bidderCode string, enabled bool) {
+	ctx := r.Context()
+
+	existing, ok := h.loadBidder(w, ctx, bidderCode)
+	if !ok {
+		return
+	}
+	if enabled && existing.Status == storage.BidderStatusArchived {
+		h.sendError(w, http.StatusConflict, "archived", "Bidder is archived. Use PUT to set an active status first.")
+		return
+	}
+
+	if err := h.store.SetEnabled(ctx, bidderCode, enabled); err != nil {
+		logger.Log.Error().Err(err).Str("bidder", bidderCode).Bool("enabled", enabled).Msg("Failed to set bidder enabled")
+		h.sendError(w, http.StatusInternalServerError, "database_error", "Failed to update bidder")
+		return
+	}
+
+	updated := *existing
+	updated.Enabled = enabled
+	h.apply(&updated)
+	h.publish(ctx, bidderCode)
+
+	logger.Log.Info().Str("bidder", bidderCode).Bool("enabled", enabled).Msg("Bidder enabled state changed")
+	middleware.RecordAuditChange(ctx, existing, &updated)
+
+	h.sendJSON(w, http.StatusOK, h.view(&updated))
+}
+
+// testBidder sends a test bid request through the bidder's current adapter
+// Disabled bidders can be tested; requests always carry test=1.
+func (h *BidderAdminHandler) testBidder(w http.ResponseWriter, r *http.Request, bidderCode string) {
+	ctx := r.Context()
+
+	awi, ok := h.registry.Get(bidderCode)
+	if !ok {
+		h.sendError(w, http.StatusNotFound, "not_found", "No adapter is compiled in for this bidder")
+		return
+	}
+
+	var req BidderTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.sendError(w, http.StatusBadRequest, "invalid_json", "Invalid request body")
+		return
+	}
+
+	timeoutMs := req.TimeoutMs
+	if timeoutMs == 0 {
+		timeoutMs = defaultBidderTimeoutMs
+		// Rows without a usable timeout (NULL or 0) keep the default
+		if stored, err := h.store.Get(ctx, bidderCode); err == nil && stored.TimeoutMs > 0 {
+			timeoutMs = stored.TimeoutMs
+		}
+	}
+	if timeoutMs < minBidderTimeoutMs || timeoutMs > maxBidderTimeoutMs {
+		h.sendError(w, http.StatusBadRequest, "invalid_timeout", fmt.Sprintf("timeout_ms must be between %d and %d", minBidderTimeoutMs, maxBidderTimeoutMs))
+		return
+	}
+
+	bidRequest := req.Request
+	if bidRequest == nil {
+		format := req.Format
+		if format == "" {
+			format = "banner"
+		}
+		if !validBidderFormat(format) {
+			h.sendError(w, http.StatusBadRequest, "invalid_format", "format must be banner, video, native or audio")
+			return
+		}
+		bidRequest = syntheticBidRequest(format, req.Params)
+	} else if len(bidRequest.Imp) == 0 {
+		h.sendError(w, http.StatusBadRequest, "invalid_request", "request must contain at least one imp")
+		return
+	}
+	bidRequest.Test = 1
+	bidRequest.TMax = timeoutMs
+
+	// Bidders declaring OpenRTB 2.5 get the same down-converted request as in an auction
+	if awi.Info.OpenRTBVersion == openrtb.Version25 {
+		if err := openrtb.ConvertTo25(bidRequest); err != nil {
+			h.sendError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+	}
+
+	response := h.fire(ctx, bidderCode, awi.Adapter, bidRequest, time.Duration(timeoutMs)*time.Millisecond)
+	response.Enabled = awi.Info.Enabled
+
+	logger.Log.Info().
+		Str("bidder", bidderCode).
+		Int("requests", len(response.Exchanges)).
+		Int("bids", len(response.Bids)).
+		Int("errors", len(response.Errors)).
+		Msg("Bidder test-fired")
+
+	h.sendJSON(w, http.StatusOK, response)
+}
+
+// fire runs a bid request through an adapter, recording every HTTP round trip
+func (h *BidderAdminHandler) fire(ctx context.Context, bidderCode string, adapter adapters.Adapter, bidRequest *openrtb.BidRequest, timeout time.Duration) *BidderTestResponse {
+	response := &BidderTestResponse{
+		BidderCode: bidderCode,
+		Request:    bidRequest,
+		Exchanges:  []BidderHTTPExchange{},
+		Bids:       []BidderTestBid{},
+	}
+	addErrors := func(errs []error) {
+		for _, err := range errs {
+			response.Errors = append(response.Errors, err.Error())
+		}
+	}
+
+	requests, errs := adapter.MakeRequests(bidRequest, &adapters.ExtraRequestInfo{BidderCoreName: bidderCode})
+	addErrors(errs)
+
+	for _, reqData := range requests {
+		if reqData == nil {
+			continue
+		}
+		exchange := BidderHTTPExchange{
+			Method:         reqData.Method,
+			URI:            reqData.URI,
+			RequestHeaders: reqData.Headers,
+			RequestBody:    string(reqData.Body),
+		}
+
+		// Mock requests (e.g. demo adapter) answer with their own body, as in an auction
+		start := time.Now()
+		var resp *adapters.ResponseData
+		if reqData.Method == "MOCK" {
+			resp = &adapters.ResponseData{StatusCode: http.StatusOK, Body: reqData.Body, Headers: reqData.Headers}
+		} else {
+			var err error
+			resp, err = h.client.Do(ctx, reqData, timeout)
+			if err != nil {
+				exchange.DurationMs = durationMs(time.Since(start))
+				exchange.Error = err.Error()
+				response.Exchanges = append(response.Exchanges, exchange)
+				continue
+			}
+		}
+		exchange.DurationMs = durationMs(time.Since(start))
+		exchange.StatusCode = resp.StatusCode
+		exchange.ResponseHeaders = resp.Headers
+		exchange.ResponseBody = string(resp.Body)
+		response.Exchanges = append(response.Exchanges, exchange)
+
+		bidderResp, errs := adapter.MakeBids(bidRequest, resp)
+		addErrors(errs)
+		if bidderResp == nil {
+			continue
+		}
+		for _, bid := range bidderResp.Bids {
+			if bid != nil {
+				response.Bids = append(response.Bids, BidderTestBid{Type: bid.BidType, Bid: bid.Bid})
+			}
+		}
+	}
+
+	return response
+}
+
+// apply overrides the registered adapter with a stored bidder's configuration
+// Returns false if no adapter is compiled in for the bidder code.
+func (h *BidderAdminHandler) apply(b *storage.Bidder) bool {
+	if err := h.registry.Configure(b.BidderCode, bidderOverride(b)); err != nil {
+		logger.Log.Warn().Err(err).Str("bidder", b.BidderCode).Msg("Bidder configuration not applied")
+		return false
+	}
+	return true
+}
+
+// publish tells other instances to reload a bidder; failures are logged
+func (h *BidderAdminHandler) publish(ctx context.Context, bidderCode string) {
+	if h.bus == nil {
+		return
+	}
+	if err := h.bus.Publish(ctx, redis.CacheBidders, bidderCode); err != nil {
+		logger.Log.Warn().Err(err).Str("bidder", bidderCode).Msg("Failed to publish bidder invalidation")
+	}
+}
+
+// view pairs a stored bidder with its runtime configuration
+func (h *BidderAdminHandler) view(b *storage.Bidder) *BidderView {
+	v := &BidderView{Bidder: b}
+	awi, ok := h.registry.Get(b.BidderCode)
+	if !ok {
+		return v
+	}
+	v.Runtime = &BidderRuntime{
+		Enabled:     awi.Info.Enabled,
+		Endpoint:    awi.Info.Endpoint,
+		GVLVendorID: awi.Info.GVLVendorID,
+	}
+	if base, ok := h.registry.GetDefault(b.BidderCode); ok {
+		v.Runtime.DefaultEndpoint = base.Info.Endpoint
+	}
+	if awi.Info.Capabilities != nil && awi.Info.Capabilities.Site != nil {
+		v.Runtime.MediaTypes = awi.Info.Capabilities.Site.MediaTypes
+	}
+	return v
+}
+
+// loadBidder fetches a bidder, writing the error response if it can't
+func (h *BidderAdminHandler) loadBidder(w http.ResponseWriter, ctx context.Context, bidderCode string) (*storage.Bidder, bool) {
+	bidder, err := h.store.Get(ctx, bidderCode)
+	if errors.Is(err, storage.ErrBidderNotFound) {
+		h.sendError(w, http.StatusNotFound, "not_found", "Bidder not found")
+		return nil, false
+	}
+	if err != nil {
+		logger.Log.Error().Err(err).Str("bidder", bidderCode).Msg("Failed to get bidder")
+		h.sendError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve bidder")
+		return nil, false
+	}
+	return bidder, true
+}
+
+// toBidder converts the request into the stored model, applying defaults
+func (h *BidderAdminHandler) toBidder(req *BidderRequest, bidderCode string) *storage.Bidder {
+	b := &storage.Bidder{
+		BidderCode:       bidderCode,
+		BidderName:       strings.TrimSpace(req.BidderName),
+		EndpointURL:      strings.TrimSpace(req.EndpointURL),
+		TimeoutMs:        req.TimeoutMs,
+		Enabled:          req.Enabled == nil || *req.Enabled,
+		Status:           req.Status,
+		SupportsBanner:   req.SupportsBanner,
+		SupportsVideo:    req.SupportsVideo,
+		SupportsNative:   req.SupportsNative,
+		SupportsAudio:    req.SupportsAudio,
+		GVLVendorID:      req.GVLVendorID,
+		HTTPHeaders:      req.HTTPHeaders,
+		Description:      req.Description,
+		DocumentationURL: strings.TrimSpace(req.DocumentationURL),
+		ContactEmail:     strings.TrimSpace(req.ContactEmail),
+	}
+	if b.EndpointURL == "" {
+		if base, ok := h.registry.GetDefault(bidderCode); ok {
+			b.EndpointURL = base.Info.Endpoint
+		}
+	}
+	if b.TimeoutMs == 0 {
+		b.TimeoutMs = defaultBidderTimeoutMs
+	}
+	if b.Status == "" {
+		b.Status = storage.BidderStatusActive
+	}
+	if b.HTTPHeaders == nil {
+		b.HTTPHeaders = map[string]interface{}{}
+	}
+	return b
+}
+
+// validateBidder checks a bidder against the bidders table constraints and what the adapters need
+// The bidder code itself is checked against the registry by the caller.
+func validateBidder(b *storage.Bidder) error {
+	if b.BidderName == "" {
+		return errors.New("bidder_name is required")
+	}
+	endpoint, err := url.Parse(b.EndpointURL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return errors.New("endpoint_url must be an absolute http or https URL")
+	}
+	if b.TimeoutMs < minBidderTimeoutMs || b.TimeoutMs > maxBidderTimeoutMs {
+		return fmt.Errorf("timeout_ms must be between %d and %d", minBidderTimeoutMs, maxBidderTimeoutMs)
+	}
+	switch b.Status {
+	case storage.BidderStatusActive, storage.BidderStatusTesting, storage.BidderStatusDisabled, storage.BidderStatusArchived:
+	default:
+		return errors.New("status must be active, testing, disabled or archived")
+	}
+	if b.GVLVendorID != nil && *b.GVLVendorID <= 0 {
+		return errors.New("gvl_vendor_id must be positive")
+	}
+	for name, value := range b.HTTPHeaders {
+		if _, ok := value.(string); !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("http_headers[%q] must be a string", name)
+		}
+	}
+	if b.ContactEmail != "" {
+		if _, err := mail.ParseAddress(b.ContactEmail); err != nil {
+			return fmt.Errorf("invalid contact_email: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkEndpoint rejects endpoint URLs that resolve to loopback, private or link-local addresses
+// The adapter's compiled-in endpoint is trusted as is.
+func (h *BidderAdminHandler) checkEndpoint(ctx context.Context, b *storage.Bidder) error {
+	if base, ok := h.registry.GetDefault(b.BidderCode); ok && base.Info.Endpoint == b.EndpointURL {
+		return nil
+	}
+	endpoint, err := url.Parse(b.EndpointURL)
+	if err != nil {
+		return errors.New("endpoint_url must be an absolute http or https URL")
+	}
+	host := endpoint.Hostname()
+
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		if ips, err = h.lookupIP(ctx, host); err != nil || len(ips) == 0 {
+			return fmt.Errorf("endpoint_url host %q does not resolve", host)
+		}
+	}
+	for _, ip := range ips {
+		if !adapters.IsPublicIP(ip) {
+			return fmt.Errorf("endpoint_url must not point at a loopback, private or link-local address (%s resolves to %s)", host, ip)
+		}
+	}
+	return nil
+}
+
+// redactedHeaderValue replaces http_headers values for callers that can't change bidders
+const redactedHeaderValue = "REDACTED"
+
+// redactHeaders hides http_headers values (often auth tokens) from callers without bidders:write
+// Returns b itself when the caller may see them or admin auth is disabled.
+func redactHeaders(ctx context.Context, b *storage.Bidder) *storage.Bidder {
+	identity := middleware.APIKeyFromContext(ctx)
+	if identity == nil || middleware.RoleHasPermission(identity.Role, middleware.PermBiddersWrite) || len(b.HTTPHeaders) == 0 {
+		return b
+	}
+	redacted := *b
+	redacted.HTTPHeaders = make(map[string]interface{}, len(b.HTTPHeaders))
+	for name := range b.HTTPHeaders {
+		redacted.HTTPHeaders[name] = redactedHeaderValue
+	}
+	return &redacted
+}
+
+// bidderOverride maps a stored bidder onto the registry override
+// Bidders are live when enabled with status active or testing.
+func bidderOverride(b *storage.Bidder) adapters.BidderOverride {
+	override := adapters.BidderOverride{
+		Enabled:  b.Enabled && (b.Status == storage.BidderStatusActive || b.Status == storage.BidderStatusTesting),
+		Endpoint: b.EndpointURL,
+	}
+	if b.GVLVendorID != nil {
+		override.GVLVendorID = *b.GVLVendorID
+	}
+	for name, value := range b.HTTPHeaders {
+		if s, ok := value.(string); ok {
+			if override.Headers == nil {
+				override.Headers = http.Header{}
+			}
+			override.Headers.Set(name, s)
+		}
+	}
+
+	var mediaTypes []adapters.BidType
+	for _, mt := range []struct {
+		supported bool
+		bidType   adapters.BidType
+	}{
+		{b.SupportsBanner, adapters.BidTypeBanner},
+		{b.SupportsVideo, adapters.BidTypeVideo},
+		{b.SupportsNative, adapters.BidTypeNative},
+		{b.SupportsAudio, adapters.BidTypeAudio},
+	} {
+		if mt.supported {
+			mediaTypes = append(mediaTypes, mt.bidType)
+		}
+	}
+	override.MediaTypes = mediaTypes
+	return override
+}
+
+// validBidderFormat reports whether format names a supports_* column
+func validBidderFormat(format string) bool {
+	switch format {
+	case "banner", "video", "native", "audio":
+		return true
+	}
+	return false
+}
+
+// syntheticBidRequest builds a minimal test request with one imp of the given format
+func syntheticBidRequest(format string, params json.RawMessage) *openrtb.BidRequest {
+	imp := openrtb.Imp{ID: "1", TagID: "test-fire"}
+	switch format {
+	case "video":
+		imp.Video = &openrtb.Video{Mimes: []string{"video/mp4"}, MinDuration: 5, MaxDuration: 30, Protocols: []int{2, 3, 5, 6}, W: 640, H: 480}
+	case "native":
+		imp.Native = &openrtb.Native{Ver: "1.2", Request: `{"ver":"1.2","assets":[{"id":1,"required":1,"title":{"len":90}}]}`}
+	case "audio":
+		imp.Audio = &openrtb.Audio{Mimes: []string{"audio/mp4"}, MinDuration: 5, MaxDuration: 30}
+	default:
+		imp.Banner = &openrtb.Banner{Format: []openrtb.Format{{W: 300, H: 250}}, W: 300, H: 250}
+	}
+	if len(params) > 0 {
+		imp.Ext, _ = json.Marshal(map[string]json.RawMessage{"bidder": params})
+	}
+
+	return &openrtb.BidRequest{
+		ID:  fmt.Sprintf("test-fire-%d", time.Now().UnixNano()),
+		Imp: []openrtb.Imp{imp},
+		Site: &openrtb.Site{
+			Domain:    "example.com",
+			Page:      "https://example.com/",
+			Publisher: &openrtb.Publisher{ID: "test-fire"},
+		},
+		Device: &openrtb.Device{UA: "Mozilla/5.0 (compatible; test-fire)", IP: "192.0.2.1"},
+		Cur:    []string{"USD"},
+		AT:     1,
+	}
+}
+
+// durationMs converts a duration to fractional milliseconds
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// sendJSON sends a JSON response
+func (h *BidderAdminHandler) sendJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to encode JSON response")
+	}
+}
+
+// sendError sends a JSON error response
+func (h *BidderAdminHandler) sendError(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	response := ErrorResponse{
+		Error:   errorCode,
+		Message: message,
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to encode error response")
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+	"sync"
 
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
 	"github.com/thenexusengine/tne_springwire/internal/usersync"
 	"github.com/thenexusengine/tne_springwire/pkg/logger"
 )
@@ -19,12 +21,18 @@ type CookieSyncRequest struct {
 	GDPRConsent string `json:"gdpr_consent,omitempty"`
 	// USPrivacy is the CCPA/US Privacy string
 	USPrivacy string `json:"us_privacy,omitempty"`
+	// GPP is the Global Privacy Platform string
+	GPP string `json:"gpp,omitempty"`
+	// GPPSID is the comma-separated list of applicable GPP section IDs
+	GPPSID string `json:"gpp_sid,omitempty"`
 	// Limit is the max number of syncs to return (default 8)
 	Limit int `json:"limit,omitempty"`
 	// CooperativeSync enables syncing for bidders not in the request
 	CooperativeSync bool `json:"coopSync,omitempty"`
 	// FilterSettings controls which sync types to use
 	FilterSettings *FilterSettings `json:"filterSettings,omitempty"`
+	// UIDStoreToken is the ext.prebid.uidstoretoken of an auction response, forwarded to /setuid
+	UIDStoreToken string `json:"uidstoretoken,omitempty"`
 }
 
 // FilterSettings controls sync type filtering
@@ -33,10 +41,52 @@ type FilterSettings struct {
 	Redirect *FilterConfig `json:"image,omitempty"` // Called "image" in Prebid spec
 }
 
+// Filter modes and the wildcard bidder list for FilterConfig
+const (
+	FilterModeInclude = "include"
+	FilterModeExclude = "exclude"
+	filterAllBidders  = "*"
+)
+
 // FilterConfig is a filter for a sync type
 type FilterConfig struct {
 	Bidders string   `json:"bidders,omitempty"` // "include" or "exclude"
-	Filter  []string `json:"filter,omitempty"`  // List of bidder codes
+	Filter  []string `json:"filter,omitempty"`  // List of bidder codes ("*" = all)
+}
+
+// UnmarshalJSON accepts both the Prebid layout ({"bidders": "*" | [...], "filter": "include"})
+// and the legacy layout ({"bidders": "include", "filter": [...]})
+func (f *FilterConfig) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Bidders json.RawMessage `json:"bidders"`
+		Filter  json.RawMessage `json:"filter"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*f = FilterConfig{}
+	for _, field := range []json.RawMessage{raw.Bidders, raw.Filter} {
+		if len(field) == 0 {
+			continue
+		}
+		var list []string
+		if err := json.Unmarshal(field, &list); err == nil {
+			f.Filter = list
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(field, &value); err != nil {
+			return err
+		}
+		switch strings.ToLower(value) {
+		case FilterModeInclude, FilterModeExclude:
+			f.Bidders = strings.ToLower(value)
+		case filterAllBidders:
+			f.Filter = []string{filterAllBidders}
+		}
+	}
+	return nil
 }
 
 // CookieSyncResponse is the response body for /cookie_sync
@@ -51,13 +101,24 @@ type BidderSyncStatus struct {
 	NoCookie bool               `json:"no_cookie,omitempty"`
 	UserSync *usersync.SyncInfo `json:"usersync,omitempty"`
 	Error    string             `json:"error,omitempty"`
+	// SkipReason explains why a bidder was not synced (e.g. missing consent)
+	SkipReason string `json:"skip_reason,omitempty"`
 }
 
+// GVLVendorIDFunc returns the IAB Global Vendor List ID for a bidder (0 if unknown)
+type GVLVendorIDFunc func(bidderCode string) int
+
 // CookieSyncHandler handles cookie sync requests
 type CookieSyncHandler struct {
-	syncers  map[string]*usersync.Syncer
-	hostURL  string
-	maxSyncs int
+	mu           sync.RWMutex
+	syncers      map[string]*usersync.Syncer      // Replaced wholesale on reload
+	baseConfigs  map[string]usersync.SyncerConfig // Built-in syncers, overridden by reloaded definitions
+	hostURL      string
+	maxSyncs     int
+	gvlVendorIDs GVLVendorIDFunc
+	prioritizer  *usersync.Prioritizer
+	sharedID     *usersync.SharedIDMinter
+	uidStore     *usersync.Store
 }
 
 // CookieSyncConfig holds configuration for the cookie sync handler
@@ -65,6 +126,7 @@ type CookieSyncConfig struct {
 	HostURL     string
 	MaxSyncs    int
 	SyncConfigs map[string]usersync.SyncerConfig
+	Priority    *usersync.PriorityConfig
 }
 
 // DefaultCookieSyncConfig returns default configuration
@@ -73,21 +135,31 @@ func DefaultCookieSyncConfig(hostURL string) *CookieSyncConfig {
 		HostURL:     hostURL,
 		MaxSyncs:    8,
 		SyncConfigs: usersync.DefaultSyncerConfigs(),
+		Priority:    usersync.DefaultPriorityConfig(),
 	}
 }
 
 // NewCookieSyncHandler creates a new cookie sync handler
 func NewCookieSyncHandler(config *CookieSyncConfig) *CookieSyncHandler {
 	syncers := make(map[string]*usersync.Syncer)
+	baseConfigs := make(map[string]usersync.SyncerConfig)
 
 	for code, syncConfig := range config.SyncConfigs {
 		syncers[code] = usersync.NewSyncer(syncConfig, config.HostURL)
+		baseConfigs[code] = syncConfig
+	}
+
+	priority := config.Priority
+	if priority == nil {
+		priority = &usersync.PriorityConfig{}
 	}
 
 	return &CookieSyncHandler{
-		syncers:  syncers,
-		hostURL:  config.HostURL,
-		maxSyncs: config.MaxSyncs,
+		syncers:     syncers,
+		baseConfigs: baseConfigs,
+		hostURL:     config.HostURL,
+		maxSyncs:    config.MaxSyncs,
+		prioritizer: usersync.NewPrioritizer(priority),
 	}
 }
 
@@ -120,7 +192,7 @@ func (h *CookieSyncHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Determine which bidders to sync
+	// Determine which bidders to sync (bidders with fresh UIDs are already dropped)
 	biddersToSync := h.getBiddersToSync(req, cookie)
 
 	// Build response
@@ -129,10 +201,41 @@ func (h *CookieSyncHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		BidderStatus: make([]BidderSyncStatus, 0, len(biddersToSync)),
 	}
 
-	// GDPR string for sync URLs
-	gdprStr := "0"
-	if req.GDPR == 1 {
-		gdprStr = "1"
+	// Decode TCF/GPP consent once for all bidders
+	consent := middleware.EvaluateSyncConsent(middleware.SyncPrivacy{
+		GDPR:        req.GDPR == 1,
+		GDPRConsent: req.GDPRConsent,
+		USPrivacy:   req.USPrivacy,
+		GPP:         req.GPP,
+		GPPSID:      req.GPPSID,
+	})
+
+	// Mint or refresh the first-party SharedID when storage is allowed
+	pubcid := ""
+	if h.sharedID.Enabled() {
+		if allowed, _ := consent.AllowsSync(0); allowed {
+			if id, _, err := h.sharedID.Resolve(r, cookie); err != nil {
+				logger.Log.Warn().Err(err).Msg("Failed to mint SharedID")
+			} else if id != "" {
+				if c := h.sharedID.Cookie(id, r); c != nil {
+					http.SetCookie(w, c)
+				}
+				pubcid = id
+			}
+		}
+	}
+
+	// Privacy signals and UID store token for sync URLs
+	params := usersync.SyncParams{
+		GDPR:        "0",
+		GDPRConsent: consent.ConsentString(),
+		USPrivacy:   req.USPrivacy,
+		GPP:         req.GPP,
+		GPPSID:      req.GPPSID,
+		StoreToken:  h.syncStoreToken(r, req.UIDStoreToken, pubcid),
+	}
+	if consent.GDPRApplies() {
+		params.GDPR = "1"
 	}
 
 	syncCount := 0
@@ -141,7 +244,7 @@ func (h *CookieSyncHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			break
 		}
 
-		syncer, ok := h.syncers[strings.ToLower(bidderCode)]
+		syncer, ok := h.getSyncer(bidderCode)
 		if !ok {
 			response.BidderStatus = append(response.BidderStatus, BidderSyncStatus{
 				Bidder: bidderCode,
@@ -154,20 +257,24 @@ func (h *CookieSyncHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
-		// Check if already synced
-		if cookie.HasUID(bidderCode) {
+		// Determine sync type based on filterSettings
+		syncType, ok := h.getSyncTypeForBidder(bidderCode, syncer, req.FilterSettings)
+		if !ok {
+			// Bidder filtered out by filterSettings
 			continue
 		}
 
-		// Determine sync type based on filterSettings
-		syncType := h.getSyncTypeForBidder(bidderCode, req.FilterSettings)
-		if syncType == usersync.SyncType("") {
-			// Bidder filtered out by filterSettings
+		// Skip bidders the user has not consented to sync with
+		if allowed, reason := consent.AllowsSync(h.gvlVendorID(bidderCode)); !allowed {
+			response.BidderStatus = append(response.BidderStatus, BidderSyncStatus{
+				Bidder:     bidderCode,
+				SkipReason: reason,
+			})
 			continue
 		}
 
 		// Get sync URL
-		syncInfo, err := syncer.GetSync(syncType, gdprStr, req.GDPRConsent, req.USPrivacy)
+		syncInfo, err := syncer.GetSync(syncType, params)
 		if err != nil {
 			logger.Log.Debug().Err(err).Str("bidder", bidderCode).Msg("Failed to get sync URL")
 			response.BidderStatus = append(response.BidderStatus, BidderSyncStatus{
@@ -193,31 +300,83 @@ func (h *CookieSyncHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.respondJSON(w, response)
 }
 
+// SetSharedID sets the minter for the server-generated SharedID (pubcid) cookie
+func (h *CookieSyncHandler) SetSharedID(minter *usersync.SharedIDMinter) {
+	h.sharedID = minter
+}
+
+// SetUIDStore sets the server-side UID store that /setuid writes through the sync redirects
+func (h *CookieSyncHandler) SetUIDStore(store *usersync.Store) {
+	h.uidStore = store
+}
+
+// syncStoreToken returns the UID store token appended to the /setuid redirects
+// A valid auction token is forwarded unchanged so its expiry is never extended; otherwise
+// the SharedID resolved for this request, or the _pubcid cookie, is signed.
+func (h *CookieSyncHandler) syncStoreToken(r *http.Request, auctionToken, pubcid string) string {
+	if !h.uidStore.Enabled() {
+		return ""
+	}
+	if auctionToken != "" {
+		if usersync.VerifyStoreToken(auctionToken) != nil {
+			return auctionToken
+		}
+		logger.Log.Debug().Msg("Ignored invalid or expired UID store token in cookie sync request")
+	}
+	if key, ok := usersync.PubCIDKey(pubcid); ok {
+		return usersync.IssueStoreToken([]usersync.StoreKey{key})
+	}
+	if key, ok := pubCIDCookieKey(r); ok {
+		return usersync.IssueStoreToken([]usersync.StoreKey{key})
+	}
+	return ""
+}
+
+// SetGVLVendorIDs sets the lookup used for TCF vendor consent checks
+func (h *CookieSyncHandler) SetGVLVendorIDs(lookup GVLVendorIDFunc) {
+	h.gvlVendorIDs = lookup
+}
+
+// gvlVendorID returns the bidder's GVL ID from the lookup, falling back to the syncer definition
+func (h *CookieSyncHandler) gvlVendorID(bidderCode string) int {
+	if h.gvlVendorIDs != nil {
+		if id := h.gvlVendorIDs(strings.ToLower(bidderCode)); id > 0 {
+			return id
+		}
+	}
+	if syncer, ok := h.getSyncer(bidderCode); ok {
+		return syncer.Config().GVLVendorID
+	}
+	return 0
+}
+
+// GVLVendorID returns the GVL ID used for a bidder's TCF vendor consent checks (0 if unknown)
+func (h *CookieSyncHandler) GVLVendorID(bidderCode string) int {
+	return h.gvlVendorID(bidderCode)
+}
+
 // getSyncTypeForBidder determines the sync type for a bidder based on filterSettings
-// Returns empty string if the bidder should be filtered out
-func (h *CookieSyncHandler) getSyncTypeForBidder(bidderCode string, filterSettings *FilterSettings) usersync.SyncType {
+// Returns false if the bidder should be filtered out. Without filterSettings the type is
+// left empty so the syncer picks one it supports (redirect, else iframe). With filterSettings,
+// a sync type without a filter follows the Prebid defaults: image allowed, iframe not allowed.
+func (h *CookieSyncHandler) getSyncTypeForBidder(bidderCode string, syncer *usersync.Syncer, filterSettings *FilterSettings) (usersync.SyncType, bool) {
 	if filterSettings == nil {
-		// No filter settings - default to redirect
-		return usersync.SyncTypeRedirect
+		return usersync.SyncType(""), true
 	}
 
 	// Try iframe first (preferred for better sync rates)
-	if filterSettings.Iframe != nil {
-		if h.shouldIncludeBidder(bidderCode, filterSettings.Iframe) {
-			return usersync.SyncTypeIframe
-		}
+	if filterSettings.Iframe != nil && h.shouldIncludeBidder(bidderCode, filterSettings.Iframe) &&
+		syncer.SupportsType(usersync.SyncTypeIframe) {
+		return usersync.SyncTypeIframe, true
 	}
 
-	// Try redirect as fallback
-	if filterSettings.Redirect != nil {
-		if h.shouldIncludeBidder(bidderCode, filterSettings.Redirect) {
-			return usersync.SyncTypeRedirect
-		}
+	// Redirect (image) syncs are allowed unless filtered
+	if (filterSettings.Redirect == nil || h.shouldIncludeBidder(bidderCode, filterSettings.Redirect)) &&
+		syncer.SupportsType(usersync.SyncTypeRedirect) {
+		return usersync.SyncTypeRedirect, true
 	}
 
-	// If filterSettings is provided but bidder doesn't match any filter, default to redirect
-	// This matches Prebid.js behavior where filterSettings is advisory, not restrictive
-	return usersync.SyncTypeRedirect
+	return usersync.SyncType(""), false
 }
 
 // shouldIncludeBidder checks if a bidder passes the filter configuration
@@ -228,9 +387,9 @@ func (h *CookieSyncHandler) shouldIncludeBidder(bidderCode string, config *Filte
 
 	bidderInList := h.containsBidder(config.Filter, bidderCode)
 
-	if config.Bidders == "include" {
+	if config.Bidders == FilterModeInclude {
 		return bidderInList // Include only if in list
-	} else if config.Bidders == "exclude" {
+	} else if config.Bidders == FilterModeExclude {
 		return !bidderInList // Exclude if in list
 	}
 
@@ -238,39 +397,53 @@ func (h *CookieSyncHandler) shouldIncludeBidder(bidderCode string, config *Filte
 	return true
 }
 
-// containsBidder checks if a bidder code is in a list (case-insensitive)
+// containsBidder checks if a bidder code is in a list (case-insensitive, "*" matches all)
 func (h *CookieSyncHandler) containsBidder(list []string, bidder string) bool {
 	bidderLower := strings.ToLower(bidder)
 	for _, b := range list {
-		if strings.ToLower(b) == bidderLower {
+		if b == filterAllBidders || strings.ToLower(b) == bidderLower {
 			return true
 		}
 	}
 	return false
 }
 
-// getBiddersToSync determines which bidders need syncing
+// getBiddersToSync determines which bidders need syncing, in sync order
+// Requested bidders come first (ranked by priority group and revenue, request order
+// breaking ties). With cooperative sync, all other enabled bidders follow, ranked the
+// same way with ties shuffled so equal bidders take turns.
 func (h *CookieSyncHandler) getBiddersToSync(req CookieSyncRequest, cookie *usersync.Cookie) []string {
-	var bidders []string
+	coopSync := req.CooperativeSync || (len(req.Bidders) == 0 && h.prioritizer.CoopSyncDefault())
 
+	var bidders []string
 	if len(req.Bidders) > 0 {
 		// Use requested bidders
-		bidders = req.Bidders
-	} else if req.CooperativeSync {
-		// Sync all configured bidders
-		for code := range h.syncers {
-			bidders = append(bidders, code)
-		}
-	} else {
+		bidders = h.prioritizer.Rank(req.Bidders)
+	} else if !coopSync {
 		// No bidders specified and no coop sync - return common bidders
 		bidders = []string{"appnexus", "rubicon", "pubmatic", "openx", "triplelift"}
 	}
 
-	// Filter out bidders that already have UIDs (optimization to avoid redundant syncs)
+	if coopSync {
+		requested := make(map[string]bool, len(bidders))
+		for _, bidder := range bidders {
+			requested[strings.ToLower(bidder)] = true
+		}
+		var coop []string
+		for code, syncer := range h.currentSyncers() {
+			if syncer.IsEnabled() && !requested[code] {
+				coop = append(coop, code)
+			}
+		}
+		usersync.Shuffle(coop)
+		bidders = append(bidders, h.prioritizer.Rank(coop)...)
+	}
+
+	// Filter out bidders with fresh UIDs (optimization to avoid redundant syncs)
 	if cookie != nil {
 		needsSync := make([]string, 0, len(bidders))
 		for _, bidder := range bidders {
-			if !cookie.HasUID(bidder) {
+			if cookie.NeedsSync(bidder, h.prioritizer.ResyncWindow()) {
 				needsSync = append(needsSync, bidder)
 			}
 		}
@@ -280,6 +453,11 @@ func (h *CookieSyncHandler) getBiddersToSync(req CookieSyncRequest, cookie *user
 	return bidders
 }
 
+// SetRevenueSource sets the revenue source used to rank bidders for syncing
+func (h *CookieSyncHandler) SetRevenueSource(source usersync.RevenueSource) {
+	h.prioritizer.SetRevenueSource(source)
+}
+
 // getCookieDomain extracts the domain for cookies
 func (h *CookieSyncHandler) getCookieDomain(r *http.Request) string {
 	host := r.Host
@@ -298,14 +476,71 @@ func (h *CookieSyncHandler) respondJSON(w http.ResponseWriter, data interface{})
 }
 
 // AddSyncer adds a syncer for a bidder
+// Added syncers are kept across reloads unless a reloaded definition overrides them
 func (h *CookieSyncHandler) AddSyncer(config usersync.SyncerConfig) {
-	h.syncers[strings.ToLower(config.BidderCode)] = usersync.NewSyncer(config, h.hostURL)
+	code := strings.ToLower(config.BidderCode)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.baseConfigs[code] = config
+	syncers := make(map[string]*usersync.Syncer, len(h.syncers)+1)
+	for c, syncer := range h.syncers {
+		syncers[c] = syncer
+	}
+	syncers[code] = usersync.NewSyncer(config, h.hostURL)
+	h.syncers = syncers
+}
+
+// ReloadSyncers replaces the reloadable syncer definitions (e.g. from the bidders table)
+// Definitions override built-in syncers with the same bidder code, and disabled ones
+// remove them; built-in syncers missing from configs are kept
+func (h *CookieSyncHandler) ReloadSyncers(configs map[string]usersync.SyncerConfig) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	syncers := make(map[string]*usersync.Syncer, len(h.baseConfigs)+len(configs))
+	for code, config := range h.baseConfigs {
+		syncers[code] = usersync.NewSyncer(config, h.hostURL)
+	}
+	for code, config := range configs {
+		if !config.Enabled {
+			delete(syncers, strings.ToLower(code))
+			continue
+		}
+		syncers[strings.ToLower(code)] = usersync.NewSyncer(config, h.hostURL)
+	}
+	h.syncers = syncers
+}
+
+// SyncerConfigs returns the active syncer definitions keyed by bidder code
+func (h *CookieSyncHandler) SyncerConfigs() map[string]usersync.SyncerConfig {
+	syncers := h.currentSyncers()
+	configs := make(map[string]usersync.SyncerConfig, len(syncers))
+	for code, syncer := range syncers {
+		configs[code] = syncer.Config()
+	}
+	return configs
+}
+
+// getSyncer returns the syncer for a bidder code (case-insensitive)
+func (h *CookieSyncHandler) getSyncer(bidderCode string) (*usersync.Syncer, bool) {
+	syncer, ok := h.currentSyncers()[strings.ToLower(bidderCode)]
+	return syncer, ok
+}
+
+// currentSyncers returns the active syncer map; callers must not modify it
+func (h *CookieSyncHandler) currentSyncers() map[string]*usersync.Syncer {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.syncers
 }
 
 // ListBidders returns all configured bidder codes
 func (h *CookieSyncHandler) ListBidders() []string {
-	bidders := make([]string, 0, len(h.syncers))
-	for code := range h.syncers {
+	syncers := h.currentSyncers()
+	bidders := make([]string, 0, len(syncers))
+	for code := range syncers {
 		bidders = append(bidders, code)
 	}
 	return bidders
@@ -2,11 +2,15 @@ package endpoints
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/thenexusengine/tne_springwire/internal/usersync"
 )
@@ -304,13 +308,21 @@ func TestCookieSyncHandler_UnsupportedBidder(t *testing.T) {
 	}
 }
 
+// Test consent strings: TCF v2 with purposes 1-2 and vendor 32, and without purpose 1
+const (
+	testTCFConsent          = "CAAAAAAAAAAAAAHABBENBkCAAMAAAAAAAAAAAAAAAAAAAAAAAgAAAAAI"
+	testTCFConsentNoPurpose = "CAAAAAAAAAAAAAHABBENBkCAAEAAAAAAAAAAAAAAAAAAAAAAAgAAAAAI"
+	testGPPWithTCF          = "DBABMA~" + testTCFConsent
+	testGPPUSNatOptOut      = "DBABLA~BAAQAAA"
+)
+
 func TestCookieSyncHandler_GDPR(t *testing.T) {
 	handler := createTestHandler()
 
 	reqBody := CookieSyncRequest{
 		Bidders:     []string{"appnexus"},
 		GDPR:        1,
-		GDPRConsent: "consent-string",
+		GDPRConsent: testTCFConsent,
 	}
 	body, _ := json.Marshal(reqBody)
 
@@ -336,6 +348,110 @@ func TestCookieSyncHandler_GDPR(t *testing.T) {
 	}
 }
 
+func TestCookieSyncHandler_ConsentSkips(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     CookieSyncRequest
+		reasons map[string]string // bidder -> expected skip reason ("" = synced)
+	}{
+		{
+			name:    "gdpr without consent",
+			req:     CookieSyncRequest{Bidders: []string{"appnexus", "rubicon"}, GDPR: 1},
+			reasons: map[string]string{"appnexus": "gdpr_no_consent", "rubicon": "gdpr_no_consent"},
+		},
+		{
+			name:    "gdpr invalid consent",
+			req:     CookieSyncRequest{Bidders: []string{"appnexus"}, GDPR: 1, GDPRConsent: "consent-string"},
+			reasons: map[string]string{"appnexus": "gdpr_invalid_consent"},
+		},
+		{
+			name:    "gdpr without purpose 1",
+			req:     CookieSyncRequest{Bidders: []string{"appnexus"}, GDPR: 1, GDPRConsent: testTCFConsentNoPurpose},
+			reasons: map[string]string{"appnexus": "gdpr_purpose1"},
+		},
+		{
+			name:    "gdpr vendor consent",
+			req:     CookieSyncRequest{Bidders: []string{"appnexus", "rubicon"}, GDPR: 1, GDPRConsent: testTCFConsent},
+			reasons: map[string]string{"appnexus": "", "rubicon": "gdpr_vendor"},
+		},
+		{
+			name:    "gpp tcf section",
+			req:     CookieSyncRequest{Bidders: []string{"appnexus"}, GPP: testGPPWithTCF, GPPSID: "2"},
+			reasons: map[string]string{"appnexus": ""},
+		},
+		{
+			name:    "us privacy opt-out",
+			req:     CookieSyncRequest{Bidders: []string{"appnexus"}, USPrivacy: "1YYN"},
+			reasons: map[string]string{"appnexus": "us_optout"},
+		},
+		{
+			name:    "gpp us opt-out",
+			req:     CookieSyncRequest{Bidders: []string{"appnexus"}, GPP: testGPPUSNatOptOut, GPPSID: "7"},
+			reasons: map[string]string{"appnexus": "us_optout"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := createTestHandler()
+			handler.SetGVLVendorIDs(func(bidder string) int {
+				return map[string]int{"appnexus": 32, "rubicon": 52}[bidder]
+			})
+
+			body, _ := json.Marshal(tt.req)
+			req := httptest.NewRequest("POST", "/cookie_sync", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			var resp CookieSyncResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(resp.BidderStatus) != len(tt.reasons) {
+				t.Fatalf("expected %d bidder statuses, got %d", len(tt.reasons), len(resp.BidderStatus))
+			}
+			for _, status := range resp.BidderStatus {
+				want := tt.reasons[status.Bidder]
+				if status.SkipReason != want {
+					t.Errorf("%s: expected skip reason %q, got %q", status.Bidder, want, status.SkipReason)
+				}
+				if want != "" && status.UserSync != nil {
+					t.Errorf("%s: skipped bidder should not get a sync URL", status.Bidder)
+				}
+				if want == "" && status.UserSync == nil {
+					t.Errorf("%s: expected sync URL", status.Bidder)
+				}
+			}
+		})
+	}
+}
+
+func TestCookieSyncHandler_ConsentForwardedFromGPP(t *testing.T) {
+	handler := createTestHandler()
+	handler.AddSyncer(usersync.SyncerConfig{
+		BidderCode:      "gdprbidder",
+		RedirectSyncURL: "https://sync.example.com/?gdpr={{gdpr}}&consent={{gdpr_consent}}&r={{redirect_url}}",
+		Enabled:         true,
+	})
+
+	body, _ := json.Marshal(CookieSyncRequest{Bidders: []string{"gdprbidder"}, GPP: testGPPWithTCF, GPPSID: "2"})
+	req := httptest.NewRequest("POST", "/cookie_sync", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	var resp CookieSyncResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.BidderStatus) != 1 || resp.BidderStatus[0].UserSync == nil {
+		t.Fatalf("expected one sync, got %+v", resp.BidderStatus)
+	}
+	url := resp.BidderStatus[0].UserSync.URL
+	if !strings.Contains(url, "gdpr=1") || !strings.Contains(url, "consent="+testTCFConsent) {
+		t.Errorf("expected GDPR signal and TCF string from GPP in sync URL, got %s", url)
+	}
+}
+
 func TestGetBiddersToSync_SpecificBidders(t *testing.T) {
 	handler := createTestHandler()
 	cookie := usersync.NewCookie()
@@ -386,6 +502,187 @@ func TestGetBiddersToSync_DefaultBidders(t *testing.T) {
 	}
 }
 
+func TestGetBiddersToSync_Prioritized(t *testing.T) {
+	config := &CookieSyncConfig{
+		HostURL:     "https://test.example.com",
+		MaxSyncs:    8,
+		SyncConfigs: createTestHandlerSyncConfigs(),
+		Priority: &usersync.PriorityConfig{
+			PriorityGroups:  [][]string{{"openx"}},
+			RevenueCacheTTL: time.Minute,
+		},
+	}
+	handler := NewCookieSyncHandler(config)
+	handler.SetRevenueSource(testRevenueSource{"pubmatic": 100, "rubicon": 10})
+
+	// Requested bidders rank by priority group, then revenue
+	bidders := handler.getBiddersToSync(CookieSyncRequest{Bidders: []string{"appnexus", "rubicon", "openx"}}, usersync.NewCookie())
+	if want := []string{"openx", "rubicon", "appnexus"}; !reflect.DeepEqual(bidders, want) {
+		t.Errorf("expected %v, got %v", want, bidders)
+	}
+
+	// Cooperative sync appends the remaining enabled bidders after the requested ones
+	bidders = handler.getBiddersToSync(CookieSyncRequest{Bidders: []string{"appnexus"}, CooperativeSync: true}, usersync.NewCookie())
+	if len(bidders) != 5 || bidders[0] != "appnexus" || bidders[1] != "openx" || bidders[2] != "pubmatic" || bidders[3] != "rubicon" {
+		t.Errorf("expected appnexus, then openx, pubmatic, rubicon by priority and revenue, got %v", bidders)
+	}
+}
+
+func TestGetBiddersToSync_CoopSyncDefault(t *testing.T) {
+	config := &CookieSyncConfig{
+		HostURL:     "https://test.example.com",
+		MaxSyncs:    8,
+		SyncConfigs: createTestHandlerSyncConfigs(),
+		Priority:    &usersync.PriorityConfig{CoopSyncDefault: true},
+	}
+	handler := NewCookieSyncHandler(config)
+
+	bidders := handler.getBiddersToSync(CookieSyncRequest{}, usersync.NewCookie())
+	if len(bidders) != len(config.SyncConfigs) {
+		t.Errorf("expected all %d enabled bidders, got %v", len(config.SyncConfigs), bidders)
+	}
+}
+
+func TestGetBiddersToSync_ResyncNearExpiry(t *testing.T) {
+	config := &CookieSyncConfig{
+		HostURL:     "https://test.example.com",
+		MaxSyncs:    8,
+		SyncConfigs: createTestHandlerSyncConfigs(),
+		Priority:    &usersync.PriorityConfig{ResyncWindow: 7 * 24 * time.Hour},
+	}
+	handler := NewCookieSyncHandler(config)
+
+	cookie := usersync.NewCookie()
+	cookie.SetUID("appnexus", "fresh-uid")
+	cookie.UIDs["rubicon"] = usersync.UID{UID: "old-uid", Expires: time.Now().Add(48 * time.Hour)}
+
+	bidders := handler.getBiddersToSync(CookieSyncRequest{Bidders: []string{"appnexus", "rubicon"}}, cookie)
+	if !reflect.DeepEqual(bidders, []string{"rubicon"}) {
+		t.Errorf("expected only the near-expiry bidder to re-sync, got %v", bidders)
+	}
+}
+
+func TestFilterConfig_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want FilterConfig
+	}{
+		{"prebid list", `{"bidders": ["appnexus"], "filter": "exclude"}`, FilterConfig{Bidders: "exclude", Filter: []string{"appnexus"}}},
+		{"prebid wildcard", `{"bidders": "*", "filter": "include"}`, FilterConfig{Bidders: "include", Filter: []string{"*"}}},
+		{"legacy layout", `{"bidders": "include", "filter": ["rubicon"]}`, FilterConfig{Bidders: "include", Filter: []string{"rubicon"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got FilterConfig
+			if err := json.Unmarshal([]byte(tt.json), &got); err != nil {
+				t.Fatalf("unmarshal failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	var invalid FilterConfig
+	if err := json.Unmarshal([]byte(`{"bidders": 5}`), &invalid); err == nil {
+		t.Error("expected error for non-string, non-list bidders")
+	}
+}
+
+func TestCookieSyncHandler_FilterSettings(t *testing.T) {
+	handler := createTestHandler()
+	handler.AddSyncer(usersync.SyncerConfig{
+		BidderCode:      "iframebidder",
+		IframeSyncURL:   "https://iframe.example.com/sync?{{redirect_url}}",
+		RedirectSyncURL: "https://pixel.example.com/sync?{{redirect_url}}",
+		Enabled:         true,
+	})
+
+	body := `{
+		"bidders": ["appnexus", "rubicon", "iframebidder"],
+		"filterSettings": {
+			"iframe": {"bidders": "*", "filter": "include"},
+			"image": {"bidders": ["rubicon"], "filter": "exclude"}
+		}
+	}`
+	req := httptest.NewRequest("POST", "/cookie_sync", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	var resp CookieSyncResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	types := make(map[string]usersync.SyncType)
+	for _, status := range resp.BidderStatus {
+		if status.UserSync != nil {
+			types[status.Bidder] = status.UserSync.Type
+		}
+	}
+	if types["iframebidder"] != usersync.SyncTypeIframe {
+		t.Errorf("expected iframe sync for iframebidder, got %q", types["iframebidder"])
+	}
+	if types["appnexus"] != usersync.SyncTypeRedirect {
+		t.Errorf("expected redirect fallback for appnexus without iframe URL, got %q", types["appnexus"])
+	}
+	if _, ok := types["rubicon"]; ok {
+		t.Error("expected rubicon to be excluded by the image filter")
+	}
+}
+
+func TestGetSyncTypeForBidder_IframeDefaultExcluded(t *testing.T) {
+	handler := createTestHandler()
+	syncer := usersync.NewSyncer(usersync.SyncerConfig{
+		BidderCode:    "iframeonly",
+		IframeSyncURL: "https://iframe.example.com/sync",
+		Enabled:       true,
+	}, "https://test.example.com")
+
+	// filterSettings without an iframe filter does not allow iframe syncs
+	settings := &FilterSettings{Redirect: &FilterConfig{Bidders: "include", Filter: []string{"*"}}}
+	if got, ok := handler.getSyncTypeForBidder("iframeonly", syncer, settings); ok {
+		t.Errorf("expected bidder to be filtered out, got %q", got)
+	}
+	if got, ok := handler.getSyncTypeForBidder("iframeonly", syncer, nil); !ok || got != "" {
+		t.Errorf("expected the syncer to choose without filterSettings, got %q (ok=%v)", got, ok)
+	}
+}
+
+func TestCookieSyncHandler_IframeOnlyWithoutFilterSettings(t *testing.T) {
+	handler := createTestHandler()
+	handler.AddSyncer(usersync.SyncerConfig{
+		BidderCode:    "iframeonly",
+		IframeSyncURL: "https://iframe.example.com/sync?{{redirect_url}}",
+		Enabled:       true,
+	})
+
+	req := httptest.NewRequest("POST", "/cookie_sync", strings.NewReader(`{"bidders": ["iframeonly", "appnexus"]}`))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	var resp CookieSyncResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	types := make(map[string]usersync.SyncType)
+	for _, status := range resp.BidderStatus {
+		if status.Error != "" {
+			t.Errorf("unexpected error for %s: %s", status.Bidder, status.Error)
+		}
+		if status.UserSync != nil {
+			types[status.Bidder] = status.UserSync.Type
+		}
+	}
+	if types["iframeonly"] != usersync.SyncTypeIframe {
+		t.Errorf("expected iframe fallback for an iframe-only syncer, got %q", types["iframeonly"])
+	}
+	if types["appnexus"] != usersync.SyncTypeRedirect {
+		t.Errorf("expected redirect for appnexus, got %q", types["appnexus"])
+	}
+}
+
 func TestGetCookieDomain(t *testing.T) {
 	handler := createTestHandler()
 
@@ -548,38 +845,178 @@ func TestCookieSyncHandler_MaxSyncsExceedsConfig(t *testing.T) {
 	}
 }
 
+// testRevenueSource is a fixed usersync.RevenueSource
+type testRevenueSource map[string]float64
+
+func (s testRevenueSource) BidderRevenue() map[string]float64 {
+	return s
+}
+
 // createTestHandler creates a handler with test configuration
 func createTestHandler() *CookieSyncHandler {
 	config := &CookieSyncConfig{
-		HostURL:  "https://test.example.com",
+		HostURL:     "https://test.example.com",
+		MaxSyncs:    8,
+		SyncConfigs: createTestHandlerSyncConfigs(),
+	}
+	return NewCookieSyncHandler(config)
+}
+
+// createTestHandlerSyncConfigs returns redirect-only syncers for common bidders
+func createTestHandlerSyncConfigs() map[string]usersync.SyncerConfig {
+	return map[string]usersync.SyncerConfig{
+		"appnexus": {
+			BidderCode:      "appnexus",
+			RedirectSyncURL: "https://ib.adnxs.com/getuid?{{redirect_url}}",
+			Enabled:         true,
+		},
+		"rubicon": {
+			BidderCode:      "rubicon",
+			RedirectSyncURL: "https://pixel.rubiconproject.com/sync?{{redirect_url}}",
+			Enabled:         true,
+		},
+		"pubmatic": {
+			BidderCode:      "pubmatic",
+			RedirectSyncURL: "https://ads.pubmatic.com/sync?{{redirect_url}}",
+			Enabled:         true,
+		},
+		"openx": {
+			BidderCode:      "openx",
+			RedirectSyncURL: "https://rtb.openx.net/sync?{{redirect_url}}",
+			Enabled:         true,
+		},
+		"triplelift": {
+			BidderCode:      "triplelift",
+			RedirectSyncURL: "https://eb2.3lift.com/sync?{{redirect_url}}",
+			Enabled:         true,
+		},
+	}
+}
+
+func TestCookieSyncHandler_SharedID(t *testing.T) {
+	handler := createTestHandler()
+	handler.SetSharedID(usersync.NewSharedIDMinter(&usersync.SharedIDConfig{Enabled: true, CookieDomains: []string{".publisher.com"}}))
+
+	tests := []struct {
+		name   string
+		req    CookieSyncRequest
+		origin string
+		want   bool
+	}{
+		{"no regulation", CookieSyncRequest{}, "https://www.publisher.com", true},
+		{"gdpr with consent", CookieSyncRequest{GDPR: 1, GDPRConsent: testTCFConsent}, "https://www.publisher.com", true},
+		{"gdpr without purpose 1", CookieSyncRequest{GDPR: 1, GDPRConsent: testTCFConsentNoPurpose}, "https://www.publisher.com", false},
+		{"us privacy opt-out", CookieSyncRequest{USPrivacy: "1YYN"}, "https://www.publisher.com", false},
+		{"third-party page", CookieSyncRequest{}, "https://other.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.req)
+			req := httptest.NewRequest("POST", "https://pbs.publisher.com/cookie_sync", bytes.NewReader(body))
+			req.Header.Set("Origin", tt.origin)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			var pubcid *http.Cookie
+			for _, c := range w.Result().Cookies() {
+				if c.Name == usersync.PubCIDCookieName {
+					pubcid = c
+				}
+			}
+			if (pubcid != nil) != tt.want {
+				t.Fatalf("Expected pubcid cookie set=%v, got %v", tt.want, pubcid)
+			}
+			if pubcid != nil && (pubcid.Domain != "publisher.com" || !usersync.IsValidSharedID(pubcid.Value)) {
+				t.Errorf("Unexpected pubcid cookie: %+v", pubcid)
+			}
+		})
+	}
+}
+
+func TestCookieSync_UIDStoreTokenReachesSetUID(t *testing.T) {
+	store := setupTestUIDStore(t)
+	ifaKey, _ := usersync.IFAKey("ifa-ctv")
+	ppuidKey, _ := usersync.PPUIDKey("pub1", "user-1")
+	auctionToken := issueTestStoreToken(t, ifaKey, ppuidKey)
+
+	syncHandler := NewCookieSyncHandler(&CookieSyncConfig{
+		HostURL:  "https://pbs.example.com",
 		MaxSyncs: 8,
 		SyncConfigs: map[string]usersync.SyncerConfig{
-			"appnexus": {
-				BidderCode:      "appnexus",
-				RedirectSyncURL: "https://ib.adnxs.com/getuid?{{redirect_url}}",
-				Enabled:         true,
-			},
-			"rubicon": {
-				BidderCode:      "rubicon",
-				RedirectSyncURL: "https://pixel.rubiconproject.com/sync?{{redirect_url}}",
-				Enabled:         true,
-			},
-			"pubmatic": {
-				BidderCode:      "pubmatic",
-				RedirectSyncURL: "https://ads.pubmatic.com/sync?{{redirect_url}}",
-				Enabled:         true,
-			},
-			"openx": {
-				BidderCode:      "openx",
-				RedirectSyncURL: "https://rtb.openx.net/sync?{{redirect_url}}",
-				Enabled:         true,
-			},
-			"triplelift": {
-				BidderCode:      "triplelift",
-				RedirectSyncURL: "https://eb2.3lift.com/sync?{{redirect_url}}",
-				Enabled:         true,
-			},
+			"appnexus": {BidderCode: "appnexus", RedirectSyncURL: "https://ib.adnxs.com/getuid?redir={{redirect_url}}", Enabled: true},
 		},
+	})
+	syncHandler.SetUIDStore(store)
+	setuidHandler := NewSetUIDHandler([]string{"appnexus"})
+	setuidHandler.SetUIDStore(store)
+
+	// Client forwards the auction's ext.prebid.uidstoretoken to /cookie_sync
+	body, _ := json.Marshal(CookieSyncRequest{Bidders: []string{"appnexus"}, UIDStoreToken: auctionToken})
+	w := httptest.NewRecorder()
+	syncHandler.ServeHTTP(w, httptest.NewRequest("POST", "/cookie_sync", bytes.NewReader(body)))
+
+	var resp CookieSyncResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.BidderStatus) != 1 || resp.BidderStatus[0].UserSync == nil {
+		t.Fatalf("Expected one appnexus sync, got %+v", resp.BidderStatus)
+	}
+
+	// The bidder fills in its UID and redirects the browser to /setuid
+	syncURL, err := url.Parse(resp.BidderStatus[0].UserSync.URL)
+	if err != nil {
+		t.Fatalf("Invalid sync URL: %v", err)
+	}
+	redirect := strings.Replace(syncURL.Query().Get("redir"), usersync.DefaultUserMacro, "an-uid", 1)
+	if !strings.Contains(redirect, "st=") {
+		t.Fatalf("Expected the store token in the /setuid redirect, got %s", redirect)
+	}
+	setuidURL, err := url.Parse(redirect)
+	if err != nil {
+		t.Fatalf("Invalid redirect URL: %v", err)
+	}
+	setuidHandler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", setuidURL.RequestURI(), nil))
+
+	// The next auction for the CTV device finds the UID in the store
+	stored, err := store.Lookup(context.Background(), []usersync.StoreKey{ifaKey})
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if stored.UIDs["appnexus"] != "an-uid" {
+		t.Errorf("Expected an-uid stored under the IFA, got %v", stored.UIDs)
+	}
+	stored, err = store.Get(context.Background(), ppuidKey)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if stored.UIDs["appnexus"] != "an-uid" {
+		t.Errorf("Expected an-uid stored under the publisher user ID, got %v", stored.UIDs)
+	}
+}
+
+func TestCookieSync_SyncStoreToken(t *testing.T) {
+	ifaKey, _ := usersync.IFAKey("ifa-ctv")
+	auctionToken := issueTestStoreToken(t, ifaKey)
+	pubcidKey, _ := usersync.PubCIDKey("cookie-pubcid")
+
+	handler := NewCookieSyncHandler(&CookieSyncConfig{HostURL: "https://pbs.example.com", MaxSyncs: 8})
+	req := httptest.NewRequest("POST", "/cookie_sync", nil)
+	req.AddCookie(&http.Cookie{Name: usersync.PubCIDCookieName, Value: "cookie-pubcid"})
+
+	if token := handler.syncStoreToken(req, auctionToken, ""); token != "" {
+		t.Errorf("Expected no token without a UID store, got %q", token)
+	}
+
+	handler.SetUIDStore(setupTestUIDStore(t))
+	if token := handler.syncStoreToken(req, auctionToken, ""); token != auctionToken {
+		t.Errorf("Expected a valid auction token to be forwarded unchanged, got %q", token)
+	}
+	if keys := usersync.VerifyStoreToken(handler.syncStoreToken(req, "forged", "")); len(keys) != 1 || keys[0] != pubcidKey {
+		t.Errorf("Expected an invalid token to fall back to the pubcid cookie, got %+v", keys)
+	}
+	if token := handler.syncStoreToken(httptest.NewRequest("POST", "/cookie_sync", nil), "", ""); token != "" {
+		t.Errorf("Expected no token without trusted identities, got %q", token)
 	}
-	return NewCookieSyncHandler(config)
 }
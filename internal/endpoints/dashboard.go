@@ -379,9 +379,28 @@ var dashboardTemplate = template.Must(template.New("dashboard").Parse(`<!DOCTYPE
             return date.toLocaleTimeString('en-US', { hour12: false });
         }
 
+        // /admin/metrics requires an API key with at least the viewer role
+        // The key is only requested once the server rejects the stored one
+        let keyPromptDeclined = false;
+        function adminKey(prompt) {
+            let key = sessionStorage.getItem('tne_admin_key') || '';
+            if (prompt && !keyPromptDeclined) {
+                key = window.prompt('Admin API key (viewer role or above):') || '';
+                keyPromptDeclined = key === '';
+                sessionStorage.setItem('tne_admin_key', key);
+            }
+            return key;
+        }
+
         async function updateDashboard() {
             try {
-                const response = await fetch('/admin/metrics');
+                let response = await fetch('/admin/metrics', { headers: { 'X-API-Key': adminKey(false) } });
+                if (response.status === 401 || response.status === 403) {
+                    response = await fetch('/admin/metrics', { headers: { 'X-API-Key': adminKey(true) } });
+                }
+                if (!response.ok) {
+                    throw new Error('metrics request failed with status ' + response.status);
+                }
                 const data = await response.json();
 
                 document.getElementById('total-auctions').textContent = data.total_auctions;
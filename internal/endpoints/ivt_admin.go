@@ -0,0 +1,111 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// defaultIVTReportWindow is the window used when ?window= is not set
+const defaultIVTReportWindow = time.Hour
+
+// IVTStatsSource returns per-publisher IVT aggregates
+type IVTStatsSource interface {
+	Summary(publisherID string, window time.Duration) (middleware.IVTPublisherSummary, bool)
+	Summaries(window time.Duration) []middleware.IVTPublisherSummary
+}
+
+// IVTAdminHandler reports IVT detection per publisher
+type IVTAdminHandler struct {
+	stats IVTStatsSource
+}
+
+// NewIVTAdminHandler creates an IVT report handler backed by the detector's aggregates
+func NewIVTAdminHandler(stats IVTStatsSource) *IVTAdminHandler {
+	return &IVTAdminHandler{stats: stats}
+}
+
+// IVTReportResponse is the IVT breakdown across all publishers
+type IVTReportResponse struct {
+	Window     string                           `json:"window"`
+	Checked    int64                            `json:"checked"`
+	Flagged    int64                            `json:"flagged"`
+	Blocked    int64                            `json:"blocked"`
+	Signals    map[string]int64                 `json:"signals"`
+	Publishers []middleware.IVTPublisherSummary `json:"publishers"`
+}
+
+// ServeHTTP handles IVT report requests
+// Routes:
+//
+//	GET /admin/ivt?window=1h       - Totals and per-publisher breakdown
+//	GET /admin/ivt/:id?window=24h  - One publisher with per-bucket counts
+//
+// window accepts Go durations between the bucket width (5m) and the retention (24h).
+func (h *IVTAdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method_not_allowed", Message: "Method not allowed"})
+		return
+	}
+	if h.stats == nil {
+		h.sendJSON(w, http.StatusServiceUnavailable, ErrorResponse{Error: "ivt_disabled", Message: "IVT detection is not enabled"})
+		return
+	}
+
+	window := defaultIVTReportWindow
+	if value := r.URL.Query().Get("window"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < middleware.IVTStatsBucketWidth || parsed > middleware.IVTStatsRetention {
+			h.sendJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_window",
+				Message: "window must be a duration between " + middleware.IVTStatsBucketWidth.String() + " and " + middleware.IVTStatsRetention.String(),
+			})
+			return
+		}
+		window = parsed
+	}
+
+	publisherID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/ivt"), "/")
+	if publisherID != "" {
+		summary, ok := h.stats.Summary(publisherID, window)
+		if !ok {
+			h.sendJSON(w, http.StatusNotFound, ErrorResponse{Error: "not_found", Message: "No IVT data for publisher " + publisherID + " in the last " + window.String()})
+			return
+		}
+		h.sendJSON(w, http.StatusOK, summary)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, h.Report(window))
+}
+
+// Report totals the per-publisher summaries over the window
+func (h *IVTAdminHandler) Report(window time.Duration) *IVTReportResponse {
+	report := &IVTReportResponse{
+		Window:     window.String(),
+		Signals:    make(map[string]int64),
+		Publishers: h.stats.Summaries(window),
+	}
+	for _, summary := range report.Publishers {
+		report.Checked += summary.Checked
+		report.Flagged += summary.Flagged
+		report.Blocked += summary.Blocked
+		for signalType, n := range summary.Signals {
+			report.Signals[signalType] += n
+		}
+	}
+	return report
+}
+
+// sendJSON writes a JSON response
+func (h *IVTAdminHandler) sendJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to encode JSON response")
+	}
+}
@@ -0,0 +1,114 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+)
+
+// newTestIVTStats returns aggregates with traffic for two publishers (test helper)
+func newTestIVTStats() *middleware.IVTStats {
+	stats := middleware.NewIVTStats()
+	stats.Record(&middleware.IVTResult{PublisherID: "pub1", IsValid: true})
+	stats.Record(&middleware.IVTResult{
+		PublisherID: "pub1",
+		ShouldBlock: true,
+		Signals:     []middleware.IVTSignal{{Type: "datacenter_ip"}},
+	})
+	stats.Record(&middleware.IVTResult{
+		PublisherID: "pub2",
+		Signals:     []middleware.IVTSignal{{Type: "suspicious_ua"}},
+	})
+	return stats
+}
+
+func TestIVTAdminHandler_Report(t *testing.T) {
+	handler := NewIVTAdminHandler(newTestIVTStats())
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/ivt?window=30m", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var report IVTReportResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if report.Window != "30m0s" {
+		t.Errorf("expected window 30m0s, got %s", report.Window)
+	}
+	if report.Checked != 3 || report.Flagged != 2 || report.Blocked != 1 {
+		t.Errorf("expected totals 3/2/1, got %d/%d/%d", report.Checked, report.Flagged, report.Blocked)
+	}
+	if report.Signals["datacenter_ip"] != 1 || report.Signals["suspicious_ua"] != 1 {
+		t.Errorf("unexpected signal totals %v", report.Signals)
+	}
+	if len(report.Publishers) != 2 || report.Publishers[0].PublisherID != "pub1" {
+		t.Fatalf("expected pub1 and pub2, got %+v", report.Publishers)
+	}
+	if report.Publishers[0].BlockedRate != 0.5 {
+		t.Errorf("expected pub1 blocked rate 0.5, got %f", report.Publishers[0].BlockedRate)
+	}
+}
+
+func TestIVTAdminHandler_Publisher(t *testing.T) {
+	handler := NewIVTAdminHandler(newTestIVTStats())
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/ivt/pub1", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var summary middleware.IVTPublisherSummary
+	if err := json.Unmarshal(rr.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if summary.PublisherID != "pub1" || summary.Window != "1h0m0s" || summary.Checked != 2 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	if len(summary.Series) != 1 || summary.Series[0].Signals["datacenter_ip"] != 1 {
+		t.Errorf("expected one bucket with the datacenter signal, got %+v", summary.Series)
+	}
+}
+
+func TestIVTAdminHandler_Errors(t *testing.T) {
+	handler := NewIVTAdminHandler(newTestIVTStats())
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		want   int
+	}{
+		{"unknown publisher", http.MethodGet, "/admin/ivt/nope", http.StatusNotFound},
+		{"invalid window", http.MethodGet, "/admin/ivt?window=soon", http.StatusBadRequest},
+		{"window too short", http.MethodGet, "/admin/ivt?window=1m", http.StatusBadRequest},
+		{"window past retention", http.MethodGet, "/admin/ivt?window=48h", http.StatusBadRequest},
+		{"wrong method", http.MethodPost, "/admin/ivt", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.target, nil))
+			if rr.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	rr := httptest.NewRecorder()
+	NewIVTAdminHandler(nil).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/ivt", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without stats, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,273 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// maxPrivacyDecodeBodySize bounds the POST body of /admin/privacy/decode
+const maxPrivacyDecodeBodySize = 64 * 1024
+
+// BidderInfoSource returns registered adapters with their bidder info
+type BidderInfoSource interface {
+	GetAll() map[string]adapters.AdapterWithInfo
+}
+
+// PrivacyDecodeHandler decodes consent strings and explains per-bidder privacy filtering
+type PrivacyDecodeHandler struct {
+	registry BidderInfoSource
+}
+
+// NewPrivacyDecodeHandler creates a consent string decoder backed by the adapter registry
+func NewPrivacyDecodeHandler(registry BidderInfoSource) *PrivacyDecodeHandler {
+	return &PrivacyDecodeHandler{registry: registry}
+}
+
+// PrivacyDecodeRequest carries the privacy signals to decode
+// Field names match the /cookie_sync and /setuid parameters
+type PrivacyDecodeRequest struct {
+	GDPR        *int         `json:"gdpr,omitempty"`
+	GDPRConsent string       `json:"gdpr_consent,omitempty"`
+	GPP         string       `json:"gpp,omitempty"`
+	GPPSID      string       `json:"gpp_sid,omitempty"`
+	USPrivacy   string       `json:"us_privacy,omitempty"`
+	Geo         *openrtb.Geo `json:"geo,omitempty"`
+}
+
+// PrivacyDecodeResponse is the decoded view of a request's privacy signals
+type PrivacyDecodeResponse struct {
+	TCF        *DecodedTCF     `json:"tcf,omitempty"`
+	TCFError   string          `json:"tcf_error,omitempty"`
+	GPP        *DecodedGPP     `json:"gpp,omitempty"`
+	GPPError   string          `json:"gpp_error,omitempty"`
+	USPrivacy  *DecodedUSP     `json:"us_privacy,omitempty"`
+	GDPR       bool            `json:"gdpr"`
+	Regulation string          `json:"regulation"`
+	Bidders    []BidderVerdict `json:"bidders"`
+}
+
+// DecodedTCF is a TCF v2 consent string with purposes, special features and vendors as 1-based IDs
+type DecodedTCF struct {
+	Version           int    `json:"version"`
+	CmpID             int    `json:"cmp_id"`
+	CmpVersion        int    `json:"cmp_version"`
+	ConsentScreen     int    `json:"consent_screen"`
+	ConsentLanguage   string `json:"consent_language"`
+	VendorListVersion int    `json:"vendor_list_version"`
+	Purposes          []int  `json:"purposes"`
+	SpecialFeatures   []int  `json:"special_features"`
+	Vendors           []int  `json:"vendors"`
+}
+
+// DecodedGPP is a GPP string split into its sections
+type DecodedGPP struct {
+	SectionIDs         []int             `json:"section_ids"`
+	Sections           map[string]string `json:"sections"`
+	ApplicableSections []int             `json:"gpp_sid,omitempty"`
+	TCFEUConsent       string            `json:"tcf_eu_consent,omitempty"`
+	USOptOut           bool              `json:"us_optout"`
+}
+
+// DecodedUSP is a US Privacy (CCPA) string
+type DecodedUSP struct {
+	Value       string `json:"value"`
+	Valid       bool   `json:"valid"`
+	OptOut      bool   `json:"opt_out"`
+	Notice      bool   `json:"notice"`
+	LSPACovered bool   `json:"lspa_covered"`
+}
+
+// BidderVerdict is whether the auction would skip a bidder for these privacy signals
+type BidderVerdict struct {
+	Bidder   string `json:"bidder"`
+	GVLID    int    `json:"gvl_id"`
+	Enabled  bool   `json:"enabled"`
+	Filtered bool   `json:"filtered"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// ServeHTTP handles GET (query parameters) and POST (JSON body) on /admin/privacy/decode
+func (h *PrivacyDecodeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req PrivacyDecodeRequest
+
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		req.GDPRConsent = q.Get("gdpr_consent")
+		req.GPP = q.Get("gpp")
+		req.GPPSID = q.Get("gpp_sid")
+		req.USPrivacy = q.Get("us_privacy")
+		if gdpr := q.Get("gdpr"); gdpr != "" {
+			value, err := strconv.Atoi(gdpr)
+			if err != nil {
+				h.sendJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_request", Message: "gdpr must be 0 or 1"})
+				return
+			}
+			req.GDPR = &value
+		}
+		if country, region := q.Get("country"), q.Get("region"); country != "" || region != "" {
+			req.Geo = &openrtb.Geo{Country: country, Region: region}
+		}
+
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPrivacyDecodeBodySize)).Decode(&req); err != nil {
+			h.sendJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_request", Message: "Invalid JSON body"})
+			return
+		}
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, h.Decode(&req))
+}
+
+// Decode decodes the privacy signals and evaluates every registered bidder
+// Bidders are evaluated with the same geo and consent filter the auction applies
+func (h *PrivacyDecodeHandler) Decode(req *PrivacyDecodeRequest) *PrivacyDecodeResponse {
+	resp := &PrivacyDecodeResponse{
+		GDPR:       req.GDPR != nil && *req.GDPR == 1,
+		Regulation: string(middleware.DetectRegulationFromGeo(req.Geo)),
+		Bidders:    []BidderVerdict{},
+	}
+
+	tcf, err := middleware.DecodeTCFv2(req.GDPRConsent)
+	if err != nil {
+		resp.TCFError = err.Error()
+	} else if tcf != nil {
+		resp.TCF = decodedTCF(tcf)
+	}
+
+	gpp, err := middleware.ParseGPPString(req.GPP)
+	if err != nil {
+		resp.GPPError = err.Error()
+	} else if gpp != nil {
+		resp.GPP = decodedGPP(gpp, req.GPPSID)
+	}
+
+	if req.USPrivacy != "" {
+		resp.USPrivacy = decodedUSP(req.USPrivacy)
+	}
+
+	if h.registry == nil {
+		return resp
+	}
+
+	bidReq := privacyDecodeBidRequest(req)
+	for code, awi := range h.registry.GetAll() {
+		gvlID := awi.Info.GVLVendorID
+		verdict := BidderVerdict{Bidder: code, GVLID: gvlID, Enabled: awi.Info.Enabled}
+		if middleware.ShouldFilterBidderByGeo(bidReq, gvlID) {
+			verdict.Filtered = true
+			verdict.Reason = filterReason(middleware.PrivacyRegulation(resp.Regulation), gvlID)
+		}
+		resp.Bidders = append(resp.Bidders, verdict)
+	}
+	sort.Slice(resp.Bidders, func(i, j int) bool {
+		return resp.Bidders[i].Bidder < resp.Bidders[j].Bidder
+	})
+	return resp
+}
+
+// privacyDecodeBidRequest builds the bid request fields ShouldFilterBidderByGeo reads
+func privacyDecodeBidRequest(req *PrivacyDecodeRequest) *openrtb.BidRequest {
+	bidReq := &openrtb.BidRequest{
+		ID: "privacy-decode",
+		Regs: &openrtb.Regs{
+			GDPR:      req.GDPR,
+			USPrivacy: req.USPrivacy,
+			GPP:       req.GPP,
+			GPPSID:    middleware.ParseGPPSID(req.GPPSID),
+		},
+		User: &openrtb.User{Consent: req.GDPRConsent},
+	}
+	if req.Geo != nil {
+		bidReq.Device = &openrtb.Device{Geo: req.Geo}
+	}
+	return bidReq
+}
+
+// filterReason explains why ShouldFilterBidderByGeo skipped a bidder
+func filterReason(regulation middleware.PrivacyRegulation, gvlID int) string {
+	if regulation == middleware.RegulationGDPR {
+		return fmt.Sprintf("GDPR applies and the TCF string has no consent for vendor %d", gvlID)
+	}
+	return fmt.Sprintf("%s applies and the US Privacy string signals an opt-out", regulation)
+}
+
+func decodedTCF(data *middleware.TCFv2Data) *DecodedTCF {
+	decoded := &DecodedTCF{
+		Version:           data.Version,
+		CmpID:             data.CmpID,
+		CmpVersion:        data.CmpVersion,
+		ConsentScreen:     data.ConsentScreen,
+		ConsentLanguage:   data.ConsentLanguage,
+		VendorListVersion: data.VendorListVersion,
+		Purposes:          setBitIDs(data.PurposeConsents),
+		SpecialFeatures:   setBitIDs(data.SpecialFeatureOptIns),
+		Vendors:           make([]int, 0, len(data.VendorConsents)),
+	}
+	for vendorID, consented := range data.VendorConsents {
+		if consented {
+			decoded.Vendors = append(decoded.Vendors, vendorID)
+		}
+	}
+	sort.Ints(decoded.Vendors)
+	return decoded
+}
+
+func decodedGPP(data *middleware.GPPData, gppSID string) *DecodedGPP {
+	decoded := &DecodedGPP{
+		SectionIDs:         data.SectionIDs,
+		Sections:           make(map[string]string, len(data.Sections)),
+		ApplicableSections: middleware.ParseGPPSID(gppSID),
+		TCFEUConsent:       data.TCFEUConsent(),
+		USOptOut:           data.HasUSOptOut(),
+	}
+	for id, section := range data.Sections {
+		decoded.Sections[strconv.Itoa(id)] = section
+	}
+	return decoded
+}
+
+// decodedUSP decodes a US Privacy string: version, notice, opt-out sale and LSPA coverage
+func decodedUSP(usp string) *DecodedUSP {
+	decoded := &DecodedUSP{Value: usp}
+	if len(usp) != 4 || usp[0] != '1' {
+		return decoded
+	}
+	decoded.Valid = true
+	decoded.Notice = usp[1] == 'Y'
+	decoded.OptOut = usp[2] == 'Y'
+	decoded.LSPACovered = usp[3] == 'Y'
+	return decoded
+}
+
+// setBitIDs converts a 0-based consent bit slice into 1-based IDs
+func setBitIDs(bits []bool) []int {
+	ids := []int{}
+	for i, set := range bits {
+		if set {
+			ids = append(ids, i+1)
+		}
+	}
+	return ids
+}
+
+// sendJSON sends a JSON response
+func (h *PrivacyDecodeHandler) sendJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to encode JSON response")
+	}
+}
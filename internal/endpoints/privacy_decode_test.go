@@ -0,0 +1,193 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// newTestPrivacyDecodeHandler registers one bidder with vendor consent in testTCFConsent (GVL 32),
+// one without (GVL 52) and one with no GVL ID
+func newTestPrivacyDecodeHandler() *PrivacyDecodeHandler {
+	registry := adapters.NewRegistry()
+	registry.Register("appnexus", nil, adapters.BidderInfo{Enabled: true, GVLVendorID: 32})
+	registry.Register("rubicon", nil, adapters.BidderInfo{Enabled: true, GVLVendorID: 52})
+	registry.Register("house", nil, adapters.BidderInfo{Enabled: false})
+	return NewPrivacyDecodeHandler(registry)
+}
+
+// verdicts indexes bidder verdicts by bidder code
+func verdicts(resp *PrivacyDecodeResponse) map[string]BidderVerdict {
+	byBidder := make(map[string]BidderVerdict, len(resp.Bidders))
+	for _, v := range resp.Bidders {
+		byBidder[v.Bidder] = v
+	}
+	return byBidder
+}
+
+func TestPrivacyDecode_GDPR(t *testing.T) {
+	gdpr := 1
+	resp := newTestPrivacyDecodeHandler().Decode(&PrivacyDecodeRequest{
+		GDPR:        &gdpr,
+		GDPRConsent: testTCFConsent,
+		Geo:         &openrtb.Geo{Country: "DEU"},
+	})
+
+	if resp.Regulation != "GDPR" || !resp.GDPR {
+		t.Errorf("expected GDPR to apply, got regulation=%s gdpr=%v", resp.Regulation, resp.GDPR)
+	}
+	if resp.TCF == nil {
+		t.Fatalf("expected decoded TCF, got error %q", resp.TCFError)
+	}
+	if !reflect.DeepEqual(resp.TCF.Purposes, []int{1, 2}) || !reflect.DeepEqual(resp.TCF.Vendors, []int{32}) {
+		t.Errorf("expected purposes [1 2] and vendors [32], got %v %v", resp.TCF.Purposes, resp.TCF.Vendors)
+	}
+	if resp.TCF.Version != 2 || resp.TCF.ConsentLanguage != "en" {
+		t.Errorf("unexpected TCF header: %+v", resp.TCF)
+	}
+
+	got := verdicts(resp)
+	if len(got) != 3 {
+		t.Fatalf("expected a verdict for every registered bidder, got %+v", resp.Bidders)
+	}
+	if got["appnexus"].Filtered {
+		t.Error("expected appnexus (vendor 32) to be allowed")
+	}
+	if v := got["rubicon"]; !v.Filtered || !strings.Contains(v.Reason, "vendor 52") {
+		t.Errorf("expected rubicon to be filtered for missing vendor consent, got %+v", v)
+	}
+	if v := got["house"]; v.Filtered || v.Enabled {
+		t.Errorf("expected house bidder without GVL ID to be allowed and reported disabled, got %+v", v)
+	}
+	if resp.Bidders[0].Bidder != "appnexus" || resp.Bidders[2].Bidder != "rubicon" {
+		t.Error("expected verdicts sorted by bidder code")
+	}
+}
+
+func TestPrivacyDecode_GDPRWithoutGeo(t *testing.T) {
+	gdpr := 1
+	resp := newTestPrivacyDecodeHandler().Decode(&PrivacyDecodeRequest{GDPR: &gdpr, GDPRConsent: testTCFConsent})
+
+	// The auction only filters by consent when the geo maps to a regulation
+	if resp.Regulation != "NONE" {
+		t.Errorf("expected no regulation without geo, got %s", resp.Regulation)
+	}
+	for _, v := range resp.Bidders {
+		if v.Filtered {
+			t.Errorf("expected %s to be allowed without geo", v.Bidder)
+		}
+	}
+}
+
+func TestPrivacyDecode_USPrivacy(t *testing.T) {
+	resp := newTestPrivacyDecodeHandler().Decode(&PrivacyDecodeRequest{
+		USPrivacy: "1YYN",
+		Geo:       &openrtb.Geo{Country: "USA", Region: "CA"},
+	})
+
+	if resp.Regulation != "CCPA" {
+		t.Errorf("expected CCPA, got %s", resp.Regulation)
+	}
+	want := &DecodedUSP{Value: "1YYN", Valid: true, Notice: true, OptOut: true}
+	if !reflect.DeepEqual(resp.USPrivacy, want) {
+		t.Errorf("expected %+v, got %+v", want, resp.USPrivacy)
+	}
+	for _, v := range resp.Bidders {
+		if !v.Filtered || !strings.Contains(v.Reason, "CCPA") {
+			t.Errorf("expected %s to be filtered for the opt-out, got %+v", v.Bidder, v)
+		}
+	}
+
+	if decodedUSP("YY").Valid {
+		t.Error("expected malformed US Privacy string to be invalid")
+	}
+}
+
+func TestPrivacyDecode_GPP(t *testing.T) {
+	resp := newTestPrivacyDecodeHandler().Decode(&PrivacyDecodeRequest{GPP: testGPPWithTCF, GPPSID: "2"})
+
+	if resp.GPP == nil {
+		t.Fatalf("expected decoded GPP, got error %q", resp.GPPError)
+	}
+	if !reflect.DeepEqual(resp.GPP.SectionIDs, []int{2}) || resp.GPP.Sections["2"] != testTCFConsent {
+		t.Errorf("expected TCF EU section, got %+v", resp.GPP)
+	}
+	if resp.GPP.TCFEUConsent != testTCFConsent || !reflect.DeepEqual(resp.GPP.ApplicableSections, []int{2}) {
+		t.Errorf("unexpected GPP decode: %+v", resp.GPP)
+	}
+	if resp.GPP.USOptOut {
+		t.Error("expected no US opt-out")
+	}
+}
+
+func TestPrivacyDecode_InvalidStrings(t *testing.T) {
+	resp := newTestPrivacyDecodeHandler().Decode(&PrivacyDecodeRequest{GDPRConsent: "not-a-consent-string!!", GPP: "!!"})
+
+	if resp.TCF != nil || resp.TCFError == "" {
+		t.Errorf("expected TCF error, got %+v", resp.TCF)
+	}
+	if resp.GPP != nil || resp.GPPError == "" {
+		t.Errorf("expected GPP error, got %+v", resp.GPP)
+	}
+}
+
+func TestPrivacyDecodeHandler_ServeHTTP(t *testing.T) {
+	h := newTestPrivacyDecodeHandler()
+
+	t.Run("GET query parameters", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/privacy/decode?gdpr=1&gdpr_consent="+testTCFConsent+"&country=DE", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+		var resp PrivacyDecodeResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		if resp.Regulation != "GDPR" || !verdicts(&resp)["rubicon"].Filtered {
+			t.Errorf("expected GDPR filtering of rubicon, got %+v", resp)
+		}
+	})
+
+	t.Run("POST JSON body", func(t *testing.T) {
+		body := `{"us_privacy":"1YYN","geo":{"country":"USA","region":"CA"}}`
+		req := httptest.NewRequest(http.MethodPost, "/admin/privacy/decode", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		var resp PrivacyDecodeResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("expected 200 with JSON, got %d: %s", w.Code, w.Body.String())
+		}
+		if resp.USPrivacy == nil || !resp.USPrivacy.OptOut {
+			t.Errorf("expected US Privacy opt-out, got %+v", resp.USPrivacy)
+		}
+	})
+
+	t.Run("bad requests", func(t *testing.T) {
+		for _, req := range []*http.Request{
+			httptest.NewRequest(http.MethodGet, "/admin/privacy/decode?gdpr=yes", nil),
+			httptest.NewRequest(http.MethodPost, "/admin/privacy/decode", strings.NewReader("{")),
+		} {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s %s: expected 400, got %d", req.Method, req.URL, w.Code)
+			}
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/privacy/decode", nil))
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected 405, got %d", w.Code)
+		}
+	})
+}
@@ -2,43 +2,107 @@
 package endpoints
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/mail"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/thenexusengine/tne_springwire/internal/fpd"
+	"github.com/thenexusengine/tne_springwire/internal/ivtpolicy"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
 	"github.com/thenexusengine/tne_springwire/pkg/logger"
 	"github.com/thenexusengine/tne_springwire/pkg/redis"
 )
 
+// PublisherAdminStore is the publisher storage behind the admin API
+// Implemented by storage.PublisherStore.
+type PublisherAdminStore interface {
+	Get(ctx context.Context, publisherID string) (*storage.Publisher, error)
+	ListPage(ctx context.Context, filter storage.PublisherFilter) ([]*storage.Publisher, int, error)
+	Create(ctx context.Context, p *storage.Publisher) error
+	UpdateIfUnmodified(ctx context.Context, p *storage.Publisher, lastUpdatedAt time.Time) error
+	Delete(ctx context.Context, publisherID string) error
+}
+
+// PublisherCacheInvalidator drops cached publisher records after a write
+// Implemented by middleware.PublisherAuth.
+type PublisherCacheInvalidator interface {
+	InvalidatePublisher(publisherID string)
+}
+
+// InvalidationPublisher tells other instances to evict a cached entry ("" = every entry of the kind)
+// Implemented by redis.InvalidationBus.
+type InvalidationPublisher interface {
+	Publish(ctx context.Context, kind, id string) error
+}
+
 // PublisherAdminHandler handles publisher CRUD operations via API
+// PostgreSQL is the source of truth; writes are mirrored to the Redis publishers hash
+// read by PublisherAuth on every instance.
 type PublisherAdminHandler struct {
+	store       PublisherAdminStore
 	redisClient *redis.Client
+	invalidator PublisherCacheInvalidator
+	bus         InvalidationPublisher
 }
 
 // NewPublisherAdminHandler creates a new publisher admin handler
-func NewPublisherAdminHandler(redisClient *redis.Client) *PublisherAdminHandler {
+// redisClient may be nil when publishers are only read from PostgreSQL.
+func NewPublisherAdminHandler(store PublisherAdminStore, redisClient *redis.Client) *PublisherAdminHandler {
 	return &PublisherAdminHandler{
+		store:       store,
 		redisClient: redisClient,
 	}
 }
 
-// Publisher represents a publisher configuration
-type Publisher struct {
-	ID             string   `json:"id"`
-	AllowedDomains string   `json:"allowed_domains"` // Pipe-separated: "domain1.com|*.domain2.com"
-	DomainList     []string `json:"domain_list"`     // Parsed array for display
+// SetCacheInvalidator sets the cache evicted after each publisher write
+func (h *PublisherAdminHandler) SetCacheInvalidator(invalidator PublisherCacheInvalidator) {
+	h.invalidator = invalidator
+}
+
+// SetInvalidationPublisher sets the bus that tells other instances about each publisher write
+func (h *PublisherAdminHandler) SetInvalidationPublisher(bus InvalidationPublisher) {
+	h.bus = bus
 }
 
 // PublisherListResponse is the response for listing publishers
 type PublisherListResponse struct {
-	Publishers []Publisher `json:"publishers"`
-	Count      int         `json:"count"`
+	Publishers []*storage.Publisher `json:"publishers"`
+	Count      int                  `json:"count"`
+	Total      int                  `json:"total"`
+	Limit      int                  `json:"limit"`
+	Offset     int                  `json:"offset"`
 }
 
 // PublisherRequest is the request body for creating/updating publishers
+// PUT replaces the whole publisher and must echo the updated_at it last read.
 type PublisherRequest struct {
-	ID             string `json:"id"`
-	AllowedDomains string `json:"allowed_domains"`
+	PublisherID    string                 `json:"publisher_id"`
+	Name           string                 `json:"name"`
+	AllowedDomains string                 `json:"allowed_domains"` // Pipe-separated: "domain1.com|*.domain2.com"
+	BidderParams   map[string]interface{} `json:"bidder_params"`
+	BidMultiplier  float64                `json:"bid_multiplier"` // 0 = 1.0
+	Status         string                 `json:"status"`         // "" = active
+	Notes          string                 `json:"notes"`
+	ContactEmail   string                 `json:"contact_email"`
+	EIDPermissions []fpd.EIDPermission    `json:"eid_permissions"`
+	DSA            *openrtb.ExtRegsDSA    `json:"dsa"`
+	IVTPolicy      *ivtpolicy.Policy      `json:"ivt_policy"`
+	RateLimitTier  string                 `json:"rate_limit_tier"`
+	UpdatedAt      *time.Time             `json:"updated_at"` // Required on PUT
+}
+
+// PublisherConflictResponse is returned when a PUT carries a stale updated_at
+type PublisherConflictResponse struct {
+	ErrorResponse
+	Current *storage.Publisher `json:"current,omitempty"`
 }
 
 // ErrorResponse is a standard error response
@@ -49,28 +113,26 @@ type ErrorResponse struct {
 
 const publishersHashKey = "tne_catalyst:publishers"
 
+// maxPublisherIDLength matches the publishers.publisher_id column
+const maxPublisherIDLength = 255
+
 // ServeHTTP handles publisher API requests
 // Routes:
 //
-//	GET    /admin/publishers       - List all publishers
-//	GET    /admin/publishers/:id   - Get specific publisher
+//	GET    /admin/publishers       - List publishers (?status=&limit=&offset=)
+//	GET    /admin/publishers/:id   - Get specific publisher (any status)
 //	POST   /admin/publishers       - Create publisher
-//	PUT    /admin/publishers/:id   - Update publisher
-//	DELETE /admin/publishers/:id   - Delete publisher
+//	PUT    /admin/publishers/:id   - Replace publisher (requires updated_at)
+//	DELETE /admin/publishers/:id   - Archive publisher
 func (h *PublisherAdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	// Check if Redis is available
-	if h.redisClient == nil {
-		h.sendError(w, http.StatusServiceUnavailable, "Redis not available", "Publisher management requires Redis connection")
+	if h.store == nil {
+		h.sendError(w, http.StatusServiceUnavailable, "database_unavailable", "Publisher management requires a database connection")
 		return
 	}
 
 	// Parse path to extract publisher ID if present
 	path := strings.TrimPrefix(r.URL.Path, "/admin/publishers")
-	path = strings.Trim(path, "/")
-	publisherID := ""
-	if path != "" {
-		publisherID = path
-	}
+	publisherID := strings.Trim(path, "/")
 
 	switch r.Method {
 	case http.MethodGet:
@@ -98,59 +160,59 @@ func (h *PublisherAdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 	}
 }
 
-// listPublishers returns all registered publishers
+// listPublishers returns one page of publishers ordered by publisher_id
 func (h *PublisherAdminHandler) listPublishers(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	query := r.URL.Query()
+	filter := storage.PublisherFilter{Status: query.Get("status")}
 
-	// Get all publishers from Redis hash
-	publishers, err := h.redisClient.HGetAll(ctx, publishersHashKey)
-	if err != nil {
-		logger.Log.Error().Err(err).Msg("Failed to list publishers from Redis")
-		h.sendError(w, http.StatusInternalServerError, "redis_error", "Failed to retrieve publishers")
+	if filter.Status != "" && !validPublisherStatus(filter.Status) {
+		h.sendError(w, http.StatusBadRequest, "invalid_status", "status must be active, paused or archived")
 		return
 	}
-
-	// Convert to response format
-	pubList := make([]Publisher, 0, len(publishers))
-	for id, domains := range publishers {
-		pubList = append(pubList, Publisher{
-			ID:             id,
-			AllowedDomains: domains,
-			DomainList:     parseDomains(domains),
-		})
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > storage.MaxPublisherPageSize {
+			h.sendError(w, http.StatusBadRequest, "invalid_limit", fmt.Sprintf("limit must be between 1 and %d", storage.MaxPublisherPageSize))
+			return
+		}
+		filter.Limit = limit
+	} else {
+		filter.Limit = storage.DefaultPublisherPageSize
+	}
+	if raw := query.Get("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			h.sendError(w, http.StatusBadRequest, "invalid_offset", "offset must be a non-negative integer")
+			return
+		}
+		filter.Offset = offset
 	}
 
-	response := PublisherListResponse{
-		Publishers: pubList,
-		Count:      len(pubList),
+	publishers, total, err := h.store.ListPage(r.Context(), filter)
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to list publishers")
+		h.sendError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve publishers")
+		return
+	}
+	if publishers == nil {
+		publishers = []*storage.Publisher{}
 	}
 
-	h.sendJSON(w, http.StatusOK, response)
+	h.sendJSON(w, http.StatusOK, PublisherListResponse{
+		Publishers: publishers,
+		Count:      len(publishers),
+		Total:      total,
+		Limit:      filter.Limit,
+		Offset:     filter.Offset,
+	})
 }
 
 // getPublisher returns a specific publisher by ID
 func (h *PublisherAdminHandler) getPublisher(w http.ResponseWriter, r *http.Request, publisherID string) {
-	ctx := r.Context()
-
-	// Get publisher from Redis
-	domains, err := h.redisClient.HGet(ctx, publishersHashKey, publisherID)
-	if err != nil {
-		logger.Log.Error().Err(err).Str("publisher_id", publisherID).Msg("Failed to get publisher from Redis")
-		h.sendError(w, http.StatusInternalServerError, "redis_error", "Failed to retrieve publisher")
-		return
-	}
-
-	if domains == "" {
-		h.sendError(w, http.StatusNotFound, "not_found", "Publisher not found")
+	publisher, ok := h.loadPublisher(w, r.Context(), publisherID)
+	if !ok {
 		return
 	}
-
-	publisher := Publisher{
-		ID:             publisherID,
-		AllowedDomains: domains,
-		DomainList:     parseDomains(domains),
-	}
-
 	h.sendJSON(w, http.StatusOK, publisher)
 }
 
@@ -158,145 +220,299 @@ func (h *PublisherAdminHandler) getPublisher(w http.ResponseWriter, r *http.Requ
 func (h *PublisherAdminHandler) createPublisher(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	// Parse request body
 	var req PublisherRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.sendError(w, http.StatusBadRequest, "invalid_json", "Invalid request body")
 		return
 	}
 
-	// Validate
-	if req.ID == "" {
-		h.sendError(w, http.StatusBadRequest, "missing_id", "Publisher ID is required")
-		return
-	}
-	if req.AllowedDomains == "" {
-		h.sendError(w, http.StatusBadRequest, "missing_domains", "Allowed domains are required")
-		return
-	}
-
-	// Check if publisher already exists
-	existing, err := h.redisClient.HGet(ctx, publishersHashKey, req.ID)
-	if err != nil {
-		logger.Log.Error().Err(err).Str("publisher_id", req.ID).Msg("Failed to check existing publisher")
-		h.sendError(w, http.StatusInternalServerError, "redis_error", "Failed to check existing publisher")
-		return
-	}
-	if existing != "" {
-		h.sendError(w, http.StatusConflict, "already_exists", "Publisher already exists. Use PUT to update.")
+	publisher := req.toPublisher(req.PublisherID)
+	if err := validatePublisher(publisher); err != nil {
+		h.sendError(w, http.StatusBadRequest, "validation_failed", err.Error())
 		return
 	}
 
-	// Create publisher in Redis
-	if err := h.redisClient.HSet(ctx, publishersHashKey, req.ID, req.AllowedDomains); err != nil {
-		logger.Log.Error().Err(err).Str("publisher_id", req.ID).Msg("Failed to create publisher in Redis")
-		h.sendError(w, http.StatusInternalServerError, "redis_error", "Failed to create publisher")
+	if err := h.store.Create(ctx, publisher); err != nil {
+		if errors.Is(err, storage.ErrPublisherExists) {
+			h.sendError(w, http.StatusConflict, "already_exists", "Publisher already exists. Use PUT to update.")
+			return
+		}
+		logger.Log.Error().Err(err).Str("publisher_id", publisher.PublisherID).Msg("Failed to create publisher")
+		h.sendError(w, http.StatusInternalServerError, "database_error", "Failed to create publisher")
 		return
 	}
 
+	h.propagate(ctx, publisher)
 	logger.Log.Info().
-		Str("publisher_id", req.ID).
-		Str("domains", req.AllowedDomains).
+		Str("publisher_id", publisher.PublisherID).
+		Str("status", publisher.Status).
+		Str("domains", publisher.AllowedDomains).
 		Msg("Publisher created")
-
-	// Return created publisher
-	publisher := Publisher{
-		ID:             req.ID,
-		AllowedDomains: req.AllowedDomains,
-		DomainList:     parseDomains(req.AllowedDomains),
-	}
+	middleware.RecordAuditChange(ctx, nil, publisher)
 
 	h.sendJSON(w, http.StatusCreated, publisher)
 }
 
-// updatePublisher updates an existing publisher
+// updatePublisher replaces an existing publisher if it hasn't changed since the client read it
 func (h *PublisherAdminHandler) updatePublisher(w http.ResponseWriter, r *http.Request, publisherID string) {
 	ctx := r.Context()
 
-	// Parse request body
 	var req PublisherRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.sendError(w, http.StatusBadRequest, "invalid_json", "Invalid request body")
 		return
 	}
-
-	// Validate
-	if req.AllowedDomains == "" {
-		h.sendError(w, http.StatusBadRequest, "missing_domains", "Allowed domains are required")
+	if req.PublisherID != "" && req.PublisherID != publisherID {
+		h.sendError(w, http.StatusBadRequest, "publisher_id_mismatch", "publisher_id in body must match the path")
+		return
+	}
+	if req.UpdatedAt == nil {
+		h.sendError(w, http.StatusPreconditionRequired, "missing_updated_at", "updated_at from the last read is required")
 		return
 	}
 
-	// Check if publisher exists
-	existing, err := h.redisClient.HGet(ctx, publishersHashKey, publisherID)
-	if err != nil {
-		logger.Log.Error().Err(err).Str("publisher_id", publisherID).Msg("Failed to check existing publisher")
-		h.sendError(w, http.StatusInternalServerError, "redis_error", "Failed to check existing publisher")
+	publisher := req.toPublisher(publisherID)
+	if err := validatePublisher(publisher); err != nil {
+		h.sendError(w, http.StatusBadRequest, "validation_failed", err.Error())
 		return
 	}
-	if existing == "" {
-		h.sendError(w, http.StatusNotFound, "not_found", "Publisher not found. Use POST to create.")
+
+	existing, ok := h.loadPublisher(w, ctx, publisherID)
+	if !ok {
 		return
 	}
 
-	// Update publisher in Redis
-	if err := h.redisClient.HSet(ctx, publishersHashKey, publisherID, req.AllowedDomains); err != nil {
-		logger.Log.Error().Err(err).Str("publisher_id", publisherID).Msg("Failed to update publisher in Redis")
-		h.sendError(w, http.StatusInternalServerError, "redis_error", "Failed to update publisher")
+	err := h.store.UpdateIfUnmodified(ctx, publisher, *req.UpdatedAt)
+	switch {
+	case errors.Is(err, storage.ErrPublisherNotFound):
+		h.sendError(w, http.StatusNotFound, "not_found", "Publisher not found. Use POST to create.")
+		return
+	case errors.Is(err, storage.ErrPublisherModified):
+		h.sendConflict(w, ctx, publisherID)
+		return
+	case err != nil:
+		logger.Log.Error().Err(err).Str("publisher_id", publisherID).Msg("Failed to update publisher")
+		h.sendError(w, http.StatusInternalServerError, "database_error", "Failed to update publisher")
 		return
 	}
 
+	h.propagate(ctx, publisher)
 	logger.Log.Info().
 		Str("publisher_id", publisherID).
-		Str("old_domains", existing).
-		Str("new_domains", req.AllowedDomains).
+		Str("old_status", existing.Status).
+		Str("new_status", publisher.Status).
+		Str("old_domains", existing.AllowedDomains).
+		Str("new_domains", publisher.AllowedDomains).
 		Msg("Publisher updated")
-
-	// Return updated publisher
-	publisher := Publisher{
-		ID:             publisherID,
-		AllowedDomains: req.AllowedDomains,
-		DomainList:     parseDomains(req.AllowedDomains),
-	}
+	middleware.RecordAuditChange(ctx, existing, publisher)
 
 	h.sendJSON(w, http.StatusOK, publisher)
 }
 
-// deletePublisher deletes a publisher
+// deletePublisher archives a publisher; the row is kept for reporting
 func (h *PublisherAdminHandler) deletePublisher(w http.ResponseWriter, r *http.Request, publisherID string) {
 	ctx := r.Context()
 
-	// Check if publisher exists
-	existing, err := h.redisClient.HGet(ctx, publishersHashKey, publisherID)
-	if err != nil {
-		logger.Log.Error().Err(err).Str("publisher_id", publisherID).Msg("Failed to check existing publisher")
-		h.sendError(w, http.StatusInternalServerError, "redis_error", "Failed to check existing publisher")
-		return
-	}
-	if existing == "" {
-		h.sendError(w, http.StatusNotFound, "not_found", "Publisher not found")
+	existing, ok := h.loadPublisher(w, ctx, publisherID)
+	if !ok {
 		return
 	}
 
-	// Delete publisher from Redis
-	if err := h.redisClient.HDel(ctx, publishersHashKey, publisherID); err != nil {
-		logger.Log.Error().Err(err).Str("publisher_id", publisherID).Msg("Failed to delete publisher from Redis")
-		h.sendError(w, http.StatusInternalServerError, "redis_error", "Failed to delete publisher")
+	if err := h.store.Delete(ctx, publisherID); err != nil {
+		logger.Log.Error().Err(err).Str("publisher_id", publisherID).Msg("Failed to archive publisher")
+		h.sendError(w, http.StatusInternalServerError, "database_error", "Failed to archive publisher")
 		return
 	}
 
+	archived := *existing
+	archived.Status = storage.PublisherStatusArchived
+	h.propagate(ctx, &archived)
+
 	logger.Log.Info().
 		Str("publisher_id", publisherID).
-		Str("domains", existing).
-		Msg("Publisher deleted")
+		Str("domains", existing.AllowedDomains).
+		Msg("Publisher archived")
+	middleware.RecordAuditChange(ctx, existing, &archived)
+
+	h.sendJSON(w, http.StatusOK, map[string]interface{}{
+		"success":      true,
+		"publisher_id": publisherID,
+		"status":       storage.PublisherStatusArchived,
+	})
+}
+
+// loadPublisher fetches a publisher, writing the error response if it can't
+func (h *PublisherAdminHandler) loadPublisher(w http.ResponseWriter, ctx context.Context, publisherID string) (*storage.Publisher, bool) {
+	publisher, err := h.store.Get(ctx, publisherID)
+	if errors.Is(err, storage.ErrPublisherNotFound) {
+		h.sendError(w, http.StatusNotFound, "not_found", "Publisher not found")
+		return nil, false
+	}
+	if err != nil {
+		logger.Log.Error().Err(err).Str("publisher_id", publisherID).Msg("Failed to get publisher")
+		h.sendError(w, http.StatusInternalServerError, "database_error", "Failed to retrieve publisher")
+		return nil, false
+	}
+	return publisher, true
+}
+
+// sendConflict reports a stale updated_at along with the current publisher
+func (h *PublisherAdminHandler) sendConflict(w http.ResponseWriter, ctx context.Context, publisherID string) {
+	response := PublisherConflictResponse{
+		ErrorResponse: ErrorResponse{
+			Error:   "conflict",
+			Message: "Publisher was modified since it was read; re-read it and retry",
+		},
+	}
+	if current, err := h.store.Get(ctx, publisherID); err == nil {
+		response.Current = current
+	}
+	h.sendJSON(w, http.StatusConflict, response)
+}
+
+// propagate mirrors a write to the Redis publishers hash and evicts cached copies
+// Only active publishers are kept in the hash, so pausing or archiving takes effect
+// on every instance at once. Redis failures are logged; PostgreSQL stays authoritative.
+func (h *PublisherAdminHandler) propagate(ctx context.Context, p *storage.Publisher) {
+	if h.invalidator != nil {
+		h.invalidator.InvalidatePublisher(p.PublisherID)
+	}
+	if h.redisClient != nil {
+		var err error
+		if p.Status == storage.PublisherStatusActive {
+			err = h.redisClient.HSet(ctx, publishersHashKey, p.PublisherID, p.AllowedDomains)
+		} else {
+			err = h.redisClient.HDel(ctx, publishersHashKey, p.PublisherID)
+		}
+		if err != nil {
+			logger.Log.Warn().Err(err).Str("publisher_id", p.PublisherID).Msg("Failed to sync publisher to Redis")
+		}
+	}
 
-	// Return success with deleted info
-	response := map[string]interface{}{
-		"success":         true,
-		"publisher_id":    publisherID,
-		"deleted_domains": existing,
+	// Other instances evict after the hash is updated, so they re-read the new record
+	if h.bus != nil {
+		if err := h.bus.Publish(ctx, redis.CachePublishers, p.PublisherID); err != nil {
+			logger.Log.Warn().Err(err).Str("publisher_id", p.PublisherID).Msg("Failed to publish publisher invalidation")
+		}
 	}
+}
+
+// toPublisher converts the request into the stored model, applying defaults
+func (req *PublisherRequest) toPublisher(publisherID string) *storage.Publisher {
+	p := &storage.Publisher{
+		PublisherID:    strings.TrimSpace(publisherID),
+		Name:           strings.TrimSpace(req.Name),
+		AllowedDomains: strings.Join(parseDomains(req.AllowedDomains), "|"),
+		BidderParams:   req.BidderParams,
+		BidMultiplier:  req.BidMultiplier,
+		Status:         req.Status,
+		Notes:          req.Notes,
+		ContactEmail:   strings.TrimSpace(req.ContactEmail),
+		EIDPermissions: req.EIDPermissions,
+		DSA:            req.DSA,
+		IVTPolicy:      req.IVTPolicy,
+		RateLimitTier:  strings.TrimSpace(req.RateLimitTier),
+	}
+	if p.BidMultiplier == 0 {
+		p.BidMultiplier = 1.0
+	}
+	if p.Status == "" {
+		p.Status = storage.PublisherStatusActive
+	}
+	if p.BidderParams == nil {
+		p.BidderParams = map[string]interface{}{}
+	}
+	return p
+}
 
-	h.sendJSON(w, http.StatusOK, response)
+// validatePublisher checks a publisher against the constraints PublisherAuth and the exchange rely on
+func validatePublisher(p *storage.Publisher) error {
+	if !validPublisherID(p.PublisherID) {
+		return fmt.Errorf("publisher_id must be 1-%d letters, digits, '.', '-' or '_'", maxPublisherIDLength)
+	}
+	if p.Name == "" {
+		return errors.New("name is required")
+	}
+	domains := parseDomains(p.AllowedDomains)
+	if len(domains) == 0 {
+		return errors.New("allowed_domains is required")
+	}
+	for _, domain := range domains {
+		if !validAllowedDomain(domain) {
+			return fmt.Errorf("invalid allowed domain %q", domain)
+		}
+	}
+	if p.BidMultiplier < 1.0 || p.BidMultiplier > 10.0 {
+		return errors.New("bid_multiplier must be between 1.0 and 10.0")
+	}
+	if !validPublisherStatus(p.Status) {
+		return errors.New("status must be active, paused or archived")
+	}
+	if p.ContactEmail != "" {
+		if _, err := mail.ParseAddress(p.ContactEmail); err != nil {
+			return fmt.Errorf("invalid contact_email: %w", err)
+		}
+	}
+	for i, perm := range p.EIDPermissions {
+		if strings.TrimSpace(perm.Source) == "" || len(perm.Bidders) == 0 {
+			return fmt.Errorf("eid_permissions[%d] needs a source and at least one bidder", i)
+		}
+	}
+	if p.IVTPolicy != nil {
+		if err := p.IVTPolicy.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.DSA != nil {
+		if err := p.DSA.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validPublisherID reports whether an ID is safe to use in paths, Redis fields and logs
+func validPublisherID(id string) bool {
+	if id == "" || len(id) > maxPublisherIDLength {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// validPublisherStatus reports whether status is allowed by the publishers.status constraint
+func validPublisherStatus(status string) bool {
+	switch status {
+	case storage.PublisherStatusActive, storage.PublisherStatusPaused, storage.PublisherStatusArchived:
+		return true
+	}
+	return false
+}
+
+// validAllowedDomain accepts "*", "*.example.com" and plain hostnames
+func validAllowedDomain(domain string) bool {
+	if domain == "*" {
+		return true
+	}
+	domain = strings.TrimPrefix(domain, "*.")
+	if len(domain) > 253 || !strings.Contains(domain, ".") {
+		return false
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
 }
 
 // parseDomains splits pipe-separated domains into array
@@ -0,0 +1,390 @@
+package endpoints
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// Segment taxonomies (IAB segtax) for Topics API taxonomy versions 1 and 2
+const (
+	segtaxTopicsV1 = 600
+	segtaxTopicsV2 = 601
+)
+
+// maxTopicsSegments bounds the topics read from one Sec-Browsing-Topics header
+const maxTopicsSegments = 10
+
+// topicsGroup holds the topics of one taxonomy and model version
+type topicsGroup struct {
+	segtax   int
+	segclass string // Classifier model version
+	topics   []int
+}
+
+// applyRequestHeaders fills bid request fields the body omits from the browser's HTTP headers:
+// site.page (Referer) and user.data (Sec-Browsing-Topics). Device fields are filled earlier by DeviceHeaders.
+func applyRequestHeaders(w http.ResponseWriter, r *http.Request, req *openrtb.BidRequest) {
+	if req.Site != nil && req.Site.Page == "" {
+		req.Site.Page = r.Header.Get("Referer")
+	}
+
+	if applyTopicsHeader(r.Header.Get("Sec-Browsing-Topics"), req) {
+		// Tell the browser the topics were observed so they count towards future epochs
+		w.Header().Set("Observe-Browsing-Topics", "?1")
+	}
+}
+
+// DeviceHeaders fills device.ua, device.sua and device.ip from the browser's HTTP headers
+// It runs ahead of geo enrichment, the privacy middleware and the IVT checks, so header-derived
+// values are located, anonymized and minimized exactly like values sent in the body.
+type DeviceHeaders struct {
+	clientIP func(*http.Request) string
+}
+
+// NewDeviceHeaders creates the device header middleware
+// clientIP resolves the client address, trusting X-Forwarded-For only from trusted proxies
+// (RateLimiter.ClientIP); nil uses the connection's remote address.
+func NewDeviceHeaders(clientIP func(*http.Request) string) *DeviceHeaders {
+	if clientIP == nil {
+		clientIP = func(r *http.Request) string {
+			if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+				return host
+			}
+			return r.RemoteAddr
+		}
+	}
+	return &DeviceHeaders{clientIP: clientIP}
+}
+
+// Middleware returns the device header middleware handler
+func (d *DeviceHeaders) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasPrefix(r.URL.Path, "/openrtb2/auction") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBodySize))
+		r.Body.Close()
+		if err != nil {
+			writeError(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+
+		if filled, ok := d.fillBody(r, body); ok {
+			body = filled
+			r.ContentLength = int64(len(body))
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// fillBody returns the body with header-derived device fields and whether it was modified
+// Unknown fields and extensions are preserved by editing the raw JSON map
+func (d *DeviceHeaders) fillBody(r *http.Request, body []byte) ([]byte, bool) {
+	var req openrtb.BidRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, false // Let the handler deal with invalid JSON
+	}
+	fields := deviceHeaderFields(r, req.Device, publicIP(d.clientIP(r)))
+	if len(fields) == 0 {
+		return nil, false
+	}
+
+	// UseNumber keeps large integer IDs intact through the round trip
+	var rawRequest map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&rawRequest); err != nil {
+		return nil, false
+	}
+	deviceMap, ok := rawRequest["device"].(map[string]interface{})
+	if !ok {
+		deviceMap = make(map[string]interface{})
+		rawRequest["device"] = deviceMap
+	}
+	for k, v := range fields {
+		deviceMap[k] = v
+	}
+
+	filled, err := json.Marshal(rawRequest)
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to marshal request after filling device headers")
+		return nil, false
+	}
+	return filled, true
+}
+
+// deviceHeaderFields returns the device fields to fill from headers: ua, sua and ip or ipv6
+// Fields the body already sets are left alone.
+func deviceHeaderFields(r *http.Request, device *openrtb.Device, ip net.IP) map[string]interface{} {
+	if device == nil {
+		device = &openrtb.Device{}
+	}
+	fields := make(map[string]interface{})
+
+	if ua := r.Header.Get("User-Agent"); device.UA == "" && ua != "" {
+		fields["ua"] = ua
+	}
+	if device.SUA == nil {
+		if sua := parseClientHints(r.Header); sua != nil {
+			fields["sua"] = sua
+		}
+	}
+	if device.IP == "" && device.IPv6 == "" && ip != nil {
+		if ip.To4() != nil {
+			fields["ip"] = ip.String()
+		} else {
+			fields["ipv6"] = ip.String()
+		}
+	}
+	return fields
+}
+
+// publicIP parses a client address, returning nil for addresses that cannot be the user's
+// (private, loopback, link-local): those are proxies missing from TRUSTED_PROXIES.
+func publicIP(addr string) net.IP {
+	ip := net.ParseIP(strings.TrimSpace(addr))
+	if ip == nil || !adapters.IsPublicIP(ip) {
+		return nil
+	}
+	return ip
+}
+
+// parseClientHints builds an OpenRTB 2.6 device.sua object from User-Agent Client Hints
+// Returns nil if the browser sent no brand or platform hints
+func parseClientHints(h http.Header) *openrtb.UserAgent {
+	sua := &openrtb.UserAgent{Source: openrtb.UASourceLowEntropy}
+
+	if list := h.Get("Sec-CH-UA-Full-Version-List"); list != "" {
+		sua.Browsers = parseBrandList(list)
+		sua.Source = openrtb.UASourceHighEntropy
+	} else if list := h.Get("Sec-CH-UA"); list != "" {
+		sua.Browsers = parseBrandList(list)
+	}
+
+	if platform := sfString(h.Get("Sec-CH-UA-Platform")); platform != "" {
+		sua.Platform = &openrtb.BrandVersion{Brand: platform}
+		if version := sfString(h.Get("Sec-CH-UA-Platform-Version")); version != "" {
+			sua.Platform.Version = strings.Split(version, ".")
+			sua.Source = openrtb.UASourceHighEntropy
+		}
+	}
+
+	if len(sua.Browsers) == 0 && sua.Platform == nil {
+		return nil
+	}
+
+	switch strings.TrimSpace(h.Get("Sec-CH-UA-Mobile")) {
+	case "?1":
+		mobile := 1
+		sua.Mobile = &mobile
+	case "?0":
+		mobile := 0
+		sua.Mobile = &mobile
+	}
+
+	for _, hint := range []struct {
+		header string
+		field  *string
+	}{
+		{"Sec-CH-UA-Arch", &sua.Architecture},
+		{"Sec-CH-UA-Bitness", &sua.Bitness},
+		{"Sec-CH-UA-Model", &sua.Model},
+	} {
+		if value := sfString(h.Get(hint.header)); value != "" {
+			*hint.field = value
+			sua.Source = openrtb.UASourceHighEntropy
+		}
+	}
+
+	return sua
+}
+
+// parseBrandList parses a Sec-CH-UA brand list: "Brand";v="1.2.3", "Other";v="4"
+// Brands are quoted strings that may contain ',' and ';' (GREASE brands such as "Not)A;Brand").
+func parseBrandList(value string) []openrtb.BrandVersion {
+	var brands []openrtb.BrandVersion
+	for _, item := range sfSplit(value, ',') {
+		parts := sfSplit(item, ';')
+		brand := sfString(parts[0])
+		if brand == "" {
+			continue
+		}
+		bv := openrtb.BrandVersion{Brand: brand}
+		for _, param := range parts[1:] {
+			if k, v, ok := strings.Cut(strings.TrimSpace(param), "="); ok && k == "v" {
+				if version := sfString(v); version != "" {
+					bv.Version = strings.Split(version, ".")
+				}
+			}
+		}
+		brands = append(brands, bv)
+	}
+	return brands
+}
+
+// sfSplit splits a structured header value on sep, ignoring separators inside quoted strings (RFC 8941)
+func sfSplit(value string, sep byte) []string {
+	var parts []string
+	start, quoted := 0, false
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case quoted && c == '\\':
+			i++ // Escaped character
+		case c == '"':
+			quoted = !quoted
+		case !quoted && c == sep:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, value[start:])
+}
+
+// sfString returns a structured header string value without surrounding whitespace and quotes
+// Escapes inside a quoted string (\" and \\) are resolved.
+func sfString(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return strings.Trim(value, `"`)
+	}
+	value = value[1 : len(value)-1]
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+// parseTopicsHeader parses a Sec-Browsing-Topics header
+// Format: (1 2);v=chrome.1:1:2, (3);v=chrome.1:2:2, ();p=P0000000
+// The version is <browser>.<config>:<taxonomy>:<model>. Groups with unknown taxonomies are skipped.
+func parseTopicsHeader(value string) []topicsGroup {
+	var groups []topicsGroup
+	count := 0
+
+	for _, member := range strings.Split(value, ",") {
+		member = strings.TrimSpace(member)
+		end := strings.Index(member, ")")
+		if !strings.HasPrefix(member, "(") || end < 0 {
+			continue
+		}
+
+		version := ""
+		for _, param := range strings.Split(member[end+1:], ";") {
+			if k, v, ok := strings.Cut(strings.TrimSpace(param), "="); ok && k == "v" {
+				version = sfString(v)
+			}
+		}
+		parts := strings.Split(version, ":")
+		if len(parts) != 3 || parts[2] == "" {
+			continue
+		}
+
+		group := topicsGroup{segclass: parts[2]}
+		switch parts[1] {
+		case "1":
+			group.segtax = segtaxTopicsV1
+		case "2":
+			group.segtax = segtaxTopicsV2
+		default:
+			continue
+		}
+
+		for _, field := range strings.Fields(member[1:end]) {
+			topic, err := strconv.Atoi(field)
+			if err != nil || topic <= 0 || count >= maxTopicsSegments {
+				continue
+			}
+			group.topics = append(group.topics, topic)
+			count++
+		}
+		if len(group.topics) > 0 {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups
+}
+
+// applyTopicsHeader adds topics from a Sec-Browsing-Topics header to user.data
+// Topics merge into an existing user.data entry with the same segtax and segclass.
+// Returns true if the header carried any topics. COPPA requests are skipped.
+func applyTopicsHeader(value string, req *openrtb.BidRequest) bool {
+	if value == "" || (req.Regs != nil && req.Regs.COPPA == 1) {
+		return false
+	}
+	groups := parseTopicsHeader(value)
+	if len(groups) == 0 {
+		return false
+	}
+
+	if req.User == nil {
+		req.User = &openrtb.User{}
+	}
+	name := ""
+	if req.Site != nil {
+		name = req.Site.Domain
+	}
+
+	for _, group := range groups {
+		idx := findTopicsData(req.User.Data, group)
+		if idx < 0 {
+			ext, _ := json.Marshal(map[string]interface{}{ //nolint:errcheck // map of ints and strings always marshals
+				"segtax":   group.segtax,
+				"segclass": group.segclass,
+			})
+			req.User.Data = append(req.User.Data, openrtb.Data{Name: name, Ext: ext})
+			idx = len(req.User.Data) - 1
+		}
+
+		data := &req.User.Data[idx]
+		existing := make(map[string]bool, len(data.Segment))
+		for _, seg := range data.Segment {
+			existing[seg.ID] = true
+		}
+		for _, topic := range group.topics {
+			id := strconv.Itoa(topic)
+			if !existing[id] {
+				data.Segment = append(data.Segment, openrtb.Segment{ID: id})
+				existing[id] = true
+			}
+		}
+	}
+	return true
+}
+
+// findTopicsData returns the index of the user.data entry for the group's segtax and segclass, or -1
+func findTopicsData(data []openrtb.Data, group topicsGroup) int {
+	for i, d := range data {
+		if len(d.Ext) == 0 {
+			continue
+		}
+		var ext struct {
+			SegTax   int    `json:"segtax"`
+			SegClass string `json:"segclass"`
+		}
+		if err := json.Unmarshal(d.Ext, &ext); err == nil && ext.SegTax == group.segtax && ext.SegClass == group.segclass {
+			return i
+		}
+	}
+	return -1
+}
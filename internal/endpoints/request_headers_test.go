@@ -0,0 +1,329 @@
+package endpoints
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+func TestParseTopicsHeader(t *testing.T) {
+	groups := parseTopicsHeader(`(1 2 3);v=chrome.1:1:2, (4);v=chrome.1:2:5, (9);v=chrome.1:7:1, ();p=P0000000000000000000000000000000`)
+
+	want := []topicsGroup{
+		{segtax: segtaxTopicsV1, segclass: "2", topics: []int{1, 2, 3}},
+		{segtax: segtaxTopicsV2, segclass: "5", topics: []int{4}},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("Expected %+v, got %+v", want, groups)
+	}
+}
+
+func TestParseTopicsHeader_Invalid(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"();p=P000",
+		"(1 2)",                   // No version
+		"(1 2);v=chrome.1",        // Malformed version
+		"(abc -1);v=chrome.1:1:2", // Invalid topic IDs
+		"1 2;v=chrome.1:1:2",      // Not an inner list
+	} {
+		if groups := parseTopicsHeader(value); len(groups) != 0 {
+			t.Errorf("parseTopicsHeader(%q) = %+v, want none", value, groups)
+		}
+	}
+}
+
+func TestParseTopicsHeader_Limit(t *testing.T) {
+	groups := parseTopicsHeader("(1 2 3 4 5 6 7 8);v=chrome.1:1:2, (9 10 11 12);v=chrome.1:2:2")
+	total := 0
+	for _, g := range groups {
+		total += len(g.topics)
+	}
+	if total != maxTopicsSegments {
+		t.Errorf("Expected %d topics, got %d", maxTopicsSegments, total)
+	}
+}
+
+func TestApplyTopicsHeader(t *testing.T) {
+	req := &openrtb.BidRequest{
+		Site: &openrtb.Site{Domain: "example.com"},
+		User: &openrtb.User{Data: []openrtb.Data{
+			{Name: "prebid", Segment: []openrtb.Segment{{ID: "1"}}, Ext: json.RawMessage(`{"segtax":600,"segclass":"2"}`)},
+		}},
+	}
+
+	if !applyTopicsHeader("(1 7);v=chrome.1:1:2, (4);v=chrome.1:2:2", req) {
+		t.Fatal("Expected topics to be applied")
+	}
+
+	data := req.User.Data
+	if len(data) != 2 {
+		t.Fatalf("Expected 2 user.data entries, got %+v", data)
+	}
+	// Topics merge into the existing entry for the same taxonomy and model without duplicates
+	if got := data[0].Segment; !reflect.DeepEqual(got, []openrtb.Segment{{ID: "1"}, {ID: "7"}}) {
+		t.Errorf("Expected merged segments 1 and 7, got %+v", got)
+	}
+	if data[1].Name != "example.com" || string(data[1].Ext) != `{"segclass":"2","segtax":601}` {
+		t.Errorf("Unexpected taxonomy v2 entry: %+v", data[1])
+	}
+
+	coppa := &openrtb.BidRequest{Regs: &openrtb.Regs{COPPA: 1}}
+	if applyTopicsHeader("(1);v=chrome.1:1:2", coppa) || coppa.User != nil {
+		t.Error("Topics should not be applied to COPPA requests")
+	}
+}
+
+func TestParseClientHints(t *testing.T) {
+	h := http.Header{}
+	h.Set("Sec-CH-UA", `"Chromium";v="119", "Google Chrome";v="119"`)
+	h.Set("Sec-CH-UA-Full-Version-List", `"Chromium";v="119.0.6045.199", "Not?A_Brand";v="24.0.0.0", "Google Chrome";v="119.0.6045.199"`)
+	h.Set("Sec-CH-UA-Platform", `"Windows"`)
+	h.Set("Sec-CH-UA-Platform-Version", `"15.0.0"`)
+	h.Set("Sec-CH-UA-Mobile", "?0")
+	h.Set("Sec-CH-UA-Arch", `"x86"`)
+	h.Set("Sec-CH-UA-Bitness", `"64"`)
+	h.Set("Sec-CH-UA-Model", `""`)
+
+	sua := parseClientHints(h)
+	if sua == nil {
+		t.Fatal("Expected device.sua")
+	}
+	wantBrowsers := []openrtb.BrandVersion{
+		{Brand: "Chromium", Version: []string{"119", "0", "6045", "199"}},
+		{Brand: "Not?A_Brand", Version: []string{"24", "0", "0", "0"}},
+		{Brand: "Google Chrome", Version: []string{"119", "0", "6045", "199"}},
+	}
+	if !reflect.DeepEqual(sua.Browsers, wantBrowsers) {
+		t.Errorf("Expected browsers %+v, got %+v", wantBrowsers, sua.Browsers)
+	}
+	if sua.Platform == nil || sua.Platform.Brand != "Windows" || !reflect.DeepEqual(sua.Platform.Version, []string{"15", "0", "0"}) {
+		t.Errorf("Unexpected platform: %+v", sua.Platform)
+	}
+	if sua.Mobile == nil || *sua.Mobile != 0 {
+		t.Errorf("Expected mobile=0, got %v", sua.Mobile)
+	}
+	if sua.Architecture != "x86" || sua.Bitness != "64" || sua.Model != "" {
+		t.Errorf("Unexpected hints: arch=%q bitness=%q model=%q", sua.Architecture, sua.Bitness, sua.Model)
+	}
+	if sua.Source != openrtb.UASourceHighEntropy {
+		t.Errorf("Expected high-entropy source, got %d", sua.Source)
+	}
+}
+
+func TestParseBrandList_GREASE(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []openrtb.BrandVersion
+	}{
+		{
+			name:  "semicolon in brand",
+			value: `"Not)A;Brand";v="8", "Chromium";v="138"`,
+			want: []openrtb.BrandVersion{
+				{Brand: "Not)A;Brand", Version: []string{"8"}},
+				{Brand: "Chromium", Version: []string{"138"}},
+			},
+		},
+		{
+			name:  "comma in brand",
+			value: `"Google Chrome";v="120", "Not,A Brand";v="99"`,
+			want: []openrtb.BrandVersion{
+				{Brand: "Google Chrome", Version: []string{"120"}},
+				{Brand: "Not,A Brand", Version: []string{"99"}},
+			},
+		},
+		{
+			name:  "escaped quote",
+			value: `"Not\"A\\Brand";v="24.0.0.0"`,
+			want:  []openrtb.BrandVersion{{Brand: `Not"A\Brand`, Version: []string{"24", "0", "0", "0"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseBrandList(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseClientHints_LowEntropy(t *testing.T) {
+	h := http.Header{}
+	h.Set("Sec-CH-UA", `"Chromium";v="119", "Google Chrome";v="119"`)
+	h.Set("Sec-CH-UA-Mobile", "?1")
+	h.Set("Sec-CH-UA-Platform", `"Android"`)
+
+	sua := parseClientHints(h)
+	if sua == nil || sua.Source != openrtb.UASourceLowEntropy {
+		t.Fatalf("Expected low-entropy device.sua, got %+v", sua)
+	}
+	if len(sua.Browsers) != 2 || sua.Browsers[1].Version[0] != "119" {
+		t.Errorf("Unexpected browsers: %+v", sua.Browsers)
+	}
+	if sua.Mobile == nil || *sua.Mobile != 1 {
+		t.Errorf("Expected mobile=1, got %v", sua.Mobile)
+	}
+
+	if parseClientHints(http.Header{}) != nil {
+		t.Error("Expected no device.sua without client hints")
+	}
+}
+
+func TestApplyRequestHeaders(t *testing.T) {
+	r := httptest.NewRequest("POST", "/openrtb2/auction", nil)
+	r.Header.Set("Referer", "https://example.com/article")
+	r.Header.Set("Sec-Browsing-Topics", "(5);v=chrome.1:1:2")
+	w := httptest.NewRecorder()
+
+	req := &openrtb.BidRequest{Site: &openrtb.Site{Domain: "example.com"}}
+	applyRequestHeaders(w, r, req)
+
+	if req.Site.Page != "https://example.com/article" {
+		t.Errorf("Expected site.page from Referer, got %q", req.Site.Page)
+	}
+	if req.User == nil || len(req.User.Data) != 1 {
+		t.Errorf("Expected Topics in user.data, got %+v", req.User)
+	}
+	if w.Header().Get("Observe-Browsing-Topics") != "?1" {
+		t.Error("Expected Observe-Browsing-Topics response header")
+	}
+}
+
+func TestApplyRequestHeaders_BodyWins(t *testing.T) {
+	r := httptest.NewRequest("POST", "/openrtb2/auction", nil)
+	r.Header.Set("Referer", "https://example.com/header")
+
+	req := &openrtb.BidRequest{Site: &openrtb.Site{Page: "https://example.com/body"}}
+	applyRequestHeaders(httptest.NewRecorder(), r, req)
+
+	if req.Site.Page != "https://example.com/body" {
+		t.Errorf("Body page should not be overwritten, got %q", req.Site.Page)
+	}
+}
+
+// runDeviceHeaders passes body through the DeviceHeaders middleware and returns what the next handler read
+func runDeviceHeaders(t *testing.T, d *DeviceHeaders, r *http.Request, next http.Handler) *openrtb.BidRequest {
+	t.Helper()
+	var got openrtb.BidRequest
+	if next == nil {
+		next = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	}
+	capture := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body) //nolint:errcheck // Test body
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Fatalf("Invalid body passed on: %v", err)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+	d.Middleware(capture).ServeHTTP(httptest.NewRecorder(), r)
+	return &got
+}
+
+// newTrustedResolver returns RateLimiter.ClientIP trusting 10.0.0.0/8 as proxies
+func newTrustedResolver(t *testing.T) func(*http.Request) string {
+	t.Helper()
+	proxies, _ := middleware.ParseTrustedProxies([]string{"10.0.0.0/8"}) //nolint:errcheck // Valid CIDR
+	rl := middleware.NewRateLimiter(&middleware.RateLimitConfig{
+		RequestsPerSecond: 10,
+		BurstSize:         10,
+		CleanupInterval:   time.Minute,
+		WindowSize:        time.Second,
+		TrustedProxies:    proxies,
+		TrustXFF:          true,
+	})
+	t.Cleanup(rl.Stop)
+	return rl.ClientIP
+}
+
+func TestDeviceHeaders(t *testing.T) {
+	d := NewDeviceHeaders(newTrustedResolver(t))
+
+	r := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(`{"id":"1","site":{"id":"s"},"ext":{"custom":12345678901234567890}}`))
+	r.RemoteAddr = "10.0.0.1:4321"
+	r.Header.Set("X-Forwarded-For", "198.51.100.1, 203.0.113.7")
+	r.Header.Set("User-Agent", "Mozilla/5.0 Test")
+	r.Header.Set("Sec-CH-UA", `"Chromium";v="119"`)
+
+	got := runDeviceHeaders(t, d, r, nil)
+
+	// The rightmost address not in TRUSTED_PROXIES is the client
+	if got.Device == nil || got.Device.UA != "Mozilla/5.0 Test" || got.Device.IP != "203.0.113.7" || got.Device.SUA == nil {
+		t.Errorf("Unexpected device: %+v", got.Device)
+	}
+	if string(got.Ext) != `{"custom":12345678901234567890}` {
+		t.Errorf("Expected ext preserved, got %s", got.Ext)
+	}
+}
+
+func TestDeviceHeaders_UntrustedForwardedFor(t *testing.T) {
+	d := NewDeviceHeaders(newTrustedResolver(t))
+
+	// A client connecting directly cannot choose device.ip through X-Forwarded-For
+	r := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(`{"id":"1"}`))
+	r.RemoteAddr = "198.51.100.9:4321"
+	r.Header.Set("X-Forwarded-For", "203.0.113.7")
+	if got := runDeviceHeaders(t, d, r, nil); got.Device == nil || got.Device.IP != "198.51.100.9" {
+		t.Errorf("Expected the connection address, got %+v", got.Device)
+	}
+
+	// An untrusted private peer is a proxy, not the user
+	r = httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(`{"id":"1"}`))
+	r.RemoteAddr = "192.168.1.5:4321"
+	r.Header.Set("X-Forwarded-For", "203.0.113.7")
+	if got := runDeviceHeaders(t, d, r, nil); got.Device != nil {
+		t.Errorf("Expected no device.ip from a private peer, got %+v", got.Device)
+	}
+
+	r = httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(`{"id":"1"}`))
+	r.RemoteAddr = "[2001:db8:1234:5678::1]:4321"
+	if got := runDeviceHeaders(t, NewDeviceHeaders(nil), r, nil); got.Device == nil || got.Device.IPv6 != "2001:db8:1234:5678::1" || got.Device.IP != "" {
+		t.Errorf("Expected IPv6 from RemoteAddr, got %+v", got.Device)
+	}
+}
+
+func TestDeviceHeaders_BodyWins(t *testing.T) {
+	r := httptest.NewRequest("POST", "/openrtb2/auction",
+		strings.NewReader(`{"id":"1","device":{"ua":"body-ua","ipv6":"2001:db8::1","sua":{"source":3}}}`))
+	r.RemoteAddr = "203.0.113.7:4321"
+	r.Header.Set("User-Agent", "header-ua")
+	r.Header.Set("Sec-CH-UA", `"Chromium";v="119"`)
+
+	got := runDeviceHeaders(t, NewDeviceHeaders(nil), r, nil)
+
+	if got.Device.UA != "body-ua" || got.Device.SUA.Source != openrtb.UASourceUAString || got.Device.IP != "" {
+		t.Errorf("Body values should not be overwritten: %+v", got.Device)
+	}
+}
+
+func TestDeviceHeaders_PrivacyAnonymizesFilledIP(t *testing.T) {
+	// Header-derived IPs reach the privacy middleware like body IPs and are anonymized under GDPR
+	var seen openrtb.BidRequest
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&seen) //nolint:errcheck // Checked via result
+	})
+	privacy := middleware.NewPrivacyMiddleware(middleware.PrivacyConfig{
+		EnforceGDPR: true,
+		AnonymizeIP: true,
+	})
+
+	body := `{"id":"1","site":{"id":"s"},"regs":{"gdpr":1},"user":{"consent":"` + testTCFConsent + `"}}`
+	r := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(body))
+	r.RemoteAddr = "203.0.113.7:4321"
+	runDeviceHeaders(t, NewDeviceHeaders(nil), r, privacy(handler))
+
+	if seen.Device == nil || seen.Device.IP != "203.0.113.0" {
+		t.Errorf("Expected anonymized IP 203.0.113.0, got %+v", seen.Device)
+	}
+}
@@ -1,16 +1,28 @@
 package endpoints
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
 	"github.com/thenexusengine/tne_springwire/internal/usersync"
 	"github.com/thenexusengine/tne_springwire/pkg/logger"
 )
 
+// uidStoreWriteTimeout bounds UID store writes from /setuid and /optout
+const uidStoreWriteTimeout = 500 * time.Millisecond
+
 // SetUIDHandler handles the /setuid endpoint for storing bidder user IDs
 type SetUIDHandler struct {
+	mu           sync.RWMutex
 	validBidders map[string]bool
+	userMacros   map[string]bool // Unsubstituted user ID macros, treated as empty UIDs
+	uidStore     *usersync.Store
+	gvlVendorIDs GVLVendorIDFunc
 }
 
 // NewSetUIDHandler creates a new setuid handler
@@ -21,6 +33,7 @@ func NewSetUIDHandler(validBidders []string) *SetUIDHandler {
 	}
 	return &SetUIDHandler{
 		validBidders: bidderMap,
+		userMacros:   map[string]bool{usersync.DefaultUserMacro: true},
 	}
 }
 
@@ -30,6 +43,9 @@ func NewSetUIDHandler(validBidders []string) *SetUIDHandler {
 //   - uid: the user ID from the bidder
 //   - gdpr: GDPR applies (0/1)
 //   - gdpr_consent: TCF consent string
+//   - gpp, gpp_sid: GPP string and applicable section IDs
+//   - us_privacy: US Privacy (CCPA) string
+//   - st: optional UID store token from the auction response (ext.prebid.uidstoretoken)
 func (h *SetUIDHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Parse query params
 	query := r.URL.Query()
@@ -43,7 +59,7 @@ func (h *SetUIDHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	bidderLower := strings.ToLower(bidder)
-	if !h.validBidders[bidderLower] {
+	if !h.isValidBidder(bidderLower) {
 		logger.Log.Warn().Str("bidder", bidder).Msg("Unknown bidder in setuid request")
 		// Still process - bidder might be dynamically registered
 	}
@@ -57,8 +73,16 @@ func (h *SetUIDHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Refuse to store anything without consent to sync with this bidder
+	if allowed, reason := h.checkConsent(r, bidderLower); !allowed {
+		logger.Log.Debug().Str("bidder", bidder).Str("reason", reason).Msg("Rejected setuid without consent")
+		http.Error(w, "consent required to sync: "+reason, http.StatusUnavailableForLegalReasons)
+		return
+	}
+
 	// Handle UID
-	if uid == "" || uid == "$UID" || uid == "0" {
+	validUID := uid != "" && !h.isUserMacro(uid) && uid != "0"
+	if !validUID {
 		// Bidder sent empty/invalid UID - delete any existing
 		cookie.DeleteUID(bidderLower)
 		logger.Log.Debug().Str("bidder", bidder).Msg("Deleted UID (empty value received)")
@@ -72,6 +96,10 @@ func (h *SetUIDHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			Msg("Stored UID")
 	}
 
+	if h.uidStore.Enabled() {
+		h.writeUIDStore(r, bidderLower, uid, validUID)
+	}
+
 	// Set the updated cookie
 	domain := h.getCookieDomain(r)
 	if httpCookie, err := cookie.ToHTTPCookie(domain); err == nil {
@@ -84,6 +112,92 @@ func (h *SetUIDHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.respondWithPixel(w)
 }
 
+// checkConsent evaluates the TCF, GPP and US Privacy query params for the bidder
+func (h *SetUIDHandler) checkConsent(r *http.Request, bidder string) (bool, string) {
+	query := r.URL.Query()
+	consent := middleware.EvaluateSyncConsent(middleware.SyncPrivacy{
+		GDPR:        query.Get("gdpr") == "1",
+		GDPRConsent: query.Get("gdpr_consent"),
+		USPrivacy:   query.Get("us_privacy"),
+		GPP:         query.Get("gpp"),
+		GPPSID:      query.Get("gpp_sid"),
+	})
+
+	gvlID := 0
+	if h.gvlVendorIDs != nil {
+		gvlID = h.gvlVendorIDs(bidder)
+	}
+	return consent.AllowsSync(gvlID)
+}
+
+// SetGVLVendorIDs sets the lookup used for TCF vendor consent checks
+func (h *SetUIDHandler) SetGVLVendorIDs(lookup GVLVendorIDFunc) {
+	h.gvlVendorIDs = lookup
+}
+
+// writeUIDStore mirrors the UID into the server-side store for every first-party ID on the request
+func (h *SetUIDHandler) writeUIDStore(r *http.Request, bidder, uid string, validUID bool) {
+	ctx, cancel := context.WithTimeout(r.Context(), uidStoreWriteTimeout)
+	defer cancel()
+
+	for _, key := range uidStoreKeysFromQuery(r) {
+		var err error
+		if validUID {
+			err = h.uidStore.SetUID(ctx, key, bidder, uid)
+		} else {
+			err = h.uidStore.DeleteUID(ctx, key, bidder)
+		}
+		if errors.Is(err, usersync.ErrOptedOut) {
+			logger.Log.Debug().Str("bidder", bidder).Str("id_type", string(key.Type)).Msg("Skipped UID store write for opted-out identity")
+			return
+		}
+		if err != nil {
+			logger.Log.Warn().Err(err).Str("bidder", bidder).Str("id_type", string(key.Type)).Msg("Failed to write UID store")
+		}
+	}
+}
+
+// SetUIDStore sets the server-side UID store written by /setuid
+func (h *SetUIDHandler) SetUIDStore(store *usersync.Store) {
+	h.uidStore = store
+}
+
+// uidStoreKeysFromQuery returns UID store keys from /setuid and /optout requests
+// Only identities the client was issued are trusted: the pubcid cookie and the keys of a
+// signed st token. Raw ifa, pubcid and ppuid query params are ignored, as anyone could
+// otherwise write UIDs or opt-outs against another user's identity.
+func uidStoreKeysFromQuery(r *http.Request) []usersync.StoreKey {
+	query := r.URL.Query()
+	var keys []usersync.StoreKey
+
+	if key, ok := pubCIDCookieKey(r); ok {
+		keys = append(keys, key)
+	}
+
+	if token := query.Get("st"); token != "" {
+		tokenKeys := usersync.VerifyStoreToken(token)
+		if tokenKeys == nil {
+			logger.Log.Debug().Msg("Ignored invalid or expired UID store token")
+		}
+		keys = append(keys, tokenKeys...)
+	}
+
+	if query.Has("ifa") || query.Has("pubcid") || query.Has("ppuid") {
+		logger.Log.Debug().Msg("Ignored unsigned UID store identities in query")
+	}
+
+	return keys
+}
+
+// pubCIDCookieKey returns the UID store key of the request's _pubcid cookie
+func pubCIDCookieKey(r *http.Request) (usersync.StoreKey, bool) {
+	c, err := r.Cookie(usersync.PubCIDCookieName)
+	if err != nil || !usersync.IsValidSharedID(c.Value) {
+		return usersync.StoreKey{}, false
+	}
+	return usersync.PubCIDKey(c.Value)
+}
+
 // getCookieDomain extracts the domain for cookies
 func (h *SetUIDHandler) getCookieDomain(r *http.Request) string {
 	host := r.Host
@@ -116,17 +230,64 @@ func (h *SetUIDHandler) respondWithPixel(w http.ResponseWriter) {
 
 // AddBidder adds a valid bidder code
 func (h *SetUIDHandler) AddBidder(bidder string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	h.validBidders[strings.ToLower(bidder)] = true
 }
 
+// SetSyncers replaces the valid bidders and user ID macros with the given syncer definitions
+// Called when syncer definitions are reloaded so new bidders are accepted without a restart
+func (h *SetUIDHandler) SetSyncers(configs map[string]usersync.SyncerConfig) {
+	bidders := make(map[string]bool, len(configs))
+	macros := map[string]bool{usersync.DefaultUserMacro: true}
+	for code, config := range configs {
+		bidders[strings.ToLower(code)] = true
+		if config.UserMacro != "" {
+			macros[config.UserMacro] = true
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.validBidders = bidders
+	h.userMacros = macros
+}
+
+// isValidBidder returns true if the bidder has a known syncer
+func (h *SetUIDHandler) isValidBidder(bidder string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.validBidders[bidder]
+}
+
+// isUserMacro returns true if the UID is an unsubstituted user ID macro
+func (h *SetUIDHandler) isUserMacro(uid string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.userMacros[uid]
+}
+
 // OptOutHandler handles opt-out requests
-type OptOutHandler struct{}
+type OptOutHandler struct {
+	uidStore *usersync.Store
+	sharedID *usersync.SharedIDMinter
+}
 
 // NewOptOutHandler creates a new opt-out handler
 func NewOptOutHandler() *OptOutHandler {
 	return &OptOutHandler{}
 }
 
+// SetUIDStore sets the server-side UID store that receives opt-out tombstones
+func (h *OptOutHandler) SetUIDStore(store *usersync.Store) {
+	h.uidStore = store
+}
+
+// SetSharedID sets the SharedID minter whose pubcid cookie is cleared on opt-out
+func (h *OptOutHandler) SetSharedID(minter *usersync.SharedIDMinter) {
+	h.sharedID = minter
+}
+
 // ServeHTTP handles the /optout endpoint
 func (h *OptOutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Parse existing cookie
@@ -135,6 +296,17 @@ func (h *OptOutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Set opt-out
 	cookie.SetOptOut(true)
 
+	// Tombstone server-side UIDs so app and CTV identities stay opted out
+	if h.uidStore.Enabled() {
+		ctx, cancel := context.WithTimeout(r.Context(), uidStoreWriteTimeout)
+		for _, key := range uidStoreKeysFromQuery(r) {
+			if err := h.uidStore.OptOut(ctx, key); err != nil {
+				logger.Log.Warn().Err(err).Str("id_type", string(key.Type)).Msg("Failed to write UID store opt-out")
+			}
+		}
+		cancel()
+	}
+
 	// Set the updated cookie
 	domain := r.Host
 	if idx := strings.Index(domain, ":"); idx != -1 {
@@ -145,6 +317,13 @@ func (h *OptOutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.SetCookie(w, httpCookie)
 	}
 
+	// Delete the SharedID and stop it being minted again
+	if h.sharedID.Enabled() {
+		for _, c := range h.sharedID.OptOutCookies(r) {
+			http.SetCookie(w, c)
+		}
+	}
+
 	// Return success page
 	w.Header().Set("Content-Type", "text/html")
 	w.WriteHeader(http.StatusOK)
@@ -1,11 +1,13 @@
 package endpoints
 
 import (
+	"context"
 	"encoding/base64"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/thenexusengine/tne_springwire/internal/usersync"
 )
@@ -93,6 +95,65 @@ func TestSetUIDHandler_ValidUID(t *testing.T) {
 	}
 }
 
+func TestSetUIDHandler_ConsentRequired(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{"gdpr without consent", "gdpr=1", http.StatusUnavailableForLegalReasons},
+		{"gdpr invalid consent", "gdpr=1&gdpr_consent=consent-string", http.StatusUnavailableForLegalReasons},
+		{"gdpr without purpose 1", "gdpr=1&gdpr_consent=" + testTCFConsentNoPurpose, http.StatusUnavailableForLegalReasons},
+		{"gdpr without vendor consent", "gdpr=1&gdpr_consent=" + testTCFConsent + "&bidder=rubicon", http.StatusUnavailableForLegalReasons},
+		{"us privacy opt-out", "us_privacy=1YYN", http.StatusUnavailableForLegalReasons},
+		{"gpp us opt-out", "gpp=" + testGPPUSNatOptOut + "&gpp_sid=7", http.StatusUnavailableForLegalReasons},
+		{"gdpr with consent", "gdpr=1&gdpr_consent=" + testTCFConsent, http.StatusOK},
+		{"gpp tcf consent", "gpp=" + testGPPWithTCF + "&gpp_sid=2", http.StatusOK},
+		{"no regulation", "gdpr=0", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := setupTestUIDStore(t)
+			handler := NewSetUIDHandler([]string{"appnexus", "rubicon"})
+			handler.SetUIDStore(store)
+			handler.SetGVLVendorIDs(func(bidder string) int {
+				return map[string]int{"appnexus": 32, "rubicon": 52}[bidder]
+			})
+			key, _ := usersync.IFAKey("ifa-consent")
+			token := issueTestStoreToken(t, key)
+
+			query := "bidder=appnexus&uid=user123&st=" + token + "&" + tt.query
+			if strings.Contains(tt.query, "bidder=") {
+				query = "uid=user123&st=" + token + "&" + tt.query
+			}
+			req := httptest.NewRequest("GET", "/setuid?"+query, nil)
+			req.Host = "example.com"
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+
+			stored, err := store.Get(context.Background(), key)
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if len(w.Result().Cookies()) != 0 {
+					t.Error("Expected no cookie without consent")
+				}
+				if len(stored.UIDs) != 0 {
+					t.Errorf("Expected no UID store write without consent, got %v", stored.UIDs)
+				}
+			} else if len(stored.UIDs) != 1 {
+				t.Errorf("Expected UID store write with consent, got %v", stored.UIDs)
+			}
+		})
+	}
+}
+
 func TestSetUIDHandler_EmptyUID(t *testing.T) {
 	handler := NewSetUIDHandler([]string{"appnexus"})
 
@@ -442,3 +503,173 @@ func TestOptOutHandler_DomainWithPort(t *testing.T) {
 		t.Error("Expected domain without port in cookie")
 	}
 }
+
+// issueTestStoreToken signs store keys with a test signing key installed for the test
+func issueTestStoreToken(t *testing.T, keys ...usersync.StoreKey) string {
+	t.Helper()
+	cfg := &usersync.SecurityConfig{
+		SigningKeys: []usersync.SigningKey{{ID: "t1", Secret: []byte("0123456789abcdef0123456789abcdef")}},
+	}
+	if err := usersync.ConfigureSecurity(cfg, nil); err != nil {
+		t.Fatalf("ConfigureSecurity failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = usersync.ConfigureSecurity(nil, nil) //nolint:errcheck // nil config cannot fail
+	})
+	return usersync.IssueStoreToken(keys)
+}
+
+// setupTestUIDStore creates a UID store backed by miniredis
+func setupTestUIDStore(t *testing.T) *usersync.Store {
+	t.Helper()
+	client, _ := setupTestRedisForPublisher(t)
+	t.Cleanup(func() { client.Close() })
+	return usersync.NewStore(client, &usersync.StoreConfig{
+		Enabled:       true,
+		TTL:           usersync.DefaultTTL,
+		OptOutTTL:     usersync.DefaultTTL,
+		LookupTimeout: time.Second,
+	})
+}
+
+func TestSetUIDHandler_WritesUIDStore(t *testing.T) {
+	store := setupTestUIDStore(t)
+	handler := NewSetUIDHandler([]string{"appnexus"})
+	handler.SetUIDStore(store)
+
+	ifaKey, _ := usersync.IFAKey("AAAA-1111")
+	pubcidKey, _ := usersync.PubCIDKey("pubcid-1")
+	ppuidKey, _ := usersync.PPUIDKey("pub1", "user-1")
+	token := issueTestStoreToken(t, ifaKey, ppuidKey)
+
+	req := httptest.NewRequest("GET", "/setuid?bidder=appnexus&uid=app-uid&st="+token, nil)
+	req.AddCookie(&http.Cookie{Name: usersync.PubCIDCookieName, Value: "pubcid-1"})
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	ctx := context.Background()
+	for _, key := range []usersync.StoreKey{ifaKey, pubcidKey, ppuidKey} {
+		stored, err := store.Get(ctx, key)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if stored.UIDs["appnexus"] != "app-uid" {
+			t.Errorf("Expected app-uid stored under %s, got %v", key.Type, stored.UIDs)
+		}
+	}
+}
+
+func TestSetUIDHandler_UIDStoreDeleteAndOptOut(t *testing.T) {
+	store := setupTestUIDStore(t)
+	ctx := context.Background()
+	key, _ := usersync.IFAKey("ifa-1")
+	_ = store.SetUID(ctx, key, "appnexus", "old-uid") //nolint:errcheck // Test setup
+	token := issueTestStoreToken(t, key)
+
+	handler := NewSetUIDHandler([]string{"appnexus"})
+	handler.SetUIDStore(store)
+
+	// Empty UID deletes the stored UID
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/setuid?bidder=appnexus&uid=&st="+token, nil))
+	stored, _ := store.Get(ctx, key) //nolint:errcheck // Checked via result
+	if _, ok := stored.UIDs["appnexus"]; ok {
+		t.Error("Expected stored UID to be deleted")
+	}
+
+	// Opted-out identities are not written
+	optOut := NewOptOutHandler()
+	optOut.SetUIDStore(store)
+	optOut.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/optout?st="+token, nil))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/setuid?bidder=appnexus&uid=new-uid&st="+token, nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected pixel response, got %d", w.Code)
+	}
+
+	stored, _ = store.Get(ctx, key) //nolint:errcheck // Checked via result
+	if !stored.OptOut || len(stored.UIDs) != 0 {
+		t.Errorf("Expected opted-out identity without UIDs, got %+v", stored)
+	}
+}
+
+func TestUIDStoreKeysFromQuery(t *testing.T) {
+	ifaKey, _ := usersync.IFAKey("ifa-1")
+	token := issueTestStoreToken(t, ifaKey)
+
+	req := httptest.NewRequest("GET", "/setuid?ifa=other-ifa&pubcid=q-pubcid&account=pub1&ppuid=user-1&st="+token, nil)
+	req.AddCookie(&http.Cookie{Name: usersync.PubCIDCookieName, Value: "cookie-pubcid"})
+
+	keys := uidStoreKeysFromQuery(req)
+
+	// Raw query identities are ignored; only the cookie and the token's keys count
+	expected := []usersync.StoreKey{{Type: usersync.IDTypePubCID, Value: "cookie-pubcid"}, ifaKey}
+	if len(keys) != len(expected) {
+		t.Fatalf("Expected %d keys, got %+v", len(expected), keys)
+	}
+	for i := range expected {
+		if keys[i] != expected[i] {
+			t.Errorf("Key %d: expected %+v, got %+v", i, expected[i], keys[i])
+		}
+	}
+
+	tampered := httptest.NewRequest("GET", "/setuid?st="+strings.Replace(token, ".", ".x", 1), nil)
+	if keys := uidStoreKeysFromQuery(tampered); len(keys) != 0 {
+		t.Errorf("Expected a tampered token to be ignored, got %+v", keys)
+	}
+}
+
+func TestSetUIDHandler_RejectsForgedIdentity(t *testing.T) {
+	store := setupTestUIDStore(t)
+	ctx := context.Background()
+	victim, _ := usersync.IFAKey("victim-ifa")
+	_ = store.SetUID(ctx, victim, "appnexus", "real-uid") //nolint:errcheck // Test setup
+
+	handler := NewSetUIDHandler([]string{"appnexus"})
+	handler.SetUIDStore(store)
+	optOut := NewOptOutHandler()
+	optOut.SetUIDStore(store)
+
+	// A token issued for another identity does not authorize writes to the victim's
+	other, _ := usersync.IFAKey("attacker-ifa")
+	token := issueTestStoreToken(t, other)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/setuid?bidder=appnexus&uid=forged&ifa=victim-ifa&st="+token, nil))
+	optOut.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/optout?ifa=victim-ifa", nil))
+
+	stored, err := store.Get(ctx, victim)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if stored.OptOut || stored.UIDs["appnexus"] != "real-uid" {
+		t.Errorf("Expected forged ifa writes refused, got %+v", stored)
+	}
+}
+
+func TestOptOutHandler_ClearsSharedID(t *testing.T) {
+	handler := NewOptOutHandler()
+	handler.SetSharedID(usersync.NewSharedIDMinter(&usersync.SharedIDConfig{Enabled: true}))
+
+	req := httptest.NewRequest("GET", "/optout", nil)
+	req.AddCookie(&http.Cookie{Name: usersync.PubCIDCookieName, Value: "existing-pubcid"})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	var deleted, optedOut bool
+	for _, c := range w.Result().Cookies() {
+		switch c.Name {
+		case usersync.PubCIDCookieName:
+			deleted = c.MaxAge < 0 && c.Value == ""
+		case usersync.SharedIDOptOutCookieName:
+			optedOut = c.Value == "1"
+		}
+	}
+	if !deleted || !optedOut {
+		t.Errorf("Expected pubcid cookie deleted (%v) and opt-out cookie set (%v)", deleted, optedOut)
+	}
+}
@@ -0,0 +1,165 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/usersync"
+	"github.com/thenexusengine/tne_springwire/pkg/logger"
+)
+
+// syncerReloadTimeout bounds a single syncer reload
+const syncerReloadTimeout = 5 * time.Second
+
+// SyncerSource loads user sync definitions keyed by bidder code (e.g. from the bidders table)
+// Definitions with Enabled false switch off the built-in syncer for that bidder.
+type SyncerSource interface {
+	ListSyncerConfigs(ctx context.Context) (map[string]usersync.SyncerConfig, error)
+}
+
+// SyncerReloader loads syncer definitions into the /cookie_sync and /setuid handlers
+// It reloads on an interval and on POST /admin/syncers/reload
+type SyncerReloader struct {
+	source     SyncerSource
+	cookieSync *CookieSyncHandler
+	setUID     *SetUIDHandler
+	interval   time.Duration
+
+	mu       sync.Mutex
+	lastLoad time.Time
+	lastErr  error
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// DefaultSyncerReloadInterval returns the reload interval from SYNCER_RELOAD_INTERVAL (default: 1m, 0 disables)
+func DefaultSyncerReloadInterval() time.Duration {
+	value := os.Getenv("SYNCER_RELOAD_INTERVAL")
+	if value == "" {
+		return time.Minute
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return time.Minute
+	}
+	return d
+}
+
+// NewSyncerReloader creates a syncer reloader
+func NewSyncerReloader(source SyncerSource, cookieSync *CookieSyncHandler, setUID *SetUIDHandler, interval time.Duration) *SyncerReloader {
+	return &SyncerReloader{
+		source:     source,
+		cookieSync: cookieSync,
+		setUID:     setUID,
+		interval:   interval,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Reload loads syncer definitions and applies them to both handlers
+// On error the previously loaded definitions stay active
+func (r *SyncerReloader) Reload(ctx context.Context) (int, error) {
+	configs, err := r.source.ListSyncerConfigs(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastErr = err
+	if err != nil {
+		return 0, err
+	}
+
+	r.cookieSync.ReloadSyncers(configs)
+	if r.setUID != nil {
+		r.setUID.SetSyncers(r.cookieSync.SyncerConfigs())
+	}
+	r.lastLoad = time.Now()
+	return len(configs), nil
+}
+
+// Start launches periodic reloads; a zero interval disables them
+func (r *SyncerReloader) Start() {
+	if r.interval <= 0 {
+		return
+	}
+	go r.run()
+}
+
+// run reloads syncers on each tick until stopped
+func (r *SyncerReloader) run() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), syncerReloadTimeout)
+			if _, err := r.Reload(ctx); err != nil {
+				logger.Log.Warn().Err(err).Msg("Failed to reload syncer definitions, keeping previous")
+			}
+			cancel()
+		case <-r.stopCh:
+			return
+		}
+	}
+}
+
+// Stop stops periodic reloads
+func (r *SyncerReloader) Stop() {
+	r.stopOnce.Do(func() { close(r.stopCh) })
+}
+
+// ServeHTTP handles POST /admin/syncers/reload and GET for the reload status
+func (r *SyncerReloader) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		r.mu.Lock()
+		status := map[string]interface{}{
+			"syncers": len(r.cookieSync.ListBidders()),
+		}
+		if !r.lastLoad.IsZero() {
+			status["last_reload"] = r.lastLoad.UTC().Format(time.RFC3339)
+		}
+		if r.lastErr != nil {
+			status["last_error"] = r.lastErr.Error()
+		}
+		r.mu.Unlock()
+		r.sendJSON(w, http.StatusOK, status)
+
+	case http.MethodPost:
+		ctx, cancel := context.WithTimeout(req.Context(), syncerReloadTimeout)
+		defer cancel()
+
+		loaded, err := r.Reload(ctx)
+		if err != nil {
+			logger.Log.Error().Err(err).Msg("Syncer reload failed")
+			r.sendJSON(w, http.StatusInternalServerError, ErrorResponse{
+				Error:   "reload_failed",
+				Message: "Failed to reload syncer definitions",
+			})
+			return
+		}
+
+		logger.Log.Info().Int("loaded", loaded).Msg("Syncer definitions reloaded")
+		r.sendJSON(w, http.StatusOK, map[string]interface{}{
+			"status":  "reloaded",
+			"loaded":  loaded,
+			"syncers": len(r.cookieSync.ListBidders()),
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// sendJSON sends a JSON response
+func (r *SyncerReloader) sendJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to encode JSON response")
+	}
+}
@@ -0,0 +1,234 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/usersync"
+)
+
+// mockSyncerSource returns fixed syncer definitions or an error
+type mockSyncerSource struct {
+	configs map[string]usersync.SyncerConfig
+	err     error
+}
+
+func (m *mockSyncerSource) ListSyncerConfigs(ctx context.Context) (map[string]usersync.SyncerConfig, error) {
+	return m.configs, m.err
+}
+
+func newTestSyncerReloader(source *mockSyncerSource) (*SyncerReloader, *CookieSyncHandler, *SetUIDHandler) {
+	cookieSync := createTestHandler()
+	setUID := NewSetUIDHandler(cookieSync.ListBidders())
+	return NewSyncerReloader(source, cookieSync, setUID, 0), cookieSync, setUID
+}
+
+func TestSyncerReloader_Reload(t *testing.T) {
+	source := &mockSyncerSource{configs: map[string]usersync.SyncerConfig{
+		"newssp": {
+			BidderCode:      "newssp",
+			RedirectSyncURL: "https://sync.newssp.com/?r={{redirect_url}}",
+			UserMacro:       "[NEWSSP_UID]",
+			GVLVendorID:     999,
+			Enabled:         true,
+		},
+		"appnexus": {
+			BidderCode:      "appnexus",
+			RedirectSyncURL: "https://db.adnxs.com/getuid?{{redirect_url}}",
+			Enabled:         true,
+		},
+	}}
+	reloader, cookieSync, setUID := newTestSyncerReloader(source)
+
+	loaded, err := reloader.Reload(context.Background())
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if loaded != 2 {
+		t.Errorf("Expected 2 loaded syncers, got %d", loaded)
+	}
+
+	// Database definitions add new bidders and override built-in ones
+	configs := cookieSync.SyncerConfigs()
+	if _, ok := configs["newssp"]; !ok {
+		t.Error("Expected newssp syncer after reload")
+	}
+	if configs["appnexus"].RedirectSyncURL != "https://db.adnxs.com/getuid?{{redirect_url}}" {
+		t.Errorf("Expected database URL to override built-in, got %s", configs["appnexus"].RedirectSyncURL)
+	}
+	if _, ok := configs["rubicon"]; !ok {
+		t.Error("Built-in syncers without a database definition should be kept")
+	}
+	if cookieSync.GVLVendorID("newssp") != 999 {
+		t.Errorf("Expected GVL ID from syncer definition, got %d", cookieSync.GVLVendorID("newssp"))
+	}
+
+	// /setuid accepts the new bidder and treats its unsubstituted macro as empty
+	if !setUID.isValidBidder("newssp") {
+		t.Error("Expected /setuid to accept newssp after reload")
+	}
+	if !setUID.isUserMacro("[NEWSSP_UID]") || !setUID.isUserMacro(usersync.DefaultUserMacro) {
+		t.Error("Expected custom and default user macros to be recognized")
+	}
+}
+
+func TestSyncerReloader_DisabledRowRemovesBuiltIn(t *testing.T) {
+	source := &mockSyncerSource{configs: map[string]usersync.SyncerConfig{
+		"appnexus": {BidderCode: "appnexus", GVLVendorID: 32, Enabled: false},
+	}}
+	reloader, cookieSync, setUID := newTestSyncerReloader(source)
+
+	if _, err := reloader.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if _, ok := cookieSync.getSyncer("appnexus"); ok {
+		t.Error("Expected the built-in appnexus syncer removed for a disabled row")
+	}
+	if setUID.isValidBidder("appnexus") {
+		t.Error("Expected /setuid to reject a bidder disabled in the database")
+	}
+	if _, ok := cookieSync.getSyncer("rubicon"); !ok {
+		t.Error("Built-in syncers without a database row should be kept")
+	}
+
+	// Re-enabling the row brings the built-in syncer back on the next reload
+	delete(source.configs, "appnexus")
+	if _, err := reloader.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if _, ok := cookieSync.getSyncer("appnexus"); !ok {
+		t.Error("Expected the built-in appnexus syncer back")
+	}
+}
+
+func TestSyncerReloader_ReloadErrorKeepsPrevious(t *testing.T) {
+	source := &mockSyncerSource{configs: map[string]usersync.SyncerConfig{
+		"newssp": {BidderCode: "newssp", RedirectSyncURL: "https://sync.newssp.com/", Enabled: true},
+	}}
+	reloader, cookieSync, _ := newTestSyncerReloader(source)
+
+	if _, err := reloader.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	source.err = errors.New("database unavailable")
+	if _, err := reloader.Reload(context.Background()); err == nil {
+		t.Fatal("Expected reload error")
+	}
+	if _, ok := cookieSync.getSyncer("newssp"); !ok {
+		t.Error("Failed reload should keep previously loaded syncers")
+	}
+}
+
+func TestSyncerReloader_ServeHTTP(t *testing.T) {
+	source := &mockSyncerSource{configs: map[string]usersync.SyncerConfig{
+		"newssp": {BidderCode: "newssp", RedirectSyncURL: "https://sync.newssp.com/", Enabled: true},
+	}}
+	reloader, _, _ := newTestSyncerReloader(source)
+
+	w := httptest.NewRecorder()
+	reloader.ServeHTTP(w, httptest.NewRequest("POST", "/admin/syncers/reload", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var resp map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp["loaded"] != float64(1) || resp["syncers"] != float64(6) {
+		t.Errorf("Unexpected reload response: %v", resp)
+	}
+
+	w = httptest.NewRecorder()
+	reloader.ServeHTTP(w, httptest.NewRequest("GET", "/admin/syncers/reload", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "last_reload") {
+		t.Errorf("Expected reload status, got %d %s", w.Code, w.Body.String())
+	}
+
+	source.err = errors.New("database unavailable")
+	w = httptest.NewRecorder()
+	reloader.ServeHTTP(w, httptest.NewRequest("POST", "/admin/syncers/reload", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500 on reload failure, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	reloader.ServeHTTP(w, httptest.NewRequest("DELETE", "/admin/syncers/reload", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", w.Code)
+	}
+}
+
+func TestSyncerReloader_Periodic(t *testing.T) {
+	source := &mockSyncerSource{configs: map[string]usersync.SyncerConfig{
+		"newssp": {BidderCode: "newssp", RedirectSyncURL: "https://sync.newssp.com/", Enabled: true},
+	}}
+	cookieSync := createTestHandler()
+	reloader := NewSyncerReloader(source, cookieSync, nil, 10*time.Millisecond)
+	reloader.Start()
+	defer reloader.Stop()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok := cookieSync.getSyncer("newssp"); ok {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Error("Expected periodic reload to load newssp")
+}
+
+func TestSetUIDHandler_ReloadedUserMacro(t *testing.T) {
+	handler := NewSetUIDHandler(nil)
+	handler.SetSyncers(map[string]usersync.SyncerConfig{
+		"newssp": {BidderCode: "newssp", UserMacro: "[NEWSSP_UID]"},
+	})
+
+	cookie := usersync.NewCookie()
+	cookie.SetUID("newssp", "old-uid")
+	httpCookie, err := cookie.ToHTTPCookie("example.com")
+	if err != nil {
+		t.Fatalf("Failed to create cookie: %v", err)
+	}
+
+	// An unsubstituted macro deletes the UID instead of storing it
+	req := httptest.NewRequest("GET", "/setuid?bidder=newssp&uid=[NEWSSP_UID]", nil)
+	req.AddCookie(httpCookie)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	result := w.Result()
+	defer result.Body.Close()
+	for _, c := range result.Cookies() {
+		if c.Name == usersync.CookieName {
+			parsedReq := httptest.NewRequest("GET", "/", nil)
+			parsedReq.AddCookie(c)
+			if uid := usersync.ParseCookie(parsedReq).GetUID("newssp"); uid != "" {
+				t.Errorf("Expected UID to be deleted, got %q", uid)
+			}
+			return
+		}
+	}
+	t.Fatal("Expected uids cookie to be set")
+}
+
+func TestDefaultSyncerReloadInterval(t *testing.T) {
+	t.Setenv("SYNCER_RELOAD_INTERVAL", "")
+	if got := DefaultSyncerReloadInterval(); got != time.Minute {
+		t.Errorf("Expected default 1m, got %v", got)
+	}
+	t.Setenv("SYNCER_RELOAD_INTERVAL", "0")
+	if got := DefaultSyncerReloadInterval(); got != 0 {
+		t.Errorf("Expected 0 to disable reloads, got %v", got)
+	}
+	t.Setenv("SYNCER_RELOAD_INTERVAL", "bogus")
+	if got := DefaultSyncerReloadInterval(); got != time.Minute {
+		t.Errorf("Expected invalid value to fall back to 1m, got %v", got)
+	}
+}
@@ -88,6 +88,9 @@ func TestExchange_CircuitBreakerOpensAfterFailures(t *testing.T) {
 			[]string{"failing_bidder"},
 			100*time.Millisecond,
 			fpd.BidderFPD{},
+			nil,
+			nil,
+			nil,
 		)
 	}
 
@@ -133,6 +136,9 @@ func TestExchange_CircuitBreakerSkipsBidderWhenOpen(t *testing.T) {
 		[]string{"test_bidder"},
 		100*time.Millisecond,
 		fpd.BidderFPD{},
+		nil,
+		nil,
+		nil,
 	)
 
 	// Verify result indicates circuit breaker
@@ -198,6 +204,9 @@ func TestExchange_CircuitBreakerRecordsSuccess(t *testing.T) {
 		[]string{"success_bidder"},
 		100*time.Millisecond,
 		fpd.BidderFPD{},
+		nil,
+		nil,
+		nil,
 	)
 
 	// Verify success was recorded
@@ -250,6 +259,9 @@ func TestExchange_CircuitBreakerRecordsFailure(t *testing.T) {
 		[]string{"failing_bidder"},
 		100*time.Millisecond,
 		fpd.BidderFPD{},
+		nil,
+		nil,
+		nil,
 	)
 
 	// Verify failure was recorded
@@ -330,6 +342,9 @@ func TestExchange_CircuitBreakerConcurrentAccess(t *testing.T) {
 				[]string{"concurrent_bidder"},
 				100*time.Millisecond,
 				fpd.BidderFPD{},
+				nil,
+				nil,
+				nil,
 			)
 		}()
 	}
@@ -388,5 +403,8 @@ func TestExchange_CircuitBreakerNoNilPanic(t *testing.T) {
 		[]string{"test_bidder"},
 		100*time.Millisecond,
 		fpd.BidderFPD{},
+		nil,
+		nil,
+		nil,
 	)
 }
@@ -0,0 +1,105 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// DSA (EU Digital Services Act) transparency levels from regs.ext.dsa.dsarequired
+const (
+	dsaRequired         = 2 // Bids without DSA transparency are rejected
+	dsaRequiredPlatform = 3 // Required, and the publisher is an online platform
+)
+
+// dsaMaxFieldLength is the IAB limit for bid.ext.dsa.behalf and bid.ext.dsa.paid
+const dsaMaxFieldLength = 100
+
+// publisherDSA returns the default regs.ext.dsa configured for the publisher in context
+func publisherDSA(ctx context.Context) *openrtb.ExtRegsDSA {
+	type dsaGetter interface {
+		GetDSA() *openrtb.ExtRegsDSA
+	}
+	if getter, ok := middleware.PublisherFromContext(ctx).(dsaGetter); ok {
+		return getter.GetDSA()
+	}
+	return nil
+}
+
+// applyPublisherDSA sets the publisher's default regs.ext.dsa on EU requests that don't carry one
+// The request is copied rather than modified, so the caller's request is left untouched.
+func applyPublisherDSA(ctx context.Context, req *openrtb.BidRequest) (*openrtb.BidRequest, error) {
+	pubDSA := publisherDSA(ctx)
+	if pubDSA == nil || !dsaApplies(req) {
+		return req, nil
+	}
+	// Rows written directly in SQL skip the admin API's validation
+	if err := pubDSA.Validate(); err != nil {
+		return req, fmt.Errorf("invalid publisher DSA defaults: %w", err)
+	}
+	if dsa, err := openrtb.RegsDSA(req.Regs); err != nil || dsa != nil {
+		return req, err
+	}
+
+	regs, err := openrtb.SetRegsDSA(req.Regs, pubDSA)
+	if err != nil {
+		return req, err
+	}
+	reqCopy := *req
+	reqCopy.Regs = regs
+	return &reqCopy, nil
+}
+
+// dsaApplies reports whether the DSA covers a request: regs.gdpr=1, or a device or user geo in the EU/EEA
+func dsaApplies(req *openrtb.BidRequest) bool {
+	if req.Regs != nil && req.Regs.GDPR != nil && *req.Regs.GDPR == 1 {
+		return true
+	}
+	// device.geo (current location) first, then user.geo, as in the privacy middleware
+	var geo *openrtb.Geo
+	if req.Device != nil && req.Device.Geo != nil {
+		geo = req.Device.Geo
+	} else if req.User != nil {
+		geo = req.User.Geo
+	}
+	return middleware.DetectRegulationFromGeo(geo) == middleware.RegulationGDPR
+}
+
+// validateBidDSA returns why a bid fails the request's DSA requirements, or "" if it passes
+func validateBidDSA(bid *openrtb.Bid, reqDSA *openrtb.ExtRegsDSA) string {
+	if reqDSA == nil || (reqDSA.Required != dsaRequired && reqDSA.Required != dsaRequiredPlatform) {
+		return ""
+	}
+
+	bidDSA, err := openrtb.BidDSA(bid)
+	if err != nil {
+		return fmt.Sprintf("malformed bid.ext: %v", err)
+	}
+	switch {
+	case bidDSA == nil:
+		return "missing bid.ext.dsa required by regs.ext.dsa"
+	case bidDSA.Behalf == "":
+		return "missing bid.ext.dsa.behalf"
+	case bidDSA.Paid == "":
+		return "missing bid.ext.dsa.paid"
+	case len(bidDSA.Transparency) == 0:
+		return "missing bid.ext.dsa.transparency"
+	case len(bidDSA.Behalf) > dsaMaxFieldLength:
+		return fmt.Sprintf("bid.ext.dsa.behalf exceeds %d characters", dsaMaxFieldLength)
+	case len(bidDSA.Paid) > dsaMaxFieldLength:
+		return fmt.Sprintf("bid.ext.dsa.paid exceeds %d characters", dsaMaxFieldLength)
+	}
+
+	// The publisher and the buyer must agree on who renders the DSA disclosure
+	if bidDSA.AdRender != nil {
+		if reqDSA.PubRender == 0 && *bidDSA.AdRender == 0 {
+			return "publisher cannot render DSA disclosure and bid.ext.dsa.adrender is 0"
+		}
+		if reqDSA.PubRender == 2 && *bidDSA.AdRender == 1 {
+			return "publisher will render DSA disclosure but bid.ext.dsa.adrender is 1"
+		}
+	}
+	return ""
+}
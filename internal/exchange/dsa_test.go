@@ -0,0 +1,191 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+	"github.com/thenexusengine/tne_springwire/internal/storage"
+)
+
+const validBidDSA = `{"dsa":{"behalf":"Advertiser","paid":"Agency","transparency":[{"domain":"dsp.example","dsaparams":[1,2]}],"adrender":1}}`
+
+func TestApplyPublisherDSA(t *testing.T) {
+	pubDSA := &openrtb.ExtRegsDSA{Required: 2, PubRender: 1, DataToPub: 2}
+	ctx := middleware.NewContextWithPublisher(context.Background(), &storage.Publisher{PublisherID: "pub-eu", DSA: pubDSA})
+
+	t.Run("fills missing dsa", func(t *testing.T) {
+		gdpr := 1
+		req := &openrtb.BidRequest{ID: "1", Regs: &openrtb.Regs{GDPR: &gdpr, Ext: json.RawMessage(`{"gpc":"1"}`)}}
+		got, err := applyPublisherDSA(ctx, req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		dsa, _ := openrtb.RegsDSA(got.Regs)
+		if dsa == nil || dsa.Required != 2 || dsa.PubRender != 1 {
+			t.Errorf("expected publisher DSA defaults, got %+v", dsa)
+		}
+		if got.Regs.GDPR == nil || !strings.Contains(string(got.Regs.Ext), `"gpc":"1"`) {
+			t.Errorf("expected other regs fields to be kept, got %+v ext=%s", got.Regs, got.Regs.Ext)
+		}
+		if string(req.Regs.Ext) != `{"gpc":"1"}` {
+			t.Errorf("original request should not be mutated, got %s", req.Regs.Ext)
+		}
+	})
+
+	t.Run("creates regs for eu geo", func(t *testing.T) {
+		req := &openrtb.BidRequest{ID: "1", Device: &openrtb.Device{Geo: &openrtb.Geo{Country: "FRA"}}}
+		got, err := applyPublisherDSA(ctx, req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dsa, _ := openrtb.RegsDSA(got.Regs); dsa == nil {
+			t.Error("expected regs.ext.dsa to be created")
+		}
+	})
+
+	t.Run("outside the eu", func(t *testing.T) {
+		gdpr := 0
+		for _, req := range []*openrtb.BidRequest{
+			{ID: "1"},
+			{ID: "2", Device: &openrtb.Device{Geo: &openrtb.Geo{Country: "USA"}}},
+			{ID: "3", Regs: &openrtb.Regs{GDPR: &gdpr}, User: &openrtb.User{Geo: &openrtb.Geo{Country: "BRA"}}},
+		} {
+			if got, err := applyPublisherDSA(ctx, req); err != nil || got != req {
+				t.Errorf("request %s: expected no DSA defaults outside the EU, got %+v (%v)", req.ID, got.Regs, err)
+			}
+		}
+	})
+
+	t.Run("invalid publisher defaults", func(t *testing.T) {
+		invalid := middleware.NewContextWithPublisher(context.Background(), &storage.Publisher{
+			PublisherID: "pub-sql",
+			DSA:         &openrtb.ExtRegsDSA{Required: 7},
+		})
+		gdpr := 1
+		req := &openrtb.BidRequest{ID: "1", Regs: &openrtb.Regs{GDPR: &gdpr}}
+		if got, err := applyPublisherDSA(invalid, req); err == nil || got != req {
+			t.Errorf("expected invalid defaults to be rejected, got %+v (%v)", got.Regs, err)
+		}
+	})
+
+	t.Run("request dsa wins", func(t *testing.T) {
+		req := &openrtb.BidRequest{ID: "1", Regs: &openrtb.Regs{Ext: json.RawMessage(`{"dsa":{"dsarequired":1}}`)}}
+		got, _ := applyPublisherDSA(ctx, req)
+		if got != req {
+			t.Error("expected request with its own dsa to be returned unchanged")
+		}
+	})
+
+	t.Run("no publisher defaults", func(t *testing.T) {
+		req := &openrtb.BidRequest{ID: "1"}
+		got, _ := applyPublisherDSA(context.Background(), req)
+		if got != req || got.Regs != nil {
+			t.Error("expected request to be returned unchanged")
+		}
+	})
+}
+
+func TestValidateBidDSA(t *testing.T) {
+	required := &openrtb.ExtRegsDSA{Required: 2, PubRender: 1}
+
+	tests := []struct {
+		name    string
+		reqDSA  *openrtb.ExtRegsDSA
+		bidExt  string
+		wantErr string // empty means the bid is valid
+	}{
+		{name: "no request dsa", bidExt: ``},
+		{name: "dsa supported but not required", reqDSA: &openrtb.ExtRegsDSA{Required: 1}, bidExt: ``},
+		{name: "valid bid dsa", reqDSA: required, bidExt: validBidDSA},
+		{name: "valid for online platform", reqDSA: &openrtb.ExtRegsDSA{Required: 3, PubRender: 1}, bidExt: validBidDSA},
+		{name: "missing dsa", reqDSA: required, bidExt: `{"other":1}`, wantErr: "missing bid.ext.dsa"},
+		{name: "missing ext", reqDSA: required, bidExt: ``, wantErr: "missing bid.ext.dsa"},
+		{name: "missing behalf", reqDSA: required, bidExt: `{"dsa":{"paid":"Agency","transparency":[{"domain":"dsp.example"}]}}`, wantErr: "behalf"},
+		{name: "missing paid", reqDSA: required, bidExt: `{"dsa":{"behalf":"Advertiser","transparency":[{"domain":"dsp.example"}]}}`, wantErr: "paid"},
+		{name: "missing transparency", reqDSA: required, bidExt: `{"dsa":{"behalf":"Advertiser","paid":"Agency"}}`, wantErr: "transparency"},
+		{name: "behalf too long", reqDSA: required, bidExt: `{"dsa":{"behalf":"` + strings.Repeat("a", 101) + `","paid":"Agency","transparency":[{"domain":"dsp.example"}]}}`, wantErr: "exceeds"},
+		{name: "nobody renders", reqDSA: &openrtb.ExtRegsDSA{Required: 2, PubRender: 0}, bidExt: `{"dsa":{"behalf":"A","paid":"B","transparency":[{"domain":"d"}],"adrender":0}}`, wantErr: "cannot render"},
+		{name: "both render", reqDSA: &openrtb.ExtRegsDSA{Required: 2, PubRender: 2}, bidExt: validBidDSA, wantErr: "will render"},
+		{name: "malformed ext", reqDSA: required, bidExt: `{"dsa":"yes"}`, wantErr: "malformed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bid := &openrtb.Bid{ID: "b1", ImpID: "imp1"}
+			if tt.bidExt != "" {
+				bid.Ext = json.RawMessage(tt.bidExt)
+			}
+			reason := validateBidDSA(bid, tt.reqDSA)
+			if tt.wantErr == "" && reason != "" {
+				t.Errorf("unexpected rejection: %s", reason)
+			}
+			if tt.wantErr != "" && !strings.Contains(reason, tt.wantErr) {
+				t.Errorf("expected reason containing %q, got %q", tt.wantErr, reason)
+			}
+		})
+	}
+}
+
+// TestRunAuction_DSAEnforcement verifies publisher DSA defaults are forwarded and bids without DSA info are dropped
+func TestRunAuction_DSAEnforcement(t *testing.T) {
+	withDSA := &capturingAdapter{mockAdapter: mockAdapter{bids: []*adapters.TypedBid{{
+		Bid:     &openrtb.Bid{ID: "dsa-bid", ImpID: "imp1", Price: 1.50, AdM: "<div>ad</div>", Ext: json.RawMessage(validBidDSA)},
+		BidType: adapters.BidTypeBanner,
+	}}}}
+	withoutDSA := &capturingAdapter{mockAdapter: mockAdapter{bids: []*adapters.TypedBid{{
+		Bid:     &openrtb.Bid{ID: "plain-bid", ImpID: "imp1", Price: 2.00, AdM: "<div>ad</div>"},
+		BidType: adapters.BidTypeBanner,
+	}}}}
+
+	registry := adapters.NewRegistry()
+	registry.Register("with_dsa", withDSA, adapters.BidderInfo{Enabled: true, DemandType: adapters.DemandTypePublisher})
+	registry.Register("without_dsa", withoutDSA, adapters.BidderInfo{Enabled: true, DemandType: adapters.DemandTypePublisher})
+
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond})
+
+	ctx := middleware.NewContextWithPublisher(context.Background(), &storage.Publisher{
+		PublisherID: "pub-eu",
+		DSA:         &openrtb.ExtRegsDSA{Required: 2, PubRender: 1, DataToPub: 2},
+	})
+	bidReq := &openrtb.BidRequest{
+		ID:     "test-dsa",
+		Site:   testSite(),
+		Device: &openrtb.Device{Geo: &openrtb.Geo{Country: "DEU"}},
+		Imp:    []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{W: 300, H: 250}}},
+	}
+
+	resp, err := ex.RunAuction(ctx, &AuctionRequest{BidRequest: bidReq})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if dsa, _ := openrtb.RegsDSA(withDSA.received.Regs); dsa == nil || dsa.Required != 2 {
+		t.Errorf("expected bidders to receive the publisher's regs.ext.dsa, got %+v", withDSA.received.Regs)
+	}
+	if bidReq.Regs != nil {
+		t.Error("original request should not be mutated")
+	}
+
+	var bids []openrtb.Bid
+	for _, sb := range resp.BidResponse.SeatBid {
+		bids = append(bids, sb.Bid...)
+	}
+	if len(bids) != 1 || bids[0].ID != "dsa-bid" {
+		t.Fatalf("expected only the bid with DSA info to win, got %+v", bids)
+	}
+
+	var ext openrtb.BidExt
+	if err := json.Unmarshal(bids[0].Ext, &ext); err != nil {
+		t.Fatalf("invalid bid ext: %v", err)
+	}
+	meta := ext.Prebid.Meta
+	if meta.DSA == nil || meta.DSA.Behalf != "Advertiser" || meta.DSA.Paid != "Agency" || len(meta.DSA.Transparency) != 1 {
+		t.Errorf("expected DSA info in ext.prebid.meta.dsa, got %+v", meta.DSA)
+	}
+}
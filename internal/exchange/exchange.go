@@ -68,7 +68,7 @@ type Exchange struct {
 	bidderBreakers   map[string]*idr.CircuitBreaker
 	bidderBreakersMu sync.RWMutex
 
-	// configMu protects fpdProcessor, eidFilter, and config.FPD
+	// configMu protects fpdProcessor, eidFilter, config.FPD and config.DefaultTimeout
 	// for safe concurrent access during runtime config updates
 	configMu sync.RWMutex
 }
@@ -344,6 +344,7 @@ type AuctionRequest struct {
 	Timeout    time.Duration
 	Account    string
 	Debug      bool
+	UserIDs    map[string]string // Bidder code -> buyer UID from the uids cookie or UID store
 }
 
 // AuctionResponse contains auction results
@@ -352,6 +353,9 @@ type AuctionResponse struct {
 	BidderResults map[string]*BidderResult
 	IDRResult     *idr.SelectPartnersResponse
 	DebugInfo     *DebugInfo
+
+	// FledgeAuctionConfigs are Protected Audience auction configs for eligible impressions
+	FledgeAuctionConfigs []*openrtb.FledgeAuctionConfig
 }
 
 // BidderResult contains results from a single bidder
@@ -363,6 +367,9 @@ type BidderResult struct {
 	Selected   bool
 	Score      float64
 	TimedOut   bool // P2-2: indicates if the bidder request timed out
+
+	FledgeAuctionConfigs []*openrtb.FledgeAuctionConfig
+	MinimizedFields      []middleware.MinimizedField // Personal data removed or coarsened before the request was sent
 }
 
 // DebugInfo contains debug information
@@ -374,7 +381,8 @@ type DebugInfo struct {
 	SelectedBidders []string
 	ExcludedBidders []string
 	Errors          map[string][]string
-	errorsMu        sync.Mutex // Protects concurrent access to Errors map
+	MinimizedFields map[string][]middleware.MinimizedField // Bidder code -> data minimization applied to its request
+	errorsMu        sync.Mutex                             // Protects concurrent access to Errors map
 }
 
 // AddError safely adds errors to the Errors map with mutex protection
@@ -436,19 +444,18 @@ func ValidateRequest(req *openrtb.BidRequest) *RequestValidationError {
 		impIDs[imp.ID] = struct{}{}
 	}
 
-	// Validate Site XOR App (exactly one must be present, not both, not neither)
-	hasSite := req.Site != nil
-	hasApp := req.App != nil
-	if hasSite && hasApp {
+	// Validate exactly one of Site, App or DOOH is present
+	distChannels := distributionChannels(req)
+	if len(distChannels) > 1 {
 		return &RequestValidationError{
 			Field:  "site/app",
-			Reason: "request cannot contain both site and app objects",
+			Reason: fmt.Sprintf("request cannot contain both %s and %s objects", distChannels[0], distChannels[1]),
 		}
 	}
-	if !hasSite && !hasApp {
+	if len(distChannels) == 0 {
 		return &RequestValidationError{
 			Field:  "site/app",
-			Reason: "request must contain either site or app object",
+			Reason: "request must contain either site or app object (or dooh)",
 		}
 	}
 
@@ -472,7 +479,8 @@ func ValidateRequest(req *openrtb.BidRequest) *RequestValidationError {
 		}
 	}
 
-	return nil
+	// Validate OpenRTB 2.6 fields
+	return validateRequest26(req)
 }
 
 // BidValidationError represents a bid validation failure
@@ -488,7 +496,8 @@ func (e *BidValidationError) Error() string {
 }
 
 // validateBid checks if a bid meets OpenRTB requirements and exchange rules
-func (e *Exchange) validateBid(bid *openrtb.Bid, bidderCode string, impIDs map[string]float64) *BidValidationError {
+// reqDSA is the request's regs.ext.dsa; when DSA transparency is required, bids must carry bid.ext.dsa
+func (e *Exchange) validateBid(bid *openrtb.Bid, bidderCode string, impIDs map[string]float64, reqDSA *openrtb.ExtRegsDSA) *BidValidationError {
 	if bid == nil {
 		return &BidValidationError{BidderCode: bidderCode, Reason: "nil bid"}
 	}
@@ -563,6 +572,16 @@ func (e *Exchange) validateBid(bid *openrtb.Bid, bidderCode string, impIDs map[s
 		}
 	}
 
+	// EU Digital Services Act: required transparency information
+	if reason := validateBidDSA(bid, reqDSA); reason != "" {
+		return &BidValidationError{
+			BidID:      bid.ID,
+			ImpID:      bid.ImpID,
+			BidderCode: bidderCode,
+			Reason:     reason,
+		}
+	}
+
 	return nil
 }
 
@@ -814,6 +833,17 @@ func (e *Exchange) applyBidMultiplier(ctx context.Context, bidsByImp map[string]
 	return bidsByImp
 }
 
+// publisherEIDPermissions returns the eid_permissions configured for the publisher in context
+func publisherEIDPermissions(ctx context.Context) []fpd.EIDPermission {
+	type eidPermissionsGetter interface {
+		GetEIDPermissions() []fpd.EIDPermission
+	}
+	if getter, ok := middleware.PublisherFromContext(ctx).(eidPermissionsGetter); ok {
+		return getter.GetEIDPermissions()
+	}
+	return nil
+}
+
 // extractBidMultiplier safely extracts BidMultiplier field from any struct
 func extractBidMultiplier(v interface{}) (float64, bool) {
 	// Type assert to common publisher interface patterns
@@ -890,14 +920,13 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 			maxImpressions, len(req.BidRequest.Imp))
 	}
 
-	// P2-3: Validate Site/App mutual exclusivity per OpenRTB 2.5 section 3.2.1
-	hasSite := req.BidRequest.Site != nil
-	hasApp := req.BidRequest.App != nil
-	if !hasSite && !hasApp {
-		return nil, NewValidationError("invalid bid request: must have either 'site' or 'app' object (OpenRTB 2.5)")
+	// P2-3: Validate Site/App/DOOH mutual exclusivity per OpenRTB 2.6 section 3.2.1
+	distChannels := distributionChannels(req.BidRequest)
+	if len(distChannels) == 0 {
+		return nil, NewValidationError("invalid bid request: must have either 'site' or 'app' object (or 'dooh' in OpenRTB 2.6)")
 	}
-	if hasSite && hasApp {
-		return nil, NewValidationError("invalid bid request: cannot have both 'site' and 'app' objects (OpenRTB 2.5)")
+	if len(distChannels) > 1 {
+		return nil, NewValidationError("invalid bid request: cannot have both '%s' and '%s' objects (OpenRTB 2.6)", distChannels[0], distChannels[1])
 	}
 
 	// P1-NEW-2: Validate impression IDs are unique and non-empty per OpenRTB 2.5 section 3.2.4
@@ -932,6 +961,7 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 			RequestTime:     startTime,
 			BidderLatencies: make(map[string]time.Duration),
 			Errors:          make(map[string][]string),
+			MinimizedFields: make(map[string][]middleware.MinimizedField),
 		},
 	}
 
@@ -941,6 +971,13 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 		return response, validationErr
 	}
 
+	// Fill regs.ext.dsa from the publisher's DSA defaults when the request doesn't set it
+	if bidReq, err := applyPublisherDSA(ctx, req.BidRequest); err != nil {
+		logger.Log.Warn().Err(err).Str("request_id", req.BidRequest.ID).Msg("Failed to apply publisher DSA defaults")
+	} else {
+		req.BidRequest = bidReq
+	}
+
 	// Get timeout from request or config
 	// P1-NEW-1: Validate TMax bounds to prevent abuse
 	timeout := req.Timeout
@@ -953,7 +990,9 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 		timeout = time.Duration(tmax) * time.Millisecond
 	}
 	if timeout == 0 {
+		e.configMu.RLock()
 		timeout = e.config.DefaultTimeout
+		e.configMu.RUnlock()
 	}
 
 	// Create timeout context
@@ -1001,14 +1040,19 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 
 	response.DebugInfo.SelectedBidders = selectedBidders
 
-	// Process FPD and filter EIDs (using snapshotted processor/filter for consistency)
-	var bidderFPD fpd.BidderFPD
-	if fpdProcessor != nil {
-		// Filter EIDs first
-		if eidFilter != nil {
-			eidFilter.ProcessRequestEIDs(req.BidRequest)
+	// EIDs are filtered per bidder when each bidder request is cloned
+	// Publisher eid_permissions and the request's ext.prebid.data.eidpermissions both apply
+	var eids *eidScope
+	if eidFilter != nil {
+		eids = &eidScope{
+			filter:      eidFilter,
+			permissions: fpd.NewEIDPermissions(publisherEIDPermissions(ctx), fpd.RequestEIDPermissions(req.BidRequest)),
 		}
+	}
 
+	// Process FPD (using snapshotted processor for consistency)
+	var bidderFPD fpd.BidderFPD
+	if fpdProcessor != nil {
 		// Process FPD for each bidder
 		var err error
 		bidderFPD, err = fpdProcessor.ProcessRequest(req.BidRequest, selectedBidders)
@@ -1018,8 +1062,11 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 		}
 	}
 
+	// Consent and regulation signals decide what personal data each bidder may receive
+	privacy := middleware.EvaluateRequestPrivacy(req.BidRequest)
+
 	// Call bidders in parallel
-	results := e.callBiddersWithFPD(ctx, req.BidRequest, selectedBidders, timeout, bidderFPD)
+	results := e.callBiddersWithFPD(ctx, req.BidRequest, selectedBidders, timeout, bidderFPD, eids, req.UserIDs, privacy)
 
 	// Extract request context for event recording
 	var country, deviceType, mediaType, adSize, publisherID string
@@ -1069,6 +1116,9 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 	// Build impression floor map for bid validation (with multiplier applied to floors)
 	impFloors := e.buildImpFloorMap(ctx, req.BidRequest)
 
+	// Bids must satisfy regs.ext.dsa: the request's own (checked by ValidateRequest) or the publisher's default
+	reqDSA, _ := openrtb.RegsDSA(req.BidRequest.Regs)
+
 	// Track seen bid IDs for deduplication
 	seenBidIDs := make(map[string]struct{})
 
@@ -1080,6 +1130,9 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 	for bidderCode, result := range results {
 		response.BidderResults[bidderCode] = result
 		response.DebugInfo.BidderLatencies[bidderCode] = result.Latency
+		if len(result.MinimizedFields) > 0 {
+			response.DebugInfo.MinimizedFields[bidderCode] = result.MinimizedFields
+		}
 
 		// Record bidder request metrics
 		if e.metrics != nil {
@@ -1149,7 +1202,7 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 			}
 
 			// Validate bid
-			if validErr := e.validateBid(tb.Bid, bidderCode, impFloors); validErr != nil {
+			if validErr := e.validateBid(tb.Bid, bidderCode, impFloors, reqDSA); validErr != nil {
 				// P3-1: Log bid validation failures for debugging
 				logger.Log.Debug().
 					Str("bidder", bidderCode).
@@ -1186,6 +1239,9 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 		}
 	}
 
+	// Protected Audience configs are returned for eligible impressions whether or not they have bids
+	response.FledgeAuctionConfigs = e.collectFledgeAuctionConfigs(results, fledgeEligibleImps(req.BidRequest))
+
 	// Apply auction logic (first-price or second-price)
 	auctionedBids := e.runAuctionLogic(validBids, impFloors)
 
@@ -1310,7 +1366,7 @@ func (e *Exchange) RunAuction(ctx context.Context, req *AuctionRequest) (*Auctio
 // callBiddersWithFPD calls all selected bidders in parallel with FPD support
 // P0-1: Uses sync.Map for thread-safe result collection
 // P0-4: Uses semaphore to limit concurrent bidder goroutines
-func (e *Exchange) callBiddersWithFPD(ctx context.Context, req *openrtb.BidRequest, bidders []string, timeout time.Duration, bidderFPD fpd.BidderFPD) map[string]*BidderResult {
+func (e *Exchange) callBiddersWithFPD(ctx context.Context, req *openrtb.BidRequest, bidders []string, timeout time.Duration, bidderFPD fpd.BidderFPD, eids *eidScope, userIDs map[string]string, privacy *middleware.RequestPrivacy) map[string]*BidderResult {
 	var results sync.Map // P0-1: Thread-safe map for concurrent writes
 	var wg sync.WaitGroup
 
@@ -1402,9 +1458,24 @@ func (e *Exchange) callBiddersWithFPD(ctx context.Context, req *openrtb.BidReque
 				}
 
 				// Clone request and apply bidder-specific FPD
-				bidderReq := e.cloneRequestWithFPD(req, code, bidderFPD)
+				bidderReq := e.cloneRequestWithFPD(req, code, bidderFPD, eids)
+				applyBuyerUID(bidderReq, userIDs[code])
+				minimized := privacy.MinimizeForBidder(bidderReq, gvlID)
+				if !awi.Info.FledgeSupported {
+					stripFledgeSignal(bidderReq)
+				}
+				if awi.Info.OpenRTBVersion == openrtb.Version25 {
+					if err := openrtb.ConvertTo25(bidderReq); err != nil {
+						results.Store(code, &BidderResult{
+							BidderCode: code,
+							Errors:     []error{fmt.Errorf("openrtb 2.5 conversion failed: %w", err)},
+						})
+						return
+					}
+				}
 
 				result := e.callBidder(ctx, bidderReq, code, awi.Adapter, timeout)
+				result.MinimizedFields = minimized
 
 				// Record result in circuit breaker
 				breaker := e.getBidderCircuitBreaker(code)
@@ -1450,11 +1521,18 @@ func (e *Exchange) callBiddersWithFPD(ctx context.Context, req *openrtb.BidReque
 	return finalResults
 }
 
+// eidScope holds the EID source filter and per-bidder EID permissions for one auction
+type eidScope struct {
+	filter      *fpd.EIDFilter
+	permissions *fpd.EIDPermissions
+}
+
 // cloneRequestWithFPD creates a selective copy of the request with bidder-specific FPD applied
 // and enforces USD currency for all bid requests.
-// PERF: Only clones fields that are modified (Cur, Imp, Site/App/User if FPD applies).
+// PERF: Only clones fields that are modified (Cur, Imp, Site/App/User if FPD or EID filtering applies).
 // Deep copies Device, Regs, Source to prevent cross-bidder data races.
-func (e *Exchange) cloneRequestWithFPD(req *openrtb.BidRequest, bidderCode string, bidderFPD fpd.BidderFPD) *openrtb.BidRequest {
+// A nil eids scope leaves user.eids unfiltered.
+func (e *Exchange) cloneRequestWithFPD(req *openrtb.BidRequest, bidderCode string, bidderFPD fpd.BidderFPD, eids *eidScope) *openrtb.BidRequest {
 	// Shallow copy of top-level struct
 	clone := *req
 
@@ -1468,6 +1546,10 @@ func (e *Exchange) cloneRequestWithFPD(req *openrtb.BidRequest, bidderCode strin
 			geoCopy := *req.Device.Geo
 			deviceCopy.Geo = &geoCopy
 		}
+		if req.Device.SUA != nil {
+			suaCopy := *req.Device.SUA
+			deviceCopy.SUA = &suaCopy
+		}
 		clone.Device = &deviceCopy
 	}
 
@@ -1537,6 +1619,26 @@ func (e *Exchange) cloneRequestWithFPD(req *openrtb.BidRequest, bidderCode strin
 		clone.User = &userCopy
 	}
 
+	// Filter EIDs for this bidder; the filtered slice is new so User must be copied
+	if eids != nil && req.User != nil && len(req.User.EIDs) > 0 {
+		if clone.User == req.User {
+			userCopy := *req.User
+			clone.User = &userCopy
+		}
+		clone.User.EIDs = eids.filter.FilterEIDsForBidder(req.User.EIDs, bidderCode, eids.permissions)
+	}
+
+	// OpenRTB 2.5 callers may send EIDs in user.ext.eids; the same filter applies there
+	if eids != nil && req.User != nil && len(req.User.Ext) > 0 {
+		if clone.User == req.User {
+			userCopy := *req.User
+			clone.User = &userCopy
+		}
+		clone.User.Ext = openrtb.FilterExtEIDs(req.User.Ext, func(extEIDs []openrtb.EID) []openrtb.EID {
+			return eids.filter.FilterEIDsForBidder(extEIDs, bidderCode, eids.permissions)
+		})
+	}
+
 	// Apply FPD if available (now safe since we cloned the affected objects)
 	if hasFPD {
 		_ = e.fpdProcessor.ApplyFPDToRequest(&clone, bidderCode, fpdData) //nolint:errcheck
@@ -1545,6 +1647,23 @@ func (e *Exchange) cloneRequestWithFPD(req *openrtb.BidRequest, bidderCode strin
 	return &clone
 }
 
+// applyBuyerUID sets user.buyeruid on a cloned bidder request unless the request already carries one
+// User is copied first because the clone may share it with the original request
+func applyBuyerUID(clone *openrtb.BidRequest, buyerUID string) {
+	if buyerUID == "" {
+		return
+	}
+	userCopy := openrtb.User{}
+	if clone.User != nil {
+		if clone.User.BuyerUID != "" {
+			return
+		}
+		userCopy = *clone.User
+	}
+	userCopy.BuyerUID = buyerUID
+	clone.User = &userCopy
+}
+
 // deepCloneRequest creates a deep copy of the BidRequest to avoid race conditions
 // when multiple bidders modify request data concurrently
 // P3-1: Uses configurable limits to bound allocations
@@ -1662,6 +1781,10 @@ func deepCloneRequest(req *openrtb.BidRequest, limits *CloneLimits) *openrtb.Bid
 			geoCopy := *req.Device.Geo
 			deviceCopy.Geo = &geoCopy
 		}
+		if req.Device.SUA != nil {
+			suaCopy := *req.Device.SUA
+			deviceCopy.SUA = &suaCopy
+		}
 		clone.Device = &deviceCopy
 	}
 
@@ -1835,6 +1958,9 @@ func (e *Exchange) callBidder(ctx context.Context, req *openrtb.BidRequest, bidd
 				continue // Reject all bids from this response
 			}
 
+			// Auction configs carry no price, so they are kept even if the currency check below fails
+			result.FledgeAuctionConfigs = append(result.FledgeAuctionConfigs, bidderResp.FledgeAuctionConfigs...)
+
 			// P1-NEW-3: Normalize and validate response currency
 			// Per OpenRTB 2.5 spec section 7.2, empty currency means USD
 			responseCurrency := bidderResp.Currency
@@ -1917,13 +2043,19 @@ func (e *Exchange) buildBidExtension(vb ValidatedBid) *openrtb.BidExt {
 		targeting["hb_deal_"+displayBidderCode] = bid.DealID
 	}
 
+	meta := &openrtb.ExtBidPrebidMeta{
+		MediaType: bidType,
+	}
+	// Surface DSA transparency so the ad can be rendered with its disclosure
+	if dsa, err := openrtb.BidDSA(bid); err == nil {
+		meta.DSA = dsa
+	}
+
 	return &openrtb.BidExt{
 		Prebid: &openrtb.ExtBidPrebid{
 			Type:      bidType,
 			Targeting: targeting,
-			Meta: &openrtb.ExtBidPrebidMeta{
-				MediaType: bidType,
-			},
+			Meta:      meta,
 		},
 	}
 }
@@ -2073,6 +2205,24 @@ func (e *Exchange) UpdateFPDConfig(config *fpd.Config) {
 	e.eidFilter = newFilter
 }
 
+// SetDefaultTimeout updates the auction timeout used when a request has no tmax
+// Bidder HTTP clients keep the timeout the exchange was created with as their upper bound.
+func (e *Exchange) SetDefaultTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+	e.configMu.Lock()
+	defer e.configMu.Unlock()
+	e.config.DefaultTimeout = timeout
+}
+
+// DefaultTimeout returns the auction timeout used when a request has no tmax
+func (e *Exchange) DefaultTimeout() time.Duration {
+	e.configMu.RLock()
+	defer e.configMu.RUnlock()
+	return e.config.DefaultTimeout
+}
+
 // GetFPDConfig returns the current FPD configuration
 func (e *Exchange) GetFPDConfig() *fpd.Config {
 	e.configMu.RLock()
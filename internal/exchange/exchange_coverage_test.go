@@ -707,6 +707,23 @@ func TestUpdateFPDConfig_NilConfig(t *testing.T) {
 	}
 }
 
+// TestSetDefaultTimeout tests updating the default auction timeout at runtime
+func TestSetDefaultTimeout(t *testing.T) {
+	registry := adapters.NewRegistry()
+	exchange := New(registry, &Config{DefaultTimeout: time.Second})
+
+	exchange.SetDefaultTimeout(500 * time.Millisecond)
+	if got := exchange.DefaultTimeout(); got != 500*time.Millisecond {
+		t.Errorf("Expected timeout 500ms, got %v", got)
+	}
+
+	// Non-positive timeouts are ignored
+	exchange.SetDefaultTimeout(0)
+	if got := exchange.DefaultTimeout(); got != 500*time.Millisecond {
+		t.Errorf("Expected timeout unchanged at 500ms, got %v", got)
+	}
+}
+
 // TestApplyBidMultiplier_NoPublisher tests multiplier with no publisher
 func TestApplyBidMultiplier_NoPublisher(t *testing.T) {
 	registry := adapters.NewRegistry()
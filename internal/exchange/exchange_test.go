@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -237,8 +240,8 @@ func TestExchangeFPDProcessing(t *testing.T) {
 func TestExchangeEIDFiltering(t *testing.T) {
 	registry := adapters.NewRegistry()
 
-	mock := &mockAdapter{bids: []*adapters.TypedBid{}}
-	registry.Register("bidder1", mock, adapters.BidderInfo{Enabled: true})
+	bidder := &capturingAdapter{}
+	registry.Register("bidder1", bidder, adapters.BidderInfo{Enabled: true})
 
 	ex := New(registry, &Config{
 		DefaultTimeout: 100 * time.Millisecond,
@@ -271,12 +274,117 @@ func TestExchangeEIDFiltering(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	// After filtering, only liveramp.com should remain
-	if len(req.BidRequest.User.EIDs) != 1 {
-		t.Errorf("expected 1 EID after filtering, got %d", len(req.BidRequest.User.EIDs))
+	// The bidder only receives liveramp.com
+	if bidder.received == nil || bidder.received.User == nil {
+		t.Fatal("expected bidder to receive a user")
+	}
+	eids := bidder.received.User.EIDs
+	if len(eids) != 1 || eids[0].Source != "liveramp.com" {
+		t.Errorf("expected only liveramp.com EID, got %+v", eids)
+	}
+
+	// Filtering happens per bidder, so the shared request keeps its EIDs
+	if len(req.BidRequest.User.EIDs) != 2 {
+		t.Errorf("original request should not be mutated, got %d EIDs", len(req.BidRequest.User.EIDs))
+	}
+}
+
+// TestExchangeEIDPermissions verifies request and publisher eidpermissions restrict EIDs per bidder
+func TestExchangeEIDPermissions(t *testing.T) {
+	registry := adapters.NewRegistry()
+	bidderA := &capturingAdapter{}
+	bidderB := &capturingAdapter{}
+	registry.Register("bidder_a", bidderA, adapters.BidderInfo{Enabled: true})
+	registry.Register("bidder_b", bidderB, adapters.BidderInfo{Enabled: true})
+
+	ex := New(registry, &Config{
+		DefaultTimeout: 100 * time.Millisecond,
+		IDREnabled:     false,
+		FPD:            &fpd.Config{Enabled: true, EIDsEnabled: true},
+	})
+
+	bidReq := &openrtb.BidRequest{
+		ID:   "test-eid-permissions",
+		Site: testSite(),
+		User: &openrtb.User{
+			EIDs: []openrtb.EID{
+				{Source: "liveramp.com", UIDs: []openrtb.UID{{ID: "lr123"}}},
+				{Source: "id5-sync.com", UIDs: []openrtb.UID{{ID: "id5-456"}}},
+				{Source: "pubcid.org", UIDs: []openrtb.UID{{ID: "pubcid-789"}}},
+			},
+		},
+		Imp: []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{W: 300, H: 250}}},
+		Ext: json.RawMessage(`{"prebid":{"data":{"eidpermissions":[{"source":"liveramp.com","bidders":["bidder_a"]}]}}}`),
+	}
+
+	// The publisher restricts id5-sync.com to bidder_b
+	ctx := middleware.NewContextWithPublisher(context.Background(), &storage.Publisher{
+		PublisherID:    "pub-eid",
+		EIDPermissions: []fpd.EIDPermission{{Source: "id5-sync.com", Bidders: []string{"bidder_b"}}},
+	})
+
+	if _, err := ex.RunAuction(ctx, &AuctionRequest{BidRequest: bidReq}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sources := func(a *capturingAdapter) []string {
+		var out []string
+		if a.received != nil && a.received.User != nil {
+			for _, eid := range a.received.User.EIDs {
+				out = append(out, eid.Source)
+			}
+		}
+		return out
+	}
+	if got := sources(bidderA); !reflect.DeepEqual(got, []string{"liveramp.com", "pubcid.org"}) {
+		t.Errorf("bidder_a: expected liveramp.com and pubcid.org, got %v", got)
+	}
+	if got := sources(bidderB); !reflect.DeepEqual(got, []string{"id5-sync.com", "pubcid.org"}) {
+		t.Errorf("bidder_b: expected id5-sync.com and pubcid.org, got %v", got)
+	}
+}
+
+// TestExchangeEIDPermissions_ExtEIDs verifies OpenRTB 2.5 user.ext.eids go through the same per-bidder filter
+func TestExchangeEIDPermissions_ExtEIDs(t *testing.T) {
+	registry := adapters.NewRegistry()
+	bidderA := &capturingAdapter{}
+	bidderB := &capturingAdapter{}
+	registry.Register("bidder_a", bidderA, adapters.BidderInfo{Enabled: true})
+	registry.Register("bidder_b", bidderB, adapters.BidderInfo{Enabled: true})
+
+	ex := New(registry, &Config{
+		DefaultTimeout: 100 * time.Millisecond,
+		IDREnabled:     false,
+		FPD:            &fpd.Config{Enabled: true, EIDsEnabled: true},
+	})
+
+	userExt := json.RawMessage(`{"eids":[{"source":"liveramp.com","uids":[{"id":"lr123"}]},{"source":"pubcid.org","uids":[{"id":"pubcid-789"}]}]}`)
+	bidReq := &openrtb.BidRequest{
+		ID:   "test-ext-eid-permissions",
+		Site: testSite(),
+		User: &openrtb.User{Ext: userExt},
+		Imp:  []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{W: 300, H: 250}}},
+		Ext:  json.RawMessage(`{"prebid":{"data":{"eidpermissions":[{"source":"liveramp.com","bidders":["bidder_a"]}]}}}`),
+	}
+
+	if _, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: bidReq}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	extOf := func(a *capturingAdapter) string {
+		if a.received == nil || a.received.User == nil {
+			return ""
+		}
+		return string(a.received.User.Ext)
 	}
-	if req.BidRequest.User.EIDs[0].Source != "liveramp.com" {
-		t.Errorf("expected liveramp.com EID, got %s", req.BidRequest.User.EIDs[0].Source)
+	if got := extOf(bidderA); !strings.Contains(got, "liveramp.com") || !strings.Contains(got, "pubcid.org") {
+		t.Errorf("bidder_a: expected liveramp.com and pubcid.org, got %s", got)
+	}
+	if got := extOf(bidderB); strings.Contains(got, "liveramp.com") || !strings.Contains(got, "pubcid.org") {
+		t.Errorf("bidder_b: expected only pubcid.org, got %s", got)
+	}
+	if string(bidReq.User.Ext) != string(userExt) {
+		t.Error("original request should not be mutated")
 	}
 }
 
@@ -504,7 +612,7 @@ func TestBidValidation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := ex.validateBid(tt.bid, tt.bidderCode, impFloors)
+			err := ex.validateBid(tt.bid, tt.bidderCode, impFloors, nil)
 			if tt.wantErr {
 				if err == nil {
 					t.Error("expected error, got nil")
@@ -1804,6 +1912,91 @@ func BenchmarkRoundToCents(b *testing.B) {
 	}
 }
 
+// capturingAdapter records the request each bidder receives
+type capturingAdapter struct {
+	mockAdapter
+	mu       sync.Mutex
+	received *openrtb.BidRequest
+}
+
+func (c *capturingAdapter) MakeRequests(request *openrtb.BidRequest, reqInfo *adapters.ExtraRequestInfo) ([]*adapters.RequestData, []error) {
+	c.mu.Lock()
+	c.received = request
+	c.mu.Unlock()
+	return c.mockAdapter.MakeRequests(request, reqInfo)
+}
+
+// TestRunAuction_AppliesBuyerUIDPerBidder verifies each bidder only receives its own UID
+func TestRunAuction_AppliesBuyerUIDPerBidder(t *testing.T) {
+	registry := adapters.NewRegistry()
+	withUID := &capturingAdapter{}
+	withoutUID := &capturingAdapter{}
+	registry.Register("bidder_a", withUID, adapters.BidderInfo{Enabled: true})
+	registry.Register("bidder_b", withoutUID, adapters.BidderInfo{Enabled: true})
+
+	ex := New(registry, &Config{
+		DefaultTimeout: 100 * time.Millisecond,
+		IDREnabled:     false,
+	})
+
+	bidReq := &openrtb.BidRequest{
+		ID:   "test-buyeruid",
+		Site: testSite(),
+		User: &openrtb.User{ID: "pub-user"},
+		Imp: []openrtb.Imp{
+			{ID: "imp1", Banner: &openrtb.Banner{W: 300, H: 250}},
+		},
+	}
+
+	_, err := ex.RunAuction(context.Background(), &AuctionRequest{
+		BidRequest: bidReq,
+		UserIDs:    map[string]string{"bidder_a": "uid-a"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if withUID.received == nil || withUID.received.User == nil || withUID.received.User.BuyerUID != "uid-a" {
+		t.Errorf("expected bidder_a to receive buyeruid uid-a, got %+v", withUID.received)
+	}
+	if withUID.received != nil && withUID.received.User != nil && withUID.received.User.ID != "pub-user" {
+		t.Errorf("expected user.id to be preserved, got %q", withUID.received.User.ID)
+	}
+	if withoutUID.received == nil || withoutUID.received.User.BuyerUID != "" {
+		t.Error("expected bidder_b to receive no buyeruid")
+	}
+	if bidReq.User.BuyerUID != "" {
+		t.Error("original request should not be mutated")
+	}
+}
+
+func TestApplyBuyerUID(t *testing.T) {
+	t.Run("creates user", func(t *testing.T) {
+		req := &openrtb.BidRequest{}
+		applyBuyerUID(req, "uid-1")
+		if req.User == nil || req.User.BuyerUID != "uid-1" {
+			t.Errorf("expected buyeruid uid-1, got %+v", req.User)
+		}
+	})
+
+	t.Run("keeps request buyeruid", func(t *testing.T) {
+		req := &openrtb.BidRequest{User: &openrtb.User{BuyerUID: "from-request"}}
+		applyBuyerUID(req, "uid-1")
+		if req.User.BuyerUID != "from-request" {
+			t.Errorf("expected request buyeruid to win, got %s", req.User.BuyerUID)
+		}
+	})
+
+	t.Run("empty uid is a no-op", func(t *testing.T) {
+		user := &openrtb.User{ID: "u1"}
+		req := &openrtb.BidRequest{User: user}
+		applyBuyerUID(req, "")
+		if req.User != user {
+			t.Error("expected user to be left untouched")
+		}
+	})
+}
+
 // TestSelectiveClone_OriginalNotMutated verifies that cloneRequestWithFPD
 // does not mutate the original request (critical for concurrent bidder calls)
 func TestSelectiveClone_OriginalNotMutated(t *testing.T) {
@@ -1859,7 +2052,7 @@ func TestSelectiveClone_OriginalNotMutated(t *testing.T) {
 	origDeviceUA := original.Device.UA
 
 	// Clone with FPD (no FPD data, so Site/App/User won't be cloned)
-	clone := ex.cloneRequestWithFPD(original, "bidder1", nil)
+	clone := ex.cloneRequestWithFPD(original, "bidder1", nil, nil)
 
 	// Verify clone has modified values
 	if clone.Cur[0] != "USD" {
@@ -1927,7 +2120,7 @@ func TestSelectiveClone_WithFPD(t *testing.T) {
 
 	origSitePtr := original.Site
 
-	clone := ex.cloneRequestWithFPD(original, "bidder1", fpdData)
+	clone := ex.cloneRequestWithFPD(original, "bidder1", fpdData, nil)
 
 	// Site should be cloned (different pointer) since FPD modifies it
 	if clone.Site == origSitePtr {
@@ -1981,7 +2174,7 @@ func BenchmarkSelectiveClone(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		ex.cloneRequestWithFPD(req, "bidder1", nil)
+		ex.cloneRequestWithFPD(req, "bidder1", nil, nil)
 	}
 }
 
@@ -2081,3 +2274,56 @@ func (m *mockMetrics) RecordBidderCircuitFailure(bidder string)   {}
 func (m *mockMetrics) RecordBidderCircuitSuccess(bidder string)   {}
 func (m *mockMetrics) RecordBidderCircuitRejected(bidder string)  {}
 func (m *mockMetrics) RecordBidderCircuitStateChange(bidder, fromState, toState string) {}
+
+// TestRunAuction_DataMinimizationPerBidder verifies personal data is minimized per bidder and reported in debug info
+func TestRunAuction_DataMinimizationPerBidder(t *testing.T) {
+	registry := adapters.NewRegistry()
+	bidderA := &capturingAdapter{}
+	bidderB := &capturingAdapter{}
+	registry.Register("bidder_a", bidderA, adapters.BidderInfo{Enabled: true})
+	registry.Register("bidder_b", bidderB, adapters.BidderInfo{Enabled: true})
+
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond})
+
+	bidReq := &openrtb.BidRequest{
+		ID:     "test-minimization",
+		Site:   testSite(),
+		Imp:    []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{W: 300, H: 250}}},
+		Device: &openrtb.Device{IP: "203.0.113.77", IFA: "ifa-1", Geo: &openrtb.Geo{Lat: 40.712776, Lon: -74.005974, ZIP: "10007"}},
+		User:   &openrtb.User{ID: "user-1", EIDs: []openrtb.EID{{Source: "liveramp.com", UIDs: []openrtb.UID{{ID: "lr-1"}}}}},
+		Regs:   &openrtb.Regs{USPrivacy: "1YYN"},
+	}
+
+	resp, err := ex.RunAuction(context.Background(), &AuctionRequest{
+		BidRequest: bidReq,
+		UserIDs:    map[string]string{"bidder_a": "uid-a"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, bidder := range []*capturingAdapter{bidderA, bidderB} {
+		got := bidder.received
+		if got.Device.IP != "203.0.113.0" || got.Device.IFA != "" || got.Device.Geo.ZIP != "" || got.Device.Geo.Lat != 40.71 {
+			t.Errorf("expected minimized device, got %+v geo=%+v", got.Device, got.Device.Geo)
+		}
+		if got.User.ID != "" || got.User.BuyerUID != "" || got.User.EIDs != nil {
+			t.Errorf("expected user identifiers removed, got %+v", got.User)
+		}
+	}
+
+	fieldsA := resp.DebugInfo.MinimizedFields["bidder_a"]
+	fieldsB := resp.DebugInfo.MinimizedFields["bidder_b"]
+	if len(fieldsA) != len(fieldsB)+1 {
+		t.Errorf("expected bidder_a's report to also list its buyeruid, got %+v vs %+v", fieldsA, fieldsB)
+	}
+	for _, f := range fieldsA {
+		if f.Reason != middleware.MinimizeReasonUSOptOut {
+			t.Errorf("expected us_optout reason, got %+v", f)
+		}
+	}
+
+	if bidReq.Device.IP != "203.0.113.77" || bidReq.User.ID != "user-1" || bidReq.Device.Geo.ZIP != "10007" {
+		t.Error("original request should not be mutated")
+	}
+}
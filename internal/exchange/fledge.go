@@ -0,0 +1,112 @@
+package exchange
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// fledgeImpExt holds the imp.ext fields Prebid.js uses to mark Protected Audience eligibility
+type fledgeImpExt struct {
+	AE  int `json:"ae"`
+	IGS *struct {
+		AE int `json:"ae"`
+	} `json:"igs"`
+}
+
+// hasFledgeSignal is a cheap pre-check so imp.ext is only parsed when it may carry ae or igs
+func hasFledgeSignal(ext json.RawMessage) bool {
+	return bytes.Contains(ext, []byte(`"ae"`)) || bytes.Contains(ext, []byte(`"igs"`))
+}
+
+// fledgeEligibleImps returns the IDs of impressions marked Protected Audience eligible
+// (imp.ext.ae=1 or imp.ext.igs.ae=1). Returns nil if no impression is eligible.
+func fledgeEligibleImps(req *openrtb.BidRequest) map[string]bool {
+	var eligible map[string]bool
+	for _, imp := range req.Imp {
+		if !hasFledgeSignal(imp.Ext) {
+			continue
+		}
+		var ext fledgeImpExt
+		if err := json.Unmarshal(imp.Ext, &ext); err != nil {
+			continue
+		}
+		if ext.AE == 1 || (ext.IGS != nil && ext.IGS.AE == 1) {
+			if eligible == nil {
+				eligible = make(map[string]bool)
+			}
+			eligible[imp.ID] = true
+		}
+	}
+	return eligible
+}
+
+// stripFledgeSignal removes imp.ext.ae and imp.ext.igs from a cloned bidder request
+// Bidders that don't declare Protected Audience support must not be told inventory is eligible.
+// The clone owns its Imp slice, so replacing Ext does not affect other bidders.
+func stripFledgeSignal(clone *openrtb.BidRequest) {
+	for i := range clone.Imp {
+		if !hasFledgeSignal(clone.Imp[i].Ext) {
+			continue
+		}
+		var ext map[string]json.RawMessage
+		if err := json.Unmarshal(clone.Imp[i].Ext, &ext); err != nil {
+			continue
+		}
+		delete(ext, "ae")
+		delete(ext, "igs")
+		if len(ext) == 0 {
+			clone.Imp[i].Ext = nil
+			continue
+		}
+		if extBytes, err := json.Marshal(ext); err == nil {
+			clone.Imp[i].Ext = extBytes
+		}
+	}
+}
+
+// collectFledgeAuctionConfigs gathers the auction configs bidders returned for eligible impressions
+// Only bidders that declare Protected Audience support contribute. Configs are attributed to the
+// bidder's seat, so platform demand stays obfuscated, and sorted by impression for stable output.
+func (e *Exchange) collectFledgeAuctionConfigs(results map[string]*BidderResult, eligible map[string]bool) []*openrtb.FledgeAuctionConfig {
+	if len(eligible) == 0 {
+		return nil
+	}
+
+	var configs []*openrtb.FledgeAuctionConfig
+	for bidderCode, result := range results {
+		if len(result.FledgeAuctionConfigs) == 0 {
+			continue
+		}
+		awi, ok := e.registry.Get(bidderCode)
+		if !ok || !awi.Info.FledgeSupported {
+			continue
+		}
+
+		seat := bidderCode
+		if awi.Info.DemandType != adapters.DemandTypePublisher {
+			seat = adapters.PlatformSeatName
+		}
+		for _, config := range result.FledgeAuctionConfigs {
+			if config == nil || !eligible[config.ImpID] {
+				continue
+			}
+			configs = append(configs, &openrtb.FledgeAuctionConfig{
+				ImpID:  config.ImpID,
+				Bidder: seat,
+				Config: config.Config,
+			})
+		}
+	}
+
+	sort.SliceStable(configs, func(i, j int) bool {
+		if configs[i].ImpID != configs[j].ImpID {
+			return configs[i].ImpID < configs[j].ImpID
+		}
+		return configs[i].Bidder < configs[j].Bidder
+	})
+	return configs
+}
@@ -0,0 +1,114 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// fledgeAdapter captures its request and returns fixed Protected Audience auction configs
+type fledgeAdapter struct {
+	capturingAdapter
+	configs []*openrtb.FledgeAuctionConfig
+}
+
+func (f *fledgeAdapter) MakeBids(request *openrtb.BidRequest, response *adapters.ResponseData) (*adapters.BidderResponse, []error) {
+	resp, errs := f.capturingAdapter.MakeBids(request, response)
+	if resp != nil {
+		resp.FledgeAuctionConfigs = f.configs
+	}
+	return resp, errs
+}
+
+func TestFledgeEligibleImps(t *testing.T) {
+	req := &openrtb.BidRequest{Imp: []openrtb.Imp{
+		{ID: "ae", Ext: json.RawMessage(`{"ae":1,"bidder":{}}`)},
+		{ID: "igs", Ext: json.RawMessage(`{"igs":{"ae":1,"biddable":1}}`)},
+		{ID: "ae0", Ext: json.RawMessage(`{"ae":0}`)},
+		{ID: "none", Ext: json.RawMessage(`{"bidder":{}}`)},
+		{ID: "bad", Ext: json.RawMessage(`{"ae":`)},
+	}}
+
+	got := fledgeEligibleImps(req)
+	if !reflect.DeepEqual(got, map[string]bool{"ae": true, "igs": true}) {
+		t.Errorf("Expected imps ae and igs to be eligible, got %v", got)
+	}
+	if fledgeEligibleImps(&openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "1"}}}) != nil {
+		t.Error("Expected nil without eligible imps")
+	}
+}
+
+func TestStripFledgeSignal(t *testing.T) {
+	clone := &openrtb.BidRequest{Imp: []openrtb.Imp{
+		{ID: "1", Ext: json.RawMessage(`{"ae":1,"igs":{"ae":1},"gpid":"/123/slot"}`)},
+		{ID: "2", Ext: json.RawMessage(`{"ae":1}`)},
+		{ID: "3", Ext: json.RawMessage(`{"gpid":"/123/other"}`)},
+	}}
+	stripFledgeSignal(clone)
+
+	if string(clone.Imp[0].Ext) != `{"gpid":"/123/slot"}` {
+		t.Errorf("Expected only gpid to remain, got %s", clone.Imp[0].Ext)
+	}
+	if clone.Imp[1].Ext != nil {
+		t.Errorf("Expected empty ext to be dropped, got %s", clone.Imp[1].Ext)
+	}
+	if string(clone.Imp[2].Ext) != `{"gpid":"/123/other"}` {
+		t.Errorf("Expected untouched ext, got %s", clone.Imp[2].Ext)
+	}
+}
+
+func TestRunAuction_FledgeAuctionConfigs(t *testing.T) {
+	config := json.RawMessage(`{"seller":"https://ssp.example","decisionLogicUrl":"https://ssp.example/decide.js"}`)
+
+	supported := &fledgeAdapter{configs: []*openrtb.FledgeAuctionConfig{
+		{ImpID: "imp1", Config: config},
+		{ImpID: "imp2", Config: config}, // Not eligible: dropped
+	}}
+	publisher := &fledgeAdapter{configs: []*openrtb.FledgeAuctionConfig{{ImpID: "imp1", Config: config}}}
+	unsupported := &fledgeAdapter{configs: []*openrtb.FledgeAuctionConfig{{ImpID: "imp1", Config: config}}}
+
+	registry := adapters.NewRegistry()
+	registry.Register("platform_pa", supported, adapters.BidderInfo{Enabled: true, FledgeSupported: true})
+	registry.Register("publisher_pa", publisher, adapters.BidderInfo{Enabled: true, FledgeSupported: true, DemandType: adapters.DemandTypePublisher})
+	registry.Register("no_pa", unsupported, adapters.BidderInfo{Enabled: true})
+
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond})
+
+	bidReq := &openrtb.BidRequest{
+		ID:   "test-fledge",
+		Site: testSite(),
+		Imp: []openrtb.Imp{
+			{ID: "imp1", Banner: &openrtb.Banner{W: 300, H: 250}, Ext: json.RawMessage(`{"ae":1}`)},
+			{ID: "imp2", Banner: &openrtb.Banner{W: 728, H: 90}},
+		},
+	}
+
+	resp, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: bidReq})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []*openrtb.FledgeAuctionConfig{
+		{ImpID: "imp1", Bidder: "publisher_pa", Config: config},
+		{ImpID: "imp1", Bidder: adapters.PlatformSeatName, Config: config},
+	}
+	if !reflect.DeepEqual(resp.FledgeAuctionConfigs, want) {
+		t.Errorf("Expected configs %+v, got %+v", want, resp.FledgeAuctionConfigs)
+	}
+
+	// Eligibility is only signalled to bidders that declare support
+	if string(supported.received.Imp[0].Ext) != `{"ae":1}` {
+		t.Errorf("Expected supporting bidder to receive ae=1, got %s", supported.received.Imp[0].Ext)
+	}
+	if unsupported.received.Imp[0].Ext != nil {
+		t.Errorf("Expected ae to be stripped for unsupported bidder, got %s", unsupported.received.Imp[0].Ext)
+	}
+	if string(bidReq.Imp[0].Ext) != `{"ae":1}` {
+		t.Errorf("Original request should not be modified, got %s", bidReq.Imp[0].Ext)
+	}
+}
@@ -0,0 +1,125 @@
+package exchange
+
+import (
+	"fmt"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// distributionChannels returns the distribution channel objects present on a request
+// OpenRTB 2.6 requires exactly one of site, app or dooh.
+func distributionChannels(req *openrtb.BidRequest) []string {
+	var channels []string
+	if req.Site != nil {
+		channels = append(channels, "site")
+	}
+	if req.App != nil {
+		channels = append(channels, "app")
+	}
+	if req.DOOH != nil {
+		channels = append(channels, "dooh")
+	}
+	return channels
+}
+
+// validateRequest26 validates the fields OpenRTB 2.6 added to the request
+func validateRequest26(req *openrtb.BidRequest) *RequestValidationError {
+	for i := range req.Imp {
+		if err := validateImp26(&req.Imp[i], i); err != nil {
+			return err
+		}
+	}
+
+	if req.User != nil {
+		for i, eid := range req.User.EIDs {
+			if eid.MM < openrtb.MatchMethodUnknown || eid.MM > openrtb.MatchMethodInferred {
+				return &RequestValidationError{
+					Field:  fmt.Sprintf("user.eids[%d].mm", i),
+					Reason: fmt.Sprintf("invalid match method: %d", eid.MM),
+				}
+			}
+		}
+	}
+
+	dsa, err := openrtb.RegsDSA(req.Regs)
+	if err != nil {
+		return &RequestValidationError{Field: "regs.ext", Reason: fmt.Sprintf("malformed regs.ext: %v", err)}
+	}
+	if dsa != nil {
+		if err := dsa.Validate(); err != nil {
+			return &RequestValidationError{Field: "regs.ext.dsa", Reason: err.Error()}
+		}
+	}
+
+	return nil
+}
+
+// validateImp26 validates the OpenRTB 2.6 fields of one impression
+func validateImp26(imp *openrtb.Imp, i int) *RequestValidationError {
+	field := func(name string) string {
+		return fmt.Sprintf("imp[%d].%s", i, name)
+	}
+
+	if imp.Rwdd != 0 && imp.Rwdd != 1 {
+		return &RequestValidationError{Field: field("rwdd"), Reason: fmt.Sprintf("must be 0 or 1: %d", imp.Rwdd)}
+	}
+	if imp.SSAI < 0 || imp.SSAI > 3 {
+		return &RequestValidationError{Field: field("ssai"), Reason: fmt.Sprintf("must be 0-3: %d", imp.SSAI)}
+	}
+	if imp.DT < 0 {
+		return &RequestValidationError{Field: field("dt"), Reason: "timestamp cannot be negative"}
+	}
+	if imp.Qty != nil && imp.Qty.Multiplier <= 0 {
+		return &RequestValidationError{Field: field("qty.multiplier"), Reason: "multiplier must be positive"}
+	}
+	if imp.Refresh != nil {
+		if imp.Refresh.Count < 0 {
+			return &RequestValidationError{Field: field("refresh.count"), Reason: "count cannot be negative"}
+		}
+		for j, rs := range imp.Refresh.RefSettings {
+			if rs.MinInt < 0 {
+				return &RequestValidationError{Field: field(fmt.Sprintf("refresh.refsettings[%d].minint", j)), Reason: "interval cannot be negative"}
+			}
+		}
+	}
+
+	if v := imp.Video; v != nil {
+		if v.Plcmt < 0 || v.Plcmt > 4 {
+			return &RequestValidationError{Field: field("video.plcmt"), Reason: fmt.Sprintf("must be 0-4: %d", v.Plcmt)}
+		}
+		if err := validatePod(field("video"), v.MinDuration, v.MaxDuration, v.PodDur, v.MaxSeq, v.PodSeq, v.SlotInPod, v.RqdDurs, v.MinCPMPerSec); err != nil {
+			return err
+		}
+	}
+	if a := imp.Audio; a != nil {
+		if err := validatePod(field("audio"), a.MinDuration, a.MaxDuration, a.PodDur, a.MaxSeq, a.PodSeq, a.SlotInPod, a.RqdDurs, a.MinCPMPerSec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validatePod validates the OpenRTB 2.6 ad pod fields shared by video and audio
+func validatePod(prefix string, minDur, maxDur, podDur, maxSeq, podSeq, slotInPod int, rqdDurs []int, minCPMPerSec float64) *RequestValidationError {
+	if len(rqdDurs) > 0 && (minDur > 0 || maxDur > 0) {
+		return &RequestValidationError{Field: prefix + ".rqddurs", Reason: "rqddurs cannot be combined with minduration/maxduration"}
+	}
+	for _, d := range rqdDurs {
+		if d <= 0 {
+			return &RequestValidationError{Field: prefix + ".rqddurs", Reason: fmt.Sprintf("durations must be positive: %d", d)}
+		}
+	}
+	if podDur < 0 || maxSeq < 0 {
+		return &RequestValidationError{Field: prefix + ".poddur", Reason: "poddur and maxseq cannot be negative"}
+	}
+	if podSeq < -1 || podSeq > 1 {
+		return &RequestValidationError{Field: prefix + ".podseq", Reason: fmt.Sprintf("must be -1, 0 or 1: %d", podSeq)}
+	}
+	if slotInPod < -2 || slotInPod > 2 {
+		return &RequestValidationError{Field: prefix + ".slotinpod", Reason: fmt.Sprintf("must be -2 to 2: %d", slotInPod)}
+	}
+	if minCPMPerSec < 0 {
+		return &RequestValidationError{Field: prefix + ".mincpmpersec", Reason: "cannot be negative"}
+	}
+	return nil
+}
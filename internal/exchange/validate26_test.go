@@ -0,0 +1,205 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/adapters"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+func TestValidateRequest_OpenRTB26(t *testing.T) {
+	imp := func(mutate func(*openrtb.Imp)) []openrtb.Imp {
+		i := openrtb.Imp{ID: "imp1", Video: &openrtb.Video{Mimes: []string{"video/mp4"}}}
+		mutate(&i)
+		return []openrtb.Imp{i}
+	}
+
+	tests := []struct {
+		name     string
+		request  *openrtb.BidRequest
+		errField string // empty means the request is valid
+	}{
+		{
+			name:    "valid dooh request",
+			request: &openrtb.BidRequest{ID: "req1", DOOH: &openrtb.DOOH{ID: "screen1", VenueType: []string{"airport"}}, Imp: []openrtb.Imp{{ID: "imp1"}}},
+		},
+		{
+			name: "valid 2.6 fields",
+			request: &openrtb.BidRequest{ID: "req1", Site: testSite(), Imp: imp(func(i *openrtb.Imp) {
+				i.Rwdd = 1
+				i.SSAI = 1
+				i.Qty = &openrtb.Qty{Multiplier: 1.5}
+				i.Refresh = &openrtb.Refresh{Count: 2, RefSettings: []openrtb.RefSettings{{RefType: 1, MinInt: 30}}}
+				i.Video.Plcmt = 1
+				i.Video.RqdDurs = []int{15, 30}
+				i.Video.PodSeq = 1
+				i.Video.SlotInPod = -1
+			})},
+		},
+		{
+			name:     "site and dooh",
+			request:  &openrtb.BidRequest{ID: "req1", Site: testSite(), DOOH: &openrtb.DOOH{ID: "screen1"}, Imp: []openrtb.Imp{{ID: "imp1"}}},
+			errField: "site/app",
+		},
+		{
+			name:     "invalid rwdd",
+			request:  &openrtb.BidRequest{ID: "req1", Site: testSite(), Imp: imp(func(i *openrtb.Imp) { i.Rwdd = 2 })},
+			errField: "imp[0].rwdd",
+		},
+		{
+			name:     "invalid ssai",
+			request:  &openrtb.BidRequest{ID: "req1", Site: testSite(), Imp: imp(func(i *openrtb.Imp) { i.SSAI = 4 })},
+			errField: "imp[0].ssai",
+		},
+		{
+			name:     "zero qty multiplier",
+			request:  &openrtb.BidRequest{ID: "req1", Site: testSite(), Imp: imp(func(i *openrtb.Imp) { i.Qty = &openrtb.Qty{} })},
+			errField: "imp[0].qty.multiplier",
+		},
+		{
+			name: "negative refresh interval",
+			request: &openrtb.BidRequest{ID: "req1", Site: testSite(), Imp: imp(func(i *openrtb.Imp) {
+				i.Refresh = &openrtb.Refresh{RefSettings: []openrtb.RefSettings{{MinInt: -1}}}
+			})},
+			errField: "imp[0].refresh.refsettings[0].minint",
+		},
+		{
+			name:     "invalid plcmt",
+			request:  &openrtb.BidRequest{ID: "req1", Site: testSite(), Imp: imp(func(i *openrtb.Imp) { i.Video.Plcmt = 5 })},
+			errField: "imp[0].video.plcmt",
+		},
+		{
+			name: "rqddurs with maxduration",
+			request: &openrtb.BidRequest{ID: "req1", Site: testSite(), Imp: imp(func(i *openrtb.Imp) {
+				i.Video.RqdDurs = []int{15}
+				i.Video.MaxDuration = 30
+			})},
+			errField: "imp[0].video.rqddurs",
+		},
+		{
+			name:     "invalid slotinpod",
+			request:  &openrtb.BidRequest{ID: "req1", Site: testSite(), Imp: imp(func(i *openrtb.Imp) { i.Video.SlotInPod = 3 })},
+			errField: "imp[0].video.slotinpod",
+		},
+		{
+			name: "invalid audio podseq",
+			request: &openrtb.BidRequest{ID: "req1", Site: testSite(), Imp: []openrtb.Imp{
+				{ID: "imp1", Audio: &openrtb.Audio{Mimes: []string{"audio/mp4"}, PodSeq: 2}},
+			}},
+			errField: "imp[0].audio.podseq",
+		},
+		{
+			name: "invalid eid match method",
+			request: &openrtb.BidRequest{ID: "req1", Site: testSite(), Imp: []openrtb.Imp{{ID: "imp1"}},
+				User: &openrtb.User{EIDs: []openrtb.EID{{Source: "liveramp.com", MM: 7}}}},
+			errField: "user.eids[0].mm",
+		},
+		{
+			name: "invalid dsarequired",
+			request: &openrtb.BidRequest{ID: "req1", Site: testSite(), Imp: []openrtb.Imp{{ID: "imp1"}},
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"dsa":{"dsarequired":4}}`)}},
+			errField: "regs.ext.dsa",
+		},
+		{
+			name: "dsa transparency without domain",
+			request: &openrtb.BidRequest{ID: "req1", Site: testSite(), Imp: []openrtb.Imp{{ID: "imp1"}},
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"dsa":{"dsarequired":1,"transparency":[{"dsaparams":[1]}]}}`)}},
+			errField: "regs.ext.dsa",
+		},
+		{
+			name: "malformed regs.ext",
+			request: &openrtb.BidRequest{ID: "req1", Site: testSite(), Imp: []openrtb.Imp{{ID: "imp1"}},
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"dsa":"yes"}`)}},
+			errField: "regs.ext",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRequest(tt.request)
+			if tt.errField == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error on %s, got nil", tt.errField)
+			}
+			if err.Field != tt.errField {
+				t.Errorf("expected field %q, got %q (%s)", tt.errField, err.Field, err.Reason)
+			}
+		})
+	}
+}
+
+// TestRunAuction_ConvertsTo25PerBidder verifies only bidders declaring OpenRTB 2.5 get down-converted requests
+func TestRunAuction_ConvertsTo25PerBidder(t *testing.T) {
+	registry := adapters.NewRegistry()
+	legacy := &capturingAdapter{}
+	current := &capturingAdapter{}
+	registry.Register("legacy", legacy, adapters.BidderInfo{Enabled: true, OpenRTBVersion: openrtb.Version25})
+	registry.Register("current", current, adapters.BidderInfo{Enabled: true})
+
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond})
+
+	gdpr := 0
+	bidReq := &openrtb.BidRequest{
+		ID:     "test-ortb25",
+		Site:   testSite(),
+		Imp:    []openrtb.Imp{{ID: "imp1", Rwdd: 1, Banner: &openrtb.Banner{W: 300, H: 250}}},
+		Source: &openrtb.Source{SChain: &openrtb.SupplyChain{Complete: 1, Ver: "1.0", Nodes: []openrtb.SupplyChainNode{{ASI: "exchange.com", SID: "1", HP: 1}}}},
+		Regs:   &openrtb.Regs{GDPR: &gdpr},
+	}
+
+	if _, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: bidReq}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if legacy.received == nil || current.received == nil {
+		t.Fatal("expected both bidders to be called")
+	}
+	if legacy.received.Source.SChain != nil || !strings.Contains(string(legacy.received.Source.Ext), `"schain"`) {
+		t.Errorf("expected schain in source.ext for the 2.5 bidder, got %+v", legacy.received.Source)
+	}
+	if legacy.received.Regs.GDPR != nil || !strings.Contains(string(legacy.received.Regs.Ext), `"gdpr":0`) {
+		t.Errorf("expected gdpr in regs.ext for the 2.5 bidder, got %+v", legacy.received.Regs)
+	}
+	if legacy.received.Imp[0].Rwdd != 0 || !strings.Contains(string(legacy.received.Imp[0].Ext), `"is_rewarded_inventory":1`) {
+		t.Errorf("expected rwdd in imp.ext.prebid for the 2.5 bidder, got %s", legacy.received.Imp[0].Ext)
+	}
+
+	if current.received.Source.SChain == nil || current.received.Regs.GDPR == nil || current.received.Imp[0].Rwdd != 1 {
+		t.Error("expected the 2.6 bidder to receive 2.6 locations")
+	}
+	if bidReq.Source.SChain == nil || bidReq.Regs.GDPR == nil || bidReq.Imp[0].Rwdd != 1 {
+		t.Error("original request should not be mutated")
+	}
+}
+
+func TestRunAuction_DOOHTo25BidderFails(t *testing.T) {
+	registry := adapters.NewRegistry()
+	legacy := &capturingAdapter{}
+	registry.Register("legacy", legacy, adapters.BidderInfo{Enabled: true, OpenRTBVersion: openrtb.Version25})
+
+	ex := New(registry, &Config{DefaultTimeout: 100 * time.Millisecond})
+
+	resp, err := ex.RunAuction(context.Background(), &AuctionRequest{BidRequest: &openrtb.BidRequest{
+		ID:   "test-dooh",
+		DOOH: &openrtb.DOOH{ID: "screen1"},
+		Imp:  []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{W: 1920, H: 1080}}},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if legacy.received != nil {
+		t.Error("expected the 2.5 bidder not to be called for dooh inventory")
+	}
+	if result := resp.BidderResults["legacy"]; result == nil || len(result.Errors) == 0 {
+		t.Errorf("expected a conversion error for the 2.5 bidder, got %+v", result)
+	}
+}
@@ -0,0 +1,77 @@
+// Package filewatch polls operator-supplied files for changes
+// Used by components that hot-reload files from disk (IP reputation lists, admin TLS material).
+package filewatch
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// Version identifies a file's contents for change detection
+type Version struct {
+	ModTime time.Time
+	Size    int64
+}
+
+// Stat returns the current version of a file
+func Stat(path string) (Version, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Version{}, err
+	}
+	return Version{ModTime: info.ModTime(), Size: info.Size()}, nil
+}
+
+// Poller calls a reload function on a fixed interval until stopped
+// The reload function is expected to compare file versions and skip unchanged files.
+type Poller struct {
+	interval time.Duration
+	reload   func() error
+	onError  func(error)
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewPoller creates a poller; onError is called with each failed reload
+func NewPoller(interval time.Duration, reload func() error, onError func(error)) *Poller {
+	return &Poller{
+		interval: interval,
+		reload:   reload,
+		onError:  onError,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start launches periodic reloads; a zero interval disables them
+func (p *Poller) Start() {
+	if p == nil || p.interval <= 0 {
+		return
+	}
+	go p.run()
+}
+
+// run reloads on each tick until stopped
+func (p *Poller) run() {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.reload(); err != nil && p.onError != nil {
+				p.onError(err)
+			}
+		case <-p.stopCh:
+			return
+		}
+	}
+}
+
+// Stop stops periodic reloads
+func (p *Poller) Stop() {
+	if p == nil {
+		return
+	}
+	p.stopOnce.Do(func() { close(p.stopCh) })
+}
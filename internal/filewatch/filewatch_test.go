@@ -0,0 +1,86 @@
+package filewatch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list.txt")
+	if err := os.WriteFile(path, []byte("10.0.0.0/8\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if before.Size != 11 {
+		t.Errorf("expected size 11, got %d", before.Size)
+	}
+
+	if err := os.WriteFile(path, []byte("10.0.0.0/8\n192.168.0.0/16\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	after, err := Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if before == after {
+		t.Error("expected the version to change with the contents")
+	}
+
+	if _, err := Stat(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestPoller_ReloadsUntilStopped(t *testing.T) {
+	var calls, failures atomic.Int32
+	p := NewPoller(5*time.Millisecond, func() error {
+		if calls.Add(1)%2 == 0 {
+			return errors.New("bad file")
+		}
+		return nil
+	}, func(error) { failures.Add(1) })
+
+	p.Start()
+	deadline := time.Now().Add(time.Second)
+	for calls.Load() < 4 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	p.Stop()
+	p.Stop() // Idempotent
+
+	if calls.Load() < 4 {
+		t.Fatalf("expected at least 4 reloads, got %d", calls.Load())
+	}
+	if failures.Load() == 0 {
+		t.Error("expected failed reloads to be reported")
+	}
+
+	stopped := calls.Load()
+	time.Sleep(20 * time.Millisecond)
+	if calls.Load() > stopped+1 {
+		t.Errorf("expected no reloads after Stop, got %d more", calls.Load()-stopped)
+	}
+}
+
+func TestPoller_ZeroIntervalAndNil(t *testing.T) {
+	var calls atomic.Int32
+	p := NewPoller(0, func() error { calls.Add(1); return nil }, nil)
+	p.Start()
+	time.Sleep(10 * time.Millisecond)
+	p.Stop()
+	if calls.Load() != 0 {
+		t.Errorf("expected no reloads with a zero interval, got %d", calls.Load())
+	}
+
+	var nilPoller *Poller
+	nilPoller.Start()
+	nilPoller.Stop()
+}
@@ -378,3 +378,11 @@ func TestEIDFilter_isSourceAllowed_Whitelist(t *testing.T) {
 		})
 	}
 }
+
+func TestEIDFilter_DefaultExcludesPubCID(t *testing.T) {
+	// pubcid.org is added by the server only when SharedID minting is enabled
+	filter := NewEIDFilter(DefaultConfig())
+	if filter.isSourceAllowed("pubcid.org") {
+		t.Error("Expected default config not to allow the pubcid.org SharedID source")
+	}
+}
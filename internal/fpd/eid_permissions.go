@@ -0,0 +1,111 @@
+package fpd
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// eidPermissionAllBidders grants an EID source to every bidder
+const eidPermissionAllBidders = "*"
+
+// EIDPermission restricts an EID source to the listed bidders
+// Matches the ext.prebid.data.eidpermissions entries sent by Prebid.js
+type EIDPermission struct {
+	Source  string   `json:"source"`
+	Bidders []string `json:"bidders"`
+}
+
+// eidRuleSet maps lowercased EID sources to the lowercased bidders allowed to receive them
+type eidRuleSet map[string]map[string]bool
+
+// EIDPermissions combines EID permission lists (e.g. publisher config and request)
+// A bidder receives an EID only if every list that names its source allows the bidder.
+// Sources no list names go to all bidders. A nil EIDPermissions allows everything.
+type EIDPermissions struct {
+	sets []eidRuleSet
+}
+
+// NewEIDPermissions builds EID permissions from one or more permission lists
+// Returns nil if no list contains a rule
+func NewEIDPermissions(lists ...[]EIDPermission) *EIDPermissions {
+	var sets []eidRuleSet
+	for _, list := range lists {
+		set := make(eidRuleSet)
+		for _, perm := range list {
+			source := strings.TrimSpace(strings.ToLower(perm.Source))
+			if source == "" {
+				continue
+			}
+			bidders, ok := set[source]
+			if !ok {
+				bidders = make(map[string]bool, len(perm.Bidders))
+				set[source] = bidders
+			}
+			for _, bidder := range perm.Bidders {
+				if bidder = strings.TrimSpace(strings.ToLower(bidder)); bidder != "" {
+					bidders[bidder] = true
+				}
+			}
+		}
+		if len(set) > 0 {
+			sets = append(sets, set)
+		}
+	}
+
+	if len(sets) == 0 {
+		return nil
+	}
+	return &EIDPermissions{sets: sets}
+}
+
+// Allows returns true if the bidder may receive EIDs from the source
+func (p *EIDPermissions) Allows(source, bidder string) bool {
+	if p == nil {
+		return true
+	}
+	source = strings.TrimSpace(strings.ToLower(source))
+	bidder = strings.ToLower(bidder)
+	for _, set := range p.sets {
+		bidders, ok := set[source]
+		if !ok {
+			continue
+		}
+		if !bidders[eidPermissionAllBidders] && !bidders[bidder] {
+			return false
+		}
+	}
+	return true
+}
+
+// RequestEIDPermissions returns the ext.prebid.data.eidpermissions entries of a bid request
+func RequestEIDPermissions(req *openrtb.BidRequest) []EIDPermission {
+	if req == nil || len(req.Ext) == 0 {
+		return nil
+	}
+	var reqExt struct {
+		Prebid *PrebidExt `json:"prebid,omitempty"`
+	}
+	if err := json.Unmarshal(req.Ext, &reqExt); err != nil || reqExt.Prebid == nil || reqExt.Prebid.Data == nil {
+		return nil
+	}
+	return reqExt.Prebid.Data.EIDPermissions
+}
+
+// FilterEIDsForBidder returns the EIDs a bidder may receive
+// The global source filter applies first, then the per-bidder permissions
+func (f *EIDFilter) FilterEIDsForBidder(eids []openrtb.EID, bidder string, perms *EIDPermissions) []openrtb.EID {
+	eids = f.FilterEIDs(eids)
+	if perms == nil || len(eids) == 0 {
+		return eids
+	}
+
+	filtered := make([]openrtb.EID, 0, len(eids))
+	for _, eid := range eids {
+		if perms.Allows(eid.Source, bidder) {
+			filtered = append(filtered, eid)
+		}
+	}
+	return filtered
+}
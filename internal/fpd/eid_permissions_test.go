@@ -0,0 +1,102 @@
+package fpd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+func TestEIDPermissions_Allows(t *testing.T) {
+	perms := NewEIDPermissions([]EIDPermission{
+		{Source: "LiveRamp.com", Bidders: []string{"AppNexus", "rubicon"}},
+		{Source: "id5-sync.com", Bidders: []string{"*"}},
+		{Source: "criteo.com", Bidders: nil},
+	})
+
+	tests := []struct {
+		source  string
+		bidder  string
+		allowed bool
+	}{
+		{"liveramp.com", "appnexus", true},
+		{"liveramp.com", "RUBICON", true},
+		{"liveramp.com", "pubmatic", false},
+		{"id5-sync.com", "pubmatic", true},
+		{"criteo.com", "appnexus", false}, // Source listed without bidders goes to nobody
+		{"uidapi.com", "pubmatic", true},  // Unlisted sources go to everybody
+	}
+	for _, tt := range tests {
+		if got := perms.Allows(tt.source, tt.bidder); got != tt.allowed {
+			t.Errorf("Allows(%q, %q) = %v, want %v", tt.source, tt.bidder, got, tt.allowed)
+		}
+	}
+}
+
+func TestEIDPermissions_AllListsMustAllow(t *testing.T) {
+	publisher := []EIDPermission{{Source: "liveramp.com", Bidders: []string{"appnexus", "rubicon"}}}
+	request := []EIDPermission{{Source: "liveramp.com", Bidders: []string{"rubicon", "pubmatic"}}}
+	perms := NewEIDPermissions(publisher, request)
+
+	if perms.Allows("liveramp.com", "appnexus") {
+		t.Error("appnexus is not allowed by the request")
+	}
+	if !perms.Allows("liveramp.com", "rubicon") {
+		t.Error("rubicon is allowed by both lists")
+	}
+	if perms.Allows("liveramp.com", "pubmatic") {
+		t.Error("pubmatic is not allowed by the publisher")
+	}
+}
+
+func TestNewEIDPermissions_Empty(t *testing.T) {
+	if perms := NewEIDPermissions(nil, []EIDPermission{{Source: " "}}); perms != nil {
+		t.Errorf("Expected nil permissions, got %+v", perms)
+	}
+	var perms *EIDPermissions
+	if !perms.Allows("liveramp.com", "appnexus") {
+		t.Error("nil permissions should allow everything")
+	}
+}
+
+func TestRequestEIDPermissions(t *testing.T) {
+	req := &openrtb.BidRequest{
+		Ext: json.RawMessage(`{"prebid":{"data":{"eidpermissions":[{"source":"liveramp.com","bidders":["appnexus"]}]}}}`),
+	}
+	perms := RequestEIDPermissions(req)
+	if len(perms) != 1 || perms[0].Source != "liveramp.com" || perms[0].Bidders[0] != "appnexus" {
+		t.Errorf("Unexpected permissions: %+v", perms)
+	}
+
+	if perms := RequestEIDPermissions(&openrtb.BidRequest{Ext: json.RawMessage(`{"prebid":{}}`)}); perms != nil {
+		t.Errorf("Expected no permissions, got %+v", perms)
+	}
+	if perms := RequestEIDPermissions(nil); perms != nil {
+		t.Errorf("Expected no permissions for nil request, got %+v", perms)
+	}
+}
+
+func TestEIDFilter_FilterEIDsForBidder(t *testing.T) {
+	filter := NewEIDFilter(&Config{EIDsEnabled: true, EIDSources: []string{"liveramp.com", "id5-sync.com"}})
+	perms := NewEIDPermissions([]EIDPermission{{Source: "id5-sync.com", Bidders: []string{"rubicon"}}})
+	eids := []openrtb.EID{
+		{Source: "liveramp.com"},
+		{Source: "id5-sync.com"},
+		{Source: "blocked.com"},
+	}
+
+	if got := filter.FilterEIDsForBidder(eids, "appnexus", perms); len(got) != 1 || got[0].Source != "liveramp.com" {
+		t.Errorf("appnexus: expected only liveramp.com, got %+v", got)
+	}
+	if got := filter.FilterEIDsForBidder(eids, "rubicon", perms); len(got) != 2 {
+		t.Errorf("rubicon: expected liveramp.com and id5-sync.com, got %+v", got)
+	}
+	if len(eids) != 3 {
+		t.Error("input EIDs should not be modified")
+	}
+
+	disabled := NewEIDFilter(&Config{EIDsEnabled: false})
+	if got := disabled.FilterEIDsForBidder(eids, "rubicon", nil); got != nil {
+		t.Errorf("Expected no EIDs when disabled, got %+v", got)
+	}
+}
@@ -45,9 +45,10 @@ type PrebidExt struct {
 
 // PrebidData represents ext.prebid.data - global FPD to apply to all bidders
 type PrebidData struct {
-	Site json.RawMessage `json:"site,omitempty"`
-	App  json.RawMessage `json:"app,omitempty"`
-	User json.RawMessage `json:"user,omitempty"`
+	Site           json.RawMessage `json:"site,omitempty"`
+	App            json.RawMessage `json:"app,omitempty"`
+	User           json.RawMessage `json:"user,omitempty"`
+	EIDPermissions []EIDPermission `json:"eidpermissions,omitempty"`
 }
 
 // BidderConfig represents an entry in ext.prebid.bidderconfig
@@ -0,0 +1,58 @@
+package geoip
+
+import "testing"
+
+func TestToAlpha3(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"DE", "DEU"},
+		{"de", "DEU"},
+		{"DEU", "DEU"},
+		{"deu", "DEU"},
+		{"GB", "GBR"},
+		{"US", "USA"},
+		{" fr ", "FRA"},
+		{"XX", "XX"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ToAlpha3(tt.input); got != tt.expected {
+			t.Errorf("ToAlpha3(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestToAlpha2(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"DEU", "DE"},
+		{"deu", "DE"},
+		{"DE", "DE"},
+		{"USA", "US"},
+		{"GBR", "GB"},
+		{"XXX", "XXX"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ToAlpha2(tt.input); got != tt.expected {
+			t.Errorf("ToAlpha2(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestCountryTables_RoundTrip(t *testing.T) {
+	if len(alpha2ToAlpha3) != len(alpha3ToAlpha2) {
+		t.Fatalf("table sizes differ: %d alpha-2, %d alpha-3", len(alpha2ToAlpha3), len(alpha3ToAlpha2))
+	}
+	for a2, a3 := range alpha2ToAlpha3 {
+		if ToAlpha2(a3) != a2 || ToAlpha3(a2) != a3 {
+			t.Errorf("round trip failed for %s/%s", a2, a3)
+		}
+	}
+}
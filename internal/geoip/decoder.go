@@ -0,0 +1,248 @@
+package geoip
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// dataType identifies a MaxMind DB data section field type
+type dataType int
+
+const (
+	typeExtended  dataType = 0
+	typePointer   dataType = 1
+	typeString    dataType = 2
+	typeDouble    dataType = 3
+	typeBytes     dataType = 4
+	typeUint16    dataType = 5
+	typeUint32    dataType = 6
+	typeMap       dataType = 7
+	typeInt32     dataType = 8
+	typeUint64    dataType = 9
+	typeUint128   dataType = 10
+	typeArray     dataType = 11
+	typeContainer dataType = 12
+	typeEndMarker dataType = 13
+	typeBool      dataType = 14
+	typeFloat     dataType = 15
+)
+
+// maxDecodeDepth guards against maliciously nested maps and arrays
+const maxDecodeDepth = 64
+
+// decoder decodes values from a MaxMind DB data section
+// Decoded types: string, float64, float32, []byte, uint64 (uint16/32/64),
+// int32, *big.Int (uint128), bool, map[string]interface{} and []interface{}
+type decoder struct {
+	buf []byte
+}
+
+// decode decodes the value at offset and returns it with the offset of the next field
+func (d *decoder) decode(offset uint) (interface{}, uint, error) {
+	return d.decodeDepth(offset, 0)
+}
+
+func (d *decoder) decodeDepth(offset uint, depth int) (interface{}, uint, error) {
+	if depth > maxDecodeDepth {
+		return nil, 0, fmt.Errorf("%w: data nested too deeply", ErrInvalidDatabase)
+	}
+
+	typ, size, offset, err := d.decodeControl(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if typ == typePointer {
+		target, next, err := d.decodePointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		// The pointed-to value is decoded in place; decoding resumes after the pointer
+		value, _, err := d.decodeDepth(target, depth+1)
+		return value, next, err
+	}
+
+	return d.decodeValue(typ, size, offset, depth)
+}
+
+// decodeControl reads a control byte and returns the field type, payload size and payload offset
+func (d *decoder) decodeControl(offset uint) (dataType, uint, uint, error) {
+	if offset >= uint(len(d.buf)) {
+		return 0, 0, 0, fmt.Errorf("%w: unexpected end of data", ErrInvalidDatabase)
+	}
+	ctrl := d.buf[offset]
+	offset++
+
+	typ := dataType(ctrl >> 5)
+	if typ == typeExtended {
+		if offset >= uint(len(d.buf)) {
+			return 0, 0, 0, fmt.Errorf("%w: unexpected end of data", ErrInvalidDatabase)
+		}
+		typ = dataType(7 + int(d.buf[offset]))
+		offset++
+		if typ < typeInt32 || typ > typeFloat {
+			return 0, 0, 0, fmt.Errorf("%w: invalid extended type %d", ErrInvalidDatabase, typ)
+		}
+	}
+
+	size := uint(ctrl & 0x1F)
+	if typ == typePointer {
+		return typ, size, offset, nil // Pointer size bits are interpreted by decodePointer
+	}
+
+	if size >= 29 {
+		extra := size - 28 // 1, 2 or 3 additional size bytes
+		if offset+extra > uint(len(d.buf)) {
+			return 0, 0, 0, fmt.Errorf("%w: unexpected end of data", ErrInvalidDatabase)
+		}
+		n := readUint(d.buf[offset : offset+extra])
+		offset += extra
+		switch extra {
+		case 1:
+			size = 29 + uint(n)
+		case 2:
+			size = 285 + uint(n)
+		default:
+			size = 65821 + uint(n)
+		}
+	}
+
+	return typ, size, offset, nil
+}
+
+// decodePointer resolves a pointer field to a data section offset
+func (d *decoder) decodePointer(size, offset uint) (uint, uint, error) {
+	pointerSize := ((size >> 3) & 0x3) + 1
+	if offset+pointerSize > uint(len(d.buf)) {
+		return 0, 0, fmt.Errorf("%w: unexpected end of data", ErrInvalidDatabase)
+	}
+	raw := uint(readUint(d.buf[offset : offset+pointerSize]))
+	next := offset + pointerSize
+
+	var target uint
+	switch pointerSize {
+	case 1:
+		target = (size&0x7)<<8 | raw
+	case 2:
+		target = ((size&0x7)<<16 | raw) + 2048
+	case 3:
+		target = ((size&0x7)<<24 | raw) + 526336
+	default:
+		target = raw
+	}
+
+	if target >= uint(len(d.buf)) {
+		return 0, 0, fmt.Errorf("%w: pointer out of range", ErrInvalidDatabase)
+	}
+	return target, next, nil
+}
+
+// decodeValue decodes a non-pointer field payload
+func (d *decoder) decodeValue(typ dataType, size, offset uint, depth int) (interface{}, uint, error) {
+	switch typ {
+	case typeMap, typeArray:
+		// Every entry needs at least one byte; reject sizes that cannot fit
+		if size > uint(len(d.buf)) {
+			return nil, 0, fmt.Errorf("%w: container size %d exceeds data section", ErrInvalidDatabase, size)
+		}
+	}
+
+	switch typ {
+	case typeMap:
+		return d.decodeMap(size, offset, depth)
+	case typeArray:
+		return d.decodeArray(size, offset, depth)
+	case typeBool:
+		return size != 0, offset, nil
+	}
+
+	if offset+size > uint(len(d.buf)) {
+		return nil, 0, fmt.Errorf("%w: field exceeds data section", ErrInvalidDatabase)
+	}
+	payload := d.buf[offset : offset+size]
+	next := offset + size
+
+	switch typ {
+	case typeString:
+		return string(payload), next, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("%w: invalid double size %d", ErrInvalidDatabase, size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(payload)), next, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("%w: invalid float size %d", ErrInvalidDatabase, size)
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(payload)), next, nil
+	case typeBytes:
+		out := make([]byte, size)
+		copy(out, payload)
+		return out, next, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("%w: invalid unsigned integer size %d", ErrInvalidDatabase, size)
+		}
+		return readUint(payload), next, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, fmt.Errorf("%w: invalid int32 size %d", ErrInvalidDatabase, size)
+		}
+		return int32(uint32(readUint(payload))), next, nil //nolint:gosec // Two's complement reinterpretation is intended
+	case typeUint128:
+		if size > 16 {
+			return nil, 0, fmt.Errorf("%w: invalid uint128 size %d", ErrInvalidDatabase, size)
+		}
+		return new(big.Int).SetBytes(payload), next, nil
+	default:
+		return nil, 0, fmt.Errorf("%w: unexpected data type %d", ErrInvalidDatabase, typ)
+	}
+}
+
+// decodeMap decodes size key/value pairs
+func (d *decoder) decodeMap(size, offset uint, depth int) (interface{}, uint, error) {
+	m := make(map[string]interface{}, size)
+	for i := uint(0); i < size; i++ {
+		key, next, err := d.decodeDepth(offset, depth+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		keyStr, ok := key.(string)
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: map key is not a string", ErrInvalidDatabase)
+		}
+
+		value, next, err := d.decodeDepth(next, depth+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		m[keyStr] = value
+		offset = next
+	}
+	return m, offset, nil
+}
+
+// decodeArray decodes size consecutive values
+func (d *decoder) decodeArray(size, offset uint, depth int) (interface{}, uint, error) {
+	arr := make([]interface{}, 0, size)
+	for i := uint(0); i < size; i++ {
+		value, next, err := d.decodeDepth(offset, depth+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		arr = append(arr, value)
+		offset = next
+	}
+	return arr, offset, nil
+}
+
+// readUint reads a big-endian unsigned integer of up to 8 bytes
+func readUint(b []byte) uint64 {
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n
+}
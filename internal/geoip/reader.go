@@ -0,0 +1,253 @@
+// Package geoip provides a dependency-free MaxMind DB (.mmdb) reader
+package geoip
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+)
+
+// metadataStartMarker precedes the metadata section at the end of every MaxMind DB file
+var metadataStartMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// metadataMaxSize bounds the search for the metadata marker (per the MaxMind DB spec)
+const metadataMaxSize = 128 * 1024
+
+// dataSectionSeparatorSize is the size of the zero-filled gap between the search tree and data section
+const dataSectionSeparatorSize = 16
+
+// ErrInvalidDatabase is returned when the file is not a valid MaxMind DB
+var ErrInvalidDatabase = errors.New("invalid MaxMind DB file")
+
+// Metadata describes a MaxMind DB file
+type Metadata struct {
+	BinaryFormatMajorVersion uint
+	BinaryFormatMinorVersion uint
+	BuildEpoch               uint64
+	DatabaseType             string
+	Description              map[string]string
+	IPVersion                uint
+	Languages                []string
+	NodeCount                uint
+	RecordSize               uint
+}
+
+// Reader performs lookups against an in-memory MaxMind DB
+// Thread-safety: Reader is immutable after Open and safe for concurrent lookups
+type Reader struct {
+	buffer    []byte
+	metadata  Metadata
+	decoder   decoder // Decoder over the data section
+	nodeBytes uint    // Bytes per search tree node
+	treeSize  uint    // Size of the search tree in bytes
+	ipv4Start uint    // Node where IPv4 lookups start in an IPv6 tree
+}
+
+// Open reads a MaxMind DB file into memory
+func Open(path string) (*Reader, error) {
+	buf, err := os.ReadFile(path) //nolint:gosec // Path comes from operator configuration
+	if err != nil {
+		return nil, err
+	}
+	return FromBytes(buf)
+}
+
+// FromBytes creates a Reader from the raw contents of a MaxMind DB file
+func FromBytes(buf []byte) (*Reader, error) {
+	searchStart := 0
+	if len(buf) > metadataMaxSize {
+		searchStart = len(buf) - metadataMaxSize
+	}
+	idx := bytes.LastIndex(buf[searchStart:], metadataStartMarker)
+	if idx == -1 {
+		return nil, fmt.Errorf("%w: metadata section not found", ErrInvalidDatabase)
+	}
+	metadataStart := uint(searchStart + idx + len(metadataStartMarker))
+
+	metaDecoder := decoder{buf: buf[metadataStart:]}
+	raw, _, err := metaDecoder.decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode metadata: %v", ErrInvalidDatabase, err)
+	}
+	rawMap, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: metadata is not a map", ErrInvalidDatabase)
+	}
+	metadata := parseMetadata(rawMap)
+
+	if metadata.BinaryFormatMajorVersion != 2 {
+		return nil, fmt.Errorf("%w: unsupported binary format version %d", ErrInvalidDatabase, metadata.BinaryFormatMajorVersion)
+	}
+	if metadata.RecordSize != 24 && metadata.RecordSize != 28 && metadata.RecordSize != 32 {
+		return nil, fmt.Errorf("%w: unsupported record size %d", ErrInvalidDatabase, metadata.RecordSize)
+	}
+	if metadata.IPVersion != 4 && metadata.IPVersion != 6 {
+		return nil, fmt.Errorf("%w: unsupported IP version %d", ErrInvalidDatabase, metadata.IPVersion)
+	}
+
+	nodeBytes := metadata.RecordSize / 4 // Two records per node
+	treeSize := metadata.NodeCount * nodeBytes
+	dataStart := treeSize + dataSectionSeparatorSize
+	dataEnd := metadataStart - uint(len(metadataStartMarker))
+	if dataStart > dataEnd {
+		return nil, fmt.Errorf("%w: search tree exceeds file size", ErrInvalidDatabase)
+	}
+
+	r := &Reader{
+		buffer:    buf,
+		metadata:  metadata,
+		decoder:   decoder{buf: buf[dataStart:dataEnd]},
+		nodeBytes: nodeBytes,
+		treeSize:  treeSize,
+	}
+
+	// IPv4 addresses live under ::/96 in IPv6 trees
+	if metadata.IPVersion == 6 {
+		node := uint(0)
+		for i := 0; i < 96 && node < metadata.NodeCount; i++ {
+			node = r.readNode(node, 0)
+		}
+		r.ipv4Start = node
+	}
+
+	return r, nil
+}
+
+// Metadata returns the database metadata
+func (r *Reader) Metadata() Metadata {
+	return r.metadata
+}
+
+// Lookup returns the decoded data record for an IP address
+// Returns nil without error when the address is not in the database
+func (r *Reader) Lookup(ip net.IP) (interface{}, error) {
+	if r == nil || r.buffer == nil {
+		return nil, errors.New("geoip: reader is closed")
+	}
+
+	pointer, err := r.lookupPointer(ip)
+	if err != nil || pointer == 0 {
+		return nil, err
+	}
+
+	offset := pointer - r.metadata.NodeCount - dataSectionSeparatorSize
+	if offset >= uint(len(r.decoder.buf)) {
+		return nil, fmt.Errorf("%w: data pointer out of range", ErrInvalidDatabase)
+	}
+
+	value, _, err := r.decoder.decode(offset)
+	return value, err
+}
+
+// lookupPointer walks the search tree and returns the record pointer (0 if not found)
+func (r *Reader) lookupPointer(ip net.IP) (uint, error) {
+	if ip == nil {
+		return 0, errors.New("geoip: nil IP address")
+	}
+
+	node := uint(0)
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+		node = r.ipv4Start
+	} else if r.metadata.IPVersion == 4 {
+		return 0, errors.New("geoip: cannot look up an IPv6 address in an IPv4-only database")
+	} else {
+		ip = ip.To16()
+	}
+
+	nodeCount := r.metadata.NodeCount
+	bitCount := len(ip) * 8
+	for i := 0; i < bitCount && node < nodeCount; i++ {
+		bit := uint(ip[i>>3]>>(7-uint(i%8))) & 1
+		node = r.readNode(node, bit)
+	}
+
+	switch {
+	case node == nodeCount:
+		return 0, nil // Not found
+	case node > nodeCount:
+		return node, nil
+	default:
+		return 0, fmt.Errorf("%w: search tree is corrupt", ErrInvalidDatabase)
+	}
+}
+
+// readNode returns the left (bit=0) or right (bit=1) record of a search tree node
+func (r *Reader) readNode(node, bit uint) uint {
+	base := node * r.nodeBytes
+	if base+r.nodeBytes > r.treeSize {
+		return r.metadata.NodeCount // Treat out-of-range nodes as empty
+	}
+	b := r.buffer[base : base+r.nodeBytes]
+
+	switch r.metadata.RecordSize {
+	case 24:
+		off := bit * 3
+		return uint(b[off])<<16 | uint(b[off+1])<<8 | uint(b[off+2])
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default: // 32
+		off := bit * 4
+		return uint(b[off])<<24 | uint(b[off+1])<<16 | uint(b[off+2])<<8 | uint(b[off+3])
+	}
+}
+
+// Close releases the database buffer
+// Lookups must not be in flight when Close is called
+func (r *Reader) Close() error {
+	if r != nil {
+		r.buffer = nil
+		r.decoder.buf = nil
+	}
+	return nil
+}
+
+// parseMetadata converts the decoded metadata map into a Metadata struct
+func parseMetadata(m map[string]interface{}) Metadata {
+	md := Metadata{
+		BinaryFormatMajorVersion: uint(asUint(m["binary_format_major_version"])),
+		BinaryFormatMinorVersion: uint(asUint(m["binary_format_minor_version"])),
+		BuildEpoch:               asUint(m["build_epoch"]),
+		DatabaseType:             asString(m["database_type"]),
+		IPVersion:                uint(asUint(m["ip_version"])),
+		NodeCount:                uint(asUint(m["node_count"])),
+		RecordSize:               uint(asUint(m["record_size"])),
+		Description:              make(map[string]string),
+	}
+
+	if langs, ok := m["languages"].([]interface{}); ok {
+		for _, lang := range langs {
+			if s, ok := lang.(string); ok {
+				md.Languages = append(md.Languages, s)
+			}
+		}
+	}
+	if desc, ok := m["description"].(map[string]interface{}); ok {
+		for lang, text := range desc {
+			md.Description[lang] = asString(text)
+		}
+	}
+
+	return md
+}
+
+// asUint converts a decoded unsigned value to uint64 (0 if not unsigned)
+func asUint(v interface{}) uint64 {
+	if u, ok := v.(uint64); ok {
+		return u
+	}
+	return 0
+}
+
+// asString converts a decoded value to string ("" if not a string)
+func asString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return ""
+}
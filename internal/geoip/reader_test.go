@@ -0,0 +1,472 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// testNetwork is a network/record pair written into a test database
+type testNetwork struct {
+	cidr   string
+	record map[string]interface{}
+}
+
+// buildTestDB writes a minimal MaxMind DB in memory (test helper)
+func buildTestDB(t *testing.T, ipVersion, recordSize int, networks []testNetwork) []byte {
+	t.Helper()
+
+	const empty = -1
+	type node struct{ left, right int }
+	nodes := []node{{empty, empty}}
+	dataRefs := map[int]int{} // encoded record value (negative) -> data offset
+
+	var data bytes.Buffer
+	for i, n := range networks {
+		_, ipNet, err := net.ParseCIDR(n.cidr)
+		if err != nil {
+			t.Fatalf("bad CIDR %q: %v", n.cidr, err)
+		}
+		ip := ipNet.IP
+		ones, _ := ipNet.Mask.Size()
+		if ipVersion == 6 && len(ip.To4()) == net.IPv4len && ip.To4() != nil && len(ipNet.Mask) == net.IPv4len {
+			ip = ip.To16()
+			for j := 0; j < 12; j++ {
+				ip[j] = 0 // ::a.b.c.d rather than IPv4-mapped
+			}
+			ones += 96
+		}
+
+		ref := -2 - i
+		dataRefs[ref] = data.Len()
+		encodeValue(&data, n.record)
+
+		cur := 0
+		for bit := 0; bit < ones; bit++ {
+			b := (ip[bit/8] >> (7 - uint(bit%8))) & 1
+			last := bit == ones-1
+			child := &nodes[cur].left
+			if b == 1 {
+				child = &nodes[cur].right
+			}
+			if last {
+				*child = ref
+				break
+			}
+			if *child < 0 {
+				nodes = append(nodes, node{empty, empty})
+				// Re-take the pointer since append may have moved the slice
+				child = &nodes[cur].left
+				if b == 1 {
+					child = &nodes[cur].right
+				}
+				*child = len(nodes) - 1
+			}
+			cur = *child
+		}
+	}
+
+	nodeCount := len(nodes)
+	resolve := func(v int) uint32 {
+		switch {
+		case v == empty:
+			return uint32(nodeCount)
+		case v >= 0:
+			return uint32(v)
+		default:
+			return uint32(nodeCount + 16 + dataRefs[v])
+		}
+	}
+
+	var out bytes.Buffer
+	for _, n := range nodes {
+		l, r := resolve(n.left), resolve(n.right)
+		switch recordSize {
+		case 24:
+			out.Write([]byte{byte(l >> 16), byte(l >> 8), byte(l), byte(r >> 16), byte(r >> 8), byte(r)})
+		case 28:
+			out.Write([]byte{byte(l >> 16), byte(l >> 8), byte(l), byte((l>>20)&0xF0 | (r>>24)&0x0F), byte(r >> 16), byte(r >> 8), byte(r)})
+		case 32:
+			_ = binary.Write(&out, binary.BigEndian, l)
+			_ = binary.Write(&out, binary.BigEndian, r)
+		}
+	}
+	out.Write(make([]byte, 16))
+	out.Write(data.Bytes())
+	out.Write(metadataStartMarker)
+	encodeValue(&out, map[string]interface{}{
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(1700000000),
+		"database_type":               "Test-City",
+		"description":                 map[string]interface{}{"en": "test database"},
+		"ip_version":                  uint16(ipVersion),
+		"languages":                   []interface{}{"en"},
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint16(recordSize),
+	})
+	return out.Bytes()
+}
+
+// writeControl writes a control byte (and extended type/size bytes)
+func writeControl(buf *bytes.Buffer, typ dataType, size int) {
+	var first byte
+	var ext []byte
+	if typ > 7 {
+		ext = append(ext, byte(typ-7))
+	} else {
+		first = byte(typ) << 5
+	}
+
+	var sizeBytes []byte
+	switch {
+	case size < 29:
+		first |= byte(size)
+	case size < 285:
+		first |= 29
+		sizeBytes = []byte{byte(size - 29)}
+	case size < 65821:
+		first |= 30
+		s := size - 285
+		sizeBytes = []byte{byte(s >> 8), byte(s)}
+	default:
+		first |= 31
+		s := size - 65821
+		sizeBytes = []byte{byte(s >> 16), byte(s >> 8), byte(s)}
+	}
+
+	buf.WriteByte(first)
+	buf.Write(ext)
+	buf.Write(sizeBytes)
+}
+
+// encodeValue encodes a Go value using the MaxMind DB data format
+func encodeValue(buf *bytes.Buffer, v interface{}) {
+	switch val := v.(type) {
+	case string:
+		writeControl(buf, typeString, len(val))
+		buf.WriteString(val)
+	case float64:
+		writeControl(buf, typeDouble, 8)
+		_ = binary.Write(buf, binary.BigEndian, math.Float64bits(val))
+	case uint16:
+		writeControl(buf, typeUint16, 2)
+		_ = binary.Write(buf, binary.BigEndian, val)
+	case uint32:
+		writeControl(buf, typeUint32, 4)
+		_ = binary.Write(buf, binary.BigEndian, val)
+	case uint64:
+		writeControl(buf, typeUint64, 8)
+		_ = binary.Write(buf, binary.BigEndian, val)
+	case bool:
+		size := 0
+		if val {
+			size = 1
+		}
+		writeControl(buf, typeBool, size)
+	case []interface{}:
+		writeControl(buf, typeArray, len(val))
+		for _, item := range val {
+			encodeValue(buf, item)
+		}
+	case map[string]interface{}:
+		writeControl(buf, typeMap, len(val))
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			encodeValue(buf, k)
+			encodeValue(buf, val[k])
+		}
+	default:
+		panic("unsupported test value type")
+	}
+}
+
+func cityRecord(country, region, city string, lat, lon float64) map[string]interface{} {
+	return map[string]interface{}{
+		"country":      map[string]interface{}{"iso_code": country},
+		"subdivisions": []interface{}{map[string]interface{}{"iso_code": region}},
+		"city":         map[string]interface{}{"names": map[string]interface{}{"en": city}},
+		"location": map[string]interface{}{
+			"latitude":        lat,
+			"longitude":       lon,
+			"accuracy_radius": uint16(20),
+			"metro_code":      uint16(807),
+		},
+		"postal": map[string]interface{}{"code": "94105"},
+	}
+}
+
+func testNetworks() []testNetwork {
+	return []testNetwork{
+		{cidr: "81.2.69.0/24", record: cityRecord("GB", "ENG", "London", 51.5142, -0.0931)},
+		{cidr: "8.8.8.0/24", record: cityRecord("US", "CA", "San Francisco", 37.7749, -122.4194)},
+		{cidr: "2001:db8::/32", record: cityRecord("DE", "BE", "Berlin", 52.52, 13.405)},
+	}
+}
+
+func TestReader_LookupRecord(t *testing.T) {
+	for _, recordSize := range []int{24, 28, 32} {
+		reader, err := FromBytes(buildTestDB(t, 6, recordSize, testNetworks()))
+		if err != nil {
+			t.Fatalf("record size %d: FromBytes failed: %v", recordSize, err)
+		}
+
+		tests := []struct {
+			ip      string
+			country string
+			region  string
+			city    string
+		}{
+			{"81.2.69.160", "GB", "ENG", "London"},
+			{"8.8.8.8", "US", "CA", "San Francisco"},
+			{"2001:db8::1", "DE", "BE", "Berlin"},
+		}
+
+		for _, tt := range tests {
+			rec, err := reader.LookupRecord(net.ParseIP(tt.ip))
+			if err != nil {
+				t.Fatalf("record size %d: lookup %s failed: %v", recordSize, tt.ip, err)
+			}
+			if rec == nil {
+				t.Fatalf("record size %d: expected record for %s", recordSize, tt.ip)
+			}
+			if rec.Country != tt.country || rec.Region != tt.region || rec.City != tt.city {
+				t.Errorf("record size %d: %s = %+v, want %s/%s/%s", recordSize, tt.ip, rec, tt.country, tt.region, tt.city)
+			}
+		}
+	}
+}
+
+func TestReader_LookupRecord_LocationFields(t *testing.T) {
+	reader, err := FromBytes(buildTestDB(t, 6, 24, testNetworks()))
+	if err != nil {
+		t.Fatalf("FromBytes failed: %v", err)
+	}
+
+	rec, err := reader.LookupRecord(net.ParseIP("8.8.8.8"))
+	if err != nil || rec == nil {
+		t.Fatalf("lookup failed: %v", err)
+	}
+	if !rec.HasLatLon || rec.Lat != 37.7749 || rec.Lon != -122.4194 {
+		t.Errorf("unexpected coordinates: %+v", rec)
+	}
+	if rec.ZIP != "94105" || rec.Metro != "807" || rec.Accuracy != 20 {
+		t.Errorf("unexpected zip/metro/accuracy: %+v", rec)
+	}
+}
+
+func TestReader_Lookup_NotFound(t *testing.T) {
+	reader, err := FromBytes(buildTestDB(t, 6, 24, testNetworks()))
+	if err != nil {
+		t.Fatalf("FromBytes failed: %v", err)
+	}
+
+	for _, ip := range []string{"10.0.0.1", "127.0.0.1", "2001:db9::1"} {
+		rec, err := reader.LookupRecord(net.ParseIP(ip))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", ip, err)
+		}
+		if rec != nil {
+			t.Errorf("%s: expected no record, got %+v", ip, rec)
+		}
+	}
+}
+
+func TestReader_IPv4Database(t *testing.T) {
+	networks := []testNetwork{
+		{cidr: "81.2.69.0/24", record: map[string]interface{}{"country": map[string]interface{}{"iso_code": "GB"}}},
+	}
+	reader, err := FromBytes(buildTestDB(t, 4, 24, networks))
+	if err != nil {
+		t.Fatalf("FromBytes failed: %v", err)
+	}
+
+	rec, err := reader.LookupRecord(net.ParseIP("81.2.69.1"))
+	if err != nil || rec == nil || rec.Country != "GB" {
+		t.Errorf("expected GB, got %+v (err=%v)", rec, err)
+	}
+
+	if _, err := reader.LookupRecord(net.ParseIP("2001:db8::1")); err == nil {
+		t.Error("expected error looking up IPv6 in IPv4-only database")
+	}
+}
+
+func TestReader_RegisteredCountryFallback(t *testing.T) {
+	networks := []testNetwork{
+		{cidr: "1.2.3.0/24", record: map[string]interface{}{"registered_country": map[string]interface{}{"iso_code": "AU"}}},
+	}
+	reader, err := FromBytes(buildTestDB(t, 6, 24, networks))
+	if err != nil {
+		t.Fatalf("FromBytes failed: %v", err)
+	}
+
+	rec, err := reader.LookupRecord(net.ParseIP("1.2.3.4"))
+	if err != nil || rec == nil || rec.Country != "AU" {
+		t.Errorf("expected registered country AU, got %+v (err=%v)", rec, err)
+	}
+}
+
+func TestReader_Metadata(t *testing.T) {
+	reader, err := FromBytes(buildTestDB(t, 6, 28, testNetworks()))
+	if err != nil {
+		t.Fatalf("FromBytes failed: %v", err)
+	}
+
+	md := reader.Metadata()
+	if md.DatabaseType != "Test-City" || md.IPVersion != 6 || md.RecordSize != 28 {
+		t.Errorf("unexpected metadata: %+v", md)
+	}
+	if md.Description["en"] != "test database" || len(md.Languages) != 1 || md.Languages[0] != "en" {
+		t.Errorf("unexpected description/languages: %+v", md)
+	}
+}
+
+func TestFromBytes_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"Empty", nil},
+		{"No metadata marker", []byte("not a database")},
+		{"Metadata not a map", append(append([]byte{}, metadataStartMarker...), 0x41, 'x')},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FromBytes(tt.data)
+			if !errors.Is(err, ErrInvalidDatabase) {
+				t.Errorf("expected ErrInvalidDatabase, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFromBytes_UnsupportedRecordSize(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write(metadataStartMarker)
+	encodeValue(&buf, map[string]interface{}{
+		"binary_format_major_version": uint16(2),
+		"ip_version":                  uint16(6),
+		"node_count":                  uint32(0),
+		"record_size":                 uint16(20),
+	})
+
+	if _, err := FromBytes(buf.Bytes()); !errors.Is(err, ErrInvalidDatabase) {
+		t.Errorf("expected ErrInvalidDatabase for record size 20, got %v", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	if err := os.WriteFile(path, buildTestDB(t, 6, 24, testNetworks()), 0o600); err != nil {
+		t.Fatalf("failed to write test database: %v", err)
+	}
+
+	reader, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	rec, err := reader.LookupRecord(net.ParseIP("81.2.69.1"))
+	if err != nil || rec == nil || rec.Country != "GB" {
+		t.Errorf("expected GB, got %+v (err=%v)", rec, err)
+	}
+
+	if err := reader.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if _, err := reader.Lookup(net.ParseIP("81.2.69.1")); err == nil {
+		t.Error("expected error looking up on closed reader")
+	}
+
+	if _, err := Open(filepath.Join(t.TempDir(), "missing.mmdb")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestDecoder_Pointer(t *testing.T) {
+	// offset 0: "ab" string; offset 3: map{ "k": pointer->0 }
+	data := []byte{
+		0x42, 'a', 'b', // string "ab"
+		0xE1,      // map, 1 entry
+		0x41, 'k', // key "k"
+		0x20, 0x00, // pointer (size 0) -> offset 0
+	}
+	d := decoder{buf: data}
+
+	value, next, err := d.decode(3)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok || m["k"] != "ab" {
+		t.Errorf("expected map with k=ab, got %#v", value)
+	}
+	if next != uint(len(data)) {
+		t.Errorf("expected next offset %d, got %d", len(data), next)
+	}
+}
+
+func TestDecoder_ExtendedTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected interface{}
+	}{
+		{"int32 negative", []byte{0x04, 0x01, 0xFF, 0xFF, 0xFF, 0xFE}, int32(-2)},
+		{"uint64", []byte{0x02, 0x02, 0x01, 0x00}, uint64(256)},
+		{"bool true", []byte{0x01, 0x07}, true},
+		{"bool false", []byte{0x00, 0x07}, false},
+		{"float", append([]byte{0x04, 0x08}, float32Bytes(1.5)...), float32(1.5)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := decoder{buf: tt.data}
+			value, _, err := d.decode(0)
+			if err != nil {
+				t.Fatalf("decode failed: %v", err)
+			}
+			if value != tt.expected {
+				t.Errorf("expected %#v, got %#v", tt.expected, value)
+			}
+		})
+	}
+}
+
+func TestDecoder_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"Truncated string", []byte{0x45, 'a'}},
+		{"Pointer out of range", []byte{0x20, 0xFF}},
+		{"Invalid extended type", []byte{0x00, 0x00}},
+		{"Oversized map", []byte{0xFF, 0xFF, 0xFF, 0xFF}},
+		{"Bad double size", []byte{0x62, 0x00, 0x00}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := decoder{buf: tt.data}
+			if _, _, err := d.decode(0); !errors.Is(err, ErrInvalidDatabase) {
+				t.Errorf("expected ErrInvalidDatabase, got %v", err)
+			}
+		})
+	}
+}
+
+func float32Bytes(f float32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, math.Float32bits(f))
+	return b
+}
@@ -0,0 +1,77 @@
+package geoip
+
+import (
+	"net"
+	"strconv"
+)
+
+// Record holds the location fields PBS reads from GeoIP2/GeoLite2 Country and City databases
+type Record struct {
+	Country   string  // ISO 3166-1 alpha-2 country code (as stored by MaxMind)
+	Region    string  // ISO 3166-2 subdivision code without country prefix (e.g. "CA")
+	City      string  // City name (English)
+	ZIP       string  // Postal code
+	Metro     string  // Nielsen DMA code (US only)
+	Lat       float64 // Latitude
+	Lon       float64 // Longitude
+	Accuracy  int     // Accuracy radius in kilometers
+	HasLatLon bool    // Whether Lat/Lon were present in the record
+}
+
+// LookupRecord returns the location record for an IP address
+// Returns nil without error when the address is not in the database
+func (r *Reader) LookupRecord(ip net.IP) (*Record, error) {
+	raw, err := r.Lookup(ip)
+	if err != nil || raw == nil {
+		return nil, err
+	}
+
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	rec := &Record{
+		Country: stringAt(m, "country", "iso_code"),
+		City:    stringAt(m, "city", "names", "en"),
+		ZIP:     stringAt(m, "postal", "code"),
+	}
+
+	// Country databases without a physical country still carry the registered country
+	if rec.Country == "" {
+		rec.Country = stringAt(m, "registered_country", "iso_code")
+	}
+
+	if subdivisions, ok := m["subdivisions"].([]interface{}); ok && len(subdivisions) > 0 {
+		if first, ok := subdivisions[0].(map[string]interface{}); ok {
+			rec.Region = asString(first["iso_code"])
+		}
+	}
+
+	if location, ok := m["location"].(map[string]interface{}); ok {
+		lat, latOK := location["latitude"].(float64)
+		lon, lonOK := location["longitude"].(float64)
+		if latOK && lonOK {
+			rec.Lat, rec.Lon, rec.HasLatLon = lat, lon, true
+		}
+		if metro := asUint(location["metro_code"]); metro > 0 {
+			rec.Metro = strconv.FormatUint(metro, 10)
+		}
+		rec.Accuracy = int(asUint(location["accuracy_radius"])) //nolint:gosec // Radius is a small uint16
+	}
+
+	return rec, nil
+}
+
+// stringAt walks nested maps and returns the string at the given path
+func stringAt(m map[string]interface{}, path ...string) string {
+	var cur interface{} = m
+	for _, key := range path {
+		next, ok := cur.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		cur = next[key]
+	}
+	return asString(cur)
+}
@@ -0,0 +1,61 @@
+// Package ivtpolicy defines per-publisher overrides of the IVT (invalid traffic) configuration
+// Policies are stored with the publisher record by storage and applied by the middleware IVT detector.
+package ivtpolicy
+
+import (
+	"fmt"
+
+	"github.com/thenexusengine/tne_springwire/internal/geoip"
+)
+
+// Policy modes
+const (
+	ModeMonitor = "monitor" // Score, log and report, never block
+	ModeBlock   = "block"   // Block requests scoring at or above the threshold
+)
+
+// Policy overrides the global IVT configuration for one publisher
+// Unset fields inherit the global configuration.
+type Policy struct {
+	Mode             string   `json:"mode,omitempty"`              // "monitor" or "block"
+	BlockThreshold   int      `json:"block_threshold,omitempty"`   // Score (1-100) at which traffic is flagged; 0 inherits
+	AllowedCountries []string `json:"allowed_countries,omitempty"` // Country whitelist (alpha-2 or alpha-3); enables the geo check
+	RequireReferer   *bool    `json:"require_referer,omitempty"`   // Require a Referer header
+
+	// Behavioral thresholds per window (0 disables a check for this publisher)
+	MaxRequestsPerIP   *int64 `json:"max_requests_per_ip,omitempty"`
+	MaxRequestsPerIFA  *int64 `json:"max_requests_per_ifa,omitempty"`
+	MaxRequestsPerUser *int64 `json:"max_requests_per_user,omitempty"`
+	MaxDomainsPerIP    *int64 `json:"max_domains_per_ip,omitempty"`
+}
+
+// Validate checks the policy for values the detector can't apply
+func (p *Policy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	switch p.Mode {
+	case "", ModeMonitor, ModeBlock:
+	default:
+		return fmt.Errorf("ivt_policy.mode must be %q or %q, got %q", ModeMonitor, ModeBlock, p.Mode)
+	}
+	if p.BlockThreshold < 0 || p.BlockThreshold > 100 {
+		return fmt.Errorf("ivt_policy.block_threshold must be between 1 and 100 (0 inherits the global threshold), got %d", p.BlockThreshold)
+	}
+	for _, c := range p.AllowedCountries {
+		if len(geoip.ToAlpha2(c)) != 2 {
+			return fmt.Errorf("ivt_policy.allowed_countries: invalid country code %q", c)
+		}
+	}
+	for name, v := range map[string]*int64{
+		"max_requests_per_ip":   p.MaxRequestsPerIP,
+		"max_requests_per_ifa":  p.MaxRequestsPerIFA,
+		"max_requests_per_user": p.MaxRequestsPerUser,
+		"max_domains_per_ip":    p.MaxDomainsPerIP,
+	} {
+		if v != nil && *v < 0 {
+			return fmt.Errorf("ivt_policy.%s must not be negative, got %d", name, *v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package ivtpolicy
+
+import (
+	"strings"
+	"testing"
+)
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func TestPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *Policy
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"empty", &Policy{}, false},
+		{"block", &Policy{Mode: ModeBlock, BlockThreshold: 50, AllowedCountries: []string{"US", "GBR"}}, false},
+		{"monitor", &Policy{Mode: ModeMonitor, MaxRequestsPerIP: int64Ptr(0)}, false},
+		{"threshold bounds", &Policy{BlockThreshold: 100}, false},
+		{"unknown mode", &Policy{Mode: "quarantine"}, true},
+		{"threshold too high", &Policy{BlockThreshold: 101}, true},
+		{"negative threshold", &Policy{BlockThreshold: -1}, true},
+		{"invalid country", &Policy{AllowedCountries: []string{"USA", "Narnia"}}, true},
+		{"negative behavior threshold", &Policy{MaxDomainsPerIP: int64Ptr(-5)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error=%v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPolicy_ValidateThresholdMessage(t *testing.T) {
+	err := (&Policy{BlockThreshold: 101}).Validate()
+	if err == nil || !strings.Contains(err.Error(), "0 inherits") {
+		t.Errorf("expected the message to explain that 0 inherits, got %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
 )
 
 // Metrics holds all Prometheus metrics
@@ -48,6 +49,9 @@ type Metrics struct {
 	PrivacyFiltered *prometheus.CounterVec
 	ConsentSignals  *prometheus.CounterVec
 
+	// User sync metrics
+	UIDCookieVerifications *prometheus.CounterVec
+
 	// System metrics
 	ActiveConnections prometheus.Gauge
 	RateLimitRejected prometheus.Counter
@@ -277,6 +281,16 @@ func NewMetrics(namespace string) *Metrics {
 			[]string{"type", "has_consent"},
 		),
 
+		// User sync metrics
+		UIDCookieVerifications: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "uid_cookie_verifications_total",
+				Help:      "uids cookie verification results (valid, legacy, legacy_rejected, tampered, unknown_key, decrypt_failed)",
+			},
+			[]string{"result"},
+		),
+
 		// System metrics
 		ActiveConnections: prometheus.NewGauge(
 			prometheus.GaugeOpts{
@@ -370,6 +384,7 @@ func NewMetrics(namespace string) *Metrics {
 		m.IDRCircuitState,
 		m.PrivacyFiltered,
 		m.ConsentSignals,
+		m.UIDCookieVerifications,
 		m.ActiveConnections,
 		m.RateLimitRejected,
 		m.AuthFailures,
@@ -479,6 +494,12 @@ func (m *Metrics) RecordConsentSignal(signalType string, hasConsent bool) {
 	m.ConsentSignals.WithLabelValues(signalType, consent).Inc()
 }
 
+// RecordUIDCookieVerification records the verification result of a uids cookie
+// Implements usersync.CookieMetrics interface
+func (m *Metrics) RecordUIDCookieVerification(result string) {
+	m.UIDCookieVerifications.WithLabelValues(result).Inc()
+}
+
 // IncRateLimitRejected increments the rate limit rejected counter
 // Implements middleware.RateLimitMetrics interface
 func (m *Metrics) IncRateLimitRejected() {
@@ -512,6 +533,30 @@ func (m *Metrics) RecordMargin(publisher, bidder, mediaType string, originalPric
 	}
 }
 
+// BidderRevenue returns cumulative bid revenue per bidder across publishers and media types
+// Implements usersync.RevenueSource for cookie sync prioritization
+func (m *Metrics) BidderRevenue() map[string]float64 {
+	ch := make(chan prometheus.Metric, 64)
+	go func() {
+		m.RevenueTotal.Collect(ch)
+		close(ch)
+	}()
+
+	revenue := make(map[string]float64)
+	for metric := range ch {
+		var pb dto.Metric
+		if err := metric.Write(&pb); err != nil {
+			continue
+		}
+		for _, label := range pb.GetLabel() {
+			if label.GetName() == "bidder" {
+				revenue[label.GetValue()] += pb.GetCounter().GetValue()
+			}
+		}
+	}
+	return revenue
+}
+
 // RecordFloorAdjustment records when a floor price is adjusted via multiplier
 func (m *Metrics) RecordFloorAdjustment(publisher string) {
 	m.FloorAdjustments.WithLabelValues(publisher).Inc()
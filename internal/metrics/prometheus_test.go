@@ -158,6 +158,22 @@ func TestRecordMargin_ZeroPrice(t *testing.T) {
 	// Should not panic
 }
 
+func TestBidderRevenue(t *testing.T) {
+	m := testMetrics
+
+	m.RecordMargin("pub-a", "revenue_bidder", "banner", 1.25, 1.00, 0.25)
+	m.RecordMargin("pub-b", "revenue_bidder", "video", 2.00, 1.60, 0.40)
+	m.RecordMargin("pub-a", "other_revenue_bidder", "banner", 0.50, 0.40, 0.10)
+
+	revenue := m.BidderRevenue()
+	if revenue["revenue_bidder"] != 3.25 {
+		t.Errorf("Expected revenue summed across publishers and media types to be 3.25, got %f", revenue["revenue_bidder"])
+	}
+	if revenue["other_revenue_bidder"] != 0.50 {
+		t.Errorf("Expected 0.50, got %f", revenue["other_revenue_bidder"])
+	}
+}
+
 func TestRecordFloorAdjustment(t *testing.T) {
 	m := testMetrics
 	
@@ -440,6 +456,14 @@ func createTestMetricsWithAll(namespace string) *Metrics {
 			},
 			[]string{"type", "has_consent"},
 		),
+		UIDCookieVerifications: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "uid_cookie_verifications_total",
+				Help:      "uids cookie verification results",
+			},
+			[]string{"result"},
+		),
 		ActiveConnections: prometheus.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
@@ -663,6 +687,21 @@ func TestRecordConsentSignal(t *testing.T) {
 	}
 }
 
+func TestRecordUIDCookieVerification(t *testing.T) {
+	m := createTestMetricsWithAll("test_uid_cookie")
+
+	m.RecordUIDCookieVerification("valid")
+	m.RecordUIDCookieVerification("tampered")
+	m.RecordUIDCookieVerification("tampered")
+
+	if count := testutil.ToFloat64(m.UIDCookieVerifications.WithLabelValues("valid")); count != 1 {
+		t.Errorf("Expected 1 valid verification, got %v", count)
+	}
+	if count := testutil.ToFloat64(m.UIDCookieVerifications.WithLabelValues("tampered")); count != 2 {
+		t.Errorf("Expected 2 tampered verifications, got %v", count)
+	}
+}
+
 func TestSetBidderCircuitState(t *testing.T) {
 	m := createTestMetricsWithAll("test_circuit_state")
 
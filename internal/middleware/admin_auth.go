@@ -0,0 +1,320 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Admin roles, from least to most privileged
+const (
+	RoleViewer   = "viewer"   // Dashboards, reports and configuration reads
+	RoleOperator = "operator" // Viewer plus publisher changes and operational actions
+	RoleAdmin    = "admin"    // Operator plus API key management and the audit log
+)
+
+// AdminRoles lists every admin role
+var AdminRoles = []string{RoleViewer, RoleOperator, RoleAdmin}
+
+// AdminPermission is an action on admin endpoints granted through roles
+type AdminPermission string
+
+// Admin permissions
+const (
+	PermAdminRead       AdminPermission = "admin:read"       // Read dashboards, metrics, reports and configuration
+	PermPublishersWrite AdminPermission = "publishers:write" // Create, update and delete publishers
+	PermBiddersWrite    AdminPermission = "bidders:write"    // Configure, enable, disable and test-fire bidders
+	PermOperationsWrite AdminPermission = "operations:write" // Operational actions such as reloading syncers
+	PermAPIKeysRead     AdminPermission = "api_keys:read"    // List API keys
+	PermAPIKeysWrite    AdminPermission = "api_keys:write"   // Mint, rotate and revoke API keys
+	PermAuditRead       AdminPermission = "audit:read"       // Read the admin audit log
+	PermProfiling       AdminPermission = "profiling:read"   // pprof profiles on the admin listener
+)
+
+// rolePermissions maps each role to the permissions it grants
+var rolePermissions = map[string][]AdminPermission{
+	RoleViewer:   {PermAdminRead},
+	RoleOperator: {PermAdminRead, PermPublishersWrite, PermBiddersWrite, PermOperationsWrite, PermProfiling},
+	RoleAdmin:    {PermAdminRead, PermPublishersWrite, PermBiddersWrite, PermOperationsWrite, PermProfiling, PermAPIKeysRead, PermAPIKeysWrite, PermAuditRead},
+}
+
+// ValidRole reports whether role is a known admin role
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RoleHasPermission reports whether role grants perm
+func RoleHasPermission(role string, perm AdminPermission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// AdminRoute declares the permissions an admin endpoint requires
+type AdminRoute struct {
+	Read  AdminPermission // Needed for GET and HEAD ("" = public, for static pages only)
+	Write AdminPermission // Needed for other methods ("" = Read; the endpoint never changes state)
+}
+
+// permission returns the permission a method needs and whether the call changes state
+func (rt AdminRoute) permission(method string) (AdminPermission, bool) {
+	if rt.Write == "" || method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+		return rt.Read, false
+	}
+	return rt.Write, true
+}
+
+// AdminAuthConfig holds admin authentication configuration
+type AdminAuthConfig struct {
+	Enabled    bool   // Require API keys with an admin role on /admin/* (independent of AUTH_ENABLED)
+	HeaderName string // Header to check for API key (default: X-API-Key)
+}
+
+// DefaultAdminAuthConfig returns default admin auth configuration
+// SECURITY: Enabled unless ADMIN_AUTH_ENABLED=false (development only)
+func DefaultAdminAuthConfig() *AdminAuthConfig {
+	return &AdminAuthConfig{
+		Enabled:    os.Getenv("ADMIN_AUTH_ENABLED") != "false",
+		HeaderName: "X-API-Key",
+	}
+}
+
+// AuditEntry records one state-changing admin call
+type AuditEntry struct {
+	ID         int64           `json:"id,omitempty"`
+	Actor      string          `json:"actor"` // Key name, publisher ID, or "anonymous" without admin auth
+	ActorKeyID string          `json:"actor_key_id,omitempty"`
+	ActorRole  string          `json:"actor_role,omitempty"`
+	Method     string          `json:"method"`
+	Path       string          `json:"path"`
+	StatusCode int             `json:"status_code"`
+	Before     json.RawMessage `json:"before,omitempty"` // State before the change, when the handler reports it
+	After      json.RawMessage `json:"after,omitempty"`  // State after the change, or the request body
+	RemoteIP   string          `json:"remote_ip,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditSink persists admin audit entries
+type AuditSink interface {
+	Record(ctx context.Context, entry *AuditEntry) error
+}
+
+const auditContextKey contextKey = "admin_audit"
+
+// maxAuditBodySize caps the request body kept when a handler doesn't report a diff
+const maxAuditBodySize = 64 * 1024
+
+// RecordAuditChange attaches the state before and after a change to the request's audit entry
+// Either side may be nil (creates have no before, deletes no after). Requests outside
+// AdminAuth, or that don't change state, have no entry and are ignored.
+func RecordAuditChange(ctx context.Context, before, after interface{}) {
+	entry, _ := ctx.Value(auditContextKey).(*AuditEntry)
+	if entry == nil {
+		return
+	}
+	entry.Before = marshalAuditState(before)
+	entry.After = marshalAuditState(after)
+}
+
+// marshalAuditState encodes one side of a diff, nil for nothing
+func marshalAuditState(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to encode admin audit state")
+		return nil
+	}
+	return data
+}
+
+// AdminAuth protects admin endpoints with role-based permissions and audits changes
+type AdminAuth struct {
+	config *AdminAuthConfig
+	auth   *Auth // Resolves keys through the same stores and cache as the public API
+
+	mu    sync.RWMutex
+	audit AuditSink
+}
+
+// NewAdminAuth creates admin auth middleware that authenticates keys through auth
+func NewAdminAuth(config *AdminAuthConfig, auth *Auth) *AdminAuth {
+	if config == nil {
+		config = DefaultAdminAuthConfig()
+	}
+	if config.HeaderName == "" {
+		config.HeaderName = "X-API-Key"
+	}
+	return &AdminAuth{config: config, auth: auth}
+}
+
+// SetAuditSink sets where audit entries are persisted (nil logs them only)
+func (a *AdminAuth) SetAuditSink(sink AuditSink) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.audit = sink
+}
+
+// IsEnabled returns whether admin endpoints require authentication
+func (a *AdminAuth) IsEnabled() bool {
+	return a.config.Enabled
+}
+
+// Protect wraps an admin endpoint with the permissions of route
+// State-changing calls are written to the audit log once the handler returns.
+func (a *AdminAuth) Protect(route AdminRoute, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		perm, mutating := route.permission(r.Method)
+		if perm == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var identity *APIKeyIdentity
+		if a.config.Enabled {
+			apiKey := apiKeyFromRequest(r, a.config.HeaderName)
+			if apiKey == "" {
+				a.auth.recordAuthFailure()
+				http.Error(w, `{"error":"missing API key"}`, http.StatusUnauthorized)
+				return
+			}
+			identity = a.auth.authenticate(r.Context(), apiKey)
+			if identity == nil {
+				a.auth.recordAuthFailure()
+				http.Error(w, `{"error":"invalid API key"}`, http.StatusForbidden)
+				return
+			}
+			scope := ScopeAdminRead
+			if mutating {
+				scope = ScopeAdminWrite
+			}
+			if !identity.HasScope(scope) {
+				a.auth.recordAuthFailure()
+				http.Error(w, `{"error":"API key lacks the `+scope+` scope"}`, http.StatusForbidden)
+				return
+			}
+			if !RoleHasPermission(identity.Role, perm) {
+				a.auth.recordAuthFailure()
+				log.Warn().
+					Str("key_id", identity.KeyID).
+					Str("role", identity.Role).
+					Str("permission", string(perm)).
+					Str("path", r.URL.Path).
+					Msg("Admin request denied")
+				http.Error(w, `{"error":"permission `+string(perm)+` required"}`, http.StatusForbidden)
+				return
+			}
+			r = r.WithContext(ContextWithAPIKey(r.Context(), identity))
+		}
+
+		if !mutating {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		entry := newAuditEntry(r, identity)
+		body := captureAuditBody(r)
+		recorder := &auditResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), auditContextKey, entry)))
+
+		entry.StatusCode = recorder.statusCode
+		if entry.Before == nil && entry.After == nil && json.Valid(body) {
+			entry.After = body
+		}
+		a.record(entry)
+	})
+}
+
+// newAuditEntry starts an audit entry for a request
+func newAuditEntry(r *http.Request, identity *APIKeyIdentity) *AuditEntry {
+	entry := &AuditEntry{
+		Actor:     "anonymous",
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		RemoteIP:  extractIP(r.RemoteAddr),
+		CreatedAt: time.Now().UTC(),
+	}
+	if identity != nil {
+		entry.ActorKeyID = identity.KeyID
+		entry.ActorRole = identity.Role
+		entry.Actor = identity.Name
+		if entry.Actor == "" {
+			entry.Actor = identity.PublisherID
+		}
+	}
+	return entry
+}
+
+// captureAuditBody reads up to maxAuditBodySize of the request body and restores it for the handler
+func captureAuditBody(r *http.Request) []byte {
+	if r.Body == nil {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxAuditBodySize+1))
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+	if err != nil || len(body) > maxAuditBodySize {
+		return nil
+	}
+	return body
+}
+
+// record persists an audit entry, logging it if there is no sink or the write fails
+func (a *AdminAuth) record(entry *AuditEntry) {
+	a.mu.RLock()
+	sink := a.audit
+	a.mu.RUnlock()
+
+	event, msg := log.Info(), "Admin audit"
+	if sink != nil {
+		// The request may already be cancelled; the audit write must still happen
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		err := sink.Record(ctx, entry)
+		if err == nil {
+			return
+		}
+		event, msg = log.Error().Err(err), "Failed to persist admin audit entry"
+	}
+
+	event.
+		Str("actor", entry.Actor).
+		Str("actor_key_id", entry.ActorKeyID).
+		Str("actor_role", entry.ActorRole).
+		Str("method", entry.Method).
+		Str("path", entry.Path).
+		Int("status", entry.StatusCode).
+		RawJSON("before", rawJSONOrNull(entry.Before)).
+		RawJSON("after", rawJSONOrNull(entry.After)).
+		Msg(msg)
+}
+
+// rawJSONOrNull returns JSON null for an empty document
+func rawJSONOrNull(data json.RawMessage) []byte {
+	if len(data) == 0 {
+		return []byte("null")
+	}
+	return data
+}
+
+// auditResponseWriter captures the status code of an audited call
+type auditResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (w *auditResponseWriter) WriteHeader(code int) {
+	w.statusCode = code
+	w.ResponseWriter.WriteHeader(code)
+}
@@ -0,0 +1,237 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeAuditSink collects audit entries (test helper)
+type fakeAuditSink struct {
+	entries []*AuditEntry
+	err     error
+}
+
+func (f *fakeAuditSink) Record(ctx context.Context, entry *AuditEntry) error {
+	f.entries = append(f.entries, entry)
+	return f.err
+}
+
+func TestRoleHasPermission(t *testing.T) {
+	tests := []struct {
+		role string
+		perm AdminPermission
+		want bool
+	}{
+		{RoleViewer, PermAdminRead, true},
+		{RoleViewer, PermPublishersWrite, false},
+		{RoleOperator, PermPublishersWrite, true},
+		{RoleOperator, PermOperationsWrite, true},
+		{RoleViewer, PermBiddersWrite, false},
+		{RoleOperator, PermBiddersWrite, true},
+		{RoleViewer, PermProfiling, false},
+		{RoleOperator, PermProfiling, true},
+		{RoleOperator, PermAPIKeysWrite, false},
+		{RoleOperator, PermAuditRead, false},
+		{RoleAdmin, PermAPIKeysWrite, true},
+		{RoleAdmin, PermAuditRead, true},
+		{"", PermAdminRead, false},
+		{"root", PermAdminRead, false},
+	}
+	for _, tt := range tests {
+		if got := RoleHasPermission(tt.role, tt.perm); got != tt.want {
+			t.Errorf("RoleHasPermission(%q, %s) = %v, want %v", tt.role, tt.perm, got, tt.want)
+		}
+	}
+}
+
+func TestAdminAuth_Permissions(t *testing.T) {
+	now := time.Now()
+	store := newTestAPIKeyStore(t, &now)
+	ctx := context.Background()
+
+	mint := func(role string, scopes ...string) string {
+		raw, _, err := store.Mint(ctx, APIKeySpec{PublisherID: "ops", Name: role, Scopes: scopes, Role: role})
+		if err != nil {
+			t.Fatalf("Mint failed: %v", err)
+		}
+		return raw
+	}
+	viewer := mint(RoleViewer, ScopeAdminRead)
+	operator := mint(RoleOperator, ScopeAdminRead, ScopeAdminWrite)
+	readOnlyOperator := mint(RoleOperator, ScopeAdminRead)
+	admin := mint(RoleAdmin, ScopeAdminRead, ScopeAdminWrite)
+	auctionKey, _, _ := store.Mint(ctx, APIKeySpec{PublisherID: "pub1", Scopes: []string{ScopeAuction}})
+
+	// Admin auth applies even with the public API key check disabled
+	auth := NewAuth(&AuthConfig{Enabled: false})
+	auth.SetKeyStore(store)
+	adminAuth := NewAdminAuth(&AdminAuthConfig{Enabled: true}, auth)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	publishers := adminAuth.Protect(AdminRoute{Read: PermAdminRead, Write: PermPublishersWrite}, ok)
+	apiKeys := adminAuth.Protect(AdminRoute{Read: PermAPIKeysRead, Write: PermAPIKeysWrite}, ok)
+	dashboard := adminAuth.Protect(AdminRoute{}, ok)
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		method  string
+		key     string
+		want    int
+	}{
+		{"missing key", publishers, http.MethodGet, "", http.StatusUnauthorized},
+		{"invalid key", publishers, http.MethodGet, "tne_0123_nope", http.StatusForbidden},
+		{"auction key", publishers, http.MethodGet, auctionKey, http.StatusForbidden},
+		{"viewer reads", publishers, http.MethodGet, viewer, http.StatusOK},
+		{"viewer writes", publishers, http.MethodPut, viewer, http.StatusForbidden},
+		{"operator writes", publishers, http.MethodPut, operator, http.StatusOK},
+		{"operator without write scope", publishers, http.MethodPut, readOnlyOperator, http.StatusForbidden},
+		{"operator lists keys", apiKeys, http.MethodGet, operator, http.StatusForbidden},
+		{"admin mints keys", apiKeys, http.MethodPost, admin, http.StatusOK},
+		{"public page", dashboard, http.MethodGet, "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/admin/test", nil)
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			rr := httptest.NewRecorder()
+			tt.handler.ServeHTTP(rr, req)
+			if rr.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestAdminAuth_LegacyKeyRole(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	send := func(role string) int {
+		auth := NewAuth(&AuthConfig{APIKeys: map[string]string{"legacy": "ops"}, LegacyKeyRole: role})
+		handler := NewAdminAuth(&AdminAuthConfig{Enabled: true}, auth).Protect(AdminRoute{Read: PermAdminRead, Write: PermAPIKeysWrite}, ok)
+		req := httptest.NewRequest(http.MethodPost, "/admin/api-keys", nil)
+		req.Header.Set("Authorization", "Bearer legacy")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if code := send(RoleAdmin); code != http.StatusOK {
+		t.Errorf("expected legacy admin key allowed, got %d", code)
+	}
+	if code := send(RoleViewer); code != http.StatusForbidden {
+		t.Errorf("expected legacy viewer key denied, got %d", code)
+	}
+	if code := send(""); code != http.StatusForbidden {
+		t.Errorf("expected legacy key without a role denied, got %d", code)
+	}
+}
+
+func TestParseLegacyKeyRole(t *testing.T) {
+	tests := map[string]string{
+		"":         RoleAdmin,
+		"none":     "",
+		"viewer":   RoleViewer,
+		"operator": RoleOperator,
+		"root":     "",
+	}
+	for input, want := range tests {
+		if got := parseLegacyKeyRole(input); got != want {
+			t.Errorf("parseLegacyKeyRole(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestAdminAuth_Audit(t *testing.T) {
+	now := time.Now()
+	store := newTestAPIKeyStore(t, &now)
+	raw, key, _ := store.Mint(context.Background(), APIKeySpec{
+		PublisherID: "ops",
+		Name:        "ops-key",
+		Scopes:      []string{ScopeAdminRead, ScopeAdminWrite},
+		Role:        RoleOperator,
+	})
+
+	auth := NewAuth(&AuthConfig{})
+	auth.SetKeyStore(store)
+	adminAuth := NewAdminAuth(&AdminAuthConfig{Enabled: true}, auth)
+	sink := &fakeAuditSink{}
+	adminAuth.SetAuditSink(sink)
+
+	var seenBody string
+	reportsDiff := adminAuth.Protect(AdminRoute{Read: PermAdminRead, Write: PermPublishersWrite}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		RecordAuditChange(r.Context(), map[string]string{"status": "active"}, map[string]string{"status": "paused"})
+		w.WriteHeader(http.StatusOK)
+	}))
+	bodyOnly := adminAuth.Protect(AdminRoute{Read: PermAdminRead, Write: PermOperationsWrite}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		seenBody = string(body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	send := func(handler http.Handler, method, body string) {
+		req := httptest.NewRequest(method, "/admin/test", strings.NewReader(body))
+		req.Header.Set("X-API-Key", raw)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	send(reportsDiff, http.MethodPut, `{"status":"paused"}`)
+	send(bodyOnly, http.MethodPost, `{"force":true}`)
+	send(reportsDiff, http.MethodGet, "")
+
+	if len(sink.entries) != 2 {
+		t.Fatalf("expected 2 audit entries (reads are not audited), got %d", len(sink.entries))
+	}
+
+	diff := sink.entries[0]
+	if diff.Actor != "ops-key" || diff.ActorKeyID != key.ID || diff.ActorRole != RoleOperator {
+		t.Errorf("unexpected actor %+v", diff)
+	}
+	if string(diff.Before) != `{"status":"active"}` || string(diff.After) != `{"status":"paused"}` {
+		t.Errorf("expected reported diff, got %s -> %s", diff.Before, diff.After)
+	}
+
+	fallback := sink.entries[1]
+	if seenBody != `{"force":true}` {
+		t.Errorf("expected handler to still read the body, got %q", seenBody)
+	}
+	if fallback.Before != nil || string(fallback.After) != `{"force":true}` || fallback.StatusCode != http.StatusAccepted {
+		t.Errorf("expected request body as the after state, got %+v", fallback)
+	}
+
+	// A failing sink doesn't fail the request
+	sink.err = errors.New("db down")
+	req := httptest.NewRequest(http.MethodPut, "/admin/test", nil)
+	req.Header.Set("X-API-Key", raw)
+	rr := httptest.NewRecorder()
+	reportsDiff.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected 200 despite audit failure, got %d", rr.Code)
+	}
+}
+
+func TestAdminAuth_Disabled(t *testing.T) {
+	adminAuth := NewAdminAuth(&AdminAuthConfig{Enabled: false}, NewAuth(&AuthConfig{}))
+	sink := &fakeAuditSink{}
+	adminAuth.SetAuditSink(sink)
+
+	handler := adminAuth.Protect(AdminRoute{Read: PermAdminRead, Write: PermPublishersWrite}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/publishers", strings.NewReader(`{"id":"pub1"}`)))
+
+	if rr.Code != http.StatusCreated {
+		t.Errorf("expected request allowed without admin auth, got %d", rr.Code)
+	}
+	if len(sink.entries) != 1 || sink.entries[0].Actor != "anonymous" {
+		t.Errorf("expected anonymous audit entry, got %+v", sink.entries)
+	}
+}
@@ -0,0 +1,480 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// API key scopes
+const (
+	ScopeAuction    = "auction"     // Public endpoints such as /openrtb2/auction
+	ScopeDebug      = "debug"       // Auction debug output (?debug=1)
+	ScopeAdminRead  = "admin-read"  // GET requests to /admin/*
+	ScopeAdminWrite = "admin-write" // Mutating requests to /admin/*
+)
+
+// AllAPIKeyScopes lists every scope a key can be granted
+var AllAPIKeyScopes = []string{ScopeAuction, ScopeDebug, ScopeAdminRead, ScopeAdminWrite}
+
+// Redis keys for hashed API keys
+const (
+	// #nosec G101 -- Redis key name, not a credential
+	RedisAPIKeyRecordsHash = "tne_catalyst:api_key_records" // hash: key_id -> APIKey JSON
+	// #nosec G101 -- Redis key name, not a credential
+	RedisAPIKeyLastUsedHash = "tne_catalyst:api_key_last_used" // hash: key_id -> unix seconds
+)
+
+// APIKeyPrefix marks keys minted by the APIKeyStore: "tne_<id>_<secret>"
+const APIKeyPrefix = "tne_"
+
+// Errors returned by the APIKeyStore
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrAPIKeyInactive = errors.New("api key is expired or revoked")
+)
+
+// APIKey is the stored metadata of a minted key; the secret itself is never stored
+type APIKey struct {
+	ID          string     `json:"id"`
+	PublisherID string     `json:"publisher_id"`
+	Name        string     `json:"name,omitempty"`
+	Scopes      []string   `json:"scopes"`
+	Role        string     `json:"role,omitempty"` // Admin role (RoleViewer, RoleOperator, RoleAdmin); "" = no admin access
+	Salt        string     `json:"salt"`           // Hex-encoded random salt
+	Hash        string     `json:"hash"`           // Hex-encoded SHA-256(salt || secret)
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`   // nil = never expires
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`   // Set when revoked
+	RotatedFrom string     `json:"rotated_from,omitempty"` // ID of the key this one replaced
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"` // Kept in RedisAPIKeyLastUsedHash
+}
+
+// HasScope reports whether the key grants scope
+func (k *APIKey) HasScope(scope string) bool {
+	return k != nil && hasScope(k.Scopes, scope)
+}
+
+// Active reports whether the key can authenticate at now
+func (k *APIKey) Active(now time.Time) bool {
+	if k == nil || k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// matches compares a secret against the stored salted hash in constant time
+func (k *APIKey) matches(secret string) bool {
+	salt, err := hex.DecodeString(k.Salt)
+	if err != nil {
+		return false
+	}
+	want, err := hex.DecodeString(k.Hash)
+	if err != nil {
+		return false
+	}
+	got := hashAPIKeySecret(salt, secret)
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// hashAPIKeySecret returns SHA-256(salt || secret)
+// Secrets are 256-bit random values, so a fast hash is sufficient.
+func hashAPIKeySecret(salt []byte, secret string) []byte {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(secret))
+	return h.Sum(nil)
+}
+
+// APIKeyIdentity is the authenticated caller of a request, stored in its context
+type APIKeyIdentity struct {
+	KeyID       string   // Empty for legacy keys from API_KEYS or RedisAPIKeysHash
+	PublisherID string   // Publisher the key belongs to
+	Scopes      []string // Scopes granted to the key
+	Role        string   // Admin role; "" = no admin access
+	Name        string   // Key name, used as the actor in the admin audit log
+}
+
+// HasScope reports whether the identity grants scope (false for nil)
+func (i *APIKeyIdentity) HasScope(scope string) bool {
+	return i != nil && hasScope(i.Scopes, scope)
+}
+
+const apiKeyContextKey contextKey = "api_key"
+
+// ContextWithAPIKey returns a copy of ctx carrying the authenticated API key identity
+func ContextWithAPIKey(ctx context.Context, identity *APIKeyIdentity) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey, identity)
+}
+
+// APIKeyFromContext returns the authenticated API key identity, or nil if the request had none
+func APIKeyFromContext(ctx context.Context) *APIKeyIdentity {
+	identity, _ := ctx.Value(apiKeyContextKey).(*APIKeyIdentity)
+	return identity
+}
+
+// hasScope reports whether scopes contains scope
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateScopes checks that every scope is known and that there is at least one
+func ValidateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+	for _, s := range scopes {
+		if !hasScope(AllAPIKeyScopes, s) {
+			return fmt.Errorf("unknown scope %q (valid: %s)", s, strings.Join(AllAPIKeyScopes, ", "))
+		}
+	}
+	return nil
+}
+
+// parseScopes parses "auction,debug" into scopes, skipping unknown entries
+// "none" yields an empty, non-nil list; an empty value yields nil.
+func parseScopes(envValue string) []string {
+	envValue = strings.TrimSpace(envValue)
+	if envValue == "" {
+		return nil
+	}
+	scopes := []string{}
+	if envValue == "none" {
+		return scopes
+	}
+	for _, s := range strings.Split(envValue, ",") {
+		s = strings.TrimSpace(s)
+		if !hasScope(AllAPIKeyScopes, s) {
+			log.Warn().Str("scope", s).Msg("Ignoring unknown API key scope")
+			continue
+		}
+		if !hasScope(scopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+// splitAPIKey splits "tne_<id>_<secret>" into its ID and secret
+func splitAPIKey(raw string) (id, secret string, ok bool) {
+	if !strings.HasPrefix(raw, APIKeyPrefix) {
+		return "", "", false
+	}
+	id, secret, ok = strings.Cut(strings.TrimPrefix(raw, APIKeyPrefix), "_")
+	if !ok || id == "" || secret == "" {
+		return "", "", false
+	}
+	return id, secret, true
+}
+
+// APIKeyStoreClient defines the Redis operations used by the APIKeyStore
+type APIKeyStoreClient interface {
+	HGet(ctx context.Context, key, field string) (string, error)
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	HSet(ctx context.Context, key, field string, value interface{}) error
+}
+
+// APIKeySpec describes a key to mint
+type APIKeySpec struct {
+	PublisherID string
+	Name        string
+	Scopes      []string
+	Role        string        // Admin role ("" = none)
+	TTL         time.Duration // 0 = never expires
+}
+
+// APIKeyStore mints, rotates, revokes and authenticates hashed API keys in Redis
+type APIKeyStore struct {
+	client APIKeyStoreClient
+	now    func() time.Time
+
+	mu        sync.RWMutex
+	listeners []func(keyID string)
+}
+
+// NewAPIKeyStore creates an API key store backed by Redis
+func NewAPIKeyStore(client APIKeyStoreClient) *APIKeyStore {
+	return &APIKeyStore{
+		client: client,
+		now:    time.Now,
+	}
+}
+
+// OnChange registers fn to be called with the ID of every key that is revoked or rotated
+func (s *APIKeyStore) OnChange(fn func(keyID string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// notify tells listeners that a key changed
+func (s *APIKeyStore) notify(keyID string) {
+	s.mu.RLock()
+	listeners := s.listeners
+	s.mu.RUnlock()
+	for _, fn := range listeners {
+		fn(keyID)
+	}
+}
+
+// Mint creates a new key and returns the raw key, which is only available here
+func (s *APIKeyStore) Mint(ctx context.Context, spec APIKeySpec) (string, *APIKey, error) {
+	return s.mint(ctx, spec, "")
+}
+
+// mint creates and stores a key, recording the key it replaces if any
+func (s *APIKeyStore) mint(ctx context.Context, spec APIKeySpec, rotatedFrom string) (string, *APIKey, error) {
+	if spec.PublisherID == "" {
+		return "", nil, errors.New("publisher_id is required")
+	}
+	if err := ValidateScopes(spec.Scopes); err != nil {
+		return "", nil, err
+	}
+	if spec.TTL < 0 {
+		return "", nil, errors.New("ttl must not be negative")
+	}
+	if spec.Role != "" && !ValidRole(spec.Role) {
+		return "", nil, fmt.Errorf("unknown role %q (valid: %s)", spec.Role, strings.Join(AdminRoles, ", "))
+	}
+
+	idBytes, err := randomBytes(8)
+	if err != nil {
+		return "", nil, err
+	}
+	secretBytes, err := randomBytes(32)
+	if err != nil {
+		return "", nil, err
+	}
+	salt, err := randomBytes(16)
+	if err != nil {
+		return "", nil, err
+	}
+
+	id := hex.EncodeToString(idBytes)
+	secret := base64.RawURLEncoding.EncodeToString(secretBytes)
+	now := s.now().UTC()
+
+	key := &APIKey{
+		ID:          id,
+		PublisherID: spec.PublisherID,
+		Name:        spec.Name,
+		Scopes:      append([]string(nil), spec.Scopes...),
+		Role:        spec.Role,
+		Salt:        hex.EncodeToString(salt),
+		Hash:        hex.EncodeToString(hashAPIKeySecret(salt, secret)),
+		CreatedAt:   now,
+		RotatedFrom: rotatedFrom,
+	}
+	if spec.TTL > 0 {
+		expiresAt := now.Add(spec.TTL)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := s.save(ctx, key); err != nil {
+		return "", nil, err
+	}
+	return APIKeyPrefix + id + "_" + secret, key, nil
+}
+
+// Get returns a key's metadata
+func (s *APIKeyStore) Get(ctx context.Context, id string) (*APIKey, error) {
+	value, err := s.client.HGet(ctx, RedisAPIKeyRecordsHash, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load api key: %w", err)
+	}
+	if value == "" {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	key, err := decodeAPIKey(value)
+	if err != nil {
+		return nil, err
+	}
+	if lastUsed, err := s.client.HGet(ctx, RedisAPIKeyLastUsedHash, id); err == nil {
+		key.LastUsedAt = parseUnixTime(lastUsed)
+	}
+	return key, nil
+}
+
+// List returns all keys, or only a publisher's keys, oldest first
+func (s *APIKeyStore) List(ctx context.Context, publisherID string) ([]*APIKey, error) {
+	records, err := s.client.HGetAll(ctx, RedisAPIKeyRecordsHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	lastUsed, err := s.client.HGetAll(ctx, RedisAPIKeyLastUsedHash)
+	if err != nil {
+		lastUsed = nil // Metadata only; the keys are still listed
+	}
+
+	keys := make([]*APIKey, 0, len(records))
+	for id, value := range records {
+		key, err := decodeAPIKey(value)
+		if err != nil {
+			log.Warn().Err(err).Str("key_id", id).Msg("Skipping unreadable API key record")
+			continue
+		}
+		if publisherID != "" && key.PublisherID != publisherID {
+			continue
+		}
+		key.LastUsedAt = parseUnixTime(lastUsed[id])
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].ID < keys[j].ID
+		}
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// Revoke disables a key immediately
+func (s *APIKeyStore) Revoke(ctx context.Context, id string) (*APIKey, error) {
+	key, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt == nil {
+		now := s.now().UTC()
+		key.RevokedAt = &now
+		if err := s.save(ctx, key); err != nil {
+			return nil, err
+		}
+	}
+	s.notify(id)
+	return key, nil
+}
+
+// Rotate mints a replacement for a key with the same publisher, name, scopes and lifetime
+// The old key keeps working for overlap so clients can switch without downtime.
+func (s *APIKeyStore) Rotate(ctx context.Context, id string, overlap time.Duration) (string, *APIKey, error) {
+	if overlap < 0 {
+		return "", nil, errors.New("overlap must not be negative")
+	}
+	old, err := s.Get(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+	now := s.now().UTC()
+	if !old.Active(now) {
+		return "", nil, ErrAPIKeyInactive
+	}
+
+	spec := APIKeySpec{PublisherID: old.PublisherID, Name: old.Name, Scopes: old.Scopes, Role: old.Role}
+	if old.ExpiresAt != nil {
+		spec.TTL = old.ExpiresAt.Sub(old.CreatedAt)
+	}
+	raw, replacement, err := s.mint(ctx, spec, old.ID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Shorten the old key's lifetime to the overlap, never extend it
+	cutoff := now.Add(overlap)
+	if old.ExpiresAt == nil || cutoff.Before(*old.ExpiresAt) {
+		old.ExpiresAt = &cutoff
+		if err := s.save(ctx, old); err != nil {
+			return "", nil, err
+		}
+	}
+	s.notify(old.ID)
+	return raw, replacement, nil
+}
+
+// Authenticate resolves a raw key minted by this store
+// It returns ErrAPIKeyNotFound for unknown keys or wrong secrets and ErrAPIKeyInactive
+// for expired or revoked keys; other errors mean Redis could not be reached.
+func (s *APIKeyStore) Authenticate(ctx context.Context, raw string) (*APIKey, error) {
+	id, secret, ok := splitAPIKey(raw)
+	if !ok {
+		return nil, ErrAPIKeyNotFound
+	}
+	value, err := s.client.HGet(ctx, RedisAPIKeyRecordsHash, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load api key: %w", err)
+	}
+	if value == "" {
+		return nil, ErrAPIKeyNotFound
+	}
+	key, err := decodeAPIKey(value)
+	if err != nil {
+		return nil, err
+	}
+	if !key.matches(secret) {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	now := s.now().UTC()
+	if !key.Active(now) {
+		return nil, ErrAPIKeyInactive
+	}
+
+	// Callers cache successful lookups, so this is written at most once per cache period
+	if err := s.client.HSet(ctx, RedisAPIKeyLastUsedHash, id, strconv.FormatInt(now.Unix(), 10)); err != nil {
+		log.Debug().Err(err).Str("key_id", id).Msg("Failed to record API key use")
+	}
+	key.LastUsedAt = &now
+	return key, nil
+}
+
+// save writes a key record
+func (s *APIKeyStore) save(ctx context.Context, key *APIKey) error {
+	stored := *key
+	stored.LastUsedAt = nil // Kept separately so authentication never rewrites records
+	data, err := json.Marshal(&stored)
+	if err != nil {
+		return fmt.Errorf("failed to encode api key: %w", err)
+	}
+	if err := s.client.HSet(ctx, RedisAPIKeyRecordsHash, key.ID, string(data)); err != nil {
+		return fmt.Errorf("failed to store api key: %w", err)
+	}
+	return nil
+}
+
+// decodeAPIKey parses a stored key record
+func decodeAPIKey(value string) (*APIKey, error) {
+	var key APIKey
+	if err := json.Unmarshal([]byte(value), &key); err != nil {
+		return nil, fmt.Errorf("invalid api key record: %w", err)
+	}
+	return &key, nil
+}
+
+// parseUnixTime parses unix seconds, returning nil for empty or invalid values
+func parseUnixTime(value string) *time.Time {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds <= 0 {
+		return nil
+	}
+	t := time.Unix(seconds, 0).UTC()
+	return &t
+}
+
+// randomBytes returns n bytes from crypto/rand
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return b, nil
+}
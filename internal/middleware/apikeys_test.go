@@ -0,0 +1,351 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestAPIKeyStore returns a store on miniredis driven by a settable clock (test helper)
+func newTestAPIKeyStore(t *testing.T, now *time.Time) *APIKeyStore {
+	t.Helper()
+	client, _ := setupBehaviorRedis(t)
+	store := NewAPIKeyStore(client)
+	store.now = func() time.Time { return *now }
+	return store
+}
+
+func TestAPIKeyStore_MintAndAuthenticate(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := newTestAPIKeyStore(t, &now)
+	ctx := context.Background()
+
+	raw, key, err := store.Mint(ctx, APIKeySpec{PublisherID: "pub1", Name: "prebid", Scopes: []string{ScopeAuction}, TTL: time.Hour})
+	if err != nil {
+		t.Fatalf("Mint failed: %v", err)
+	}
+	if !strings.HasPrefix(raw, APIKeyPrefix+key.ID+"_") {
+		t.Errorf("expected raw key to embed the key ID, got %s", raw)
+	}
+	if strings.Contains(key.Hash, raw) || key.Salt == "" {
+		t.Error("expected only a salted hash to be stored")
+	}
+
+	got, err := store.Authenticate(ctx, raw)
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if got.PublisherID != "pub1" || !got.HasScope(ScopeAuction) || got.HasScope(ScopeDebug) {
+		t.Errorf("unexpected key %+v", got)
+	}
+
+	stored, _ := store.Get(ctx, key.ID)
+	if stored.LastUsedAt == nil || !stored.LastUsedAt.Equal(now) {
+		t.Errorf("expected last use recorded, got %v", stored.LastUsedAt)
+	}
+
+	// Wrong secrets and unknown keys are indistinguishable
+	if _, err := store.Authenticate(ctx, raw+"x"); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("expected wrong secret to be not found, got %v", err)
+	}
+	if _, err := store.Authenticate(ctx, "tne_0000_secret"); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("expected unknown key to be not found, got %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := store.Authenticate(ctx, raw); !errors.Is(err, ErrAPIKeyInactive) {
+		t.Errorf("expected expired key to be inactive, got %v", err)
+	}
+}
+
+func TestAPIKeyStore_MintValidation(t *testing.T) {
+	now := time.Now()
+	store := newTestAPIKeyStore(t, &now)
+
+	tests := []struct {
+		name string
+		spec APIKeySpec
+	}{
+		{"missing publisher", APIKeySpec{Scopes: []string{ScopeAuction}}},
+		{"no scopes", APIKeySpec{PublisherID: "pub1"}},
+		{"unknown scope", APIKeySpec{PublisherID: "pub1", Scopes: []string{"superuser"}}},
+		{"negative ttl", APIKeySpec{PublisherID: "pub1", Scopes: []string{ScopeAuction}, TTL: -time.Hour}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := store.Mint(context.Background(), tt.spec); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestAPIKeyStore_RevokeAndRotate(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := newTestAPIKeyStore(t, &now)
+	ctx := context.Background()
+
+	var changed []string
+	store.OnChange(func(keyID string) { changed = append(changed, keyID) })
+
+	oldRaw, old, _ := store.Mint(ctx, APIKeySpec{PublisherID: "pub1", Scopes: []string{ScopeAuction, ScopeDebug}, TTL: 90 * 24 * time.Hour})
+
+	newRaw, replacement, err := store.Rotate(ctx, old.ID, time.Hour)
+	if err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	if replacement.RotatedFrom != old.ID || replacement.PublisherID != "pub1" || len(replacement.Scopes) != 2 {
+		t.Errorf("expected replacement with the same publisher and scopes, got %+v", replacement)
+	}
+	if replacement.ExpiresAt == nil || !replacement.ExpiresAt.Equal(now.Add(90*24*time.Hour)) {
+		t.Errorf("expected replacement to keep the 90 day lifetime, got %v", replacement.ExpiresAt)
+	}
+
+	// Both keys work during the overlap, only the new one after it
+	if _, err := store.Authenticate(ctx, oldRaw); err != nil {
+		t.Errorf("expected old key to work during the overlap, got %v", err)
+	}
+	now = now.Add(time.Hour)
+	if _, err := store.Authenticate(ctx, oldRaw); !errors.Is(err, ErrAPIKeyInactive) {
+		t.Errorf("expected old key to expire after the overlap, got %v", err)
+	}
+	if _, err := store.Authenticate(ctx, newRaw); err != nil {
+		t.Errorf("expected new key to work, got %v", err)
+	}
+
+	if _, _, err := store.Rotate(ctx, old.ID, time.Hour); !errors.Is(err, ErrAPIKeyInactive) {
+		t.Errorf("expected rotating an expired key to fail, got %v", err)
+	}
+
+	revoked, err := store.Revoke(ctx, replacement.ID)
+	if err != nil || revoked.RevokedAt == nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if _, err := store.Authenticate(ctx, newRaw); !errors.Is(err, ErrAPIKeyInactive) {
+		t.Errorf("expected revoked key to be inactive, got %v", err)
+	}
+	if _, err := store.Revoke(ctx, "missing"); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("expected not found for an unknown key, got %v", err)
+	}
+
+	if len(changed) != 2 || changed[0] != old.ID || changed[1] != replacement.ID {
+		t.Errorf("expected change notifications for both keys, got %v", changed)
+	}
+}
+
+func TestAPIKeyStore_List(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := newTestAPIKeyStore(t, &now)
+	ctx := context.Background()
+
+	_, first, _ := store.Mint(ctx, APIKeySpec{PublisherID: "pub1", Scopes: []string{ScopeAuction}})
+	now = now.Add(time.Minute)
+	store.Mint(ctx, APIKeySpec{PublisherID: "pub2", Scopes: []string{ScopeAuction}})
+	now = now.Add(time.Minute)
+	_, third, _ := store.Mint(ctx, APIKeySpec{PublisherID: "pub1", Scopes: []string{ScopeAdminRead}})
+
+	keys, err := store.List(ctx, "pub1")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(keys) != 2 || keys[0].ID != first.ID || keys[1].ID != third.ID {
+		t.Errorf("expected pub1's keys oldest first, got %+v", keys)
+	}
+
+	all, _ := store.List(ctx, "")
+	if len(all) != 3 {
+		t.Errorf("expected 3 keys, got %d", len(all))
+	}
+}
+
+func TestParseScopes(t *testing.T) {
+	if parseScopes("") != nil {
+		t.Error("expected nil for an empty value")
+	}
+	if scopes := parseScopes("none"); scopes == nil || len(scopes) != 0 {
+		t.Errorf("expected an empty list for none, got %v", scopes)
+	}
+	if scopes := parseScopes("auction, debug,bogus,auction"); len(scopes) != 2 || scopes[0] != ScopeAuction || scopes[1] != ScopeDebug {
+		t.Errorf("expected auction and debug, got %v", scopes)
+	}
+}
+
+func TestRequiredScope(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodPost, "/openrtb2/auction", ScopeAuction},
+		{http.MethodGet, "/admin/publishers", ScopeAdminRead},
+		{http.MethodHead, "/admin/ivt/pub1", ScopeAdminRead},
+		{http.MethodPost, "/admin/api-keys", ScopeAdminWrite},
+		{http.MethodDelete, "/admin/publishers/pub1", ScopeAdminWrite},
+		{http.MethodGet, "/administrator", ScopeAuction},
+	}
+	for _, tt := range tests {
+		if got := RequiredScope(httptest.NewRequest(tt.method, tt.path, nil)); got != tt.want {
+			t.Errorf("%s %s: expected %s, got %s", tt.method, tt.path, tt.want, got)
+		}
+	}
+}
+
+func TestAuth_ScopedKeys(t *testing.T) {
+	now := time.Now()
+	store := newTestAPIKeyStore(t, &now)
+	ctx := context.Background()
+
+	auctionKey, _, _ := store.Mint(ctx, APIKeySpec{PublisherID: "pub1", Scopes: []string{ScopeAuction}})
+	readerKey, _, _ := store.Mint(ctx, APIKeySpec{PublisherID: "ops", Scopes: []string{ScopeAdminRead}})
+
+	auth := NewAuth(&AuthConfig{Enabled: true, HeaderName: "X-API-Key"})
+	auth.SetKeyStore(store)
+
+	var identity *APIKeyIdentity
+	handler := auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity = APIKeyFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	send := func(method, path, key string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-API-Key", key)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		key    string
+		want   int
+	}{
+		{"auction key on auction", http.MethodPost, "/openrtb2/auction", auctionKey, http.StatusOK},
+		{"auction key on admin", http.MethodGet, "/admin/publishers", auctionKey, http.StatusForbidden},
+		{"reader on admin read", http.MethodGet, "/admin/publishers", readerKey, http.StatusOK},
+		{"reader on admin write", http.MethodPost, "/admin/publishers", readerKey, http.StatusForbidden},
+		{"unknown key", http.MethodPost, "/openrtb2/auction", "tne_0123_nope", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := send(tt.method, tt.path, tt.key); code != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, code)
+			}
+		})
+	}
+
+	send(http.MethodPost, "/openrtb2/auction", auctionKey)
+	if identity == nil || identity.PublisherID != "pub1" || identity.KeyID == "" {
+		t.Errorf("expected the key identity in the request context, got %+v", identity)
+	}
+}
+
+func TestAuth_RevocationEvictsCache(t *testing.T) {
+	now := time.Now()
+	store := newTestAPIKeyStore(t, &now)
+	ctx := context.Background()
+
+	raw, key, _ := store.Mint(ctx, APIKeySpec{PublisherID: "pub1", Scopes: []string{ScopeAuction}})
+
+	auth := NewAuth(&AuthConfig{Enabled: true})
+	auth.SetKeyStore(store)
+
+	if _, valid := auth.validateKey(ctx, raw); !valid {
+		t.Fatal("expected minted key to be valid")
+	}
+	if _, err := store.Revoke(ctx, key.ID); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if _, valid := auth.validateKey(ctx, raw); valid {
+		t.Error("expected revoked key to be rejected despite the cache")
+	}
+}
+
+func TestAuth_InvalidateAPIKey(t *testing.T) {
+	now := time.Now()
+	store := newTestAPIKeyStore(t, &now)
+	ctx := context.Background()
+
+	raw, key, _ := store.Mint(ctx, APIKeySpec{PublisherID: "pub1", Scopes: []string{ScopeAuction}})
+
+	auth := NewAuth(&AuthConfig{Enabled: true})
+	auth.SetKeyStore(store)
+	if _, valid := auth.validateKey(ctx, raw); !valid {
+		t.Fatal("expected minted key to be valid")
+	}
+
+	// Revoked by another instance: the local cache only learns of it from the invalidation bus
+	other := NewAPIKeyStore(store.client)
+	other.now = store.now
+	if _, err := other.Revoke(ctx, key.ID); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if _, valid := auth.validateKey(ctx, raw); !valid {
+		t.Fatal("expected cached key to stay valid until invalidated")
+	}
+
+	auth.InvalidateAPIKey(key.ID)
+	if _, valid := auth.validateKey(ctx, raw); valid {
+		t.Error("expected invalidated key to be re-read and rejected")
+	}
+}
+
+func TestAuth_LegacyKeyScopes(t *testing.T) {
+	// Unset: legacy keys keep full access
+	auth := NewAuth(&AuthConfig{Enabled: true, APIKeys: map[string]string{"legacy": "pub1"}})
+	if identity := auth.authenticate(context.Background(), "legacy"); !identity.HasScope(ScopeAdminWrite) {
+		t.Errorf("expected legacy key with all scopes, got %+v", identity)
+	}
+
+	auth = NewAuth(&AuthConfig{Enabled: true, APIKeys: map[string]string{"legacy": "pub1"}, LegacyKeyScopes: []string{ScopeAuction}})
+	identity := auth.authenticate(context.Background(), "legacy")
+	if !identity.HasScope(ScopeAuction) || identity.HasScope(ScopeDebug) {
+		t.Errorf("expected legacy key limited to auction, got %+v", identity)
+	}
+	// Cached lookups keep the restriction
+	if identity := auth.authenticate(context.Background(), "legacy"); identity.HasScope(ScopeDebug) {
+		t.Error("expected cached legacy key to stay restricted")
+	}
+
+	auth = NewAuth(&AuthConfig{Enabled: true, APIKeys: map[string]string{"legacy": "pub1"}, LegacyKeyScopes: []string{}})
+	if _, valid := auth.validateKey(context.Background(), "legacy"); valid {
+		t.Error("expected legacy keys rejected when disabled")
+	}
+}
+
+func TestAuth_BypassPathAttachesKey(t *testing.T) {
+	auth := NewAuth(&AuthConfig{
+		Enabled:         true,
+		HeaderName:      "X-API-Key",
+		APIKeys:         map[string]string{"debug-key": "pub1"},
+		LegacyKeyScopes: []string{ScopeAuction, ScopeDebug},
+		BypassPaths:     []string{"/openrtb2/auction"},
+	})
+
+	var identity *APIKeyIdentity
+	handler := auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity = APIKeyFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, key := range []string{"debug-key", "wrong-key", ""} {
+		identity = nil
+		req := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil)
+		req.Header.Set("X-API-Key", key)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("expected bypass path to pass with key %q, got %d", key, rr.Code)
+		}
+		if got := identity.HasScope(ScopeDebug); got != (key == "debug-key") {
+			t.Errorf("key %q: expected debug scope %v, got %v", key, key == "debug-key", got)
+		}
+	}
+}
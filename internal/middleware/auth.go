@@ -4,6 +4,7 @@ package middleware
 import (
 	"context"
 	"crypto/subtle"
+	"errors"
 	"net/http"
 	"os"
 	"strings"
@@ -35,6 +36,11 @@ type AuthConfig struct {
 	BypassPaths []string          // Paths that don't require auth (e.g., /health, /status)
 	RedisURL    string            // Redis URL for shared API keys
 	UseRedis    bool              // Whether to use Redis for API key validation
+	// LegacyKeyScopes are granted to raw keys from APIKeys and RedisAPIKeysHash
+	// (nil = all scopes, empty = legacy keys rejected)
+	LegacyKeyScopes []string
+	// LegacyKeyRole is the admin role of legacy keys ("" = no admin access)
+	LegacyKeyRole string
 }
 
 // DefaultAuthConfig returns default auth configuration
@@ -44,12 +50,30 @@ func DefaultAuthConfig() *AuthConfig {
 		Enabled:     os.Getenv("AUTH_ENABLED") == "true",
 		APIKeys:     parseAPIKeys(os.Getenv("API_KEYS")),
 		HeaderName:  "X-API-Key",
-		BypassPaths: []string{"/health", "/status", "/metrics", "/info/bidders", "/cookie_sync", "/setuid", "/optout", "/admin/dashboard", "/admin/metrics"},
+		BypassPaths: []string{"/health", "/status", "/metrics", "/info/bidders", "/cookie_sync", "/setuid", "/optout", "/admin/"},
 		// Note: /openrtb2/auction is conditionally added to bypass list in cmd/server/main.go
 		// based on whether PublisherAuth is enabled (primary auth) or disabled (fallback to API key)
-		// Note: /admin/dashboard and /admin/metrics are public for team monitoring
-		RedisURL: redisURL,
-		UseRedis: redisURL != "" && os.Getenv("AUTH_USE_REDIS") != "false",
+		// Note: /admin/ routes are authenticated by AdminAuth with role permissions, even when AUTH_ENABLED=false
+		RedisURL:        redisURL,
+		UseRedis:        redisURL != "" && os.Getenv("AUTH_USE_REDIS") != "false",
+		LegacyKeyScopes: parseScopes(os.Getenv("API_KEY_LEGACY_SCOPES")),
+		LegacyKeyRole:   parseLegacyKeyRole(os.Getenv("API_KEY_LEGACY_ROLE")),
+	}
+}
+
+// parseLegacyKeyRole parses API_KEY_LEGACY_ROLE
+// Legacy keys keep admin access by default; "none" removes it.
+func parseLegacyKeyRole(envValue string) string {
+	switch role := strings.TrimSpace(envValue); {
+	case role == "":
+		return RoleAdmin
+	case role == "none":
+		return ""
+	case ValidRole(role):
+		return role
+	default:
+		log.Warn().Str("role", role).Msg("Ignoring unknown API_KEY_LEGACY_ROLE, legacy keys get no admin access")
+		return ""
 	}
 }
 
@@ -82,7 +106,8 @@ type AuthMetrics interface {
 type Auth struct {
 	config      *AuthConfig
 	redisClient RedisClient
-	metrics     AuthMetrics // P0: Metrics for auth failures
+	keyStore    *APIKeyStore // Hashed, scoped keys (APIKeyPrefix)
+	metrics     AuthMetrics  // P0: Metrics for auth failures
 	mu          sync.RWMutex
 	// Cache for Redis lookups (reduces latency)
 	keyCache     map[string]cachedKey
@@ -92,6 +117,7 @@ type Auth struct {
 
 type cachedKey struct {
 	publisherID string
+	identity    *APIKeyIdentity
 	expiresAt   time.Time
 }
 
@@ -121,6 +147,18 @@ func (a *Auth) SetRedisClient(client RedisClient) {
 	a.redisClient = client
 }
 
+// SetKeyStore enables hashed, scoped API keys
+// Keys revoked or rotated through the store are evicted from this instance's cache.
+func (a *Auth) SetKeyStore(store *APIKeyStore) {
+	a.mu.Lock()
+	a.keyStore = store
+	a.mu.Unlock()
+
+	if store != nil {
+		store.OnChange(a.InvalidateAPIKey)
+	}
+}
+
 // Middleware returns the authentication middleware handler
 func (a *Auth) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -137,24 +175,23 @@ func (a *Auth) Middleware(next http.Handler) http.Handler {
 			return
 		}
 
+		// Get API key from header
+		apiKey := apiKeyFromRequest(r, headerName)
+
 		// Check bypass paths
+		// A valid key is still attached so scoped features (e.g. debug) work on public paths.
 		for _, path := range bypassPaths {
 			if strings.HasPrefix(r.URL.Path, path) {
+				if apiKey != "" {
+					if identity := a.authenticate(r.Context(), apiKey); identity != nil {
+						r = r.WithContext(ContextWithAPIKey(r.Context(), identity))
+					}
+				}
 				next.ServeHTTP(w, r)
 				return
 			}
 		}
 
-		// Get API key from header
-		apiKey := r.Header.Get(headerName)
-		if apiKey == "" {
-			// Also check Authorization header with Bearer scheme
-			authHeader := r.Header.Get("Authorization")
-			if strings.HasPrefix(authHeader, "Bearer ") {
-				apiKey = strings.TrimPrefix(authHeader, "Bearer ")
-			}
-		}
-
 		if apiKey == "" {
 			a.recordAuthFailure()
 			http.Error(w, `{"error":"missing API key"}`, http.StatusUnauthorized)
@@ -162,38 +199,106 @@ func (a *Auth) Middleware(next http.Handler) http.Handler {
 		}
 
 		// Validate API key
-		publisherID, valid := a.validateKey(r.Context(), apiKey)
-		if !valid {
+		identity := a.authenticate(r.Context(), apiKey)
+		if identity == nil {
 			a.recordAuthFailure()
 			http.Error(w, `{"error":"invalid API key"}`, http.StatusForbidden)
 			return
 		}
 
+		if scope := RequiredScope(r); !identity.HasScope(scope) {
+			a.recordAuthFailure()
+			http.Error(w, `{"error":"API key lacks the `+scope+` scope"}`, http.StatusForbidden)
+			return
+		}
+
 		// Add publisher ID to request context via header (can be used downstream)
-		r.Header.Set("X-Publisher-ID", publisherID)
+		r.Header.Set("X-Publisher-ID", identity.PublisherID)
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(ContextWithAPIKey(r.Context(), identity)))
 	})
 }
 
+// apiKeyFromRequest returns the key from headerName or an Authorization Bearer header
+func apiKeyFromRequest(r *http.Request, headerName string) string {
+	if apiKey := r.Header.Get(headerName); apiKey != "" {
+		return apiKey
+	}
+	if authHeader := r.Header.Get("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		return strings.TrimPrefix(authHeader, "Bearer ")
+	}
+	return ""
+}
+
+// RequiredScope returns the API key scope needed for a request
+// Admin reads need admin-read, admin changes admin-write, everything else auction.
+func RequiredScope(r *http.Request) string {
+	if r.URL.Path == "/admin" || strings.HasPrefix(r.URL.Path, "/admin/") {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			return ScopeAdminRead
+		}
+		return ScopeAdminWrite
+	}
+	return ScopeAuction
+}
+
 // validateKey checks if an API key is valid and returns the associated publisher ID
 func (a *Auth) validateKey(ctx context.Context, key string) (string, bool) {
+	identity := a.authenticate(ctx, key)
+	if identity == nil {
+		return "", false
+	}
+	return identity.PublisherID, true
+}
+
+// authenticate resolves an API key to its identity, or nil if it is invalid, expired or revoked
+func (a *Auth) authenticate(ctx context.Context, key string) *APIKeyIdentity {
 	// Check local cache first
-	if pubID, found := a.checkCache(key); found {
-		return pubID, pubID != ""
+	if identity, found := a.cachedIdentity(key); found {
+		return identity
 	}
 
-	// Try Redis if available
 	a.mu.RLock()
+	keyStore := a.keyStore
 	redisClient := a.redisClient
 	useRedis := a.config.UseRedis
+	legacyScopes := a.config.LegacyKeyScopes
 	a.mu.RUnlock()
 
+	// Hashed keys are looked up by ID; their secrets never appear in the legacy stores
+	if _, _, ok := splitAPIKey(key); ok && keyStore != nil {
+		record, err := keyStore.Authenticate(ctx, key)
+		if err == nil {
+			identity := &APIKeyIdentity{
+				KeyID:       record.ID,
+				PublisherID: record.PublisherID,
+				Scopes:      record.Scopes,
+				Role:        record.Role,
+				Name:        record.Name,
+			}
+			a.cacheIdentity(key, identity, record.ExpiresAt)
+			return identity
+		}
+		if !errors.Is(err, ErrAPIKeyNotFound) && !errors.Is(err, ErrAPIKeyInactive) {
+			// Don't cache a Redis failure as an invalid key
+			log.Warn().Err(err).Msg("API key store lookup failed")
+			return nil
+		}
+		a.updateCache(key, "")
+		return nil
+	}
+
+	if legacyScopes != nil && len(legacyScopes) == 0 {
+		// Legacy keys disabled
+		a.updateCache(key, "")
+		return nil
+	}
+
 	if useRedis && redisClient != nil {
 		pubID, err := redisClient.HGet(ctx, RedisAPIKeysHash, key)
 		if err == nil && pubID != "" {
 			a.updateCache(key, pubID)
-			return pubID, true
+			return a.legacyIdentity(pubID)
 		}
 		if err != nil {
 			log.Debug().Err(err).Msg("Redis API key lookup failed, falling back to local")
@@ -219,12 +324,25 @@ func (a *Auth) validateKey(ctx context.Context, key string) (string, bool) {
 
 	if found {
 		a.updateCache(key, foundPubID)
-		return foundPubID, true
+		return a.legacyIdentity(foundPubID)
 	}
 
 	// Cache negative result briefly to avoid hammering Redis
 	a.updateCache(key, "")
-	return "", false
+	return nil
+}
+
+// legacyIdentity returns the identity of a raw key from APIKeys or RedisAPIKeysHash
+func (a *Auth) legacyIdentity(publisherID string) *APIKeyIdentity {
+	a.mu.RLock()
+	scopes := a.config.LegacyKeyScopes
+	role := a.config.LegacyKeyRole
+	a.mu.RUnlock()
+
+	if scopes == nil {
+		scopes = AllAPIKeyScopes // Unscoped keys keep full access unless API_KEY_LEGACY_SCOPES is set
+	}
+	return &APIKeyIdentity{PublisherID: publisherID, Scopes: scopes, Role: role}
 }
 
 // checkCache checks if a key is in the cache and still valid
@@ -244,6 +362,53 @@ func (a *Auth) checkCache(key string) (string, bool) {
 	return cached.publisherID, true
 }
 
+// cachedIdentity returns a cached identity; found with a nil identity means the key is known invalid
+func (a *Auth) cachedIdentity(key string) (*APIKeyIdentity, bool) {
+	a.cacheMu.RLock()
+	cached, exists := a.keyCache[key]
+	a.cacheMu.RUnlock()
+
+	if !exists || time.Now().After(cached.expiresAt) {
+		return nil, false
+	}
+	if cached.publisherID == "" {
+		return nil, true
+	}
+	if cached.identity != nil {
+		return cached.identity, true
+	}
+	return a.legacyIdentity(cached.publisherID), true
+}
+
+// cacheIdentity caches a hashed key's identity, never past the key's expiry
+func (a *Auth) cacheIdentity(key string, identity *APIKeyIdentity, keyExpiresAt *time.Time) {
+	a.cacheMu.Lock()
+	defer a.cacheMu.Unlock()
+
+	expiresAt := time.Now().Add(a.cacheTimeout)
+	if keyExpiresAt != nil && keyExpiresAt.Before(expiresAt) {
+		expiresAt = *keyExpiresAt
+	}
+	a.keyCache[key] = cachedKey{
+		publisherID: identity.PublisherID,
+		identity:    identity,
+		expiresAt:   expiresAt,
+	}
+}
+
+// InvalidateAPIKey drops cached lookups of a hashed key so revocations apply immediately
+// Called by the key store on local revocations and by the invalidation bus for other instances.
+func (a *Auth) InvalidateAPIKey(keyID string) {
+	a.cacheMu.Lock()
+	defer a.cacheMu.Unlock()
+
+	for key, cached := range a.keyCache {
+		if cached.identity != nil && cached.identity.KeyID == keyID {
+			delete(a.keyCache, key)
+		}
+	}
+}
+
 // updateCache adds or updates a key in the cache
 func (a *Auth) updateCache(key, publisherID string) {
 	a.cacheMu.Lock()
@@ -241,7 +241,7 @@ func TestDefaultAuthConfig(t *testing.T) {
 	// Verify bypass paths - note: /openrtb2/auction is NOT in default list
 	// It's conditionally added at runtime in cmd/server/main.go based on
 	// whether PublisherAuth is enabled (see commit d61640d)
-	expectedBypass := []string{"/health", "/status", "/metrics", "/info/bidders", "/cookie_sync", "/setuid", "/optout", "/admin/dashboard", "/admin/metrics"}
+	expectedBypass := []string{"/health", "/status", "/metrics", "/info/bidders", "/cookie_sync", "/setuid", "/optout", "/admin/"}
 	if len(config.BypassPaths) != len(expectedBypass) {
 		t.Errorf("Expected %d bypass paths, got %d", len(expectedBypass), len(config.BypassPaths))
 	}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/thenexusengine/tne_springwire/internal/geoip"
+)
+
+// OpenRTB geo.type and geo.ipservice values set on enriched geo objects
+const (
+	geoTypeIPAddress    = 2 // Location derived from IP address
+	geoIPServiceMaxMind = 3 // MaxMind
+)
+
+// GeoEnrichmentConfig holds device.geo enrichment configuration
+type GeoEnrichmentConfig struct {
+	Enabled bool // Fill device.geo from device.ip when the request omits it
+}
+
+// DefaultGeoEnrichmentConfig returns default geo enrichment configuration
+// GEOIP_ENRICH_DEVICE_GEO: "false" disables enrichment (default: enabled when GeoIP is configured)
+func DefaultGeoEnrichmentConfig() *GeoEnrichmentConfig {
+	return &GeoEnrichmentConfig{
+		Enabled: os.Getenv("GEOIP_ENRICH_DEVICE_GEO") != "false",
+	}
+}
+
+// GeoEnrichment fills device.geo from device.ip so privacy and IVT checks see a location
+// It also normalizes device.geo.country to the ISO alpha-3 form OpenRTB specifies
+type GeoEnrichment struct {
+	config *GeoEnrichmentConfig
+	geoip  GeoIPLookup
+	mu     sync.RWMutex
+}
+
+// NewGeoEnrichment creates a new geo enrichment middleware
+// A nil lookup disables IP lookups; country normalization still applies
+func NewGeoEnrichment(config *GeoEnrichmentConfig, lookup GeoIPLookup) *GeoEnrichment {
+	if config == nil {
+		config = DefaultGeoEnrichmentConfig()
+	}
+	return &GeoEnrichment{
+		config: config,
+		geoip:  lookup,
+	}
+}
+
+// geoEnrichmentRequest is a minimal struct for deciding whether enrichment is needed
+type geoEnrichmentRequest struct {
+	Device *struct {
+		IP   string `json:"ip"`
+		IPv6 string `json:"ipv6"`
+		Geo  *struct {
+			Country string `json:"country"`
+		} `json:"geo"`
+	} `json:"device"`
+}
+
+// Middleware returns the geo enrichment middleware handler
+func (g *GeoEnrichment) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.mu.RLock()
+		enabled := g.config.Enabled
+		g.mu.RUnlock()
+
+		// Only apply to POST requests to auction endpoints
+		if !enabled || r.Method != http.MethodPost || !strings.HasPrefix(r.URL.Path, "/openrtb2/auction") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBodySize))
+		r.Body.Close()
+		if err != nil {
+			http.Error(w, `{"error":"failed to read request body"}`, http.StatusBadRequest)
+			return
+		}
+
+		if enriched, ok := g.enrichBody(body); ok {
+			body = enriched
+			r.ContentLength = int64(len(body))
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// enrichBody returns the enriched request body and whether it was modified
+// Unknown fields and extensions are preserved by editing the raw JSON map
+func (g *GeoEnrichment) enrichBody(body []byte) ([]byte, bool) {
+	var minReq geoEnrichmentRequest
+	if err := json.Unmarshal(body, &minReq); err != nil || minReq.Device == nil {
+		return nil, false // Let the handler deal with invalid JSON
+	}
+
+	var country string
+	if minReq.Device.Geo != nil {
+		country = minReq.Device.Geo.Country
+	}
+	needsLookup := country == "" && (minReq.Device.IP != "" || minReq.Device.IPv6 != "") && g.geoip != nil
+	needsNormalize := country != "" && geoip.ToAlpha3(country) != country
+	if !needsLookup && !needsNormalize {
+		return nil, false
+	}
+
+	// UseNumber keeps large integer IDs intact through the round trip
+	var rawRequest map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&rawRequest); err != nil {
+		return nil, false
+	}
+
+	deviceMap, ok := rawRequest["device"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	if !g.enrichDeviceGeo(deviceMap) {
+		return nil, false
+	}
+
+	modified, err := json.Marshal(rawRequest)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to marshal request after geo enrichment")
+		return nil, false
+	}
+	return modified, true
+}
+
+// enrichDeviceGeo fills or normalizes device.geo in a raw device map
+// Returns true if the map was modified
+func (g *GeoEnrichment) enrichDeviceGeo(deviceMap map[string]interface{}) bool {
+	geoMap, _ := deviceMap["geo"].(map[string]interface{}) //nolint:errcheck // Missing geo is created below
+
+	// Request already carries a country - only normalize it
+	if country, _ := geoMap["country"].(string); country != "" { //nolint:errcheck // Non-string country is left untouched
+		normalized := geoip.ToAlpha3(country)
+		if normalized == country {
+			return false
+		}
+		geoMap["country"] = normalized
+		return true
+	}
+
+	if g.geoip == nil {
+		return false
+	}
+
+	ip, _ := deviceMap["ip"].(string) //nolint:errcheck // Fall back to IPv6 below
+	if ip == "" {
+		ip, _ = deviceMap["ipv6"].(string) //nolint:errcheck // No IP means no lookup
+	}
+	if ip == "" {
+		return false
+	}
+
+	country, err := g.geoip.LookupCountry(ip)
+	if err != nil {
+		log.Debug().Err(err).Str("ip", ip).Msg("GeoIP lookup failed during geo enrichment")
+		return false
+	}
+	if country == "" {
+		return false // Private or unknown IP
+	}
+
+	if geoMap == nil {
+		geoMap = make(map[string]interface{})
+		deviceMap["geo"] = geoMap
+	}
+	geoMap["country"] = geoip.ToAlpha3(country)
+
+	if _, exists := geoMap["region"]; !exists {
+		if region, err := g.geoip.LookupRegion(ip); err == nil && region != "" {
+			geoMap["region"] = region
+		}
+	}
+	if _, exists := geoMap["city"]; !exists {
+		if city, err := g.geoip.LookupCity(ip); err == nil && city != "" {
+			geoMap["city"] = city
+		}
+	}
+	if _, exists := geoMap["type"]; !exists {
+		geoMap["type"] = geoTypeIPAddress
+	}
+	if _, exists := geoMap["ipservice"]; !exists {
+		geoMap["ipservice"] = geoIPServiceMaxMind
+	}
+
+	return true
+}
+
+// SetEnabled enables or disables geo enrichment
+func (g *GeoEnrichment) SetEnabled(enabled bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.config.Enabled = enabled
+}
@@ -0,0 +1,217 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// runGeoEnrichment sends body through the middleware and returns the body seen downstream
+func runGeoEnrichment(t *testing.T, g *GeoEnrichment, method, path, body string) map[string]interface{} {
+	t.Helper()
+
+	var received []byte
+	handler := g.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		if r.ContentLength != int64(len(received)) && r.ContentLength != -1 {
+			t.Errorf("ContentLength %d does not match body length %d", r.ContentLength, len(received))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rr.Code)
+	}
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(received, &out); err != nil {
+		t.Fatalf("Downstream body is not valid JSON: %v (%s)", err, received)
+	}
+	return out
+}
+
+// deviceGeo extracts device.geo from a decoded request
+func deviceGeo(t *testing.T, req map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	device, ok := req["device"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Expected device object")
+	}
+	geo, ok := device["geo"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Expected device.geo object")
+	}
+	return geo
+}
+
+func newTestGeoLookup() *MockGeoIP {
+	mock := NewMockGeoIP()
+	mock.SetCountry("81.2.69.160", "GB")
+	mock.SetLocation("81.2.69.160", "ENG", "London")
+	mock.SetCountry("2001:db8::1", "DE")
+	return mock
+}
+
+func TestGeoEnrichment_FillsDeviceGeoFromIP(t *testing.T) {
+	g := NewGeoEnrichment(&GeoEnrichmentConfig{Enabled: true}, newTestGeoLookup())
+
+	out := runGeoEnrichment(t, g, http.MethodPost, "/openrtb2/auction",
+		`{"id":"req-1","imp":[{"id":"1"}],"device":{"ip":"81.2.69.160","ua":"Mozilla/5.0"},"ext":{"custom":{"keep":true}}}`)
+
+	geo := deviceGeo(t, out)
+	if geo["country"] != "GBR" {
+		t.Errorf("Expected alpha-3 country GBR, got %v", geo["country"])
+	}
+	if geo["region"] != "ENG" || geo["city"] != "London" {
+		t.Errorf("Expected region/city ENG/London, got %v/%v", geo["region"], geo["city"])
+	}
+	if geo["type"] != float64(geoTypeIPAddress) || geo["ipservice"] != float64(geoIPServiceMaxMind) {
+		t.Errorf("Expected type=2 ipservice=3, got %v/%v", geo["type"], geo["ipservice"])
+	}
+
+	// Unknown fields must survive the rewrite
+	ext, _ := out["ext"].(map[string]interface{})
+	if custom, _ := ext["custom"].(map[string]interface{}); custom["keep"] != true {
+		t.Error("Expected ext.custom.keep to be preserved")
+	}
+}
+
+func TestGeoEnrichment_IPv6Fallback(t *testing.T) {
+	g := NewGeoEnrichment(&GeoEnrichmentConfig{Enabled: true}, newTestGeoLookup())
+
+	out := runGeoEnrichment(t, g, http.MethodPost, "/openrtb2/auction",
+		`{"id":"req-1","device":{"ipv6":"2001:db8::1","geo":{"lat":1.5}}}`)
+
+	geo := deviceGeo(t, out)
+	if geo["country"] != "DEU" {
+		t.Errorf("Expected DEU from IPv6 lookup, got %v", geo["country"])
+	}
+	if geo["lat"] != 1.5 {
+		t.Errorf("Expected existing geo fields to be kept, got lat=%v", geo["lat"])
+	}
+}
+
+func TestGeoEnrichment_KeepsExistingCountry(t *testing.T) {
+	mock := newTestGeoLookup()
+	g := NewGeoEnrichment(&GeoEnrichmentConfig{Enabled: true}, mock)
+
+	out := runGeoEnrichment(t, g, http.MethodPost, "/openrtb2/auction",
+		`{"id":"req-1","device":{"ip":"81.2.69.160","geo":{"country":"FRA"}}}`)
+
+	geo := deviceGeo(t, out)
+	if geo["country"] != "FRA" {
+		t.Errorf("Expected existing country to win over IP lookup, got %v", geo["country"])
+	}
+	if _, exists := geo["city"]; exists {
+		t.Error("Expected no city lookup when country is already present")
+	}
+}
+
+func TestGeoEnrichment_NormalizesAlpha2Country(t *testing.T) {
+	// Normalization works even without a GeoIP database
+	g := NewGeoEnrichment(&GeoEnrichmentConfig{Enabled: true}, nil)
+
+	out := runGeoEnrichment(t, g, http.MethodPost, "/openrtb2/auction",
+		`{"id":"req-1","device":{"geo":{"country":"de","region":"BE"}}}`)
+
+	geo := deviceGeo(t, out)
+	if geo["country"] != "DEU" {
+		t.Errorf("Expected DEU, got %v", geo["country"])
+	}
+	if geo["region"] != "BE" {
+		t.Errorf("Expected region to be preserved, got %v", geo["region"])
+	}
+}
+
+func TestGeoEnrichment_PreservesLargeIntegers(t *testing.T) {
+	g := NewGeoEnrichment(&GeoEnrichmentConfig{Enabled: true}, newTestGeoLookup())
+
+	var received []byte
+	handler := g.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+	}))
+	body := `{"id":"req-1","device":{"ip":"81.2.69.160"},"ext":{"bigid":9007199254740993}}`
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/openrtb2/auction", bytes.NewReader([]byte(body))))
+
+	if !bytes.Contains(received, []byte("9007199254740993")) {
+		t.Errorf("Expected large integer to survive round trip, got %s", received)
+	}
+}
+
+func TestGeoEnrichment_PassThrough(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		method  string
+		path    string
+		body    string
+	}{
+		{"Disabled", false, http.MethodPost, "/openrtb2/auction", `{"device":{"ip":"81.2.69.160"}}`},
+		{"GET request", true, http.MethodGet, "/openrtb2/auction", `{"device":{"ip":"81.2.69.160"}}`},
+		{"Other path", true, http.MethodPost, "/cookie_sync", `{"device":{"ip":"81.2.69.160"}}`},
+		{"Unknown IP", true, http.MethodPost, "/openrtb2/auction", `{"device":{"ip":"10.0.0.1"}}`},
+		{"No device", true, http.MethodPost, "/openrtb2/auction", `{"id":"x"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGeoEnrichment(&GeoEnrichmentConfig{Enabled: tt.enabled}, newTestGeoLookup())
+
+			var received []byte
+			handler := g.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received, _ = io.ReadAll(r.Body)
+			}))
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewReader([]byte(tt.body)))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if string(received) != tt.body {
+				t.Errorf("Expected body to pass through unchanged, got %s", received)
+			}
+		})
+	}
+}
+
+func TestGeoEnrichment_InvalidJSONPassThrough(t *testing.T) {
+	g := NewGeoEnrichment(&GeoEnrichmentConfig{Enabled: true}, newTestGeoLookup())
+
+	var received []byte
+	handler := g.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+	}))
+	body := `{"device":`
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/openrtb2/auction", bytes.NewReader([]byte(body))))
+
+	if string(received) != body {
+		t.Errorf("Expected invalid JSON to pass through unchanged, got %s", received)
+	}
+}
+
+func TestGeoEnrichment_LookupError(t *testing.T) {
+	mock := newTestGeoLookup()
+	mock.SetError(errors.New("lookup failed"))
+	g := NewGeoEnrichment(&GeoEnrichmentConfig{Enabled: true}, mock)
+
+	out := runGeoEnrichment(t, g, http.MethodPost, "/openrtb2/auction", `{"device":{"ip":"81.2.69.160"}}`)
+
+	device, _ := out["device"].(map[string]interface{})
+	if _, exists := device["geo"]; exists {
+		t.Error("Expected no geo object when lookup fails")
+	}
+}
+
+func TestGeoEnrichment_SetEnabled(t *testing.T) {
+	g := NewGeoEnrichment(nil, newTestGeoLookup())
+	g.SetEnabled(false)
+
+	if g.config.Enabled {
+		t.Error("Expected enrichment to be disabled")
+	}
+}
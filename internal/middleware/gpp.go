@@ -0,0 +1,214 @@
+package middleware
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+// GPP section IDs (IAB Global Privacy Platform section registry)
+const (
+	GPPSectionTCFEUv2 = 2  // EU TCF v2
+	GPPSectionUSPv1   = 6  // US Privacy string
+	GPPSectionUSNat   = 7  // US national
+	GPPSectionUSCA    = 8  // California
+	GPPSectionUSVA    = 9  // Virginia
+	GPPSectionUSCO    = 10 // Colorado
+	GPPSectionUSUT    = 11 // Utah
+	GPPSectionUSCT    = 12 // Connecticut
+)
+
+// gppHeaderType is the fixed header type value of a GPP string
+const gppHeaderType = 3
+
+// gppMaxSections bounds the number of header range entries we decode
+const gppMaxSections = 64
+
+// gppUSOptOutOffsets lists the bit offsets of the opt-out fields (sale, sharing or
+// targeted advertising) in each US section core segment. A value of 1 means opted out.
+var gppUSOptOutOffsets = map[int][]int{
+	GPPSectionUSNat: {18, 20, 22}, // SaleOptOut, SharingOptOut, TargetedAdvertisingOptOut
+	GPPSectionUSCA:  {12, 14},     // SaleOptOut, SharingOptOut
+	GPPSectionUSVA:  {12, 14},     // SaleOptOut, TargetedAdvertisingOptOut
+	GPPSectionUSCO:  {12, 14},     // SaleOptOut, TargetedAdvertisingOptOut
+	GPPSectionUSUT:  {14, 16},     // SaleOptOut, TargetedAdvertisingOptOut
+	GPPSectionUSCT:  {12, 14},     // SaleOptOut, TargetedAdvertisingOptOut
+}
+
+// GPP parsing errors
+var (
+	errInvalidGPPHeader   = &tcfError{"invalid GPP header"}
+	errInvalidGPPSections = &tcfError{"GPP section count does not match header"}
+)
+
+// GPPData holds the parts of a GPP string PBS enforces
+type GPPData struct {
+	SectionIDs []int          // Section IDs listed in the header
+	Sections   map[int]string // Section ID -> encoded section string
+}
+
+// ParseGPPString parses a GPP string header and splits it into sections
+func ParseGPPString(gpp string) (*GPPData, error) {
+	if gpp == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(gpp, "~")
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errInvalidGPPHeader
+	}
+
+	reader := newBitReader(header)
+	if reader.readInt(6) != gppHeaderType {
+		return nil, errInvalidGPPHeader
+	}
+	reader.readInt(6) // Version
+
+	sectionIDs, ok := readFibonacciRange(reader, len(header)*8)
+	if !ok {
+		return nil, errInvalidGPPHeader
+	}
+	if len(sectionIDs) != len(parts)-1 {
+		return nil, errInvalidGPPSections
+	}
+
+	data := &GPPData{
+		SectionIDs: sectionIDs,
+		Sections:   make(map[int]string, len(sectionIDs)),
+	}
+	for i, id := range sectionIDs {
+		data.Sections[id] = parts[i+1]
+	}
+	return data, nil
+}
+
+// TCFEUConsent returns the TCF v2 core string from the EU TCF section
+func (g *GPPData) TCFEUConsent() string {
+	if g == nil {
+		return ""
+	}
+	section := g.Sections[GPPSectionTCFEUv2]
+	// Segments after the core string (disclosed vendors, publisher TC) are not needed
+	if idx := strings.Index(section, "."); idx != -1 {
+		section = section[:idx]
+	}
+	return section
+}
+
+// USPrivacy returns the US Privacy string from the USP v1 section
+func (g *GPPData) USPrivacy() string {
+	if g == nil {
+		return ""
+	}
+	return g.Sections[GPPSectionUSPv1]
+}
+
+// HasUSOptOut returns true if any US section signals a sale, sharing or targeted advertising opt-out
+func (g *GPPData) HasUSOptOut() bool {
+	if g == nil {
+		return false
+	}
+	if usp := g.USPrivacy(); len(usp) >= 3 && usp[2] == 'Y' {
+		return true
+	}
+
+	for id, offsets := range gppUSOptOutOffsets {
+		section, ok := g.Sections[id]
+		if !ok {
+			continue
+		}
+		if idx := strings.Index(section, "."); idx != -1 {
+			section = section[:idx] // Core segment only
+		}
+		decoded, err := base64.RawURLEncoding.DecodeString(section)
+		if err != nil {
+			continue
+		}
+		for _, offset := range offsets {
+			reader := newBitReader(decoded)
+			reader.bitPos = offset
+			if reader.readInt(2) == 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ParseGPPSID parses a comma-separated gpp_sid list, ignoring invalid entries
+func ParseGPPSID(sid string) []int {
+	var ids []int
+	for _, part := range strings.Split(sid, ",") {
+		if id, err := strconv.Atoi(strings.TrimSpace(part)); err == nil && id > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// readFibonacciRange reads a GPP Fibonacci-encoded integer range
+// Each value is encoded as an offset from the previous value
+func readFibonacciRange(reader *bitReader, totalBits int) ([]int, bool) {
+	count := reader.readInt(12)
+	if count > gppMaxSections {
+		return nil, false
+	}
+
+	var ids []int
+	last := 0
+	for i := 0; i < count; i++ {
+		if reader.bitPos >= totalBits {
+			return nil, false
+		}
+		isRange := reader.readBool()
+
+		start, ok := readFibonacciInt(reader, totalBits)
+		if !ok {
+			return nil, false
+		}
+		start += last
+		last = start
+
+		if !isRange {
+			ids = append(ids, start)
+			continue
+		}
+
+		end, ok := readFibonacciInt(reader, totalBits)
+		if !ok {
+			return nil, false
+		}
+		end += last
+		last = end
+		if end-start > gppMaxSections {
+			return nil, false
+		}
+		for id := start; id <= end; id++ {
+			ids = append(ids, id)
+		}
+	}
+	return ids, true
+}
+
+// readFibonacciInt reads a Fibonacci-coded integer terminated by two consecutive 1 bits
+func readFibonacciInt(reader *bitReader, totalBits int) (int, bool) {
+	value := 0
+	a, b := 1, 2 // Fibonacci terms for the current and next bit position
+	prev := false
+	for reader.bitPos < totalBits {
+		bit := reader.readBool()
+		if bit && prev {
+			return value, true
+		}
+		if bit {
+			value += a
+		}
+		prev = bit
+		a, b = b, a+b
+		if a > 1<<16 {
+			return 0, false // Section IDs are small; reject runaway encodings
+		}
+	}
+	return 0, false
+}
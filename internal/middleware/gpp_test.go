@@ -0,0 +1,259 @@
+package middleware
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+)
+
+// testBitWriter builds bit-packed consent strings for tests
+type testBitWriter struct {
+	bits []bool
+}
+
+func (w *testBitWriter) writeInt(value, bits int) {
+	for i := bits - 1; i >= 0; i-- {
+		w.bits = append(w.bits, value>>i&1 == 1)
+	}
+}
+
+func (w *testBitWriter) writeBool(b bool) {
+	w.bits = append(w.bits, b)
+}
+
+// writeFibonacci writes a Fibonacci-coded integer (Zeckendorf, least significant first, terminated by 1)
+func (w *testBitWriter) writeFibonacci(value int) {
+	fibs := []int{1, 2}
+	for fibs[len(fibs)-1] <= value {
+		fibs = append(fibs, fibs[len(fibs)-1]+fibs[len(fibs)-2])
+	}
+	code := make([]bool, len(fibs))
+	last := 0
+	for i := len(fibs) - 1; i >= 0; i-- {
+		if fibs[i] <= value {
+			code[i] = true
+			value -= fibs[i]
+			if last == 0 {
+				last = i
+			}
+		}
+	}
+	w.bits = append(w.bits, code[:last+1]...)
+	w.bits = append(w.bits, true)
+}
+
+func (w *testBitWriter) encode() string {
+	data := make([]byte, (len(w.bits)+7)/8)
+	for i, b := range w.bits {
+		if b {
+			data[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// buildTestTCFString builds a TCF v2 core string with the given purpose and vendor consents
+func buildTestTCFString(purposes, vendors []int) string {
+	return buildTestTCFStringWithFeatures(purposes, vendors, nil)
+}
+
+// buildTestTCFStringWithFeatures builds a TCF v2 core string that also opts in to special features
+func buildTestTCFStringWithFeatures(purposes, vendors, specialFeatures []int) string {
+	w := &testBitWriter{}
+	w.writeInt(2, 6)    // Version
+	w.writeInt(0, 36)   // Created
+	w.writeInt(0, 36)   // LastUpdated
+	w.writeInt(7, 12)   // CmpId
+	w.writeInt(1, 12)   // CmpVersion
+	w.writeInt(1, 6)    // ConsentScreen
+	w.writeInt(4, 6)    // Language 'e'
+	w.writeInt(13, 6)   // Language 'n'
+	w.writeInt(100, 12) // VendorListVersion
+	w.writeInt(2, 6)    // TcfPolicyVersion
+	w.writeInt(0, 2)    // IsServiceSpecific, UseNonStandardStacks
+	features := make(map[int]bool)
+	for _, f := range specialFeatures {
+		features[f] = true
+	}
+	for f := 1; f <= 12; f++ {
+		w.writeBool(features[f]) // SpecialFeatureOptIns
+	}
+	consented := make(map[int]bool)
+	for _, p := range purposes {
+		consented[p] = true
+	}
+	for p := 1; p <= 24; p++ {
+		w.writeBool(consented[p])
+	}
+	w.writeInt(0, 24+12+24+12+24+12) // Remaining purpose and feature fields
+
+	maxVendor := 0
+	for _, v := range vendors {
+		if v > maxVendor {
+			maxVendor = v
+		}
+	}
+	w.writeInt(maxVendor, 16)
+	w.writeBool(false) // BitField encoding
+	vendorSet := make(map[int]bool)
+	for _, v := range vendors {
+		vendorSet[v] = true
+	}
+	for v := 1; v <= maxVendor; v++ {
+		w.writeBool(vendorSet[v])
+	}
+	return w.encode()
+}
+
+// buildTestGPPString builds a GPP string from ordered sections
+func buildTestGPPString(sectionIDs []int, sections []string) string {
+	w := &testBitWriter{}
+	w.writeInt(gppHeaderType, 6)
+	w.writeInt(1, 6) // Version
+	w.writeInt(len(sectionIDs), 12)
+	last := 0
+	for _, id := range sectionIDs {
+		w.writeBool(false) // Single ID
+		w.writeFibonacci(id - last)
+		last = id
+	}
+
+	gpp := w.encode()
+	for _, section := range sections {
+		gpp += "~" + section
+	}
+	return gpp
+}
+
+// buildTestUSSection builds a US section core segment with one opt-out field set
+func buildTestUSSection(optOutOffset int) string {
+	w := &testBitWriter{}
+	w.writeInt(1, 6) // Version
+	for len(w.bits) < optOutOffset {
+		w.writeBool(false)
+	}
+	w.writeInt(1, 2) // Opted out
+	w.writeInt(0, 16)
+	return w.encode()
+}
+
+func TestParseGPPString(t *testing.T) {
+	tcf := buildTestTCFString([]int{1}, []int{32})
+	gpp := buildTestGPPString([]int{2, 6}, []string{tcf + ".QAAA", "1YNN"})
+
+	data, err := ParseGPPString(gpp)
+	if err != nil {
+		t.Fatalf("ParseGPPString failed: %v", err)
+	}
+	if !reflect.DeepEqual(data.SectionIDs, []int{2, 6}) {
+		t.Errorf("Expected sections [2 6], got %v", data.SectionIDs)
+	}
+	if data.TCFEUConsent() != tcf {
+		t.Errorf("Expected TCF core string without segments, got %q", data.TCFEUConsent())
+	}
+	if data.USPrivacy() != "1YNN" {
+		t.Errorf("Expected USP section, got %q", data.USPrivacy())
+	}
+	if data.HasUSOptOut() {
+		t.Error("1YNN should not signal an opt-out")
+	}
+}
+
+func TestParseGPPString_Range(t *testing.T) {
+	w := &testBitWriter{}
+	w.writeInt(gppHeaderType, 6)
+	w.writeInt(1, 6)
+	w.writeInt(1, 12)
+	w.writeBool(true) // Range 7-8
+	w.writeFibonacci(7)
+	w.writeFibonacci(1)
+	gpp := w.encode() + "~a~b"
+
+	data, err := ParseGPPString(gpp)
+	if err != nil {
+		t.Fatalf("ParseGPPString failed: %v", err)
+	}
+	if !reflect.DeepEqual(data.SectionIDs, []int{7, 8}) {
+		t.Errorf("Expected sections [7 8], got %v", data.SectionIDs)
+	}
+}
+
+func TestParseGPPString_Invalid(t *testing.T) {
+	if data, err := ParseGPPString(""); data != nil || err != nil {
+		t.Errorf("Empty string should return nil, nil; got %v, %v", data, err)
+	}
+
+	// Header announcing one section without encoding its ID
+	truncated := &testBitWriter{}
+	truncated.writeInt(gppHeaderType, 6)
+	truncated.writeInt(1, 6)
+	truncated.writeInt(1, 12)
+
+	tests := []struct {
+		name string
+		gpp  string
+	}{
+		{"not base64", "!!!"},
+		{"wrong header type", "BAAA~x"},
+		{"section count mismatch", buildTestGPPString([]int{2, 6}, []string{"only-one"})},
+		{"truncated header", truncated.encode() + "~x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseGPPString(tt.gpp); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestGPPData_HasUSOptOut(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     int
+		offset int
+	}{
+		{"usnat sale", GPPSectionUSNat, 18},
+		{"usnat targeted", GPPSectionUSNat, 22},
+		{"usca sharing", GPPSectionUSCA, 14},
+		{"usva sale", GPPSectionUSVA, 12},
+		{"usut targeted", GPPSectionUSUT, 16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gpp := buildTestGPPString([]int{tt.id}, []string{buildTestUSSection(tt.offset)})
+			data, err := ParseGPPString(gpp)
+			if err != nil {
+				t.Fatalf("ParseGPPString failed: %v", err)
+			}
+			if !data.HasUSOptOut() {
+				t.Error("Expected opt-out")
+			}
+		})
+	}
+
+	// A 2 (did not opt out) is not an opt-out
+	w := &testBitWriter{}
+	w.writeInt(1, 6)
+	w.writeInt(0, 12)
+	w.writeInt(2, 2)
+	w.writeInt(0, 16)
+	data, _ := ParseGPPString(buildTestGPPString([]int{GPPSectionUSCA}, []string{w.encode()})) //nolint:errcheck // Checked via result
+	if data.HasUSOptOut() {
+		t.Error("Value 2 should not be treated as opt-out")
+	}
+
+	uspOptOut, _ := ParseGPPString(buildTestGPPString([]int{GPPSectionUSPv1}, []string{"1YYN"})) //nolint:errcheck // Checked via result
+	if !uspOptOut.HasUSOptOut() {
+		t.Error("USP section with sale opt-out should signal opt-out")
+	}
+}
+
+func TestParseGPPSID(t *testing.T) {
+	if got := ParseGPPSID("2, 6,x,-1"); !reflect.DeepEqual(got, []int{2, 6}) {
+		t.Errorf("Expected [2 6], got %v", got)
+	}
+	if got := ParseGPPSID(""); len(got) != 0 {
+		t.Errorf("Expected no IDs, got %v", got)
+	}
+}
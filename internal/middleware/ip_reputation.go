@@ -0,0 +1,356 @@
+package middleware
+
+import (
+	"bufio"
+	"fmt"
+	"math/bits"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/thenexusengine/tne_springwire/internal/filewatch"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// IP reputation list categories (sophisticated invalid traffic)
+const (
+	IPCategoryDatacenter = "datacenter" // Cloud and hosting provider ranges
+	IPCategoryProxy      = "proxy"      // Known proxies, VPN exits and anonymizers
+	IPCategoryBotASN     = "bot_asn"    // Ranges announced by ASNs of known crawlers
+)
+
+// ipReputationCategories lists categories in match priority order
+var ipReputationCategories = []string{IPCategoryBotASN, IPCategoryProxy, IPCategoryDatacenter}
+
+// IPCategoryNoBidReason maps an IP reputation category to its OpenRTB no-bid reason
+func IPCategoryNoBidReason(category string) openrtb.NoBidReason {
+	if category == IPCategoryBotASN {
+		return openrtb.NoBidKnownWebSpider
+	}
+	return openrtb.NoBidCloudDataCenter
+}
+
+// IPReputationMatch is a client IP found in a reputation list
+type IPReputationMatch struct {
+	Category string // IPCategory* constant
+	Network  string // Matching list entry in CIDR notation
+	Label    string // Free text after the CIDR on the list line (provider, ASN, ...)
+}
+
+// IPReputation matches client IPs against datacenter, proxy/VPN and bot ASN range lists
+// Lists are plain text files with one IP or CIDR per line, optionally followed by a label
+// ("3.0.0.0/9 AWS", "66.249.64.0/19 AS15169"). Blank lines and # comments are ignored.
+// Lists are reloaded when their files change; a failed reload keeps the previous lists.
+type IPReputation struct {
+	paths  map[string]string // category -> file path
+	poller *filewatch.Poller
+
+	lists atomic.Pointer[ipReputationLists]
+	mu    sync.Mutex // Serializes reloads
+}
+
+// ipReputationLists is an immutable snapshot of the loaded lists
+type ipReputationLists struct {
+	trees    map[string]*ipRadixTree
+	versions map[string]filewatch.Version
+}
+
+// NewIPReputation loads the configured lists; empty paths disable their category
+// Returns nil without error when no list is configured
+func NewIPReputation(datacenterPath, proxyPath, botASNPath string, reloadInterval time.Duration) (*IPReputation, error) {
+	paths := make(map[string]string, 3)
+	for category, path := range map[string]string{
+		IPCategoryDatacenter: datacenterPath,
+		IPCategoryProxy:      proxyPath,
+		IPCategoryBotASN:     botASNPath,
+	} {
+		if path != "" {
+			paths[category] = path
+		}
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	r := &IPReputation{paths: paths}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	r.poller = filewatch.NewPoller(reloadInterval, r.Reload, func(err error) {
+		log.Warn().Err(err).Msg("Failed to reload IP reputation lists, keeping previous")
+	})
+	return r, nil
+}
+
+// Reload re-reads list files that changed since the last load
+// Either every changed list loads or none does, so a bad file never leaves a partial update.
+func (r *IPReputation) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.lists.Load()
+	next := &ipReputationLists{
+		trees:    make(map[string]*ipRadixTree, len(r.paths)),
+		versions: make(map[string]filewatch.Version, len(r.paths)),
+	}
+	changed := false
+
+	for category, path := range r.paths {
+		version, err := filewatch.Stat(path)
+		if err != nil {
+			return fmt.Errorf("%s list: %w", category, err)
+		}
+
+		if current != nil && current.versions[category] == version {
+			next.trees[category] = current.trees[category]
+			next.versions[category] = version
+			continue
+		}
+
+		tree, skipped, err := loadIPList(path)
+		if err != nil {
+			return fmt.Errorf("%s list: %w", category, err)
+		}
+		if skipped > 0 {
+			log.Warn().Str("category", category).Str("path", path).Int("skipped", skipped).Msg("Skipped invalid IP reputation list entries")
+		}
+		log.Info().Str("category", category).Str("path", path).Int("entries", tree.size).Msg("IP reputation list loaded")
+
+		next.trees[category] = tree
+		next.versions[category] = version
+		changed = true
+	}
+
+	if changed || current == nil {
+		r.lists.Store(next)
+	}
+	return nil
+}
+
+// Lookup returns every list the IP is in, strictest category first
+func (r *IPReputation) Lookup(ipStr string) []IPReputationMatch {
+	if r == nil {
+		return nil
+	}
+	lists := r.lists.Load()
+	if lists == nil {
+		return nil
+	}
+	key, ok := ipRadixKey(net.ParseIP(strings.TrimSpace(ipStr)))
+	if !ok {
+		return nil
+	}
+
+	var matches []IPReputationMatch
+	for _, category := range ipReputationCategories {
+		tree := lists.trees[category]
+		if tree == nil {
+			continue
+		}
+		if entry := tree.lookup(key); entry != nil {
+			matches = append(matches, IPReputationMatch{Category: category, Network: entry.network, Label: entry.label})
+		}
+	}
+	return matches
+}
+
+// Start launches periodic change checks; a zero interval disables them
+func (r *IPReputation) Start() {
+	if r == nil {
+		return
+	}
+	r.poller.Start()
+}
+
+// Stop stops periodic reloads
+func (r *IPReputation) Stop() {
+	if r == nil {
+		return
+	}
+	r.poller.Stop()
+}
+
+// loadIPList parses a list file into a radix tree, returning the number of invalid lines skipped
+func loadIPList(path string) (*ipRadixTree, int, error) {
+	f, err := os.Open(path) //nolint:gosec // Path comes from operator configuration
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	tree := &ipRadixTree{}
+	skipped := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx != -1 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		ipNet, err := parseIPListEntry(fields[0])
+		if err != nil {
+			skipped++
+			continue
+		}
+		tree.insert(ipNet, strings.Join(fields[1:], " "))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+	return tree, skipped, nil
+}
+
+// parseIPListEntry accepts a CIDR or a single address
+func parseIPListEntry(entry string) (*net.IPNet, error) {
+	if strings.Contains(entry, "/") {
+		_, ipNet, err := net.ParseCIDR(entry)
+		return ipNet, err
+	}
+	ip := net.ParseIP(entry)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP %q", entry)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// ipRadixTree is a path-compressed binary radix tree of IP prefixes
+// IPv4 prefixes are stored in the IPv4-mapped IPv6 space, so one tree serves both families.
+type ipRadixTree struct {
+	root *ipRadixNode
+	size int
+}
+
+type ipRadixNode struct {
+	key      [16]byte // Prefix bits; bits past length are zero
+	length   int      // Prefix length in bits
+	entry    *ipRadixEntry
+	children [2]*ipRadixNode
+}
+
+type ipRadixEntry struct {
+	network string
+	label   string
+}
+
+// ipv4MappedBits is the length of the ::ffff:0:0/96 prefix IPv4 addresses live under
+const ipv4MappedBits = 96
+
+// ipRadixKey converts an IP into its 128-bit tree key
+func ipRadixKey(ip net.IP) ([16]byte, bool) {
+	var key [16]byte
+	ip16 := ip.To16()
+	if ip16 == nil {
+		return key, false
+	}
+	copy(key[:], ip16)
+	return key, true
+}
+
+// insert adds a prefix; re-inserting a prefix replaces its label
+func (t *ipRadixTree) insert(ipNet *net.IPNet, label string) {
+	ones, totalBits := ipNet.Mask.Size()
+	key, ok := ipRadixKey(ipNet.IP)
+	if !ok {
+		return
+	}
+	if totalBits == 32 {
+		ones += ipv4MappedBits
+	}
+	key = maskKey(key, ones)
+	entry := &ipRadixEntry{network: ipNet.String(), label: label}
+
+	node := &t.root
+	for {
+		cur := *node
+		if cur == nil {
+			*node = &ipRadixNode{key: key, length: ones, entry: entry}
+			t.size++
+			return
+		}
+
+		common := commonPrefixBits(key, cur.key, min(ones, cur.length))
+		if common < cur.length {
+			// Split: a new node holding the shared prefix takes cur's place
+			split := &ipRadixNode{key: maskKey(key, common), length: common}
+			split.children[keyBit(cur.key, common)] = cur
+			if common == ones {
+				split.entry = entry
+			} else {
+				split.children[keyBit(key, common)] = &ipRadixNode{key: key, length: ones, entry: entry}
+			}
+			*node = split
+			t.size++
+			return
+		}
+
+		if ones == cur.length {
+			if cur.entry == nil {
+				t.size++
+			}
+			cur.entry = entry
+			return
+		}
+		node = &cur.children[keyBit(key, cur.length)]
+	}
+}
+
+// lookup returns the most specific prefix containing key
+func (t *ipRadixTree) lookup(key [16]byte) *ipRadixEntry {
+	var best *ipRadixEntry
+	for node := t.root; node != nil; {
+		if commonPrefixBits(key, node.key, node.length) < node.length {
+			break
+		}
+		if node.entry != nil {
+			best = node.entry
+		}
+		if node.length == 128 {
+			break
+		}
+		node = node.children[keyBit(key, node.length)]
+	}
+	return best
+}
+
+// keyBit returns bit i of key (0 = most significant)
+func keyBit(key [16]byte, i int) int {
+	return int(key[i/8]>>(7-uint(i%8))) & 1 //nolint:gosec // i%8 is in [0,7]
+}
+
+// maskKey zeroes every bit of key past length
+func maskKey(key [16]byte, length int) [16]byte {
+	for i := range key {
+		switch {
+		case length >= (i+1)*8:
+		case length <= i*8:
+			key[i] = 0
+		default:
+			key[i] &= ^byte(0xFF >> uint(length-i*8)) //nolint:gosec // Shift is in [1,7]
+		}
+	}
+	return key
+}
+
+// commonPrefixBits returns how many leading bits a and b share, up to limit
+func commonPrefixBits(a, b [16]byte, limit int) int {
+	n := 0
+	for i := 0; i < 16 && n < limit; i++ {
+		if x := a[i] ^ b[i]; x != 0 {
+			n += bits.LeadingZeros8(x)
+			break
+		}
+		n += 8
+	}
+	return min(n, limit)
+}
@@ -0,0 +1,342 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// writeIPList writes an IP list file into dir (test helper)
+func writeIPList(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestIPRadixTree_LongestMatch(t *testing.T) {
+	tree := &ipRadixTree{}
+	for _, entry := range []struct{ cidr, label string }{
+		{"10.0.0.0/8", "wide"},
+		{"10.1.0.0/16", "narrow"},
+		{"10.1.2.3/32", "host"},
+		{"192.168.0.0/24", "lan"},
+		{"2001:db8::/32", "v6"},
+		{"2001:db8:abcd::/48", "v6-narrow"},
+	} {
+		_, ipNet, err := net.ParseCIDR(entry.cidr)
+		if err != nil {
+			t.Fatalf("bad CIDR %s: %v", entry.cidr, err)
+		}
+		tree.insert(ipNet, entry.label)
+	}
+	if tree.size != 6 {
+		t.Errorf("expected 6 entries, got %d", tree.size)
+	}
+
+	tests := []struct {
+		ip    string
+		label string // empty means no match
+	}{
+		{"10.200.0.1", "wide"},
+		{"10.1.9.9", "narrow"},
+		{"10.1.2.3", "host"},
+		{"10.1.2.4", "narrow"},
+		{"192.168.0.255", "lan"},
+		{"192.168.1.1", ""},
+		{"11.0.0.1", ""},
+		{"2001:db8:1::1", "v6"},
+		{"2001:db8:abcd:1::1", "v6-narrow"},
+		{"2001:db9::1", ""},
+		{"::ffff:10.1.2.3", "host"}, // IPv4-mapped form of an IPv4 entry
+	}
+	for _, tt := range tests {
+		key, _ := ipRadixKey(net.ParseIP(tt.ip))
+		entry := tree.lookup(key)
+		switch {
+		case tt.label == "" && entry != nil:
+			t.Errorf("%s: expected no match, got %s", tt.ip, entry.label)
+		case tt.label != "" && (entry == nil || entry.label != tt.label):
+			t.Errorf("%s: expected %s, got %+v", tt.ip, tt.label, entry)
+		}
+	}
+}
+
+func TestIPRadixTree_InsertOrder(t *testing.T) {
+	// Inserting the narrow prefix first forces a split when the wider one arrives
+	tree := &ipRadixTree{}
+	for _, cidr := range []string{"10.1.2.0/24", "10.1.3.0/24", "10.0.0.0/8", "10.1.2.0/24"} {
+		_, ipNet, _ := net.ParseCIDR(cidr)
+		tree.insert(ipNet, cidr)
+	}
+	if tree.size != 3 {
+		t.Errorf("expected duplicate prefix to be counted once, got %d", tree.size)
+	}
+
+	key, _ := ipRadixKey(net.ParseIP("10.1.3.7"))
+	if entry := tree.lookup(key); entry == nil || entry.network != "10.1.3.0/24" {
+		t.Errorf("expected 10.1.3.0/24, got %+v", entry)
+	}
+	key, _ = ipRadixKey(net.ParseIP("10.9.9.9"))
+	if entry := tree.lookup(key); entry == nil || entry.network != "10.0.0.0/8" {
+		t.Errorf("expected 10.0.0.0/8, got %+v", entry)
+	}
+}
+
+func TestLoadIPList(t *testing.T) {
+	dir := t.TempDir()
+	path := writeIPList(t, dir, "dc.txt", `# cloud ranges
+3.0.0.0/9 AWS us-east-1
+34.64.0.0/10   GCP
+
+203.0.113.7    # single host
+not-an-ip
+2600:1f00::/24 AWS
+`)
+
+	tree, skipped, err := loadIPList(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if tree.size != 4 || skipped != 1 {
+		t.Errorf("expected 4 entries and 1 skipped line, got %d and %d", tree.size, skipped)
+	}
+
+	key, _ := ipRadixKey(net.ParseIP("3.5.1.1"))
+	if entry := tree.lookup(key); entry == nil || entry.label != "AWS us-east-1" {
+		t.Errorf("expected label with spaces to be kept, got %+v", entry)
+	}
+	key, _ = ipRadixKey(net.ParseIP("203.0.113.7"))
+	if entry := tree.lookup(key); entry == nil || entry.network != "203.0.113.7/32" {
+		t.Errorf("expected single host as /32, got %+v", entry)
+	}
+
+	if _, _, err := loadIPList(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
+
+func TestIPReputation_Lookup(t *testing.T) {
+	dir := t.TempDir()
+	rep, err := NewIPReputation(
+		writeIPList(t, dir, "dc.txt", "66.249.0.0/16 hosting\n3.0.0.0/9 AWS\n"),
+		writeIPList(t, dir, "proxy.txt", "185.220.101.0/24 tor\n"),
+		writeIPList(t, dir, "bots.txt", "66.249.64.0/19 AS15169\n"),
+		0,
+	)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	matches := rep.Lookup("66.249.66.1")
+	if len(matches) != 2 || matches[0].Category != IPCategoryBotASN || matches[1].Category != IPCategoryDatacenter {
+		t.Fatalf("expected bot ASN then datacenter matches, got %+v", matches)
+	}
+	if matches[0].Label != "AS15169" || matches[0].Network != "66.249.64.0/19" {
+		t.Errorf("unexpected match details: %+v", matches[0])
+	}
+
+	if got := rep.Lookup("185.220.101.5"); len(got) != 1 || got[0].Category != IPCategoryProxy {
+		t.Errorf("expected proxy match, got %+v", got)
+	}
+	if got := rep.Lookup("8.8.8.8"); len(got) != 0 {
+		t.Errorf("expected no match, got %+v", got)
+	}
+	if got := rep.Lookup("garbage"); got != nil {
+		t.Errorf("expected nil for invalid IP, got %+v", got)
+	}
+
+	var nilRep *IPReputation
+	if nilRep.Lookup("3.0.0.1") != nil {
+		t.Error("expected nil reputation to match nothing")
+	}
+}
+
+func TestNewIPReputation_NotConfigured(t *testing.T) {
+	rep, err := NewIPReputation("", "", "", time.Minute)
+	if rep != nil || err != nil {
+		t.Errorf("expected nil without lists, got %v %v", rep, err)
+	}
+	rep.Start() // nil-safe
+	rep.Stop()
+
+	if _, err := NewIPReputation(filepath.Join(t.TempDir(), "missing.txt"), "", "", 0); err == nil {
+		t.Error("expected error for a missing list file")
+	}
+}
+
+func TestIPReputation_Reload(t *testing.T) {
+	dir := t.TempDir()
+	dcPath := writeIPList(t, dir, "dc.txt", "3.0.0.0/9 AWS\n")
+	rep, err := NewIPReputation(dcPath, "", "", 0)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	before := rep.lists.Load()
+
+	// Unchanged files keep the loaded snapshot
+	if err := rep.Reload(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if rep.lists.Load() != before {
+		t.Error("expected unchanged lists not to be rebuilt")
+	}
+
+	writeIPList(t, dir, "dc.txt", "34.64.0.0/10 GCP\n34.128.0.0/10 GCP\n")
+	if err := rep.Reload(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if len(rep.Lookup("3.0.0.1")) != 0 || len(rep.Lookup("34.64.0.1")) != 1 {
+		t.Error("expected the rewritten list to replace the old one")
+	}
+
+	// A list that disappears keeps the previous snapshot
+	if err := os.Remove(dcPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := rep.Reload(); err == nil {
+		t.Error("expected reload error for a missing file")
+	}
+	if len(rep.Lookup("34.64.0.1")) != 1 {
+		t.Error("expected previous lists to stay active after a failed reload")
+	}
+}
+
+func TestIPReputation_HotReload(t *testing.T) {
+	dir := t.TempDir()
+	proxyPath := writeIPList(t, dir, "proxy.txt", "")
+	rep, err := NewIPReputation("", proxyPath, "", 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	rep.Start()
+	defer rep.Stop()
+
+	writeIPList(t, dir, "proxy.txt", "198.51.100.0/24 vpn\n")
+	deadline := time.Now().Add(2 * time.Second)
+	for len(rep.Lookup("198.51.100.9")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the proxy list to be reloaded after the file changed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// newIPReputationDetector returns a detector with datacenter and bot ASN lists (test helper)
+func newIPReputationDetector(t *testing.T, blocking bool) *IVTDetector {
+	t.Helper()
+	dir := t.TempDir()
+	config := &IVTConfig{
+		MonitoringEnabled:  true,
+		BlockingEnabled:    blocking,
+		CheckUserAgent:     true,
+		CheckIPReputation:  true,
+		DatacenterListPath: writeIPList(t, dir, "dc.txt", "3.0.0.0/9 AWS\n"),
+		BotASNListPath:     writeIPList(t, dir, "bots.txt", "66.249.64.0/19 AS15169\n"),
+	}
+	detector := NewIVTDetector(config)
+	t.Cleanup(func() { detector.Close() })
+	return detector
+}
+
+func TestIVTDetector_IPReputation(t *testing.T) {
+	detector := newIPReputationDetector(t, true)
+
+	tests := []struct {
+		name       string
+		ip         string
+		wantSignal string
+		wantNBR    openrtb.NoBidReason
+	}{
+		{"datacenter", "3.1.2.3", "datacenter_ip", openrtb.NoBidCloudDataCenter},
+		{"bot ASN", "66.249.66.1", "bot_asn_ip", openrtb.NoBidKnownWebSpider},
+		{"residential", "81.2.69.160", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil)
+			req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/120.0")
+			req.Header.Set("X-Forwarded-For", tt.ip)
+
+			result := detector.Validate(context.Background(), req, "pub", "example.com")
+
+			if tt.wantSignal == "" {
+				if result.ShouldBlock || result.NoBidReason != 0 || len(result.Signals) != 0 {
+					t.Errorf("expected clean result, got %+v", result)
+				}
+				return
+			}
+			if !result.ShouldBlock || result.NoBidReason != tt.wantNBR {
+				t.Errorf("expected block with nbr %d, got block=%v nbr=%d", tt.wantNBR, result.ShouldBlock, result.NoBidReason)
+			}
+			if result.Signals[0].Type != tt.wantSignal || !strings.Contains(result.BlockReason, tt.ip[:2]) {
+				t.Errorf("expected %s to lead the signals, got %+v (reason %q)", tt.wantSignal, result.Signals, result.BlockReason)
+			}
+		})
+	}
+
+	metrics := detector.GetMetrics()
+	if metrics.DatacenterIPs != 1 || metrics.BotASNIPs != 1 {
+		t.Errorf("expected one datacenter and one bot ASN hit, got %d and %d", metrics.DatacenterIPs, metrics.BotASNIPs)
+	}
+}
+
+func TestIVTDetector_IPReputationMonitoringOnly(t *testing.T) {
+	detector := newIPReputationDetector(t, false)
+
+	req := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 Chrome/120.0")
+	req.Header.Set("X-Forwarded-For", "3.1.2.3")
+
+	result := detector.Validate(context.Background(), req, "pub", "example.com")
+	if result.IsValid || result.ShouldBlock {
+		t.Errorf("expected flagged but not blocked, got valid=%v block=%v", result.IsValid, result.ShouldBlock)
+	}
+	if result.NoBidReason != 0 {
+		t.Errorf("expected no NBR without blocking, got %d", result.NoBidReason)
+	}
+
+	// The check can be switched off at runtime
+	config := *detector.GetConfig()
+	config.CheckIPReputation = false
+	detector.SetConfig(&config)
+	if result := detector.Validate(context.Background(), req, "pub", "example.com"); len(result.Signals) != 0 {
+		t.Errorf("expected no signals with the check disabled, got %+v", result.Signals)
+	}
+}
+
+func TestPublisherAuth_IVTBlockNoBidReason(t *testing.T) {
+	auth := NewPublisherAuth(&PublisherAuthConfig{Enabled: true, AllowUnregistered: true})
+	auth.ivtDetector = newIPReputationDetector(t, true)
+
+	handler := auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler should not be called for blocked traffic")
+	}))
+
+	body := []byte(`{"id":"1","imp":[{"id":"imp1"}],"site":{"domain":"example.com","publisher":{"id":"pub1"}}}`)
+	req := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", bytes.NewReader(body))
+	req.Header.Set("User-Agent", "Mozilla/5.0 Chrome/120.0")
+	req.Header.Set("X-Forwarded-For", "66.249.66.1")
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), `"nbr":3`) {
+		t.Errorf("expected known web spider NBR in body, got %s", rr.Body.String())
+	}
+}
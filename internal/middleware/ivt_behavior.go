@@ -0,0 +1,199 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/ivtpolicy"
+)
+
+// RedisIVTPrefix is the key prefix for behavioral IVT sliding windows
+const RedisIVTPrefix = "tne_catalyst:ivt:"
+
+// zeroIFA is the IFA reported when the user limits ad tracking
+const zeroIFA = "00000000-0000-0000-0000-000000000000"
+
+// OpenRTB device types that are handheld
+const (
+	deviceTypePhone  = 4
+	deviceTypeTablet = 5
+)
+
+// slidingWindowScript records one event in each sorted set and returns their counts
+// KEYS: one sorted set per window; ARGV[1]: now (ms), ARGV[2]: window (ms), ARGV[3..]: member per key.
+// Events older than the window are trimmed before counting, so counts cover exactly one window.
+const slidingWindowScript = `
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local counts = {}
+for i, key in ipairs(KEYS) do
+	redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+	redis.call('ZADD', key, now, ARGV[i + 2])
+	counts[i] = redis.call('ZCARD', key)
+	redis.call('PEXPIRE', key, window)
+end
+return counts
+`
+
+// IVTBehaviorClient defines the Redis operations used for behavioral IVT tracking
+type IVTBehaviorClient interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// IVTRequest carries the bid request fields and publisher policy used by IVT checks
+type IVTRequest struct {
+	PublisherID string
+	Domain      string
+	RequestID   string // Top-level bid request ID
+	DeviceIP    string // device.ip; behavior is tracked per client IP when empty
+	IFA         string // device.ifa
+	UserID      string // user.id, scoped to the publisher
+	DeviceUA    string // device.ua; the User-Agent header is used when empty
+	DeviceOS    string
+	DeviceMake  string
+	DeviceType  int
+	Policy      *ivtpolicy.Policy // Publisher IVT policy (nil uses the global config)
+}
+
+// IVTBehavior holds the sliding window counts observed for a request, including the request itself
+// Counts are zero for identifiers the request did not carry
+type IVTBehavior struct {
+	Window        time.Duration
+	IPRequests    int64 // Requests from the IP
+	IFARequests   int64 // Requests carrying the IFA
+	UserRequests  int64 // Requests carrying the user ID
+	IPDomains     int64 // Distinct domains requested from the IP
+	RequestIDSeen int64 // Times the publisher sent this request ID
+}
+
+// behaviorKey is one sliding window updated for a request
+type behaviorKey struct {
+	key    string
+	member string
+	count  *int64
+}
+
+// trackBehavior records the request in the shared sliding windows and returns the counts
+func trackBehavior(ctx context.Context, client IVTBehaviorClient, ip string, req *IVTRequest, window time.Duration) (*IVTBehavior, error) {
+	behavior := &IVTBehavior{Window: window}
+	eventID := newIVTEventID()
+
+	var keys []behaviorKey
+	if ip != "" {
+		keys = append(keys, behaviorKey{RedisIVTPrefix + "ip:" + ip, eventID, &behavior.IPRequests})
+		if req.Domain != "" {
+			keys = append(keys, behaviorKey{RedisIVTPrefix + "ipdomains:" + ip, req.Domain, &behavior.IPDomains})
+		}
+	}
+	if ifa := strings.ToLower(strings.TrimSpace(req.IFA)); ifa != "" && ifa != zeroIFA {
+		keys = append(keys, behaviorKey{RedisIVTPrefix + "ifa:" + hashIVTID(ifa), eventID, &behavior.IFARequests})
+	}
+	if req.UserID != "" {
+		keys = append(keys, behaviorKey{RedisIVTPrefix + "user:" + hashIVTID(req.PublisherID+":"+req.UserID), eventID, &behavior.UserRequests})
+	}
+	if req.RequestID != "" {
+		keys = append(keys, behaviorKey{RedisIVTPrefix + "reqid:" + hashIVTID(req.PublisherID+":"+req.RequestID), eventID, &behavior.RequestIDSeen})
+	}
+	if len(keys) == 0 {
+		return behavior, nil
+	}
+
+	redisKeys := make([]string, len(keys))
+	args := make([]interface{}, 0, len(keys)+2)
+	args = append(args, time.Now().UnixMilli(), window.Milliseconds())
+	for i, k := range keys {
+		redisKeys[i] = k.key
+		args = append(args, k.member)
+	}
+
+	raw, err := client.Eval(ctx, slidingWindowScript, redisKeys, args...)
+	if err != nil {
+		return nil, err
+	}
+	counts, ok := raw.([]interface{})
+	if !ok || len(counts) != len(keys) {
+		return nil, fmt.Errorf("unexpected sliding window result %T", raw)
+	}
+	for i, k := range keys {
+		if n, ok := counts[i].(int64); ok {
+			*k.count = n
+		}
+	}
+	return behavior, nil
+}
+
+// behaviorSignals converts sliding window counts into IVT signals using the configured thresholds
+// A zero threshold disables its check
+func behaviorSignals(b *IVTBehavior, cfg *IVTConfig) []IVTSignal {
+	var signals []IVTSignal
+	add := func(signalType, severity, description string) {
+		signals = append(signals, IVTSignal{
+			Type:        signalType,
+			Severity:    severity,
+			Description: description,
+			DetectedAt:  time.Now(),
+		})
+	}
+	window := b.Window.String()
+
+	// Shared IPs (carrier NAT, offices) legitimately send bursts, so IP velocity alone doesn't block
+	if cfg.MaxRequestsPerIP > 0 && b.IPRequests > cfg.MaxRequestsPerIP {
+		add("ip_velocity", "medium", strconv.FormatInt(b.IPRequests, 10)+" requests from IP in "+window)
+	}
+	if cfg.MaxRequestsPerIFA > 0 && b.IFARequests > cfg.MaxRequestsPerIFA {
+		add("ifa_velocity", "high", strconv.FormatInt(b.IFARequests, 10)+" requests for IFA in "+window)
+	}
+	if cfg.MaxRequestsPerUser > 0 && b.UserRequests > cfg.MaxRequestsPerUser {
+		add("user_velocity", "high", strconv.FormatInt(b.UserRequests, 10)+" requests for user ID in "+window)
+	}
+	if cfg.MaxDomainsPerIP > 0 && b.IPDomains > cfg.MaxDomainsPerIP {
+		add("domain_fanout", "high", strconv.FormatInt(b.IPDomains, 10)+" domains from IP in "+window)
+	}
+	if b.RequestIDSeen > 1 {
+		add("duplicate_request_id", "high", "request ID seen "+strconv.FormatInt(b.RequestIDSeen, 10)+" times in "+window)
+	}
+	return signals
+}
+
+// deviceUAMismatch returns why the device fields contradict the user agent, or "" if they are consistent
+func deviceUAMismatch(ua, deviceOS, deviceMake string, deviceType int) string {
+	ua = strings.ToLower(ua)
+	deviceOS = strings.ToLower(deviceOS)
+	deviceMake = strings.ToLower(deviceMake)
+
+	iosUA := strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad") || strings.Contains(ua, "ipod")
+	androidUA := strings.Contains(ua, "android")
+	iosOS := deviceOS == "ios" || deviceOS == "ipados" || deviceOS == "iphone os"
+	androidOS := deviceOS == "android"
+
+	switch {
+	case iosUA && androidOS:
+		return "iOS user agent with device.os " + deviceOS
+	case androidUA && iosOS:
+		return "Android user agent with device.os " + deviceOS
+	case deviceMake == "apple" && (androidOS || androidUA):
+		return "Apple device running Android"
+	case (deviceType == deviceTypePhone || deviceType == deviceTypeTablet) && strings.Contains(ua, "windows nt"):
+		return "handheld device type with a Windows desktop user agent"
+	}
+	return ""
+}
+
+// hashIVTID hashes identifiers so raw IFAs, user IDs and request IDs are never stored
+func hashIVTID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:16])
+}
+
+// newIVTEventID returns a unique sorted set member for one request
+func newIVTEventID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:]) //nolint:errcheck // crypto/rand.Read does not fail on supported platforms
+	return strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + hex.EncodeToString(b[:])
+}
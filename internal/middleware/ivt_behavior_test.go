@@ -0,0 +1,279 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/thenexusengine/tne_springwire/pkg/redis"
+)
+
+// setupBehaviorRedis returns a Redis client backed by miniredis (test helper)
+func setupBehaviorRedis(t *testing.T) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client, err := redis.New("redis://" + mr.Addr())
+	if err != nil {
+		t.Fatalf("Failed to create Redis client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client, mr
+}
+
+// failingBehaviorClient simulates a Redis outage
+type failingBehaviorClient struct{}
+
+func (failingBehaviorClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestTrackBehavior_Counts(t *testing.T) {
+	client, mr := setupBehaviorRedis(t)
+	ctx := context.Background()
+
+	var b *IVTBehavior
+	for i, domain := range []string{"a.com", "b.com", "a.com"} {
+		var err error
+		b, err = trackBehavior(ctx, client, "203.0.113.9", &IVTRequest{
+			PublisherID: "pub1",
+			Domain:      domain,
+			RequestID:   "req-" + domain,
+			IFA:         "AAAA-BBBB",
+			UserID:      "u1",
+		}, time.Minute)
+		if err != nil {
+			t.Fatalf("request %d: track failed: %v", i, err)
+		}
+	}
+
+	if b.IPRequests != 3 || b.IFARequests != 3 || b.UserRequests != 3 {
+		t.Errorf("expected 3 requests per identifier, got %+v", b)
+	}
+	if b.IPDomains != 2 {
+		t.Errorf("expected 2 distinct domains, got %d", b.IPDomains)
+	}
+	if b.RequestIDSeen != 2 {
+		t.Errorf("expected the repeated a.com request ID to be seen twice, got %d", b.RequestIDSeen)
+	}
+
+	// Identifiers are hashed before they reach Redis, and every key expires
+	for _, key := range mr.Keys() {
+		if strings.Contains(strings.ToLower(key), "aaaa-bbbb") || strings.HasSuffix(key, ":u1") {
+			t.Errorf("raw identifier stored in key %s", key)
+		}
+		if mr.TTL(key) <= 0 {
+			t.Errorf("expected TTL on %s", key)
+		}
+	}
+}
+
+func TestTrackBehavior_WindowSlides(t *testing.T) {
+	client, _ := setupBehaviorRedis(t)
+	ctx := context.Background()
+	req := &IVTRequest{Domain: "a.com"}
+	window := 100 * time.Millisecond
+
+	for i := 0; i < 3; i++ {
+		if _, err := trackBehavior(ctx, client, "198.51.100.1", req, window); err != nil {
+			t.Fatalf("track failed: %v", err)
+		}
+	}
+	time.Sleep(150 * time.Millisecond)
+
+	b, err := trackBehavior(ctx, client, "198.51.100.1", req, window)
+	if err != nil {
+		t.Fatalf("track failed: %v", err)
+	}
+	if b.IPRequests != 1 {
+		t.Errorf("expected requests older than the window to be dropped, got %d", b.IPRequests)
+	}
+}
+
+func TestTrackBehavior_SkipsMissingIdentifiers(t *testing.T) {
+	client, mr := setupBehaviorRedis(t)
+
+	b, err := trackBehavior(context.Background(), client, "", &IVTRequest{IFA: zeroIFA}, time.Minute)
+	if err != nil {
+		t.Fatalf("track failed: %v", err)
+	}
+	if b.IPRequests != 0 || b.IFARequests != 0 || len(mr.Keys()) != 0 {
+		t.Errorf("expected nothing tracked for a zeroed IFA and no IP, got %+v keys=%v", b, mr.Keys())
+	}
+}
+
+func TestBehaviorSignals(t *testing.T) {
+	cfg := &IVTConfig{MaxRequestsPerIP: 10, MaxRequestsPerIFA: 5, MaxRequestsPerUser: 5, MaxDomainsPerIP: 3}
+
+	tests := []struct {
+		name     string
+		behavior IVTBehavior
+		want     []string
+	}{
+		{"normal", IVTBehavior{IPRequests: 10, IFARequests: 5, UserRequests: 5, IPDomains: 3, RequestIDSeen: 1}, nil},
+		{"ip velocity", IVTBehavior{IPRequests: 11}, []string{"ip_velocity"}},
+		{"ifa and user velocity", IVTBehavior{IFARequests: 6, UserRequests: 6}, []string{"ifa_velocity", "user_velocity"}},
+		{"domain fan-out", IVTBehavior{IPDomains: 4}, []string{"domain_fanout"}},
+		{"duplicate request id", IVTBehavior{RequestIDSeen: 2}, []string{"duplicate_request_id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.behavior.Window = time.Minute
+			signals := behaviorSignals(&tt.behavior, cfg)
+			if len(signals) != len(tt.want) {
+				t.Fatalf("expected %v, got %+v", tt.want, signals)
+			}
+			for i, want := range tt.want {
+				if signals[i].Type != want {
+					t.Errorf("expected %s, got %s", want, signals[i].Type)
+				}
+			}
+		})
+	}
+
+	// Zero thresholds disable their checks
+	if signals := behaviorSignals(&IVTBehavior{IPRequests: 1000, IPDomains: 1000}, &IVTConfig{}); len(signals) != 0 {
+		t.Errorf("expected no signals with thresholds disabled, got %+v", signals)
+	}
+}
+
+func TestDeviceUAMismatch(t *testing.T) {
+	const (
+		iphoneUA  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148"
+		androidUA = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36"
+		windowsUA = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36"
+	)
+
+	tests := []struct {
+		name       string
+		ua         string
+		os         string
+		make       string
+		deviceType int
+		mismatch   bool
+	}{
+		{"consistent iPhone", iphoneUA, "iOS", "Apple", deviceTypePhone, false},
+		{"consistent Android", androidUA, "Android", "Google", deviceTypePhone, false},
+		{"desktop", windowsUA, "Windows", "", 2, false},
+		{"no device fields", iphoneUA, "", "", 0, false},
+		{"iPhone UA on Android", iphoneUA, "Android", "", 0, true},
+		{"Android UA on iOS", androidUA, "iOS", "", 0, true},
+		{"Apple make on Android", androidUA, "", "Apple", 0, true},
+		{"phone with Windows UA", windowsUA, "", "", deviceTypePhone, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := deviceUAMismatch(tt.ua, tt.os, tt.make, tt.deviceType)
+			if (reason != "") != tt.mismatch {
+				t.Errorf("expected mismatch=%v, got %q", tt.mismatch, reason)
+			}
+		})
+	}
+}
+
+// newBehaviorDetector returns a blocking detector with low behavioral thresholds (test helper)
+func newBehaviorDetector(client IVTBehaviorClient) *IVTDetector {
+	detector := NewIVTDetector(&IVTConfig{
+		MonitoringEnabled: true,
+		BlockingEnabled:   true,
+		CheckBehavior:     true,
+		BehaviorWindow:    time.Minute,
+		BehaviorTimeout:   time.Second,
+		MaxRequestsPerIP:  100,
+		MaxRequestsPerIFA: 2,
+		MaxDomainsPerIP:   10,
+	})
+	detector.SetBehaviorClient(client)
+	return detector
+}
+
+func TestIVTDetector_Behavior(t *testing.T) {
+	client, _ := setupBehaviorRedis(t)
+	detector := newBehaviorDetector(client)
+
+	var result *IVTResult
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil)
+		req.Header.Set("User-Agent", "Mozilla/5.0 Chrome/120.0")
+		result = detector.ValidateRequest(context.Background(), req, &IVTRequest{
+			PublisherID: "pub1",
+			Domain:      "example.com",
+			RequestID:   "same-id",
+			DeviceIP:    "203.0.113.50",
+			IFA:         "ifa-1",
+		})
+	}
+
+	if result.Behavior == nil || result.Behavior.IFARequests != 3 || result.Behavior.RequestIDSeen != 3 {
+		t.Fatalf("expected behavior counts on the result, got %+v", result.Behavior)
+	}
+	types := map[string]bool{}
+	for _, s := range result.Signals {
+		types[s.Type] = true
+	}
+	if !types["ifa_velocity"] || !types["duplicate_request_id"] {
+		t.Errorf("expected IFA velocity and duplicate request ID signals, got %+v", result.Signals)
+	}
+	if !result.ShouldBlock || result.Score < 70 {
+		t.Errorf("expected behavioral signals to block, got score %d", result.Score)
+	}
+
+	metrics := detector.GetMetrics()
+	if metrics.VelocityHits == 0 || metrics.DuplicateReqIDs == 0 {
+		t.Errorf("expected behavioral metrics, got velocity=%d duplicates=%d", metrics.VelocityHits, metrics.DuplicateReqIDs)
+	}
+}
+
+func TestIVTDetector_BehaviorRedisFailure(t *testing.T) {
+	detector := newBehaviorDetector(failingBehaviorClient{})
+
+	req := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 Chrome/120.0")
+	result := detector.ValidateRequest(context.Background(), req, &IVTRequest{RequestID: "r1", Domain: "example.com"})
+
+	if result.Behavior != nil || len(result.Signals) != 0 || !result.IsValid {
+		t.Errorf("expected Redis errors to skip behavior checks, got %+v", result)
+	}
+}
+
+func TestIVTDetector_DeviceMismatch(t *testing.T) {
+	detector := newBehaviorDetector(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)")
+	result := detector.ValidateRequest(context.Background(), req, &IVTRequest{DeviceOS: "android"})
+
+	if len(result.Signals) != 1 || result.Signals[0].Type != "device_mismatch" {
+		t.Errorf("expected device mismatch from the User-Agent header, got %+v", result.Signals)
+	}
+	if result.Behavior != nil {
+		t.Error("expected no behavior without a Redis client")
+	}
+}
+
+func TestIVTRequestFromBidRequest(t *testing.T) {
+	var minReq minimalBidRequest
+	body := `{"id":"r1","site":{"domain":"example.com"},
+		"device":{"ip":"203.0.113.1","ifa":"ifa-1","ua":"UA","os":"iOS","make":"Apple","devicetype":4},
+		"user":{"id":"u1"}}`
+	if err := json.Unmarshal([]byte(body), &minReq); err != nil {
+		t.Fatal(err)
+	}
+
+	got := ivtRequest(&minReq, "pub1", "example.com")
+	want := IVTRequest{
+		PublisherID: "pub1", Domain: "example.com", RequestID: "r1", DeviceIP: "203.0.113.1",
+		IFA: "ifa-1", UserID: "u1", DeviceUA: "UA", DeviceOS: "iOS", DeviceMake: "Apple", DeviceType: 4,
+	}
+	if *got != want {
+		t.Errorf("expected %+v, got %+v", want, *got)
+	}
+}
@@ -12,9 +12,9 @@ import (
 	"sync"
 	"time"
 
-	// TODO: Re-enable when geoip2 dependency is fixed in CI
-	// "github.com/oschwald/geoip2-golang"
 	"github.com/rs/zerolog/log"
+	"github.com/thenexusengine/tne_springwire/internal/geoip"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
 )
 
 // IVTConfig holds Invalid Traffic detection configuration
@@ -22,15 +22,53 @@ import (
 type IVTConfig struct {
 	MonitoringEnabled    bool     // Enable IVT detection, logging, and metrics
 	BlockingEnabled      bool     // Block high-score traffic (requires MonitoringEnabled)
+	BlockThreshold       int      // Score at which traffic is flagged and blocked (0 = DefaultIVTBlockThreshold)
 	CheckUserAgent       bool     // Validate user agent patterns
 	CheckReferer         bool     // Validate referer against domain
 	CheckGeo             bool     // Validate IP geo restrictions (requires GeoIP)
+	CheckIPReputation    bool     // Match client IPs against datacenter, proxy and bot ASN lists
 	CheckRateLimit       bool     // Already implemented in publisher_auth
 	AllowedCountries     []string // Whitelist of country codes (empty = all allowed)
 	BlockedCountries     []string // Blacklist of country codes
 	SuspiciousUAPatterns []string // Regex patterns for suspicious user agents
 	RequireReferer       bool     // Require referer header (strict mode)
 	GeoIPDBPath          string   // Path to MaxMind GeoIP2/GeoLite2 database file
+
+	// IP reputation lists (read at startup, then reloaded when the files change)
+	DatacenterListPath   string        // Datacenter and cloud provider CIDRs
+	ProxyListPath        string        // Known proxy and VPN CIDRs
+	BotASNListPath       string        // CIDRs announced by bot and crawler ASNs
+	IPListReloadInterval time.Duration // How often list files are checked for changes (0 disables)
+
+	// Behavioral checks over sliding windows shared through Redis (0 disables a threshold)
+	CheckBehavior      bool          // Track request velocity, domain fan-out, repeated request IDs and device/UA consistency
+	BehaviorWindow     time.Duration // Sliding window length
+	BehaviorTimeout    time.Duration // Redis budget per request; on timeout behavior is skipped
+	MaxRequestsPerIP   int64         // Requests per IP per window
+	MaxRequestsPerIFA  int64         // Requests per device IFA per window
+	MaxRequestsPerUser int64         // Requests per publisher user ID per window
+	MaxDomainsPerIP    int64         // Distinct domains per IP per window
+}
+
+// DefaultSuspiciousUAPatterns are the regex patterns IVT detection flags by default
+var DefaultSuspiciousUAPatterns = []string{
+	// Bots and scrapers (common patterns)
+	`(?i)bot`,
+	`(?i)crawler`,
+	`(?i)spider`,
+	`(?i)scraper`,
+	`(?i)curl`,
+	`(?i)wget`,
+	`(?i)python`,
+	`(?i)\bjava\b`, // Match "java" as whole word (not in "javascript")
+	`(?i)phantom`,
+	`(?i)headless`,
+	`(?i)selenium`,
+	// Suspicious patterns
+	`^$`,            // Empty UA
+	`^Mozilla/4.0$`, // Ancient UA
+	`(?i)test`,
+	`(?i)scanner`,
 }
 
 // DefaultIVTConfig returns production-safe defaults with environment variable overrides
@@ -60,6 +98,26 @@ func DefaultIVTConfig() *IVTConfig {
 		return []string{}
 	}
 
+	// Helper to parse duration env vars
+	parseDuration := func(envKey string, defaultVal time.Duration) time.Duration {
+		if val := os.Getenv(envKey); val != "" {
+			if parsed, err := time.ParseDuration(val); err == nil && parsed >= 0 {
+				return parsed
+			}
+		}
+		return defaultVal
+	}
+
+	// Helper to parse non-negative int env vars
+	parseInt := func(envKey string, defaultVal int64) int64 {
+		if val := os.Getenv(envKey); val != "" {
+			if parsed, err := strconv.ParseInt(val, 10, 64); err == nil && parsed >= 0 {
+				return parsed
+			}
+		}
+		return defaultVal
+	}
+
 	// Parse monitoring and blocking flags
 	monitoringEnabled := parseBool("IVT_MONITORING_ENABLED", true)
 	blockingEnabled := parseBool("IVT_BLOCKING_ENABLED", false)
@@ -77,12 +135,18 @@ func DefaultIVTConfig() *IVTConfig {
 		// IVT_BLOCKING_ENABLED: Block high-score traffic (default: false = monitoring only)
 		BlockingEnabled: blockingEnabled,
 
+		// IVT_BLOCK_THRESHOLD: Score at which traffic is flagged and blocked (default: 70)
+		BlockThreshold: int(parseInt("IVT_BLOCK_THRESHOLD", DefaultIVTBlockThreshold)),
+
 		// Individual check toggles
 		CheckUserAgent: parseBool("IVT_CHECK_UA", true),
 		CheckReferer:   parseBool("IVT_CHECK_REFERER", true),
 		CheckGeo:       parseBool("IVT_CHECK_GEO", false),
 		CheckRateLimit: parseBool("IVT_CHECK_RATELIMIT", true),
 
+		// IVT_CHECK_IP_REPUTATION: Match client IPs against the configured lists (default: true)
+		CheckIPReputation: parseBool("IVT_CHECK_IP_REPUTATION", true),
+
 		// Geographic restrictions
 		// IVT_ALLOWED_COUNTRIES: Comma-separated country codes (e.g., "US,GB,CA")
 		AllowedCountries: parseStringSlice("IVT_ALLOWED_COUNTRIES"),
@@ -90,26 +154,8 @@ func DefaultIVTConfig() *IVTConfig {
 		// IVT_BLOCKED_COUNTRIES: Comma-separated country codes (e.g., "CN,RU")
 		BlockedCountries: parseStringSlice("IVT_BLOCKED_COUNTRIES"),
 
-		// Default suspicious UA patterns (can be extended via code)
-		SuspiciousUAPatterns: []string{
-			// Bots and scrapers (common patterns)
-			`(?i)bot`,
-			`(?i)crawler`,
-			`(?i)spider`,
-			`(?i)scraper`,
-			`(?i)curl`,
-			`(?i)wget`,
-			`(?i)python`,
-			`(?i)\bjava\b`, // Match "java" as whole word (not in "javascript")
-			`(?i)phantom`,
-			`(?i)headless`,
-			`(?i)selenium`,
-			// Suspicious patterns
-			`^$`,            // Empty UA
-			`^Mozilla/4.0$`, // Ancient UA
-			`(?i)test`,
-			`(?i)scanner`,
-		},
+		// Default suspicious UA patterns (can be replaced through the server config file)
+		SuspiciousUAPatterns: append([]string(nil), DefaultSuspiciousUAPatterns...),
 
 		// IVT_REQUIRE_REFERER: Strict mode - require referer header (default: false)
 		RequireReferer: parseBool("IVT_REQUIRE_REFERER", false),
@@ -117,6 +163,25 @@ func DefaultIVTConfig() *IVTConfig {
 		// GEOIP_DB_PATH: Path to MaxMind GeoIP2/GeoLite2 database file
 		// Example: "/usr/share/GeoIP/GeoLite2-Country.mmdb"
 		GeoIPDBPath: os.Getenv("GEOIP_DB_PATH"),
+
+		// IVT_DATACENTER_LIST, IVT_PROXY_LIST, IVT_BOT_ASN_LIST: Paths to IP/CIDR list files
+		DatacenterListPath: os.Getenv("IVT_DATACENTER_LIST"),
+		ProxyListPath:      os.Getenv("IVT_PROXY_LIST"),
+		BotASNListPath:     os.Getenv("IVT_BOT_ASN_LIST"),
+
+		// IVT_IP_LIST_RELOAD_INTERVAL: How often list files are checked for changes (default: 1m)
+		IPListReloadInterval: parseDuration("IVT_IP_LIST_RELOAD_INTERVAL", time.Minute),
+
+		// IVT_CHECK_BEHAVIOR: Behavioral checks (default: true; velocity and fan-out need Redis)
+		CheckBehavior:   parseBool("IVT_CHECK_BEHAVIOR", true),
+		BehaviorWindow:  parseDuration("IVT_BEHAVIOR_WINDOW", time.Minute),
+		BehaviorTimeout: parseDuration("IVT_BEHAVIOR_TIMEOUT", 10*time.Millisecond),
+
+		// IVT_MAX_*: Per-window thresholds above which a behavioral signal is raised
+		MaxRequestsPerIP:   parseInt("IVT_MAX_REQUESTS_PER_IP", 600),
+		MaxRequestsPerIFA:  parseInt("IVT_MAX_REQUESTS_PER_IFA", 120),
+		MaxRequestsPerUser: parseInt("IVT_MAX_REQUESTS_PER_USER", 120),
+		MaxDomainsPerIP:    parseInt("IVT_MAX_DOMAINS_PER_IP", 20),
 	}
 
 	return config
@@ -125,91 +190,107 @@ func DefaultIVTConfig() *IVTConfig {
 // IVTSignal represents a detected IVT indicator
 type IVTSignal struct {
 	Type        string    // Type of signal (domain_mismatch, suspicious_ua, etc.)
-	Severity    string    // low, medium, high
+	Severity    string    // low, medium, high, critical
 	Description string    // Human-readable description
 	DetectedAt  time.Time // When detected
 }
 
 // IVTResult contains the validation result
 type IVTResult struct {
-	IsValid       bool          // Overall validity
-	Signals       []IVTSignal   // All detected signals
-	Score         int           // IVT score (0-100, higher = more suspicious)
-	BlockReason   string        // Reason for blocking (if blocked)
-	ShouldBlock   bool          // Whether to block this request
-	PublisherID   string        // Publisher ID from request
-	Domain        string        // Domain from request
-	IPAddress     string        // Client IP
-	UserAgent     string        // User agent
-	DetectionTime time.Duration // Time taken to detect
+	IsValid       bool                // Overall validity
+	Signals       []IVTSignal         // All detected signals
+	Score         int                 // IVT score (0-100, higher = more suspicious)
+	BlockReason   string              // Reason for blocking (if blocked)
+	ShouldBlock   bool                // Whether to block this request
+	NoBidReason   openrtb.NoBidReason // OpenRTB no-bid reason for blocked SIVT (0 if none)
+	Behavior      *IVTBehavior        // Sliding window counts (nil if not tracked)
+	PublisherID   string              // Publisher ID from request
+	Domain        string              // Domain from request
+	IPAddress     string              // Client IP
+	UserAgent     string              // User agent
+	DetectionTime time.Duration       // Time taken to detect
 }
 
 // GeoIPLookup provides geographic location lookup for IP addresses
 type GeoIPLookup interface {
-	// LookupCountry returns the ISO country code for an IP address
+	// LookupCountry returns the ISO 3166-1 alpha-2 country code for an IP address
 	LookupCountry(ip string) (string, error)
+	// LookupRegion returns the ISO 3166-2 subdivision code (e.g. "CA") for an IP address
+	LookupRegion(ip string) (string, error)
+	// LookupCity returns the city name for an IP address
+	LookupCity(ip string) (string, error)
 	// Close releases resources
 	Close() error
 }
 
 // MaxMindGeoIP implements GeoIPLookup using MaxMind GeoIP2/GeoLite2 databases
-// TODO: Re-enable when geoip2 dependency is fixed in CI
+// Region and city lookups require a City database; Country databases return empty strings
 type MaxMindGeoIP struct {
-	// reader *geoip2.Reader
+	reader *geoip.Reader
 }
 
 // NewMaxMindGeoIP creates a new MaxMind GeoIP lookup instance
-// TODO: Re-enable when geoip2 dependency is fixed in CI
+// Returns nil without error when dbPath is empty (GeoIP disabled)
 func NewMaxMindGeoIP(dbPath string) (*MaxMindGeoIP, error) {
 	if dbPath == "" {
 		return nil, nil // GeoIP disabled
 	}
 
-	// Temporarily disabled due to CI dependency issues
-	log.Warn().Msg("GeoIP functionality temporarily disabled - geoip2 dependency issues")
-	return nil, nil
+	reader, err := geoip.Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MaxMindGeoIP{reader: reader}, nil
+}
+
+// lookup returns the location record for an IP address (nil if unknown)
+func (g *MaxMindGeoIP) lookup(ipStr string) (*geoip.Record, error) {
+	if g == nil || g.reader == nil {
+		return nil, nil
+	}
+
+	ip := net.ParseIP(strings.TrimSpace(ipStr))
+	if ip == nil {
+		return nil, nil // Invalid IP
+	}
 
-	// reader, err := geoip2.Open(dbPath)
-	// if err != nil {
-	// 	return nil, err
-	// }
-	//
-	// return &MaxMindGeoIP{reader: reader}, nil
+	return g.reader.LookupRecord(ip)
 }
 
-// LookupCountry returns the ISO country code for an IP address
-// TODO: Re-enable when geoip2 dependency is fixed in CI
+// LookupCountry returns the ISO 3166-1 alpha-2 country code for an IP address
 func (g *MaxMindGeoIP) LookupCountry(ipStr string) (string, error) {
-	// Temporarily disabled
-	return "", nil
-
-	// if g == nil || g.reader == nil {
-	// 	return "", nil
-	// }
-	//
-	// ip := net.ParseIP(ipStr)
-	// if ip == nil {
-	// 	return "", nil // Invalid IP
-	// }
-	//
-	// record, err := g.reader.Country(ip)
-	// if err != nil {
-	// 	return "", err
-	// }
-	//
-	// return record.Country.IsoCode, nil
+	record, err := g.lookup(ipStr)
+	if err != nil || record == nil {
+		return "", err
+	}
+	return record.Country, nil
+}
+
+// LookupRegion returns the ISO 3166-2 subdivision code for an IP address
+func (g *MaxMindGeoIP) LookupRegion(ipStr string) (string, error) {
+	record, err := g.lookup(ipStr)
+	if err != nil || record == nil {
+		return "", err
+	}
+	return record.Region, nil
+}
+
+// LookupCity returns the city name for an IP address
+func (g *MaxMindGeoIP) LookupCity(ipStr string) (string, error) {
+	record, err := g.lookup(ipStr)
+	if err != nil || record == nil {
+		return "", err
+	}
+	return record.City, nil
 }
 
 // Close releases GeoIP database resources
-// TODO: Re-enable when geoip2 dependency is fixed in CI
 func (g *MaxMindGeoIP) Close() error {
-	// Temporarily disabled
+	if g != nil && g.reader != nil {
+		return g.reader.Close()
+	}
 	return nil
-
-	// if g != nil && g.reader != nil {
-	// 	return g.reader.Close()
-	// }
-	// return nil
 }
 
 // IVTDetector provides Invalid Traffic detection
@@ -217,11 +298,14 @@ type IVTDetector struct {
 	config  *IVTConfig
 	mu      sync.RWMutex
 	metrics *IVTMetrics
-	geoip   GeoIPLookup // GeoIP lookup service (nil if disabled)
+	geoip   GeoIPLookup   // GeoIP lookup service (nil if disabled)
+	ipRep   *IPReputation // IP reputation lists (nil if none configured)
+
+	behaviorClient IVTBehaviorClient // Redis for behavioral sliding windows (nil if disabled)
+	stats          *IVTStats         // Per-publisher aggregates
 
-	// Compiled regex patterns (cached for performance)
-	uaPatterns   []*regexp.Regexp
-	patternsOnce sync.Once
+	// Compiled SuspiciousUAPatterns, swapped together with config
+	uaPatterns []*regexp.Regexp
 }
 
 // IVTMetrics tracks IVT detection metrics
@@ -239,6 +323,13 @@ type IVTMetrics struct {
 	InvalidReferer   int64 // Invalid/missing referers
 	GeoMismatches    int64 // Geographic restrictions
 	RateLimitHits    int64 // Rate limit exceeded
+	DatacenterIPs    int64 // Datacenter and cloud IPs
+	ProxyIPs         int64 // Proxy and VPN IPs
+	BotASNIPs        int64 // IPs of known crawler ASNs
+	VelocityHits     int64 // IP, IFA or user ID request velocity exceeded
+	DomainFanouts    int64 // Too many domains from one IP
+	DuplicateReqIDs  int64 // Repeated bid request IDs
+	DeviceMismatches int64 // Device fields contradicting the user agent
 
 	// Performance
 	LastCheckTime    time.Time
@@ -252,51 +343,78 @@ func NewIVTDetector(config *IVTConfig) *IVTDetector {
 	}
 
 	// Initialize GeoIP if database path is provided
-	var geoip GeoIPLookup
+	var geoLookup GeoIPLookup
 	if config.GeoIPDBPath != "" {
 		maxmind, err := NewMaxMindGeoIP(config.GeoIPDBPath)
 		if err != nil {
 			log.Warn().Err(err).Str("path", config.GeoIPDBPath).Msg("Failed to initialize GeoIP database, geo checking disabled")
 		} else {
-			geoip = maxmind
+			geoLookup = maxmind
 			log.Info().Str("path", config.GeoIPDBPath).Msg("GeoIP database loaded successfully")
 		}
 	}
 
+	// Load IP reputation lists if any are configured
+	ipRep, err := NewIPReputation(config.DatacenterListPath, config.ProxyListPath, config.BotASNListPath, config.IPListReloadInterval)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load IP reputation lists, IP reputation checking disabled")
+	}
+	ipRep.Start()
+
 	return &IVTDetector{
-		config:  config,
-		metrics: &IVTMetrics{},
-		geoip:   geoip,
+		config:     config,
+		metrics:    &IVTMetrics{},
+		geoip:      geoLookup,
+		ipRep:      ipRep,
+		stats:      NewIVTStats(),
+		uaPatterns: compileUAPatterns(config.SuspiciousUAPatterns),
 	}
 }
 
-// compilePatterns compiles regex patterns once for performance
-func (d *IVTDetector) compilePatterns() {
-	d.patternsOnce.Do(func() {
-		d.mu.RLock()
-		patterns := d.config.SuspiciousUAPatterns
-		d.mu.RUnlock()
-
-		d.uaPatterns = make([]*regexp.Regexp, 0, len(patterns))
-		for _, pattern := range patterns {
-			if re, err := regexp.Compile(pattern); err == nil {
-				d.uaPatterns = append(d.uaPatterns, re)
-			} else {
-				log.Warn().Err(err).Str("pattern", pattern).Msg("Failed to compile IVT UA pattern")
-			}
+// compileUAPatterns compiles the suspicious UA patterns, skipping invalid ones
+func compileUAPatterns(patterns []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		if re, err := regexp.Compile(pattern); err == nil {
+			compiled = append(compiled, re)
+		} else {
+			log.Warn().Err(err).Str("pattern", pattern).Msg("Failed to compile IVT UA pattern")
 		}
-	})
+	}
+	return compiled
+}
+
+// SetBehaviorClient sets the Redis client used for behavioral sliding windows
+func (d *IVTDetector) SetBehaviorClient(client IVTBehaviorClient) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.behaviorClient = client
 }
 
 // Validate performs IVT detection on a request
 func (d *IVTDetector) Validate(ctx context.Context, r *http.Request, publisherID, domain string) *IVTResult {
+	return d.ValidateRequest(ctx, r, &IVTRequest{PublisherID: publisherID, Domain: domain})
+}
+
+// ValidateRequest performs IVT detection using bid request fields for the behavioral checks
+func (d *IVTDetector) ValidateRequest(ctx context.Context, r *http.Request, req *IVTRequest) *IVTResult {
 	startTime := time.Now()
+	publisherID, domain := req.PublisherID, req.Domain
 
 	// Snapshot entire config once to reduce lock contention
 	d.mu.RLock()
 	cfg := *d.config
+	uaPatterns := d.uaPatterns
+	behaviorClient := d.behaviorClient
 	d.mu.RUnlock()
 
+	// Publisher policy overrides the global config for this request only
+	applyIVTPolicy(req.Policy, &cfg)
+	threshold := cfg.BlockThreshold
+	if threshold <= 0 {
+		threshold = DefaultIVTBlockThreshold
+	}
+
 	result := &IVTResult{
 		IsValid:     true,
 		Signals:     []IVTSignal{},
@@ -314,18 +432,24 @@ func (d *IVTDetector) Validate(ctx context.Context, r *http.Request, publisherID
 	}
 
 	// Run all checks with snapshotted config
-	d.checkUserAgentWithConfig(r, result, &cfg)
+	d.checkUserAgentWithConfig(r, uaPatterns, result, &cfg)
 	d.checkRefererWithConfig(r, domain, result, &cfg)
 	d.checkGeoWithConfig(r, result, &cfg)
+	d.checkIPReputationWithConfig(result, &cfg)
+	d.checkDeviceWithConfig(r, req, result, &cfg)
+	d.checkBehaviorWithConfig(ctx, behaviorClient, req, result, &cfg)
 
 	// Calculate final score and decision
 	result.Score = d.calculateScore(result.Signals)
-	result.ShouldBlock = cfg.BlockingEnabled && result.Score >= 70 // Block at 70+ score
-	result.IsValid = result.Score < 70                             // Valid if score < 70
+	result.ShouldBlock = cfg.BlockingEnabled && result.Score >= threshold
+	result.IsValid = result.Score < threshold
 
 	if result.ShouldBlock && len(result.Signals) > 0 {
 		result.BlockReason = result.Signals[0].Description // Use first signal as reason
 	}
+	if !result.ShouldBlock {
+		result.NoBidReason = 0
+	}
 
 	result.DetectionTime = time.Since(startTime)
 
@@ -336,7 +460,7 @@ func (d *IVTDetector) Validate(ctx context.Context, r *http.Request, publisherID
 }
 
 // checkUserAgentWithConfig validates user agent patterns using snapshotted config
-func (d *IVTDetector) checkUserAgentWithConfig(r *http.Request, result *IVTResult, cfg *IVTConfig) {
+func (d *IVTDetector) checkUserAgentWithConfig(r *http.Request, uaPatterns []*regexp.Regexp, result *IVTResult, cfg *IVTConfig) {
 	if !cfg.CheckUserAgent {
 		return
 	}
@@ -355,8 +479,7 @@ func (d *IVTDetector) checkUserAgentWithConfig(r *http.Request, result *IVTResul
 	}
 
 	// Check against suspicious patterns
-	d.compilePatterns()
-	for _, pattern := range d.uaPatterns {
+	for _, pattern := range uaPatterns {
 		if pattern.MatchString(ua) {
 			result.Signals = append(result.Signals, IVTSignal{
 				Type:        "suspicious_ua",
@@ -433,9 +556,10 @@ func (d *IVTDetector) checkGeoWithConfig(r *http.Request, result *IVTResult, cfg
 		// No country found (private IP, unknown, etc.)
 		return
 	}
+	country = geoip.ToAlpha2(country)
 
 	// Check allowed countries whitelist
-	if len(cfg.AllowedCountries) > 0 && !contains(cfg.AllowedCountries, country) {
+	if len(cfg.AllowedCountries) > 0 && !containsCountry(cfg.AllowedCountries, country) {
 		result.Signals = append(result.Signals, IVTSignal{
 			Type:        "geo_restricted",
 			Severity:    "high",
@@ -449,7 +573,7 @@ func (d *IVTDetector) checkGeoWithConfig(r *http.Request, result *IVTResult, cfg
 	}
 
 	// Check blocked countries blacklist
-	if len(cfg.BlockedCountries) > 0 && contains(cfg.BlockedCountries, country) {
+	if len(cfg.BlockedCountries) > 0 && containsCountry(cfg.BlockedCountries, country) {
 		result.Signals = append(result.Signals, IVTSignal{
 			Type:        "geo_blocked",
 			Severity:    "high",
@@ -462,6 +586,84 @@ func (d *IVTDetector) checkGeoWithConfig(r *http.Request, result *IVTResult, cfg
 	}
 }
 
+// checkIPReputationWithConfig flags client IPs found in the datacenter, proxy or bot ASN lists
+// These are sophisticated IVT signals: a single match scores enough to block. They are placed
+// ahead of other signals, strictest first, so the block reason and no-bid reason name them.
+func (d *IVTDetector) checkIPReputationWithConfig(result *IVTResult, cfg *IVTConfig) {
+	if !cfg.CheckIPReputation || d.ipRep == nil || result.IPAddress == "" {
+		return
+	}
+
+	matches := d.ipRep.Lookup(result.IPAddress)
+	if len(matches) == 0 {
+		return
+	}
+
+	signals := make([]IVTSignal, 0, len(matches)+len(result.Signals))
+	for _, match := range matches {
+		description := "IP in " + match.Category + " list (" + match.Network + ")"
+		if match.Label != "" {
+			description = "IP in " + match.Category + " list (" + match.Network + " " + match.Label + ")"
+		}
+		signals = append(signals, IVTSignal{
+			Type:        match.Category + "_ip",
+			Severity:    "critical",
+			Description: description,
+			DetectedAt:  time.Now(),
+		})
+	}
+	result.Signals = append(signals, result.Signals...)
+	result.NoBidReason = IPCategoryNoBidReason(matches[0].Category)
+}
+
+// checkDeviceWithConfig flags device fields that contradict the user agent
+func (d *IVTDetector) checkDeviceWithConfig(r *http.Request, req *IVTRequest, result *IVTResult, cfg *IVTConfig) {
+	if !cfg.CheckBehavior {
+		return
+	}
+
+	ua := req.DeviceUA
+	if ua == "" {
+		ua = r.UserAgent()
+	}
+	if reason := deviceUAMismatch(ua, req.DeviceOS, req.DeviceMake, req.DeviceType); reason != "" {
+		result.Signals = append(result.Signals, IVTSignal{
+			Type:        "device_mismatch",
+			Severity:    "high",
+			Description: reason,
+			DetectedAt:  time.Now(),
+		})
+	}
+}
+
+// checkBehaviorWithConfig records the request in the Redis sliding windows and flags unusual activity
+// Behavior is tracked per device.ip when present, so server-to-server traffic isn't counted per server.
+// Redis errors skip the check rather than failing the request.
+func (d *IVTDetector) checkBehaviorWithConfig(ctx context.Context, client IVTBehaviorClient, req *IVTRequest, result *IVTResult, cfg *IVTConfig) {
+	if !cfg.CheckBehavior || client == nil || cfg.BehaviorWindow <= 0 {
+		return
+	}
+
+	ip := req.DeviceIP
+	if ip == "" {
+		ip = result.IPAddress
+	}
+
+	if cfg.BehaviorTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.BehaviorTimeout)
+		defer cancel()
+	}
+
+	behavior, err := trackBehavior(ctx, client, ip, req, cfg.BehaviorWindow)
+	if err != nil {
+		log.Debug().Err(err).Str("ip", ip).Msg("Behavioral IVT tracking failed")
+		return
+	}
+	result.Behavior = behavior
+	result.Signals = append(result.Signals, behaviorSignals(behavior, cfg)...)
+}
+
 // calculateScore computes IVT score from signals
 func (d *IVTDetector) calculateScore(signals []IVTSignal) int {
 	score := 0
@@ -473,6 +675,8 @@ func (d *IVTDetector) calculateScore(signals []IVTSignal) int {
 			score += 35
 		case "high":
 			score += 50
+		case "critical":
+			score += 100
 		}
 	}
 
@@ -486,6 +690,8 @@ func (d *IVTDetector) calculateScore(signals []IVTSignal) int {
 
 // updateMetrics updates detection metrics
 func (d *IVTDetector) updateMetrics(result *IVTResult) {
+	d.stats.Record(result)
+
 	d.metrics.mu.Lock()
 	defer d.metrics.mu.Unlock()
 
@@ -523,6 +729,20 @@ func (d *IVTDetector) updateMetrics(result *IVTResult) {
 			d.metrics.GeoMismatches++
 		case "rate_limit":
 			d.metrics.RateLimitHits++
+		case IPCategoryDatacenter + "_ip":
+			d.metrics.DatacenterIPs++
+		case IPCategoryProxy + "_ip":
+			d.metrics.ProxyIPs++
+		case IPCategoryBotASN + "_ip":
+			d.metrics.BotASNIPs++
+		case "ip_velocity", "ifa_velocity", "user_velocity":
+			d.metrics.VelocityHits++
+		case "domain_fanout":
+			d.metrics.DomainFanouts++
+		case "duplicate_request_id":
+			d.metrics.DuplicateReqIDs++
+		case "device_mismatch":
+			d.metrics.DeviceMismatches++
 		}
 	}
 }
@@ -541,6 +761,13 @@ func (d *IVTDetector) GetMetrics() IVTMetrics {
 		InvalidReferer:   d.metrics.InvalidReferer,
 		GeoMismatches:    d.metrics.GeoMismatches,
 		RateLimitHits:    d.metrics.RateLimitHits,
+		DatacenterIPs:    d.metrics.DatacenterIPs,
+		ProxyIPs:         d.metrics.ProxyIPs,
+		BotASNIPs:        d.metrics.BotASNIPs,
+		VelocityHits:     d.metrics.VelocityHits,
+		DomainFanouts:    d.metrics.DomainFanouts,
+		DuplicateReqIDs:  d.metrics.DuplicateReqIDs,
+		DeviceMismatches: d.metrics.DeviceMismatches,
 		LastCheckTime:    d.metrics.LastCheckTime,
 		AvgCheckDuration: d.metrics.AvgCheckDuration,
 	}
@@ -548,12 +775,13 @@ func (d *IVTDetector) GetMetrics() IVTMetrics {
 
 // SetConfig updates IVT configuration at runtime
 func (d *IVTDetector) SetConfig(config *IVTConfig) {
+	// Compile outside the lock; in-flight checks keep the patterns they snapshotted
+	uaPatterns := compileUAPatterns(config.SuspiciousUAPatterns)
+
 	d.mu.Lock()
 	defer d.mu.Unlock()
 	d.config = config
-
-	// Reset compiled patterns to force recompile
-	d.patternsOnce = sync.Once{}
+	d.uaPatterns = uaPatterns
 }
 
 // GetConfig returns current configuration
@@ -614,8 +842,34 @@ func contains(slice []string, val string) bool {
 	return false
 }
 
-// Close releases resources (GeoIP database)
+// containsCountry checks if a country list contains a country, accepting alpha-2 or alpha-3 entries
+func containsCountry(countries []string, alpha2 string) bool {
+	for _, c := range countries {
+		if geoip.ToAlpha2(c) == alpha2 {
+			return true
+		}
+	}
+	return false
+}
+
+// GeoIP returns the GeoIP lookup service (nil if disabled)
+func (d *IVTDetector) GeoIP() GeoIPLookup {
+	return d.geoip
+}
+
+// Stats returns the per-publisher IVT aggregates
+func (d *IVTDetector) Stats() *IVTStats {
+	return d.stats
+}
+
+// IPReputation returns the IP reputation lists (nil if none configured)
+func (d *IVTDetector) IPReputation() *IPReputation {
+	return d.ipRep
+}
+
+// Close releases resources (GeoIP database, IP list reloads)
 func (d *IVTDetector) Close() error {
+	d.ipRep.Stop()
 	if d.geoip != nil {
 		return d.geoip.Close()
 	}
@@ -2,12 +2,16 @@ package middleware
 
 import (
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
 // MockGeoIP implements GeoIPLookup for testing
 type MockGeoIP struct {
 	countryMap map[string]string // IP -> Country code
+	regionMap  map[string]string // IP -> Region code
+	cityMap    map[string]string // IP -> City name
 	err        error             // Error to return
 }
 
@@ -15,6 +19,8 @@ type MockGeoIP struct {
 func NewMockGeoIP() *MockGeoIP {
 	return &MockGeoIP{
 		countryMap: make(map[string]string),
+		regionMap:  make(map[string]string),
+		cityMap:    make(map[string]string),
 	}
 }
 
@@ -26,6 +32,22 @@ func (m *MockGeoIP) LookupCountry(ip string) (string, error) {
 	return m.countryMap[ip], nil
 }
 
+// LookupRegion returns the mocked region for an IP
+func (m *MockGeoIP) LookupRegion(ip string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	return m.regionMap[ip], nil
+}
+
+// LookupCity returns the mocked city for an IP
+func (m *MockGeoIP) LookupCity(ip string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	return m.cityMap[ip], nil
+}
+
 // Close is a no-op for the mock
 func (m *MockGeoIP) Close() error {
 	return nil
@@ -36,6 +58,12 @@ func (m *MockGeoIP) SetCountry(ip, country string) {
 	m.countryMap[ip] = country
 }
 
+// SetLocation sets the region and city for an IP (test helper)
+func (m *MockGeoIP) SetLocation(ip, region, city string) {
+	m.regionMap[ip] = region
+	m.cityMap[ip] = city
+}
+
 // SetError sets an error to return (test helper)
 func (m *MockGeoIP) SetError(err error) {
 	m.err = err
@@ -52,28 +80,48 @@ func TestMaxMindGeoIP_NewMaxMindGeoIP_EmptyPath(t *testing.T) {
 }
 
 func TestMaxMindGeoIP_NewMaxMindGeoIP_InvalidPath(t *testing.T) {
-	// TODO: Re-enable when geoip2 dependency is fixed
-	t.Skip("GeoIP functionality temporarily disabled")
-	// geoip, err := NewMaxMindGeoIP("/nonexistent/path/database.mmdb")
-	// if err == nil {
-	// 	t.Error("Expected error for invalid path")
-	// }
-	// if geoip != nil {
-	// 	t.Error("Expected nil GeoIP for invalid path")
-	// }
+	geoip, err := NewMaxMindGeoIP("/nonexistent/path/database.mmdb")
+	if err == nil {
+		t.Error("Expected error for invalid path")
+	}
+	if geoip != nil {
+		t.Error("Expected nil GeoIP for invalid path")
+	}
+}
+
+func TestMaxMindGeoIP_NewMaxMindGeoIP_NotADatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bogus.mmdb")
+	if err := os.WriteFile(path, []byte("not a maxmind database"), 0o600); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	geoip, err := NewMaxMindGeoIP(path)
+	if err == nil {
+		t.Error("Expected error for invalid database file")
+	}
+	if geoip != nil {
+		t.Error("Expected nil GeoIP for invalid database file")
+	}
 }
 
 func TestMaxMindGeoIP_LookupCountry_NilReader(t *testing.T) {
-	// TODO: Re-enable when geoip2 dependency is fixed
-	t.Skip("GeoIP functionality temporarily disabled")
-	// geoip := &MaxMindGeoIP{reader: nil}
-	// country, err := geoip.LookupCountry("8.8.8.8")
-	// if err != nil {
-	// 	t.Errorf("Expected no error for nil reader, got %v", err)
-	// }
-	// if country != "" {
-	// 	t.Errorf("Expected empty country for nil reader, got %s", country)
-	// }
+	geoip := &MaxMindGeoIP{reader: nil}
+	country, err := geoip.LookupCountry("8.8.8.8")
+	if err != nil {
+		t.Errorf("Expected no error for nil reader, got %v", err)
+	}
+	if country != "" {
+		t.Errorf("Expected empty country for nil reader, got %s", country)
+	}
+
+	region, err := geoip.LookupRegion("8.8.8.8")
+	if err != nil || region != "" {
+		t.Errorf("Expected empty region for nil reader, got %q (err=%v)", region, err)
+	}
+	city, err := geoip.LookupCity("8.8.8.8")
+	if err != nil || city != "" {
+		t.Errorf("Expected empty city for nil reader, got %q (err=%v)", city, err)
+	}
 }
 
 func TestMaxMindGeoIP_LookupCountry_InvalidIP(t *testing.T) {
@@ -88,13 +136,11 @@ func TestMaxMindGeoIP_LookupCountry_InvalidIP(t *testing.T) {
 }
 
 func TestMaxMindGeoIP_Close_NilReader(t *testing.T) {
-	// TODO: Re-enable when geoip2 dependency is fixed
-	t.Skip("GeoIP functionality temporarily disabled")
-	// geoip := &MaxMindGeoIP{reader: nil}
-	// err := geoip.Close()
-	// if err != nil {
-	// 	t.Errorf("Expected no error closing nil reader, got %v", err)
-	// }
+	geoip := &MaxMindGeoIP{reader: nil}
+	err := geoip.Close()
+	if err != nil {
+		t.Errorf("Expected no error closing nil reader, got %v", err)
+	}
 }
 
 func TestCheckGeoWithConfig_Disabled(t *testing.T) {
@@ -500,27 +546,28 @@ func TestIVTDetector_Close(t *testing.T) {
 }
 
 func TestNewIVTDetector_WithGeoIPPath(t *testing.T) {
-	// TODO: Re-enable when geoip2 dependency is fixed
-	t.Skip("GeoIP functionality temporarily disabled")
-	// // Test with invalid path (should fail gracefully)
-	// config := &IVTConfig{
-	// 	GeoIPDBPath: "/nonexistent/path/database.mmdb",
-	// }
-	//
-	// detector := NewIVTDetector(config)
-	// if detector == nil {
-	// 	t.Fatal("Expected detector to be created even with invalid GeoIP path")
-	// }
-	//
-	// // GeoIP should be nil since the path is invalid
-	// if detector.geoip != nil {
-	// 	t.Error("Expected GeoIP to be nil for invalid path")
-	// }
-	//
-	// // Cleanup
-	// if err := detector.Close(); err != nil {
-	// 	t.Errorf("Error closing detector: %v", err)
-	// }
+	// Test with invalid path (should fail gracefully)
+	config := &IVTConfig{
+		GeoIPDBPath: "/nonexistent/path/database.mmdb",
+	}
+
+	detector := NewIVTDetector(config)
+	if detector == nil {
+		t.Fatal("Expected detector to be created even with invalid GeoIP path")
+	}
+
+	// GeoIP should be nil since the path is invalid
+	if detector.geoip != nil {
+		t.Error("Expected GeoIP to be nil for invalid path")
+	}
+	if detector.GeoIP() != nil {
+		t.Error("Expected GeoIP() to return nil for invalid path")
+	}
+
+	// Cleanup
+	if err := detector.Close(); err != nil {
+		t.Errorf("Error closing detector: %v", err)
+	}
 }
 
 func TestNewIVTDetector_WithoutGeoIPPath(t *testing.T) {
@@ -573,3 +620,52 @@ func TestCheckGeoWithConfig_XForwardedFor(t *testing.T) {
 		t.Errorf("Expected geo_blocked signal, got %s", result.Signals[0].Type)
 	}
 }
+
+func TestCheckGeoWithConfig_Alpha3CountryLists(t *testing.T) {
+	config := &IVTConfig{
+		CheckGeo:         true,
+		BlockedCountries: []string{"CHN", "rus"},
+	}
+
+	mock := NewMockGeoIP()
+	mock.SetCountry("1.2.3.4", "RU")
+
+	detector := &IVTDetector{
+		config:  config,
+		geoip:   mock,
+		metrics: &IVTMetrics{},
+	}
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Forwarded-For", "1.2.3.4")
+	result := &IVTResult{}
+
+	detector.checkGeoWithConfig(req, result, config)
+
+	if len(result.Signals) != 1 || result.Signals[0].Type != "geo_blocked" {
+		t.Fatalf("Expected geo_blocked signal for alpha-3 blocked list, got %+v", result.Signals)
+	}
+}
+
+func TestContainsCountry(t *testing.T) {
+	testCases := []struct {
+		name      string
+		countries []string
+		alpha2    string
+		expected  bool
+	}{
+		{"Alpha-2 match", []string{"US", "GB"}, "GB", true},
+		{"Alpha-3 match", []string{"USA", "DEU"}, "DE", true},
+		{"Lowercase match", []string{"de"}, "DE", true},
+		{"No match", []string{"USA", "GBR"}, "FR", false},
+		{"Empty list", []string{}, "US", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := containsCountry(tc.countries, tc.alpha2); got != tc.expected {
+				t.Errorf("containsCountry(%v, %q) = %v, expected %v", tc.countries, tc.alpha2, got, tc.expected)
+			}
+		})
+	}
+}
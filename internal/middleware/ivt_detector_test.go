@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"net/http/httptest"
+	"sync"
 	"testing"
 )
 
@@ -289,3 +290,44 @@ func TestIVTDetector_GetConfig(t *testing.T) {
 		t.Error("Expected config.BlockingEnabled to be true")
 	}
 }
+
+func TestIVTDetector_SetConfigRecompilesUAPatterns(t *testing.T) {
+	detector := NewIVTDetector(DefaultIVTConfig())
+
+	config := DefaultIVTConfig()
+	config.SuspiciousUAPatterns = []string{`(?i)scanner`}
+	detector.SetConfig(config)
+
+	req := httptest.NewRequest("POST", "/openrtb2/auction", nil)
+	req.Header.Set("User-Agent", "Acme Scanner/1.0")
+	if result := detector.Validate(context.Background(), req, "test-pub", ""); len(result.Signals) == 0 {
+		t.Error("Expected the reloaded pattern to flag the user agent")
+	}
+
+	req.Header.Set("User-Agent", "Googlebot/2.1")
+	if result := detector.Validate(context.Background(), req, "test-pub", ""); len(result.Signals) != 0 {
+		t.Errorf("Expected the replaced default patterns to be dropped, got %d signals", len(result.Signals))
+	}
+}
+
+// Run with -race: reloads must not race with in-flight checks
+func TestIVTDetector_SetConfigConcurrentWithValidate(t *testing.T) {
+	detector := NewIVTDetector(DefaultIVTConfig())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				req := httptest.NewRequest("POST", "/openrtb2/auction", nil)
+				req.Header.Set("User-Agent", "Googlebot/2.1")
+				detector.Validate(context.Background(), req, "test-pub", "")
+			}
+		}()
+	}
+	for j := 0; j < 50; j++ {
+		detector.SetConfig(DefaultIVTConfig())
+	}
+	wg.Wait()
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import "github.com/thenexusengine/tne_springwire/internal/ivtpolicy"
+
+// DefaultIVTBlockThreshold is the IVT score at which traffic is flagged and, when blocking, rejected
+const DefaultIVTBlockThreshold = 70
+
+// applyIVTPolicy overlays a publisher's policy on a snapshot of the global configuration
+func applyIVTPolicy(p *ivtpolicy.Policy, cfg *IVTConfig) {
+	if p == nil {
+		return
+	}
+	switch p.Mode {
+	case ivtpolicy.ModeMonitor:
+		cfg.MonitoringEnabled = true
+		cfg.BlockingEnabled = false
+	case ivtpolicy.ModeBlock:
+		cfg.MonitoringEnabled = true
+		cfg.BlockingEnabled = true
+	}
+	if p.BlockThreshold > 0 {
+		cfg.BlockThreshold = p.BlockThreshold
+	}
+	if len(p.AllowedCountries) > 0 {
+		cfg.CheckGeo = true
+		cfg.AllowedCountries = p.AllowedCountries
+	}
+	if p.RequireReferer != nil {
+		cfg.RequireReferer = *p.RequireReferer
+		if cfg.RequireReferer {
+			cfg.CheckReferer = true
+		}
+	}
+	if p.MaxRequestsPerIP != nil {
+		cfg.MaxRequestsPerIP = *p.MaxRequestsPerIP
+	}
+	if p.MaxRequestsPerIFA != nil {
+		cfg.MaxRequestsPerIFA = *p.MaxRequestsPerIFA
+	}
+	if p.MaxRequestsPerUser != nil {
+		cfg.MaxRequestsPerUser = *p.MaxRequestsPerUser
+	}
+	if p.MaxDomainsPerIP != nil {
+		cfg.MaxDomainsPerIP = *p.MaxDomainsPerIP
+	}
+}
+
+// publisherIVTPolicy returns the IVT policy of a publisher record, or nil if it has none
+func publisherIVTPolicy(pub interface{}) *ivtpolicy.Policy {
+	type ivtPolicyProvider interface {
+		GetIVTPolicy() *ivtpolicy.Policy
+	}
+	if pp, ok := pub.(ivtPolicyProvider); ok {
+		return pp.GetIVTPolicy()
+	}
+	return nil
+}
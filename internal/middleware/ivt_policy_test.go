@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/ivtpolicy"
+)
+
+// policyPublisher is a publisher record carrying an IVT policy
+type policyPublisher struct {
+	allowedDomains string
+	policy         *ivtpolicy.Policy
+}
+
+func (p *policyPublisher) GetAllowedDomains() string       { return p.allowedDomains }
+func (p *policyPublisher) GetIVTPolicy() *ivtpolicy.Policy { return p.policy }
+
+// policyPublisherStore serves policyPublisher records by ID
+type policyPublisherStore map[string]*policyPublisher
+
+func (s policyPublisherStore) GetByPublisherID(ctx context.Context, publisherID string) (interface{}, error) {
+	if pub, ok := s[publisherID]; ok {
+		return pub, nil
+	}
+	return nil, nil
+}
+
+func int64Ptr(v int64) *int64 { return &v }
+func boolPtr(v bool) *bool    { return &v }
+
+func TestIVTPolicy_Apply(t *testing.T) {
+	cfg := IVTConfig{
+		MonitoringEnabled: true,
+		BlockingEnabled:   true,
+		CheckReferer:      false,
+		AllowedCountries:  []string{"GB"},
+		MaxRequestsPerIP:  600,
+		MaxDomainsPerIP:   20,
+	}
+
+	policy := &ivtpolicy.Policy{
+		Mode:             ivtpolicy.ModeMonitor,
+		BlockThreshold:   40,
+		AllowedCountries: []string{"US"},
+		RequireReferer:   boolPtr(true),
+		MaxRequestsPerIP: int64Ptr(0),
+	}
+	applyIVTPolicy(policy, &cfg)
+
+	if !cfg.MonitoringEnabled || cfg.BlockingEnabled {
+		t.Error("expected monitor mode to disable blocking")
+	}
+	if cfg.BlockThreshold != 40 {
+		t.Errorf("expected threshold 40, got %d", cfg.BlockThreshold)
+	}
+	if !cfg.CheckGeo || len(cfg.AllowedCountries) != 1 || cfg.AllowedCountries[0] != "US" {
+		t.Errorf("expected geo check with US only, got check=%v countries=%v", cfg.CheckGeo, cfg.AllowedCountries)
+	}
+	if !cfg.CheckReferer || !cfg.RequireReferer {
+		t.Error("expected required referer to enable the referer check")
+	}
+	if cfg.MaxRequestsPerIP != 0 || cfg.MaxDomainsPerIP != 20 {
+		t.Errorf("expected IP velocity disabled and domain fan-out inherited, got %d and %d", cfg.MaxRequestsPerIP, cfg.MaxDomainsPerIP)
+	}
+
+	// A nil policy leaves the config untouched
+	before := cfg
+	applyIVTPolicy(nil, &cfg)
+	if cfg.BlockThreshold != before.BlockThreshold || cfg.BlockingEnabled != before.BlockingEnabled {
+		t.Error("expected nil policy to be a no-op")
+	}
+}
+
+func TestIVTDetector_PolicyOverridesGlobalConfig(t *testing.T) {
+	// Globally monitor-only with the default threshold
+	detector := NewIVTDetector(&IVTConfig{
+		MonitoringEnabled: true,
+		CheckReferer:      true,
+	})
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil)
+		req.Header.Set("User-Agent", "Mozilla/5.0 Chrome/120.0")
+		return req
+	}
+
+	// A missing referer is only a signal when required; medium scores 35
+	result := detector.ValidateRequest(context.Background(), newRequest(), &IVTRequest{PublisherID: "pub1", Domain: "example.com"})
+	if len(result.Signals) != 0 || result.ShouldBlock {
+		t.Fatalf("expected no signals under the global config, got %+v", result.Signals)
+	}
+
+	strict := &ivtpolicy.Policy{Mode: ivtpolicy.ModeBlock, BlockThreshold: 30, RequireReferer: boolPtr(true)}
+	result = detector.ValidateRequest(context.Background(), newRequest(), &IVTRequest{PublisherID: "pub2", Domain: "example.com", Policy: strict})
+	if !result.ShouldBlock || result.IsValid || result.Score != 35 {
+		t.Errorf("expected strict policy to block a score of 35, got score=%d block=%v", result.Score, result.ShouldBlock)
+	}
+
+	// The policy must not leak into the shared config
+	if cfg := detector.GetConfig(); cfg.BlockingEnabled || cfg.RequireReferer || cfg.BlockThreshold != 0 {
+		t.Errorf("expected global config unchanged, got %+v", cfg)
+	}
+}
+
+func TestIVTDetector_PolicyAllowedCountries(t *testing.T) {
+	geo := NewMockGeoIP()
+	geo.SetCountry("1.2.3.4", "FR")
+
+	detector := &IVTDetector{
+		config:  &IVTConfig{MonitoringEnabled: true, BlockingEnabled: true},
+		geoip:   geo,
+		metrics: &IVTMetrics{},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 Chrome/120.0")
+	req.Header.Set("X-Forwarded-For", "1.2.3.4")
+
+	result := detector.ValidateRequest(context.Background(), req, &IVTRequest{
+		PublisherID: "pub1",
+		Policy:      &ivtpolicy.Policy{AllowedCountries: []string{"USA", "CAN"}},
+	})
+	if len(result.Signals) != 1 || result.Signals[0].Type != "geo_restricted" {
+		t.Errorf("expected the policy's allowed countries to restrict FR, got %+v", result.Signals)
+	}
+}
+
+func TestPublisherAuth_AppliesPublisherIVTPolicy(t *testing.T) {
+	auth := NewPublisherAuth(&PublisherAuthConfig{Enabled: true, AllowUnregistered: true})
+	auth.ivtDetector = NewIVTDetector(&IVTConfig{MonitoringEnabled: true, CheckReferer: true})
+	auth.SetPublisherStore(policyPublisherStore{
+		"strict":  {policy: &ivtpolicy.Policy{Mode: ivtpolicy.ModeBlock, BlockThreshold: 30, RequireReferer: boolPtr(true)}},
+		"lenient": {},
+	})
+
+	called := 0
+	handler := auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called++
+		if PublisherFromContext(r.Context()) == nil {
+			t.Error("expected publisher in context")
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	send := func(publisherID string) int {
+		body := []byte(`{"id":"1","imp":[{"id":"imp1"}],"site":{"domain":"example.com","publisher":{"id":"` + publisherID + `"}}}`)
+		req := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", bytes.NewReader(body))
+		req.Header.Set("User-Agent", "Mozilla/5.0 Chrome/120.0")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if code := send("lenient"); code != http.StatusOK {
+		t.Errorf("expected publisher without a policy to pass, got %d", code)
+	}
+	if code := send("strict"); code != http.StatusForbidden {
+		t.Errorf("expected strict publisher without a referer to be blocked, got %d", code)
+	}
+	if called != 1 {
+		t.Errorf("expected handler called once, got %d", called)
+	}
+
+	stats, ok := auth.IVTStats().Summary("strict", IVTStatsRetention)
+	if !ok || stats.Blocked != 1 || stats.Signals["invalid_referer"] != 1 {
+		t.Errorf("expected the block in the strict publisher's stats, got %+v", stats)
+	}
+}
@@ -0,0 +1,199 @@
+package middleware
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Per-publisher IVT aggregation
+const (
+	IVTStatsBucketWidth = 5 * time.Minute // Granularity of per-publisher counts
+	IVTStatsRetention   = 24 * time.Hour  // How far back per-publisher counts are kept
+
+	// maxIVTStatsPublishers bounds memory when unregistered publisher IDs are allowed
+	maxIVTStatsPublishers = 10000
+)
+
+// Publisher keys for results that can't be attributed to a single publisher
+const (
+	IVTStatsUnknownPublisher = "_unknown" // Requests without a publisher ID
+	IVTStatsOtherPublishers  = "_other"   // Publishers beyond the tracking limit
+)
+
+// IVTStatsBucket holds IVT counts for one publisher over one bucket
+type IVTStatsBucket struct {
+	Start   time.Time        `json:"start"`
+	Checked int64            `json:"checked"`
+	Flagged int64            `json:"flagged"`
+	Blocked int64            `json:"blocked"`
+	Signals map[string]int64 `json:"signals"` // Signal type -> requests carrying it
+}
+
+// IVTPublisherSummary is a publisher's IVT breakdown over a time window
+type IVTPublisherSummary struct {
+	PublisherID string           `json:"publisher_id"`
+	Window      string           `json:"window"`
+	Checked     int64            `json:"checked"`
+	Flagged     int64            `json:"flagged"`
+	Blocked     int64            `json:"blocked"`
+	FlaggedRate float64          `json:"flagged_rate"` // Share of checked requests flagged (0-1)
+	BlockedRate float64          `json:"blocked_rate"` // Share of checked requests blocked (0-1)
+	Signals     map[string]int64 `json:"signals"`
+	Series      []IVTStatsBucket `json:"series,omitempty"` // Per-bucket counts, oldest first
+}
+
+// IVTStats aggregates IVT results per publisher in fixed time buckets
+// Counts are kept in memory per instance for IVTStatsRetention.
+type IVTStats struct {
+	mu         sync.Mutex
+	publishers map[string]map[int64]*IVTStatsBucket // publisher -> bucket start (unix) -> counts
+	now        func() time.Time
+}
+
+// NewIVTStats creates an empty per-publisher IVT aggregator
+func NewIVTStats() *IVTStats {
+	return &IVTStats{
+		publishers: make(map[string]map[int64]*IVTStatsBucket),
+		now:        time.Now,
+	}
+}
+
+// Record adds a detection result to its publisher's current bucket
+func (s *IVTStats) Record(result *IVTResult) {
+	if s == nil || result == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	start := now.Truncate(IVTStatsBucketWidth).Unix()
+
+	buckets := s.bucketsFor(result.PublisherID)
+	bucket := buckets[start]
+	if bucket == nil {
+		// Drop buckets that aged out before adding a new one
+		cutoff := now.Add(-IVTStatsRetention).Unix()
+		for ts := range buckets {
+			if ts <= cutoff {
+				delete(buckets, ts)
+			}
+		}
+		bucket = &IVTStatsBucket{Start: time.Unix(start, 0).UTC(), Signals: make(map[string]int64)}
+		buckets[start] = bucket
+	}
+
+	bucket.Checked++
+	if !result.IsValid {
+		bucket.Flagged++
+	}
+	if result.ShouldBlock {
+		bucket.Blocked++
+	}
+	seen := make(map[string]bool, len(result.Signals))
+	for _, signal := range result.Signals {
+		if !seen[signal.Type] {
+			seen[signal.Type] = true
+			bucket.Signals[signal.Type]++
+		}
+	}
+}
+
+// bucketsFor returns the bucket map for a publisher, creating it if needed (caller holds mu)
+func (s *IVTStats) bucketsFor(publisherID string) map[int64]*IVTStatsBucket {
+	if publisherID == "" {
+		publisherID = IVTStatsUnknownPublisher
+	}
+	if buckets, ok := s.publishers[publisherID]; ok {
+		return buckets
+	}
+	if len(s.publishers) >= maxIVTStatsPublishers {
+		publisherID = IVTStatsOtherPublishers
+		if buckets, ok := s.publishers[publisherID]; ok {
+			return buckets
+		}
+	}
+	buckets := make(map[int64]*IVTStatsBucket)
+	s.publishers[publisherID] = buckets
+	return buckets
+}
+
+// Summary returns one publisher's breakdown over the window, including per-bucket counts
+// Returns false if nothing was recorded for the publisher in the window.
+func (s *IVTStats) Summary(publisherID string, window time.Duration) (IVTPublisherSummary, bool) {
+	if s == nil {
+		return IVTPublisherSummary{}, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buckets, ok := s.publishers[publisherID]
+	if !ok {
+		return IVTPublisherSummary{}, false
+	}
+	summary := s.summarize(publisherID, buckets, window, true)
+	return summary, summary.Checked > 0
+}
+
+// Summaries returns every publisher with traffic in the window, sorted by publisher ID
+func (s *IVTStats) Summaries(window time.Duration) []IVTPublisherSummary {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summaries := make([]IVTPublisherSummary, 0, len(s.publishers))
+	for publisherID, buckets := range s.publishers {
+		if summary := s.summarize(publisherID, buckets, window, false); summary.Checked > 0 {
+			summaries = append(summaries, summary)
+		}
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].PublisherID < summaries[j].PublisherID
+	})
+	return summaries
+}
+
+// summarize totals the buckets that started within the window (caller holds mu)
+func (s *IVTStats) summarize(publisherID string, buckets map[int64]*IVTStatsBucket, window time.Duration, series bool) IVTPublisherSummary {
+	summary := IVTPublisherSummary{
+		PublisherID: publisherID,
+		Window:      window.String(),
+		Signals:     make(map[string]int64),
+	}
+	since := s.now().Add(-window).Truncate(IVTStatsBucketWidth).Unix()
+
+	for ts, bucket := range buckets {
+		if ts < since {
+			continue
+		}
+		summary.Checked += bucket.Checked
+		summary.Flagged += bucket.Flagged
+		summary.Blocked += bucket.Blocked
+		for signalType, n := range bucket.Signals {
+			summary.Signals[signalType] += n
+		}
+		if series {
+			copied := *bucket
+			copied.Signals = make(map[string]int64, len(bucket.Signals))
+			for signalType, n := range bucket.Signals {
+				copied.Signals[signalType] = n
+			}
+			summary.Series = append(summary.Series, copied)
+		}
+	}
+
+	sort.Slice(summary.Series, func(i, j int) bool {
+		return summary.Series[i].Start.Before(summary.Series[j].Start)
+	})
+	if summary.Checked > 0 {
+		summary.FlaggedRate = float64(summary.Flagged) / float64(summary.Checked)
+		summary.BlockedRate = float64(summary.Blocked) / float64(summary.Checked)
+	}
+	return summary
+}
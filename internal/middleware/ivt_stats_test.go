@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newTestIVTStats returns stats driven by a settable clock (test helper)
+func newTestIVTStats(now *time.Time) *IVTStats {
+	s := NewIVTStats()
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func TestIVTStats_RecordAndSummary(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	stats := newTestIVTStats(&now)
+
+	stats.Record(&IVTResult{PublisherID: "pub1", IsValid: true})
+	stats.Record(&IVTResult{
+		PublisherID: "pub1",
+		ShouldBlock: true,
+		Signals: []IVTSignal{
+			{Type: "suspicious_ua"},
+			{Type: "invalid_referer"},
+			{Type: "suspicious_ua"}, // Counted once per request
+		},
+	})
+	now = now.Add(10 * time.Minute)
+	stats.Record(&IVTResult{PublisherID: "pub1", Signals: []IVTSignal{{Type: "suspicious_ua"}}})
+
+	summary, ok := stats.Summary("pub1", time.Hour)
+	if !ok {
+		t.Fatal("expected summary for pub1")
+	}
+	if summary.Checked != 3 || summary.Flagged != 2 || summary.Blocked != 1 {
+		t.Errorf("expected 3 checked, 2 flagged, 1 blocked, got %d/%d/%d", summary.Checked, summary.Flagged, summary.Blocked)
+	}
+	if summary.Signals["suspicious_ua"] != 2 || summary.Signals["invalid_referer"] != 1 {
+		t.Errorf("unexpected signal breakdown %v", summary.Signals)
+	}
+	if summary.BlockedRate != 1.0/3 {
+		t.Errorf("expected blocked rate 1/3, got %f", summary.BlockedRate)
+	}
+	if len(summary.Series) != 2 || !summary.Series[0].Start.Before(summary.Series[1].Start) {
+		t.Errorf("expected 2 buckets oldest first, got %+v", summary.Series)
+	}
+
+	// A window covering only the latest bucket
+	summary, _ = stats.Summary("pub1", 5*time.Minute)
+	if summary.Checked != 1 {
+		t.Errorf("expected 1 request in the last 5m, got %d", summary.Checked)
+	}
+
+	if _, ok := stats.Summary("pub2", time.Hour); ok {
+		t.Error("expected no summary for an unseen publisher")
+	}
+}
+
+func TestIVTStats_Retention(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	stats := newTestIVTStats(&now)
+
+	stats.Record(&IVTResult{PublisherID: "pub1"})
+	now = now.Add(IVTStatsRetention + IVTStatsBucketWidth)
+	stats.Record(&IVTResult{PublisherID: "pub1"})
+
+	if buckets := len(stats.publishers["pub1"]); buckets != 1 {
+		t.Errorf("expected the expired bucket to be dropped, got %d buckets", buckets)
+	}
+	summary, _ := stats.Summary("pub1", IVTStatsRetention)
+	if summary.Checked != 1 {
+		t.Errorf("expected only the recent request, got %d", summary.Checked)
+	}
+}
+
+func TestIVTStats_Summaries(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	stats := newTestIVTStats(&now)
+
+	stats.Record(&IVTResult{PublisherID: "pub-b"})
+	stats.Record(&IVTResult{PublisherID: "pub-a"})
+	stats.Record(&IVTResult{})
+	now = now.Add(2 * time.Hour)
+	stats.Record(&IVTResult{PublisherID: "pub-c"})
+
+	summaries := stats.Summaries(time.Hour)
+	if len(summaries) != 1 || summaries[0].PublisherID != "pub-c" {
+		t.Fatalf("expected only pub-c in the last hour, got %+v", summaries)
+	}
+	if summaries[0].Series != nil {
+		t.Error("expected no per-bucket series in the publisher list")
+	}
+
+	summaries = stats.Summaries(IVTStatsRetention)
+	want := []string{IVTStatsUnknownPublisher, "pub-a", "pub-b", "pub-c"}
+	if len(summaries) != len(want) {
+		t.Fatalf("expected %v, got %+v", want, summaries)
+	}
+	for i, id := range want {
+		if summaries[i].PublisherID != id {
+			t.Errorf("expected %s at %d, got %s", id, i, summaries[i].PublisherID)
+		}
+	}
+}
+
+func TestIVTStats_PublisherLimit(t *testing.T) {
+	now := time.Now()
+	stats := newTestIVTStats(&now)
+
+	for i := 0; i < maxIVTStatsPublishers; i++ {
+		stats.Record(&IVTResult{PublisherID: "pub" + strconv.Itoa(i)})
+	}
+	stats.Record(&IVTResult{PublisherID: "one-too-many"})
+	stats.Record(&IVTResult{PublisherID: "pub0"})
+
+	if _, ok := stats.Summary("one-too-many", time.Hour); ok {
+		t.Error("expected publishers past the limit not to be tracked individually")
+	}
+	if other, ok := stats.Summary(IVTStatsOtherPublishers, time.Hour); !ok || other.Checked != 1 {
+		t.Errorf("expected overflow counted under %s, got %+v", IVTStatsOtherPublishers, other)
+	}
+	if pub0, _ := stats.Summary("pub0", time.Hour); pub0.Checked != 2 {
+		t.Errorf("expected existing publishers still tracked, got %d", pub0.Checked)
+	}
+}
+
+func TestIVTStats_NilSafe(t *testing.T) {
+	var stats *IVTStats
+	stats.Record(&IVTResult{PublisherID: "pub1"})
+	if _, ok := stats.Summary("pub1", time.Hour); ok {
+		t.Error("expected no summary from nil stats")
+	}
+	if stats.Summaries(time.Hour) != nil {
+		t.Error("expected no summaries from nil stats")
+	}
+}
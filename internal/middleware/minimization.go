@@ -0,0 +1,209 @@
+package middleware
+
+import (
+	"math"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// Reasons a bidder request field was minimized, strictest first
+const (
+	MinimizeReasonCOPPA      = "coppa"       // regs.coppa=1
+	MinimizeReasonUSOptOut   = "us_optout"   // US Privacy or GPP sale/sharing/targeted-ads opt-out
+	MinimizeReasonGDPRVendor = "gdpr_vendor" // GDPR applies and the bidder has no vendor consent
+	MinimizeReasonGDPRGeo    = "gdpr_geo"    // GDPR applies without TCF special feature 1 (precise geolocation)
+)
+
+// Actions applied to a minimized field
+const (
+	MinimizeActionTruncated = "truncated"
+	MinimizeActionRounded   = "rounded"
+	MinimizeActionRemoved   = "removed"
+)
+
+// geoPrecisionDecimals is how many decimals lat/lon keep when coarsened (~1.1km)
+const geoPrecisionDecimals = 2
+
+// MinimizedField records a field removed or coarsened in one bidder's request
+type MinimizedField struct {
+	Field  string
+	Action string
+	Reason string
+}
+
+// RequestPrivacy is the privacy state of an auction request, evaluated once and applied per bidder
+type RequestPrivacy struct {
+	coppa   bool
+	consent *SyncConsent
+}
+
+// EvaluateRequestPrivacy decodes the COPPA, TCF and US privacy signals of an auction request
+func EvaluateRequestPrivacy(req *openrtb.BidRequest) *RequestPrivacy {
+	return &RequestPrivacy{
+		coppa:   req != nil && req.Regs != nil && req.Regs.COPPA == 1,
+		consent: EvaluateSyncConsent(SyncPrivacyFromRequest(req)),
+	}
+}
+
+// preciseGeoReason returns why precise location can't be sent, or "" if it can
+func (p *RequestPrivacy) preciseGeoReason() string {
+	switch {
+	case p.coppa:
+		return MinimizeReasonCOPPA
+	case p.consent.USOptOut():
+		return MinimizeReasonUSOptOut
+	case p.consent.GDPRApplies() && !p.hasSpecialFeature1():
+		return MinimizeReasonGDPRGeo
+	}
+	return ""
+}
+
+// identifiersReason returns why user and device identifiers can't be sent to a bidder, or "" if they can
+func (p *RequestPrivacy) identifiersReason(gvlID int) string {
+	switch {
+	case p.coppa:
+		return MinimizeReasonCOPPA
+	case p.consent.USOptOut():
+		return MinimizeReasonUSOptOut
+	case p.consent.GDPRApplies() && !p.hasVendorConsent(gvlID):
+		return MinimizeReasonGDPRVendor
+	}
+	return ""
+}
+
+func (p *RequestPrivacy) hasSpecialFeature1() bool {
+	tcf := p.consent.tcf
+	return p.consent.tcfErr == nil && tcf != nil && len(tcf.SpecialFeatureOptIns) > 0 && tcf.SpecialFeatureOptIns[0]
+}
+
+// hasVendorConsent requires a valid TCF string, plus vendor consent when the bidder's GVL ID is known
+func (p *RequestPrivacy) hasVendorConsent(gvlID int) bool {
+	if p.consent.tcfErr != nil || p.consent.tcf == nil {
+		return false
+	}
+	return gvlID <= 0 || p.consent.tcf.VendorConsents[gvlID]
+}
+
+// MinimizeForBidder removes or coarsens personal data in a bidder's cloned request
+// Device, user and geo objects are copied before they change, so the clone may share
+// them with other bidders' requests. Returns the fields that were minimized.
+func (p *RequestPrivacy) MinimizeForBidder(req *openrtb.BidRequest, gvlID int) []MinimizedField {
+	if p == nil {
+		return nil
+	}
+	m := &minimizer{}
+
+	if reason := p.preciseGeoReason(); reason != "" {
+		if req.Device != nil {
+			device := *req.Device
+			m.truncateIP(&device.IP, "device.ip", reason)
+			m.truncateIP(&device.IPv6, "device.ipv6", reason)
+			device.Geo = m.coarsenGeo(device.Geo, "device.geo", reason)
+			req.Device = &device
+		}
+		if req.User != nil && req.User.Geo != nil {
+			user := *req.User
+			user.Geo = m.coarsenGeo(user.Geo, "user.geo", reason)
+			req.User = &user
+		}
+	}
+
+	if reason := p.identifiersReason(gvlID); reason != "" {
+		if req.Device != nil {
+			device := *req.Device
+			m.removeString(&device.IFA, "device.ifa", reason)
+			m.removeString(&device.IDSHA1, "device.didsha1", reason)
+			m.removeString(&device.IDMD5, "device.didmd5", reason)
+			m.removeString(&device.DPIDSHA1, "device.dpidsha1", reason)
+			m.removeString(&device.DPIDMD5, "device.dpidmd5", reason)
+			m.removeString(&device.MacSHA1, "device.macsha1", reason)
+			m.removeString(&device.MacMD5, "device.macmd5", reason)
+			req.Device = &device
+		}
+		if req.User != nil {
+			user := *req.User
+			m.removeString(&user.ID, "user.id", reason)
+			m.removeString(&user.BuyerUID, "user.buyeruid", reason)
+			if len(user.EIDs) > 0 {
+				user.EIDs = nil
+				m.record("user.eids", MinimizeActionRemoved, reason)
+			}
+			// OpenRTB 2.5 shape, which callers may add after ingest normalization
+			if ext, ok := openrtb.RemoveExtField(user.Ext, "eids"); ok {
+				user.Ext = ext
+				m.record("user.ext.eids", MinimizeActionRemoved, reason)
+			}
+			if reason == MinimizeReasonCOPPA {
+				if user.YOB != 0 {
+					user.YOB = 0
+					m.record("user.yob", MinimizeActionRemoved, reason)
+				}
+				m.removeString(&user.Gender, "user.gender", reason)
+			}
+			req.User = &user
+		}
+	}
+
+	return m.fields
+}
+
+// minimizer collects the fields changed in one bidder request
+type minimizer struct {
+	fields []MinimizedField
+}
+
+func (m *minimizer) record(field, action, reason string) {
+	m.fields = append(m.fields, MinimizedField{Field: field, Action: action, Reason: reason})
+}
+
+func (m *minimizer) removeString(value *string, field, reason string) {
+	if *value != "" {
+		*value = ""
+		m.record(field, MinimizeActionRemoved, reason)
+	}
+}
+
+func (m *minimizer) truncateIP(ip *string, field, reason string) {
+	if *ip == "" {
+		return
+	}
+	if truncated := AnonymizeIP(*ip); truncated != *ip {
+		*ip = truncated
+		m.record(field, MinimizeActionTruncated, reason)
+	}
+}
+
+// coarsenGeo returns a copy of geo with ZIP and metro removed and coordinates rounded
+// (removed entirely under COPPA)
+func (m *minimizer) coarsenGeo(geo *openrtb.Geo, prefix, reason string) *openrtb.Geo {
+	if geo == nil {
+		return nil
+	}
+	geoCopy := *geo
+	m.removeString(&geoCopy.ZIP, prefix+".zip", reason)
+	m.removeString(&geoCopy.Metro, prefix+".metro", reason)
+
+	if geoCopy.Lat == 0 && geoCopy.Lon == 0 {
+		return &geoCopy
+	}
+	if reason == MinimizeReasonCOPPA {
+		geoCopy.Lat, geoCopy.Lon = 0, 0
+		m.record(prefix+".lat", MinimizeActionRemoved, reason)
+		m.record(prefix+".lon", MinimizeActionRemoved, reason)
+		return &geoCopy
+	}
+	if lat := roundCoordinate(geoCopy.Lat); lat != geoCopy.Lat {
+		geoCopy.Lat = lat
+		m.record(prefix+".lat", MinimizeActionRounded, reason)
+	}
+	if lon := roundCoordinate(geoCopy.Lon); lon != geoCopy.Lon {
+		geoCopy.Lon = lon
+		m.record(prefix+".lon", MinimizeActionRounded, reason)
+	}
+	return &geoCopy
+}
+
+func roundCoordinate(v float64) float64 {
+	scale := math.Pow(10, geoPrecisionDecimals)
+	return math.Round(v*scale) / scale
+}
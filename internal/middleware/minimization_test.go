@@ -0,0 +1,237 @@
+package middleware
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// minimizationRequest builds a request carrying every field data minimization can touch
+func minimizationRequest(regs *openrtb.Regs, consent string) *openrtb.BidRequest {
+	return &openrtb.BidRequest{
+		ID: "req-1",
+		Device: &openrtb.Device{
+			IP:   "203.0.113.77",
+			IPv6: "2001:db8:85a3:1234:5678:8a2e:370:7334",
+			IFA:  "ifa-1",
+			Geo:  &openrtb.Geo{Lat: 52.520008, Lon: 13.404954, ZIP: "10115", Metro: "123", City: "Berlin", Country: "DEU"},
+		},
+		User: &openrtb.User{
+			ID:       "user-1",
+			BuyerUID: "buyer-1",
+			YOB:      1990,
+			Gender:   "F",
+			Consent:  consent,
+			Geo:      &openrtb.Geo{Lat: 52.5, Lon: 13.4, ZIP: "10115"},
+			EIDs:     []openrtb.EID{{Source: "liveramp.com", UIDs: []openrtb.UID{{ID: "lr-1"}}}},
+		},
+		Regs: regs,
+	}
+}
+
+// minimizedFieldNames returns the minimized fields as "field:action:reason"
+func minimizedFieldNames(fields []MinimizedField) map[string]bool {
+	names := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		names[f.Field+":"+f.Action+":"+f.Reason] = true
+	}
+	return names
+}
+
+func TestMinimizeForBidder_NoRegulation(t *testing.T) {
+	req := minimizationRequest(nil, "")
+	orig := *req
+
+	fields := EvaluateRequestPrivacy(req).MinimizeForBidder(req, 32)
+	if len(fields) != 0 {
+		t.Errorf("expected no minimization, got %+v", fields)
+	}
+	if req.Device != orig.Device || req.User != orig.User {
+		t.Error("expected device and user to be left untouched")
+	}
+}
+
+func TestMinimizeForBidder_COPPA(t *testing.T) {
+	req := minimizationRequest(&openrtb.Regs{COPPA: 1}, "")
+	origDevice, origUser := req.Device, req.User
+
+	fields := EvaluateRequestPrivacy(req).MinimizeForBidder(req, 0)
+	names := minimizedFieldNames(fields)
+
+	for _, want := range []string{
+		"device.ip:truncated:coppa", "device.ipv6:truncated:coppa",
+		"device.geo.lat:removed:coppa", "device.geo.zip:removed:coppa", "device.geo.metro:removed:coppa",
+		"device.ifa:removed:coppa", "user.id:removed:coppa", "user.buyeruid:removed:coppa",
+		"user.eids:removed:coppa", "user.yob:removed:coppa", "user.gender:removed:coppa",
+		"user.geo.zip:removed:coppa",
+	} {
+		if !names[want] {
+			t.Errorf("expected %s in %v", want, names)
+		}
+	}
+
+	if req.Device.IP != "203.0.113.0" || req.Device.IPv6 != "2001:db8:85a3::" {
+		t.Errorf("expected truncated IPs, got %s %s", req.Device.IP, req.Device.IPv6)
+	}
+	if req.Device.Geo.Lat != 0 || req.Device.Geo.Lon != 0 || req.Device.Geo.City != "Berlin" {
+		t.Errorf("expected coordinates removed and city kept, got %+v", req.Device.Geo)
+	}
+	if req.User.YOB != 0 || req.User.EIDs != nil || req.User.BuyerUID != "" {
+		t.Errorf("expected user identifiers removed, got %+v", req.User)
+	}
+
+	// The originals are shared with other bidders and must not change
+	if origDevice.IP != "203.0.113.77" || origDevice.IFA != "ifa-1" || origDevice.Geo.ZIP != "10115" {
+		t.Error("original device was modified")
+	}
+	if origUser.ID != "user-1" || len(origUser.EIDs) != 1 || origUser.Geo.ZIP != "10115" {
+		t.Error("original user was modified")
+	}
+}
+
+func TestMinimizeForBidder_USOptOut(t *testing.T) {
+	req := minimizationRequest(&openrtb.Regs{USPrivacy: "1YYN"}, "")
+
+	fields := EvaluateRequestPrivacy(req).MinimizeForBidder(req, 32)
+	names := minimizedFieldNames(fields)
+
+	for _, want := range []string{
+		"device.ip:truncated:us_optout", "device.geo.lat:rounded:us_optout", "device.geo.lon:rounded:us_optout",
+		"device.geo.zip:removed:us_optout", "device.ifa:removed:us_optout", "user.eids:removed:us_optout",
+	} {
+		if !names[want] {
+			t.Errorf("expected %s in %v", want, names)
+		}
+	}
+	if req.Device.Geo.Lat != 52.52 || req.Device.Geo.Lon != 13.4 {
+		t.Errorf("expected coordinates rounded to 2 decimals, got %v,%v", req.Device.Geo.Lat, req.Device.Geo.Lon)
+	}
+	if names["user.yob:removed:us_optout"] || req.User.YOB != 1990 {
+		t.Error("expected yob to be kept outside COPPA")
+	}
+	// Already coarse coordinates are not reported
+	if names["user.geo.lat:rounded:us_optout"] {
+		t.Error("expected user.geo.lat (already 1 decimal) not to be reported")
+	}
+}
+
+func TestMinimizeForBidder_GPPUSOptOut(t *testing.T) {
+	gpp := buildTestGPPString([]int{GPPSectionUSNat}, []string{buildTestUSSection(18)})
+	req := minimizationRequest(&openrtb.Regs{GPP: gpp, GPPSID: []int{GPPSectionUSNat}}, "")
+
+	fields := EvaluateRequestPrivacy(req).MinimizeForBidder(req, 0)
+	if !minimizedFieldNames(fields)["user.buyeruid:removed:us_optout"] {
+		t.Errorf("expected GPP opt-out to remove buyeruid, got %+v", fields)
+	}
+}
+
+func TestMinimizeForBidder_GDPR(t *testing.T) {
+	gdpr := 1
+
+	t.Run("full consent", func(t *testing.T) {
+		consent := buildTestTCFStringWithFeatures([]int{1, 2, 3, 4}, []int{32}, []int{1})
+		req := minimizationRequest(&openrtb.Regs{GDPR: &gdpr}, consent)
+		if fields := EvaluateRequestPrivacy(req).MinimizeForBidder(req, 32); len(fields) != 0 {
+			t.Errorf("expected no minimization, got %+v", fields)
+		}
+	})
+
+	t.Run("no special feature 1", func(t *testing.T) {
+		consent := buildTestTCFString([]int{1, 2, 3, 4}, []int{32})
+		req := minimizationRequest(&openrtb.Regs{GDPR: &gdpr}, consent)
+		names := minimizedFieldNames(EvaluateRequestPrivacy(req).MinimizeForBidder(req, 32))
+		if !names["device.ip:truncated:gdpr_geo"] || !names["device.geo.lat:rounded:gdpr_geo"] || !names["device.geo.zip:removed:gdpr_geo"] {
+			t.Errorf("expected precise geo minimization, got %v", names)
+		}
+		if names["user.eids:removed:gdpr_vendor"] {
+			t.Error("expected identifiers to be kept for a consented vendor")
+		}
+	})
+
+	t.Run("no vendor consent", func(t *testing.T) {
+		consent := buildTestTCFStringWithFeatures([]int{1, 2, 3, 4}, []int{32}, []int{1})
+		req := minimizationRequest(&openrtb.Regs{GDPR: &gdpr}, consent)
+		privacy := EvaluateRequestPrivacy(req)
+
+		names := minimizedFieldNames(privacy.MinimizeForBidder(req, 52))
+		if !names["user.buyeruid:removed:gdpr_vendor"] || !names["user.eids:removed:gdpr_vendor"] || !names["device.ifa:removed:gdpr_vendor"] {
+			t.Errorf("expected identifiers removed, got %v", names)
+		}
+		if names["device.ip:truncated:gdpr_geo"] {
+			t.Error("expected precise geo to be kept with special feature 1")
+		}
+	})
+
+	t.Run("no consent string", func(t *testing.T) {
+		req := minimizationRequest(&openrtb.Regs{GDPR: &gdpr}, "")
+		names := minimizedFieldNames(EvaluateRequestPrivacy(req).MinimizeForBidder(req, 0))
+		if !names["device.ip:truncated:gdpr_geo"] || !names["user.id:removed:gdpr_vendor"] {
+			t.Errorf("expected geo and identifier minimization, got %v", names)
+		}
+	})
+}
+
+func TestMinimizeForBidder_ExtEIDs(t *testing.T) {
+	gdpr := 1
+	tests := []struct {
+		name   string
+		regs   *openrtb.Regs
+		reason string
+	}{
+		{"coppa", &openrtb.Regs{COPPA: 1}, MinimizeReasonCOPPA},
+		{"us opt-out", &openrtb.Regs{USPrivacy: "1YYN"}, MinimizeReasonUSOptOut},
+		{"gdpr without consent", &openrtb.Regs{GDPR: &gdpr}, MinimizeReasonGDPRVendor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Both shapes at once: 2.6 user.eids and 2.5 user.ext.eids
+			req := minimizationRequest(tt.regs, "")
+			origExt := json.RawMessage(`{"eids":[{"source":"liveramp.com","uids":[{"id":"lr-1"}]}],"data":{"segment":"a"}}`)
+			req.User.Ext = origExt
+			origUser := req.User
+
+			names := minimizedFieldNames(EvaluateRequestPrivacy(req).MinimizeForBidder(req, 32))
+			if !names["user.eids:removed:"+tt.reason] || !names["user.ext.eids:removed:"+tt.reason] {
+				t.Errorf("expected both EID shapes removed, got %v", names)
+			}
+			if strings.Contains(string(req.User.Ext), "eids") || !strings.Contains(string(req.User.Ext), `"segment":"a"`) {
+				t.Errorf("expected only ext.eids removed, got %s", req.User.Ext)
+			}
+			if string(origUser.Ext) != string(origExt) {
+				t.Error("original user.ext was modified")
+			}
+		})
+	}
+
+	// A request with consent keeps user.ext.eids
+	consent := buildTestTCFStringWithFeatures([]int{1, 2, 3, 4}, []int{32}, []int{1})
+	req := minimizationRequest(&openrtb.Regs{GDPR: &gdpr}, consent)
+	req.User.Ext = json.RawMessage(`{"eids":[{"source":"liveramp.com","uids":[{"id":"lr-1"}]}]}`)
+	if fields := EvaluateRequestPrivacy(req).MinimizeForBidder(req, 32); len(fields) != 0 || !strings.Contains(string(req.User.Ext), "eids") {
+		t.Errorf("expected ext.eids kept with consent, got %+v %s", fields, req.User.Ext)
+	}
+}
+
+func TestMinimizeForBidder_NilPrivacy(t *testing.T) {
+	var p *RequestPrivacy
+	if fields := p.MinimizeForBidder(&openrtb.BidRequest{}, 1); fields != nil {
+		t.Errorf("expected nil, got %+v", fields)
+	}
+}
+
+func TestParseTCFv2String_SpecialFeatures(t *testing.T) {
+	m := &PrivacyMiddleware{}
+	data, err := m.parseTCFv2String(buildTestTCFStringWithFeatures([]int{1}, []int{32}, []int{1, 2}))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if !reflect.DeepEqual(data.SpecialFeatureOptIns[:3], []bool{true, true, false}) {
+		t.Errorf("expected special features 1 and 2, got %v", data.SpecialFeatureOptIns)
+	}
+	if !data.PurposeConsents[0] || !data.VendorConsents[32] {
+		t.Error("expected purpose 1 and vendor 32 consent to still parse")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/thenexusengine/tne_springwire/internal/geoip"
 	"github.com/thenexusengine/tne_springwire/internal/openrtb"
 	"github.com/thenexusengine/tne_springwire/pkg/logger"
 )
@@ -250,35 +251,7 @@ func (m *PrivacyMiddleware) detectApplicableRegulation(req *openrtb.BidRequest)
 		geo = req.User.Geo
 	}
 
-	if geo == nil {
-		return RegulationNone
-	}
-
-	// Check GDPR countries (EU/EEA + UK)
-	if geo.Country != "" && gdprCountries[geo.Country] {
-		return RegulationGDPR
-	}
-
-	// Check US state privacy laws
-	if geo.Country == "USA" && geo.Region != "" {
-		if regulation, exists := usPrivacyStates[geo.Region]; exists {
-			return regulation
-		}
-		// Other US states without specific laws
-		return RegulationNone
-	}
-
-	// Check other countries with privacy laws
-	switch geo.Country {
-	case "BRA": // Brazil
-		return RegulationLGPD
-	case "CAN": // Canada
-		return RegulationPIPEDA
-	case "SGP": // Singapore
-		return RegulationPDPA
-	}
-
-	return RegulationNone
+	return DetectRegulationFromGeo(geo)
 }
 
 // validateGeoConsent checks if the request has appropriate consent for the detected geo
@@ -441,16 +414,17 @@ func (m *PrivacyMiddleware) validateGDPRConsent(req *openrtb.BidRequest) *Privac
 
 // TCFv2Data holds parsed TCF v2 consent data
 type TCFv2Data struct {
-	Version           int
-	Created           int64
-	LastUpdated       int64
-	CmpID             int
-	CmpVersion        int
-	ConsentScreen     int
-	ConsentLanguage   string
-	VendorListVersion int
-	PurposeConsents   []bool // Indexed by purpose ID (1-based in spec, 0-based here)
-	VendorConsents    map[int]bool
+	Version              int
+	Created              int64
+	LastUpdated          int64
+	CmpID                int
+	CmpVersion           int
+	ConsentScreen        int
+	ConsentLanguage      string
+	VendorListVersion    int
+	SpecialFeatureOptIns []bool // Indexed by special feature ID (1-based in spec, 0-based here)
+	PurposeConsents      []bool // Indexed by purpose ID (1-based in spec, 0-based here)
+	VendorConsents       map[int]bool
 }
 
 // parseTCFv2String parses a TCF v2 consent string and extracts purpose consents
@@ -479,8 +453,9 @@ func (m *PrivacyMiddleware) parseTCFv2String(consent string) (*TCFv2Data, error)
 	}
 
 	data := &TCFv2Data{
-		PurposeConsents: make([]bool, 24), // 24 purposes in TCF v2
-		VendorConsents:  make(map[int]bool),
+		SpecialFeatureOptIns: make([]bool, 12), // 12 special feature bits in TCF v2
+		PurposeConsents:      make([]bool, 24), // 24 purposes in TCF v2
+		VendorConsents:       make(map[int]bool),
 	}
 
 	// Parse using bit reader
@@ -521,8 +496,10 @@ func (m *PrivacyMiddleware) parseTCFv2String(consent string) (*TCFv2Data, error)
 	reader.readInt(1)
 	// UseNonStandardStacks (1 bit) - skip
 	reader.readInt(1)
-	// SpecialFeatureOptIns (12 bits) - skip
-	reader.readInt(12)
+	// SpecialFeatureOptIns (12 bits) - feature 1 is precise geolocation
+	for i := 0; i < 12; i++ {
+		data.SpecialFeatureOptIns[i] = reader.readBool()
+	}
 
 	// Purpose consents (24 bits - one for each purpose)
 	for i := 0; i < 24; i++ {
@@ -690,6 +667,13 @@ func CheckVendorConsentStatic(consentString string, gvlID int) bool {
 	return exists && hasConsent
 }
 
+// DecodeTCFv2 parses a TCF v2 consent string for diagnostics outside the request path
+// Returns nil data and no error for an empty string
+func DecodeTCFv2(consentString string) (*TCFv2Data, error) {
+	m := &PrivacyMiddleware{}
+	return m.parseTCFv2String(consentString)
+}
+
 // DetectRegulationFromGeo determines which privacy regulation applies based on geo
 // This is a standalone function for use in the exchange during auction
 // Pass either device.geo or user.geo
@@ -698,21 +682,24 @@ func DetectRegulationFromGeo(geo *openrtb.Geo) PrivacyRegulation {
 		return RegulationNone
 	}
 
+	// Geo country may arrive as alpha-2 (e.g. "DE") even though OpenRTB specifies alpha-3
+	country := geoip.ToAlpha3(geo.Country)
+
 	// Check GDPR countries (EU/EEA + UK)
-	if geo.Country != "" && gdprCountries[geo.Country] {
+	if country != "" && gdprCountries[country] {
 		return RegulationGDPR
 	}
 
 	// Check US state privacy laws
-	if geo.Country == "USA" && geo.Region != "" {
-		if regulation, exists := usPrivacyStates[geo.Region]; exists {
+	if country == "USA" && geo.Region != "" {
+		if regulation, exists := usPrivacyStates[strings.ToUpper(geo.Region)]; exists {
 			return regulation
 		}
 		return RegulationNone
 	}
 
 	// Check other countries with privacy laws
-	switch geo.Country {
+	switch country {
 	case "BRA":
 		return RegulationLGPD
 	case "CAN":
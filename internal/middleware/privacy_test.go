@@ -720,3 +720,84 @@ func TestPrivacyMiddleware_NoAnonymizationWithoutGDPR(t *testing.T) {
 		t.Errorf("Expected original IP without GDPR, got %q", modifiedReq.Device.IP)
 	}
 }
+
+func TestDetectRegulationFromGeo_CountryCodeForms(t *testing.T) {
+	tests := []struct {
+		name     string
+		geo      *openrtb.Geo
+		expected PrivacyRegulation
+	}{
+		{"Nil geo", nil, RegulationNone},
+		{"Alpha-3 EU", &openrtb.Geo{Country: "DEU"}, RegulationGDPR},
+		{"Alpha-2 EU", &openrtb.Geo{Country: "DE"}, RegulationGDPR},
+		{"Lowercase alpha-2 EU", &openrtb.Geo{Country: "fr"}, RegulationGDPR},
+		{"Alpha-2 UK", &openrtb.Geo{Country: "GB"}, RegulationGDPR},
+		{"Alpha-3 California", &openrtb.Geo{Country: "USA", Region: "CA"}, RegulationCCPA},
+		{"Alpha-2 California", &openrtb.Geo{Country: "US", Region: "CA"}, RegulationCCPA},
+		{"Lowercase region", &openrtb.Geo{Country: "US", Region: "va"}, RegulationVCDPA},
+		{"Alpha-2 US no state law", &openrtb.Geo{Country: "US", Region: "TX"}, RegulationNone},
+		{"Alpha-2 Brazil", &openrtb.Geo{Country: "BR"}, RegulationLGPD},
+		{"Alpha-2 Canada", &openrtb.Geo{Country: "CA"}, RegulationPIPEDA},
+		{"Alpha-2 Japan", &openrtb.Geo{Country: "JP"}, RegulationNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectRegulationFromGeo(tt.geo); got != tt.expected {
+				t.Errorf("DetectRegulationFromGeo(%+v) = %s, expected %s", tt.geo, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPrivacyMiddleware_GeoEnforcementAlpha2Country(t *testing.T) {
+	// EU user identified by an alpha-2 country code must still trigger GDPR geo enforcement
+	config := DefaultPrivacyConfig()
+	config.GeoEnforcement = true
+	mw := NewPrivacyMiddleware(config)
+
+	called := false
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := &openrtb.BidRequest{
+		ID:     "test-alpha2",
+		Imp:    []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{}}},
+		Device: &openrtb.Device{Geo: &openrtb.Geo{Country: "DE"}},
+	}
+
+	body, _ := json.Marshal(req)
+	httpReq := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, httpReq)
+
+	if called {
+		t.Error("Handler should not be called for EU user without GDPR signal")
+	}
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rr.Code)
+	}
+}
+
+func TestDecodeTCFv2(t *testing.T) {
+	data, err := DecodeTCFv2(buildTestTCFString([]int{1, 3}, []int{32, 52}))
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if !data.PurposeConsents[0] || data.PurposeConsents[1] || !data.PurposeConsents[2] {
+		t.Errorf("expected purposes 1 and 3, got %v", data.PurposeConsents[:4])
+	}
+	if !data.VendorConsents[32] || !data.VendorConsents[52] {
+		t.Errorf("expected vendors 32 and 52, got %v", data.VendorConsents)
+	}
+
+	if data, err := DecodeTCFv2(""); data != nil || err != nil {
+		t.Errorf("expected nil result for empty string, got %+v %v", data, err)
+	}
+	if _, err := DecodeTCFv2("short"); err == nil {
+		t.Error("expected error for a too-short string")
+	}
+}
@@ -18,12 +18,20 @@ import (
 
 // PublisherAuthConfig holds publisher authentication configuration
 type PublisherAuthConfig struct {
-	Enabled           bool              // Enable publisher validation
-	AllowUnregistered bool              // Allow requests without publisher ID (for testing)
-	RegisteredPubs    map[string]string // publisher_id -> allowed domains (comma-separated, empty = any)
-	ValidateDomain    bool              // Validate request domain matches registered domains
-	RateLimitPerPub   int               // Requests per second per publisher (0 = unlimited)
-	UseRedis          bool              // Use Redis for publisher validation
+	Enabled           bool                     // Enable publisher validation
+	AllowUnregistered bool                     // Allow requests without publisher ID (for testing)
+	RegisteredPubs    map[string]string        // publisher_id -> allowed domains (comma-separated, empty = any)
+	ValidateDomain    bool                     // Validate request domain matches registered domains
+	RateLimitPerPub   int                      // Requests per second per publisher (0 = unlimited)
+	RateLimitTiers    map[string]RateLimitTier // Named limits assigned through the publisher's rate_limit_tier
+	UseRedis          bool                     // Use Redis for publisher validation
+	IVT               *IVTConfig               // IVT detection settings (nil = DefaultIVTConfig)
+}
+
+// RateLimitTier is a named per-publisher rate limit
+type RateLimitTier struct {
+	RequestsPerSecond int // Sustained requests per second (0 = unlimited)
+	BurstSize         int // Requests allowed at once (0 = RequestsPerSecond)
 }
 
 // DefaultPublisherAuthConfig returns default config
@@ -43,7 +51,8 @@ func DefaultPublisherAuthConfig() *PublisherAuthConfig {
 		AllowUnregistered: allowUnregistered,
 		RegisteredPubs:    parsePublishers(os.Getenv("REGISTERED_PUBLISHERS")),
 		ValidateDomain:    os.Getenv("PUBLISHER_VALIDATE_DOMAIN") == "true",
-		RateLimitPerPub:   100, // Default 100 RPS per publisher
+		RateLimitPerPub:   parsePositiveInt(os.Getenv("PUBLISHER_RATE_LIMIT_RPS"), 100), // Default 100 RPS per publisher
+		RateLimitTiers:    parseRateLimitTiers(os.Getenv("PUBLISHER_RATE_LIMIT_TIERS")),
 		UseRedis:          os.Getenv("PUBLISHER_AUTH_USE_REDIS") != "false",
 	}
 }
@@ -68,8 +77,49 @@ func parsePublishers(envValue string) map[string]string {
 	return pubs
 }
 
+// parsePositiveInt parses a positive integer, returning defaultVal for empty or invalid values
+func parsePositiveInt(value string, defaultVal int) int {
+	if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && n > 0 {
+		return n
+	}
+	return defaultVal
+}
+
+// parseRateLimitTiers parses "basic:50,standard:100:200,premium:1000:2000" (name:rps[:burst])
+func parseRateLimitTiers(envValue string) map[string]RateLimitTier {
+	tiers := make(map[string]RateLimitTier)
+	if envValue == "" {
+		return tiers
+	}
+
+	for _, entry := range strings.Split(envValue, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) < 2 || len(parts) > 3 || strings.TrimSpace(parts[0]) == "" {
+			log.Warn().Str("tier", entry).Msg("Ignoring invalid PUBLISHER_RATE_LIMIT_TIERS entry")
+			continue
+		}
+		rps, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || rps < 0 {
+			log.Warn().Str("tier", entry).Msg("Ignoring invalid PUBLISHER_RATE_LIMIT_TIERS entry")
+			continue
+		}
+		tier := RateLimitTier{RequestsPerSecond: rps}
+		if len(parts) == 3 {
+			burst, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+			if err != nil || burst < 0 {
+				log.Warn().Str("tier", entry).Msg("Ignoring invalid PUBLISHER_RATE_LIMIT_TIERS entry")
+				continue
+			}
+			tier.BurstSize = burst
+		}
+		tiers[strings.TrimSpace(parts[0])] = tier
+	}
+	return tiers
+}
+
 // minimalBidRequest is a minimal struct for extracting publisher info
 type minimalBidRequest struct {
+	ID   string `json:"id"`
 	Site *struct {
 		Domain    string `json:"domain"`
 		Publisher *struct {
@@ -82,6 +132,17 @@ type minimalBidRequest struct {
 			ID string `json:"id"`
 		} `json:"publisher"`
 	} `json:"app"`
+	Device *struct {
+		IP         string `json:"ip"`
+		IFA        string `json:"ifa"`
+		UA         string `json:"ua"`
+		OS         string `json:"os"`
+		Make       string `json:"make"`
+		DeviceType int    `json:"devicetype"`
+	} `json:"device"`
+	User *struct {
+		ID string `json:"id"`
+	} `json:"user"`
 }
 
 // PublisherStore interface for database operations
@@ -96,9 +157,10 @@ type PublisherAuth struct {
 	publisherStore PublisherStore
 	mu             sync.RWMutex
 
-	// Rate limiting per publisher
+	// Rate limiting per publisher (shared through Redis when distributed is set)
 	rateLimits   map[string]*rateLimitEntry
 	rateLimitsMu sync.RWMutex
+	distributed  *DistributedRateLimiter
 
 	// In-memory fallback cache (for Redis/PostgreSQL failures)
 	publisherCache   map[string]*publisherCacheEntry
@@ -135,10 +197,14 @@ func NewPublisherAuth(config *PublisherAuthConfig) *PublisherAuth {
 	if config == nil {
 		config = DefaultPublisherAuthConfig()
 	}
+	ivtConfig := config.IVT
+	if ivtConfig == nil {
+		ivtConfig = DefaultIVTConfig()
+	}
 	return &PublisherAuth{
 		config:      config,
 		rateLimits:  make(map[string]*rateLimitEntry),
-		ivtDetector: NewIVTDetector(DefaultIVTConfig()),
+		ivtDetector: NewIVTDetector(ivtConfig),
 	}
 }
 
@@ -149,6 +215,13 @@ func (p *PublisherAuth) SetRedisClient(client RedisClient) {
 	p.redisClient = client
 }
 
+// SetDistributedRateLimiter shares per-publisher limits across instances through Redis
+func (p *PublisherAuth) SetDistributedRateLimiter(distributed *DistributedRateLimiter) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.distributed = distributed
+}
+
 // SetPublisherStore sets the PostgreSQL publisher store
 func (p *PublisherAuth) SetPublisherStore(store PublisherStore) {
 	p.mu.Lock()
@@ -207,9 +280,19 @@ func (p *PublisherAuth) Middleware(next http.Handler) http.Handler {
 			return
 		}
 
+		// Retrieve the full publisher object for its IVT policy and downstream use
+		var pub interface{}
+		if publisherID != "" && p.publisherStore != nil {
+			if found, err := p.publisherStore.GetByPublisherID(r.Context(), publisherID); err == nil && found != nil {
+				pub = found
+			}
+		}
+
 		// IVT detection (Invalid Traffic)
 		if p.ivtDetector != nil {
-			ivtResult := p.ivtDetector.Validate(r.Context(), r, publisherID, domain)
+			ivtReq := ivtRequest(&minReq, publisherID, domain)
+			ivtReq.Policy = publisherIVTPolicy(pub)
+			ivtResult := p.ivtDetector.ValidateRequest(r.Context(), r, ivtReq)
 
 			// Log IVT detection
 			if !ivtResult.IsValid {
@@ -221,6 +304,7 @@ func (p *PublisherAuth) Middleware(next http.Handler) http.Handler {
 					Int("ivt_score", ivtResult.Score).
 					Int("signal_count", len(ivtResult.Signals)).
 					Bool("blocked", ivtResult.ShouldBlock).
+					Interface("behavior", ivtResult.Behavior).
 					Msg("IVT detected")
 			}
 
@@ -230,7 +314,12 @@ func (p *PublisherAuth) Middleware(next http.Handler) http.Handler {
 					Str("publisher_id", publisherID).
 					Str("reason", ivtResult.BlockReason).
 					Int("score", ivtResult.Score).
+					Int("nbr", int(ivtResult.NoBidReason)).
 					Msg("Request blocked - IVT detected")
+				if ivtResult.NoBidReason != 0 {
+					http.Error(w, `{"error":"invalid traffic detected","nbr":`+strconv.Itoa(int(ivtResult.NoBidReason))+`}`, http.StatusForbidden)
+					return
+				}
 				http.Error(w, `{"error":"invalid traffic detected"}`, http.StatusForbidden)
 				return
 			}
@@ -243,25 +332,26 @@ func (p *PublisherAuth) Middleware(next http.Handler) http.Handler {
 		}
 
 		// Apply rate limiting per publisher
-		if publisherID != "" && !p.checkRateLimit(publisherID) {
-			log.Warn().
-				Str("publisher_id", publisherID).
-				Msg("Publisher rate limit exceeded")
-			http.Error(w, `{"error":"rate limit exceeded"}`, http.StatusTooManyRequests)
-			return
+		if publisherID != "" {
+			decision := p.rateLimit(r.Context(), publisherID, pub)
+			setRateLimitHeaders(w.Header(), decision)
+			if !decision.Allowed {
+				log.Warn().
+					Str("publisher_id", publisherID).
+					Int("limit", decision.Limit).
+					Msg("Publisher rate limit exceeded")
+				http.Error(w, `{"error":"rate limit exceeded"}`, http.StatusTooManyRequests)
+				return
+			}
 		}
 
 		// Add publisher ID to request context via header
 		r.Header.Set("X-Publisher-ID", publisherID)
 
-		// Retrieve and store full publisher object in context for downstream use
-		if publisherID != "" && p.publisherStore != nil {
-			pub, err := p.publisherStore.GetByPublisherID(r.Context(), publisherID)
-			if err == nil && pub != nil {
-				// Store publisher in context for exchange to access bid_multiplier
-				ctx := context.WithValue(r.Context(), publisherContextKey, pub)
-				r = r.WithContext(ctx)
-			}
+		// Store publisher in context for exchange to access bid_multiplier
+		if pub != nil {
+			ctx := context.WithValue(r.Context(), publisherContextKey, pub)
+			r = r.WithContext(ctx)
 		}
 
 		// Restore body for handler
@@ -286,6 +376,23 @@ func (p *PublisherAuth) extractPublisherInfo(req *minimalBidRequest) (publisherI
 	return
 }
 
+// ivtRequest extracts the bid request fields used by behavioral IVT checks
+func ivtRequest(req *minimalBidRequest, publisherID, domain string) *IVTRequest {
+	ivtReq := &IVTRequest{PublisherID: publisherID, Domain: domain, RequestID: req.ID}
+	if req.Device != nil {
+		ivtReq.DeviceIP = req.Device.IP
+		ivtReq.IFA = req.Device.IFA
+		ivtReq.DeviceUA = req.Device.UA
+		ivtReq.DeviceOS = req.Device.OS
+		ivtReq.DeviceMake = req.Device.Make
+		ivtReq.DeviceType = req.Device.DeviceType
+	}
+	if req.User != nil {
+		ivtReq.UserID = req.User.ID
+	}
+	return ivtReq
+}
+
 // validatePublisher validates the publisher ID and domain
 // Fallback chain: Redis → PostgreSQL → Memory cache → RegisteredPubs
 func (p *PublisherAuth) validatePublisher(ctx context.Context, publisherID, domain string) error {
@@ -360,6 +467,9 @@ func (p *PublisherAuth) validatePublisher(ctx context.Context, publisherID, doma
 		// PostgreSQL error or not found - log and fall through to memory cache
 		if err != nil {
 			p.logDatabaseFallback(err, publisherID)
+		} else {
+			// Paused or archived: don't keep serving it from the cache
+			p.InvalidatePublisher(publisherID)
 		}
 		// Continue to memory cache fallback
 	}
@@ -437,7 +547,11 @@ func (p *PublisherAuth) checkRateLimit(publisherID string) bool {
 	if rateLimit <= 0 {
 		return true // Unlimited
 	}
+	return p.takeRateLimitToken(publisherID, rateLimit, rateLimit).Allowed
+}
 
+// takeRateLimitToken consumes a token from the publisher's in-memory bucket
+func (p *PublisherAuth) takeRateLimitToken(publisherID string, rate, burst int) RateLimitDecision {
 	p.rateLimitsMu.Lock()
 	defer p.rateLimitsMu.Unlock()
 
@@ -445,33 +559,63 @@ func (p *PublisherAuth) checkRateLimit(publisherID string) bool {
 	now := time.Now()
 
 	if !exists {
-		p.rateLimits[publisherID] = &rateLimitEntry{
-			tokens:    float64(rateLimit) - 1,
+		entry = &rateLimitEntry{
+			tokens:    float64(burst),
 			lastCheck: now,
 		}
-		return true
+		p.rateLimits[publisherID] = entry
 	}
 
-	// Refill tokens based on time elapsed
-	elapsed := now.Sub(entry.lastCheck).Seconds()
-	entry.tokens += elapsed * float64(rateLimit)
-	if entry.tokens > float64(rateLimit) {
-		entry.tokens = float64(rateLimit)
+	decision := localTokenBucket(&entry.tokens, &entry.lastCheck, now, rate, burst)
+
+	// Opportunistic cleanup to prevent unbounded memory growth
+	// Remove stale entries if map is getting too large (>1000 entries)
+	if decision.Allowed && len(p.rateLimits) > 1000 {
+		p.cleanupStaleRateLimits(now)
 	}
-	entry.lastCheck = now
+	return decision
+}
 
-	// Try to consume a token
-	if entry.tokens >= 1 {
-		entry.tokens--
-		// Opportunistic cleanup to prevent unbounded memory growth
-		// Remove stale entries if map is getting too large (>1000 entries)
-		if len(p.rateLimits) > 1000 {
-			p.cleanupStaleRateLimits(now)
-		}
-		return true
+// rateLimit applies the publisher's tier limit, shared across instances when Redis is available
+func (p *PublisherAuth) rateLimit(ctx context.Context, publisherID string, pub interface{}) RateLimitDecision {
+	rate, burst := p.publisherRateLimit(publisherID, pub)
+	if rate <= 0 {
+		return RateLimitDecision{Allowed: true} // Unlimited
 	}
 
-	return false
+	p.mu.RLock()
+	distributed := p.distributed
+	p.mu.RUnlock()
+
+	if decision, ok := distributed.Allow(ctx, "pub:"+publisherID, rate, burst); ok {
+		return decision
+	}
+	return p.takeRateLimitToken(publisherID, rate, burst)
+}
+
+// publisherRateLimit returns the rate and burst for a publisher's tier
+// Publishers without a tier, or with an unknown one, get RateLimitPerPub.
+func (p *PublisherAuth) publisherRateLimit(publisherID string, pub interface{}) (rate, burst int) {
+	type rateLimitTierProvider interface {
+		GetRateLimitTier() string
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if tp, ok := pub.(rateLimitTierProvider); ok {
+		if name := tp.GetRateLimitTier(); name != "" {
+			if tier, ok := p.config.RateLimitTiers[name]; ok {
+				burst = tier.BurstSize
+				if burst <= 0 {
+					burst = tier.RequestsPerSecond
+				}
+				return tier.RequestsPerSecond, burst
+			}
+			log.Debug().Str("publisher_id", publisherID).Str("tier", name).Msg("Unknown rate limit tier, using default")
+		}
+	}
+	return p.config.RateLimitPerPub, p.config.RateLimitPerPub
 }
 
 // cleanupStaleRateLimits removes rate limit entries that haven't been accessed recently
@@ -561,6 +705,21 @@ func (p *PublisherAuth) getCachedPublisher(publisherID string) string {
 	return entry.allowedDomains
 }
 
+// InvalidatePublisher drops a publisher from the in-memory cache
+// Called after admin writes so the next request re-reads Redis/PostgreSQL.
+func (p *PublisherAuth) InvalidatePublisher(publisherID string) {
+	p.publisherCacheMu.Lock()
+	defer p.publisherCacheMu.Unlock()
+	delete(p.publisherCache, publisherID)
+}
+
+// ClearCache drops every publisher from the in-memory cache
+func (p *PublisherAuth) ClearCache() {
+	p.publisherCacheMu.Lock()
+	defer p.publisherCacheMu.Unlock()
+	p.publisherCache = make(map[string]*publisherCacheEntry)
+}
+
 // cleanupExpiredCache removes expired cache entries
 func (p *PublisherAuth) cleanupExpiredCache() {
 	now := time.Now()
@@ -609,6 +768,13 @@ func (p *PublisherAuth) SetIVTConfig(config *IVTConfig) {
 	}
 }
 
+// SetIVTBehaviorClient sets the Redis client for behavioral IVT sliding windows
+func (p *PublisherAuth) SetIVTBehaviorClient(client IVTBehaviorClient) {
+	if p.ivtDetector != nil {
+		p.ivtDetector.SetBehaviorClient(client)
+	}
+}
+
 // GetIVTConfig returns current IVT configuration
 func (p *PublisherAuth) GetIVTConfig() *IVTConfig {
 	if p.ivtDetector != nil {
@@ -625,6 +791,22 @@ func (p *PublisherAuth) GetIVTMetrics() IVTMetrics {
 	return IVTMetrics{}
 }
 
+// IVTStats returns the per-publisher IVT aggregates (nil if IVT detection is disabled)
+func (p *PublisherAuth) IVTStats() *IVTStats {
+	if p.ivtDetector != nil {
+		return p.ivtDetector.Stats()
+	}
+	return nil
+}
+
+// GeoIP returns the GeoIP lookup loaded by the IVT detector (nil if disabled)
+func (p *PublisherAuth) GeoIP() GeoIPLookup {
+	if p.ivtDetector != nil {
+		return p.ivtDetector.GeoIP()
+	}
+	return nil
+}
+
 // EnableIVTMonitoring enables/disables IVT monitoring (detection, logging, metrics)
 func (p *PublisherAuth) EnableIVTMonitoring(enabled bool) {
 	if p.ivtDetector != nil {
@@ -219,6 +219,68 @@ func TestValidatePublisher_MemoryCacheExpiration(t *testing.T) {
 	}
 }
 
+// TestValidatePublisher_DeactivatedPublisherEvicted tests that a publisher PostgreSQL no longer
+// returns (paused or archived) isn't served from the memory cache
+func TestValidatePublisher_DeactivatedPublisherEvicted(t *testing.T) {
+	mockStore := &mockPublisherStore{
+		data: map[string]*mockPublisher{
+			"pub123": {PublisherID: "pub123", AllowedDomains: "example.com"},
+		},
+	}
+
+	auth := NewPublisherAuth(&PublisherAuthConfig{Enabled: true})
+	auth.SetPublisherStore(mockStore)
+
+	if err := auth.validatePublisher(context.Background(), "pub123", "example.com"); err != nil {
+		t.Fatalf("First request should succeed: %v", err)
+	}
+
+	// GetByPublisherID only returns active publishers
+	mockStore.mu.Lock()
+	mockStore.data = nil
+	mockStore.mu.Unlock()
+
+	if err := auth.validatePublisher(context.Background(), "pub123", "example.com"); err == nil {
+		t.Error("Expected deactivated publisher to be rejected despite the cached entry")
+	}
+	if cached := auth.getCachedPublisher("pub123"); cached != "" {
+		t.Errorf("Expected cache entry to be evicted, got %q", cached)
+	}
+}
+
+// TestInvalidatePublisher tests explicit cache eviction after admin writes
+func TestInvalidatePublisher(t *testing.T) {
+	auth := NewPublisherAuth(&PublisherAuthConfig{Enabled: true})
+	auth.cachePublisher("pub123", "example.com", time.Minute)
+	auth.cachePublisher("pub456", "other.com", time.Minute)
+
+	auth.InvalidatePublisher("pub123")
+	auth.InvalidatePublisher("unknown") // No-op
+
+	if cached := auth.getCachedPublisher("pub123"); cached != "" {
+		t.Errorf("Expected pub123 evicted, got %q", cached)
+	}
+	if cached := auth.getCachedPublisher("pub456"); cached != "other.com" {
+		t.Errorf("Expected pub456 to stay cached, got %q", cached)
+	}
+}
+
+// TestPublisherAuth_ClearCache tests dropping every cached publisher after a reconnect
+func TestPublisherAuth_ClearCache(t *testing.T) {
+	auth := NewPublisherAuth(&PublisherAuthConfig{Enabled: true})
+	auth.cachePublisher("pub123", "example.com", time.Minute)
+	auth.cachePublisher("pub456", "other.com", time.Minute)
+
+	auth.ClearCache()
+
+	if cached := auth.getCachedPublisher("pub123"); cached != "" {
+		t.Errorf("Expected pub123 evicted, got %q", cached)
+	}
+	if cached := auth.getCachedPublisher("pub456"); cached != "" {
+		t.Errorf("Expected pub456 evicted, got %q", cached)
+	}
+}
+
 // TestValidatePublisher_FallbackToRegisteredPubs tests falling back to in-memory RegisteredPubs
 func TestValidatePublisher_FallbackToRegisteredPubs(t *testing.T) {
 	mockRedis := &mockRedisClientWithErrors{
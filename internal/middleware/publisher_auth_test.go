@@ -504,6 +504,15 @@ func TestNewPublisherAuth_NilConfig(t *testing.T) {
 	}
 }
 
+func TestNewPublisherAuth_IVTConfig(t *testing.T) {
+	ivtConfig := &IVTConfig{MonitoringEnabled: true, BlockThreshold: 85}
+	auth := NewPublisherAuth(&PublisherAuthConfig{Enabled: true, IVT: ivtConfig})
+
+	if got := auth.GetIVTConfig(); got == nil || got.BlockThreshold != 85 {
+		t.Errorf("Expected the configured IVT settings, got %+v", got)
+	}
+}
+
 func TestSetRedisClient(t *testing.T) {
 	auth := NewPublisherAuth(&PublisherAuthConfig{
 		Enabled: true,
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net"
 	"net/http"
 	"os"
@@ -19,6 +21,7 @@ type RateLimitConfig struct {
 	WindowSize        time.Duration // Time window for rate limiting
 	TrustedProxies    []*net.IPNet  // CIDR ranges of trusted proxies
 	TrustXFF          bool          // Whether to trust X-Forwarded-For at all
+	RedisTimeout      time.Duration // Budget for each distributed limit check before using local limits
 }
 
 // DefaultRateLimitConfig returns default rate limit configuration
@@ -33,33 +36,19 @@ func DefaultRateLimitConfig() *RateLimitConfig {
 		burst = rps * 2 // Default burst size
 	}
 
-	// Parse trusted proxies from env (comma-separated CIDR ranges)
+	// Parse trusted proxies from env (comma-separated CIDR ranges); invalid entries are skipped
 	// Example: TRUSTED_PROXIES=10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,127.0.0.1/32
-	var trustedProxies []*net.IPNet
-	if proxyStr := os.Getenv("TRUSTED_PROXIES"); proxyStr != "" {
-		for _, cidr := range strings.Split(proxyStr, ",") {
-			cidr = strings.TrimSpace(cidr)
-			if cidr == "" {
-				continue
-			}
-			// Handle single IPs by adding /32 or /128
-			if !strings.Contains(cidr, "/") {
-				if strings.Contains(cidr, ":") {
-					cidr += "/128"
-				} else {
-					cidr += "/32"
-				}
-			}
-			_, network, err := net.ParseCIDR(cidr)
-			if err == nil {
-				trustedProxies = append(trustedProxies, network)
-			}
-		}
-	}
+	trustedProxies, _ := ParseTrustedProxies(strings.Split(os.Getenv("TRUSTED_PROXIES"), ","))
 
 	// Only trust XFF header if trusted proxies are configured
 	trustXFF := len(trustedProxies) > 0
 
+	// RATE_LIMIT_REDIS_TIMEOUT: Redis budget per check when limits are shared (default: 5ms)
+	redisTimeout, err := time.ParseDuration(os.Getenv("RATE_LIMIT_REDIS_TIMEOUT"))
+	if err != nil || redisTimeout <= 0 {
+		redisTimeout = 5 * time.Millisecond
+	}
+
 	// P1-1: Rate limiting ENABLED by default for DoS protection
 	// Set RATE_LIMIT_ENABLED=false to disable (development only)
 	return &RateLimitConfig{
@@ -70,7 +59,35 @@ func DefaultRateLimitConfig() *RateLimitConfig {
 		WindowSize:        time.Second,
 		TrustedProxies:    trustedProxies,
 		TrustXFF:          trustXFF,
+		RedisTimeout:      redisTimeout,
+	}
+}
+
+// ParseTrustedProxies parses CIDR ranges, treating single IPs as /32 or /128
+// Valid ranges are returned even when some entries fail to parse.
+func ParseTrustedProxies(entries []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	var errs []error
+	for _, cidr := range entries {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		if !strings.Contains(cidr, "/") {
+			if strings.Contains(cidr, ":") {
+				cidr += "/128"
+			} else {
+				cidr += "/32"
+			}
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		networks = append(networks, network)
 	}
+	return networks, errors.Join(errs...)
 }
 
 // clientState tracks rate limit state for a single client
@@ -85,12 +102,15 @@ type RateLimitMetrics interface {
 }
 
 // RateLimiter provides rate limiting middleware using token bucket algorithm
+// With a distributed limiter set, limits are shared across instances through Redis and
+// the in-memory buckets are only used while Redis is unavailable.
 type RateLimiter struct {
-	config  *RateLimitConfig
-	clients map[string]*clientState
-	mu      sync.Mutex
-	stopCh  chan struct{}
-	metrics RateLimitMetrics
+	config      *RateLimitConfig
+	clients     map[string]*clientState
+	mu          sync.Mutex
+	stopCh      chan struct{}
+	metrics     RateLimitMetrics
+	distributed *DistributedRateLimiter
 }
 
 // NewRateLimiter creates a new rate limiter
@@ -156,27 +176,50 @@ func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 		}
 
 		// Check rate limit
-		if !rl.allow(clientID) {
+		decision := rl.check(r.Context(), clientID)
+		setRateLimitHeaders(w.Header(), decision)
+		if !decision.Allowed {
 			// Record metric for rate limit rejection
-			if rl.metrics != nil {
-				rl.metrics.IncRateLimitRejected()
+			rl.mu.Lock()
+			metrics := rl.metrics
+			rl.mu.Unlock()
+			if metrics != nil {
+				metrics.IncRateLimitRejected()
 			}
-			w.Header().Set("Retry-After", "1")
-			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(rl.config.RequestsPerSecond))
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(decision.Limit))
 			w.Header().Set("X-RateLimit-Remaining", "0")
 			http.Error(w, `{"error":"rate limit exceeded"}`, http.StatusTooManyRequests)
 			return
 		}
 
 		// Add rate limit headers
-		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(rl.config.RequestsPerSecond))
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(decision.Limit))
 
 		next.ServeHTTP(w, r)
 	})
 }
 
-// allow checks if a request from the given client should be allowed
+// check decides whether a request from the given client is allowed
+// The shared Redis limit is used when available, the in-memory bucket otherwise.
+func (rl *RateLimiter) check(ctx context.Context, clientID string) RateLimitDecision {
+	rl.mu.Lock()
+	distributed := rl.distributed
+	rate, burst := rl.config.RequestsPerSecond, rl.config.BurstSize
+	rl.mu.Unlock()
+
+	if decision, ok := distributed.Allow(ctx, "client:"+clientID, rate, burst); ok {
+		return decision
+	}
+	return rl.take(clientID)
+}
+
+// allow checks if a request from the given client should be allowed by the in-memory bucket
 func (rl *RateLimiter) allow(clientID string) bool {
+	return rl.take(clientID).Allowed
+}
+
+// take consumes a token from the client's in-memory bucket
+func (rl *RateLimiter) take(clientID string) RateLimitDecision {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
@@ -185,31 +228,19 @@ func (rl *RateLimiter) allow(clientID string) bool {
 
 	if !exists {
 		// New client, start with burst size tokens
-		rl.clients[clientID] = &clientState{
-			tokens:    float64(rl.config.BurstSize - 1), // -1 for current request
+		state = &clientState{
+			tokens:    float64(rl.config.BurstSize),
 			lastCheck: now,
 		}
-		return true
-	}
-
-	// Calculate tokens to add based on time elapsed
-	elapsed := now.Sub(state.lastCheck).Seconds()
-	state.tokens += elapsed * float64(rl.config.RequestsPerSecond)
-
-	// Cap at burst size
-	if state.tokens > float64(rl.config.BurstSize) {
-		state.tokens = float64(rl.config.BurstSize)
+		rl.clients[clientID] = state
 	}
 
-	state.lastCheck = now
-
-	// Check if we have tokens available
-	if state.tokens < 1 {
-		return false
-	}
+	return localTokenBucket(&state.tokens, &state.lastCheck, now, rl.config.RequestsPerSecond, rl.config.BurstSize)
+}
 
-	state.tokens--
-	return true
+// ClientIP returns the client IP, trusting X-Forwarded-For and X-Real-IP only from trusted proxies
+func (rl *RateLimiter) ClientIP(r *http.Request) string {
+	return rl.getClientIP(r)
 }
 
 // getClientIP extracts the client IP from the request with secure XFF handling
@@ -311,6 +342,20 @@ func (rl *RateLimiter) SetBurstSize(burst int) {
 	rl.config.BurstSize = burst
 }
 
+// RedisTimeout returns the configured budget for distributed limit checks
+func (rl *RateLimiter) RedisTimeout() time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.config.RedisTimeout
+}
+
+// SetDistributed shares limits across instances through Redis (nil uses in-memory limits only)
+func (rl *RateLimiter) SetDistributed(distributed *DistributedRateLimiter) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.distributed = distributed
+}
+
 // SetMetrics sets the metrics interface for the rate limiter
 func (rl *RateLimiter) SetMetrics(m RateLimitMetrics) {
 	rl.mu.Lock()
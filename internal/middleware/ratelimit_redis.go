@@ -0,0 +1,217 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RedisRateLimitPrefix is the key prefix for distributed rate limit state
+const RedisRateLimitPrefix = "tne_catalyst:ratelimit:"
+
+// gcraScript applies the generic cell rate algorithm to one key using the Redis clock
+// The key stores the theoretical arrival time (TAT) in microseconds, so every instance
+// shares one limit and no per-request state is written for denied requests.
+// ARGV[1]: emission interval (us per request), ARGV[2]: burst capacity (us).
+// Returns {allowed, remaining, retry_after_us, reset_after_us}.
+const gcraScript = `
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local emission = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+
+local new_tat = tat + emission
+local used = new_tat - now
+if used > capacity then
+	return {0, 0, used - capacity, tat - now}
+end
+
+redis.call('SET', KEYS[1], string.format('%d', new_tat), 'PX', math.ceil(used / 1000))
+return {1, math.floor((capacity - used) / emission), 0, used}
+`
+
+// RateLimitClient defines the Redis operations used for distributed rate limiting
+type RateLimitClient interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// RateLimitDecision is the outcome of one rate limit check
+type RateLimitDecision struct {
+	Allowed    bool
+	Limit      int           // Sustained requests per second
+	Burst      int           // Requests allowed at once
+	Remaining  int           // Requests left before the limit applies
+	RetryAfter time.Duration // Wait before the next request is allowed (0 if allowed)
+	ResetAfter time.Duration // Time until the full burst is available again
+}
+
+// DistributedRateLimiter enforces rate limits shared by every instance through Redis
+// When Redis fails, Allow reports it so callers can use their local limiter, and Redis
+// is not retried until the backoff passes so an outage doesn't add latency to each request.
+type DistributedRateLimiter struct {
+	client  RateLimitClient
+	timeout time.Duration
+	backoff time.Duration
+
+	mu      sync.Mutex
+	retryAt time.Time
+}
+
+// defaultRateLimitRedisBackoff is how long Redis is skipped after a failure
+const defaultRateLimitRedisBackoff = 5 * time.Second
+
+// NewDistributedRateLimiter creates a Redis-backed rate limiter; timeout bounds each Redis call
+func NewDistributedRateLimiter(client RateLimitClient, timeout time.Duration) *DistributedRateLimiter {
+	return &DistributedRateLimiter{
+		client:  client,
+		timeout: timeout,
+		backoff: defaultRateLimitRedisBackoff,
+	}
+}
+
+// Allow checks one request against the shared limit for key
+// ok is false when Redis is unavailable; the decision must then come from a local limiter.
+func (l *DistributedRateLimiter) Allow(ctx context.Context, key string, rate, burst int) (decision RateLimitDecision, ok bool) {
+	if l == nil || l.client == nil || rate <= 0 {
+		return RateLimitDecision{}, false
+	}
+	if burst <= 0 {
+		burst = rate
+	}
+
+	l.mu.Lock()
+	skip := time.Now().Before(l.retryAt)
+	l.mu.Unlock()
+	if skip {
+		return RateLimitDecision{}, false
+	}
+
+	if l.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.timeout)
+		defer cancel()
+	}
+
+	emission := int64(time.Second/time.Microsecond) / int64(rate)
+	if emission < 1 {
+		emission = 1
+	}
+	raw, err := l.client.Eval(ctx, gcraScript, []string{RedisRateLimitPrefix + key}, emission, emission*int64(burst))
+	if err == nil {
+		decision, err = parseGCRAResult(raw)
+	}
+	if err != nil {
+		l.mu.Lock()
+		l.retryAt = time.Now().Add(l.backoff)
+		l.mu.Unlock()
+		logRateLimitFallback(err)
+		return RateLimitDecision{}, false
+	}
+
+	decision.Limit = rate
+	decision.Burst = burst
+	return decision, true
+}
+
+// parseGCRAResult converts the script reply into a decision
+func parseGCRAResult(raw interface{}) (RateLimitDecision, error) {
+	values, ok := raw.([]interface{})
+	if !ok || len(values) != 4 {
+		return RateLimitDecision{}, fmt.Errorf("unexpected rate limit result %T", raw)
+	}
+	n := make([]int64, len(values))
+	for i, v := range values {
+		if n[i], ok = v.(int64); !ok {
+			return RateLimitDecision{}, fmt.Errorf("unexpected rate limit value %T", v)
+		}
+	}
+	return RateLimitDecision{
+		Allowed:    n[0] == 1,
+		Remaining:  int(n[1]),
+		RetryAfter: time.Duration(n[2]) * time.Microsecond,
+		ResetAfter: time.Duration(n[3]) * time.Microsecond,
+	}, nil
+}
+
+// lastRateLimitWarning rate-limits the Redis fallback warning (max 1 log per minute)
+var lastRateLimitWarning sync.Map // string -> time.Time
+
+// logRateLimitFallback logs that rate limiting fell back to local state
+func logRateLimitFallback(err error) {
+	if last, ok := lastRateLimitWarning.Load("redis"); ok {
+		if time.Since(last.(time.Time)) < time.Minute {
+			return
+		}
+	}
+	lastRateLimitWarning.Store("redis", time.Now())
+	log.Warn().Err(err).Msg("Distributed rate limiting unavailable, using per-instance limits")
+}
+
+// localTokenBucket applies a token bucket refill and consumes a token if one is available
+// tokens and lastCheck are updated in place; the caller holds the lock guarding them.
+func localTokenBucket(tokens *float64, lastCheck *time.Time, now time.Time, rate, burst int) RateLimitDecision {
+	elapsed := now.Sub(*lastCheck).Seconds()
+	*tokens += elapsed * float64(rate)
+	if *tokens > float64(burst) {
+		*tokens = float64(burst)
+	}
+	*lastCheck = now
+
+	decision := RateLimitDecision{Limit: rate, Burst: burst}
+	if *tokens < 1 {
+		decision.RetryAfter = secondsToDuration((1 - *tokens) / float64(rate))
+	} else {
+		*tokens--
+		decision.Allowed = true
+	}
+	decision.Remaining = int(math.Max(0, math.Floor(*tokens)))
+	decision.ResetAfter = secondsToDuration((float64(burst) - *tokens) / float64(rate))
+	return decision
+}
+
+// secondsToDuration converts fractional seconds to a duration
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// setRateLimitHeaders writes the IETF RateLimit headers for a decision
+// When several limiters apply to one request, the most restrictive remaining count is kept.
+func setRateLimitHeaders(h http.Header, d RateLimitDecision) {
+	if d.Limit <= 0 {
+		return
+	}
+	if existing := h.Get("RateLimit-Remaining"); existing != "" {
+		if n, err := strconv.Atoi(existing); err == nil && n <= d.Remaining && d.Allowed {
+			return
+		}
+	}
+	h.Set("RateLimit-Limit", strconv.Itoa(d.Burst))
+	h.Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(d.ResetAfter)))
+	// The burst is the quota, replenished in full over burst/rate seconds
+	window := (d.Burst + d.Limit - 1) / d.Limit
+	h.Set("RateLimit-Policy", strconv.Itoa(d.Burst)+";w="+strconv.Itoa(window))
+	if !d.Allowed {
+		retry := ceilSeconds(d.RetryAfter)
+		if retry < 1 {
+			retry = 1
+		}
+		h.Set("Retry-After", strconv.Itoa(retry))
+	}
+}
+
+// ceilSeconds rounds a duration up to whole seconds
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,270 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// countingFailClient simulates a Redis outage and counts the attempts
+type countingFailClient struct {
+	calls int
+}
+
+func (c *countingFailClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	c.calls++
+	return nil, errors.New("connection refused")
+}
+
+// tierPublisher is a publisher record assigned to a rate limit tier
+type tierPublisher struct {
+	tier string
+}
+
+func (p *tierPublisher) GetAllowedDomains() string { return "" }
+func (p *tierPublisher) GetRateLimitTier() string  { return p.tier }
+
+// tierPublisherStore serves tierPublisher records by ID
+type tierPublisherStore map[string]*tierPublisher
+
+func (s tierPublisherStore) GetByPublisherID(ctx context.Context, publisherID string) (interface{}, error) {
+	if pub, ok := s[publisherID]; ok {
+		return pub, nil
+	}
+	return nil, nil
+}
+
+func TestDistributedRateLimiter_Allow(t *testing.T) {
+	client, _ := setupBehaviorRedis(t)
+	limiter := NewDistributedRateLimiter(client, time.Second)
+	ctx := context.Background()
+
+	// 1 RPS with a burst of 3: three requests pass, the fourth waits about a second
+	for i := 0; i < 3; i++ {
+		decision, ok := limiter.Allow(ctx, "pub:pub1", 1, 3)
+		if !ok {
+			t.Fatal("expected Redis to answer")
+		}
+		if !decision.Allowed {
+			t.Fatalf("expected request %d within the burst to pass", i+1)
+		}
+		if decision.Remaining != 2-i {
+			t.Errorf("expected %d remaining after request %d, got %d", 2-i, i+1, decision.Remaining)
+		}
+	}
+
+	decision, ok := limiter.Allow(ctx, "pub:pub1", 1, 3)
+	if !ok || decision.Allowed {
+		t.Fatalf("expected request past the burst to be denied, got %+v", decision)
+	}
+	if decision.RetryAfter <= 0 || decision.RetryAfter > time.Second {
+		t.Errorf("expected retry within a second, got %v", decision.RetryAfter)
+	}
+	if decision.Limit != 1 || decision.Burst != 3 {
+		t.Errorf("expected limit 1 and burst 3, got %d and %d", decision.Limit, decision.Burst)
+	}
+
+	// Other keys have their own limit
+	if decision, _ := limiter.Allow(ctx, "pub:pub2", 1, 3); !decision.Allowed {
+		t.Error("expected a different key to be unaffected")
+	}
+}
+
+func TestDistributedRateLimiter_SharedAcrossInstances(t *testing.T) {
+	client, _ := setupBehaviorRedis(t)
+	instanceA := NewDistributedRateLimiter(client, time.Second)
+	instanceB := NewDistributedRateLimiter(client, time.Second)
+	ctx := context.Background()
+
+	allowed := 0
+	for i := 0; i < 5; i++ {
+		for _, limiter := range []*DistributedRateLimiter{instanceA, instanceB} {
+			if decision, _ := limiter.Allow(ctx, "client:1.2.3.4", 1, 4); decision.Allowed {
+				allowed++
+			}
+		}
+	}
+	if allowed != 4 {
+		t.Errorf("expected the burst of 4 shared by both instances, got %d allowed", allowed)
+	}
+}
+
+func TestDistributedRateLimiter_Fallback(t *testing.T) {
+	client := &countingFailClient{}
+	limiter := NewDistributedRateLimiter(client, time.Millisecond)
+
+	if _, ok := limiter.Allow(context.Background(), "pub:pub1", 10, 10); ok {
+		t.Fatal("expected ok=false when Redis fails")
+	}
+	if _, ok := limiter.Allow(context.Background(), "pub:pub1", 10, 10); ok {
+		t.Fatal("expected ok=false during the backoff")
+	}
+	if client.calls != 1 {
+		t.Errorf("expected Redis skipped during the backoff, got %d calls", client.calls)
+	}
+
+	// Nil limiters and unlimited rates never consult Redis
+	var nilLimiter *DistributedRateLimiter
+	if _, ok := nilLimiter.Allow(context.Background(), "pub:pub1", 10, 10); ok {
+		t.Error("expected ok=false from a nil limiter")
+	}
+}
+
+func TestLocalTokenBucket(t *testing.T) {
+	now := time.Now()
+	tokens, lastCheck := 2.0, now
+
+	for i := 0; i < 2; i++ {
+		if d := localTokenBucket(&tokens, &lastCheck, now, 2, 2); !d.Allowed {
+			t.Fatalf("expected request %d to pass", i+1)
+		}
+	}
+	d := localTokenBucket(&tokens, &lastCheck, now, 2, 2)
+	if d.Allowed || d.Remaining != 0 {
+		t.Fatalf("expected an empty bucket to deny, got %+v", d)
+	}
+	if d.RetryAfter != 500*time.Millisecond {
+		t.Errorf("expected retry after 500ms at 2 RPS, got %v", d.RetryAfter)
+	}
+
+	// Refill is capped at the burst
+	d = localTokenBucket(&tokens, &lastCheck, now.Add(time.Minute), 2, 2)
+	if !d.Allowed || d.Remaining != 1 {
+		t.Errorf("expected a refilled bucket with 1 remaining, got %+v", d)
+	}
+}
+
+func TestSetRateLimitHeaders(t *testing.T) {
+	h := http.Header{}
+	setRateLimitHeaders(h, RateLimitDecision{Allowed: true, Limit: 10, Burst: 20, Remaining: 15, ResetAfter: 500 * time.Millisecond})
+
+	want := map[string]string{
+		"RateLimit-Limit":     "20",
+		"RateLimit-Remaining": "15",
+		"RateLimit-Reset":     "1",
+		"RateLimit-Policy":    "20;w=2",
+	}
+	for name, value := range want {
+		if got := h.Get(name); got != value {
+			t.Errorf("expected %s=%s, got %s", name, value, got)
+		}
+	}
+	if h.Get("Retry-After") != "" {
+		t.Error("expected no Retry-After when allowed")
+	}
+
+	// A less restrictive limiter doesn't overwrite the headers
+	setRateLimitHeaders(h, RateLimitDecision{Allowed: true, Limit: 1000, Burst: 2000, Remaining: 1999})
+	if h.Get("RateLimit-Remaining") != "15" {
+		t.Errorf("expected the more restrictive remaining count kept, got %s", h.Get("RateLimit-Remaining"))
+	}
+
+	// A denial always wins
+	setRateLimitHeaders(h, RateLimitDecision{Limit: 5, Burst: 5, RetryAfter: 10 * time.Millisecond, ResetAfter: time.Second})
+	if h.Get("RateLimit-Remaining") != "0" || h.Get("Retry-After") != "1" {
+		t.Errorf("expected denial headers, got remaining=%s retry=%s", h.Get("RateLimit-Remaining"), h.Get("Retry-After"))
+	}
+
+	// Unlimited decisions write nothing
+	empty := http.Header{}
+	setRateLimitHeaders(empty, RateLimitDecision{Allowed: true})
+	if len(empty) != 0 {
+		t.Errorf("expected no headers for an unlimited decision, got %v", empty)
+	}
+}
+
+func TestParseRateLimitTiers(t *testing.T) {
+	tiers := parseRateLimitTiers("basic:50, premium:1000:2000,broken,bad:x,unlimited:0")
+
+	if len(tiers) != 3 {
+		t.Fatalf("expected 3 valid tiers, got %v", tiers)
+	}
+	if tiers["basic"] != (RateLimitTier{RequestsPerSecond: 50}) {
+		t.Errorf("unexpected basic tier %+v", tiers["basic"])
+	}
+	if tiers["premium"] != (RateLimitTier{RequestsPerSecond: 1000, BurstSize: 2000}) {
+		t.Errorf("unexpected premium tier %+v", tiers["premium"])
+	}
+	if _, ok := tiers["unlimited"]; !ok {
+		t.Error("expected a zero-rate tier to be kept as unlimited")
+	}
+}
+
+func TestPublisherAuth_RateLimitTiers(t *testing.T) {
+	client, _ := setupBehaviorRedis(t)
+	auth := NewPublisherAuth(&PublisherAuthConfig{
+		Enabled:           true,
+		AllowUnregistered: true,
+		RateLimitPerPub:   100,
+		RateLimitTiers:    map[string]RateLimitTier{"trial": {RequestsPerSecond: 1, BurstSize: 2}},
+	})
+	auth.SetPublisherStore(tierPublisherStore{"trial-pub": {tier: "trial"}, "default-pub": {}})
+	auth.SetDistributedRateLimiter(NewDistributedRateLimiter(client, time.Second))
+
+	handler := auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	send := func(publisherID string) *httptest.ResponseRecorder {
+		body := []byte(`{"id":"1","imp":[{"id":"imp1"}],"site":{"domain":"example.com","publisher":{"id":"` + publisherID + `"}}}`)
+		req := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", bytes.NewReader(body))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for i := 0; i < 2; i++ {
+		if rr := send("trial-pub"); rr.Code != http.StatusOK {
+			t.Fatalf("expected request %d within the trial burst to pass, got %d", i+1, rr.Code)
+		}
+	}
+	rr := send("trial-pub")
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 past the trial burst, got %d", rr.Code)
+	}
+	if rr.Header().Get("RateLimit-Limit") != "2" || rr.Header().Get("Retry-After") == "" {
+		t.Errorf("expected RateLimit headers on the 429, got %v", rr.Header())
+	}
+
+	rr = send("default-pub")
+	if rr.Code != http.StatusOK || rr.Header().Get("RateLimit-Limit") != "100" {
+		t.Errorf("expected the default limit for a publisher without a tier, got %d %v", rr.Code, rr.Header())
+	}
+}
+
+func TestRateLimiter_Distributed(t *testing.T) {
+	client, _ := setupBehaviorRedis(t)
+	config := &RateLimitConfig{Enabled: true, RequestsPerSecond: 1, BurstSize: 1}
+
+	// Two instances sharing Redis share the client's limit
+	var codes []int
+	for i := 0; i < 2; i++ {
+		rl := NewRateLimiter(config)
+		rl.SetDistributed(NewDistributedRateLimiter(client, time.Second))
+		handler := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		codes = append(codes, rr.Code)
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusTooManyRequests {
+		t.Errorf("expected the second instance to see the shared limit, got %v", codes)
+	}
+
+	// Falls back to the in-memory bucket when Redis is down
+	rl := NewRateLimiter(config)
+	rl.SetDistributed(NewDistributedRateLimiter(&countingFailClient{}, time.Millisecond))
+	if d := rl.check(context.Background(), "192.0.2.1"); !d.Allowed {
+		t.Error("expected the local bucket to allow the first request")
+	}
+	if d := rl.check(context.Background(), "192.0.2.1"); d.Allowed {
+		t.Error("expected the local bucket to enforce the limit")
+	}
+}
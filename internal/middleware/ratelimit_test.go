@@ -316,3 +316,24 @@ func TestRateLimiterSetters(t *testing.T) {
 		t.Errorf("expected burst 50, got %d", rl.config.BurstSize)
 	}
 }
+
+func TestParseTrustedProxies(t *testing.T) {
+	networks, err := ParseTrustedProxies([]string{"10.0.0.0/8", " 127.0.0.1 ", "", "::1", "not-an-ip"})
+	if err == nil {
+		t.Error("Expected an error for the invalid entry")
+	}
+
+	want := []string{"10.0.0.0/8", "127.0.0.1/32", "::1/128"}
+	if len(networks) != len(want) {
+		t.Fatalf("Expected %d valid networks, got %v", len(want), networks)
+	}
+	for i, network := range networks {
+		if network.String() != want[i] {
+			t.Errorf("Expected %s, got %s", want[i], network)
+		}
+	}
+
+	if networks, err := ParseTrustedProxies(nil); err != nil || networks != nil {
+		t.Errorf("Expected no networks and no error for an empty list, got %v, %v", networks, err)
+	}
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// Skip reasons reported when a user sync is blocked by consent
+const (
+	SyncSkipGDPRNoConsent      = "gdpr_no_consent"
+	SyncSkipGDPRInvalidConsent = "gdpr_invalid_consent"
+	SyncSkipGDPRPurpose1       = "gdpr_purpose1"
+	SyncSkipGDPRVendor         = "gdpr_vendor"
+	SyncSkipUSOptOut           = "us_optout"
+)
+
+// SyncPrivacy holds the privacy signals sent to /cookie_sync and /setuid
+type SyncPrivacy struct {
+	GDPR        bool   // GDPR applies (gdpr=1)
+	GDPRConsent string // TCF v2 consent string
+	USPrivacy   string // US Privacy (CCPA) string
+	GPP         string // GPP string
+	GPPSID      string // Comma-separated applicable GPP section IDs
+}
+
+// SyncPrivacyFromRequest extracts the privacy signals from a bid request
+// The TCF string is read from user.consent, falling back to the OpenRTB 2.5 user.ext.consent
+func SyncPrivacyFromRequest(req *openrtb.BidRequest) SyncPrivacy {
+	var p SyncPrivacy
+	if req == nil {
+		return p
+	}
+
+	if req.Regs != nil {
+		p.GDPR = req.Regs.GDPR != nil && *req.Regs.GDPR == 1
+		p.USPrivacy = req.Regs.USPrivacy
+		p.GPP = req.Regs.GPP
+		sids := make([]string, 0, len(req.Regs.GPPSID))
+		for _, sid := range req.Regs.GPPSID {
+			sids = append(sids, strconv.Itoa(sid))
+		}
+		p.GPPSID = strings.Join(sids, ",")
+	}
+
+	if req.User != nil {
+		p.GDPRConsent = req.User.Consent
+		if p.GDPRConsent == "" && len(req.User.Ext) > 0 {
+			var ext struct {
+				Consent string `json:"consent"`
+			}
+			if err := json.Unmarshal(req.User.Ext, &ext); err == nil {
+				p.GDPRConsent = ext.Consent
+			}
+		}
+	}
+
+	return p
+}
+
+// SyncConsent is the evaluated consent for user syncing
+type SyncConsent struct {
+	gdprApplies bool
+	consent     string
+	tcf         *TCFv2Data
+	tcfErr      error
+	usOptOut    bool
+}
+
+// EvaluateSyncConsent decodes the TCF and GPP signals for a sync request
+// GDPR applies when gdpr=1 or gpp_sid includes the EU TCF section. The TCF string
+// is taken from gdpr_consent, falling back to the GPP EU TCF section.
+func EvaluateSyncConsent(p SyncPrivacy) *SyncConsent {
+	c := &SyncConsent{
+		gdprApplies: p.GDPR,
+		consent:     p.GDPRConsent,
+	}
+
+	for _, sid := range ParseGPPSID(p.GPPSID) {
+		if sid == GPPSectionTCFEUv2 {
+			c.gdprApplies = true
+		}
+	}
+
+	gpp, gppErr := ParseGPPString(p.GPP)
+	if c.consent == "" {
+		c.consent = gpp.TCFEUConsent()
+	}
+
+	if len(p.USPrivacy) >= 3 && p.USPrivacy[2] == 'Y' {
+		c.usOptOut = true
+	}
+	if gpp.HasUSOptOut() {
+		c.usOptOut = true
+	}
+
+	if c.gdprApplies && c.consent != "" {
+		m := &PrivacyMiddleware{}
+		c.tcf, c.tcfErr = m.parseTCFv2String(c.consent)
+	}
+	if c.gdprApplies && c.consent == "" && gppErr != nil {
+		// The only consent signal was an undecodable GPP string
+		c.tcfErr = gppErr
+	}
+
+	return c
+}
+
+// GDPRApplies returns true if GDPR applies to the request
+func (c *SyncConsent) GDPRApplies() bool {
+	return c.gdprApplies
+}
+
+// ConsentString returns the TCF consent string to forward to bidder sync URLs
+func (c *SyncConsent) ConsentString() string {
+	return c.consent
+}
+
+// USOptOut returns true if the user opted out of sale or sharing under US privacy law
+func (c *SyncConsent) USOptOut() bool {
+	return c.usOptOut
+}
+
+// AllowsSync checks whether a bidder may sync, returning a skip reason if not
+// Under GDPR, purpose 1 (store and access information) is required, plus vendor
+// consent when the bidder's GVL ID is known (gvlID > 0).
+func (c *SyncConsent) AllowsSync(gvlID int) (bool, string) {
+	if c.usOptOut {
+		return false, SyncSkipUSOptOut
+	}
+	if !c.gdprApplies {
+		return true, ""
+	}
+
+	if c.tcfErr != nil {
+		return false, SyncSkipGDPRInvalidConsent
+	}
+	if c.tcf == nil {
+		return false, SyncSkipGDPRNoConsent
+	}
+	if len(c.tcf.PurposeConsents) == 0 || !c.tcf.PurposeConsents[0] {
+		return false, SyncSkipGDPRPurpose1
+	}
+	if gvlID > 0 && !c.tcf.VendorConsents[gvlID] {
+		return false, SyncSkipGDPRVendor
+	}
+	return true, ""
+}
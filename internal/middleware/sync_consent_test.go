@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+func TestEvaluateSyncConsent(t *testing.T) {
+	fullConsent := buildTestTCFString([]int{1, 2}, []int{32})
+	noPurpose1 := buildTestTCFString([]int{2}, []int{32})
+
+	tests := []struct {
+		name    string
+		privacy SyncPrivacy
+		gvlID   int
+		allowed bool
+		reason  string
+	}{
+		{"no regulation", SyncPrivacy{}, 32, true, ""},
+		{"gdpr without consent", SyncPrivacy{GDPR: true}, 32, false, SyncSkipGDPRNoConsent},
+		{"gdpr invalid consent", SyncPrivacy{GDPR: true, GDPRConsent: "consent-string"}, 32, false, SyncSkipGDPRInvalidConsent},
+		{"gdpr without purpose 1", SyncPrivacy{GDPR: true, GDPRConsent: noPurpose1}, 32, false, SyncSkipGDPRPurpose1},
+		{"gdpr without vendor", SyncPrivacy{GDPR: true, GDPRConsent: fullConsent}, 52, false, SyncSkipGDPRVendor},
+		{"gdpr with vendor", SyncPrivacy{GDPR: true, GDPRConsent: fullConsent}, 32, true, ""},
+		{"gdpr unknown vendor", SyncPrivacy{GDPR: true, GDPRConsent: fullConsent}, 0, true, ""},
+		{"ccpa opt-out", SyncPrivacy{USPrivacy: "1YYN"}, 32, false, SyncSkipUSOptOut},
+		{"ccpa no opt-out", SyncPrivacy{USPrivacy: "1YNN"}, 32, true, ""},
+		{
+			"gpp tcf section",
+			SyncPrivacy{GPP: buildTestGPPString([]int{2}, []string{fullConsent}), GPPSID: "2"},
+			32, true, "",
+		},
+		{
+			"gpp sid applies gdpr",
+			SyncPrivacy{GPP: buildTestGPPString([]int{2}, []string{noPurpose1}), GPPSID: "2"},
+			32, false, SyncSkipGDPRPurpose1,
+		},
+		{"gpp sid with invalid gpp", SyncPrivacy{GPP: "!!!", GPPSID: "2"}, 32, false, SyncSkipGDPRInvalidConsent},
+		{
+			"gpp us opt-out",
+			SyncPrivacy{GPP: buildTestGPPString([]int{GPPSectionUSNat}, []string{buildTestUSSection(18)}), GPPSID: "7"},
+			32, false, SyncSkipUSOptOut,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, reason := EvaluateSyncConsent(tt.privacy).AllowsSync(tt.gvlID)
+			if allowed != tt.allowed || reason != tt.reason {
+				t.Errorf("Expected (%v, %q), got (%v, %q)", tt.allowed, tt.reason, allowed, reason)
+			}
+		})
+	}
+}
+
+func TestSyncConsent_ConsentString(t *testing.T) {
+	tcf := buildTestTCFString([]int{1}, nil)
+	consent := EvaluateSyncConsent(SyncPrivacy{
+		GPP:    buildTestGPPString([]int{2}, []string{tcf + ".QAAA"}),
+		GPPSID: "2",
+	})
+	if !consent.GDPRApplies() {
+		t.Error("GDPR should apply when gpp_sid includes the TCF section")
+	}
+	if consent.ConsentString() != tcf {
+		t.Errorf("Expected TCF string from GPP, got %q", consent.ConsentString())
+	}
+
+	explicit := EvaluateSyncConsent(SyncPrivacy{GDPR: true, GDPRConsent: "explicit"})
+	if explicit.ConsentString() != "explicit" {
+		t.Errorf("gdpr_consent should take precedence, got %q", explicit.ConsentString())
+	}
+}
+
+func TestSyncPrivacyFromRequest(t *testing.T) {
+	gdpr := 1
+	req := &openrtb.BidRequest{
+		Regs: &openrtb.Regs{GDPR: &gdpr, USPrivacy: "1YNN", GPP: "DBABMA~x", GPPSID: []int{2, 6}},
+		User: &openrtb.User{Ext: json.RawMessage(`{"consent":"legacy-consent"}`)},
+	}
+
+	p := SyncPrivacyFromRequest(req)
+	want := SyncPrivacy{GDPR: true, GDPRConsent: "legacy-consent", USPrivacy: "1YNN", GPP: "DBABMA~x", GPPSID: "2,6"}
+	if p != want {
+		t.Errorf("Expected %+v, got %+v", want, p)
+	}
+
+	req.User.Consent = "consent"
+	if got := SyncPrivacyFromRequest(req).GDPRConsent; got != "consent" {
+		t.Errorf("user.consent should take precedence, got %q", got)
+	}
+
+	if got := SyncPrivacyFromRequest(nil); got != (SyncPrivacy{}) {
+		t.Errorf("Expected empty privacy for nil request, got %+v", got)
+	}
+}
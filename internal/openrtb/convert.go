@@ -0,0 +1,381 @@
+package openrtb
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// OpenRTB protocol versions a bidder can declare
+const (
+	Version25 = "2.5"
+	Version26 = "2.6"
+)
+
+// ErrDOOHNotSupported is returned when a DOOH request is converted to OpenRTB 2.5, which has no dooh object
+var ErrDOOHNotSupported = errors.New("dooh inventory cannot be expressed in OpenRTB 2.5")
+
+// ConvertTo25 rewrites an OpenRTB 2.6 request into OpenRTB 2.5 shapes for bidders that declare 2.5:
+//   - source.schain moves to source.ext.schain
+//   - regs.gdpr and regs.us_privacy move to regs.ext
+//   - user.consent and user.eids move to user.ext
+//   - imp.rwdd moves to imp.ext.prebid.is_rewarded_inventory
+//   - other fields 2.5 doesn't define (device.sua, video pod fields, content.network, ...) are dropped
+//
+// Every object that changes is copied first, so the request may share pointers with other bidders' requests.
+func ConvertTo25(req *BidRequest) error {
+	if req.DOOH != nil {
+		return ErrDOOHNotSupported
+	}
+	req.WLangB = nil
+	req.CatTax = 0
+
+	if err := convertSource25(req); err != nil {
+		return err
+	}
+	if err := convertRegs25(req); err != nil {
+		return err
+	}
+	if err := convertUser25(req); err != nil {
+		return err
+	}
+	if err := convertImps25(req); err != nil {
+		return err
+	}
+
+	if req.Site != nil {
+		site := *req.Site
+		site.CatTax = 0
+		site.KwArray = nil
+		site.InventoryPartnerDomain = ""
+		site.Publisher = convertPublisher25(site.Publisher)
+		site.Content = convertContent25(site.Content)
+		req.Site = &site
+	}
+	if req.App != nil {
+		app := *req.App
+		app.CatTax = 0
+		app.KwArray = nil
+		app.InventoryPartnerDomain = ""
+		app.Publisher = convertPublisher25(app.Publisher)
+		app.Content = convertContent25(app.Content)
+		req.App = &app
+	}
+	if req.Device != nil && (req.Device.SUA != nil || req.Device.LangB != "") {
+		device := *req.Device
+		device.SUA = nil
+		device.LangB = ""
+		req.Device = &device
+	}
+	return nil
+}
+
+func convertSource25(req *BidRequest) error {
+	if req.Source == nil || req.Source.SChain == nil {
+		return nil
+	}
+	source := *req.Source
+	ext, err := setExtField(source.Ext, "schain", source.SChain)
+	if err != nil {
+		return err
+	}
+	source.Ext = ext
+	source.SChain = nil
+	req.Source = &source
+	return nil
+}
+
+func convertRegs25(req *BidRequest) error {
+	if req.Regs == nil || (req.Regs.GDPR == nil && req.Regs.USPrivacy == "") {
+		return nil
+	}
+	regs := *req.Regs
+	var err error
+	if regs.GDPR != nil {
+		if regs.Ext, err = setExtField(regs.Ext, "gdpr", *regs.GDPR); err != nil {
+			return err
+		}
+		regs.GDPR = nil
+	}
+	if regs.USPrivacy != "" {
+		if regs.Ext, err = setExtField(regs.Ext, "us_privacy", regs.USPrivacy); err != nil {
+			return err
+		}
+		regs.USPrivacy = ""
+	}
+	req.Regs = &regs
+	return nil
+}
+
+func convertUser25(req *BidRequest) error {
+	if req.User == nil {
+		return nil
+	}
+	user := *req.User
+	user.KwArray = nil
+	var err error
+	if user.Consent != "" {
+		if user.Ext, err = setExtField(user.Ext, "consent", user.Consent); err != nil {
+			return err
+		}
+		user.Consent = ""
+	}
+	if len(user.EIDs) > 0 {
+		eids := make([]EID, len(user.EIDs))
+		for i, eid := range user.EIDs {
+			eid.Inserter = ""
+			eid.Matcher = ""
+			eid.MM = 0
+			eids[i] = eid
+		}
+		if user.Ext, err = setExtField(user.Ext, "eids", eids); err != nil {
+			return err
+		}
+		user.EIDs = nil
+	}
+	req.User = &user
+	return nil
+}
+
+func convertImps25(req *BidRequest) error {
+	if len(req.Imp) == 0 {
+		return nil
+	}
+	imps := make([]Imp, len(req.Imp))
+	for i, imp := range req.Imp {
+		if imp.Rwdd == 1 {
+			ext, err := setPrebidExtField(imp.Ext, "is_rewarded_inventory", 1)
+			if err != nil {
+				return err
+			}
+			imp.Ext = ext
+		}
+		imp.Rwdd = 0
+		imp.SSAI = 0
+		imp.Qty = nil
+		imp.DT = 0
+		imp.Refresh = nil
+
+		if imp.Video != nil {
+			video := *imp.Video
+			video.MaxSeq = 0
+			video.PodDur = 0
+			video.PodID = ""
+			video.PodSeq = 0
+			video.RqdDurs = nil
+			video.Plcmt = 0
+			video.SlotInPod = 0
+			video.MinCPMPerSec = 0
+			imp.Video = &video
+		}
+		if imp.Audio != nil {
+			audio := *imp.Audio
+			audio.PodDur = 0
+			audio.RqdDurs = nil
+			audio.PodID = ""
+			audio.PodSeq = 0
+			audio.SlotInPod = 0
+			audio.MinCPMPerSec = 0
+			imp.Audio = &audio
+		}
+		imps[i] = imp
+	}
+	req.Imp = imps
+	return nil
+}
+
+func convertPublisher25(pub *Publisher) *Publisher {
+	if pub == nil || pub.CatTax == 0 {
+		return pub
+	}
+	pubCopy := *pub
+	pubCopy.CatTax = 0
+	return &pubCopy
+}
+
+func convertContent25(content *Content) *Content {
+	if content == nil {
+		return nil
+	}
+	contentCopy := *content
+	contentCopy.CatTax = 0
+	contentCopy.KwArray = nil
+	contentCopy.LangB = ""
+	contentCopy.Network = nil
+	contentCopy.Channel = nil
+	if content.Producer != nil && content.Producer.CatTax != 0 {
+		producer := *content.Producer
+		producer.CatTax = 0
+		contentCopy.Producer = &producer
+	}
+	return &contentCopy
+}
+
+// ConvertTo26 moves OpenRTB 2.5 ext fields of an incoming request to their 2.6 locations, the reverse
+// of ConvertTo25, so the exchange only has to read one shape. A field already set in its 2.6
+// location wins and the ext copy is left alone; ext values of the wrong type are also left in place.
+func ConvertTo26(req *BidRequest) {
+	if req.Source != nil && req.Source.SChain == nil {
+		var schain SupplyChain
+		if ext, ok := takeExtField(req.Source.Ext, "schain", &schain); ok {
+			req.Source.SChain = &schain
+			req.Source.Ext = ext
+		}
+	}
+
+	if req.Regs != nil {
+		var gdpr int
+		if req.Regs.GDPR == nil {
+			if ext, ok := takeExtField(req.Regs.Ext, "gdpr", &gdpr); ok {
+				req.Regs.GDPR = &gdpr
+				req.Regs.Ext = ext
+			}
+		}
+		if req.Regs.USPrivacy == "" {
+			if ext, ok := takeExtField(req.Regs.Ext, "us_privacy", &req.Regs.USPrivacy); ok {
+				req.Regs.Ext = ext
+			}
+		}
+	}
+
+	if req.User != nil {
+		if req.User.Consent == "" {
+			if ext, ok := takeExtField(req.User.Ext, "consent", &req.User.Consent); ok {
+				req.User.Ext = ext
+			}
+		}
+		var eids []EID
+		if len(req.User.EIDs) == 0 {
+			if ext, ok := takeExtField(req.User.Ext, "eids", &eids); ok {
+				req.User.EIDs = eids
+				req.User.Ext = ext
+			}
+		}
+	}
+
+	for i := range req.Imp {
+		imp := &req.Imp[i]
+		if imp.Rwdd != 0 || len(imp.Ext) == 0 {
+			continue
+		}
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(imp.Ext, &fields) != nil {
+			continue
+		}
+		var rewarded int
+		prebid, ok := takeExtField(fields["prebid"], "is_rewarded_inventory", &rewarded)
+		if !ok {
+			continue
+		}
+		imp.Rwdd = rewarded
+		if len(prebid) > 0 {
+			fields["prebid"] = prebid
+		} else {
+			delete(fields, "prebid")
+		}
+		imp.Ext = marshalExtFields(fields)
+	}
+}
+
+// takeExtField decodes ext.<key> into dst and returns ext without it
+// ok is false, and dst untouched, when the key is missing or does not decode.
+func takeExtField(ext json.RawMessage, key string, dst interface{}) (json.RawMessage, bool) {
+	if len(ext) == 0 {
+		return ext, false
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(ext, &fields) != nil {
+		return ext, false
+	}
+	raw, ok := fields[key]
+	if !ok || json.Unmarshal(raw, dst) != nil {
+		return ext, false
+	}
+	delete(fields, key)
+	return marshalExtFields(fields), true
+}
+
+// RemoveExtField returns ext without ext.<key>, and whether the key was there
+// ext itself is not modified.
+func RemoveExtField(ext json.RawMessage, key string) (json.RawMessage, bool) {
+	if len(ext) == 0 {
+		return ext, false
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(ext, &fields) != nil {
+		return ext, false
+	}
+	if _, ok := fields[key]; !ok {
+		return ext, false
+	}
+	delete(fields, key)
+	return marshalExtFields(fields), true
+}
+
+// FilterExtEIDs returns ext with the OpenRTB 2.5 ext.eids reduced to what filter keeps
+// ext.eids that don't decode can't be filtered and are removed. ext itself is not modified.
+func FilterExtEIDs(ext json.RawMessage, filter func([]EID) []EID) json.RawMessage {
+	var fields map[string]json.RawMessage
+	if len(ext) == 0 || json.Unmarshal(ext, &fields) != nil {
+		return ext
+	}
+	raw, ok := fields["eids"]
+	if !ok {
+		return ext
+	}
+	var eids []EID
+	if json.Unmarshal(raw, &eids) == nil {
+		if kept := filter(eids); len(kept) > 0 {
+			if encoded, err := json.Marshal(kept); err == nil {
+				fields["eids"] = encoded
+				return marshalExtFields(fields)
+			}
+		}
+	}
+	delete(fields, "eids")
+	return marshalExtFields(fields)
+}
+
+// marshalExtFields encodes ext fields, returning nil for an empty ext
+func marshalExtFields(fields map[string]json.RawMessage) json.RawMessage {
+	if len(fields) == 0 {
+		return nil
+	}
+	ext, err := json.Marshal(fields)
+	if err != nil {
+		return nil
+	}
+	return ext
+}
+
+// setExtField returns ext with key set to value, keeping all other ext fields
+func setExtField(ext json.RawMessage, key string, value interface{}) (json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if len(ext) > 0 {
+		if err := json.Unmarshal(ext, &fields); err != nil {
+			return nil, err
+		}
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	fields[key] = raw
+	return json.Marshal(fields)
+}
+
+// setPrebidExtField returns ext with ext.prebid.<key> set to value
+func setPrebidExtField(ext json.RawMessage, key string, value interface{}) (json.RawMessage, error) {
+	var prebid json.RawMessage
+	if len(ext) > 0 {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(ext, &fields); err != nil {
+			return nil, err
+		}
+		prebid = fields["prebid"]
+	}
+	prebid, err := setExtField(prebid, key, value)
+	if err != nil {
+		return nil, err
+	}
+	return setExtField(ext, "prebid", prebid)
+}
@@ -0,0 +1,262 @@
+package openrtb
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func request26() *BidRequest {
+	gdpr := 1
+	return &BidRequest{
+		ID:     "req-26",
+		WLangB: []string{"en-US"},
+		CatTax: 6,
+		Imp: []Imp{{
+			ID:      "imp-1",
+			Rwdd:    1,
+			SSAI:    2,
+			DT:      1700000000000,
+			Refresh: &Refresh{Count: 2},
+			Video:   &Video{Mimes: []string{"video/mp4"}, Plcmt: 1, PodID: "pod-1", RqdDurs: []int{15, 30}},
+			Ext:     json.RawMessage(`{"prebid":{"bidder":{"appnexus":{"placementId":1}}},"gpid":"/1/slot"}`),
+		}},
+		Site: &Site{
+			Domain:  "example.com",
+			CatTax:  6,
+			KwArray: []string{"news"},
+			Content: &Content{Title: "Show", Network: &Network{Name: "Net"}, Channel: &Channel{Name: "Chan"}},
+		},
+		Device: &Device{UA: "ua", SUA: &UserAgent{Source: UASourceHighEntropy}, LangB: "en"},
+		User: &User{
+			ID:      "user-1",
+			Consent: "CONSENT",
+			EIDs:    []EID{{Source: "liveramp.com", Inserter: "pbs.example", MM: MatchMethodAuthenticated, UIDs: []UID{{ID: "lr-1"}}}},
+			Ext:     json.RawMessage(`{"data":{"segment":"a"}}`),
+		},
+		Source: &Source{TID: "tid", SChain: &SupplyChain{Complete: 1, Ver: "1.0", Nodes: []SupplyChainNode{{ASI: "exchange.com", SID: "1", HP: 1}}}},
+		Regs:   &Regs{GDPR: &gdpr, USPrivacy: "1YNN", GPP: "DBABMA~", GPPSID: []int{2}},
+	}
+}
+
+func TestConvertTo25(t *testing.T) {
+	req := request26()
+	if err := ConvertTo25(req); err != nil {
+		t.Fatalf("ConvertTo25 failed: %v", err)
+	}
+
+	var sourceExt struct {
+		SChain *SupplyChain `json:"schain"`
+	}
+	if req.Source.SChain != nil || json.Unmarshal(req.Source.Ext, &sourceExt) != nil || sourceExt.SChain == nil || sourceExt.SChain.Nodes[0].ASI != "exchange.com" {
+		t.Errorf("expected schain in source.ext, got %+v ext=%s", req.Source, req.Source.Ext)
+	}
+
+	if req.Regs.GDPR != nil || req.Regs.USPrivacy != "" {
+		t.Errorf("expected regs.gdpr and regs.us_privacy to be moved, got %+v", req.Regs)
+	}
+	if string(req.Regs.Ext) != `{"gdpr":1,"us_privacy":"1YNN"}` {
+		t.Errorf("unexpected regs.ext: %s", req.Regs.Ext)
+	}
+	if req.Regs.GPP != "DBABMA~" {
+		t.Error("expected regs.gpp to be kept")
+	}
+
+	var userExt struct {
+		Consent string            `json:"consent"`
+		EIDs    []EID             `json:"eids"`
+		Data    map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal(req.User.Ext, &userExt); err != nil {
+		t.Fatalf("invalid user.ext: %v", err)
+	}
+	if req.User.Consent != "" || req.User.EIDs != nil || userExt.Consent != "CONSENT" || userExt.Data["segment"] != "a" {
+		t.Errorf("expected consent and eids in user.ext, got %+v ext=%s", req.User, req.User.Ext)
+	}
+	if len(userExt.EIDs) != 1 || userExt.EIDs[0].Source != "liveramp.com" || userExt.EIDs[0].Inserter != "" || userExt.EIDs[0].MM != 0 {
+		t.Errorf("expected 2.5 eids without inserter/mm, got %+v", userExt.EIDs)
+	}
+
+	imp := req.Imp[0]
+	if imp.Rwdd != 0 || imp.SSAI != 0 || imp.DT != 0 || imp.Refresh != nil {
+		t.Errorf("expected 2.6 imp fields to be dropped, got %+v", imp)
+	}
+	if imp.Video.Plcmt != 0 || imp.Video.PodID != "" || imp.Video.RqdDurs != nil || len(imp.Video.Mimes) != 1 {
+		t.Errorf("expected 2.6 video fields to be dropped, got %+v", imp.Video)
+	}
+	var impExt struct {
+		Prebid struct {
+			IsRewarded int                        `json:"is_rewarded_inventory"`
+			Bidder     map[string]json.RawMessage `json:"bidder"`
+		} `json:"prebid"`
+		GPID string `json:"gpid"`
+	}
+	if err := json.Unmarshal(imp.Ext, &impExt); err != nil {
+		t.Fatalf("invalid imp.ext: %v", err)
+	}
+	if impExt.Prebid.IsRewarded != 1 || impExt.Prebid.Bidder["appnexus"] == nil || impExt.GPID != "/1/slot" {
+		t.Errorf("expected is_rewarded_inventory merged into imp.ext.prebid, got %s", imp.Ext)
+	}
+
+	if req.Site.CatTax != 0 || req.Site.KwArray != nil || req.Site.Content.Network != nil || req.Site.Content.Channel != nil {
+		t.Errorf("expected 2.6 site fields to be dropped, got %+v", req.Site)
+	}
+	if req.Device.SUA != nil || req.Device.LangB != "" || req.Device.UA != "ua" {
+		t.Errorf("expected device.sua to be dropped, got %+v", req.Device)
+	}
+	if req.WLangB != nil || req.CatTax != 0 {
+		t.Error("expected request-level 2.6 fields to be dropped")
+	}
+}
+
+func TestConvertTo25_DoesNotModifySharedObjects(t *testing.T) {
+	orig := request26()
+	clone := *orig
+	if err := ConvertTo25(&clone); err != nil {
+		t.Fatalf("ConvertTo25 failed: %v", err)
+	}
+
+	if orig.Source.SChain == nil || orig.Regs.GDPR == nil || orig.User.Consent == "" || len(orig.User.EIDs) != 1 {
+		t.Error("original source, regs or user was modified")
+	}
+	if orig.Imp[0].Rwdd != 1 || orig.Imp[0].Video.Plcmt != 1 || orig.Device.SUA == nil || orig.Site.Content.Network == nil {
+		t.Error("original imp, device or site was modified")
+	}
+	if orig.User.EIDs[0].MM != MatchMethodAuthenticated {
+		t.Error("original eids were modified")
+	}
+}
+
+func TestConvertTo25_Errors(t *testing.T) {
+	if err := ConvertTo25(&BidRequest{ID: "dooh", DOOH: &DOOH{ID: "screen"}}); !errors.Is(err, ErrDOOHNotSupported) {
+		t.Errorf("expected ErrDOOHNotSupported, got %v", err)
+	}
+
+	req := &BidRequest{ID: "bad", User: &User{Consent: "c", Ext: json.RawMessage(`[1,2]`)}}
+	if err := ConvertTo25(req); err == nil {
+		t.Error("expected error for non-object user.ext")
+	}
+}
+
+func TestConvertTo25_Minimal(t *testing.T) {
+	req := &BidRequest{ID: "min", Imp: []Imp{{ID: "1", Banner: &Banner{W: 300, H: 250}}}, Site: &Site{Page: "https://example.com"}}
+	if err := ConvertTo25(req); err != nil {
+		t.Fatalf("ConvertTo25 failed: %v", err)
+	}
+	if req.Source != nil || req.Regs != nil || req.User != nil || req.Imp[0].Ext != nil {
+		t.Errorf("expected absent objects to stay absent, got %+v", req)
+	}
+}
+
+func TestConvertTo26(t *testing.T) {
+	req := &BidRequest{
+		ID:     "req-1",
+		Imp:    []Imp{{ID: "1", Ext: json.RawMessage(`{"prebid":{"is_rewarded_inventory":1},"bidder":{"x":1}}`)}},
+		Source: &Source{Ext: json.RawMessage(`{"schain":{"ver":"1.0","complete":1,"nodes":[{"asi":"upstream.com","sid":"1","hp":1}]}}`)},
+		Regs:   &Regs{Ext: json.RawMessage(`{"gdpr":1,"us_privacy":"1YNN","dsa":{"dsarequired":1}}`)},
+		User:   &User{Ext: json.RawMessage(`{"consent":"CONSENT","eids":[{"source":"id5-sync.com","uids":[{"id":"abc"}]}]}`)},
+	}
+
+	ConvertTo26(req)
+
+	if req.Source.SChain == nil || len(req.Source.SChain.Nodes) != 1 || req.Source.Ext != nil {
+		t.Errorf("expected schain moved to source.schain, got %+v ext %s", req.Source.SChain, req.Source.Ext)
+	}
+	if req.Regs.GDPR == nil || *req.Regs.GDPR != 1 || req.Regs.USPrivacy != "1YNN" {
+		t.Errorf("expected regs.gdpr and regs.us_privacy set, got %+v", req.Regs)
+	}
+	if string(req.Regs.Ext) != `{"dsa":{"dsarequired":1}}` {
+		t.Errorf("expected other regs.ext fields kept, got %s", req.Regs.Ext)
+	}
+	if req.User.Consent != "CONSENT" || len(req.User.EIDs) != 1 || req.User.EIDs[0].Source != "id5-sync.com" || req.User.Ext != nil {
+		t.Errorf("expected consent and eids moved to user, got %+v", req.User)
+	}
+	if req.Imp[0].Rwdd != 1 || string(req.Imp[0].Ext) != `{"bidder":{"x":1}}` {
+		t.Errorf("expected imp.rwdd set from ext.prebid, got rwdd %d ext %s", req.Imp[0].Rwdd, req.Imp[0].Ext)
+	}
+}
+
+func TestConvertTo26_KeepsExisting(t *testing.T) {
+	gdpr := 0
+	req := &BidRequest{
+		ID:   "req-1",
+		Regs: &Regs{GDPR: &gdpr, Ext: json.RawMessage(`{"gdpr":1}`)},
+		User: &User{
+			EIDs: []EID{{Source: "pubcid.org"}},
+			Ext:  json.RawMessage(`{"eids":[{"source":"id5-sync.com"}],"consent":42}`),
+		},
+	}
+
+	ConvertTo26(req)
+
+	if *req.Regs.GDPR != 0 || string(req.Regs.Ext) != `{"gdpr":1}` {
+		t.Errorf("expected the 2.6 regs.gdpr to win, got %d ext %s", *req.Regs.GDPR, req.Regs.Ext)
+	}
+	if len(req.User.EIDs) != 1 || req.User.EIDs[0].Source != "pubcid.org" {
+		t.Errorf("expected the 2.6 user.eids to win, got %+v", req.User.EIDs)
+	}
+	if req.User.Consent != "" {
+		t.Errorf("expected a non-string ext.consent left alone, got %q", req.User.Consent)
+	}
+}
+
+func TestRemoveExtField(t *testing.T) {
+	ext := json.RawMessage(`{"eids":[{"source":"a.com"}],"data":{"k":"v"}}`)
+
+	out, ok := RemoveExtField(ext, "eids")
+	if !ok || string(out) != `{"data":{"k":"v"}}` {
+		t.Errorf("Expected eids removed, got %s (%v)", out, ok)
+	}
+	if !strings.Contains(string(ext), "eids") {
+		t.Error("Expected the input ext untouched")
+	}
+
+	if out, ok := RemoveExtField(json.RawMessage(`{"eids":[]}`), "eids"); !ok || out != nil {
+		t.Errorf("Expected nil ext once empty, got %s", out)
+	}
+	for _, in := range []string{``, `{"data":{}}`, `not json`} {
+		if out, ok := RemoveExtField(json.RawMessage(in), "eids"); ok || string(out) != in {
+			t.Errorf("RemoveExtField(%q) = %s, %v; want the input back", in, out, ok)
+		}
+	}
+}
+
+func TestFilterExtEIDs(t *testing.T) {
+	keepA := func(eids []EID) []EID {
+		var kept []EID
+		for _, eid := range eids {
+			if eid.Source == "a.com" {
+				kept = append(kept, eid)
+			}
+		}
+		return kept
+	}
+
+	ext := json.RawMessage(`{"eids":[{"source":"a.com","uids":[{"id":"1"}]},{"source":"b.com","uids":[{"id":"2"}]}],"data":{"k":"v"}}`)
+	out := FilterExtEIDs(ext, keepA)
+	if strings.Contains(string(out), "b.com") || !strings.Contains(string(out), "a.com") || !strings.Contains(string(out), `"data":{"k":"v"}`) {
+		t.Errorf("Expected only a.com kept, got %s", out)
+	}
+	if !strings.Contains(string(ext), "b.com") {
+		t.Error("Expected the input ext untouched")
+	}
+
+	tests := []struct {
+		name string
+		ext  string
+		want string
+	}{
+		{"nothing kept", `{"eids":[{"source":"b.com"}],"data":{}}`, `{"data":{}}`},
+		{"undecodable eids", `{"eids":"a.com"}`, ``},
+		{"no eids", `{"data":{}}`, `{"data":{}}`},
+		{"empty", ``, ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FilterExtEIDs(json.RawMessage(tt.ext), keepA); string(got) != tt.want {
+				t.Errorf("FilterExtEIDs(%s) = %s, want %s", tt.ext, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 // Package openrtb provides OpenRTB 2.5/2.6 data models
 package openrtb
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 // BidRequest represents an OpenRTB 2.5/2.6 bid request
 type BidRequest struct {
@@ -9,6 +12,7 @@ type BidRequest struct {
 	Imp    []Imp           `json:"imp"`
 	Site   *Site           `json:"site,omitempty"`
 	App    *App            `json:"app,omitempty"`
+	DOOH   *DOOH           `json:"dooh,omitempty"` // OpenRTB 2.6: digital out-of-home inventory
 	Device *Device         `json:"device,omitempty"`
 	User   *User           `json:"user,omitempty"`
 	Test   int             `json:"test,omitempty"`
@@ -17,11 +21,13 @@ type BidRequest struct {
 	WSeat  []string        `json:"wseat,omitempty"` // Allowed buyer seats
 	BSeat  []string        `json:"bseat,omitempty"` // Blocked buyer seats
 	AllImp int             `json:"allimps,omitempty"`
-	Cur    []string        `json:"cur,omitempty"`   // Allowed currencies
-	WLang  []string        `json:"wlang,omitempty"` // Allowed languages
-	BCat   []string        `json:"bcat,omitempty"`  // Blocked categories
-	BAdv   []string        `json:"badv,omitempty"`  // Blocked advertisers
-	BApp   []string        `json:"bapp,omitempty"`  // Blocked apps
+	Cur    []string        `json:"cur,omitempty"`    // Allowed currencies
+	WLang  []string        `json:"wlang,omitempty"`  // Allowed languages
+	WLangB []string        `json:"wlangb,omitempty"` // Allowed languages (BCP-47), OpenRTB 2.6
+	BCat   []string        `json:"bcat,omitempty"`   // Blocked categories
+	CatTax int             `json:"cattax,omitempty"` // Taxonomy of bcat, OpenRTB 2.6
+	BAdv   []string        `json:"badv,omitempty"`   // Blocked advertisers
+	BApp   []string        `json:"bapp,omitempty"`   // Blocked apps
 	Source *Source         `json:"source,omitempty"`
 	Regs   *Regs           `json:"regs,omitempty"`
 	Ext    json.RawMessage `json:"ext,omitempty"`
@@ -46,9 +52,36 @@ type Imp struct {
 	Secure            *int            `json:"secure,omitempty"`
 	IframeBuster      []string        `json:"iframebuster,omitempty"`
 	Exp               int             `json:"exp,omitempty"`
+	Rwdd              int             `json:"rwdd,omitempty"`    // Rewarded inventory, OpenRTB 2.6
+	SSAI              int             `json:"ssai,omitempty"`    // Server-side ad insertion, OpenRTB 2.6
+	Qty               *Qty            `json:"qty,omitempty"`     // DOOH impression multiplier, OpenRTB 2.6
+	DT                float64         `json:"dt,omitempty"`      // Timestamp the impression will be fulfilled (ms), OpenRTB 2.6
+	Refresh           *Refresh        `json:"refresh,omitempty"` // Ad slot refresh, OpenRTB 2.6
 	Ext               json.RawMessage `json:"ext,omitempty"`
 }
 
+// Qty represents the impression multiplier of a DOOH impression (OpenRTB 2.6)
+type Qty struct {
+	Multiplier float64         `json:"multiplier"`
+	SourceType int             `json:"sourcetype,omitempty"` // 1=measurement vendor, 2=publisher, 3=exchange
+	Vendor     string          `json:"vendor,omitempty"`
+	Ext        json.RawMessage `json:"ext,omitempty"`
+}
+
+// Refresh describes how often an ad slot is refreshed (OpenRTB 2.6)
+type Refresh struct {
+	RefSettings []RefSettings   `json:"refsettings,omitempty"`
+	Count       int             `json:"count,omitempty"` // Refreshes since the last page load
+	Ext         json.RawMessage `json:"ext,omitempty"`
+}
+
+// RefSettings describes one refresh trigger (OpenRTB 2.6)
+type RefSettings struct {
+	RefType int             `json:"reftype,omitempty"` // 0=unknown, 1=user action, 2=event, 3=time
+	MinInt  int             `json:"minint,omitempty"`  // Minimum refresh interval in seconds
+	Ext     json.RawMessage `json:"ext,omitempty"`
+}
+
 // Banner represents a banner impression
 type Banner struct {
 	Format   []Format        `json:"format,omitempty"`
@@ -85,12 +118,20 @@ type Video struct {
 	Mimes          []string        `json:"mimes,omitempty"`
 	MinDuration    int             `json:"minduration,omitempty"`
 	MaxDuration    int             `json:"maxduration,omitempty"`
+	StartDelay     *int            `json:"startdelay,omitempty"`
+	MaxSeq         int             `json:"maxseq,omitempty"` // OpenRTB 2.6: max ads in a dynamic pod
+	PodDur         int             `json:"poddur,omitempty"` // OpenRTB 2.6: total dynamic pod duration (seconds)
 	Protocols      []int           `json:"protocols,omitempty"`
 	Protocol       int             `json:"protocol,omitempty"` // Deprecated
 	W              int             `json:"w,omitempty"`
 	H              int             `json:"h,omitempty"`
-	StartDelay     *int            `json:"startdelay,omitempty"`
-	Placement      int             `json:"placement,omitempty"`
+	PodID          string          `json:"podid,omitempty"`        // OpenRTB 2.6: pod this impression belongs to
+	PodSeq         int             `json:"podseq,omitempty"`       // OpenRTB 2.6: pod position in the content stream
+	RqdDurs        []int           `json:"rqddurs,omitempty"`      // OpenRTB 2.6: exact acceptable durations
+	Placement      int             `json:"placement,omitempty"`    // Deprecated in OpenRTB 2.6 in favor of plcmt
+	Plcmt          int             `json:"plcmt,omitempty"`        // OpenRTB 2.6: 1=instream, 2=accompanying, 3=interstitial, 4=standalone
+	SlotInPod      int             `json:"slotinpod,omitempty"`    // OpenRTB 2.6: seller-guaranteed slot position
+	MinCPMPerSec   float64         `json:"mincpmpersec,omitempty"` // OpenRTB 2.6: minimum CPM per second
 	Linearity      int             `json:"linearity,omitempty"`
 	Skip           *int            `json:"skip,omitempty"`
 	SkipMin        int             `json:"skipmin,omitempty"`
@@ -116,8 +157,14 @@ type Audio struct {
 	Mimes         []string        `json:"mimes,omitempty"`
 	MinDuration   int             `json:"minduration,omitempty"`
 	MaxDuration   int             `json:"maxduration,omitempty"`
+	PodDur        int             `json:"poddur,omitempty"` // OpenRTB 2.6
 	Protocols     []int           `json:"protocols,omitempty"`
 	StartDelay    *int            `json:"startdelay,omitempty"`
+	RqdDurs       []int           `json:"rqddurs,omitempty"`      // OpenRTB 2.6
+	PodID         string          `json:"podid,omitempty"`        // OpenRTB 2.6
+	PodSeq        int             `json:"podseq,omitempty"`       // OpenRTB 2.6
+	SlotInPod     int             `json:"slotinpod,omitempty"`    // OpenRTB 2.6
+	MinCPMPerSec  float64         `json:"mincpmpersec,omitempty"` // OpenRTB 2.6
 	Sequence      int             `json:"sequence,omitempty"`
 	BAttr         []int           `json:"battr,omitempty"`
 	MaxExtended   int             `json:"maxextended,omitempty"`
@@ -171,40 +218,60 @@ type Deal struct {
 
 // Site represents a website
 type Site struct {
-	ID            string          `json:"id,omitempty"`
-	Name          string          `json:"name,omitempty"`
-	Domain        string          `json:"domain,omitempty"`
-	Cat           []string        `json:"cat,omitempty"`
-	SectionCat    []string        `json:"sectioncat,omitempty"`
-	PageCat       []string        `json:"pagecat,omitempty"`
-	Page          string          `json:"page,omitempty"`
-	Ref           string          `json:"ref,omitempty"`
-	Search        string          `json:"search,omitempty"`
-	Mobile        int             `json:"mobile,omitempty"`
-	PrivacyPolicy int             `json:"privacypolicy,omitempty"`
-	Publisher     *Publisher      `json:"publisher,omitempty"`
-	Content       *Content        `json:"content,omitempty"`
-	Keywords      string          `json:"keywords,omitempty"`
-	Ext           json.RawMessage `json:"ext,omitempty"`
+	ID                     string          `json:"id,omitempty"`
+	Name                   string          `json:"name,omitempty"`
+	Domain                 string          `json:"domain,omitempty"`
+	Cat                    []string        `json:"cat,omitempty"`
+	CatTax                 int             `json:"cattax,omitempty"` // OpenRTB 2.6
+	SectionCat             []string        `json:"sectioncat,omitempty"`
+	PageCat                []string        `json:"pagecat,omitempty"`
+	Page                   string          `json:"page,omitempty"`
+	Ref                    string          `json:"ref,omitempty"`
+	Search                 string          `json:"search,omitempty"`
+	Mobile                 int             `json:"mobile,omitempty"`
+	PrivacyPolicy          int             `json:"privacypolicy,omitempty"`
+	Publisher              *Publisher      `json:"publisher,omitempty"`
+	Content                *Content        `json:"content,omitempty"`
+	Keywords               string          `json:"keywords,omitempty"`
+	KwArray                []string        `json:"kwarray,omitempty"`                // OpenRTB 2.6
+	InventoryPartnerDomain string          `json:"inventorypartnerdomain,omitempty"` // OpenRTB 2.6
+	Ext                    json.RawMessage `json:"ext,omitempty"`
 }
 
 // App represents a mobile application
 type App struct {
-	ID            string          `json:"id,omitempty"`
-	Name          string          `json:"name,omitempty"`
-	Bundle        string          `json:"bundle,omitempty"`
-	Domain        string          `json:"domain,omitempty"`
-	StoreURL      string          `json:"storeurl,omitempty"`
-	Cat           []string        `json:"cat,omitempty"`
-	SectionCat    []string        `json:"sectioncat,omitempty"`
-	PageCat       []string        `json:"pagecat,omitempty"`
-	Ver           string          `json:"ver,omitempty"`
-	PrivacyPolicy int             `json:"privacypolicy,omitempty"`
-	Paid          int             `json:"paid,omitempty"`
-	Publisher     *Publisher      `json:"publisher,omitempty"`
-	Content       *Content        `json:"content,omitempty"`
-	Keywords      string          `json:"keywords,omitempty"`
-	Ext           json.RawMessage `json:"ext,omitempty"`
+	ID                     string          `json:"id,omitempty"`
+	Name                   string          `json:"name,omitempty"`
+	Bundle                 string          `json:"bundle,omitempty"`
+	Domain                 string          `json:"domain,omitempty"`
+	StoreURL               string          `json:"storeurl,omitempty"`
+	Cat                    []string        `json:"cat,omitempty"`
+	CatTax                 int             `json:"cattax,omitempty"` // OpenRTB 2.6
+	SectionCat             []string        `json:"sectioncat,omitempty"`
+	PageCat                []string        `json:"pagecat,omitempty"`
+	Ver                    string          `json:"ver,omitempty"`
+	PrivacyPolicy          int             `json:"privacypolicy,omitempty"`
+	Paid                   int             `json:"paid,omitempty"`
+	Publisher              *Publisher      `json:"publisher,omitempty"`
+	Content                *Content        `json:"content,omitempty"`
+	Keywords               string          `json:"keywords,omitempty"`
+	KwArray                []string        `json:"kwarray,omitempty"`                // OpenRTB 2.6
+	InventoryPartnerDomain string          `json:"inventorypartnerdomain,omitempty"` // OpenRTB 2.6
+	Ext                    json.RawMessage `json:"ext,omitempty"`
+}
+
+// DOOH represents digital out-of-home inventory (OpenRTB 2.6)
+// A request carries exactly one of site, app or dooh.
+type DOOH struct {
+	ID           string          `json:"id,omitempty"`
+	Name         string          `json:"name,omitempty"`
+	VenueType    []string        `json:"venuetype,omitempty"`
+	VenueTypeTax int             `json:"venuetypetax,omitempty"`
+	Publisher    *Publisher      `json:"publisher,omitempty"`
+	Domain       string          `json:"domain,omitempty"`
+	Keywords     string          `json:"keywords,omitempty"`
+	Content      *Content        `json:"content,omitempty"`
+	Ext          json.RawMessage `json:"ext,omitempty"`
 }
 
 // Publisher represents a publisher
@@ -212,6 +279,7 @@ type Publisher struct {
 	ID     string          `json:"id,omitempty"`
 	Name   string          `json:"name,omitempty"`
 	Cat    []string        `json:"cat,omitempty"`
+	CatTax int             `json:"cattax,omitempty"` // OpenRTB 2.6
 	Domain string          `json:"domain,omitempty"`
 	Ext    json.RawMessage `json:"ext,omitempty"`
 }
@@ -230,6 +298,7 @@ type Content struct {
 	Producer           *Producer       `json:"producer,omitempty"`
 	URL                string          `json:"url,omitempty"`
 	Cat                []string        `json:"cat,omitempty"`
+	CatTax             int             `json:"cattax,omitempty"` // OpenRTB 2.6
 	ProdQ              int             `json:"prodq,omitempty"`
 	VideoQuality       int             `json:"videoquality,omitempty"` // Deprecated
 	Context            int             `json:"context,omitempty"`
@@ -237,20 +306,41 @@ type Content struct {
 	UserRating         string          `json:"userrating,omitempty"`
 	QAGMediaRating     int             `json:"qagmediarating,omitempty"`
 	Keywords           string          `json:"keywords,omitempty"`
+	KwArray            []string        `json:"kwarray,omitempty"` // OpenRTB 2.6
 	LiveStream         int             `json:"livestream,omitempty"`
 	SourceRelationship int             `json:"sourcerelationship,omitempty"`
 	Len                int             `json:"len,omitempty"`
 	Language           string          `json:"language,omitempty"`
+	LangB              string          `json:"langb,omitempty"` // OpenRTB 2.6: BCP-47 language
 	Embeddable         int             `json:"embeddable,omitempty"`
 	Data               []Data          `json:"data,omitempty"`
+	Network            *Network        `json:"network,omitempty"` // OpenRTB 2.6
+	Channel            *Channel        `json:"channel,omitempty"` // OpenRTB 2.6
 	Ext                json.RawMessage `json:"ext,omitempty"`
 }
 
+// Network represents the network an ad is shown on, e.g. a TV network (OpenRTB 2.6)
+type Network struct {
+	ID     string          `json:"id,omitempty"`
+	Name   string          `json:"name,omitempty"`
+	Domain string          `json:"domain,omitempty"`
+	Ext    json.RawMessage `json:"ext,omitempty"`
+}
+
+// Channel represents the channel an ad is shown on, e.g. a local TV station (OpenRTB 2.6)
+type Channel struct {
+	ID     string          `json:"id,omitempty"`
+	Name   string          `json:"name,omitempty"`
+	Domain string          `json:"domain,omitempty"`
+	Ext    json.RawMessage `json:"ext,omitempty"`
+}
+
 // Producer represents a content producer
 type Producer struct {
 	ID     string          `json:"id,omitempty"`
 	Name   string          `json:"name,omitempty"`
 	Cat    []string        `json:"cat,omitempty"`
+	CatTax int             `json:"cattax,omitempty"` // OpenRTB 2.6
 	Domain string          `json:"domain,omitempty"`
 	Ext    json.RawMessage `json:"ext,omitempty"`
 }
@@ -258,6 +348,7 @@ type Producer struct {
 // Device represents a user device
 type Device struct {
 	UA             string          `json:"ua,omitempty"`
+	SUA            *UserAgent      `json:"sua,omitempty"`
 	Geo            *Geo            `json:"geo,omitempty"`
 	DNT            *int            `json:"dnt,omitempty"`
 	Lmt            *int            `json:"lmt,omitempty"`
@@ -277,6 +368,7 @@ type Device struct {
 	GeoFetch       int             `json:"geofetch,omitempty"`
 	FlashVer       string          `json:"flashver,omitempty"`
 	Language       string          `json:"language,omitempty"`
+	LangB          string          `json:"langb,omitempty"` // OpenRTB 2.6: BCP-47 language
 	Carrier        string          `json:"carrier,omitempty"`
 	MCCMNC         string          `json:"mccmnc,omitempty"`
 	ConnectionType int             `json:"connectiontype,omitempty"`
@@ -290,6 +382,36 @@ type Device struct {
 	Ext            json.RawMessage `json:"ext,omitempty"`
 }
 
+// UserAgent represents structured user agent information (OpenRTB 2.6 device.sua)
+// Usually built from User-Agent Client Hints
+type UserAgent struct {
+	Browsers     []BrandVersion  `json:"browsers,omitempty"`
+	Platform     *BrandVersion   `json:"platform,omitempty"`
+	Mobile       *int            `json:"mobile,omitempty"`
+	Architecture string          `json:"architecture,omitempty"`
+	Bitness      string          `json:"bitness,omitempty"`
+	Model        string          `json:"model,omitempty"`
+	Source       UASource        `json:"source,omitempty"`
+	Ext          json.RawMessage `json:"ext,omitempty"`
+}
+
+// BrandVersion identifies a browser or platform and its version components
+type BrandVersion struct {
+	Brand   string          `json:"brand"`
+	Version []string        `json:"version,omitempty"`
+	Ext     json.RawMessage `json:"ext,omitempty"`
+}
+
+// UASource identifies where structured user agent data came from (AdCOM User-Agent Source)
+type UASource int
+
+const (
+	UASourceUnknown     UASource = 0 // Unspecified or unknown
+	UASourceLowEntropy  UASource = 1 // User-Agent Client Hints (low-entropy headers only)
+	UASourceHighEntropy UASource = 2 // User-Agent Client Hints (with high-entropy headers)
+	UASourceUAString    UASource = 3 // Parsed from the User-Agent string
+)
+
 // Geo represents geographic location
 type Geo struct {
 	Lat           float64         `json:"lat,omitempty"`
@@ -315,6 +437,7 @@ type User struct {
 	YOB        int             `json:"yob,omitempty"`
 	Gender     string          `json:"gender,omitempty"`
 	Keywords   string          `json:"keywords,omitempty"`
+	KwArray    []string        `json:"kwarray,omitempty"` // OpenRTB 2.6
 	CustomData string          `json:"customdata,omitempty"`
 	Geo        *Geo            `json:"geo,omitempty"`
 	Data       []Data          `json:"data,omitempty"`
@@ -341,11 +464,24 @@ type Segment struct {
 
 // EID represents extended identifier
 type EID struct {
-	Source string          `json:"source,omitempty"`
-	UIDs   []UID           `json:"uids,omitempty"`
-	Ext    json.RawMessage `json:"ext,omitempty"`
+	Inserter string          `json:"inserter,omitempty"` // OpenRTB 2.6: domain of the entity that added the ID
+	Source   string          `json:"source,omitempty"`
+	Matcher  string          `json:"matcher,omitempty"` // OpenRTB 2.6: domain of the entity that matched the ID
+	MM       int             `json:"mm,omitempty"`      // OpenRTB 2.6: match method, see MatchMethod constants
+	UIDs     []UID           `json:"uids,omitempty"`
+	Ext      json.RawMessage `json:"ext,omitempty"`
 }
 
+// EID match methods (AdCOM ID Match Method, OpenRTB 2.6 eid.mm)
+const (
+	MatchMethodUnknown       = 0
+	MatchMethodNoMatch       = 1 // Inserted by the ID owner
+	MatchMethodCookieSync    = 2
+	MatchMethodAuthenticated = 3 // Login or other authentication
+	MatchMethodObserved      = 4 // Observed (e.g. shared IP)
+	MatchMethodInferred      = 5 // Probabilistic
+)
+
 // UID represents a user ID
 type UID struct {
 	ID    string          `json:"id,omitempty"`
@@ -354,6 +490,7 @@ type UID struct {
 }
 
 // Source represents request source
+// OpenRTB 2.6 moved schain here from source.ext.schain.
 type Source struct {
 	FD     int             `json:"fd,omitempty"`
 	TID    string          `json:"tid,omitempty"`
@@ -390,3 +527,71 @@ type Regs struct {
 	GPPSID    []int           `json:"gpp_sid,omitempty"`
 	Ext       json.RawMessage `json:"ext,omitempty"`
 }
+
+// ExtRegs holds the regs.ext fields the exchange reads
+type ExtRegs struct {
+	DSA *ExtRegsDSA `json:"dsa,omitempty"`
+}
+
+// ExtRegsDSA holds EU Digital Services Act transparency requirements (regs.ext.dsa)
+type ExtRegsDSA struct {
+	Required     int               `json:"dsarequired"` // 0=not required, 1=supported, 2=required, 3=required and publisher is an online platform
+	PubRender    int               `json:"pubrender"`   // 0=publisher can't render, 1=might render, 2=will render
+	DataToPub    int               `json:"datatopub"`   // 0=don't send, 1=optional, 2=send transparency data
+	Transparency []DSATransparency `json:"transparency,omitempty"`
+}
+
+// DSATransparency identifies an entity that applied user parameters to an ad
+type DSATransparency struct {
+	Domain string `json:"domain"`
+	Params []int  `json:"dsaparams,omitempty"` // 1=profiling, 2=basic advertising, 3=precise geolocation
+}
+
+// Validate checks the DSA enums and transparency entries
+func (d *ExtRegsDSA) Validate() error {
+	switch {
+	case d.Required < 0 || d.Required > 3:
+		return fmt.Errorf("dsa.dsarequired must be 0-3, got %d", d.Required)
+	case d.PubRender < 0 || d.PubRender > 2:
+		return fmt.Errorf("dsa.pubrender must be 0-2, got %d", d.PubRender)
+	case d.DataToPub < 0 || d.DataToPub > 2:
+		return fmt.Errorf("dsa.datatopub must be 0-2, got %d", d.DataToPub)
+	}
+	for i, t := range d.Transparency {
+		if t.Domain == "" {
+			return fmt.Errorf("dsa.transparency[%d].domain is required", i)
+		}
+		for _, param := range t.Params {
+			if param < 1 || param > 3 {
+				return fmt.Errorf("dsa.transparency[%d].dsaparams must be 1-3, got %d", i, param)
+			}
+		}
+	}
+	return nil
+}
+
+// RegsDSA returns the regs.ext.dsa object of a request, or nil if absent
+func RegsDSA(regs *Regs) (*ExtRegsDSA, error) {
+	if regs == nil || len(regs.Ext) == 0 {
+		return nil, nil
+	}
+	var ext ExtRegs
+	if err := json.Unmarshal(regs.Ext, &ext); err != nil {
+		return nil, err
+	}
+	return ext.DSA, nil
+}
+
+// SetRegsDSA returns a copy of regs with regs.ext.dsa set, keeping the other ext fields
+func SetRegsDSA(regs *Regs, dsa *ExtRegsDSA) (*Regs, error) {
+	var regsCopy Regs
+	if regs != nil {
+		regsCopy = *regs
+	}
+	ext, err := setExtField(regsCopy.Ext, "dsa", dsa)
+	if err != nil {
+		return nil, err
+	}
+	regsCopy.Ext = ext
+	return &regsCopy, nil
+}
@@ -2,6 +2,7 @@ package openrtb
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 )
 
@@ -358,6 +359,53 @@ func TestDevice_AllFields(t *testing.T) {
 	}
 }
 
+func TestDevice_StructuredUserAgent(t *testing.T) {
+	input := `{
+		"sua": {
+			"browsers": [{"brand": "Chromium", "version": ["119", "0", "6045", "199"]}],
+			"platform": {"brand": "macOS", "version": ["14", "1"]},
+			"mobile": 0,
+			"architecture": "arm",
+			"bitness": "64",
+			"source": 2
+		}
+	}`
+
+	var device Device
+	if err := json.Unmarshal([]byte(input), &device); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	sua := device.SUA
+	if sua == nil {
+		t.Fatal("expected sua")
+	}
+	if len(sua.Browsers) != 1 || sua.Browsers[0].Brand != "Chromium" || len(sua.Browsers[0].Version) != 4 {
+		t.Errorf("browsers mismatch: %+v", sua.Browsers)
+	}
+	if sua.Platform == nil || sua.Platform.Brand != "macOS" {
+		t.Errorf("platform mismatch: %+v", sua.Platform)
+	}
+	if sua.Mobile == nil || *sua.Mobile != 0 {
+		t.Error("expected mobile=0")
+	}
+	if sua.Source != UASourceHighEntropy {
+		t.Errorf("expected source=2, got %d", sua.Source)
+	}
+
+	data, err := json.Marshal(device)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var roundTrip Device
+	if err := json.Unmarshal(data, &roundTrip); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if roundTrip.SUA == nil || roundTrip.SUA.Architecture != "arm" || roundTrip.SUA.Bitness != "64" {
+		t.Errorf("round trip mismatch: %+v", roundTrip.SUA)
+	}
+}
+
 func TestGeo_Coordinates(t *testing.T) {
 	geo := Geo{
 		Lat:       34.0522,
@@ -761,3 +809,121 @@ func BenchmarkBidRequest_Marshal(b *testing.B) {
 		json.Marshal(req)
 	}
 }
+
+func TestBidRequest_OpenRTB26Fields(t *testing.T) {
+	jsonStr := `{
+		"id": "req-26",
+		"wlangb": ["en-US"],
+		"cattax": 6,
+		"imp": [{
+			"id": "1",
+			"rwdd": 1,
+			"ssai": 2,
+			"qty": {"multiplier": 1.5, "sourcetype": 1, "vendor": "measure.example"},
+			"refresh": {"refsettings": [{"reftype": 3, "minint": 30}], "count": 2},
+			"video": {"mimes": ["video/mp4"], "plcmt": 1, "poddur": 60, "podid": "pod-1", "podseq": 1, "rqddurs": [15, 30], "slotinpod": -1, "mincpmpersec": 0.5}
+		}],
+		"dooh": {"id": "screen-1", "venuetype": ["airport"], "venuetypetax": 1},
+		"user": {"eids": [{"source": "liveramp.com", "inserter": "pbs.example", "matcher": "lr.example", "mm": 3, "uids": [{"id": "lr-1"}]}]},
+		"regs": {"ext": {"dsa": {"dsarequired": 2, "pubrender": 1, "datatopub": 2, "transparency": [{"domain": "dsa.example", "dsaparams": [1, 2]}]}}}
+	}`
+
+	var req BidRequest
+	if err := json.Unmarshal([]byte(jsonStr), &req); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	imp := req.Imp[0]
+	if imp.Rwdd != 1 || imp.SSAI != 2 || imp.Qty.Multiplier != 1.5 || imp.Refresh.RefSettings[0].MinInt != 30 {
+		t.Errorf("imp 2.6 fields mismatch: %+v", imp)
+	}
+	if imp.Video.Plcmt != 1 || imp.Video.PodID != "pod-1" || len(imp.Video.RqdDurs) != 2 || imp.Video.SlotInPod != -1 {
+		t.Errorf("video pod fields mismatch: %+v", imp.Video)
+	}
+	if req.DOOH == nil || req.DOOH.VenueType[0] != "airport" || req.CatTax != 6 {
+		t.Errorf("dooh mismatch: %+v", req.DOOH)
+	}
+	if eid := req.User.EIDs[0]; eid.Inserter != "pbs.example" || eid.MM != MatchMethodAuthenticated {
+		t.Errorf("eid 2.6 fields mismatch: %+v", eid)
+	}
+
+	dsa, err := RegsDSA(req.Regs)
+	if err != nil {
+		t.Fatalf("RegsDSA failed: %v", err)
+	}
+	if dsa == nil || dsa.Required != 2 || dsa.PubRender != 1 || dsa.Transparency[0].Domain != "dsa.example" || len(dsa.Transparency[0].Params) != 2 {
+		t.Errorf("dsa mismatch: %+v", dsa)
+	}
+
+	data, err := json.Marshal(&req)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var roundTrip BidRequest
+	if err := json.Unmarshal(data, &roundTrip); err != nil {
+		t.Fatalf("failed to unmarshal round trip: %v", err)
+	}
+	if roundTrip.Imp[0].Video.MinCPMPerSec != 0.5 || roundTrip.DOOH.ID != "screen-1" || roundTrip.WLangB[0] != "en-US" {
+		t.Error("2.6 fields lost in round trip")
+	}
+}
+
+func TestRegsDSA(t *testing.T) {
+	if dsa, err := RegsDSA(nil); dsa != nil || err != nil {
+		t.Errorf("expected nil for nil regs, got %+v %v", dsa, err)
+	}
+	if dsa, err := RegsDSA(&Regs{Ext: json.RawMessage(`{"gdpr":1}`)}); dsa != nil || err != nil {
+		t.Errorf("expected nil without dsa, got %+v %v", dsa, err)
+	}
+	if _, err := RegsDSA(&Regs{Ext: json.RawMessage(`{"dsa":`)}); err == nil {
+		t.Error("expected error for malformed regs.ext")
+	}
+}
+
+func TestExtRegsDSA_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		dsa     ExtRegsDSA
+		wantErr string // empty means valid
+	}{
+		{name: "valid", dsa: ExtRegsDSA{Required: 3, PubRender: 2, DataToPub: 2, Transparency: []DSATransparency{{Domain: "dsp.example", Params: []int{1, 3}}}}},
+		{name: "zero values", dsa: ExtRegsDSA{}},
+		{name: "dsarequired", dsa: ExtRegsDSA{Required: 4}, wantErr: "dsarequired"},
+		{name: "pubrender", dsa: ExtRegsDSA{PubRender: -1}, wantErr: "pubrender"},
+		{name: "datatopub", dsa: ExtRegsDSA{DataToPub: 3}, wantErr: "datatopub"},
+		{name: "transparency domain", dsa: ExtRegsDSA{Transparency: []DSATransparency{{Params: []int{1}}}}, wantErr: "domain"},
+		{name: "dsaparams", dsa: ExtRegsDSA{Transparency: []DSATransparency{{Domain: "d", Params: []int{4}}}}, wantErr: "dsaparams"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.dsa.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSetRegsDSA(t *testing.T) {
+	orig := &Regs{COPPA: 1, Ext: json.RawMessage(`{"gpc":"1"}`)}
+	regs, err := SetRegsDSA(orig, &ExtRegsDSA{Required: 2, PubRender: 1})
+	if err != nil {
+		t.Fatalf("SetRegsDSA failed: %v", err)
+	}
+	if dsa, _ := RegsDSA(regs); dsa == nil || dsa.Required != 2 {
+		t.Errorf("expected dsa to be set, got %s", regs.Ext)
+	}
+	if regs.COPPA != 1 || !strings.Contains(string(regs.Ext), `"gpc":"1"`) {
+		t.Errorf("expected other regs fields to be kept, got %+v", regs)
+	}
+	if string(orig.Ext) != `{"gpc":"1"}` {
+		t.Error("original regs should not be modified")
+	}
+
+	if regs, err := SetRegsDSA(nil, &ExtRegsDSA{Required: 1}); err != nil || regs == nil {
+		t.Errorf("expected regs to be created, got %+v %v", regs, err)
+	}
+}
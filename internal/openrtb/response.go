@@ -80,11 +80,19 @@ const (
 
 // BidResponseExt represents PBS-specific response extensions
 type BidResponseExt struct {
-	ResponseTimeMillis map[string]int                `json:"responsetimemillis,omitempty"`
-	Errors             map[string][]ExtBidderMessage `json:"errors,omitempty"`
-	Warnings           map[string][]ExtBidderMessage `json:"warnings,omitempty"`
-	TMMaxRequest       int                           `json:"tmaxrequest,omitempty"`
-	Prebid             *ExtBidResponsePrebid         `json:"prebid,omitempty"`
+	ResponseTimeMillis map[string]int                 `json:"responsetimemillis,omitempty"`
+	Errors             map[string][]ExtBidderMessage  `json:"errors,omitempty"`
+	Warnings           map[string][]ExtBidderMessage  `json:"warnings,omitempty"`
+	TMMaxRequest       int                            `json:"tmaxrequest,omitempty"`
+	Prebid             *ExtBidResponsePrebid          `json:"prebid,omitempty"`
+	DataMinimization   map[string][]ExtMinimizedField `json:"dataminimization,omitempty"` // Debug only: bidder code -> personal data withheld from it
+}
+
+// ExtMinimizedField is a field removed or coarsened in a bidder's request because of regulation or consent
+type ExtMinimizedField struct {
+	Field  string `json:"field"`  // e.g. device.ip, user.eids
+	Action string `json:"action"` // truncated, rounded or removed
+	Reason string `json:"reason"` // coppa, us_optout, gdpr_vendor or gdpr_geo
 }
 
 // ExtBidderMessage represents bidder message
@@ -95,8 +103,23 @@ type ExtBidderMessage struct {
 
 // ExtBidResponsePrebid represents prebid response extension
 type ExtBidResponsePrebid struct {
-	AuctionTimestamp int64           `json:"auctiontimestamp,omitempty"`
-	Passthrough      json.RawMessage `json:"passthrough,omitempty"`
+	AuctionTimestamp int64                 `json:"auctiontimestamp,omitempty"`
+	Passthrough      json.RawMessage       `json:"passthrough,omitempty"`
+	Fledge           *ExtBidResponseFledge `json:"fledge,omitempty"`
+	UIDStoreToken    string                `json:"uidstoretoken,omitempty"` // Signed UID store keys, passed to /setuid and /optout as st
+}
+
+// ExtBidResponseFledge carries Protected Audience (FLEDGE) auction configs returned by bidders
+type ExtBidResponseFledge struct {
+	AuctionConfigs []*FledgeAuctionConfig `json:"auctionconfigs,omitempty"`
+}
+
+// FledgeAuctionConfig is a bidder's interest-group auction config for one impression
+// Config is passed through to the browser's runAdAuction untouched.
+type FledgeAuctionConfig struct {
+	ImpID  string          `json:"impid"`
+	Bidder string          `json:"bidder,omitempty"`
+	Config json.RawMessage `json:"config"`
 }
 
 // BidExt represents bid extension
@@ -158,4 +181,27 @@ type ExtBidPrebidMeta struct {
 	RendererName    string          `json:"rendererName,omitempty"`
 	RendererVersion string          `json:"rendererVersion,omitempty"`
 	RendererURL     string          `json:"rendererUrl,omitempty"`
+	DSA             *ExtBidDSA      `json:"dsa,omitempty"` // DSA transparency info from bid.ext.dsa, for rendering the ad's "about this ad" disclosure
+}
+
+// ExtBidDSA is the EU Digital Services Act transparency object a bid returns in bid.ext.dsa
+type ExtBidDSA struct {
+	Behalf       string            `json:"behalf,omitempty"` // Advertiser on whose behalf the ad is shown
+	Paid         string            `json:"paid,omitempty"`   // Entity that paid for the ad
+	Transparency []DSATransparency `json:"transparency,omitempty"`
+	AdRender     *int              `json:"adrender,omitempty"` // 0=buyer won't render, 1=buyer will render
+}
+
+// BidDSA returns the bid.ext.dsa object of a bid, or nil if absent
+func BidDSA(bid *Bid) (*ExtBidDSA, error) {
+	if bid == nil || len(bid.Ext) == 0 {
+		return nil, nil
+	}
+	var ext struct {
+		DSA *ExtBidDSA `json:"dsa"`
+	}
+	if err := json.Unmarshal(bid.Ext, &ext); err != nil {
+		return nil, err
+	}
+	return ext.DSA, nil
 }
@@ -743,3 +743,24 @@ func BenchmarkBid_Marshal(b *testing.B) {
 		_, _ = json.Marshal(bid)
 	}
 }
+
+func TestBidDSA(t *testing.T) {
+	bid := &Bid{ID: "1", Ext: json.RawMessage(`{"dsa":{"behalf":"Advertiser","paid":"Agency","transparency":[{"domain":"dsp.example","dsaparams":[1]}],"adrender":0}}`)}
+	dsa, err := BidDSA(bid)
+	if err != nil {
+		t.Fatalf("BidDSA failed: %v", err)
+	}
+	if dsa == nil || dsa.Behalf != "Advertiser" || dsa.Paid != "Agency" || dsa.Transparency[0].Params[0] != 1 {
+		t.Errorf("dsa mismatch: %+v", dsa)
+	}
+	if dsa.AdRender == nil || *dsa.AdRender != 0 {
+		t.Error("expected adrender 0 to be distinguishable from absent")
+	}
+
+	if dsa, err := BidDSA(&Bid{ID: "2"}); dsa != nil || err != nil {
+		t.Errorf("expected nil without ext, got %+v %v", dsa, err)
+	}
+	if _, err := BidDSA(&Bid{ID: "3", Ext: json.RawMessage(`{"dsa":1}`)}); err == nil {
+		t.Error("expected error for malformed dsa")
+	}
+}
@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+)
+
+// maxAuditListLimit caps how many audit entries one query returns
+const maxAuditListLimit = 1000
+
+// AuditFilter selects admin audit log entries
+type AuditFilter struct {
+	Actor string    // Exact actor ("" = any)
+	Path  string    // Path prefix ("" = any)
+	Since time.Time // Only entries at or after this time (zero = any)
+	Limit int       // Max entries, newest first (0 = 100)
+}
+
+// AuditStore provides database operations for the admin audit log
+type AuditStore struct {
+	db *sql.DB
+}
+
+// NewAuditStore creates a new audit store
+func NewAuditStore(db *sql.DB) *AuditStore {
+	return &AuditStore{db: db}
+}
+
+// Record appends an entry to the audit log (implements middleware.AuditSink)
+func (s *AuditStore) Record(ctx context.Context, entry *middleware.AuditEntry) error {
+	query := `
+		INSERT INTO admin_audit_log (
+			actor, actor_key_id, actor_role, remote_ip, method, path,
+			status_code, before_state, after_state, created_at
+		) VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+
+	err := s.db.QueryRowContext(ctx, query,
+		entry.Actor,
+		entry.ActorKeyID,
+		entry.ActorRole,
+		entry.RemoteIP,
+		entry.Method,
+		entry.Path,
+		entry.StatusCode,
+		nullableJSON(entry.Before),
+		nullableJSON(entry.After),
+		entry.CreatedAt,
+	).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+// List returns audit entries matching filter, newest first
+func (s *AuditStore) List(ctx context.Context, filter AuditFilter) ([]*middleware.AuditEntry, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.Actor != "" {
+		args = append(args, filter.Actor)
+		conditions = append(conditions, fmt.Sprintf("actor = $%d", len(args)))
+	}
+	if filter.Path != "" {
+		args = append(args, escapeLike(filter.Path)+"%")
+		conditions = append(conditions, fmt.Sprintf("path LIKE $%d", len(args)))
+	}
+	if !filter.Since.IsZero() {
+		args = append(args, filter.Since)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > maxAuditListLimit {
+		limit = maxAuditListLimit
+	}
+	args = append(args, limit)
+
+	query := `
+		SELECT id, actor, COALESCE(actor_key_id, ''), COALESCE(actor_role, ''), COALESCE(remote_ip, ''),
+		       method, path, status_code, before_state, after_state, created_at
+		FROM admin_audit_log`
+	if len(conditions) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf("\n\t\tORDER BY created_at DESC, id DESC\n\t\tLIMIT $%d", len(args))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*middleware.AuditEntry, 0, limit)
+	for rows.Next() {
+		var e middleware.AuditEntry
+		var before, after []byte
+		if err := rows.Scan(
+			&e.ID,
+			&e.Actor,
+			&e.ActorKeyID,
+			&e.ActorRole,
+			&e.RemoteIP,
+			&e.Method,
+			&e.Path,
+			&e.StatusCode,
+			&before,
+			&after,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		e.Before = before
+		e.After = after
+		entries = append(entries, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// nullableJSON returns nil for an empty JSON document so the column stays NULL
+func nullableJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return data
+}
+
+// escapeLike escapes LIKE wildcards in a literal prefix
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
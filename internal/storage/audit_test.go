@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/thenexusengine/tne_springwire/internal/middleware"
+)
+
+func TestAuditStore_Record(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	store := NewAuditStore(db)
+	entry := &middleware.AuditEntry{
+		Actor:      "ops-key",
+		ActorKeyID: "k1",
+		ActorRole:  middleware.RoleOperator,
+		Method:     "PUT",
+		Path:       "/admin/publishers/pub1",
+		StatusCode: 200,
+		Before:     []byte(`{"status":"active"}`),
+		After:      []byte(`{"status":"paused"}`),
+		RemoteIP:   "10.0.0.1",
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	mock.ExpectQuery("INSERT INTO admin_audit_log").
+		WithArgs("ops-key", "k1", middleware.RoleOperator, "10.0.0.1", "PUT", "/admin/publishers/pub1", 200,
+			[]byte(`{"status":"active"}`), []byte(`{"status":"paused"}`), entry.CreatedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+
+	if err := store.Record(context.Background(), entry); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if entry.ID != 42 {
+		t.Errorf("Expected ID 42, got %d", entry.ID)
+	}
+
+	// Empty diffs are stored as NULL
+	mock.ExpectQuery("INSERT INTO admin_audit_log").
+		WithArgs("anonymous", "", "", "", "POST", "/admin/syncers/reload", 200, nil, nil, sqlmock.AnyArg()).
+		WillReturnError(errors.New("connection lost"))
+
+	err = store.Record(context.Background(), &middleware.AuditEntry{
+		Actor:      "anonymous",
+		Method:     "POST",
+		Path:       "/admin/syncers/reload",
+		StatusCode: 200,
+	})
+	if err == nil {
+		t.Error("Expected error from failed insert")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestAuditStore_List(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	store := NewAuditStore(db)
+	since := time.Now().Add(-time.Hour)
+	created := time.Now()
+
+	rows := sqlmock.NewRows([]string{
+		"id", "actor", "actor_key_id", "actor_role", "remote_ip",
+		"method", "path", "status_code", "before_state", "after_state", "created_at",
+	}).
+		AddRow(2, "ops-key", "k1", "operator", "10.0.0.1", "DELETE", "/admin/publishers/pub_1", 200, []byte(`{"id":"pub_1"}`), nil, created).
+		AddRow(1, "ops-key", "k1", "operator", "", "POST", "/admin/publishers", 201, nil, []byte(`{"id":"pub_1"}`), created)
+
+	mock.ExpectQuery(`FROM admin_audit_log\s+WHERE actor = \$1 AND path LIKE \$2 AND created_at >= \$3\s+ORDER BY created_at DESC, id DESC\s+LIMIT \$4`).
+		WithArgs("ops-key", `/admin/publishers\_%`, since, maxAuditListLimit).
+		WillReturnRows(rows)
+
+	entries, err := store.List(context.Background(), AuditFilter{
+		Actor: "ops-key",
+		Path:  "/admin/publishers_",
+		Since: since,
+		Limit: 5000,
+	})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].ID != 2 || string(entries[0].Before) != `{"id":"pub_1"}` || entries[0].After != nil {
+		t.Errorf("Unexpected first entry %+v", entries[0])
+	}
+	if entries[1].StatusCode != 201 || string(entries[1].After) != `{"id":"pub_1"}` {
+		t.Errorf("Unexpected second entry %+v", entries[1])
+	}
+
+	// No filters: default limit only
+	mock.ExpectQuery(`FROM admin_audit_log\s+ORDER BY`).
+		WithArgs(100).
+		WillReturnError(errors.New("query failed"))
+
+	if _, err := store.List(context.Background(), AuditFilter{}); err == nil {
+		t.Error("Expected error from failed query")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
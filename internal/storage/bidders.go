@@ -5,8 +5,11 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/usersync"
 )
 
 // Bidder represents a bidder configuration from the database
@@ -31,6 +34,15 @@ type Bidder struct {
 	UpdatedAt        time.Time              `json:"updated_at"`
 }
 
+// BidderUserSync holds a bidder's user sync definition from the bidders table
+// The supported sync types are the ones with a URL template
+type BidderUserSync struct {
+	IframeURL   string `json:"iframe_url,omitempty"`   // iframe sync URL template
+	RedirectURL string `json:"redirect_url,omitempty"` // redirect (image) sync URL template
+	SupportCORS bool   `json:"support_cors"`
+	UserMacro   string `json:"user_macro,omitempty"` // Bidder's user ID macro in the /setuid redirect
+}
+
 // PublisherBidder represents a bidder with publisher-specific configuration
 type PublisherBidder struct {
 	Bidder
@@ -39,6 +51,20 @@ type PublisherBidder struct {
 	BidderConfig  map[string]interface{} `json:"bidder_config"`
 }
 
+// Bidder statuses allowed by the bidders.status check constraint
+const (
+	BidderStatusActive   = "active"
+	BidderStatusTesting  = "testing"
+	BidderStatusDisabled = "disabled"
+	BidderStatusArchived = "archived"
+)
+
+// Bidder store errors
+var (
+	ErrBidderNotFound = errors.New("bidder not found")
+	ErrBidderExists   = errors.New("bidder already exists")
+)
+
 // BidderStore provides database operations for bidders
 type BidderStore struct {
 	db *sql.DB
@@ -54,8 +80,8 @@ func (s *BidderStore) GetByCode(ctx context.Context, bidderCode string) (*Bidder
 	query := `
 		SELECT id, bidder_code, bidder_name, endpoint_url, timeout_ms,
 		       enabled, status, supports_banner, supports_video, supports_native, supports_audio,
-		       gvl_vendor_id, http_headers, description, documentation_url, contact_email,
-		       created_at, updated_at
+		       gvl_vendor_id, http_headers, COALESCE(description, ''), COALESCE(documentation_url, ''),
+		       COALESCE(contact_email, ''), created_at, updated_at
 		FROM bidders
 		WHERE bidder_code = $1 AND enabled = true AND status = 'active'
 	`
@@ -101,13 +127,66 @@ func (s *BidderStore) GetByCode(ctx context.Context, bidderCode string) (*Bidder
 	return &b, nil
 }
 
+// Get retrieves a bidder by bidder_code whatever its status
+// Returns ErrBidderNotFound if there is no such bidder.
+func (s *BidderStore) Get(ctx context.Context, bidderCode string) (*Bidder, error) {
+	query := `
+		SELECT id, bidder_code, bidder_name, endpoint_url, timeout_ms,
+		       enabled, status, supports_banner, supports_video, supports_native, supports_audio,
+		       gvl_vendor_id, http_headers, COALESCE(description, ''), COALESCE(documentation_url, ''),
+		       COALESCE(contact_email, ''), created_at, updated_at
+		FROM bidders
+		WHERE bidder_code = $1
+	`
+
+	var b Bidder
+	var httpHeadersJSON []byte
+
+	err := s.db.QueryRowContext(ctx, query, bidderCode).Scan(
+		&b.ID,
+		&b.BidderCode,
+		&b.BidderName,
+		&b.EndpointURL,
+		&b.TimeoutMs,
+		&b.Enabled,
+		&b.Status,
+		&b.SupportsBanner,
+		&b.SupportsVideo,
+		&b.SupportsNative,
+		&b.SupportsAudio,
+		&b.GVLVendorID,
+		&httpHeadersJSON,
+		&b.Description,
+		&b.DocumentationURL,
+		&b.ContactEmail,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrBidderNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bidder: %w", err)
+	}
+
+	// Parse JSONB http_headers
+	if len(httpHeadersJSON) > 0 {
+		if err := json.Unmarshal(httpHeadersJSON, &b.HTTPHeaders); err != nil {
+			return nil, fmt.Errorf("failed to parse http_headers: %w", err)
+		}
+	}
+
+	return &b, nil
+}
+
 // ListActive retrieves all active bidders
 func (s *BidderStore) ListActive(ctx context.Context) ([]*Bidder, error) {
 	query := `
 		SELECT id, bidder_code, bidder_name, endpoint_url, timeout_ms,
 		       enabled, status, supports_banner, supports_video, supports_native, supports_audio,
-		       gvl_vendor_id, http_headers, description, documentation_url, contact_email,
-		       created_at, updated_at
+		       gvl_vendor_id, http_headers, COALESCE(description, ''), COALESCE(documentation_url, ''),
+		       COALESCE(contact_email, ''), created_at, updated_at
 		FROM bidders
 		WHERE enabled = true AND status = 'active'
 		ORDER BY bidder_code
@@ -261,8 +340,8 @@ func (s *BidderStore) List(ctx context.Context) ([]*Bidder, error) {
 	query := `
 		SELECT id, bidder_code, bidder_name, endpoint_url, timeout_ms,
 		       enabled, status, supports_banner, supports_video, supports_native, supports_audio,
-		       gvl_vendor_id, http_headers, description, documentation_url, contact_email,
-		       created_at, updated_at
+		       gvl_vendor_id, http_headers, COALESCE(description, ''), COALESCE(documentation_url, ''),
+		       COALESCE(contact_email, ''), created_at, updated_at
 		FROM bidders
 		ORDER BY bidder_code
 	`
@@ -355,9 +434,13 @@ func (s *BidderStore) Create(ctx context.Context, b *Bidder) error {
 		b.ContactEmail,
 	).Scan(&b.ID, &b.CreatedAt, &b.UpdatedAt)
 
+	if isUniqueViolation(err) {
+		return ErrBidderExists
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create bidder: %w", err)
 	}
+	b.Status = status
 
 	return nil
 }
@@ -406,7 +489,7 @@ func (s *BidderStore) Update(ctx context.Context, b *Bidder) error {
 	}
 
 	if rows == 0 {
-		return fmt.Errorf("bidder not found: %s", b.BidderCode)
+		return fmt.Errorf("%w: %s", ErrBidderNotFound, b.BidderCode)
 	}
 
 	return nil
@@ -431,7 +514,7 @@ func (s *BidderStore) Delete(ctx context.Context, bidderCode string) error {
 	}
 
 	if rows == 0 {
-		return fmt.Errorf("bidder not found: %s", bidderCode)
+		return fmt.Errorf("%w: %s", ErrBidderNotFound, bidderCode)
 	}
 
 	return nil
@@ -456,7 +539,7 @@ func (s *BidderStore) SetEnabled(ctx context.Context, bidderCode string, enabled
 	}
 
 	if rows == 0 {
-		return fmt.Errorf("bidder not found: %s", bidderCode)
+		return fmt.Errorf("%w: %s", ErrBidderNotFound, bidderCode)
 	}
 
 	return nil
@@ -467,8 +550,8 @@ func (s *BidderStore) GetCapabilities(ctx context.Context, banner, video, native
 	query := `
 		SELECT id, bidder_code, bidder_name, endpoint_url, timeout_ms,
 		       enabled, status, supports_banner, supports_video, supports_native, supports_audio,
-		       gvl_vendor_id, http_headers, description, documentation_url, contact_email,
-		       created_at, updated_at
+		       gvl_vendor_id, http_headers, COALESCE(description, ''), COALESCE(documentation_url, ''),
+		       COALESCE(contact_email, ''), created_at, updated_at
 		FROM bidders
 		WHERE enabled = true
 		  AND status = 'active'
@@ -526,3 +609,93 @@ func (s *BidderStore) GetCapabilities(ctx context.Context, banner, video, native
 
 	return bidders, rows.Err()
 }
+
+// ListSyncerConfigs retrieves user sync definitions keyed by bidder code
+// Bidders that are disabled, or neither active nor testing, are returned with Enabled false
+// so they can switch off a built-in syncer; enabled bidders without a sync URL are omitted.
+// NULL enabled or status columns count as disabled.
+func (s *BidderStore) ListSyncerConfigs(ctx context.Context) (map[string]usersync.SyncerConfig, error) {
+	query := `
+		SELECT bidder_code, gvl_vendor_id, usersync_iframe_url, usersync_redirect_url,
+		       usersync_supports_cors, usersync_user_macro,
+		       COALESCE(enabled, false) AND COALESCE(status, '') IN ('active', 'testing')
+		FROM bidders
+		ORDER BY bidder_code
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bidder syncers: %w", err)
+	}
+	defer rows.Close()
+
+	configs := make(map[string]usersync.SyncerConfig)
+	for rows.Next() {
+		var (
+			bidderCode  string
+			gvlVendorID sql.NullInt64
+			iframeURL   sql.NullString
+			redirectURL sql.NullString
+			supportCORS sql.NullBool
+			userMacro   sql.NullString
+			enabled     sql.NullBool
+		)
+
+		if err := rows.Scan(&bidderCode, &gvlVendorID, &iframeURL, &redirectURL, &supportCORS, &userMacro, &enabled); err != nil {
+			return nil, fmt.Errorf("failed to scan bidder syncer row: %w", err)
+		}
+		if enabled.Bool && iframeURL.String == "" && redirectURL.String == "" {
+			continue
+		}
+
+		configs[bidderCode] = usersync.SyncerConfig{
+			BidderCode:      bidderCode,
+			IframeSyncURL:   iframeURL.String,
+			RedirectSyncURL: redirectURL.String,
+			SupportCORS:     supportCORS.Bool,
+			UserMacro:       userMacro.String,
+			GVLVendorID:     int(gvlVendorID.Int64),
+			Enabled:         enabled.Bool,
+		}
+	}
+
+	return configs, rows.Err()
+}
+
+// SetUserSync updates a bidder's user sync definition
+// Empty URL templates and macros are stored as NULL
+func (s *BidderStore) SetUserSync(ctx context.Context, bidderCode string, sync *BidderUserSync) error {
+	query := `
+		UPDATE bidders
+		SET usersync_iframe_url = $1, usersync_redirect_url = $2,
+		    usersync_supports_cors = $3, usersync_user_macro = $4
+		WHERE bidder_code = $5
+	`
+
+	result, err := s.db.ExecContext(ctx, query,
+		nullString(sync.IframeURL),
+		nullString(sync.RedirectURL),
+		sync.SupportCORS,
+		nullString(sync.UserMacro),
+		bidderCode,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update bidder user sync: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("%w: %s", ErrBidderNotFound, bidderCode)
+	}
+
+	return nil
+}
+
+// nullString converts an empty string to SQL NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 )
 
 func TestNewBidderStore(t *testing.T) {
@@ -667,6 +668,74 @@ func TestBidderStore_Create_Success(t *testing.T) {
 	}
 }
 
+// TestBidderStore_Create_Duplicate tests the unique bidder_code violation
+func TestBidderStore_Create_Duplicate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewBidderStore(db)
+
+	mock.ExpectQuery("INSERT INTO bidders").
+		WillReturnError(&pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"})
+
+	if err := store.Create(context.Background(), createTestBidder("appnexus")); !errors.Is(err, ErrBidderExists) {
+		t.Errorf("Expected ErrBidderExists, got %v", err)
+	}
+}
+
+// TestBidderStore_Get tests loading a bidder whatever its status
+func TestBidderStore_Get(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewBidderStore(db)
+	ctx := context.Background()
+	disabled := createTestBidder("appnexus")
+	disabled.Enabled = false
+	disabled.Status = BidderStatusDisabled
+
+	rows := sqlmock.NewRows([]string{
+		"id", "bidder_code", "bidder_name", "endpoint_url", "timeout_ms",
+		"enabled", "status", "supports_banner", "supports_video", "supports_native", "supports_audio",
+		"gvl_vendor_id", "http_headers", "description", "documentation_url", "contact_email",
+		"created_at", "updated_at",
+	}).AddRow(
+		disabled.ID, disabled.BidderCode, disabled.BidderName, disabled.EndpointURL, disabled.TimeoutMs,
+		disabled.Enabled, disabled.Status, disabled.SupportsBanner, disabled.SupportsVideo, disabled.SupportsNative, disabled.SupportsAudio,
+		disabled.GVLVendorID, []byte(`{"X-Seat":"abc"}`), "", "", "",
+		disabled.CreatedAt, disabled.UpdatedAt,
+	)
+
+	mock.ExpectQuery("SELECT (.+) FROM bidders\\s+WHERE bidder_code = \\$1\\s*$").
+		WithArgs("appnexus").
+		WillReturnRows(rows)
+	mock.ExpectQuery("SELECT (.+) FROM bidders").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	bidder, err := store.Get(ctx, "appnexus")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if bidder.Enabled || bidder.Status != BidderStatusDisabled || bidder.HTTPHeaders["X-Seat"] != "abc" {
+		t.Errorf("Expected disabled bidder with headers, got %+v", bidder)
+	}
+
+	if _, err := store.Get(ctx, "missing"); !errors.Is(err, ErrBidderNotFound) {
+		t.Errorf("Expected ErrBidderNotFound, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
 // TestBidderStore_Create_Error tests create with database error
 func TestBidderStore_Create_Error(t *testing.T) {
 	db, mock, err := sqlmock.New()
@@ -1004,3 +1073,148 @@ func TestBidderStore_GetCapabilities_AllFormats(t *testing.T) {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
 }
+
+func TestBidderStore_ListSyncerConfigs_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewBidderStore(db)
+	ctx := context.Background()
+
+	rows := sqlmock.NewRows([]string{
+		"bidder_code", "gvl_vendor_id", "usersync_iframe_url", "usersync_redirect_url",
+		"usersync_supports_cors", "usersync_user_macro", "enabled",
+	}).
+		AddRow("appnexus", 32, nil, "https://ib.adnxs.com/getuid?{{redirect_url}}", true, "$UID", true).
+		AddRow("newssp", nil, "https://sync.newssp.com/iframe", nil, nil, nil, true).
+		AddRow("plainbidder", nil, nil, nil, nil, nil, true).
+		AddRow("rubicon", 52, nil, nil, nil, nil, false)
+
+	mock.ExpectQuery("SELECT bidder_code, gvl_vendor_id, usersync_iframe_url").WillReturnRows(rows)
+
+	configs, err := store.ListSyncerConfigs(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(configs) != 3 {
+		t.Fatalf("Expected 3 syncer configs, got %d", len(configs))
+	}
+
+	// Enabled rows without a sync URL leave the built-in syncer alone; disabled rows are kept to remove it
+	if _, ok := configs["plainbidder"]; ok {
+		t.Error("Expected enabled bidder without a sync URL omitted")
+	}
+	if rubicon, ok := configs["rubicon"]; !ok || rubicon.Enabled {
+		t.Errorf("Expected disabled rubicon returned with Enabled false, got %+v", rubicon)
+	}
+
+	appnexus := configs["appnexus"]
+	if appnexus.RedirectSyncURL != "https://ib.adnxs.com/getuid?{{redirect_url}}" || appnexus.IframeSyncURL != "" {
+		t.Errorf("Unexpected appnexus URLs: %+v", appnexus)
+	}
+	if !appnexus.SupportCORS || appnexus.UserMacro != "$UID" || appnexus.GVLVendorID != 32 || !appnexus.Enabled {
+		t.Errorf("Unexpected appnexus config: %+v", appnexus)
+	}
+
+	newssp := configs["newssp"]
+	if newssp.IframeSyncURL != "https://sync.newssp.com/iframe" || newssp.SupportCORS || newssp.GVLVendorID != 0 {
+		t.Errorf("Unexpected newssp config: %+v", newssp)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestBidderStore_ListSyncerConfigs_NullEnabled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewBidderStore(db)
+
+	rows := sqlmock.NewRows([]string{
+		"bidder_code", "gvl_vendor_id", "usersync_iframe_url", "usersync_redirect_url",
+		"usersync_supports_cors", "usersync_user_macro", "enabled",
+	}).
+		AddRow("legacy", nil, "https://sync.legacy.com/iframe", nil, nil, nil, nil).
+		AddRow("newssp", nil, "https://sync.newssp.com/iframe", nil, nil, nil, true)
+
+	// NULL enabled/status columns must be coalesced rather than propagating NULL
+	mock.ExpectQuery(`COALESCE\(enabled, false\) AND COALESCE\(status, ''\) IN`).WillReturnRows(rows)
+
+	configs, err := store.ListSyncerConfigs(context.Background())
+	if err != nil {
+		t.Fatalf("Expected a NULL enabled row not to fail the listing, got %v", err)
+	}
+	if legacy, ok := configs["legacy"]; !ok || legacy.Enabled {
+		t.Errorf("Expected NULL enabled row returned as disabled, got %+v (present=%v)", legacy, ok)
+	}
+	if !configs["newssp"].Enabled {
+		t.Error("Expected newssp enabled")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestBidderStore_ListSyncerConfigs_QueryError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewBidderStore(db)
+
+	mock.ExpectQuery("SELECT bidder_code").WillReturnError(errors.New("connection lost"))
+
+	if _, err := store.ListSyncerConfigs(context.Background()); err == nil {
+		t.Error("Expected error when query fails")
+	}
+}
+
+func TestBidderStore_SetUserSync(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewBidderStore(db)
+	ctx := context.Background()
+
+	mock.ExpectExec("UPDATE bidders").
+		WithArgs(
+			sql.NullString{},
+			sql.NullString{String: "https://sync.example.com/?r={{redirect_url}}", Valid: true},
+			true,
+			sql.NullString{String: "${UID}", Valid: true},
+			"newssp",
+		).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE bidders").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = store.SetUserSync(ctx, "newssp", &BidderUserSync{
+		RedirectURL: "https://sync.example.com/?r={{redirect_url}}",
+		SupportCORS: true,
+		UserMacro:   "${UID}",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := store.SetUserSync(ctx, "missing", &BidderUserSync{}); err == nil {
+		t.Error("Expected error for unknown bidder")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -5,10 +5,15 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/lib/pq" // PostgreSQL driver
+
+	"github.com/thenexusengine/tne_springwire/internal/fpd"
+	"github.com/thenexusengine/tne_springwire/internal/ivtpolicy"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
 )
 
 // Publisher represents a publisher configuration from the database
@@ -24,6 +29,10 @@ type Publisher struct {
 	UpdatedAt      time.Time              `json:"updated_at"`
 	Notes          string                 `json:"notes,omitempty"`
 	ContactEmail   string                 `json:"contact_email,omitempty"`
+	EIDPermissions []fpd.EIDPermission    `json:"eid_permissions,omitempty"` // EID sources restricted to named bidders
+	DSA            *openrtb.ExtRegsDSA    `json:"dsa,omitempty"`             // Default regs.ext.dsa for requests that don't set one
+	IVTPolicy      *ivtpolicy.Policy      `json:"ivt_policy,omitempty"`      // Overrides for the global IVT configuration
+	RateLimitTier  string                 `json:"rate_limit_tier,omitempty"` // Named rate limit from PUBLISHER_RATE_LIMIT_TIERS ("" = default)
 }
 
 // GetAllowedDomains returns the allowed domains string (for middleware interface)
@@ -36,11 +45,58 @@ func (p *Publisher) GetBidMultiplier() float64 {
 	return p.BidMultiplier
 }
 
+// GetEIDPermissions returns the publisher's per-bidder EID permissions (for exchange interface)
+func (p *Publisher) GetEIDPermissions() []fpd.EIDPermission {
+	return p.EIDPermissions
+}
+
+// GetDSA returns the publisher's default DSA transparency requirements (for exchange interface)
+func (p *Publisher) GetDSA() *openrtb.ExtRegsDSA {
+	return p.DSA
+}
+
+// GetIVTPolicy returns the publisher's IVT policy overrides (for middleware interface)
+func (p *Publisher) GetIVTPolicy() *ivtpolicy.Policy {
+	return p.IVTPolicy
+}
+
+// GetRateLimitTier returns the publisher's rate limit tier (for middleware interface)
+func (p *Publisher) GetRateLimitTier() string {
+	return p.RateLimitTier
+}
+
 // GetPublisherID returns the publisher ID (for exchange interface)
 func (p *Publisher) GetPublisherID() string {
 	return p.PublisherID
 }
 
+// Publisher statuses allowed by the publishers.status check constraint
+const (
+	PublisherStatusActive   = "active"
+	PublisherStatusPaused   = "paused"
+	PublisherStatusArchived = "archived"
+)
+
+// Publisher page sizes for ListPage
+const (
+	DefaultPublisherPageSize = 50
+	MaxPublisherPageSize     = 500
+)
+
+// Publisher store errors
+var (
+	ErrPublisherNotFound = errors.New("publisher not found")
+	ErrPublisherExists   = errors.New("publisher already exists")
+	ErrPublisherModified = errors.New("publisher was modified since it was read")
+)
+
+// PublisherFilter selects a page of publishers
+type PublisherFilter struct {
+	Status string // Exact status ("" = any)
+	Limit  int    // Page size (0 = DefaultPublisherPageSize, capped at MaxPublisherPageSize)
+	Offset int    // Publishers to skip
+}
+
 // PublisherStore provides database operations for publishers
 type PublisherStore struct {
 	db *sql.DB
@@ -60,16 +116,125 @@ func (s *PublisherStore) GetByPublisherID(ctx context.Context, publisherID strin
 // getByPublisherIDConcrete is the internal implementation returning concrete type
 func (s *PublisherStore) getByPublisherIDConcrete(ctx context.Context, publisherID string) (*Publisher, error) {
 	query := `
-		SELECT id, publisher_id, name, allowed_domains, bidder_params, bid_multiplier,
-		       status, created_at, updated_at, notes, contact_email
+		SELECT ` + publisherColumns + `
 		FROM publishers
 		WHERE publisher_id = $1 AND status = 'active'
 	`
 
+	p, err := scanPublisher(s.db.QueryRowContext(ctx, query, publisherID))
+	if err == sql.ErrNoRows {
+		return nil, nil // Publisher not found
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query publisher: %w", err)
+	}
+
+	return p, nil
+}
+
+// Get retrieves a publisher by publisher_id whatever its status
+// Returns ErrPublisherNotFound if there is no such publisher.
+func (s *PublisherStore) Get(ctx context.Context, publisherID string) (*Publisher, error) {
+	query := `
+		SELECT ` + publisherColumns + `
+		FROM publishers
+		WHERE publisher_id = $1
+	`
+
+	p, err := scanPublisher(s.db.QueryRowContext(ctx, query, publisherID))
+	if err == sql.ErrNoRows {
+		return nil, ErrPublisherNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query publisher: %w", err)
+	}
+
+	return p, nil
+}
+
+// List retrieves all active publishers
+func (s *PublisherStore) List(ctx context.Context) ([]*Publisher, error) {
+	query := `
+		SELECT ` + publisherColumns + `
+		FROM publishers
+		WHERE status = 'active'
+		ORDER BY publisher_id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query publishers: %w", err)
+	}
+	defer rows.Close()
+
+	return scanPublishers(rows, 100)
+}
+
+// ListPage retrieves one page of publishers ordered by publisher_id, and the total matching filter
+func (s *PublisherStore) ListPage(ctx context.Context, filter PublisherFilter) ([]*Publisher, int, error) {
+	where := ""
+	var args []interface{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where = "WHERE status = $1"
+	}
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM publishers " + where
+	if err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count publishers: %w", err)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultPublisherPageSize
+	}
+	if limit > MaxPublisherPageSize {
+		limit = MaxPublisherPageSize
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	args = append(args, limit, offset)
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM publishers
+		%s
+		ORDER BY publisher_id
+		LIMIT $%d OFFSET $%d
+	`, publisherColumns, where, len(args)-1, len(args))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query publishers: %w", err)
+	}
+	defer rows.Close()
+
+	publishers, err := scanPublishers(rows, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return publishers, total, nil
+}
+
+// publisherColumns are the columns scanPublisher reads, in order
+const publisherColumns = `id, publisher_id, name, allowed_domains, bidder_params, bid_multiplier,
+		       status, created_at, updated_at, COALESCE(notes, ''), COALESCE(contact_email, ''),
+		       eid_permissions, dsa, ivt_policy, COALESCE(rate_limit_tier, '')`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPublisher reads one publisherColumns row, returning scan errors unwrapped
+func scanPublisher(row rowScanner) (*Publisher, error) {
 	var p Publisher
-	var bidderParamsJSON []byte
+	var bidderParamsJSON, eidPermissionsJSON, dsaJSON, ivtPolicyJSON []byte
 
-	err := s.db.QueryRowContext(ctx, query, publisherID).Scan(
+	err := row.Scan(
 		&p.ID,
 		&p.PublisherID,
 		&p.Name,
@@ -81,13 +246,13 @@ func (s *PublisherStore) getByPublisherIDConcrete(ctx context.Context, publisher
 		&p.UpdatedAt,
 		&p.Notes,
 		&p.ContactEmail,
+		&eidPermissionsJSON,
+		&dsaJSON,
+		&ivtPolicyJSON,
+		&p.RateLimitTier,
 	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil // Publisher not found
-	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to query publisher: %w", err)
+		return nil, err
 	}
 
 	// Parse JSONB bidder_params
@@ -97,55 +262,39 @@ func (s *PublisherStore) getByPublisherIDConcrete(ctx context.Context, publisher
 		}
 	}
 
-	return &p, nil
-}
+	// Parse JSONB eid_permissions
+	if len(eidPermissionsJSON) > 0 {
+		if err := json.Unmarshal(eidPermissionsJSON, &p.EIDPermissions); err != nil {
+			return nil, fmt.Errorf("failed to parse eid_permissions: %w", err)
+		}
+	}
 
-// List retrieves all active publishers
-func (s *PublisherStore) List(ctx context.Context) ([]*Publisher, error) {
-	query := `
-		SELECT id, publisher_id, name, allowed_domains, bidder_params, bid_multiplier,
-		       status, created_at, updated_at, notes, contact_email
-		FROM publishers
-		WHERE status = 'active'
-		ORDER BY publisher_id
-	`
+	// Parse JSONB dsa (NULL when the publisher has no DSA defaults)
+	if len(dsaJSON) > 0 {
+		if err := json.Unmarshal(dsaJSON, &p.DSA); err != nil {
+			return nil, fmt.Errorf("failed to parse dsa: %w", err)
+		}
+	}
 
-	rows, err := s.db.QueryContext(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query publishers: %w", err)
+	// Parse JSONB ivt_policy (NULL when the publisher uses the global IVT config)
+	if len(ivtPolicyJSON) > 0 {
+		if err := json.Unmarshal(ivtPolicyJSON, &p.IVTPolicy); err != nil {
+			return nil, fmt.Errorf("failed to parse ivt_policy: %w", err)
+		}
 	}
-	defer rows.Close()
 
-	publishers := make([]*Publisher, 0, 100)
+	return &p, nil
+}
+
+// scanPublishers reads all remaining publisherColumns rows
+func scanPublishers(rows *sql.Rows, capacity int) ([]*Publisher, error) {
+	publishers := make([]*Publisher, 0, capacity)
 	for rows.Next() {
-		var p Publisher
-		var bidderParamsJSON []byte
-
-		err := rows.Scan(
-			&p.ID,
-			&p.PublisherID,
-			&p.Name,
-			&p.AllowedDomains,
-			&bidderParamsJSON,
-			&p.BidMultiplier,
-			&p.Status,
-			&p.CreatedAt,
-			&p.UpdatedAt,
-			&p.Notes,
-			&p.ContactEmail,
-		)
+		p, err := scanPublisher(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan publisher row: %w", err)
 		}
-
-		// Parse JSONB bidder_params
-		if len(bidderParamsJSON) > 0 {
-			if err := json.Unmarshal(bidderParamsJSON, &p.BidderParams); err != nil {
-				return nil, fmt.Errorf("failed to parse bidder_params: %w", err)
-			}
-		}
-
-		publishers = append(publishers, &p)
+		publishers = append(publishers, p)
 	}
 
 	return publishers, rows.Err()
@@ -166,8 +315,9 @@ func (s *PublisherStore) Create(ctx context.Context, p *Publisher) error {
 
 	query := `
 		INSERT INTO publishers (
-			publisher_id, name, allowed_domains, bidder_params, bid_multiplier, status, notes, contact_email
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			publisher_id, name, allowed_domains, bidder_params, bid_multiplier, status, notes, contact_email,
+			eid_permissions, dsa, ivt_policy, rate_limit_tier
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, ''))
 		RETURNING id, created_at, updated_at
 	`
 
@@ -176,6 +326,21 @@ func (s *PublisherStore) Create(ctx context.Context, p *Publisher) error {
 		return fmt.Errorf("failed to marshal bidder_params: %w", err)
 	}
 
+	eidPermissionsJSON, err := marshalEIDPermissions(p.EIDPermissions)
+	if err != nil {
+		return err
+	}
+
+	dsaJSON, err := marshalDSA(p.DSA)
+	if err != nil {
+		return err
+	}
+
+	ivtPolicyJSON, err := marshalIVTPolicy(p.IVTPolicy)
+	if err != nil {
+		return err
+	}
+
 	err = s.db.QueryRowContext(ctx, query,
 		p.PublisherID,
 		p.Name,
@@ -185,11 +350,19 @@ func (s *PublisherStore) Create(ctx context.Context, p *Publisher) error {
 		status,
 		p.Notes,
 		p.ContactEmail,
+		eidPermissionsJSON,
+		dsaJSON,
+		ivtPolicyJSON,
+		p.RateLimitTier,
 	).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
 
+	if isUniqueViolation(err) {
+		return ErrPublisherExists
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create publisher: %w", err)
 	}
+	p.Status = status
 
 	return nil
 }
@@ -199,8 +372,9 @@ func (s *PublisherStore) Update(ctx context.Context, p *Publisher) error {
 	query := `
 		UPDATE publishers
 		SET name = $1, allowed_domains = $2, bidder_params = $3,
-		    bid_multiplier = $4, status = $5, notes = $6, contact_email = $7
-		WHERE publisher_id = $8
+		    bid_multiplier = $4, status = $5, notes = $6, contact_email = $7,
+		    eid_permissions = $8, dsa = $9, ivt_policy = $10, rate_limit_tier = NULLIF($11, '')
+		WHERE publisher_id = $12
 	`
 
 	bidderParamsJSON, err := json.Marshal(p.BidderParams)
@@ -208,6 +382,21 @@ func (s *PublisherStore) Update(ctx context.Context, p *Publisher) error {
 		return fmt.Errorf("failed to marshal bidder_params: %w", err)
 	}
 
+	eidPermissionsJSON, err := marshalEIDPermissions(p.EIDPermissions)
+	if err != nil {
+		return err
+	}
+
+	dsaJSON, err := marshalDSA(p.DSA)
+	if err != nil {
+		return err
+	}
+
+	ivtPolicyJSON, err := marshalIVTPolicy(p.IVTPolicy)
+	if err != nil {
+		return err
+	}
+
 	result, err := s.db.ExecContext(ctx, query,
 		p.Name,
 		p.AllowedDomains,
@@ -216,6 +405,10 @@ func (s *PublisherStore) Update(ctx context.Context, p *Publisher) error {
 		p.Status,
 		p.Notes,
 		p.ContactEmail,
+		eidPermissionsJSON,
+		dsaJSON,
+		ivtPolicyJSON,
+		p.RateLimitTier,
 		p.PublisherID,
 	)
 
@@ -235,6 +428,121 @@ func (s *PublisherStore) Update(ctx context.Context, p *Publisher) error {
 	return nil
 }
 
+// UpdateIfUnmodified modifies a publisher only if its updated_at still equals lastUpdatedAt
+// On success p.UpdatedAt holds the new timestamp. Returns ErrPublisherModified if another
+// write got there first and ErrPublisherNotFound if the publisher doesn't exist.
+func (s *PublisherStore) UpdateIfUnmodified(ctx context.Context, p *Publisher, lastUpdatedAt time.Time) error {
+	query := `
+		UPDATE publishers
+		SET name = $1, allowed_domains = $2, bidder_params = $3,
+		    bid_multiplier = $4, status = $5, notes = $6, contact_email = $7,
+		    eid_permissions = $8, dsa = $9, ivt_policy = $10, rate_limit_tier = NULLIF($11, '')
+		WHERE publisher_id = $12 AND updated_at = $13
+		RETURNING id, created_at, updated_at
+	`
+
+	bidderParamsJSON, err := json.Marshal(p.BidderParams)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bidder_params: %w", err)
+	}
+
+	eidPermissionsJSON, err := marshalEIDPermissions(p.EIDPermissions)
+	if err != nil {
+		return err
+	}
+
+	dsaJSON, err := marshalDSA(p.DSA)
+	if err != nil {
+		return err
+	}
+
+	ivtPolicyJSON, err := marshalIVTPolicy(p.IVTPolicy)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.QueryRowContext(ctx, query,
+		p.Name,
+		p.AllowedDomains,
+		bidderParamsJSON,
+		p.BidMultiplier,
+		p.Status,
+		p.Notes,
+		p.ContactEmail,
+		eidPermissionsJSON,
+		dsaJSON,
+		ivtPolicyJSON,
+		p.RateLimitTier,
+		p.PublisherID,
+		lastUpdatedAt,
+	).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		// Either the publisher is gone or updated_at moved on
+		var exists bool
+		if err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM publishers WHERE publisher_id = $1)`, p.PublisherID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check publisher: %w", err)
+		}
+		if !exists {
+			return ErrPublisherNotFound
+		}
+		return ErrPublisherModified
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update publisher: %w", err)
+	}
+
+	return nil
+}
+
+// isUniqueViolation reports whether err is a PostgreSQL unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// marshalEIDPermissions encodes eid_permissions as a JSON array (empty when unset)
+func marshalEIDPermissions(perms []fpd.EIDPermission) ([]byte, error) {
+	if perms == nil {
+		perms = []fpd.EIDPermission{}
+	}
+	data, err := json.Marshal(perms)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal eid_permissions: %w", err)
+	}
+	return data, nil
+}
+
+// marshalDSA validates and encodes the publisher's DSA defaults (nil stores SQL NULL)
+func marshalDSA(dsa *openrtb.ExtRegsDSA) ([]byte, error) {
+	if dsa == nil {
+		return nil, nil
+	}
+	if err := dsa.Validate(); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(dsa)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal dsa: %w", err)
+	}
+	return data, nil
+}
+
+// marshalIVTPolicy validates and encodes the publisher's IVT policy (nil stores SQL NULL)
+func marshalIVTPolicy(policy *ivtpolicy.Policy) ([]byte, error) {
+	if policy == nil {
+		return nil, nil
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ivt_policy: %w", err)
+	}
+	return data, nil
+}
+
 // Delete soft-deletes a publisher by setting status to 'archived'
 func (s *PublisherStore) Delete(ctx context.Context, publisherID string) error {
 	query := `
@@ -3,12 +3,17 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"errors"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+
+	"github.com/thenexusengine/tne_springwire/internal/ivtpolicy"
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
 )
 
 // createTestPublisher creates a test publisher for use in tests
@@ -67,6 +72,7 @@ func TestPublisherStore_GetByPublisherID_Success(t *testing.T) {
 	rows := sqlmock.NewRows([]string{
 		"id", "publisher_id", "name", "allowed_domains", "bidder_params",
 		"bid_multiplier", "status", "created_at", "updated_at", "notes", "contact_email",
+		"eid_permissions", "dsa", "ivt_policy", "rate_limit_tier",
 	}).AddRow(
 		expectedPublisher.ID,
 		expectedPublisher.PublisherID,
@@ -79,6 +85,10 @@ func TestPublisherStore_GetByPublisherID_Success(t *testing.T) {
 		expectedPublisher.UpdatedAt,
 		expectedPublisher.Notes,
 		expectedPublisher.ContactEmail,
+		[]byte(`[{"source":"liveramp.com","bidders":["appnexus"]}]`),
+		[]byte(`{"dsarequired":2,"pubrender":1,"datatopub":2}`),
+		[]byte(`{"mode":"block","block_threshold":50,"allowed_countries":["US","CA"]}`),
+		"premium",
 	)
 
 	mock.ExpectQuery("SELECT (.+) FROM publishers WHERE publisher_id").
@@ -104,6 +114,19 @@ func TestPublisherStore_GetByPublisherID_Success(t *testing.T) {
 	if publisher.BidMultiplier != 1.05 {
 		t.Errorf("Expected 1.05, got %f", publisher.BidMultiplier)
 	}
+	perms := publisher.GetEIDPermissions()
+	if len(perms) != 1 || perms[0].Source != "liveramp.com" || len(perms[0].Bidders) != 1 || perms[0].Bidders[0] != "appnexus" {
+		t.Errorf("Expected liveramp.com restricted to appnexus, got %+v", perms)
+	}
+	if dsa := publisher.GetDSA(); dsa == nil || dsa.Required != 2 || dsa.PubRender != 1 {
+		t.Errorf("Expected DSA defaults dsarequired=2 pubrender=1, got %+v", dsa)
+	}
+	if policy := publisher.GetIVTPolicy(); policy == nil || policy.Mode != "block" || policy.BlockThreshold != 50 || len(policy.AllowedCountries) != 2 {
+		t.Errorf("Expected IVT policy mode=block threshold=50 with 2 countries, got %+v", policy)
+	}
+	if tier := publisher.GetRateLimitTier(); tier != "premium" {
+		t.Errorf("Expected rate limit tier 'premium', got '%s'", tier)
+	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("Unfulfilled expectations: %v", err)
@@ -183,6 +206,7 @@ func TestPublisherStore_GetByPublisherID_InvalidJSON(t *testing.T) {
 	rows := sqlmock.NewRows([]string{
 		"id", "publisher_id", "name", "allowed_domains", "bidder_params",
 		"bid_multiplier", "status", "created_at", "updated_at", "notes", "contact_email",
+		"eid_permissions", "dsa", "ivt_policy", "rate_limit_tier",
 	}).AddRow(
 		"1",
 		"pub-123",
@@ -195,6 +219,10 @@ func TestPublisherStore_GetByPublisherID_InvalidJSON(t *testing.T) {
 		time.Now(),
 		"notes",
 		"test@example.com",
+		nil,
+		nil,
+		nil,
+		"",
 	)
 
 	mock.ExpectQuery("SELECT (.+) FROM publishers WHERE publisher_id").
@@ -235,12 +263,13 @@ func TestPublisherStore_List_Success(t *testing.T) {
 	rows := sqlmock.NewRows([]string{
 		"id", "publisher_id", "name", "allowed_domains", "bidder_params",
 		"bid_multiplier", "status", "created_at", "updated_at", "notes", "contact_email",
+		"eid_permissions", "dsa", "ivt_policy", "rate_limit_tier",
 	}).AddRow(
 		pub1.ID, pub1.PublisherID, pub1.Name, pub1.AllowedDomains, bidderParamsJSON1,
-		pub1.BidMultiplier, pub1.Status, pub1.CreatedAt, pub1.UpdatedAt, pub1.Notes, pub1.ContactEmail,
+		pub1.BidMultiplier, pub1.Status, pub1.CreatedAt, pub1.UpdatedAt, pub1.Notes, pub1.ContactEmail, nil, nil, nil, "",
 	).AddRow(
 		pub2.ID, pub2.PublisherID, pub2.Name, pub2.AllowedDomains, bidderParamsJSON2,
-		pub2.BidMultiplier, pub2.Status, pub2.CreatedAt, pub2.UpdatedAt, pub2.Notes, pub2.ContactEmail,
+		pub2.BidMultiplier, pub2.Status, pub2.CreatedAt, pub2.UpdatedAt, pub2.Notes, pub2.ContactEmail, nil, nil, nil, "",
 	)
 
 	mock.ExpectQuery("SELECT (.+) FROM publishers WHERE status").
@@ -280,6 +309,7 @@ func TestPublisherStore_List_Empty(t *testing.T) {
 	rows := sqlmock.NewRows([]string{
 		"id", "publisher_id", "name", "allowed_domains", "bidder_params",
 		"bid_multiplier", "status", "created_at", "updated_at", "notes", "contact_email",
+		"eid_permissions", "dsa", "ivt_policy", "rate_limit_tier",
 	})
 
 	mock.ExpectQuery("SELECT (.+) FROM publishers WHERE status").
@@ -338,9 +368,10 @@ func TestPublisherStore_List_ScanError(t *testing.T) {
 	rows := sqlmock.NewRows([]string{
 		"id", "publisher_id", "name", "allowed_domains", "bidder_params",
 		"bid_multiplier", "status", "created_at", "updated_at", "notes", "contact_email",
+		"eid_permissions", "dsa", "ivt_policy", "rate_limit_tier",
 	}).AddRow(
 		"1", "pub-1", "Test", "example.com", []byte("{invalid}"),
-		1.05, "active", time.Now(), time.Now(), "notes", "test@example.com",
+		1.05, "active", time.Now(), time.Now(), "notes", "test@example.com", nil, nil, nil, "",
 	)
 
 	mock.ExpectQuery("SELECT (.+) FROM publishers WHERE status").
@@ -385,6 +416,10 @@ func TestPublisherStore_Create_Success(t *testing.T) {
 			publisher.Status,
 			publisher.Notes,
 			publisher.ContactEmail,
+			sqlmock.AnyArg(), // eid_permissions JSON
+			sqlmock.AnyArg(), // dsa JSON
+			sqlmock.AnyArg(), // ivt_policy JSON
+			sqlmock.AnyArg(), // rate_limit_tier
 		).
 		WillReturnRows(rows)
 
@@ -428,6 +463,10 @@ func TestPublisherStore_Create_DefaultBidMultiplier(t *testing.T) {
 			publisher.Status,
 			publisher.Notes,
 			publisher.ContactEmail,
+			sqlmock.AnyArg(), // eid_permissions JSON
+			sqlmock.AnyArg(), // dsa JSON
+			sqlmock.AnyArg(), // ivt_policy JSON
+			sqlmock.AnyArg(), // rate_limit_tier
 		).
 		WillReturnRows(rows)
 
@@ -467,6 +506,52 @@ func TestPublisherStore_Create_MarshalError(t *testing.T) {
 	}
 }
 
+func TestPublisherStore_Create_InvalidDSA(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewPublisherStore(db)
+
+	publisher := createTestPublisher("pub-new")
+	publisher.DSA = &openrtb.ExtRegsDSA{Required: 5}
+
+	if err := store.Create(context.Background(), publisher); err == nil {
+		t.Error("Expected error for out-of-range dsarequired")
+	}
+
+	// Nothing is written when the defaults are rejected
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPublisherStore_Create_InvalidIVTPolicy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewPublisherStore(db)
+	ctx := context.Background()
+
+	publisher := createTestPublisher("pub-new")
+	publisher.IVTPolicy = &ivtpolicy.Policy{Mode: "quarantine"}
+
+	err = store.Create(ctx, publisher)
+	if err == nil {
+		t.Error("Expected error for invalid IVT policy mode")
+	}
+
+	// Nothing is written when the policy is rejected
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
 func TestPublisherStore_Create_QueryError(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -483,6 +568,7 @@ func TestPublisherStore_Create_QueryError(t *testing.T) {
 		WithArgs(
 			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
 			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
 		).
 		WillReturnError(errors.New("database error"))
 
@@ -518,6 +604,10 @@ func TestPublisherStore_Update_Success(t *testing.T) {
 			publisher.Status,
 			publisher.Notes,
 			publisher.ContactEmail,
+			sqlmock.AnyArg(), // eid_permissions JSON
+			sqlmock.AnyArg(), // dsa JSON
+			sqlmock.AnyArg(), // ivt_policy JSON
+			sqlmock.AnyArg(), // rate_limit_tier
 			publisher.PublisherID,
 		).
 		WillReturnResult(sqlmock.NewResult(0, 1))
@@ -548,6 +638,7 @@ func TestPublisherStore_Update_NotFound(t *testing.T) {
 		WithArgs(
 			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
 			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
 		).
 		WillReturnResult(sqlmock.NewResult(0, 0)) // 0 rows affected
 
@@ -599,6 +690,7 @@ func TestPublisherStore_Update_QueryError(t *testing.T) {
 		WithArgs(
 			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
 			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
 		).
 		WillReturnError(errors.New("database error"))
 
@@ -818,3 +910,157 @@ func TestPublisher_GetterMethods(t *testing.T) {
 		t.Errorf("Expected 1.05, got %f", publisher.GetBidMultiplier())
 	}
 }
+
+// publisherRows returns mock rows in publisherColumns order (test helper)
+func publisherRows(publishers ...*Publisher) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		"id", "publisher_id", "name", "allowed_domains", "bidder_params",
+		"bid_multiplier", "status", "created_at", "updated_at", "notes", "contact_email",
+		"eid_permissions", "dsa", "ivt_policy", "rate_limit_tier",
+	})
+	for _, p := range publishers {
+		bidderParamsJSON, _ := json.Marshal(p.BidderParams)
+		rows.AddRow(p.ID, p.PublisherID, p.Name, p.AllowedDomains, bidderParamsJSON, p.BidMultiplier,
+			p.Status, p.CreatedAt, p.UpdatedAt, p.Notes, p.ContactEmail, []byte(`[]`), nil, nil, p.RateLimitTier)
+	}
+	return rows
+}
+
+func TestPublisherStore_Get(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewPublisherStore(db)
+	paused := createTestPublisher("pub-123")
+	paused.Status = PublisherStatusPaused
+
+	// Inactive publishers are still returned
+	mock.ExpectQuery("SELECT (.+) FROM publishers WHERE publisher_id = \\$1$").
+		WithArgs("pub-123").
+		WillReturnRows(publisherRows(paused))
+	mock.ExpectQuery("SELECT (.+) FROM publishers").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	publisher, err := store.Get(context.Background(), "pub-123")
+	if err != nil || publisher.Status != PublisherStatusPaused {
+		t.Fatalf("Expected paused publisher, got %+v, %v", publisher, err)
+	}
+	if _, err := store.Get(context.Background(), "missing"); !errors.Is(err, ErrPublisherNotFound) {
+		t.Errorf("Expected ErrPublisherNotFound, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPublisherStore_ListPage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewPublisherStore(db)
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM publishers WHERE status = \\$1").
+		WithArgs(PublisherStatusActive).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery("SELECT (.+) FROM publishers\\s+WHERE status = \\$1\\s+ORDER BY publisher_id\\s+LIMIT \\$2 OFFSET \\$3").
+		WithArgs(PublisherStatusActive, 2, 2).
+		WillReturnRows(publisherRows(createTestPublisher("pub-3")))
+
+	publishers, total, err := store.ListPage(context.Background(), PublisherFilter{Status: PublisherStatusActive, Limit: 2, Offset: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if total != 3 || len(publishers) != 1 || publishers[0].PublisherID != "pub-3" {
+		t.Errorf("Expected last page of 3, got total %d and %+v", total, publishers)
+	}
+
+	// No status filter, default and capped page size
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM publishers").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("SELECT (.+) FROM publishers\\s+ORDER BY publisher_id\\s+LIMIT \\$1 OFFSET \\$2").
+		WithArgs(MaxPublisherPageSize, 0).
+		WillReturnRows(publisherRows())
+
+	if _, _, err := store.ListPage(context.Background(), PublisherFilter{Limit: 10000, Offset: -5}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPublisherStore_UpdateIfUnmodified(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewPublisherStore(db)
+	ctx := context.Background()
+	publisher := createTestPublisher("pub-123")
+	lastRead := time.Date(2026, 5, 1, 10, 0, 0, 123456000, time.UTC)
+	updatedAt := lastRead.Add(time.Minute)
+
+	anyArgs := func(extra ...driver.Value) []driver.Value {
+		args := make([]driver.Value, 0, 13)
+		for i := 0; i < 11; i++ {
+			args = append(args, sqlmock.AnyArg())
+		}
+		return append(args, extra...)
+	}
+
+	// Success returns the new updated_at
+	mock.ExpectQuery("UPDATE publishers (.+) WHERE publisher_id = \\$12 AND updated_at = \\$13").
+		WithArgs(anyArgs("pub-123", lastRead)...).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("1", lastRead, updatedAt))
+	if err := store.UpdateIfUnmodified(ctx, publisher, lastRead); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !publisher.UpdatedAt.Equal(updatedAt) {
+		t.Errorf("Expected updated_at %v, got %v", updatedAt, publisher.UpdatedAt)
+	}
+
+	// Stale updated_at on an existing publisher is a conflict
+	mock.ExpectQuery("UPDATE publishers").WithArgs(anyArgs("pub-123", lastRead)...).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SELECT EXISTS").WithArgs("pub-123").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	if err := store.UpdateIfUnmodified(ctx, publisher, lastRead); !errors.Is(err, ErrPublisherModified) {
+		t.Errorf("Expected ErrPublisherModified, got %v", err)
+	}
+
+	// Missing publisher
+	mock.ExpectQuery("UPDATE publishers").WithArgs(anyArgs("pub-123", lastRead)...).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SELECT EXISTS").WithArgs("pub-123").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	if err := store.UpdateIfUnmodified(ctx, publisher, lastRead); !errors.Is(err, ErrPublisherNotFound) {
+		t.Errorf("Expected ErrPublisherNotFound, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPublisherStore_Create_Duplicate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	store := NewPublisherStore(db)
+	mock.ExpectQuery("INSERT INTO publishers").
+		WillReturnError(&pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"})
+
+	if err := store.Create(context.Background(), createTestPublisher("pub-123")); !errors.Is(err, ErrPublisherExists) {
+		t.Errorf("Expected ErrPublisherExists, got %v", err)
+	}
+}
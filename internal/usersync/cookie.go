@@ -2,7 +2,6 @@
 package usersync
 
 import (
-	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"sync"
@@ -41,22 +40,27 @@ func NewCookie() *Cookie {
 }
 
 // ParseCookie parses a cookie from an HTTP request
+// Cookies that fail signature verification or decryption are discarded, keeping only their opt-out.
+// Each call records a verification metric, so endpoints parse once per request and pass the result on.
 func ParseCookie(r *http.Request) *Cookie {
 	cookie, err := r.Cookie(CookieName)
 	if err != nil {
 		return NewCookie()
 	}
 
-	// Decode base64
-	decoded, err := base64.URLEncoding.DecodeString(cookie.Value)
-	if err != nil {
-		return NewCookie()
+	cc := currentCodec()
+	decoded, result := cc.decode(cookie.Value, time.Now())
+	if decoded == nil {
+		cc.record(result)
+		return rejectedCookie(cookie.Value)
 	}
 
 	var c Cookie
 	if err := json.Unmarshal(decoded, &c); err != nil {
+		cc.record(VerifyResultTampered)
 		return NewCookie()
 	}
+	cc.record(result)
 
 	if c.UIDs == nil {
 		c.UIDs = make(map[string]UID)
@@ -68,6 +72,19 @@ func ParseCookie(r *http.Request) *Cookie {
 	return &c
 }
 
+// rejectedCookie replaces a cookie that failed verification, carrying over its opt-out
+// An unverified opt-out is safe to honor: it can only stop syncing for the browser that sent it.
+func rejectedCookie(value string) *Cookie {
+	c := NewCookie()
+	var claimed struct {
+		OptOut bool `json:"optout"`
+	}
+	if payload := unverifiedPayload(value); payload != nil && json.Unmarshal(payload, &claimed) == nil {
+		c.OptOut = claimed.OptOut
+	}
+	return c
+}
+
 // GetUID returns the UID for a bidder, or empty string if not found/expired
 func (c *Cookie) GetUID(bidderCode string) string {
 	c.mu.RLock()
@@ -109,6 +126,18 @@ func (c *Cookie) HasUID(bidderCode string) bool {
 	return c.GetUID(bidderCode) != ""
 }
 
+// NeedsSync returns true if the bidder has no valid UID or its UID expires within window
+func (c *Cookie) NeedsSync(bidderCode string, window time.Duration) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	uid, ok := c.UIDs[bidderCode]
+	if !ok || uid.UID == "" {
+		return true
+	}
+	return !time.Now().Add(window).Before(uid.Expires)
+}
+
 // SyncCount returns the number of synced bidders
 func (c *Cookie) SyncCount() int {
 	c.mu.RLock()
@@ -161,19 +190,18 @@ func (c *Cookie) ToHTTPCookie(domain string) (*http.Cookie, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	data, err := json.Marshal(c)
+	cc := currentCodec()
+	encoded, err := c.encode(cc)
 	if err != nil {
 		return nil, err
 	}
 
-	encoded := base64.URLEncoding.EncodeToString(data)
-
 	// Check size limit
 	if len(encoded) > MaxCookieSize {
 		// Trim oldest UIDs to fit
 		c.trimToFit()
-		if data, err := json.Marshal(c); err == nil {
-			encoded = base64.URLEncoding.EncodeToString(data)
+		if trimmed, err := c.encode(cc); err == nil {
+			encoded = trimmed
 		}
 	}
 
@@ -194,11 +222,10 @@ func (c *Cookie) ToHTTPCookie(domain string) (*http.Cookie, error) {
 func (c *Cookie) trimToFit() {
 	// Simple approach: remove UIDs with earliest expiry until we fit
 	for len(c.UIDs) > 0 {
-		data, err := json.Marshal(c)
+		encoded, err := c.encode(currentCodec())
 		if err != nil {
-			break // Can't check size if marshal fails
+			break // Can't check size if encoding fails
 		}
-		encoded := base64.URLEncoding.EncodeToString(data)
 		if len(encoded) <= MaxCookieSize {
 			break
 		}
@@ -218,6 +245,16 @@ func (c *Cookie) trimToFit() {
 	}
 }
 
+// encode marshals the cookie and encodes it with the given codec
+// Caller must hold c.mu
+func (c *Cookie) encode(cc *cookieCodec) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return cc.encode(data)
+}
+
 // GetAllUIDs returns a copy of all valid UIDs
 func (c *Cookie) GetAllUIDs() map[string]string {
 	c.mu.RLock()
@@ -238,3 +238,27 @@ func TestCookie_TrimToFit(t *testing.T) {
 		t.Errorf("Expected some UIDs to be removed, still have %d", len(c.UIDs))
 	}
 }
+
+func TestCookie_NeedsSync(t *testing.T) {
+	c := NewCookie()
+	c.SetUID("fresh", "uid-1")
+	c.UIDs["expiring"] = UID{UID: "uid-2", Expires: time.Now().Add(24 * time.Hour)}
+	c.UIDs["expired"] = UID{UID: "uid-3", Expires: time.Now().Add(-time.Hour)}
+
+	window := 7 * 24 * time.Hour
+	if c.NeedsSync("fresh", window) {
+		t.Error("Fresh UID should not need sync")
+	}
+	if !c.NeedsSync("expiring", window) {
+		t.Error("UID expiring within the window should need sync")
+	}
+	if !c.NeedsSync("expired", window) {
+		t.Error("Expired UID should need sync")
+	}
+	if !c.NeedsSync("missing", window) {
+		t.Error("Missing UID should need sync")
+	}
+	if c.NeedsSync("expiring", 0) {
+		t.Error("Unexpired UID should not need sync without a re-sync window")
+	}
+}
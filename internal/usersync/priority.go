@@ -0,0 +1,162 @@
+package usersync
+
+import (
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RevenueSource reports cumulative revenue per bidder code
+type RevenueSource interface {
+	BidderRevenue() map[string]float64
+}
+
+// PriorityConfig holds cookie sync prioritization configuration
+type PriorityConfig struct {
+	// PriorityGroups lists bidder groups in descending priority; ungrouped bidders rank last
+	PriorityGroups [][]string
+	// ResyncWindow re-syncs bidders whose UID expires within this window
+	ResyncWindow time.Duration
+	// RevenueCacheTTL is how long a revenue snapshot is reused before refreshing
+	RevenueCacheTTL time.Duration
+	// CoopSyncDefault enables cooperative sync when a request names no bidders
+	CoopSyncDefault bool
+}
+
+// DefaultPriorityConfig returns cookie sync prioritization configuration from environment variables
+// COOKIE_SYNC_PRIORITY_GROUPS: groups separated by ';', bidders by ',' (e.g. "appnexus,rubicon;pubmatic")
+// COOKIE_SYNC_RESYNC_WINDOW: re-sync UIDs expiring within this duration (default: 7 days)
+// COOKIE_SYNC_REVENUE_CACHE_TTL: revenue ranking refresh interval (default: 5m)
+// COOKIE_SYNC_COOP_DEFAULT: "true" enables cooperative sync by default
+func DefaultPriorityConfig() *PriorityConfig {
+	return &PriorityConfig{
+		PriorityGroups:  parsePriorityGroups(os.Getenv("COOKIE_SYNC_PRIORITY_GROUPS")),
+		ResyncWindow:    getEnvDuration("COOKIE_SYNC_RESYNC_WINDOW", 7*24*time.Hour),
+		RevenueCacheTTL: getEnvDuration("COOKIE_SYNC_REVENUE_CACHE_TTL", 5*time.Minute),
+		CoopSyncDefault: os.Getenv("COOKIE_SYNC_COOP_DEFAULT") == "true",
+	}
+}
+
+// parsePriorityGroups parses "a,b;c" into [[a b] [c]], lowercasing bidder codes
+func parsePriorityGroups(envValue string) [][]string {
+	var groups [][]string
+	for _, group := range strings.Split(envValue, ";") {
+		var bidders []string
+		for _, bidder := range strings.Split(group, ",") {
+			if bidder = strings.ToLower(strings.TrimSpace(bidder)); bidder != "" {
+				bidders = append(bidders, bidder)
+			}
+		}
+		if len(bidders) > 0 {
+			groups = append(groups, bidders)
+		}
+	}
+	return groups
+}
+
+// Prioritizer orders bidders for cookie sync by priority group, then revenue
+type Prioritizer struct {
+	config *PriorityConfig
+	groups map[string]int // Bidder code -> group index
+
+	mu         sync.Mutex
+	revenue    RevenueSource
+	snapshot   map[string]float64
+	snapshotAt time.Time
+}
+
+// NewPrioritizer creates a cookie sync prioritizer
+func NewPrioritizer(config *PriorityConfig) *Prioritizer {
+	if config == nil {
+		config = DefaultPriorityConfig()
+	}
+
+	groups := make(map[string]int)
+	for i, group := range config.PriorityGroups {
+		for _, bidder := range group {
+			bidder = strings.ToLower(bidder)
+			if _, exists := groups[bidder]; !exists {
+				groups[bidder] = i
+			}
+		}
+	}
+
+	return &Prioritizer{
+		config: config,
+		groups: groups,
+	}
+}
+
+// SetRevenueSource sets the source used to rank bidders by revenue contribution
+func (p *Prioritizer) SetRevenueSource(source RevenueSource) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.revenue = source
+	p.snapshot = nil
+}
+
+// ResyncWindow returns the window before UID expiry in which bidders are re-synced
+func (p *Prioritizer) ResyncWindow() time.Duration {
+	return p.config.ResyncWindow
+}
+
+// CoopSyncDefault returns true if cooperative sync is on when a request names no bidders
+func (p *Prioritizer) CoopSyncDefault() bool {
+	return p.config.CoopSyncDefault
+}
+
+// Rank returns bidders ordered by priority group, then by descending revenue
+// Ties keep their input order, so callers can shuffle first to rotate equal bidders
+func (p *Prioritizer) Rank(bidders []string) []string {
+	revenue := p.revenueSnapshot()
+
+	ranked := make([]string, len(bidders))
+	copy(ranked, bidders)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		gi, gj := p.group(ranked[i]), p.group(ranked[j])
+		if gi != gj {
+			return gi < gj
+		}
+		return revenue[strings.ToLower(ranked[i])] > revenue[strings.ToLower(ranked[j])]
+	})
+	return ranked
+}
+
+// Shuffle randomizes bidder order in place so equally ranked bidders take turns
+func Shuffle(bidders []string) {
+	rand.Shuffle(len(bidders), func(i, j int) { //nolint:gosec // Not security sensitive
+		bidders[i], bidders[j] = bidders[j], bidders[i]
+	})
+}
+
+// group returns the priority group index for a bidder (ungrouped bidders last)
+func (p *Prioritizer) group(bidder string) int {
+	if i, ok := p.groups[strings.ToLower(bidder)]; ok {
+		return i
+	}
+	return len(p.config.PriorityGroups)
+}
+
+// revenueSnapshot returns per-bidder revenue, refreshing the cached snapshot when stale
+func (p *Prioritizer) revenueSnapshot() map[string]float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.revenue == nil {
+		return nil
+	}
+	if p.snapshot != nil && time.Since(p.snapshotAt) < p.config.RevenueCacheTTL {
+		return p.snapshot
+	}
+
+	snapshot := make(map[string]float64)
+	for bidder, value := range p.revenue.BidderRevenue() {
+		snapshot[strings.ToLower(bidder)] += value
+	}
+	p.snapshot = snapshot
+	p.snapshotAt = time.Now()
+	return snapshot
+}
@@ -0,0 +1,99 @@
+package usersync
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// mockRevenueSource is a RevenueSource with fixed values that counts calls
+type mockRevenueSource struct {
+	revenue map[string]float64
+	calls   int
+}
+
+func (m *mockRevenueSource) BidderRevenue() map[string]float64 {
+	m.calls++
+	return m.revenue
+}
+
+func TestPrioritizer_RankByGroup(t *testing.T) {
+	p := NewPrioritizer(&PriorityConfig{
+		PriorityGroups: [][]string{{"rubicon"}, {"OpenX", "pubmatic"}},
+	})
+
+	got := p.Rank([]string{"appnexus", "pubmatic", "openx", "rubicon"})
+	want := []string{"rubicon", "pubmatic", "openx", "appnexus"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestPrioritizer_RankByRevenue(t *testing.T) {
+	p := NewPrioritizer(&PriorityConfig{
+		PriorityGroups:  [][]string{{"triplelift"}},
+		RevenueCacheTTL: time.Minute,
+	})
+	source := &mockRevenueSource{revenue: map[string]float64{"appnexus": 10, "Rubicon": 50, "triplelift": 1}}
+	p.SetRevenueSource(source)
+
+	got := p.Rank([]string{"openx", "appnexus", "rubicon", "triplelift"})
+	want := []string{"triplelift", "rubicon", "appnexus", "openx"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// The revenue snapshot is cached for RevenueCacheTTL
+	p.Rank([]string{"appnexus"})
+	if source.calls != 1 {
+		t.Errorf("Expected 1 revenue read, got %d", source.calls)
+	}
+}
+
+func TestPrioritizer_RankKeepsTieOrder(t *testing.T) {
+	p := NewPrioritizer(&PriorityConfig{})
+	input := []string{"c", "a", "b"}
+
+	got := p.Rank(input)
+	if !reflect.DeepEqual(got, input) {
+		t.Errorf("Equal bidders should keep input order, got %v", got)
+	}
+	got[0] = "changed"
+	if input[0] != "c" {
+		t.Error("Rank should not modify its input")
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	bidders := []string{"a", "b", "c", "d"}
+	Shuffle(bidders)
+
+	seen := make(map[string]bool)
+	for _, b := range bidders {
+		seen[b] = true
+	}
+	if len(seen) != 4 {
+		t.Errorf("Shuffle should keep all bidders, got %v", bidders)
+	}
+}
+
+func TestDefaultPriorityConfig(t *testing.T) {
+	t.Setenv("COOKIE_SYNC_PRIORITY_GROUPS", " AppNexus, rubicon ;; pubmatic,")
+	t.Setenv("COOKIE_SYNC_RESYNC_WINDOW", "72h")
+	t.Setenv("COOKIE_SYNC_COOP_DEFAULT", "true")
+
+	cfg := DefaultPriorityConfig()
+	want := [][]string{{"appnexus", "rubicon"}, {"pubmatic"}}
+	if !reflect.DeepEqual(cfg.PriorityGroups, want) {
+		t.Errorf("Expected groups %v, got %v", want, cfg.PriorityGroups)
+	}
+	if cfg.ResyncWindow != 72*time.Hour {
+		t.Errorf("Expected re-sync window 72h, got %v", cfg.ResyncWindow)
+	}
+	if cfg.RevenueCacheTTL != 5*time.Minute {
+		t.Errorf("Expected default revenue cache TTL, got %v", cfg.RevenueCacheTTL)
+	}
+	if !cfg.CoopSyncDefault {
+		t.Error("Expected cooperative sync by default")
+	}
+}
@@ -0,0 +1,426 @@
+package usersync
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cookie value formats
+// Legacy cookies are plain base64 JSON; signed cookies carry a version prefix,
+// the signing key ID, the payload and an HMAC-SHA256 over everything before it:
+//
+//	s1.<kid>.<base64 JSON>.<mac>            signed
+//	e1.<kid>.<base64 nonce+ciphertext>.<mac> signed and AES-GCM encrypted
+const (
+	formatSigned    = "s1"
+	formatEncrypted = "e1"
+)
+
+// formatStoreToken prefixes UID store tokens: t1.<kid>.<base64 JSON>.<mac>
+const formatStoreToken = "t1"
+
+// StoreTokenTTL is how long a UID store token authorizes /setuid and /optout writes
+const StoreTokenTTL = 24 * time.Hour
+
+// Cookie verification results reported to CookieMetrics
+const (
+	VerifyResultValid          = "valid"
+	VerifyResultLegacy         = "legacy"
+	VerifyResultLegacyRejected = "legacy_rejected"
+	VerifyResultTampered       = "tampered"
+	VerifyResultUnknownKey     = "unknown_key"
+	VerifyResultDecryptFailed  = "decrypt_failed"
+)
+
+// minSigningKeyLength is the minimum HMAC secret length in bytes
+const minSigningKeyLength = 16
+
+var (
+	// ErrNoSigningKeys is returned when encryption is configured without signing keys
+	ErrNoSigningKeys = errors.New("usersync: encryption requires at least one signing key")
+	// ErrInvalidSigningKey is returned for malformed signing key IDs or short secrets
+	ErrInvalidSigningKey = errors.New("usersync: invalid signing key")
+	// ErrInvalidEncryptionKey is returned when the AES key is not 16, 24 or 32 bytes
+	ErrInvalidEncryptionKey = errors.New("usersync: encryption key must be 16, 24 or 32 bytes")
+)
+
+// SigningKey is an HMAC key used to sign and verify uids cookies
+type SigningKey struct {
+	ID     string // Short identifier embedded in the cookie (letters, digits, '-' and '_')
+	Secret []byte // HMAC-SHA256 secret
+}
+
+// SecurityConfig holds uids cookie signing and encryption configuration
+type SecurityConfig struct {
+	// SigningKeys verify incoming cookies; the first key signs outgoing cookies.
+	// Rotate by prepending a new key and keeping the old one until cookies re-sign.
+	SigningKeys []SigningKey
+	// EncryptionKey enables AES-GCM encryption of the cookie payload when set
+	EncryptionKey []byte
+	// LegacyAcceptUntil accepts unsigned cookies until this time (migration window)
+	// Zero rejects unsigned cookies once signing keys are configured
+	LegacyAcceptUntil time.Time
+}
+
+// CookieMetrics defines the metrics interface for cookie verification
+type CookieMetrics interface {
+	RecordUIDCookieVerification(result string)
+}
+
+// DefaultSecurityConfig returns cookie security configuration from environment variables
+// UIDS_COOKIE_SIGNING_KEYS: comma-separated "kid:secret" pairs, first key signs (default: unsigned)
+// UIDS_COOKIE_ENCRYPTION_KEY: base64 AES-128/192/256 key (default: no encryption)
+// UIDS_COOKIE_LEGACY_UNTIL: RFC 3339 time or YYYY-MM-DD date until which unsigned cookies are accepted
+func DefaultSecurityConfig() (*SecurityConfig, error) {
+	cfg := &SecurityConfig{
+		SigningKeys: parseSigningKeys(os.Getenv("UIDS_COOKIE_SIGNING_KEYS")),
+	}
+
+	if encKey := os.Getenv("UIDS_COOKIE_ENCRYPTION_KEY"); encKey != "" {
+		key, err := base64.StdEncoding.DecodeString(encKey)
+		if err != nil {
+			return nil, fmt.Errorf("usersync: UIDS_COOKIE_ENCRYPTION_KEY is not valid base64: %w", err)
+		}
+		cfg.EncryptionKey = key
+	}
+
+	if until := os.Getenv("UIDS_COOKIE_LEGACY_UNTIL"); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			t, err = time.Parse(time.DateOnly, until)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("usersync: UIDS_COOKIE_LEGACY_UNTIL must be RFC 3339 or YYYY-MM-DD: %w", err)
+		}
+		cfg.LegacyAcceptUntil = t
+	}
+
+	return cfg, nil
+}
+
+// parseSigningKeys parses "kid:secret" pairs, preserving order
+func parseSigningKeys(envValue string) []SigningKey {
+	var keys []SigningKey
+	for _, pair := range strings.Split(envValue, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		keys = append(keys, SigningKey{
+			ID:     strings.TrimSpace(parts[0]),
+			Secret: []byte(strings.TrimSpace(parts[1])),
+		})
+	}
+	return keys
+}
+
+// Validate checks the configuration for malformed keys
+func (c *SecurityConfig) Validate() error {
+	seen := make(map[string]bool, len(c.SigningKeys))
+	for _, key := range c.SigningKeys {
+		if !validKeyID(key.ID) {
+			return fmt.Errorf("%w: key ID %q must be non-empty letters, digits, '-' or '_'", ErrInvalidSigningKey, key.ID)
+		}
+		if seen[key.ID] {
+			return fmt.Errorf("%w: duplicate key ID %q", ErrInvalidSigningKey, key.ID)
+		}
+		seen[key.ID] = true
+		if len(key.Secret) < minSigningKeyLength {
+			return fmt.Errorf("%w: secret for %q must be at least %d bytes", ErrInvalidSigningKey, key.ID, minSigningKeyLength)
+		}
+	}
+
+	if len(c.EncryptionKey) > 0 {
+		if len(c.SigningKeys) == 0 {
+			return ErrNoSigningKeys
+		}
+		switch len(c.EncryptionKey) {
+		case 16, 24, 32:
+		default:
+			return ErrInvalidEncryptionKey
+		}
+	}
+	return nil
+}
+
+// validKeyID reports whether a key ID is safe to embed in the cookie value
+func validKeyID(id string) bool {
+	if id == "" || len(id) > 32 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// cookieCodec encodes and decodes uids cookie values
+type cookieCodec struct {
+	keys        map[string][]byte
+	currentKey  string
+	aead        cipher.AEAD
+	legacyUntil time.Time
+	metrics     CookieMetrics
+}
+
+var (
+	codecMu sync.RWMutex
+	codec   = &cookieCodec{}
+)
+
+// ConfigureSecurity installs the signing and encryption configuration used by
+// ParseCookie and ToHTTPCookie. A nil config restores unsigned legacy cookies.
+func ConfigureSecurity(cfg *SecurityConfig, metrics CookieMetrics) error {
+	next := &cookieCodec{metrics: metrics}
+
+	if cfg != nil {
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+
+		if len(cfg.SigningKeys) > 0 {
+			next.keys = make(map[string][]byte, len(cfg.SigningKeys))
+			for _, key := range cfg.SigningKeys {
+				next.keys[key.ID] = append([]byte(nil), key.Secret...)
+			}
+			next.currentKey = cfg.SigningKeys[0].ID
+		}
+
+		if len(cfg.EncryptionKey) > 0 {
+			block, err := aes.NewCipher(cfg.EncryptionKey)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidEncryptionKey, err)
+			}
+			aead, err := cipher.NewGCM(block)
+			if err != nil {
+				return fmt.Errorf("usersync: failed to create AES-GCM cipher: %w", err)
+			}
+			next.aead = aead
+		}
+
+		next.legacyUntil = cfg.LegacyAcceptUntil
+	}
+
+	codecMu.Lock()
+	codec = next
+	codecMu.Unlock()
+	return nil
+}
+
+// currentCodec returns the active cookie codec
+func currentCodec() *cookieCodec {
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+	return codec
+}
+
+// signingEnabled returns true when cookies are signed
+func (cc *cookieCodec) signingEnabled() bool {
+	return cc.currentKey != ""
+}
+
+// encode converts cookie JSON into a cookie value
+func (cc *cookieCodec) encode(data []byte) (string, error) {
+	if !cc.signingEnabled() {
+		return base64.URLEncoding.EncodeToString(data), nil
+	}
+
+	format := formatSigned
+	payload := data
+	if cc.aead != nil {
+		format = formatEncrypted
+		nonce := make([]byte, cc.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", fmt.Errorf("usersync: failed to generate nonce: %w", err)
+		}
+		// The key ID is bound as additional data so ciphertexts cannot be moved between keys
+		payload = cc.aead.Seal(nonce, nonce, data, []byte(cc.currentKey))
+	}
+
+	signed := format + "." + cc.currentKey + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + sign(cc.keys[cc.currentKey], signed), nil
+}
+
+// decode verifies a cookie value and returns the cookie JSON and verification result
+// A nil payload means the cookie must be discarded
+func (cc *cookieCodec) decode(value string, now time.Time) ([]byte, string) {
+	format, rest, found := strings.Cut(value, ".")
+	if !found || (format != formatSigned && format != formatEncrypted) {
+		return cc.decodeLegacy(value, now)
+	}
+
+	parts := strings.Split(rest, ".")
+	if len(parts) != 3 {
+		return nil, VerifyResultTampered
+	}
+	kid, encodedPayload, mac := parts[0], parts[1], parts[2]
+
+	secret, ok := cc.keys[kid]
+	if !ok {
+		return nil, VerifyResultUnknownKey
+	}
+
+	signed := format + "." + kid + "." + encodedPayload
+	if !hmac.Equal([]byte(sign(secret, signed)), []byte(mac)) {
+		return nil, VerifyResultTampered
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, VerifyResultTampered
+	}
+
+	if format == formatSigned {
+		return payload, VerifyResultValid
+	}
+
+	if cc.aead == nil || len(payload) < cc.aead.NonceSize() {
+		return nil, VerifyResultDecryptFailed
+	}
+	nonce, ciphertext := payload[:cc.aead.NonceSize()], payload[cc.aead.NonceSize():]
+	plaintext, err := cc.aead.Open(nil, nonce, ciphertext, []byte(kid))
+	if err != nil {
+		return nil, VerifyResultDecryptFailed
+	}
+	return plaintext, VerifyResultValid
+}
+
+// decodeLegacy decodes an unsigned base64 JSON cookie if the migration window allows it
+func (cc *cookieCodec) decodeLegacy(value string, now time.Time) ([]byte, string) {
+	if cc.signingEnabled() && !now.Before(cc.legacyUntil) {
+		return nil, VerifyResultLegacyRejected
+	}
+
+	decoded, err := base64.URLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, VerifyResultTampered
+	}
+	if !cc.signingEnabled() {
+		return decoded, VerifyResultValid
+	}
+	return decoded, VerifyResultLegacy
+}
+
+// unverifiedPayload returns a cookie's JSON without checking its signature
+// Returns nil for encrypted or malformed values.
+func unverifiedPayload(value string) []byte {
+	format, rest, found := strings.Cut(value, ".")
+	if !found || (format != formatSigned && format != formatEncrypted) {
+		decoded, err := base64.URLEncoding.DecodeString(value)
+		if err != nil {
+			return nil
+		}
+		return decoded
+	}
+	parts := strings.Split(rest, ".")
+	if format != formatSigned || len(parts) != 3 {
+		return nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil
+	}
+	return payload
+}
+
+// record reports a verification result to metrics when signing is enabled
+func (cc *cookieCodec) record(result string) {
+	if cc.metrics != nil && cc.signingEnabled() {
+		cc.metrics.RecordUIDCookieVerification(result)
+	}
+}
+
+// sign returns the base64 HMAC-SHA256 of value
+func sign(secret []byte, value string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// storeToken is the signed payload of a UID store token
+type storeToken struct {
+	Keys    []storeTokenKey `json:"k"`
+	Expires int64           `json:"exp"` // Unix seconds
+}
+
+// storeTokenKey is a StoreKey in a token
+type storeTokenKey struct {
+	Type  IDType `json:"t"`
+	Value string `json:"v"`
+}
+
+// IssueStoreToken signs store keys taken from an authenticated bid request
+// /setuid and /optout only write IFA and publisher user ID keys carried by a valid token, so
+// clients cannot write UIDs or opt-outs against identities they were not issued.
+// Returns "" when signing keys are not configured.
+func IssueStoreToken(keys []StoreKey) string {
+	return currentCodec().issueStoreToken(keys, time.Now())
+}
+
+// VerifyStoreToken returns the store keys of a valid, unexpired token, or nil
+func VerifyStoreToken(token string) []StoreKey {
+	return currentCodec().verifyStoreToken(token, time.Now())
+}
+
+// issueStoreToken signs keys with the current signing key
+func (cc *cookieCodec) issueStoreToken(keys []StoreKey, now time.Time) string {
+	if !cc.signingEnabled() || len(keys) == 0 {
+		return ""
+	}
+
+	token := storeToken{Expires: now.Add(StoreTokenTTL).Unix()}
+	for _, key := range keys {
+		token.Keys = append(token.Keys, storeTokenKey{Type: key.Type, Value: key.Value})
+	}
+	data, err := json.Marshal(token)
+	if err != nil {
+		return ""
+	}
+
+	signed := formatStoreToken + "." + cc.currentKey + "." + base64.RawURLEncoding.EncodeToString(data)
+	return signed + "." + sign(cc.keys[cc.currentKey], signed)
+}
+
+// verifyStoreToken checks the token signature and expiry and returns its keys
+func (cc *cookieCodec) verifyStoreToken(value string, now time.Time) []StoreKey {
+	parts := strings.Split(value, ".")
+	if len(parts) != 4 || parts[0] != formatStoreToken {
+		return nil
+	}
+	secret, ok := cc.keys[parts[1]]
+	if !ok {
+		return nil
+	}
+	signed := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(sign(secret, signed)), []byte(parts[3])) {
+		return nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil
+	}
+	var token storeToken
+	if err := json.Unmarshal(data, &token); err != nil || now.Unix() >= token.Expires {
+		return nil
+	}
+
+	keys := make([]StoreKey, 0, len(token.Keys))
+	for _, key := range token.Keys {
+		keys = append(keys, StoreKey{Type: key.Type, Value: key.Value})
+	}
+	return keys
+}
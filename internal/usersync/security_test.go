@@ -0,0 +1,424 @@
+package usersync
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockCookieMetrics records verification results
+type mockCookieMetrics struct {
+	results map[string]int
+}
+
+func (m *mockCookieMetrics) RecordUIDCookieVerification(result string) {
+	if m.results == nil {
+		m.results = make(map[string]int)
+	}
+	m.results[result]++
+}
+
+var (
+	testKeyCurrent = SigningKey{ID: "k2", Secret: []byte("0123456789abcdef0123456789abcdef")}
+	testKeyOld     = SigningKey{ID: "k1", Secret: []byte("fedcba9876543210fedcba9876543210")}
+	testAESKey     = []byte("0123456789abcdef0123456789abcdef")
+)
+
+// configureTestSecurity installs a cookie security config for the duration of a test
+func configureTestSecurity(t *testing.T, cfg *SecurityConfig) *mockCookieMetrics {
+	t.Helper()
+	metrics := &mockCookieMetrics{}
+	if err := ConfigureSecurity(cfg, metrics); err != nil {
+		t.Fatalf("ConfigureSecurity failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = ConfigureSecurity(nil, nil) //nolint:errcheck // nil config cannot fail
+	})
+	return metrics
+}
+
+// roundTrip writes a cookie and parses it back from a request
+func roundTrip(t *testing.T, c *Cookie) (*Cookie, string) {
+	t.Helper()
+	httpCookie, err := c.ToHTTPCookie("example.com")
+	if err != nil {
+		t.Fatalf("ToHTTPCookie failed: %v", err)
+	}
+	return parseValue(httpCookie.Value), httpCookie.Value
+}
+
+// parseValue parses a raw uids cookie value
+func parseValue(value string) *Cookie {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(&http.Cookie{Name: CookieName, Value: value})
+	return ParseCookie(req)
+}
+
+// legacyValue encodes a cookie the way unsigned versions did
+func legacyValue(t *testing.T, c *Cookie) string {
+	t.Helper()
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	return base64.URLEncoding.EncodeToString(data)
+}
+
+func TestSignedCookie_RoundTrip(t *testing.T) {
+	metrics := configureTestSecurity(t, &SecurityConfig{SigningKeys: []SigningKey{testKeyCurrent}})
+
+	c := NewCookie()
+	c.SetUID("appnexus", "signed-uid")
+
+	parsed, value := roundTrip(t, c)
+
+	if !strings.HasPrefix(value, "s1.k2.") {
+		t.Errorf("Expected signed value with key k2, got %s", value)
+	}
+	if parsed.GetUID("appnexus") != "signed-uid" {
+		t.Errorf("Expected signed-uid, got %q", parsed.GetUID("appnexus"))
+	}
+	if metrics.results[VerifyResultValid] != 1 {
+		t.Errorf("Expected 1 valid verification, got %v", metrics.results)
+	}
+}
+
+func TestSignedCookie_Tampered(t *testing.T) {
+	metrics := configureTestSecurity(t, &SecurityConfig{SigningKeys: []SigningKey{testKeyCurrent}})
+
+	c := NewCookie()
+	c.SetUID("appnexus", "real-uid")
+	_, value := roundTrip(t, c)
+
+	// Replace the payload with a forged one, keeping the original MAC
+	forged := NewCookie()
+	forged.SetUID("appnexus", "forged-uid")
+	forgedData, _ := json.Marshal(forged) //nolint:errcheck // Test data
+	parts := strings.Split(value, ".")
+	parts[2] = base64.RawURLEncoding.EncodeToString(forgedData)
+
+	parsed := parseValue(strings.Join(parts, "."))
+
+	if parsed.HasUID("appnexus") {
+		t.Error("Forged cookie should be discarded")
+	}
+	if metrics.results[VerifyResultTampered] != 1 {
+		t.Errorf("Expected 1 tampered verification, got %v", metrics.results)
+	}
+}
+
+func TestSignedCookie_OptOutCannotBeCleared(t *testing.T) {
+	configureTestSecurity(t, &SecurityConfig{SigningKeys: []SigningKey{testKeyCurrent}})
+
+	c := NewCookie()
+	c.SetOptOut(true)
+	_, value := roundTrip(t, c)
+
+	// Flip optout in the payload
+	parts := strings.Split(value, ".")
+	payload, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	cleared := strings.Replace(string(payload), `"optout":true,`, "", 1)
+	parts[2] = base64.RawURLEncoding.EncodeToString([]byte(cleared))
+
+	parsed := parseValue(strings.Join(parts, "."))
+	if parsed.IsOptOut() {
+		t.Error("Tampered cookie should be replaced with a fresh cookie")
+	}
+
+	// The untouched value keeps the opt-out
+	if !parseValue(value).IsOptOut() {
+		t.Error("Signed opt-out should survive a round trip")
+	}
+}
+
+func TestSignedCookie_KeyRotation(t *testing.T) {
+	// Cookie signed with the old key
+	configureTestSecurity(t, &SecurityConfig{SigningKeys: []SigningKey{testKeyOld}})
+	c := NewCookie()
+	c.SetUID("rubicon", "rotated-uid")
+	_, oldValue := roundTrip(t, c)
+
+	// New key signs, old key still verifies
+	metrics := configureTestSecurity(t, &SecurityConfig{SigningKeys: []SigningKey{testKeyCurrent, testKeyOld}})
+	parsed := parseValue(oldValue)
+	if parsed.GetUID("rubicon") != "rotated-uid" {
+		t.Errorf("Cookie signed with old key should verify, got %q", parsed.GetUID("rubicon"))
+	}
+
+	_, newValue := roundTrip(t, parsed)
+	if !strings.HasPrefix(newValue, "s1.k2.") {
+		t.Errorf("Re-written cookie should be signed with current key, got %s", newValue)
+	}
+
+	// Old key retired
+	metrics = configureTestSecurity(t, &SecurityConfig{SigningKeys: []SigningKey{testKeyCurrent}})
+	if parseValue(oldValue).HasUID("rubicon") {
+		t.Error("Cookie signed with retired key should be discarded")
+	}
+	if metrics.results[VerifyResultUnknownKey] != 1 {
+		t.Errorf("Expected 1 unknown_key verification, got %v", metrics.results)
+	}
+}
+
+func TestEncryptedCookie_RoundTrip(t *testing.T) {
+	metrics := configureTestSecurity(t, &SecurityConfig{
+		SigningKeys:   []SigningKey{testKeyCurrent},
+		EncryptionKey: testAESKey,
+	})
+
+	c := NewCookie()
+	c.SetUID("pubmatic", "secret-uid")
+
+	parsed, value := roundTrip(t, c)
+
+	if !strings.HasPrefix(value, "e1.k2.") {
+		t.Errorf("Expected encrypted value, got %s", value)
+	}
+	if strings.Contains(value, base64.RawURLEncoding.EncodeToString([]byte("secret-uid"))) {
+		t.Error("Encrypted cookie should not expose the UID")
+	}
+	if parsed.GetUID("pubmatic") != "secret-uid" {
+		t.Errorf("Expected secret-uid, got %q", parsed.GetUID("pubmatic"))
+	}
+	if metrics.results[VerifyResultValid] != 1 {
+		t.Errorf("Expected 1 valid verification, got %v", metrics.results)
+	}
+}
+
+func TestEncryptedCookie_WrongKey(t *testing.T) {
+	configureTestSecurity(t, &SecurityConfig{
+		SigningKeys:   []SigningKey{testKeyCurrent},
+		EncryptionKey: testAESKey,
+	})
+	c := NewCookie()
+	c.SetUID("pubmatic", "secret-uid")
+	_, value := roundTrip(t, c)
+
+	metrics := configureTestSecurity(t, &SecurityConfig{
+		SigningKeys:   []SigningKey{testKeyCurrent},
+		EncryptionKey: []byte("another-32-byte-encryption-key!!"),
+	})
+	if parseValue(value).HasUID("pubmatic") {
+		t.Error("Cookie encrypted with another key should be discarded")
+	}
+	if metrics.results[VerifyResultDecryptFailed] != 1 {
+		t.Errorf("Expected 1 decrypt_failed verification, got %v", metrics.results)
+	}
+}
+
+func TestLegacyCookie_MigrationWindow(t *testing.T) {
+	c := NewCookie()
+	c.SetUID("appnexus", "legacy-uid")
+	value := legacyValue(t, c)
+
+	t.Run("accepted during window", func(t *testing.T) {
+		metrics := configureTestSecurity(t, &SecurityConfig{
+			SigningKeys:       []SigningKey{testKeyCurrent},
+			LegacyAcceptUntil: time.Now().Add(time.Hour),
+		})
+		if parseValue(value).GetUID("appnexus") != "legacy-uid" {
+			t.Error("Legacy cookie should be accepted during migration window")
+		}
+		if metrics.results[VerifyResultLegacy] != 1 {
+			t.Errorf("Expected 1 legacy verification, got %v", metrics.results)
+		}
+	})
+
+	t.Run("rejected after window", func(t *testing.T) {
+		metrics := configureTestSecurity(t, &SecurityConfig{
+			SigningKeys:       []SigningKey{testKeyCurrent},
+			LegacyAcceptUntil: time.Now().Add(-time.Hour),
+		})
+		if parseValue(value).HasUID("appnexus") {
+			t.Error("Legacy cookie should be rejected after migration window")
+		}
+		if metrics.results[VerifyResultLegacyRejected] != 1 {
+			t.Errorf("Expected 1 legacy_rejected verification, got %v", metrics.results)
+		}
+	})
+
+	t.Run("accepted when signing disabled", func(t *testing.T) {
+		metrics := configureTestSecurity(t, nil)
+		if parseValue(value).GetUID("appnexus") != "legacy-uid" {
+			t.Error("Unsigned cookie should be accepted when signing is disabled")
+		}
+		if len(metrics.results) != 0 {
+			t.Errorf("No metrics expected when signing is disabled, got %v", metrics.results)
+		}
+	})
+}
+
+func TestRejectedCookie_KeepsOptOut(t *testing.T) {
+	c := NewCookie()
+	c.SetOptOut(true)
+	legacy := legacyValue(t, c)
+
+	configureTestSecurity(t, &SecurityConfig{SigningKeys: []SigningKey{testKeyOld}})
+	_, retired := roundTrip(t, c)
+
+	configureTestSecurity(t, &SecurityConfig{
+		SigningKeys:       []SigningKey{testKeyCurrent},
+		LegacyAcceptUntil: time.Now().Add(-time.Hour),
+	})
+	_, signed := roundTrip(t, c)
+	parts := strings.Split(signed, ".")
+	parts[3] = "forged-mac"
+	tampered := strings.Join(parts, ".")
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"legacy after window", legacy},
+		{"retired key", retired},
+		{"bad signature", tampered},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !parseValue(tt.value).IsOptOut() {
+				t.Error("Expected the opt-out kept")
+			}
+		})
+	}
+
+	if parseValue("not base64!").IsOptOut() {
+		t.Error("Expected an unreadable cookie to carry no opt-out")
+	}
+}
+
+func TestSignedCookie_TrimToFit(t *testing.T) {
+	configureTestSecurity(t, &SecurityConfig{
+		SigningKeys:   []SigningKey{testKeyCurrent},
+		EncryptionKey: testAESKey,
+	})
+
+	c := NewCookie()
+	for i := 0; i < 200; i++ {
+		c.SetUID("bidder"+strconv.Itoa(i), strings.Repeat("u", 50))
+	}
+
+	httpCookie, err := c.ToHTTPCookie("example.com")
+	if err != nil {
+		t.Fatalf("ToHTTPCookie failed: %v", err)
+	}
+	if len(httpCookie.Value) > MaxCookieSize {
+		t.Errorf("Signed cookie size %d exceeds max %d", len(httpCookie.Value), MaxCookieSize)
+	}
+}
+
+func TestStoreToken(t *testing.T) {
+	ifa, _ := IFAKey("ifa-1")
+	ppuid, _ := PPUIDKey("pub1", "user-1")
+
+	if token := IssueStoreToken([]StoreKey{ifa}); token != "" {
+		t.Errorf("Expected no token without signing keys, got %q", token)
+	}
+
+	configureTestSecurity(t, &SecurityConfig{SigningKeys: []SigningKey{testKeyCurrent, testKeyOld}})
+	cc := currentCodec()
+	now := time.Now()
+	token := cc.issueStoreToken([]StoreKey{ifa, ppuid}, now)
+
+	keys := cc.verifyStoreToken(token, now)
+	if len(keys) != 2 || keys[0] != ifa || keys[1] != ppuid {
+		t.Errorf("Expected token keys back, got %+v", keys)
+	}
+	if keys := cc.verifyStoreToken(token, now.Add(StoreTokenTTL)); keys != nil {
+		t.Errorf("Expected expired token rejected, got %+v", keys)
+	}
+
+	// Swapping the payload breaks the signature
+	parts := strings.Split(token, ".")
+	forged := cc.issueStoreToken([]StoreKey{{Type: IDTypeIFA, Value: "victim"}}, now)
+	parts[2] = strings.Split(forged, ".")[2]
+	if keys := cc.verifyStoreToken(strings.Join(parts, "."), now); keys != nil {
+		t.Errorf("Expected tampered token rejected, got %+v", keys)
+	}
+
+	// Tokens survive key rotation while the old key is kept
+	configureTestSecurity(t, &SecurityConfig{SigningKeys: []SigningKey{testKeyOld}})
+	old := currentCodec().issueStoreToken([]StoreKey{ifa}, now)
+	configureTestSecurity(t, &SecurityConfig{SigningKeys: []SigningKey{testKeyCurrent, testKeyOld}})
+	if keys := currentCodec().verifyStoreToken(old, now); len(keys) != 1 {
+		t.Errorf("Expected token signed by a rotated key accepted, got %+v", keys)
+	}
+	configureTestSecurity(t, &SecurityConfig{SigningKeys: []SigningKey{testKeyCurrent}})
+	if keys := currentCodec().verifyStoreToken(old, now); keys != nil {
+		t.Errorf("Expected token signed by a removed key rejected, got %+v", keys)
+	}
+}
+
+func TestSecurityConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     SecurityConfig
+		wantErr error
+	}{
+		{"empty", SecurityConfig{}, nil},
+		{"valid", SecurityConfig{SigningKeys: []SigningKey{testKeyCurrent, testKeyOld}, EncryptionKey: testAESKey}, nil},
+		{"bad key ID", SecurityConfig{SigningKeys: []SigningKey{{ID: "a.b", Secret: testKeyCurrent.Secret}}}, ErrInvalidSigningKey},
+		{"empty key ID", SecurityConfig{SigningKeys: []SigningKey{{Secret: testKeyCurrent.Secret}}}, ErrInvalidSigningKey},
+		{"duplicate key ID", SecurityConfig{SigningKeys: []SigningKey{testKeyCurrent, testKeyCurrent}}, ErrInvalidSigningKey},
+		{"short secret", SecurityConfig{SigningKeys: []SigningKey{{ID: "k1", Secret: []byte("short")}}}, ErrInvalidSigningKey},
+		{"encryption without signing", SecurityConfig{EncryptionKey: testAESKey}, ErrNoSigningKeys},
+		{"bad encryption key", SecurityConfig{SigningKeys: []SigningKey{testKeyCurrent}, EncryptionKey: []byte("short")}, ErrInvalidEncryptionKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefaultSecurityConfig(t *testing.T) {
+	t.Setenv("UIDS_COOKIE_SIGNING_KEYS", "k2:"+string(testKeyCurrent.Secret)+", k1:"+string(testKeyOld.Secret))
+	t.Setenv("UIDS_COOKIE_ENCRYPTION_KEY", base64.StdEncoding.EncodeToString(testAESKey))
+	t.Setenv("UIDS_COOKIE_LEGACY_UNTIL", "2026-12-31")
+
+	cfg, err := DefaultSecurityConfig()
+	if err != nil {
+		t.Fatalf("DefaultSecurityConfig failed: %v", err)
+	}
+
+	if len(cfg.SigningKeys) != 2 || cfg.SigningKeys[0].ID != "k2" || cfg.SigningKeys[1].ID != "k1" {
+		t.Errorf("Unexpected signing keys: %+v", cfg.SigningKeys)
+	}
+	if string(cfg.EncryptionKey) != string(testAESKey) {
+		t.Error("Encryption key not decoded")
+	}
+	if !cfg.LegacyAcceptUntil.Equal(time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected legacy window: %v", cfg.LegacyAcceptUntil)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Config from env should validate: %v", err)
+	}
+}
+
+func TestDefaultSecurityConfig_Invalid(t *testing.T) {
+	t.Run("bad encryption key", func(t *testing.T) {
+		t.Setenv("UIDS_COOKIE_ENCRYPTION_KEY", "not base64!!")
+		if _, err := DefaultSecurityConfig(); err == nil {
+			t.Error("Expected error for invalid base64 key")
+		}
+	})
+
+	t.Run("bad legacy window", func(t *testing.T) {
+		t.Setenv("UIDS_COOKIE_LEGACY_UNTIL", "next week")
+		if _, err := DefaultSecurityConfig(); err == nil {
+			t.Error("Expected error for invalid legacy window")
+		}
+	})
+}
@@ -0,0 +1,255 @@
+package usersync
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+// SharedIDSource is the EID source for the publisher common ID (SharedID)
+const SharedIDSource = "pubcid.org"
+
+// SharedIDOptOutCookieName is the SharedID opt-out cookie, also honored by the Prebid.js SharedID module
+const SharedIDOptOutCookieName = "_pubcid_optout"
+
+// sharedIDAType is the EID agent type for a device/browser-scoped ID
+const sharedIDAType = 1
+
+// maxSharedIDLength bounds accepted pubcid cookie values
+const maxSharedIDLength = 128
+
+// SharedIDConfig holds configuration for the server-minted SharedID cookie
+type SharedIDConfig struct {
+	Enabled       bool
+	CookieDomains []string      // Publisher domains the cookie may be scoped to
+	TTL           time.Duration // Cookie lifetime, refreshed on each use
+}
+
+// DefaultSharedIDConfig returns SharedID configuration from environment variables
+// SHAREDID_ENABLED: "true" mints a pubcid cookie when the publisher has no ID module (default: disabled)
+// SHAREDID_COOKIE_DOMAINS: comma-separated publisher domains, e.g. "publisher.com,news.example" (default: none, nothing is minted)
+// SHAREDID_TTL: cookie lifetime (default: 365 days)
+func DefaultSharedIDConfig() *SharedIDConfig {
+	return &SharedIDConfig{
+		Enabled:       os.Getenv("SHAREDID_ENABLED") == "true",
+		CookieDomains: parseCookieDomains(os.Getenv("SHAREDID_COOKIE_DOMAINS")),
+		TTL:           getEnvDuration("SHAREDID_TTL", 365*24*time.Hour),
+	}
+}
+
+// parseCookieDomains splits a comma-separated domain list, dropping leading dots and empty entries
+func parseCookieDomains(value string) []string {
+	var domains []string
+	for _, d := range strings.Split(value, ",") {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "."))
+		if d != "" {
+			domains = append(domains, d)
+		}
+	}
+	return domains
+}
+
+// SharedIDMinter reads and mints the first-party pubcid cookie
+// A nil SharedIDMinter is disabled
+type SharedIDMinter struct {
+	config SharedIDConfig
+}
+
+// NewSharedIDMinter creates a SharedID minter
+func NewSharedIDMinter(config *SharedIDConfig) *SharedIDMinter {
+	if config == nil {
+		config = DefaultSharedIDConfig()
+	}
+	cfg := *config
+	cfg.CookieDomains = parseCookieDomains(strings.Join(config.CookieDomains, ","))
+	if cfg.TTL <= 0 {
+		cfg.TTL = 365 * 24 * time.Hour
+	}
+	return &SharedIDMinter{config: cfg}
+}
+
+// Enabled returns true if SharedID minting is configured
+func (m *SharedIDMinter) Enabled() bool {
+	return m != nil && m.config.Enabled
+}
+
+// IsOptedOut returns true if the user opted out via the SharedID opt-out cookie or the uids cookie
+// uids is the request's already parsed uids cookie (nil if it has none).
+func (m *SharedIDMinter) IsOptedOut(r *http.Request, uids *Cookie) bool {
+	if _, err := r.Cookie(SharedIDOptOutCookieName); err == nil {
+		return true
+	}
+	return uids != nil && uids.IsOptOut()
+}
+
+// Resolve returns the user's SharedID, minting a new one if the request has no valid pubcid cookie
+// Returns an empty ID when the user has opted out, or when no ID can be minted because the
+// request is not first-party to a configured publisher domain (the cookie would not come back).
+func (m *SharedIDMinter) Resolve(r *http.Request, uids *Cookie) (id string, minted bool, err error) {
+	if m.IsOptedOut(r, uids) {
+		return "", false, nil
+	}
+	if c, cookieErr := r.Cookie(PubCIDCookieName); cookieErr == nil && IsValidSharedID(c.Value) {
+		return c.Value, false, nil
+	}
+	if _, ok := m.firstPartyDomain(r); !ok {
+		return "", false, nil
+	}
+	id, err = NewSharedID()
+	if err != nil {
+		return "", false, err
+	}
+	return id, true, nil
+}
+
+// Cookie returns the pubcid cookie for the ID; the TTL is refreshed each time it is set
+// The cookie is readable by page scripts so a later on-page SharedID module reuses the same ID.
+// Returns nil when the request is not first-party to a configured publisher domain.
+func (m *SharedIDMinter) Cookie(id string, r *http.Request) *http.Cookie {
+	domain, ok := m.firstPartyDomain(r)
+	if !ok {
+		return nil
+	}
+	return &http.Cookie{
+		Name:     PubCIDCookieName,
+		Value:    id,
+		Path:     "/",
+		Domain:   domain,
+		Expires:  time.Now().Add(m.config.TTL),
+		MaxAge:   int(m.config.TTL.Seconds()),
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	}
+}
+
+// OptOutCookies returns cookies that delete the pubcid cookie and set the SharedID opt-out cookie
+// They are scoped to the matching publisher domain, or to the request host otherwise.
+func (m *SharedIDMinter) OptOutCookies(r *http.Request) []*http.Cookie {
+	domain, ok := m.firstPartyDomain(r)
+	if !ok {
+		domain = hostWithoutPort(r.Host)
+	}
+	return []*http.Cookie{
+		{
+			Name:     PubCIDCookieName,
+			Value:    "",
+			Path:     "/",
+			Domain:   domain,
+			MaxAge:   -1,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		},
+		{
+			Name:     SharedIDOptOutCookieName,
+			Value:    "1",
+			Path:     "/",
+			Domain:   domain,
+			Expires:  time.Now().Add(m.config.TTL),
+			MaxAge:   int(m.config.TTL.Seconds()),
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		},
+	}
+}
+
+// firstPartyDomain returns the configured publisher domain covering both the PBS host and the
+// page that sent the request (Origin, else Referer)
+// Only then is the cookie first-party on the page and sent back on the next request.
+func (m *SharedIDMinter) firstPartyDomain(r *http.Request) (string, bool) {
+	host := strings.ToLower(hostWithoutPort(r.Host))
+	page := strings.ToLower(pageHost(r))
+	if host == "" || page == "" {
+		return "", false
+	}
+	for _, domain := range m.config.CookieDomains {
+		if withinDomain(host, domain) && withinDomain(page, domain) {
+			return domain, true
+		}
+	}
+	return "", false
+}
+
+// pageHost returns the host of the page that sent the request
+func pageHost(r *http.Request) string {
+	for _, header := range []string{"Origin", "Referer"} {
+		if u, err := url.Parse(r.Header.Get(header)); err == nil && u.Hostname() != "" {
+			return u.Hostname()
+		}
+	}
+	return ""
+}
+
+// withinDomain returns true if host is the domain or one of its subdomains
+func withinDomain(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// hostWithoutPort strips the port from a Host header value
+func hostWithoutPort(host string) string {
+	if idx := strings.Index(host, ":"); idx != -1 {
+		return host[:idx]
+	}
+	return host
+}
+
+// NewSharedID returns a random (version 4) UUID
+func NewSharedID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate SharedID: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// IsValidSharedID returns true if the value is an acceptable pubcid
+// Any ID module may have written the cookie, so UUIDs and other URL-safe tokens are accepted
+func IsValidSharedID(id string) bool {
+	if id == "" || len(id) > maxSharedIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// HasSharedIDEID returns true if the user already carries a pubcid.org EID
+func HasSharedIDEID(user *openrtb.User) bool {
+	if user == nil {
+		return false
+	}
+	for _, eid := range user.EIDs {
+		if strings.EqualFold(eid.Source, SharedIDSource) && len(eid.UIDs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// InjectSharedIDEID adds the SharedID to the request's EIDs unless a pubcid.org EID is already present
+// Returns true if the EID was added
+func InjectSharedIDEID(req *openrtb.BidRequest, id string) bool {
+	if req == nil || id == "" || (req.User != nil && HasSharedIDEID(req.User)) {
+		return false
+	}
+	if req.User == nil {
+		req.User = &openrtb.User{}
+	}
+	req.User.EIDs = append(req.User.EIDs, openrtb.EID{
+		Source: SharedIDSource,
+		UIDs:   []openrtb.UID{{ID: id, AType: sharedIDAType}},
+	})
+	return true
+}
@@ -0,0 +1,222 @@
+package usersync
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/thenexusengine/tne_springwire/internal/openrtb"
+)
+
+var uuidV4Pattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNewSharedID(t *testing.T) {
+	a, err := NewSharedID()
+	if err != nil {
+		t.Fatalf("NewSharedID failed: %v", err)
+	}
+	b, _ := NewSharedID()
+	if !uuidV4Pattern.MatchString(a) {
+		t.Errorf("Expected a version 4 UUID, got %q", a)
+	}
+	if a == b {
+		t.Error("Expected distinct IDs")
+	}
+}
+
+func TestIsValidSharedID(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{"3f2b1c9e-8d4a-4f6b-9c1e-2a3b4c5d6e7f", true},
+		{"legacy_pubcid.v1", true},
+		{"", false},
+		{"has space", false},
+		{"<script>", false},
+		{string(make([]byte, maxSharedIDLength+1)), false},
+	}
+	for _, tt := range tests {
+		if got := IsValidSharedID(tt.id); got != tt.valid {
+			t.Errorf("IsValidSharedID(%q) = %v, want %v", tt.id, got, tt.valid)
+		}
+	}
+}
+
+// firstPartyRequest returns a request from a publisher page to PBS on the publisher's domain
+func firstPartyRequest() *http.Request {
+	req := httptest.NewRequest("GET", "https://pbs.publisher.com/cookie_sync", nil)
+	req.Header.Set("Origin", "https://www.publisher.com")
+	return req
+}
+
+func TestSharedIDMinter_Resolve(t *testing.T) {
+	m := NewSharedIDMinter(&SharedIDConfig{Enabled: true, CookieDomains: []string{"publisher.com"}})
+
+	// No cookie: a new ID is minted
+	id, minted, err := m.Resolve(firstPartyRequest(), nil)
+	if err != nil || !minted || !uuidV4Pattern.MatchString(id) {
+		t.Errorf("Expected a minted UUID, got %q minted=%v err=%v", id, minted, err)
+	}
+
+	// Existing cookie is reused
+	req := firstPartyRequest()
+	req.AddCookie(m.Cookie("existing-id", firstPartyRequest()))
+	id, minted, _ = m.Resolve(req, ParseCookie(req))
+	if id != "existing-id" || minted {
+		t.Errorf("Expected existing ID to be reused, got %q minted=%v", id, minted)
+	}
+
+	// Opt-out cookie blocks minting
+	req = firstPartyRequest()
+	for _, c := range m.OptOutCookies(firstPartyRequest()) {
+		if c.Value != "" {
+			req.AddCookie(c)
+		}
+	}
+	if id, _, _ := m.Resolve(req, ParseCookie(req)); id != "" {
+		t.Errorf("Expected no ID after opt-out, got %q", id)
+	}
+
+	// uids cookie opt-out also blocks minting
+	uids := NewCookie()
+	uids.SetOptOut(true)
+	uidsCookie, err := uids.ToHTTPCookie("publisher.com")
+	if err != nil {
+		t.Fatalf("Failed to build uids cookie: %v", err)
+	}
+	req = firstPartyRequest()
+	req.AddCookie(uidsCookie)
+	if id, _, _ := m.Resolve(req, ParseCookie(req)); id != "" {
+		t.Errorf("Expected no ID for opted-out uids cookie, got %q", id)
+	}
+}
+
+func TestSharedIDMinter_ResolveOnlyMintsFirstParty(t *testing.T) {
+	m := NewSharedIDMinter(&SharedIDConfig{Enabled: true, CookieDomains: []string{".publisher.com", "news.example"}})
+
+	tests := []struct {
+		name    string
+		url     string
+		origin  string
+		referer string
+		mint    bool
+	}{
+		{"origin on publisher domain", "https://pbs.publisher.com/", "https://www.publisher.com", "", true},
+		{"referer on publisher domain", "https://pbs.publisher.com/", "", "https://publisher.com/article", true},
+		{"second publisher", "https://pbs.news.example/", "https://news.example", "", true},
+		{"page on another site", "https://pbs.publisher.com/", "https://other.com", "", false},
+		{"PBS on a shared host", "https://pbs.vendor.net/", "https://www.publisher.com", "", false},
+		{"hosts on different publishers", "https://pbs.publisher.com/", "https://news.example", "", false},
+		{"suffix is not a subdomain", "https://pbs.publisher.com/", "https://notpublisher.com", "", false},
+		{"no page", "https://pbs.publisher.com/", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.url, nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.referer != "" {
+				req.Header.Set("Referer", tt.referer)
+			}
+
+			id, minted, err := m.Resolve(req, nil)
+			if err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+			if minted != tt.mint || (id != "") != tt.mint {
+				t.Errorf("Expected minted=%v, got %q minted=%v", tt.mint, id, minted)
+			}
+			if (m.Cookie("abc", req) != nil) != tt.mint {
+				t.Errorf("Expected a cookie only when first-party")
+			}
+		})
+	}
+
+	// An existing cookie came back, so it is reused even without a matching domain
+	req := httptest.NewRequest("GET", "https://pbs.vendor.net/", nil)
+	req.AddCookie(&http.Cookie{Name: PubCIDCookieName, Value: "page-written"})
+	if id, minted, _ := m.Resolve(req, nil); id != "page-written" || minted {
+		t.Errorf("Expected the existing cookie to be reused, got %q minted=%v", id, minted)
+	}
+
+	// No configured domains: nothing is minted
+	if id, _, _ := NewSharedIDMinter(&SharedIDConfig{Enabled: true}).Resolve(firstPartyRequest(), nil); id != "" {
+		t.Errorf("Expected no ID without configured domains, got %q", id)
+	}
+}
+
+func TestSharedIDMinter_Cookie(t *testing.T) {
+	m := NewSharedIDMinter(&SharedIDConfig{Enabled: true, CookieDomains: []string{".Publisher.com"}, TTL: 24 * time.Hour})
+	req := httptest.NewRequest("GET", "https://pbs.publisher.com:8000/", nil)
+	req.Header.Set("Referer", "https://www.publisher.com/")
+	c := m.Cookie("abc", req)
+	if c == nil || c.Name != PubCIDCookieName || c.Domain != "publisher.com" || c.MaxAge != 86400 {
+		t.Fatalf("Unexpected cookie: %+v", c)
+	}
+	if c.HttpOnly {
+		t.Error("pubcid cookie must be readable by on-page ID modules")
+	}
+
+	cookies := m.OptOutCookies(req)
+	if len(cookies) != 2 || cookies[0].MaxAge != -1 || cookies[1].Name != SharedIDOptOutCookieName || cookies[1].Domain != "publisher.com" {
+		t.Errorf("Unexpected opt-out cookies: %+v", cookies)
+	}
+
+	// Opt-outs outside a publisher domain are scoped to the request host
+	cookies = m.OptOutCookies(httptest.NewRequest("GET", "https://pbs.vendor.net:8000/optout", nil))
+	if cookies[0].Domain != "pbs.vendor.net" {
+		t.Errorf("Expected host-scoped opt-out cookies, got %q", cookies[0].Domain)
+	}
+}
+
+func TestSharedIDMinter_Enabled(t *testing.T) {
+	var m *SharedIDMinter
+	if m.Enabled() {
+		t.Error("nil minter should be disabled")
+	}
+	if NewSharedIDMinter(&SharedIDConfig{}).Enabled() {
+		t.Error("Expected disabled minter")
+	}
+}
+
+func TestInjectSharedIDEID(t *testing.T) {
+	req := &openrtb.BidRequest{}
+	if !InjectSharedIDEID(req, "abc") {
+		t.Fatal("Expected EID to be added")
+	}
+	if len(req.User.EIDs) != 1 || req.User.EIDs[0].Source != SharedIDSource || req.User.EIDs[0].UIDs[0].ID != "abc" {
+		t.Errorf("Unexpected EIDs: %+v", req.User.EIDs)
+	}
+
+	// An existing pubcid.org EID is never replaced
+	if InjectSharedIDEID(req, "other") {
+		t.Error("Expected existing pubcid EID to be kept")
+	}
+	if InjectSharedIDEID(&openrtb.BidRequest{}, "") {
+		t.Error("Expected empty ID to be ignored")
+	}
+}
+
+func TestDefaultSharedIDConfig(t *testing.T) {
+	t.Setenv("SHAREDID_ENABLED", "true")
+	t.Setenv("SHAREDID_COOKIE_DOMAINS", ".publisher.com, news.example,")
+	t.Setenv("SHAREDID_TTL", "720h")
+
+	cfg := DefaultSharedIDConfig()
+	if !cfg.Enabled || !slices.Equal(cfg.CookieDomains, []string{"publisher.com", "news.example"}) || cfg.TTL != 720*time.Hour {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+
+	t.Setenv("SHAREDID_ENABLED", "")
+	t.Setenv("SHAREDID_TTL", "")
+	cfg = DefaultSharedIDConfig()
+	if cfg.Enabled || cfg.TTL != 365*24*time.Hour {
+		t.Errorf("Unexpected defaults: %+v", cfg)
+	}
+}
@@ -0,0 +1,265 @@
+package usersync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// RedisUIDPrefix is the key prefix for server-side UID hashes
+// Each hash maps bidder code -> JSON UID and carries an optional opt-out tombstone
+const RedisUIDPrefix = "tne_catalyst:uids:"
+
+// PubCIDCookieName is the first-party publisher common ID (SharedID) cookie
+const PubCIDCookieName = "_pubcid"
+
+// optOutField marks a UID hash as opted out (not a valid bidder code)
+const optOutField = "!optout"
+
+// zeroIFA is the IFA reported when the user limits ad tracking
+const zeroIFA = "00000000-0000-0000-0000-000000000000"
+
+// ErrOptedOut is returned when writing UIDs for an opted-out identity
+var ErrOptedOut = errors.New("usersync: identity has opted out")
+
+// IDType identifies the first-party ID a UID hash is keyed by
+type IDType string
+
+const (
+	// IDTypePubCID is the publisher common ID (SharedID) cookie value
+	IDTypePubCID IDType = "pubcid"
+	// IDTypeIFA is the device advertising ID for app and CTV traffic
+	IDTypeIFA IDType = "ifa"
+	// IDTypePPUID is a SHA-256 hash of the publisher-provided user ID
+	IDTypePPUID IDType = "ppuid"
+)
+
+// StoreKey identifies a user in the UID store
+type StoreKey struct {
+	Type  IDType
+	Value string
+}
+
+// PubCIDKey returns the store key for a pubcid; ok is false for empty IDs
+func PubCIDKey(pubcid string) (StoreKey, bool) {
+	pubcid = strings.TrimSpace(pubcid)
+	if pubcid == "" {
+		return StoreKey{}, false
+	}
+	return StoreKey{Type: IDTypePubCID, Value: pubcid}, true
+}
+
+// IFAKey returns the store key for a device IFA; ok is false for empty or zeroed IFAs
+func IFAKey(ifa string) (StoreKey, bool) {
+	ifa = strings.ToLower(strings.TrimSpace(ifa))
+	if ifa == "" || ifa == zeroIFA {
+		return StoreKey{}, false
+	}
+	return StoreKey{Type: IDTypeIFA, Value: ifa}, true
+}
+
+// PPUIDKey returns the store key for a publisher user ID, scoped to the publisher
+// The raw ID is hashed so publisher user IDs are never stored
+func PPUIDKey(publisherID, userID string) (StoreKey, bool) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return StoreKey{}, false
+	}
+	sum := sha256.Sum256([]byte(publisherID + ":" + userID))
+	return StoreKey{Type: IDTypePPUID, Value: hex.EncodeToString(sum[:])}, true
+}
+
+// redisKey returns the Redis key for the store key
+func (k StoreKey) redisKey() string {
+	return RedisUIDPrefix + string(k.Type) + ":" + k.Value
+}
+
+// StoreClient defines the Redis operations used by the UID store
+type StoreClient interface {
+	HGet(ctx context.Context, key, field string) (string, error)
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	HSet(ctx context.Context, key, field string, value interface{}) error
+	HDel(ctx context.Context, key string, fields ...string) error
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+	Del(ctx context.Context, keys ...string) error
+}
+
+// StoreConfig holds UID store configuration
+type StoreConfig struct {
+	Enabled       bool
+	TTL           time.Duration // Lifetime of each bidder UID and of the hash after its last write
+	OptOutTTL     time.Duration // Lifetime of the opt-out tombstone
+	LookupTimeout time.Duration // Budget for reads on the auction path
+}
+
+// DefaultStoreConfig returns UID store configuration from environment variables
+// UID_STORE_ENABLED: "false" disables the store (default: enabled when Redis is configured)
+// UID_STORE_TTL: bidder UID lifetime (default: 90 days, matching the uids cookie)
+// UID_STORE_OPTOUT_TTL: opt-out tombstone lifetime (default: 5 years)
+// UID_STORE_LOOKUP_TIMEOUT: auction read budget (default: 20ms)
+func DefaultStoreConfig() *StoreConfig {
+	return &StoreConfig{
+		Enabled:       os.Getenv("UID_STORE_ENABLED") != "false",
+		TTL:           getEnvDuration("UID_STORE_TTL", DefaultTTL),
+		OptOutTTL:     getEnvDuration("UID_STORE_OPTOUT_TTL", 5*365*24*time.Hour),
+		LookupTimeout: getEnvDuration("UID_STORE_LOOKUP_TIMEOUT", 20*time.Millisecond),
+	}
+}
+
+// getEnvDuration reads a positive duration from an environment variable with a default
+func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return defaultVal
+}
+
+// Store is a Redis-backed server-side UID store for traffic without cookies
+type Store struct {
+	client StoreClient
+	config *StoreConfig
+}
+
+// NewStore creates a UID store
+func NewStore(client StoreClient, config *StoreConfig) *Store {
+	if config == nil {
+		config = DefaultStoreConfig()
+	}
+	return &Store{
+		client: client,
+		config: config,
+	}
+}
+
+// Enabled returns true if the store can serve requests
+func (s *Store) Enabled() bool {
+	return s != nil && s.client != nil && s.config.Enabled
+}
+
+// LookupTimeout returns the configured auction read budget
+func (s *Store) LookupTimeout() time.Duration {
+	return s.config.LookupTimeout
+}
+
+// StoredUIDs holds the UIDs stored for an identity
+type StoredUIDs struct {
+	UIDs   map[string]string // Bidder code -> UID (unexpired only)
+	OptOut bool
+}
+
+// Get returns the unexpired UIDs for a key; expired bidder UIDs are removed
+func (s *Store) Get(ctx context.Context, key StoreKey) (*StoredUIDs, error) {
+	result := &StoredUIDs{UIDs: make(map[string]string)}
+	if !s.Enabled() {
+		return result, nil
+	}
+
+	fields, err := s.client.HGetAll(ctx, key.redisKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read UIDs: %w", err)
+	}
+
+	if _, ok := fields[optOutField]; ok {
+		result.OptOut = true
+		return result, nil
+	}
+
+	now := time.Now()
+	var expired []string
+	for bidder, raw := range fields {
+		var uid UID
+		if err := json.Unmarshal([]byte(raw), &uid); err != nil || !now.Before(uid.Expires) {
+			expired = append(expired, bidder)
+			continue
+		}
+		result.UIDs[bidder] = uid.UID
+	}
+
+	if len(expired) > 0 {
+		// Best effort cleanup; stale fields are ignored on the next read anyway
+		_ = s.client.HDel(ctx, key.redisKey(), expired...) //nolint:errcheck // Cleanup only
+	}
+
+	return result, nil
+}
+
+// Lookup merges UIDs across keys, earlier keys taking precedence per bidder
+// If any key is opted out, no UIDs are returned
+func (s *Store) Lookup(ctx context.Context, keys []StoreKey) (*StoredUIDs, error) {
+	merged := &StoredUIDs{UIDs: make(map[string]string)}
+	for _, key := range keys {
+		stored, err := s.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if stored.OptOut {
+			return &StoredUIDs{UIDs: make(map[string]string), OptOut: true}, nil
+		}
+		for bidder, uid := range stored.UIDs {
+			if _, exists := merged.UIDs[bidder]; !exists {
+				merged.UIDs[bidder] = uid
+			}
+		}
+	}
+	return merged, nil
+}
+
+// SetUID stores a bidder UID for a key
+// Returns ErrOptedOut without writing if the key carries an opt-out tombstone
+func (s *Store) SetUID(ctx context.Context, key StoreKey, bidder, uid string) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	optOut, err := s.client.HGet(ctx, key.redisKey(), optOutField)
+	if err != nil {
+		return fmt.Errorf("failed to check opt-out: %w", err)
+	}
+	if optOut != "" {
+		return ErrOptedOut
+	}
+
+	data, err := json.Marshal(UID{UID: uid, Expires: time.Now().Add(s.config.TTL).UTC()})
+	if err != nil {
+		return err
+	}
+	if err := s.client.HSet(ctx, key.redisKey(), strings.ToLower(bidder), string(data)); err != nil {
+		return fmt.Errorf("failed to write UID: %w", err)
+	}
+	// Refresh the hash TTL so it outlives its newest UID
+	if err := s.client.Expire(ctx, key.redisKey(), s.config.TTL); err != nil {
+		return fmt.Errorf("failed to set UID TTL: %w", err)
+	}
+	return nil
+}
+
+// DeleteUID removes a bidder UID for a key
+func (s *Store) DeleteUID(ctx context.Context, key StoreKey, bidder string) error {
+	if !s.Enabled() {
+		return nil
+	}
+	return s.client.HDel(ctx, key.redisKey(), strings.ToLower(bidder))
+}
+
+// OptOut deletes all UIDs for a key and writes an opt-out tombstone
+func (s *Store) OptOut(ctx context.Context, key StoreKey) error {
+	if !s.Enabled() {
+		return nil
+	}
+	if err := s.client.Del(ctx, key.redisKey()); err != nil {
+		return fmt.Errorf("failed to delete UIDs: %w", err)
+	}
+	if err := s.client.HSet(ctx, key.redisKey(), optOutField, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("failed to write opt-out: %w", err)
+	}
+	if err := s.client.Expire(ctx, key.redisKey(), s.config.OptOutTTL); err != nil {
+		return fmt.Errorf("failed to set opt-out TTL: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package usersync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/thenexusengine/tne_springwire/pkg/redis"
+)
+
+// setupTestStore creates a UID store backed by miniredis
+func setupTestStore(t *testing.T) (*Store, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client, err := redis.New("redis://" + mr.Addr())
+	if err != nil {
+		t.Fatalf("Failed to create Redis client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	store := NewStore(client, &StoreConfig{
+		Enabled:       true,
+		TTL:           24 * time.Hour,
+		OptOutTTL:     365 * 24 * time.Hour,
+		LookupTimeout: time.Second,
+	})
+	return store, mr
+}
+
+func TestStoreKeys(t *testing.T) {
+	if _, ok := IFAKey(""); ok {
+		t.Error("Empty IFA should not produce a key")
+	}
+	if _, ok := IFAKey("00000000-0000-0000-0000-000000000000"); ok {
+		t.Error("Zeroed IFA should not produce a key")
+	}
+	key, ok := IFAKey(" AAAA-BBBB ")
+	if !ok || key.Value != "aaaa-bbbb" || key.Type != IDTypeIFA {
+		t.Errorf("Unexpected IFA key: %+v", key)
+	}
+
+	if _, ok := PubCIDKey("  "); ok {
+		t.Error("Blank pubcid should not produce a key")
+	}
+
+	key, ok = PPUIDKey("pub-1", "user@example.com")
+	if !ok {
+		t.Fatal("Expected PPUID key")
+	}
+	if strings.Contains(key.Value, "user@example.com") || len(key.Value) != 64 {
+		t.Errorf("PPUID should be stored as a SHA-256 hash, got %q", key.Value)
+	}
+	other, _ := PPUIDKey("pub-2", "user@example.com")
+	if other.Value == key.Value {
+		t.Error("PPUID keys should be scoped per publisher")
+	}
+}
+
+func TestStore_SetAndGet(t *testing.T) {
+	store, mr := setupTestStore(t)
+	ctx := context.Background()
+	key, _ := IFAKey("ifa-123")
+
+	if err := store.SetUID(ctx, key, "AppNexus", "an-uid"); err != nil {
+		t.Fatalf("SetUID failed: %v", err)
+	}
+	if err := store.SetUID(ctx, key, "rubicon", "rp-uid"); err != nil {
+		t.Fatalf("SetUID failed: %v", err)
+	}
+
+	stored, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if stored.UIDs["appnexus"] != "an-uid" || stored.UIDs["rubicon"] != "rp-uid" {
+		t.Errorf("Unexpected UIDs: %v", stored.UIDs)
+	}
+	if stored.OptOut {
+		t.Error("Identity should not be opted out")
+	}
+
+	if ttl := mr.TTL(RedisUIDPrefix + "ifa:ifa-123"); ttl != 24*time.Hour {
+		t.Errorf("Expected hash TTL of 24h, got %v", ttl)
+	}
+}
+
+func TestStore_PerBidderExpiry(t *testing.T) {
+	store, mr := setupTestStore(t)
+	ctx := context.Background()
+	key, _ := PubCIDKey("pubcid-1")
+	redisKey := RedisUIDPrefix + "pubcid:pubcid-1"
+
+	if err := store.SetUID(ctx, key, "appnexus", "fresh-uid"); err != nil {
+		t.Fatalf("SetUID failed: %v", err)
+	}
+
+	// A UID whose own expiry has passed, in a hash that is still alive
+	stale, _ := json.Marshal(UID{UID: "stale-uid", Expires: time.Now().Add(-time.Minute)}) //nolint:errcheck // Test data
+	mr.HSet(redisKey, "rubicon", string(stale))
+
+	stored, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if _, ok := stored.UIDs["rubicon"]; ok {
+		t.Error("Expired bidder UID should not be returned")
+	}
+	if stored.UIDs["appnexus"] != "fresh-uid" {
+		t.Errorf("Expected fresh-uid, got %q", stored.UIDs["appnexus"])
+	}
+	if mr.HGet(redisKey, "rubicon") != "" {
+		t.Error("Expired bidder UID should be removed from Redis")
+	}
+}
+
+func TestStore_OptOutTombstone(t *testing.T) {
+	store, mr := setupTestStore(t)
+	ctx := context.Background()
+	key, _ := IFAKey("ifa-optout")
+
+	if err := store.SetUID(ctx, key, "appnexus", "an-uid"); err != nil {
+		t.Fatalf("SetUID failed: %v", err)
+	}
+	if err := store.OptOut(ctx, key); err != nil {
+		t.Fatalf("OptOut failed: %v", err)
+	}
+
+	stored, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !stored.OptOut || len(stored.UIDs) != 0 {
+		t.Errorf("Expected opted-out identity without UIDs, got %+v", stored)
+	}
+
+	if err := store.SetUID(ctx, key, "rubicon", "rp-uid"); !errors.Is(err, ErrOptedOut) {
+		t.Errorf("Expected ErrOptedOut, got %v", err)
+	}
+
+	if ttl := mr.TTL(RedisUIDPrefix + "ifa:ifa-optout"); ttl != 365*24*time.Hour {
+		t.Errorf("Expected tombstone TTL of 1 year, got %v", ttl)
+	}
+}
+
+func TestStore_Lookup(t *testing.T) {
+	store, _ := setupTestStore(t)
+	ctx := context.Background()
+	ifa, _ := IFAKey("ifa-1")
+	pubcid, _ := PubCIDKey("pubcid-1")
+
+	_ = store.SetUID(ctx, ifa, "appnexus", "ifa-an")       //nolint:errcheck // Test setup
+	_ = store.SetUID(ctx, pubcid, "appnexus", "pubcid-an") //nolint:errcheck // Test setup
+	_ = store.SetUID(ctx, pubcid, "rubicon", "pubcid-rp")  //nolint:errcheck // Test setup
+
+	merged, err := store.Lookup(ctx, []StoreKey{ifa, pubcid})
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if merged.UIDs["appnexus"] != "ifa-an" {
+		t.Errorf("Earlier key should take precedence, got %q", merged.UIDs["appnexus"])
+	}
+	if merged.UIDs["rubicon"] != "pubcid-rp" {
+		t.Errorf("Later key should fill missing bidders, got %q", merged.UIDs["rubicon"])
+	}
+
+	// Opting out any identity suppresses all stored UIDs
+	_ = store.OptOut(ctx, pubcid) //nolint:errcheck // Test setup
+	merged, err = store.Lookup(ctx, []StoreKey{ifa, pubcid})
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if !merged.OptOut || len(merged.UIDs) != 0 {
+		t.Errorf("Expected opt-out to suppress UIDs, got %+v", merged)
+	}
+}
+
+func TestStore_DeleteUID(t *testing.T) {
+	store, _ := setupTestStore(t)
+	ctx := context.Background()
+	key, _ := IFAKey("ifa-delete")
+
+	_ = store.SetUID(ctx, key, "appnexus", "an-uid") //nolint:errcheck // Test setup
+	if err := store.DeleteUID(ctx, key, "AppNexus"); err != nil {
+		t.Fatalf("DeleteUID failed: %v", err)
+	}
+
+	stored, _ := store.Get(ctx, key) //nolint:errcheck // Checked via result
+	if len(stored.UIDs) != 0 {
+		t.Errorf("Expected no UIDs after delete, got %v", stored.UIDs)
+	}
+}
+
+func TestStore_Disabled(t *testing.T) {
+	var nilStore *Store
+	if nilStore.Enabled() {
+		t.Error("Nil store should be disabled")
+	}
+
+	store := NewStore(nil, &StoreConfig{Enabled: true})
+	if store.Enabled() {
+		t.Error("Store without client should be disabled")
+	}
+
+	ctx := context.Background()
+	key, _ := IFAKey("ifa-1")
+	if err := store.SetUID(ctx, key, "appnexus", "uid"); err != nil {
+		t.Errorf("Disabled store should ignore writes, got %v", err)
+	}
+	stored, err := store.Get(ctx, key)
+	if err != nil || len(stored.UIDs) != 0 {
+		t.Errorf("Disabled store should return no UIDs, got %+v, %v", stored, err)
+	}
+}
+
+func TestStore_RedisError(t *testing.T) {
+	store, mr := setupTestStore(t)
+	mr.Close()
+
+	key, _ := IFAKey("ifa-1")
+	if _, err := store.Get(context.Background(), key); err == nil {
+		t.Error("Expected error when Redis is unavailable")
+	}
+}
+
+func TestDefaultStoreConfig(t *testing.T) {
+	t.Setenv("UID_STORE_ENABLED", "false")
+	t.Setenv("UID_STORE_TTL", "48h")
+	t.Setenv("UID_STORE_OPTOUT_TTL", "invalid")
+
+	cfg := DefaultStoreConfig()
+	if cfg.Enabled {
+		t.Error("Expected store to be disabled")
+	}
+	if cfg.TTL != 48*time.Hour {
+		t.Errorf("Expected TTL 48h, got %v", cfg.TTL)
+	}
+	if cfg.OptOutTTL != 5*365*24*time.Hour {
+		t.Errorf("Invalid duration should fall back to default, got %v", cfg.OptOutTTL)
+	}
+	if cfg.LookupTimeout != 20*time.Millisecond {
+		t.Errorf("Expected default lookup timeout, got %v", cfg.LookupTimeout)
+	}
+}
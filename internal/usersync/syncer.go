@@ -22,16 +22,24 @@ type SyncerConfig struct {
 	// BidderCode is the bidder identifier
 	BidderCode string
 	// IframeSyncURL is the URL template for iframe syncs
-	// Use {{gdpr}}, {{gdpr_consent}}, {{us_privacy}}, {{redirect_url}} as placeholders
+	// Use {{gdpr}}, {{gdpr_consent}}, {{us_privacy}}, {{gpp}}, {{gpp_sid}}, {{redirect_url}} as placeholders
+	// ({{.GPP}} and {{.GPPSID}}, the Prebid Server spellings, are accepted too)
 	IframeSyncURL string
 	// RedirectSyncURL is the URL template for redirect syncs
 	RedirectSyncURL string
 	// SupportCORS indicates if the bidder supports CORS for the sync
 	SupportCORS bool
+	// UserMacro is the bidder's macro for its user ID in the /setuid redirect (default: $UID)
+	UserMacro string
+	// GVLVendorID is the IAB Global Vendor List ID used for TCF vendor consent (0 if unknown)
+	GVLVendorID int
 	// Enabled indicates if syncing is enabled for this bidder
 	Enabled bool
 }
 
+// DefaultUserMacro is the user ID macro used when a syncer does not define one
+const DefaultUserMacro = "$UID"
+
 // Syncer handles user sync URL generation for a bidder
 type Syncer struct {
 	config  SyncerConfig
@@ -53,8 +61,38 @@ type SyncInfo struct {
 	Bidder string   `json:"bidder"`
 }
 
+// SyncParams holds the privacy signals and UID store token substituted into a sync URL
+type SyncParams struct {
+	GDPR        string // "0" or "1"
+	GDPRConsent string // TCF consent string
+	USPrivacy   string // US Privacy string
+	GPP         string // GPP string
+	GPPSID      string // Comma-separated applicable GPP section IDs
+	StoreToken  string // Signed UID store keys, forwarded to /setuid as st ("" = none)
+}
+
+// consentQuery returns the privacy signals as /setuid query params, which /setuid checks before storing
+func (p SyncParams) consentQuery() string {
+	gdpr := p.GDPR
+	if gdpr == "" {
+		gdpr = "0"
+	}
+	query := "&gdpr=" + url.QueryEscape(gdpr)
+	for _, param := range []struct{ name, value string }{
+		{"gdpr_consent", p.GDPRConsent},
+		{"us_privacy", p.USPrivacy},
+		{"gpp", p.GPP},
+		{"gpp_sid", p.GPPSID},
+	} {
+		if param.value != "" {
+			query += "&" + param.name + "=" + url.QueryEscape(param.value)
+		}
+	}
+	return query
+}
+
 // GetSync returns the sync info for this bidder
-func (s *Syncer) GetSync(syncType SyncType, gdpr string, consent string, usPrivacy string) (*SyncInfo, error) {
+func (s *Syncer) GetSync(syncType SyncType, params SyncParams) (*SyncInfo, error) {
 	if !s.config.Enabled {
 		return nil, fmt.Errorf("syncing disabled for %s", s.config.BidderCode)
 	}
@@ -83,13 +121,29 @@ func (s *Syncer) GetSync(syncType SyncType, gdpr string, consent string, usPriva
 	}
 
 	// Build the redirect URL (where bidder will send the UID)
-	redirectURL := fmt.Sprintf("%s/setuid?bidder=%s&uid=$UID", s.hostURL, url.QueryEscape(s.config.BidderCode))
+	userMacro := s.config.UserMacro
+	if userMacro == "" {
+		userMacro = DefaultUserMacro
+	}
+	redirectURL := fmt.Sprintf("%s/setuid?bidder=%s", s.hostURL, url.QueryEscape(s.config.BidderCode))
+	redirectURL += params.consentQuery()
+	if params.StoreToken != "" {
+		// Lets /setuid key the UID store by the identities the token was issued for
+		redirectURL += "&st=" + url.QueryEscape(params.StoreToken)
+	}
+	redirectURL += "&uid=" + userMacro
 
 	// Replace placeholders
 	syncURL := urlTemplate
-	syncURL = strings.ReplaceAll(syncURL, "{{gdpr}}", gdpr)
-	syncURL = strings.ReplaceAll(syncURL, "{{gdpr_consent}}", url.QueryEscape(consent))
-	syncURL = strings.ReplaceAll(syncURL, "{{us_privacy}}", url.QueryEscape(usPrivacy))
+	syncURL = strings.ReplaceAll(syncURL, "{{gdpr}}", params.GDPR)
+	syncURL = strings.ReplaceAll(syncURL, "{{gdpr_consent}}", url.QueryEscape(params.GDPRConsent))
+	syncURL = strings.ReplaceAll(syncURL, "{{us_privacy}}", url.QueryEscape(params.USPrivacy))
+	for _, macro := range []string{"{{gpp}}", "{{.GPP}}"} {
+		syncURL = strings.ReplaceAll(syncURL, macro, url.QueryEscape(params.GPP))
+	}
+	for _, macro := range []string{"{{gpp_sid}}", "{{.GPPSID}}"} {
+		syncURL = strings.ReplaceAll(syncURL, macro, url.QueryEscape(params.GPPSID))
+	}
 	syncURL = strings.ReplaceAll(syncURL, "{{redirect_url}}", url.QueryEscape(redirectURL))
 
 	return &SyncInfo{
@@ -104,6 +158,22 @@ func (s *Syncer) BidderCode() string {
 	return s.config.BidderCode
 }
 
+// Config returns the syncer configuration
+func (s *Syncer) Config() SyncerConfig {
+	return s.config
+}
+
+// SupportsType returns true if a sync URL is configured for the sync type
+func (s *Syncer) SupportsType(syncType SyncType) bool {
+	switch syncType {
+	case SyncTypeIframe:
+		return s.config.IframeSyncURL != ""
+	case SyncTypeRedirect:
+		return s.config.RedirectSyncURL != ""
+	}
+	return false
+}
+
 // IsEnabled returns true if syncing is enabled
 func (s *Syncer) IsEnabled() bool {
 	return s.config.Enabled
@@ -1,6 +1,7 @@
 package usersync
 
 import (
+	"net/url"
 	"strings"
 	"testing"
 )
@@ -31,7 +32,7 @@ func TestSyncerGetSync_Redirect(t *testing.T) {
 
 	syncer := NewSyncer(config, "https://pbs.example.com")
 
-	syncInfo, err := syncer.GetSync(SyncTypeRedirect, "1", "consent-string", "")
+	syncInfo, err := syncer.GetSync(SyncTypeRedirect, SyncParams{GDPR: "1", GDPRConsent: "consent-string"})
 	if err != nil {
 		t.Fatalf("GetSync failed: %v", err)
 	}
@@ -62,7 +63,7 @@ func TestSyncerGetSync_Iframe(t *testing.T) {
 
 	syncer := NewSyncer(config, "https://pbs.example.com")
 
-	syncInfo, err := syncer.GetSync(SyncTypeIframe, "0", "", "")
+	syncInfo, err := syncer.GetSync(SyncTypeIframe, SyncParams{GDPR: "0"})
 	if err != nil {
 		t.Fatalf("GetSync failed: %v", err)
 	}
@@ -83,7 +84,7 @@ func TestSyncerGetSync_DefaultType(t *testing.T) {
 	syncer := NewSyncer(config, "https://pbs.example.com")
 
 	// Empty string should prefer redirect
-	syncInfo, err := syncer.GetSync("", "0", "", "")
+	syncInfo, err := syncer.GetSync("", SyncParams{GDPR: "0"})
 	if err != nil {
 		t.Fatalf("GetSync failed: %v", err)
 	}
@@ -102,7 +103,7 @@ func TestSyncerGetSync_Disabled(t *testing.T) {
 
 	syncer := NewSyncer(config, "https://pbs.example.com")
 
-	_, err := syncer.GetSync(SyncTypeRedirect, "0", "", "")
+	_, err := syncer.GetSync(SyncTypeRedirect, SyncParams{GDPR: "0"})
 	if err == nil {
 		t.Error("Should return error when disabled")
 	}
@@ -117,12 +118,57 @@ func TestSyncerGetSync_NoURL(t *testing.T) {
 
 	syncer := NewSyncer(config, "https://pbs.example.com")
 
-	_, err := syncer.GetSync(SyncTypeRedirect, "0", "", "")
+	_, err := syncer.GetSync(SyncTypeRedirect, SyncParams{GDPR: "0"})
 	if err == nil {
 		t.Error("Should return error when no URL configured")
 	}
 }
 
+func TestSyncerGetSync_UserMacro(t *testing.T) {
+	syncer := NewSyncer(SyncerConfig{
+		BidderCode:      "macrobidder",
+		RedirectSyncURL: "https://example.com/sync?r={{redirect_url}}",
+		UserMacro:       "${UID}",
+		Enabled:         true,
+	}, "https://pbs.example.com")
+
+	info, err := syncer.GetSync(SyncTypeRedirect, SyncParams{GDPR: "0"})
+	if err != nil {
+		t.Fatalf("GetSync failed: %v", err)
+	}
+	if !strings.Contains(info.URL, url.QueryEscape("uid=${UID}")) {
+		t.Errorf("Expected custom user macro in redirect URL, got %s", info.URL)
+	}
+
+	defaultMacro := NewSyncer(SyncerConfig{
+		BidderCode:      "plain",
+		RedirectSyncURL: "https://example.com/sync?r={{redirect_url}}",
+		Enabled:         true,
+	}, "https://pbs.example.com")
+	info, _ = defaultMacro.GetSync(SyncTypeRedirect, SyncParams{GDPR: "0"}) //nolint:errcheck // Checked via result
+	if !strings.Contains(info.URL, url.QueryEscape("uid="+DefaultUserMacro)) {
+		t.Errorf("Expected default user macro in redirect URL, got %s", info.URL)
+	}
+}
+
+func TestSyncerSupportsType(t *testing.T) {
+	syncer := NewSyncer(SyncerConfig{
+		BidderCode:      "test",
+		RedirectSyncURL: "https://example.com/sync",
+		Enabled:         true,
+	}, "https://pbs.example.com")
+
+	if !syncer.SupportsType(SyncTypeRedirect) {
+		t.Error("Expected redirect support")
+	}
+	if syncer.SupportsType(SyncTypeIframe) {
+		t.Error("Expected no iframe support without an iframe URL")
+	}
+	if syncer.SupportsType(SyncType("")) {
+		t.Error("Unknown sync type should not be supported")
+	}
+}
+
 func TestDefaultSyncerConfigs(t *testing.T) {
 	configs := DefaultSyncerConfigs()
 
@@ -153,7 +199,7 @@ func TestSyncerUSPrivacy(t *testing.T) {
 
 	syncer := NewSyncer(config, "https://pbs.example.com")
 
-	syncInfo, err := syncer.GetSync(SyncTypeRedirect, "0", "", "1YNN")
+	syncInfo, err := syncer.GetSync(SyncTypeRedirect, SyncParams{GDPR: "0", USPrivacy: "1YNN"})
 	if err != nil {
 		t.Fatalf("GetSync failed: %v", err)
 	}
@@ -162,3 +208,106 @@ func TestSyncerUSPrivacy(t *testing.T) {
 		t.Errorf("URL should contain US privacy string, got: %s", syncInfo.URL)
 	}
 }
+
+func TestSyncerGPP(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+	}{
+		{"repo macros", "https://example.com/sync?gpp={{gpp}}&gpp_sid={{gpp_sid}}&redirect={{redirect_url}}"},
+		{"prebid server macros", "https://example.com/sync?gpp={{.GPP}}&gpp_sid={{.GPPSID}}&redirect={{redirect_url}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syncer := NewSyncer(SyncerConfig{BidderCode: "rubicon", RedirectSyncURL: tt.template, Enabled: true}, "https://pbs.example.com")
+
+			syncInfo, err := syncer.GetSync(SyncTypeRedirect, SyncParams{GDPR: "0", GPP: "DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA", GPPSID: "2,6"})
+			if err != nil {
+				t.Fatalf("GetSync failed: %v", err)
+			}
+
+			if !strings.Contains(syncInfo.URL, "gpp=DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA&") {
+				t.Errorf("URL should contain the GPP string, got: %s", syncInfo.URL)
+			}
+			if !strings.Contains(syncInfo.URL, "gpp_sid=2%2C6&") {
+				t.Errorf("URL should contain the escaped GPP section IDs, got: %s", syncInfo.URL)
+			}
+		})
+	}
+}
+
+func TestSyncerGetSync_StoreToken(t *testing.T) {
+	syncer := NewSyncer(SyncerConfig{
+		BidderCode:      "appnexus",
+		RedirectSyncURL: "https://example.com/sync?r={{redirect_url}}",
+		Enabled:         true,
+	}, "https://pbs.example.com")
+
+	info, err := syncer.GetSync(SyncTypeRedirect, SyncParams{GDPR: "0", StoreToken: "st1.k1.payload+/=.mac"})
+	if err != nil {
+		t.Fatalf("GetSync failed: %v", err)
+	}
+	syncURL, err := url.Parse(info.URL)
+	if err != nil {
+		t.Fatalf("Invalid sync URL: %v", err)
+	}
+	redirect, err := url.Parse(syncURL.Query().Get("r"))
+	if err != nil {
+		t.Fatalf("Invalid redirect URL: %v", err)
+	}
+	if got := redirect.Query().Get("st"); got != "st1.k1.payload+/=.mac" {
+		t.Errorf("Expected the store token in the /setuid redirect, got %q (%s)", got, redirect)
+	}
+
+	info, _ = syncer.GetSync(SyncTypeRedirect, SyncParams{GDPR: "0"}) //nolint:errcheck // Checked via result
+	if strings.Contains(info.URL, "st%3D") {
+		t.Errorf("Expected no st parameter without a token, got %s", info.URL)
+	}
+}
+
+func TestSyncerGetSync_RedirectCarriesConsent(t *testing.T) {
+	syncer := NewSyncer(SyncerConfig{
+		BidderCode:      "appnexus",
+		RedirectSyncURL: "https://example.com/sync?r={{redirect_url}}",
+		Enabled:         true,
+	}, "https://pbs.example.com")
+
+	params := SyncParams{
+		GDPR:        "1",
+		GDPRConsent: "CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA",
+		USPrivacy:   "1YNN",
+		GPP:         "DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA",
+		GPPSID:      "2,6",
+	}
+	info, err := syncer.GetSync(SyncTypeRedirect, params)
+	if err != nil {
+		t.Fatalf("GetSync failed: %v", err)
+	}
+	syncURL, err := url.Parse(info.URL)
+	if err != nil {
+		t.Fatalf("Invalid sync URL: %v", err)
+	}
+	encoded := syncURL.Query().Get("r")
+	redirect, err := url.Parse(encoded)
+	if err != nil {
+		t.Fatalf("Invalid redirect URL: %v", err)
+	}
+
+	query := redirect.Query()
+	for name, want := range map[string]string{
+		"gdpr":         params.GDPR,
+		"gdpr_consent": params.GDPRConsent,
+		"us_privacy":   params.USPrivacy,
+		"gpp":          params.GPP,
+		"gpp_sid":      params.GPPSID,
+		"uid":          DefaultUserMacro,
+	} {
+		if got := query.Get(name); got != want {
+			t.Errorf("Expected %s=%q in the /setuid redirect, got %q (%s)", name, want, got, encoded)
+		}
+	}
+	if !strings.Contains(encoded, "gpp_sid=2%2C6") {
+		t.Errorf("Expected escaped values in the redirect, got %s", encoded)
+	}
+}
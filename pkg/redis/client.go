@@ -121,6 +121,16 @@ func (c *Client) HDel(ctx context.Context, key string, fields ...string) error {
 	return c.client.HDel(ctx, key, fields...).Err()
 }
 
+// Expire sets a key's time to live
+func (c *Client) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	return c.client.Expire(ctx, key, ttl).Err()
+}
+
+// Del deletes keys
+func (c *Client) Del(ctx context.Context, keys ...string) error {
+	return c.client.Del(ctx, keys...).Err()
+}
+
 // SMembers gets all members of a set
 func (c *Client) SMembers(ctx context.Context, key string) ([]string, error) {
 	return c.client.SMembers(ctx, key).Result()
@@ -141,6 +151,11 @@ func (c *Client) PoolStats() *redis.PoolStats {
 	return c.client.PoolStats()
 }
 
+// Eval runs a Lua script atomically, sending only its SHA1 once Redis has it cached
+func (c *Client) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	return redis.NewScript(script).Run(ctx, c.client, keys, args...).Result()
+}
+
 // Do executes a generic Redis command (for compatibility)
 func (c *Client) Do(ctx context.Context, args ...interface{}) *redis.Cmd {
 	return c.client.Do(ctx, args...)
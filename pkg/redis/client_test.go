@@ -334,6 +334,55 @@ func TestClient_HDel_Success(t *testing.T) {
 	}
 }
 
+func TestClient_Expire_Success(t *testing.T) {
+	mr, redisURL := setupTestRedis(t)
+	defer mr.Close()
+
+	client, err := New(redisURL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	mr.HSet("test-hash", "field1", "value1")
+
+	if err := client.Expire(ctx, "test-hash", time.Hour); err != nil {
+		t.Fatalf("Expire failed: %v", err)
+	}
+	if ttl := mr.TTL("test-hash"); ttl != time.Hour {
+		t.Errorf("Expected TTL of 1h, got %v", ttl)
+	}
+
+	// Key disappears once the TTL elapses
+	mr.FastForward(2 * time.Hour)
+	if mr.Exists("test-hash") {
+		t.Error("Expected key to expire")
+	}
+}
+
+func TestClient_Del_Success(t *testing.T) {
+	mr, redisURL := setupTestRedis(t)
+	defer mr.Close()
+
+	client, err := New(redisURL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	mr.HSet("hash1", "field", "value")
+	mr.HSet("hash2", "field", "value")
+
+	if err := client.Del(ctx, "hash1", "hash2", "missing"); err != nil {
+		t.Fatalf("Del failed: %v", err)
+	}
+	if mr.Exists("hash1") || mr.Exists("hash2") {
+		t.Error("Expected keys to be deleted")
+	}
+}
+
 func TestClient_SMembers_Success(t *testing.T) {
 	mr, redisURL := setupTestRedis(t)
 	defer mr.Close()
@@ -597,3 +646,28 @@ func TestClient_MultipleOperations(t *testing.T) {
 		t.Errorf("Expected 2 fields after delete, got %d", len(all))
 	}
 }
+
+func TestClient_Eval(t *testing.T) {
+	mr, redisURL := setupTestRedis(t)
+	defer mr.Close()
+
+	client, err := New(redisURL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	script := `redis.call('SET', KEYS[1], ARGV[1]); return redis.call('INCRBY', KEYS[2], ARGV[2])`
+	for want := int64(5); want <= 10; want += 5 {
+		result, err := client.Eval(context.Background(), script, []string{"k1", "k2"}, "v", 5)
+		if err != nil {
+			t.Fatalf("Eval failed: %v", err)
+		}
+		if result != want {
+			t.Errorf("Expected %d, got %v", want, result)
+		}
+	}
+	if got, _ := mr.Get("k1"); got != "v" {
+		t.Errorf("Expected script to set k1, got %q", got)
+	}
+}
@@ -0,0 +1,238 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// InvalidationChannel is the pub/sub channel cache invalidations are published on
+const InvalidationChannel = "tne_catalyst:invalidate"
+
+// Cache kinds carried on the invalidation bus
+const (
+	CachePublishers = "publishers" // ID is a publisher_id
+	CacheAPIKeys    = "api_keys"   // ID is a hashed API key ID
+	CacheBidders    = "bidders"    // ID is a bidder_code
+)
+
+const (
+	// invalidationHealthCheck is how long the subscriber waits for a message before pinging Redis
+	invalidationHealthCheck = 30 * time.Second
+
+	// Backoff between failed receives while Redis is unreachable
+	invalidationMinBackoff = 100 * time.Millisecond
+	invalidationMaxBackoff = 10 * time.Second
+
+	// invalidationRefreshTimeout bounds a single subscriber refresh
+	invalidationRefreshTimeout = 10 * time.Second
+)
+
+// Invalidation is a message on the invalidation bus
+type Invalidation struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id,omitempty"`     // "" = every entry of the kind
+	Source string `json:"source,omitempty"` // Publishing instance, which has already updated its own caches
+}
+
+// InvalidationSubscriber evicts one kind of cached entry
+type InvalidationSubscriber struct {
+	Evict   func(id string)                 // Drop or reload one entry
+	Refresh func(ctx context.Context) error // Drop or reload every entry
+}
+
+// InvalidationBus fans cache invalidations out to every instance over Redis pub/sub
+// An instance updates its own caches when it writes, then publishes so the others evict too.
+// Messages sent while the subscription is down are lost, so every subscriber is refreshed
+// in full after a reconnect.
+type InvalidationBus struct {
+	client      *Client
+	channel     string
+	source      string
+	healthCheck time.Duration
+
+	mu          sync.RWMutex
+	subscribers map[string][]InvalidationSubscriber
+
+	pubsub *redis.PubSub
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewInvalidationBus creates an invalidation bus on the given channel
+func NewInvalidationBus(client *Client, channel string) *InvalidationBus {
+	return &InvalidationBus{
+		client:      client,
+		channel:     channel,
+		source:      instanceID(),
+		healthCheck: invalidationHealthCheck,
+		subscribers: make(map[string][]InvalidationSubscriber),
+	}
+}
+
+// instanceID names this process on the bus so it can skip its own messages
+func instanceID() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return host
+	}
+	return host + "-" + hex.EncodeToString(suffix)
+}
+
+// Subscribe registers a subscriber for a cache kind
+// Subscribers should be registered before Start.
+func (b *InvalidationBus) Subscribe(kind string, sub InvalidationSubscriber) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers[kind] = append(b.subscribers[kind], sub)
+}
+
+// Publish tells the other instances to evict an entry ("" = every entry of the kind)
+func (b *InvalidationBus) Publish(ctx context.Context, kind, id string) error {
+	if b == nil {
+		return nil
+	}
+	payload, err := json.Marshal(Invalidation{Kind: kind, ID: id, Source: b.source})
+	if err != nil {
+		return err
+	}
+	return b.client.client.Publish(ctx, b.channel, payload).Err()
+}
+
+// Start subscribes to the channel and dispatches invalidations until Stop
+func (b *InvalidationBus) Start() {
+	if b == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+	b.done = make(chan struct{})
+	b.pubsub = b.client.client.Subscribe(ctx, b.channel)
+	go b.run(ctx)
+}
+
+// Stop unsubscribes and waits for the dispatch loop to exit
+func (b *InvalidationBus) Stop() {
+	if b == nil || b.cancel == nil {
+		return
+	}
+	b.cancel()
+	b.pubsub.Close()
+	<-b.done
+	b.cancel = nil
+}
+
+// run receives from the subscription, refreshing every subscriber after a resubscribe
+// go-redis reconnects and resubscribes on the next receive after a connection error.
+func (b *InvalidationBus) run(ctx context.Context) {
+	defer close(b.done)
+
+	subscribed := false
+	backoff := invalidationMinBackoff
+	for {
+		msg, err := b.pubsub.ReceiveTimeout(ctx, b.healthCheck)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			// A quiet channel is fine; the ping makes a dead connection fail and reconnect
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				if err = b.pubsub.Ping(ctx); err == nil {
+					continue
+				}
+			}
+			if backoff == invalidationMinBackoff {
+				log.Warn().Err(err).Str("channel", b.channel).Msg("Invalidation bus disconnected, retrying")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, invalidationMaxBackoff)
+			continue
+		}
+		backoff = invalidationMinBackoff
+
+		switch msg := msg.(type) {
+		case *redis.Subscription:
+			if msg.Kind != "subscribe" {
+				continue
+			}
+			if subscribed {
+				log.Info().Str("channel", b.channel).Msg("Invalidation bus resubscribed, refreshing caches")
+				b.refreshAll(ctx)
+			}
+			subscribed = true
+		case *redis.Message:
+			b.dispatch(ctx, msg.Payload)
+		}
+	}
+}
+
+// dispatch applies one invalidation message to the subscribers of its kind
+func (b *InvalidationBus) dispatch(ctx context.Context, payload string) {
+	var inv Invalidation
+	if err := json.Unmarshal([]byte(payload), &inv); err != nil || inv.Kind == "" {
+		log.Warn().Str("payload", payload).Msg("Ignoring malformed invalidation")
+		return
+	}
+	if inv.Source == b.source {
+		return
+	}
+
+	b.mu.RLock()
+	subscribers := b.subscribers[inv.Kind]
+	b.mu.RUnlock()
+
+	for _, sub := range subscribers {
+		if inv.ID == "" {
+			b.refresh(ctx, inv.Kind, sub)
+		} else if sub.Evict != nil {
+			sub.Evict(inv.ID)
+		}
+	}
+	log.Debug().Str("kind", inv.Kind).Str("id", inv.ID).Str("source", inv.Source).Msg("Cache invalidation applied")
+}
+
+// refreshAll refreshes every subscriber of every kind
+func (b *InvalidationBus) refreshAll(ctx context.Context) {
+	b.mu.RLock()
+	subscribers := make(map[string][]InvalidationSubscriber, len(b.subscribers))
+	for kind, subs := range b.subscribers {
+		subscribers[kind] = subs
+	}
+	b.mu.RUnlock()
+
+	for kind, subs := range subscribers {
+		for _, sub := range subs {
+			b.refresh(ctx, kind, sub)
+		}
+	}
+}
+
+// refresh runs one subscriber's full refresh, logging failures
+func (b *InvalidationBus) refresh(ctx context.Context, kind string, sub InvalidationSubscriber) {
+	if sub.Refresh == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, invalidationRefreshTimeout)
+	defer cancel()
+	if err := sub.Refresh(ctx); err != nil {
+		log.Warn().Err(err).Str("kind", kind).Msg("Cache refresh after invalidation failed")
+	}
+}
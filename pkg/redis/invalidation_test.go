@@ -0,0 +1,199 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// recordingSubscriber records evictions and refreshes (test helper)
+type recordingSubscriber struct {
+	mu        sync.Mutex
+	evicted   []string
+	refreshes int
+}
+
+func (r *recordingSubscriber) subscriber() InvalidationSubscriber {
+	return InvalidationSubscriber{
+		Evict: func(id string) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.evicted = append(r.evicted, id)
+		},
+		Refresh: func(ctx context.Context) error {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.refreshes++
+			return nil
+		},
+	}
+}
+
+func (r *recordingSubscriber) snapshot() ([]string, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.evicted...), r.refreshes
+}
+
+// waitFor polls cond until it holds or the deadline passes (test helper)
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// newTestBus creates a bus on its own client with a short health check (test helper)
+func newTestBus(t *testing.T, redisURL string) *InvalidationBus {
+	t.Helper()
+	client, err := New(redisURL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	bus := NewInvalidationBus(client, InvalidationChannel)
+	bus.healthCheck = 50 * time.Millisecond
+	return bus
+}
+
+// subscribers counts subscriptions to the invalidation channel (test helper)
+func subscribers(mr *miniredis.Miniredis) int {
+	return mr.PubSubNumSub(InvalidationChannel)[InvalidationChannel]
+}
+
+func TestInvalidationBus_PublishEvictsOnOtherInstances(t *testing.T) {
+	mr, redisURL := setupTestRedis(t)
+	defer mr.Close()
+
+	writer := newTestBus(t, redisURL)
+	reader := newTestBus(t, redisURL)
+
+	own := &recordingSubscriber{}
+	writer.Subscribe(CachePublishers, own.subscriber())
+	remote := &recordingSubscriber{}
+	reader.Subscribe(CachePublishers, remote.subscriber())
+	other := &recordingSubscriber{}
+	reader.Subscribe(CacheBidders, other.subscriber())
+
+	writer.Start()
+	defer writer.Stop()
+	reader.Start()
+	defer reader.Stop()
+	waitFor(t, "subscriptions", func() bool { return subscribers(mr) == 2 })
+
+	ctx := context.Background()
+	if err := writer.Publish(ctx, CachePublishers, "pub1"); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if err := writer.Publish(ctx, CachePublishers, ""); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	waitFor(t, "remote refresh", func() bool {
+		_, refreshes := remote.snapshot()
+		return refreshes == 1
+	})
+	evicted, _ := remote.snapshot()
+	if len(evicted) != 1 || evicted[0] != "pub1" {
+		t.Errorf("expected pub1 evicted remotely, got %v", evicted)
+	}
+
+	// The writer has already updated its own caches
+	if evicted, refreshes := own.snapshot(); len(evicted) != 0 || refreshes != 0 {
+		t.Errorf("expected own messages ignored, got %v evicted and %d refreshes", evicted, refreshes)
+	}
+	// Other kinds are untouched
+	if evicted, refreshes := other.snapshot(); len(evicted) != 0 || refreshes != 0 {
+		t.Errorf("expected bidders untouched, got %v evicted and %d refreshes", evicted, refreshes)
+	}
+}
+
+func TestInvalidationBus_ExternalPublish(t *testing.T) {
+	mr, redisURL := setupTestRedis(t)
+	defer mr.Close()
+
+	bus := newTestBus(t, redisURL)
+	rec := &recordingSubscriber{}
+	bus.Subscribe(CacheBidders, rec.subscriber())
+	bus.Start()
+	defer bus.Stop()
+	waitFor(t, "subscription", func() bool { return subscribers(mr) == 1 })
+
+	// Operators can invalidate by hand, e.g. after editing rows with SQL
+	mr.Publish(InvalidationChannel, "not json")
+	mr.Publish(InvalidationChannel, `{"id":"rubicon"}`)
+	mr.Publish(InvalidationChannel, `{"kind":"bidders","id":"rubicon"}`)
+
+	waitFor(t, "eviction", func() bool {
+		evicted, _ := rec.snapshot()
+		return len(evicted) == 1
+	})
+	if evicted, _ := rec.snapshot(); evicted[0] != "rubicon" {
+		t.Errorf("expected rubicon evicted, got %v", evicted)
+	}
+}
+
+func TestInvalidationBus_RefreshesAfterReconnect(t *testing.T) {
+	mr, redisURL := setupTestRedis(t)
+	defer mr.Close()
+
+	bus := newTestBus(t, redisURL)
+	publishers := &recordingSubscriber{}
+	bus.Subscribe(CachePublishers, publishers.subscriber())
+	keys := &recordingSubscriber{}
+	bus.Subscribe(CacheAPIKeys, keys.subscriber())
+	bus.Start()
+	defer bus.Stop()
+	waitFor(t, "subscription", func() bool { return subscribers(mr) == 1 })
+
+	if _, refreshes := publishers.snapshot(); refreshes != 0 {
+		t.Fatalf("expected no refresh on first subscribe, got %d", refreshes)
+	}
+
+	// Invalidations published while disconnected are lost, so everything is refreshed
+	mr.Close()
+	time.Sleep(100 * time.Millisecond)
+	if err := mr.Restart(); err != nil {
+		t.Fatalf("Failed to restart miniredis: %v", err)
+	}
+
+	waitFor(t, "refresh after reconnect", func() bool {
+		_, p := publishers.snapshot()
+		_, k := keys.snapshot()
+		return p == 1 && k == 1
+	})
+	waitFor(t, "resubscription", func() bool { return subscribers(mr) == 1 })
+
+	mr.Publish(InvalidationChannel, `{"kind":"api_keys","id":"key1"}`)
+	waitFor(t, "eviction after reconnect", func() bool {
+		evicted, _ := keys.snapshot()
+		return len(evicted) == 1
+	})
+}
+
+func TestInvalidationBus_StopAndNil(t *testing.T) {
+	mr, redisURL := setupTestRedis(t)
+	defer mr.Close()
+
+	bus := newTestBus(t, redisURL)
+	bus.Start()
+	bus.Stop()
+	bus.Stop()
+
+	// A nil bus (no Redis configured) is a no-op
+	var none *InvalidationBus
+	none.Subscribe(CacheBidders, InvalidationSubscriber{})
+	if err := none.Publish(context.Background(), CacheBidders, "rubicon"); err != nil {
+		t.Errorf("expected nil bus publish to succeed, got %v", err)
+	}
+	none.Start()
+	none.Stop()
+}